@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// orderLeg is one order of a multi-leg trade
+type orderLeg struct {
+	Symbol   string
+	Side     string // BUY, SELL, SHORT (open a perpetual short) or COVER (buy back a short)
+	Quantity float64
+	Price    float64
+}
+
+// gateLeg runs a leg that adds risk through the pre-trade gate, which rounds its quantity to
+// the instrument's quantity step, and the daily trade limits. Returns the leg with the approved
+// quantity and whether the gate reduced it to fit a limit or the available balance.
+func (bot *TradingBot) gateLeg(ctx context.Context, strategyName string, leg orderLeg) (orderLeg, bool, error) {
+	decision := bot.checkPreTrade(ctx, strategyName, leg.Symbol, leg.Side, leg.Quantity, leg.Price)
+	if !decision.Approved {
+		return leg, false, fmt.Errorf("%s %s rejected: %w", leg.Side, leg.Symbol, decision.Rejection)
+	}
+	if err := bot.PortfolioManager.CheckTradeLimit(leg.Symbol); err != nil {
+		return leg, false, fmt.Errorf("%s %s: %w", leg.Side, leg.Symbol, err)
+	}
+	leg.Quantity = decision.Quantity
+	return leg, decision.Resized, nil
+}
+
+// gateHedgedLegs runs the legs of a hedged trade through gateLeg before any of them is placed.
+// A leg that would be resized fails the trade, as it would leave the hedge unbalanced.
+func (bot *TradingBot) gateHedgedLegs(ctx context.Context, strategyName string, legs []orderLeg) ([]orderLeg, error) {
+	approved := make([]orderLeg, 0, len(legs))
+	for _, leg := range legs {
+		gated, resized, err := bot.gateLeg(ctx, strategyName, leg)
+		if err != nil {
+			return nil, err
+		}
+		if resized {
+			return nil, fmt.Errorf("%s %s would be resized from %.8f to %.8f, unbalancing the hedge",
+				leg.Side, leg.Symbol, leg.Quantity, gated.Quantity)
+		}
+		approved = append(approved, gated)
+	}
+	return approved, nil
+}
+
+// reverseSide returns the side of the order that undoes a leg
+func reverseSide(side string) string {
+	switch side {
+	case "BUY":
+		return "SELL"
+	case "SELL":
+		return "BUY"
+	case "SHORT":
+		return "COVER"
+	}
+	return "SHORT"
+}
+
+// logLeg records a placed leg in the trade log and tells the strategy about the fill
+func (bot *TradingBot) logLeg(strategyName, reason string, leg orderLeg) {
+	bot.PortfolioManager.LogTrade(leg.Symbol, leg.Side, leg.Quantity, leg.Price, strategyName, 1.0, reason)
+	bot.notifyFill(strategyName, "", leg.Symbol, leg.Side, leg.Quantity, leg.Price)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/portfolio"
+	"github.com/forbest/bybitgo/internal/risk"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// pollSignalOrders polls the status of resting signal orders, records their new fills and
+// notifies the strategies that placed them. Scalping orders are managed by runScalping and
+// market making quotes by runMarketMaking.
+func (bot *TradingBot) pollSignalOrders(ctx context.Context) {
+	for _, order := range bot.PortfolioManager.GetOpenOrders() {
+		if bot.Scalping != nil {
+			if _, managed := bot.Scalping.Orders[order.OrderID]; managed {
+				continue
+			}
+		}
+		if _, managed := bot.MarketMaking.Orders[order.OrderID]; managed {
+			continue
+		}
+
+		var status *bybit.OrderStatus
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			var err error
+			status, err = bot.BybitClient.GetOrderStatus(ctx, order.Symbol, order.OrderID)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get order %s: %v", order.OrderID, err)
+			continue
+		}
+
+		// Price of the new fills from the change in the volume-weighted average
+		previousStatus := order.Status
+		filled, _ := status.FilledQuantity.Float64()
+		avgPrice, _ := status.AvgPrice.Float64()
+		if delta := filled - order.FilledQuantity; delta > 0 {
+			price := (avgPrice*filled - order.AvgFillPrice*order.FilledQuantity) / delta
+			if err := bot.PortfolioManager.RecordFill(order.OrderID, delta, price); err != nil {
+				log.Printf("Warning: %v", err)
+			} else {
+				bot.notifyFill(order.Strategy, order.OrderID, order.Symbol, order.Action, delta, price)
+			}
+		}
+
+		if status.Status == bybit.OrderStatusCancelled || status.Status == bybit.OrderStatusRejected {
+			bot.PortfolioManager.CancelOrderRemainder(order.OrderID)
+		}
+		if order.Status != previousStatus {
+			bot.notifyOrderUpdate(order)
+		}
+	}
+}
+
+// strategyByName returns the implementation of a strategy, nil if it is unknown
+func (bot *TradingBot) strategyByName(name string) strategy.Strategy {
+	return bot.Strategies[strategy.StrategyType(name)]
+}
+
+// notifyOrderUpdate tells the strategy that placed an order about its current status
+func (bot *TradingBot) notifyOrderUpdate(order *portfolio.OrderRecord) {
+	strategy.NotifyOrderUpdate(bot.strategyByName(order.Strategy), strategy.OrderUpdate{
+		OrderID:        order.OrderID,
+		Symbol:         order.Symbol,
+		Side:           order.Action,
+		Status:         order.Status,
+		Quantity:       order.Quantity,
+		FilledQuantity: order.FilledQuantity,
+		AvgFillPrice:   order.AvgFillPrice,
+		Timestamp:      order.UpdatedAt,
+	})
+}
+
+// notifyFill tells a strategy about an execution against one of its orders
+func (bot *TradingBot) notifyFill(strategyName, orderID, symbol, side string, quantity, price float64) {
+	strategy.NotifyFill(bot.strategyByName(strategyName), strategy.Fill{
+		OrderID:   orderID,
+		Symbol:    symbol,
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		Timestamp: bot.now(),
+	})
+}
+
+// notifyPositionClosed tells the strategy that opened a position that it has been closed. The
+// quantity is negative for short positions.
+func (bot *TradingBot) notifyPositionClosed(strategyName, symbol string, quantity, entryPrice, exitPrice float64, reason string) {
+	pnl := (exitPrice - entryPrice) * quantity
+	strategy.NotifyPositionClosed(bot.strategyByName(strategyName), strategy.ClosedPosition{
+		Symbol:     symbol,
+		Quantity:   math.Abs(quantity),
+		Short:      quantity < 0,
+		EntryPrice: entryPrice,
+		ExitPrice:  exitPrice,
+		PnL:        pnl,
+		Reason:     reason,
+		Timestamp:  bot.now(),
+	})
+
+	// Strategy selection and signal calibration learn from the realized PnL
+	if strategyName != "" {
+		bot.StrategyAI.RecordOutcome(symbol, strategy.StrategyType(strategyName), pnl)
+	}
+	if bot.Calibrator != nil {
+		bot.Calibrator.RecordOutcome(symbol, pnl)
+	}
+}
+
+// saveStrategyStates persists the working state of the stateful strategies, what strategy
+// selection and signal calibration learned, and the shadow ledger
+func (bot *TradingBot) saveStrategyStates() {
+	if err := strategy.SaveStates(strategy.StatePath(bot.Config.DataDir), bot.Strategies); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if bot.StrategyAI.Bandit != nil {
+		if err := bot.StrategyAI.Bandit.SaveState(strategy.BanditStatePath(bot.Config.DataDir)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if bot.Calibrator != nil {
+		if err := bot.Calibrator.SaveState(strategy.CalibrationStatePath(bot.Config.DataDir)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if bot.Shadow != nil {
+		if err := bot.Shadow.SaveState(portfolio.ShadowStatePath(bot.Config.DataDir)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
@@ -3,15 +3,23 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/forbest/bybitgo/internal/backtest"
 	"github.com/forbest/bybitgo/internal/bybit"
 	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/execution"
 	"github.com/forbest/bybitgo/internal/market"
 	"github.com/forbest/bybitgo/internal/notifications"
 	"github.com/forbest/bybitgo/internal/portfolio"
@@ -19,6 +27,7 @@ import (
 	"github.com/forbest/bybitgo/internal/strategy"
 	"github.com/forbest/bybitgo/internal/web"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 )
 
 // TradingBot represents the main trading bot
@@ -29,18 +38,48 @@ type TradingBot struct {
 	MarketAnalyzer   *market.MarketAnalyzer
 	StrategyAI       *strategy.StrategyAI
 	RiskManager      *risk.RiskManager
+	PositionSizer    *risk.PositionSizer
+	PreTradeGate     *risk.PreTradeGate
+	Executor         execution.OrderExecutor // Places signal orders, simulated in paper trading mode
 	Strategies       map[strategy.StrategyType]strategy.Strategy
-	CircuitBreaker   *risk.CircuitBreaker
-	Dashboard        *web.Dashboard
-	Server           *http.Server
-	Notifier         *notifications.Notifier
+	// Strategy parameters from the parameter file, nil if none is configured
+	StrategyParams *strategy.ParameterConfig
+	// Parameters of strategies with per-symbol overrides before any override is applied
+	baseParameters   map[strategy.StrategyType]map[string]float64
+	DCA              *strategy.DCAStrategy               // Scheduled accumulation, nil if no DCA symbols are configured
+	PairsTrading     *strategy.PairsTradingStrategy      // Spread trading, nil if no pairs are configured
+	FundingArbitrage *strategy.FundingArbitrageStrategy  // Funding collection, nil if no symbols are configured
+	Scalping         *strategy.OrderBookScalpingStrategy // Order book scalping, nil if no symbols are configured
+	MarketMaking     *strategy.MarketMakingStrategy      // Quotes both sides of the configured market making symbols
+	// Weighted vote of several strategies used for every symbol, nil if not configured
+	Ensemble *strategy.EnsembleStrategy
+	// Triangular arbitrage, nil if no triangles are configured
+	TriangularArbitrage *strategy.TriangularArbitrageStrategy
+	CircuitBreakers     *risk.CircuitBreakerGroup
+	Dashboard           *web.Dashboard
+	Server              *http.Server
+	Notifier            *notifications.Notifier
+	Calibrator          *strategy.SignalCalibrator // Signal hit rates by strength, nil if calibration is disabled
+	Shadow              *portfolio.ShadowLedger    // Hypothetical fills of the shadow strategies, nil if none is configured
+	Debouncer           *strategy.SignalDebouncer  // Holds back BUY/SELL flips that have not persisted
+	Fusion              *strategy.SignalFusion     // Checks strategy signals against the indicator signals
+	HistoricalData      bybit.DataProvider         // Klines of the market analyzer warm-up
+	regimeProfiles      map[string]string          // Regime profiles in use per strategy/symbol, to log profile switches
 	// Add fields for manual override control
 	IsRunning bool
 	StopChan  chan struct{}
+	// Rebalance intervals changed from the dashboard, for the trading loop's ticker
+	rebalanceReset chan time.Duration
+	// UTC date of the last daily summary that was sent
+	LastSummaryDate string
+	// Simulated time of a replay, nil for the wall clock
+	clock func() time.Time
+	// Set by replays, which look up nothing on the exchange besides historical klines
+	offline bool
 }
 
-// NewTradingBot creates a new TradingBot
-func NewTradingBot() (*TradingBot, error) {
+// loadConfig loads the configuration from the environment and the .env file
+func loadConfig() (*config.Config, error) {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: Error loading .env file: %v", err)
@@ -51,12 +90,30 @@ func NewTradingBot() (*TradingBot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	return cfg, nil
+}
 
+// NewTradingBot creates a new TradingBot from a configuration
+func NewTradingBot(cfg *config.Config) (*TradingBot, error) {
 	// Create Bybit client
 	bybitClient := bybit.NewClient(cfg.BybitAPIKey, cfg.BybitAPISecret, cfg.Testnet)
 
 	// Create market analyzer
 	marketAnalyzer := market.NewMarketAnalyzer()
+	// Attach market sentiment to the enhanced market data if a provider is configured
+	if cfg.SentimentProvider != "" {
+		provider, err := market.NewSentimentProvider(cfg.SentimentProvider, market.SentimentSettings{
+			URL:      cfg.SentimentURL,
+			Scores:   cfg.SentimentScores,
+			CacheTTL: time.Duration(cfg.SentimentCacheMinutes) * time.Minute,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sentiment provider: %w", err)
+		}
+		marketAnalyzer.Sentiment = provider
+		marketAnalyzer.SentimentWeight = cfg.SentimentWeight
+		log.Printf("Market sentiment from %s provider", cfg.SentimentProvider)
+	}
 
 	// Create portfolio manager
 	portfolioManager := portfolio.NewPortfolioManager(bybitClient, cfg)
@@ -65,165 +122,1857 @@ func NewTradingBot() (*TradingBot, error) {
 
 	// Create strategy AI
 	strategyAI := strategy.NewStrategyAI(marketAnalyzer)
+	if cfg.BanditSelection {
+		// Learn from realized PnL which strategies work in which regime, resuming past experience
+		strategyAI.Bandit = strategy.NewStrategyBandit(cfg.BanditPrior, cfg.BanditDecay)
+		if err := strategyAI.Bandit.LoadState(strategy.BanditStatePath(cfg.DataDir)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
 
 	// Create risk manager
 	riskManager := risk.NewRiskManager(cfg)
+	// Set the market analyzer reference for VaR
+	riskManager.MarketAnalyzer = marketAnalyzer
+
+	// Replace the built-in risk limits with rules from file if configured
+	if cfg.RiskRulesFile != "" {
+		rules, err := risk.LoadRiskRules(cfg.RiskRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		riskManager.Rules = rules
+		log.Printf("Loaded %d risk rules from %s", len(rules), cfg.RiskRulesFile)
+	}
+
+	// Create ATR-based position sizer
+	positionSizer := risk.NewPositionSizer(cfg)
+
+	// Create circuit breakers per endpoint category
+	circuitBreakers := risk.NewCircuitBreakerGroup(time.Duration(cfg.CircuitBreakerTimeoutSeconds)*time.Second, cfg.CircuitBreakerFailureThreshold)
+	circuitBreakers.Configure("", risk.CircuitBreakerSettings{
+		HalfOpenMaxCalls: cfg.CircuitBreakerHalfOpenProbes,
+		SuccessThreshold: cfg.CircuitBreakerSuccessThreshold,
+	})
+	circuitBreakers.OnStateChange = func(name, from, to string) {
+		log.Printf("Circuit breaker %s: %s -> %s", name, from, to)
+		if to == "open" {
+			riskManager.EmitRiskEvent(risk.RiskEventCircuitBreakerOpened, risk.SeverityCritical, name,
+				fmt.Sprintf("Circuit breaker %s opened after repeated API failures", name))
+		}
+	}
 
-	// Create circuit breaker (10 seconds timeout, 5 failure threshold)
-	circuitBreaker := risk.NewCircuitBreaker(10*time.Second, 5)
+	// Create pre-trade gate that every order passes before submission
+	preTradeGate := risk.NewPreTradeGate(riskManager, circuitBreakers.Get(risk.EndpointOrders))
+
+	// Create the executor that turns risk-checked signals into orders
+	var executor execution.OrderExecutor = execution.NewLiveExecutor(bybitClient, circuitBreakers)
+	if cfg.PaperTrading {
+		executor = execution.NewPaperExecutor()
+		log.Println("Paper trading mode: strategy orders are simulated")
+
+		// Multi-leg, resting and exchange-side orders are not simulated, so their features are disabled
+		if len(cfg.Pairs) > 0 || len(cfg.FundingArbSymbols) > 0 || len(cfg.ScalpSymbols) > 0 || len(cfg.MMSymbols) > 0 || len(cfg.TriArbTriangles) > 0 || cfg.PlaceProtectiveOrders {
+			log.Println("Warning: Pairs trading, funding arbitrage, order book scalping, market making quotes, triangular arbitrage and protective orders are disabled in paper trading mode")
+		}
+		cfg.Pairs = nil
+		cfg.FundingArbSymbols = nil
+		cfg.ScalpSymbols = nil
+		cfg.MMSymbols = nil
+		cfg.TriArbTriangles = nil
+		cfg.PlaceProtectiveOrders = false
+	}
+
+	// Quote market making around the analyzer's volatility and the tracked inventory
+	marketMakingStrategy := strategy.NewMarketMakingStrategy()
+	marketMakingStrategy.MarketAnalyzer = marketAnalyzer
+	marketMakingStrategy.RiskManager = riskManager
+	marketMakingStrategy.Parameters["order_notional"] = cfg.MMOrderNotional
+	marketMakingStrategy.Parameters["refresh_bps"] = cfg.MMRefreshBps
+	marketMakingStrategy.Parameters["max_inventory"] = cfg.MMMaxInventory
+	if cfg.MMHedgeInventory {
+		marketMakingStrategy.Parameters["hedge"] = 1
+	}
 
 	// Create strategy implementations
 	strategies := map[strategy.StrategyType]strategy.Strategy{
-		strategy.MarketMaking:       strategy.NewMarketMakingStrategy(),
+		strategy.MarketMaking:       marketMakingStrategy,
 		strategy.Momentum:           strategy.NewMomentumStrategy(),
 		strategy.MeanReversion:      strategy.NewMeanReversionStrategy(),
 		strategy.VolatilityBreakout: strategy.NewVolatilityBreakoutStrategy(),
+		strategy.TrendFollowing:     strategy.NewTrendFollowingStrategy(),
+		strategy.BreakoutRetest:     strategy.NewBreakoutRetestStrategy(),
+	}
+
+	// Create the dollar-cost averaging strategy for the configured symbols
+	var dcaStrategy *strategy.DCAStrategy
+	if len(cfg.DCASymbols) > 0 {
+		dcaStrategy = strategy.NewDCAStrategy()
+		dcaStrategy.Parameters["amount"] = cfg.DCAAmount
+		dcaStrategy.Parameters["interval_hours"] = cfg.DCAIntervalHours
+		dcaStrategy.Parameters["dip_percent"] = cfg.DCADipPercent
+		dcaStrategy.Parameters["dip_multiplier"] = cfg.DCADipMultiplier
+
+		// Resume the schedule from the last logged DCA buys
+		for _, trade := range portfolioManager.GetTradeLog() {
+			if trade.Strategy == string(strategy.DCA) && trade.Action == "BUY" {
+				dcaStrategy.LastBuy[trade.Symbol] = trade.Timestamp
+			}
+		}
+		strategies[strategy.DCA] = dcaStrategy
+	}
+
+	// Create the pairs trading strategy for the configured pairs
+	var pairsStrategy *strategy.PairsTradingStrategy
+	if len(cfg.Pairs) > 0 {
+		pairs := make([]strategy.Pair, 0, len(cfg.Pairs))
+		for _, pair := range cfg.Pairs {
+			pairs = append(pairs, strategy.Pair{SymbolA: pair[0], SymbolB: pair[1]})
+		}
+		pairsStrategy = strategy.NewPairsTradingStrategy(marketAnalyzer, pairs)
+		pairsStrategy.Parameters["entry_z"] = cfg.PairsEntryZ
+		pairsStrategy.Parameters["exit_z"] = cfg.PairsExitZ
+		pairsStrategy.Parameters["stop_z"] = cfg.PairsStopZ
+		pairsStrategy.Parameters["min_correlation"] = cfg.PairsMinCorrelation
+		pairsStrategy.Parameters["leg_notional"] = cfg.PairsLegNotional
+		strategies[strategy.PairsTrading] = pairsStrategy
+	}
+
+	// Create the funding arbitrage strategy for the configured symbols
+	var fundingStrategy *strategy.FundingArbitrageStrategy
+	if len(cfg.FundingArbSymbols) > 0 {
+		fundingStrategy = strategy.NewFundingArbitrageStrategy()
+		fundingStrategy.Parameters["entry_rate"] = cfg.FundingArbEntryRate
+		fundingStrategy.Parameters["exit_rate"] = cfg.FundingArbExitRate
+		fundingStrategy.Parameters["max_basis_percent"] = cfg.FundingArbMaxBasisPercent
+		fundingStrategy.Parameters["notional"] = cfg.FundingArbNotional
+		strategies[strategy.FundingArbitrage] = fundingStrategy
+	}
+
+	// Create the order book scalping strategy for the configured symbols
+	var scalpingStrategy *strategy.OrderBookScalpingStrategy
+	if len(cfg.ScalpSymbols) > 0 {
+		scalpingStrategy = strategy.NewOrderBookScalpingStrategy()
+		scalpingStrategy.Parameters["order_notional"] = cfg.ScalpOrderNotional
+		scalpingStrategy.Parameters["imbalance_threshold"] = cfg.ScalpImbalance
+		scalpingStrategy.Parameters["max_spread_bps"] = cfg.ScalpMaxSpreadBps
+		scalpingStrategy.Parameters["min_depth"] = cfg.ScalpMinDepth
+		scalpingStrategy.Parameters["order_ttl_seconds"] = cfg.ScalpOrderTTLSeconds
+		scalpingStrategy.Parameters["max_inventory"] = cfg.ScalpMaxInventory
+		strategies[strategy.OrderBookScalping] = scalpingStrategy
+	}
+
+	// Create the triangular arbitrage strategy, its triangles are resolved when the bot starts
+	var triArbStrategy *strategy.TriangularArbitrageStrategy
+	if len(cfg.TriArbTriangles) > 0 {
+		triArbStrategy = strategy.NewTriangularArbitrageStrategy()
+		triArbStrategy.Parameters["taker_fee_percent"] = cfg.TriArbTakerFeePercent
+		triArbStrategy.Parameters["min_profit_percent"] = cfg.TriArbMinProfitPercent
+		triArbStrategy.Parameters["max_latency_ms"] = cfg.TriArbMaxLatencyMs
+		triArbStrategy.Parameters["notional"] = cfg.TriArbNotional
+		strategies[strategy.TriangularArbitrage] = triArbStrategy
+	}
+
+	// Add registered third-party strategies: the ones named in the config and every strategy
+	// registered by a plugin file
+	pluginStrategies := make([]strategy.StrategyType, 0, len(cfg.StrategyPlugins))
+	for _, name := range cfg.StrategyPlugins {
+		pluginStrategies = append(pluginStrategies, strategy.StrategyType(name))
+	}
+	for _, path := range cfg.StrategyPluginPaths {
+		names, err := strategy.LoadPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded strategy plugin %s: %v", path, names)
+		pluginStrategies = append(pluginStrategies, names...)
+	}
+	for _, name := range pluginStrategies {
+		if _, exists := strategies[name]; exists {
+			continue
+		}
+		impl, err := strategy.NewStrategy(name)
+		if err != nil {
+			return nil, err
+		}
+		strategies[name] = impl
+		strategyAI.AddStrategy(name, impl)
+	}
+
+	// Take the shadow strategies out of the selection, their signals only go to the shadow ledger
+	var shadowLedger *portfolio.ShadowLedger
+	if len(cfg.ShadowStrategies) > 0 {
+		strategyAI.Shadow = make(map[strategy.StrategyType]bool)
+		for _, name := range cfg.ShadowStrategies {
+			strategyType := strategy.StrategyType(strings.ToLower(name))
+			if _, exists := strategies[strategyType]; !exists || !strategyAI.IsSelectable(strategyType) {
+				log.Printf("Warning: Strategy %s can not run in shadow mode", name)
+				continue
+			}
+			strategyAI.Shadow[strategyType] = true
+		}
+		if len(strategyAI.Shadow) > 0 {
+			shadowLedger = portfolio.NewShadowLedger(cfg.ShadowFeePercent)
+			if err := shadowLedger.LoadState(portfolio.ShadowStatePath(cfg.DataDir)); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			log.Printf("Shadow mode strategies: %v", cfg.ShadowStrategies)
+		}
+	}
+
+	// Create the ensemble from the configured member strategies
+	var ensembleStrategy *strategy.EnsembleStrategy
+	if len(cfg.EnsembleStrategies) > 0 {
+		ensembleStrategy = strategy.NewEnsembleStrategy()
+		ensembleStrategy.Parameters["min_consensus"] = cfg.EnsembleMinConsensus
+		ensembleStrategy.Parameters["accuracy_window"] = float64(cfg.EnsembleAccuracyWindow)
+		if !cfg.EnsembleLearnWeights {
+			ensembleStrategy.Parameters["learn_weights"] = 0
+		}
+
+		members := make([]string, 0, len(cfg.EnsembleStrategies))
+		for name := range cfg.EnsembleStrategies {
+			members = append(members, name)
+		}
+		sort.Strings(members)
+		for _, name := range members {
+			// Env lists are upper-cased, strategy names are lower case
+			strategyType := strategy.StrategyType(strings.ToLower(name))
+			impl, exists := strategies[strategyType]
+			if !exists || cfg.EnsembleStrategies[name] < 0 || strategyAI.Shadow[strategyType] {
+				log.Printf("Warning: Ignoring ensemble member %s", name)
+				continue
+			}
+			ensembleStrategy.AddMember(strategyType, impl, cfg.EnsembleStrategies[name])
+		}
+		if len(ensembleStrategy.Members) > 0 {
+			strategies[strategy.Ensemble] = ensembleStrategy
+		} else {
+			ensembleStrategy = nil
+		}
+	}
+
+	// Apply tuned parameters from the parameter file on top of the defaults and env settings
+	var strategyParams *strategy.ParameterConfig
+	baseParameters := make(map[strategy.StrategyType]map[string]float64)
+	if cfg.StrategyParamsFile != "" {
+		var err error
+		strategyParams, err = strategy.LoadParameterConfig(cfg.StrategyParamsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := strategyParams.Apply(strategies); err != nil {
+			return nil, fmt.Errorf("invalid strategy parameters in %s: %w", cfg.StrategyParamsFile, err)
+		}
+		for strategyType, impl := range strategies {
+			if strategyParams.HasOverrides(strategyType) {
+				baseParameters[strategyType] = strategyParams.ForSymbol(strategyType, "", impl.GetParameters())
+			}
+		}
+		log.Printf("Loaded strategy parameters from %s", cfg.StrategyParamsFile)
+	}
+
+	// Track the hit rate of signals by strength, resuming the recorded history
+	var calibrator *strategy.SignalCalibrator
+	if cfg.SignalCalibration {
+		calibrator = strategy.NewSignalCalibrator(cfg.CalibrationBuckets, cfg.CalibrationPrior)
+		if err := calibrator.LoadState(strategy.CalibrationStatePath(cfg.DataDir)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	// Strategies that need more history than is fetched would never warm up
+	for strategyType, impl := range strategies {
+		if required := strategy.RequiredBars(impl); required > bybit.MarketDataBars {
+			log.Printf("Warning: Strategy %s needs %d bars to warm up but only %d are fetched", strategyType, required, bybit.MarketDataBars)
+		}
+	}
+
+	// Strategies that only trade in certain sessions are paused outside them
+	for strategyType, impl := range strategies {
+		spec := strategy.Sessions(impl)
+		if spec == "" {
+			continue
+		}
+		sessions, err := config.ParseSessions(spec)
+		if err != nil {
+			log.Printf("Warning: Ignoring trading sessions of strategy %s: %v", strategyType, err)
+			continue
+		}
+		riskManager.Sessions[string(strategyType)] = sessions
+	}
+	for key, sessions := range cfg.StrategySessions {
+		log.Printf("Trading sessions of %s: %v UTC", key, sessions)
+	}
+
+	// Fetch the configured timeframes and the ones the strategies analyze with the 5 minute klines
+	timeframes := append([]string{}, cfg.Timeframes...)
+	for _, impl := range strategies {
+		for _, interval := range strategy.Timeframes(impl) {
+			if !slices.Contains(timeframes, interval) {
+				timeframes = append(timeframes, interval)
+			}
+		}
+	}
+	sort.Strings(timeframes)
+	bybitClient.Timeframes = timeframes
+	if len(timeframes) > 0 {
+		log.Printf("Fetching additional kline timeframes: %v", timeframes)
+	}
+
+	// Restore the working state of stateful strategies so resting orders and open positions
+	// of the previous run are still managed
+	if err := strategy.LoadStates(strategy.StatePath(cfg.DataDir), strategies); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Create dashboard
+	dashboard := web.NewDashboard(portfolioManager, riskManager, marketAnalyzer)
+	// Publish circuit breaker state on the dashboard
+	dashboard.CircuitBreakers = circuitBreakers
+	dashboard.Calibrator = calibrator
+	dashboard.Shadow = shadowLedger
+	dashboard.StrategyAI = strategyAI
+	dashboard.BacktestStore = backtest.NewResultStore(backtest.ResultsPath(cfg.DataDir))
+	var bybitData bybit.DataProvider = bybitClient
+	if cfg.KlineCache {
+		bybitData = backtest.NewKlineCache(backtest.KlineCachePath(cfg.DataDir), bybitClient)
+	}
+	dashboard.HistoricalData = bybitData
+	historicalData, err := backtest.NewDataProvider(cfg.HistoricalDataSource, cfg, bybitData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create notifier
+	notifier := notifications.NewNotifier()
+
+	// Push trades to the dashboard clients as they are logged, and again once a close's PnL is known
+	portfolioManager.OnTrade = dashboard.PublishTrade
+	portfolioManager.OnTradeUpdate = dashboard.PublishTradeUpdate
+
+	// Measure positions against the limits in the reporting currency
+	riskManager.ConversionRate = portfolioManager.ConversionRate
+
+	// Push risk incidents to the notifier and the dashboard clients as soon as they are detected
+	riskManager.OnRiskEvent = func(event risk.RiskEvent) {
+		log.Printf("RISK EVENT [%s] %s %s: %s", event.Severity, event.Type, event.Subject, event.Message)
+		dashboard.Publish(web.EventRiskAlert, event)
+		notifier.SendRiskAlert(notifications.RiskAlert{
+			Type:      event.Type,
+			Severity:  event.Severity,
+			Subject:   event.Subject,
+			Message:   event.Message,
+			Timestamp: event.Timestamp.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	// Reschedule trading cycles when the rebalance interval changes on the dashboard; the other
+	// tunables are read from the shared configuration, under its lock, on every use
+	rebalanceReset := make(chan time.Duration, 1)
+	dashboard.OnConfigUpdate = func(update config.Tunables) {
+		if update.RebalanceMinutes == nil {
+			return
+		}
+		select {
+		case <-rebalanceReset: // Replace an interval the loop has not picked up yet
+		default:
+		}
+		rebalanceReset <- cfg.GetRebalanceInterval()
+	}
+
+	bot := &TradingBot{
+		Config:              cfg,
+		BybitClient:         bybitClient,
+		PortfolioManager:    portfolioManager,
+		MarketAnalyzer:      marketAnalyzer,
+		HistoricalData:      historicalData,
+		StrategyAI:          strategyAI,
+		RiskManager:         riskManager,
+		PositionSizer:       positionSizer,
+		PreTradeGate:        preTradeGate,
+		Executor:            executor,
+		CircuitBreakers:     circuitBreakers,
+		Strategies:          strategies,
+		StrategyParams:      strategyParams,
+		baseParameters:      baseParameters,
+		regimeProfiles:      make(map[string]string),
+		DCA:                 dcaStrategy,
+		PairsTrading:        pairsStrategy,
+		FundingArbitrage:    fundingStrategy,
+		Scalping:            scalpingStrategy,
+		MarketMaking:        marketMakingStrategy,
+		Ensemble:            ensembleStrategy,
+		TriangularArbitrage: triArbStrategy,
+		Calibrator:          calibrator,
+		Shadow:              shadowLedger,
+		Debouncer:           strategy.NewSignalDebouncer(cfg.SignalPersistence, cfg.SignalFlipChange),
+		Fusion:              strategy.NewSignalFusion(cfg.SignalFusion, cfg.FusionCombinedWeight, cfg.FusionVolumeWeight, cfg.FusionMinScore),
+		Dashboard:           dashboard,
+		Notifier:            notifier,
+		IsRunning:           true, // Start running by default
+		StopChan:            make(chan struct{}),
+		rebalanceReset:      rebalanceReset,
+	}
+	dashboard.StrategyDetails = bot.strategyDetails
+	return bot, nil
+}
+
+// now returns the current time of the bot, the simulated time in a replay
+func (bot *TradingBot) now() time.Time {
+	if bot.clock != nil {
+		return bot.clock()
+	}
+	return time.Now()
+}
+
+// Run starts the trading bot
+func (bot *TradingBot) Run(ctx context.Context) error {
+	log.Println("Starting trading bot...")
+
+	// Start the web dashboard in a separate goroutine
+	go func() {
+		log.Println("Starting web dashboard on port 8080...")
+		if err := bot.Dashboard.Start("8080"); err != nil && err != http.ErrServerClosed {
+			log.Printf("Dashboard server error: %v", err)
+		}
+	}()
+
+	// Start the override command handler in a separate goroutine
+	go bot.handleOverrideCommands()
+	bot.publishStatus()
+
+	// Initialize portfolio with top coins
+	if err := bot.PortfolioManager.UpdateTopCoins(ctx); err != nil {
+		return fmt.Errorf("failed to initialize portfolio: %w", err)
+	}
+
+	log.Printf("Initialized portfolio with symbols: %v", bot.PortfolioManager.Symbols)
+
+	// Resolve quote currencies and conversion rates for the reporting currency
+	if err := bot.PortfolioManager.UpdateCurrencyInfo(ctx); err != nil {
+		log.Printf("Warning: Failed to update currency info: %v", err)
+	}
+
+	// Analyze recent history so market regimes and correlations are known from the first cycle
+	if err := bot.MarketAnalyzer.WarmUp(ctx, bot.HistoricalData, bot.PortfolioManager.Symbols); err != nil {
+		log.Printf("Warning: Market analyzer warm-up: %v", err)
+	}
+
+	// Look up the markets of the configured arbitrage triangles
+	if bot.TriangularArbitrage != nil {
+		bot.resolveTriangles(ctx)
+	}
+
+	// Start the main trading loop
+	return bot.tradingLoop(ctx)
+}
+
+// handleOverrideCommands handles manual override commands from the web dashboard
+func (bot *TradingBot) handleOverrideCommands() {
+	for command := range bot.Dashboard.GetOverrideChannel() {
+		log.Printf("Received manual override command: %s", command.Command)
+
+		switch command.Command {
+		case "start":
+			if bot.RiskManager.IsHalted() {
+				log.Printf("Trading is halted (%s), use resume to restart", bot.RiskManager.HaltState.Reason)
+				continue
+			}
+			bot.IsRunning = true
+			log.Println("Trading bot started manually")
+		case "resume":
+			if err := bot.RiskManager.Resume(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			bot.IsRunning = true
+			log.Println("Trading resumed manually after halt")
+		case "stop":
+			bot.IsRunning = false
+			log.Println("Trading bot stopped manually")
+		case "rebalance":
+			// Trigger immediate rebalancing
+			log.Println("Manual rebalancing triggered")
+			// In a real implementation, you would trigger rebalancing here
+		case "emergency_stop":
+			bot.IsRunning = false
+			log.Println("Emergency stop triggered manually")
+			// Send emergency stop notification
+			bot.Notifier.SendEmergencyStopAlert("Manual emergency stop triggered")
+		default:
+			log.Printf("Unknown command: %s", command.Command)
+		}
+		bot.publishStatus()
+	}
+}
+
+// publishStatus pushes whether the bot is trading to the dashboard clients
+func (bot *TradingBot) publishStatus() {
+	bot.Dashboard.PublishStatus(web.BotStatus{
+		Running:    bot.IsRunning,
+		Halted:     bot.RiskManager.IsHalted(),
+		HaltReason: bot.RiskManager.HaltState.Reason,
+	})
+}
+
+// tradingLoop runs the main trading loop
+func (bot *TradingBot) tradingLoop(ctx context.Context) error {
+	ticker := time.NewTicker(bot.PortfolioManager.RebalanceInterval)
+	defer ticker.Stop()
+
+	// Optionally check for weight drift between scheduled cycles
+	var driftChan <-chan time.Time
+	if bot.Config.RebalanceDriftThreshold > 0 {
+		driftTicker := time.NewTicker(time.Duration(bot.Config.DriftCheckMinutes) * time.Minute)
+		defer driftTicker.Stop()
+		driftChan = driftTicker.C
+	}
+
+	// Optionally follow trailing stops with live prices between scheduled cycles
+	var trailingChan <-chan time.Time
+	if bot.Config.TrailingStopCheckSeconds > 0 {
+		trailingTicker := time.NewTicker(time.Duration(bot.Config.TrailingStopCheckSeconds) * time.Second)
+		defer trailingTicker.Stop()
+		trailingChan = trailingTicker.C
+	}
+
+	// Scalp order books on their own fast schedule
+	var scalpChan <-chan time.Time
+	if bot.Scalping != nil {
+		scalpTicker := time.NewTicker(time.Duration(bot.Config.ScalpCheckSeconds) * time.Second)
+		defer scalpTicker.Stop()
+		scalpChan = scalpTicker.C
+	}
+
+	// Refresh market making quotes on their own schedule
+	var mmChan <-chan time.Time
+	if len(bot.Config.MMSymbols) > 0 {
+		mmTicker := time.NewTicker(time.Duration(bot.Config.MMCheckSeconds) * time.Second)
+		defer mmTicker.Stop()
+		mmChan = mmTicker.C
+	}
+
+	// Scan arbitrage triangles on their own fast schedule
+	var triArbChan <-chan time.Time
+	if bot.TriangularArbitrage != nil && len(bot.TriangularArbitrage.Triangles) > 0 {
+		triArbTicker := time.NewTicker(time.Duration(bot.Config.TriArbCheckSeconds) * time.Second)
+		defer triArbTicker.Stop()
+		triArbChan = triArbTicker.C
+	}
+
+	// Run initial cycle
+	if err := bot.runTradingCycle(ctx); err != nil {
+		log.Printf("Error in initial trading cycle: %v", err)
+	}
+	bot.Dashboard.SnapshotMetrics()
+
+	// Listen for interrupt signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Context cancelled, shutting down...")
+			bot.saveStrategyStates()
+			return nil
+		case <-sigChan:
+			log.Println("Received interrupt signal, shutting down...")
+			bot.saveStrategyStates()
+			return nil
+		case <-ticker.C:
+			// Check if bot is running (manual override)
+			if bot.IsRunning {
+				log.Println("Running trading cycle...")
+				if err := bot.runTradingCycle(ctx); err != nil {
+					log.Printf("Error in trading cycle: %v", err)
+				}
+			} else {
+				log.Println("Trading bot is stopped (manual override), skipping trading cycle...")
+			}
+		case interval := <-bot.rebalanceReset:
+			log.Printf("Trading cycles rescheduled every %s", interval)
+			bot.PortfolioManager.RebalanceInterval = interval
+			ticker.Reset(interval)
+		case <-driftChan:
+			if bot.IsRunning {
+				bot.checkDriftRebalance(ctx)
+			}
+		case <-trailingChan:
+			if bot.IsRunning {
+				bot.checkTrailingStops(ctx)
+			}
+		case <-scalpChan:
+			if bot.IsRunning && !bot.dailyLossBreached(ctx, nil) {
+				bot.runScalping(ctx)
+			}
+		case <-mmChan:
+			if bot.IsRunning && !bot.dailyLossBreached(ctx, nil) {
+				bot.runMarketMaking(ctx)
+			}
+		case <-triArbChan:
+			if bot.IsRunning && !bot.dailyLossBreached(ctx, nil) {
+				bot.runTriangularArbitrage(ctx)
+			}
+		case <-bot.StopChan:
+			log.Println("Received stop signal, shutting down...")
+			bot.saveStrategyStates()
+			return nil
+		}
+
+		// Snapshot the metrics pushed to dashboard clients here, where the portfolio changes
+		bot.Dashboard.SnapshotMetrics()
+	}
+}
+
+// checkDriftRebalance rebalances early when weights drift beyond the threshold or the symbol set changes
+func (bot *TradingBot) checkDriftRebalance(ctx context.Context) {
+	if bot.CircuitBreakers.State(risk.EndpointMarketData) == "open" {
+		return
+	}
+
+	// Refresh prices for the current holdings
+	currentPrices := make(map[string]float64)
+	for symbol := range bot.PortfolioManager.Holdings {
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			price, err := bot.BybitClient.GetTickerPrice(ctx, symbol)
+			if err != nil {
+				return err
+			}
+			currentPrices[symbol], _ = price.Float64()
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get price for %s during drift check: %v", symbol, err)
+		}
+	}
+
+	var triggered bool
+	var reason string
+	err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+		var err error
+		triggered, reason, err = bot.PortfolioManager.CheckRebalanceTrigger(ctx, currentPrices)
+		return err
+	})
+	if err != nil {
+		log.Printf("Warning: Drift check failed: %v", err)
+		return
+	}
+	if !triggered {
+		return
+	}
+
+	log.Printf("Drift-triggered rebalance: %s", reason)
+	err = bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+		return bot.PortfolioManager.RebalancePortfolio(ctx)
+	})
+	if err != nil {
+		log.Printf("Warning: Drift-triggered rebalance failed: %v", err)
+	}
+}
+
+// checkLiquidationRisk refreshes derivatives positions, warns about positions close to
+// liquidation and reduces the ones inside the deleverage buffer
+func (bot *TradingBot) checkLiquidationRisk(ctx context.Context) {
+	var positions []bybit.DerivativePosition
+	err := bot.CircuitBreakers.Call(risk.EndpointAccount, func() error {
+		var err error
+		positions, err = bot.BybitClient.GetDerivativePositions(ctx)
+		return err
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to get derivatives positions: %v", err)
+		return
+	}
+
+	bot.RiskManager.UpdateLiquidationRisks(positions)
+
+	for _, action := range bot.RiskManager.CheckLiquidationRisk() {
+		log.Printf("  %s", action.Message)
+		if !action.Deleverage {
+			continue
+		}
+
+		quantity := decimal.NewFromFloat(action.ReduceQuantity)
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.ReduceDerivativePosition(ctx, action.Symbol, action.Side, quantity)
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to deleverage %s: %v", action.Symbol, err)
+			bot.Notifier.SendEmergencyStopAlert(fmt.Sprintf("%s (deleveraging failed: %v)", action.Message, err))
+			continue
+		}
+		bot.Notifier.SendEmergencyStopAlert(action.Message)
+	}
+}
+
+// checkTrailingStops refreshes prices of the tracked positions and applies them to the trailing stops
+func (bot *TradingBot) checkTrailingStops(ctx context.Context) {
+	if bot.CircuitBreakers.State(risk.EndpointMarketData) == "open" || len(bot.RiskManager.Positions) == 0 {
+		return
+	}
+
+	currentPrices := make(map[string]float64)
+	for symbol := range bot.RiskManager.Positions {
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			price, err := bot.BybitClient.GetTickerPrice(ctx, symbol)
+			if err != nil {
+				return err
+			}
+			currentPrices[symbol], _ = price.Float64()
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get price for %s during trailing stop check: %v", symbol, err)
+		}
+	}
+
+	// Close breached positions before halting, which would leave them unprotected
+	bot.applyTrailingStops(ctx, currentPrices)
+	bot.dailyLossBreached(ctx, currentPrices)
+}
+
+// applyTrailingStops moves trailing stops with the given prices and closes breached positions
+func (bot *TradingBot) applyTrailingStops(ctx context.Context, currentPrices map[string]float64) {
+	for _, trigger := range bot.RiskManager.UpdateTrailingStops(currentPrices) {
+		log.Printf("  %s", trigger.Message)
+		if err := bot.closePosition(ctx, trigger.Symbol, trigger.Quantity, trigger.EntryPrice, trigger.Price, "TRAILING_STOP", trigger.Message); err != nil {
+			log.Printf("Warning: Failed to close %s on trailing stop: %v", trigger.Symbol, err)
+		}
+	}
+}
+
+// closePosition sends a market sell for a long position, or buys back a short position. The
+// quantity is capped at the bot's holdings so the order can never flip the position.
+func (bot *TradingBot) closePosition(ctx context.Context, symbol string, quantity, entryPrice, price float64, strategyName, reason string) error {
+	action, held := "SELL", bot.PortfolioManager.Holdings[symbol]
+	short := held < 0
+	if short {
+		action, held = "COVER", -held
+	}
+	if quantity > held {
+		quantity = held
+	}
+	if quantity <= 0 {
+		bot.RiskManager.RemovePosition(symbol)
+		return nil
+	}
+
+	signal := bybit.TradeSignal{Symbol: symbol, Action: action, Strength: 1.0, Reason: reason}
+	if _, err := bot.Executor.ExecuteSignal(ctx, signal, quantity, price); err != nil {
+		return fmt.Errorf("failed to place close order: %w", err)
+	}
+
+	bot.PortfolioManager.LogTrade(symbol, action, quantity, price, strategyName, 1.0, reason)
+	bot.PortfolioManager.UpdateTradePnL(symbol, entryPrice, price, quantity, !short)
+	if pos, tracked := bot.RiskManager.Positions[symbol]; tracked {
+		mae, mfe := pos.Excursions(price)
+		bot.PortfolioManager.RecordTradeExcursion(symbol, mae, mfe, pos.OpenedAt)
+	}
+	bot.RiskManager.RemovePosition(symbol)
+	bot.notifyFill(strategyName, "", symbol, action, quantity, price)
+	signedQuantity := quantity
+	if short {
+		signedQuantity = -quantity
+	}
+	bot.notifyPositionClosed(strategyName, symbol, signedQuantity, entryPrice, price, reason)
+
+	bot.Notifier.SendTradeAlert(notifications.TradeAlert{
+		Symbol:     symbol,
+		Action:     action,
+		Quantity:   quantity,
+		Price:      price,
+		Strategy:   strategyName,
+		Confidence: 1.0,
+		Reason:     reason,
+		Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
+	})
+
+	return nil
+}
+
+// orderSide maps a strategy's BUY or SELL signal to the order that is placed and caps its
+// quantity. A SELL sells spot inventory or, without inventory, opens a linear perpetual short if
+// short selling is enabled; a BUY buys back an open short before buying spot. An empty side
+// means the signal can not be traded.
+func (bot *TradingBot) orderSide(symbol, action string, quantity float64) (string, float64) {
+	held := bot.PortfolioManager.Holdings[symbol]
+	switch {
+	case action == "BUY" && held < 0:
+		return "COVER", math.Min(quantity, -held)
+	case action == "BUY":
+		return "BUY", quantity
+	case held > 0:
+		return "SELL", math.Min(quantity, held)
+	case bot.Config.ShortSelling:
+		return "SHORT", quantity
+	}
+	return "", 0
+}
+
+// checkPreTrade runs the pre-trade gate for an order of a strategy, looking up the instrument's trading rules
+func (bot *TradingBot) checkPreTrade(ctx context.Context, strategyName, symbol, side string, quantity, price float64) risk.PreTradeDecision {
+	var instrument *bybit.InstrumentInfo
+	var balance *bybit.SymbolBalance
+	// Replays check orders against the limits only
+	if !bot.offline {
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			var err error
+			instrument, err = bot.BybitClient.GetInstrumentInfo(ctx, symbol)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Instrument rules unavailable for %s: %v", symbol, err)
+		}
+
+		err = bot.CircuitBreakers.Call(risk.EndpointAccount, func() error {
+			var err error
+			balance, err = bot.BybitClient.GetAvailableBalance(ctx, symbol)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Available balance unavailable for %s: %v", symbol, err)
+		}
+	}
+
+	rate, _ := bot.PortfolioManager.ConversionRate(symbol)
+	return bot.PreTradeGate.Check(risk.OrderRequest{
+		Symbol:         symbol,
+		Side:           side,
+		Quantity:       quantity,
+		Price:          price,
+		Strategy:       strategyName,
+		ConversionRate: rate,
+		Instrument:     instrument,
+		Balance:        balance,
+	})
+}
+
+// useSymbolParameters switches a strategy to the symbol's parameters from the parameter file,
+// including the profiles of the symbol's current market regime, and resets overrides of the
+// previously analyzed symbol
+func (bot *TradingBot) useSymbolParameters(strategyType strategy.StrategyType, symbol string) {
+	base, exists := bot.baseParameters[strategyType]
+	if !exists {
+		return
+	}
+	params, profiles := bot.StrategyParams.ForRegime(strategyType, symbol, bot.MarketAnalyzer.GetMarketRegime(symbol), base)
+	if err := bot.Strategies[strategyType].SetParameters(params); err != nil {
+		log.Printf("Warning: Failed to apply %s parameters for %s: %v", strategyType, symbol, err)
+		return
+	}
+
+	// Log when the symbol's regime switches the strategy to other profiles
+	key := string(strategyType) + "/" + symbol
+	active := strings.Join(profiles, ", ")
+	if active != bot.regimeProfiles[key] {
+		if active == "" {
+			log.Printf("  %s parameters for %s switched back from regime profiles %s", strategyType, symbol, bot.regimeProfiles[key])
+		} else {
+			log.Printf("  %s parameters for %s switched to regime profiles %s", strategyType, symbol, active)
+		}
+		bot.regimeProfiles[key] = active
+	}
+}
+
+// strategyDetails returns the strategy the last selection picked for a symbol, the ensemble if
+// it votes on every symbol, and the parameters the strategy trades the symbol with
+func (bot *TradingBot) strategyDetails(symbol string) (strategy.StrategyType, map[string]float64, bool) {
+	selected, ok := bot.StrategyAI.LastSelection(symbol)
+	if !ok {
+		return "", nil, false
+	}
+	if bot.Ensemble != nil {
+		selected = strategy.Ensemble
+	}
+
+	if base, exists := bot.baseParameters[selected]; exists {
+		params, _ := bot.StrategyParams.ForRegime(selected, symbol, bot.MarketAnalyzer.GetMarketRegime(symbol), base)
+		return selected, params, true
+	}
+	impl, exists := bot.Strategies[selected]
+	if !exists {
+		return selected, nil, true
+	}
+	params := make(map[string]float64)
+	for name, value := range impl.GetParameters() {
+		params[name] = value
+	}
+	return selected, params, true
+}
+
+// runDCA places the scheduled dollar-cost averaging buys that are due. DCA buys pass the same
+// pause, pre-trade and trade limit checks as strategy orders.
+func (bot *TradingBot) runDCA(ctx context.Context, marketData map[string]*bybit.MarketData) {
+	for _, symbol := range bot.Config.DCASymbols {
+		// Use the cycle's market data, fetching symbols that are not traded by the strategies
+		data, exists := marketData[symbol]
+		if !exists {
+			err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+				var err error
+				data, err = bot.BybitClient.GetMarketData(ctx, symbol)
+				return err
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to get market data for DCA symbol %s: %v", symbol, err)
+				continue
+			}
+		}
+		if len(data.Kline) == 0 {
+			continue
+		}
+
+		bot.useSymbolParameters(strategy.DCA, symbol)
+		signal := bot.DCA.Analyze(data)
+		if signal.Action != "BUY" {
+			continue
+		}
+
+		price, _ := data.Kline[len(data.Kline)-1].Close.Float64()
+		amount, _ := bot.DCA.BuyAmount(data)
+		quantity := amount / price
+		log.Printf("  DCA %s: %s", symbol, signal.Reason)
+
+		if paused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategy.DCA), bot.now()); paused {
+			log.Printf("  Skipping DCA %s: %s", symbol, reason)
+			continue
+		}
+
+		decision := bot.checkPreTrade(ctx, string(strategy.DCA), symbol, signal.Action, quantity, price)
+		if !decision.Approved {
+			log.Printf("  Rejected DCA %s: %v", symbol, decision.Rejection)
+			continue
+		}
+		if decision.Resized {
+			log.Printf("  Resized DCA %s from %.6f to %.6f: %v", symbol, quantity, decision.Quantity, decision.Adjustments)
+			quantity = decision.Quantity
+		}
+
+		if err := bot.PortfolioManager.CheckTradeLimit(symbol); err != nil {
+			log.Printf("  Skipping DCA %s: %v", symbol, err)
+			continue
+		}
+
+		filled, err := bot.Executor.ExecuteSignal(ctx, signal, quantity, price)
+		if err != nil {
+			log.Printf("Warning: Failed to execute DCA for %s: %v", symbol, err)
+			continue
+		}
+		quantity, price = filled.Quantity, filled.Price
+		bot.notifyFill(string(strategy.DCA), filled.OrderID, symbol, signal.Action, quantity, price)
+
+		if err := bot.DCA.Execute(signal); err != nil {
+			log.Printf("Warning: Failed to execute DCA for %s: %v", symbol, err)
+			continue
+		}
+
+		bot.PortfolioManager.LogTrade(symbol, signal.Action, quantity, price, string(strategy.DCA), signal.Strength, signal.Reason)
+		bot.Notifier.SendTradeAlert(notifications.TradeAlert{
+			Symbol:     symbol,
+			Action:     signal.Action,
+			Quantity:   quantity,
+			Price:      price,
+			Strategy:   string(strategy.DCA),
+			Confidence: signal.Strength,
+			Reason:     signal.Reason,
+			Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
+		})
+	}
+}
+
+// runPairsTrading analyzes the configured pairs and executes both legs of open and close
+// signals as linear perpetual orders, so the short leg does not need spot holdings
+func (bot *TradingBot) runPairsTrading(ctx context.Context, marketData map[string]*bybit.MarketData) {
+	for _, pair := range bot.PairsTrading.Pairs {
+		// Make sure the analyzer has price history for both legs
+		for _, symbol := range []string{pair.SymbolA, pair.SymbolB} {
+			if _, exists := marketData[symbol]; exists {
+				continue
+			}
+			var data *bybit.MarketData
+			err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+				var err error
+				data, err = bot.BybitClient.GetMarketData(ctx, symbol)
+				return err
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to get market data for pair symbol %s: %v", symbol, err)
+				continue
+			}
+			marketData[symbol] = data
+			bot.MarketAnalyzer.AnalyzeMarketConditions(ctx, symbol, data)
+		}
+
+		signal := bot.PairsTrading.AnalyzePair(pair)
+		log.Printf("  Pair %s: %s (z %.2f) - %s", pair.Key(), signal.Action, signal.ZScore, signal.Reason)
+		if signal.Action == strategy.PairHold {
+			continue
+		}
+
+		if signal.Action != strategy.PairClose {
+			paused := false
+			for _, symbol := range []string{pair.SymbolA, pair.SymbolB} {
+				if isPaused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategy.PairsTrading), bot.now()); isPaused {
+					log.Printf("  Skipping pair %s: %s", pair.Key(), reason)
+					paused = true
+					break
+				}
+			}
+			if paused {
+				continue
+			}
+		}
+
+		signal, err := bot.executePairLegs(ctx, signal)
+		if err != nil {
+			log.Printf("Warning: Failed to execute pair %s: %v", pair.Key(), err)
+			continue
+		}
+
+		if signal.Action == strategy.PairClose {
+			if pos, exists := bot.PairsTrading.Positions[pair.Key()]; exists {
+				prices := map[string]float64{}
+				for _, leg := range signal.Legs {
+					prices[leg.Symbol] = leg.Price
+				}
+				log.Printf("  Closed pair %s with spread PnL %.2f", pair.Key(), pos.PnL(prices))
+			}
+		}
+		bot.PairsTrading.ExecutePair(signal)
+
+		for _, leg := range signal.Legs {
+			bot.Notifier.SendTradeAlert(notifications.TradeAlert{
+				Symbol:     leg.Symbol,
+				Action:     leg.Side,
+				Quantity:   leg.Quantity,
+				Price:      leg.Price,
+				Strategy:   string(strategy.PairsTrading),
+				Confidence: 1.0,
+				Reason:     fmt.Sprintf("%s %s: %s", signal.Action, pair.Key(), signal.Reason),
+				Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+}
+
+// executePairLegs places both legs of a pair signal and records them in the trade log. The legs
+// of an opening trade pass the pre-trade gate and the trade limits before either is placed, and
+// if the second leg fails the first is unwound so no unhedged position is left behind. Returns
+// the signal with the quantities that were placed.
+func (bot *TradingBot) executePairLegs(ctx context.Context, signal strategy.PairSignal) (strategy.PairSignal, error) {
+	closing := signal.Action == strategy.PairClose
+	reason := fmt.Sprintf("%s %s: %s", signal.Action, signal.Pair.Key(), signal.Reason)
+
+	// Perpetual legs open longs and shorts, and closing legs sell longs and cover shorts
+	legs := make([]orderLeg, len(signal.Legs))
+	for i, leg := range signal.Legs {
+		side := leg.Side
+		switch {
+		case closing && side == "BUY":
+			side = "COVER"
+		case !closing && side == "SELL":
+			side = "SHORT"
+		}
+		legs[i] = orderLeg{Symbol: leg.Symbol, Side: side, Quantity: leg.Quantity, Price: leg.Price}
+	}
+	if !closing {
+		var err error
+		if legs, err = bot.gateHedgedLegs(ctx, string(strategy.PairsTrading), legs); err != nil {
+			return signal, err
+		}
+	}
+
+	placed := make([]strategy.PairLeg, len(signal.Legs))
+	for i, leg := range legs {
+		quantity := decimal.NewFromFloat(leg.Quantity).Truncate(3) // Linear perpetual quantity precision
+		leg.Quantity, _ = quantity.Float64()
+		side := signal.Legs[i].Side
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			if closing {
+				// Closing a leg reduces the position opened with the opposite side
+				positionSide := "BUY"
+				if side == "BUY" {
+					positionSide = "SELL"
+				}
+				return bot.BybitClient.ReduceDerivativePosition(ctx, leg.Symbol, positionSide, quantity)
+			}
+			return bot.BybitClient.PlaceDerivativeOrder(ctx, leg.Symbol, side, quantity)
+		})
+		if err != nil {
+			if !closing {
+				for j, filled := range placed[:i] {
+					unwind := decimal.NewFromFloat(filled.Quantity)
+					if err := bot.BybitClient.ReduceDerivativePosition(ctx, filled.Symbol, filled.Side, unwind); err != nil {
+						log.Printf("Warning: Failed to unwind pair leg %s: %v", filled.Symbol, err)
+						continue
+					}
+					unwound := legs[j]
+					unwound.Quantity, unwound.Side = filled.Quantity, reverseSide(unwound.Side)
+					bot.logLeg(string(strategy.PairsTrading), "Unwind "+reason, unwound)
+				}
+			}
+			return signal, err
+		}
+		bot.logLeg(string(strategy.PairsTrading), reason, leg)
+		placed[i] = strategy.PairLeg{Symbol: leg.Symbol, Side: side, Quantity: leg.Quantity, Price: leg.Price}
+	}
+
+	signal.Legs = placed
+	return signal, nil
+}
+
+// runFundingArbitrage compares each configured symbol's perpetual funding rate with spot and
+// opens or closes the spot-long/perpetual-short hedge
+func (bot *TradingBot) runFundingArbitrage(ctx context.Context) {
+	for _, symbol := range bot.Config.FundingArbSymbols {
+		var rate *bybit.FundingRate
+		var spotPrice decimal.Decimal
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			var err error
+			if rate, err = bot.BybitClient.GetFundingRate(ctx, symbol); err != nil {
+				return err
+			}
+			spotPrice, err = bot.BybitClient.GetTickerPrice(ctx, symbol)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get funding data for %s: %v", symbol, err)
+			continue
+		}
+		bot.FundingArbitrage.Rates[symbol] = rate
+
+		price, _ := spotPrice.Float64()
+		bot.useSymbolParameters(strategy.FundingArbitrage, symbol)
+		signal := bot.FundingArbitrage.AnalyzeFunding(rate, price)
+		log.Printf("  Funding %s: %s - %s", symbol, signal.Action, signal.Reason)
+		if signal.Action == strategy.FundingHold {
+			continue
+		}
+
+		if signal.Action == strategy.FundingOpen {
+			if paused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategy.FundingArbitrage), bot.now()); paused {
+				log.Printf("  Skipping funding arbitrage %s: %s", symbol, reason)
+				continue
+			}
+		}
+
+		signal, err = bot.executeFundingLegs(ctx, signal)
+		if err != nil {
+			log.Printf("Warning: Failed to execute funding arbitrage for %s: %v", symbol, err)
+			continue
+		}
+		bot.FundingArbitrage.ExecuteFunding(signal)
+
+		bot.Notifier.SendTradeAlert(notifications.TradeAlert{
+			Symbol:     symbol,
+			Action:     signal.Action,
+			Quantity:   signal.Quantity,
+			Price:      signal.SpotPrice,
+			Strategy:   string(strategy.FundingArbitrage),
+			Confidence: 1.0,
+			Reason:     signal.Reason,
+			Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
+		})
+	}
+}
+
+// executeFundingLegs places the spot and perpetual legs of a funding signal and records them in
+// the trade log. Opening legs pass the pre-trade gate and the trade limits before either is
+// placed; spot is bought first and sold again if the perpetual short fails, so no unhedged
+// position is left behind. A close whose spot sale fails after the perpetual was bought back
+// records the closed perpetual in the strategy, so the next close only retries the spot.
+// Returns the signal with the quantity that was placed.
+func (bot *TradingBot) executeFundingLegs(ctx context.Context, signal strategy.FundingSignal) (strategy.FundingSignal, error) {
+	strategyName := string(strategy.FundingArbitrage)
+	spot := orderLeg{Symbol: signal.Symbol, Side: "BUY", Quantity: signal.Quantity, Price: signal.SpotPrice}
+	perp := orderLeg{Symbol: signal.Symbol, Side: "SHORT", Quantity: signal.Quantity, Price: signal.PerpPrice}
+	if signal.Action == strategy.FundingClose {
+		spot.Side, perp.Side = "SELL", "COVER"
+	} else {
+		legs, err := bot.gateHedgedLegs(ctx, strategyName, []orderLeg{spot, perp})
+		if err != nil {
+			return signal, err
+		}
+		signal.Quantity = math.Min(legs[0].Quantity, legs[1].Quantity)
+	}
+
+	quantity := decimal.NewFromFloat(signal.Quantity).Truncate(3) // Linear perpetual quantity precision
+	if quantity.IsZero() {
+		return signal, fmt.Errorf("quantity %.8f rounds to zero at the perpetual's precision", signal.Quantity)
+	}
+	signal.Quantity, _ = quantity.Float64()
+	spot.Quantity, perp.Quantity = signal.Quantity, signal.Quantity
+
+	if signal.Action == strategy.FundingClose {
+		if pos, exists := bot.FundingArbitrage.Positions[signal.Symbol]; !exists || !pos.PerpClosed {
+			err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+				return bot.BybitClient.ReduceDerivativePosition(ctx, signal.Symbol, "SELL", quantity)
+			})
+			if err != nil {
+				return signal, err
+			}
+			bot.logLeg(strategyName, signal.Reason, perp)
+		}
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.PlaceOrder(ctx, bybit.Order{Symbol: signal.Symbol, Side: "SELL", Type: "MARKET", Quantity: quantity})
+		})
+		if err != nil {
+			bot.FundingArbitrage.MarkPerpClosed(signal.Symbol)
+			return signal, fmt.Errorf("perpetual closed, spot sale will be retried: %w", err)
+		}
+		bot.logLeg(strategyName, signal.Reason, spot)
+		return signal, nil
+	}
+
+	err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+		return bot.BybitClient.PlaceOrder(ctx, bybit.Order{Symbol: signal.Symbol, Side: "BUY", Type: "MARKET", Quantity: quantity})
+	})
+	if err != nil {
+		return signal, err
+	}
+	bot.logLeg(strategyName, signal.Reason, spot)
+	err = bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+		return bot.BybitClient.PlaceDerivativeOrder(ctx, signal.Symbol, "SELL", quantity)
+	})
+	if err != nil {
+		unwindErr := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.PlaceOrder(ctx, bybit.Order{Symbol: signal.Symbol, Side: "SELL", Type: "MARKET", Quantity: quantity})
+		})
+		if unwindErr != nil {
+			log.Printf("Warning: Failed to unwind spot leg of %s: %v", signal.Symbol, unwindErr)
+		} else {
+			spot.Side = reverseSide(spot.Side)
+			bot.logLeg(strategyName, "Unwind "+signal.Reason, spot)
+		}
+		return signal, err
+	}
+	bot.logLeg(strategyName, signal.Reason, perp)
+	return signal, nil
+}
+
+// runScalping applies fills of the resting scalping orders, cancels stale ones and quotes
+// symbols whose order book is leaning to one side
+func (bot *TradingBot) runScalping(ctx context.Context) {
+	now := time.Now()
+
+	// Manage resting orders
+	for orderID, order := range bot.Scalping.Orders {
+		var status *bybit.OrderStatus
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			var err error
+			status, err = bot.BybitClient.GetOrderStatus(ctx, order.Symbol, orderID)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get scalping order %s: %v", orderID, err)
+			continue
+		}
+
+		filled, _ := status.FilledQuantity.Float64()
+		avgPrice, _ := status.AvgPrice.Float64()
+		if quantity, price := bot.Scalping.UpdateOrder(orderID, filled, avgPrice); quantity > 0 {
+			if err := bot.PortfolioManager.RecordFill(orderID, quantity, price); err != nil {
+				log.Printf("Warning: %v", err)
+			} else {
+				bot.notifyFill(string(strategy.OrderBookScalping), orderID, order.Symbol, order.Side, quantity, price)
+			}
+		}
+
+		switch status.Status {
+		case bybit.OrderStatusFilled:
+			bot.Scalping.RemoveOrder(orderID)
+		case bybit.OrderStatusCancelled, bybit.OrderStatusRejected:
+			bot.PortfolioManager.CancelOrderRemainder(orderID)
+			bot.Scalping.RemoveOrder(orderID)
+		default:
+			if stale, reason := bot.Scalping.IsStale(order, now); stale {
+				err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+					return bot.BybitClient.CancelSpotOrder(ctx, order.Symbol, orderID)
+				})
+				if err != nil {
+					log.Printf("Warning: Failed to cancel scalping order %s: %v", orderID, err)
+					continue
+				}
+				log.Printf("  Cancelled scalping %s order %s for %s: %s", order.Side, orderID, order.Symbol, reason)
+				bot.PortfolioManager.CancelOrderRemainder(orderID)
+				bot.Scalping.RemoveOrder(orderID)
+			}
+		}
+	}
+
+	// Quote symbols without a resting order
+	for _, symbol := range bot.Config.ScalpSymbols {
+		var book *bybit.OrderBook
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			var err error
+			book, err = bot.BybitClient.GetOrderBook(ctx, symbol, int(bot.Scalping.Parameters["depth_levels"]))
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get order book for %s: %v", symbol, err)
+			continue
+		}
+
+		bot.useSymbolParameters(strategy.OrderBookScalping, symbol)
+		signal := bot.Scalping.AnalyzeBook(book)
+		if signal.Action == "HOLD" {
+			continue
+		}
+
+		if paused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategy.OrderBookScalping), now); paused {
+			log.Printf("  Skipping scalping %s: %s", symbol, reason)
+			continue
+		}
+		decision := bot.checkPreTrade(ctx, string(strategy.OrderBookScalping), symbol, signal.Action, signal.Quantity, signal.Price)
+		if !decision.Approved {
+			log.Printf("  Rejected scalping %s %s: %v", signal.Action, symbol, decision.Rejection)
+			continue
+		}
+		signal.Quantity = decision.Quantity
+
+		var orderID string
+		err = bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			var err error
+			orderID, err = bot.BybitClient.PlaceLimitOrder(ctx, symbol, signal.Action,
+				decimal.NewFromFloat(signal.Quantity), decimal.NewFromFloat(signal.Price), true)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to place scalping order for %s: %v", symbol, err)
+			continue
+		}
+
+		log.Printf("  Scalping %s %.6f %s at %.4f: %s", signal.Action, signal.Quantity, symbol, signal.Price, signal.Reason)
+		bot.Scalping.TrackOrder(orderID, signal)
+		bot.PortfolioManager.TrackOrder(orderID, symbol, signal.Action, signal.Quantity,
+			string(strategy.OrderBookScalping), math.Abs(signal.Imbalance), signal.Reason)
+	}
+}
+
+// runMarketMaking applies fills of the resting market making quotes, sells inventory above the
+// limit when hedging is on and keeps a bid and an ask on each configured symbol, replacing quotes
+// the model's prices have moved away from
+func (bot *TradingBot) runMarketMaking(ctx context.Context) {
+	now := time.Now()
+
+	// Apply fills and drop finished quotes
+	for orderID, order := range bot.MarketMaking.Orders {
+		var status *bybit.OrderStatus
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			var err error
+			status, err = bot.BybitClient.GetOrderStatus(ctx, order.Symbol, orderID)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get market making order %s: %v", orderID, err)
+			continue
+		}
+
+		filled, _ := status.FilledQuantity.Float64()
+		avgPrice, _ := status.AvgPrice.Float64()
+		if quantity, price := bot.MarketMaking.UpdateOrder(orderID, filled, avgPrice); quantity > 0 {
+			if err := bot.PortfolioManager.RecordFill(orderID, quantity, price); err != nil {
+				log.Printf("Warning: %v", err)
+			} else {
+				bot.notifyFill(string(strategy.MarketMaking), orderID, order.Symbol, order.Side, quantity, price)
+			}
+		}
+
+		switch status.Status {
+		case bybit.OrderStatusFilled:
+			bot.MarketMaking.RemoveOrder(orderID)
+		case bybit.OrderStatusCancelled, bybit.OrderStatusRejected:
+			bot.PortfolioManager.CancelOrderRemainder(orderID)
+			bot.MarketMaking.RemoveOrder(orderID)
+		}
+	}
+
+	for _, symbol := range bot.Config.MMSymbols {
+		var book *bybit.OrderBook
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			var err error
+			book, err = bot.BybitClient.GetOrderBook(ctx, symbol, 1)
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to get order book for %s: %v", symbol, err)
+			continue
+		}
+
+		bot.useSymbolParameters(strategy.MarketMaking, symbol)
+
+		// Sell inventory above the limit at market
+		if quantity := bot.MarketMaking.HedgeQuantity(symbol, book.BestBid()); quantity > 0 {
+			reason := "Market making inventory above limit"
+			signal := bybit.TradeSignal{Symbol: symbol, Action: "SELL", Strength: 1.0, Reason: reason}
+			filled, err := bot.Executor.ExecuteSignal(ctx, signal, quantity, book.BestBid())
+			if err != nil {
+				log.Printf("Warning: Failed to hedge market making inventory of %s: %v", symbol, err)
+			} else {
+				log.Printf("  Hedged %.6f %s of market making inventory at %.4f", filled.Quantity, symbol, filled.Price)
+				bot.MarketMaking.RecordHedge(symbol, filled.Quantity)
+				bot.PortfolioManager.LogTrade(symbol, "SELL", filled.Quantity, filled.Price, string(strategy.MarketMaking), 1.0, reason)
+				bot.notifyFill(string(strategy.MarketMaking), filled.OrderID, symbol, "SELL", filled.Quantity, filled.Price)
+			}
+		}
+
+		targets, quote := bot.MarketMaking.PlanQuotes(book, now)
+		targetBySide := make(map[string]strategy.QuoteTarget)
+		for _, target := range targets {
+			targetBySide[target.Side] = target
+		}
+
+		// Cancel quotes whose side is no longer quoted or whose price is stale
+		for _, order := range bot.MarketMaking.SymbolOrders(symbol) {
+			refresh, reason := bot.MarketMaking.NeedsRefresh(order, targetBySide[order.Side])
+			if !refresh {
+				delete(targetBySide, order.Side)
+				continue
+			}
+			err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+				return bot.BybitClient.CancelSpotOrder(ctx, symbol, order.OrderID)
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to cancel market making order %s: %v", order.OrderID, err)
+				// Keep the side occupied until the quote is gone
+				delete(targetBySide, order.Side)
+				continue
+			}
+			log.Printf("  Cancelled market making %s order %s for %s: %s", order.Side, order.OrderID, symbol, reason)
+			bot.PortfolioManager.CancelOrderRemainder(order.OrderID)
+			bot.MarketMaking.RemoveOrder(order.OrderID)
+		}
+
+		// Place the quotes of the sides without a resting order
+		for _, target := range targets {
+			if _, open := targetBySide[target.Side]; !open {
+				continue
+			}
+			if paused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategy.MarketMaking), now); paused {
+				log.Printf("  Skipping market making %s: %s", symbol, reason)
+				break
+			}
+			decision := bot.checkPreTrade(ctx, string(strategy.MarketMaking), symbol, target.Side, target.Quantity, target.Price)
+			if !decision.Approved {
+				log.Printf("  Rejected market making %s %s: %v", target.Side, symbol, decision.Rejection)
+				continue
+			}
+			target.Quantity = decision.Quantity
+
+			var orderID string
+			err = bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+				var err error
+				orderID, err = bot.BybitClient.PlaceLimitOrder(ctx, symbol, target.Side,
+					decimal.NewFromFloat(target.Quantity), decimal.NewFromFloat(target.Price), true)
+				return err
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to place market making order for %s: %v", symbol, err)
+				continue
+			}
+
+			reason := fmt.Sprintf("Market making %s quote, reservation %.4f, spread %.4f", strings.ToLower(target.Side), quote.ReservationPrice, quote.Spread)
+			log.Printf("  Market making %s %.6f %s at %.4f", target.Side, target.Quantity, symbol, target.Price)
+			bot.MarketMaking.TrackOrder(orderID, target)
+			bot.PortfolioManager.TrackOrder(orderID, symbol, target.Side, target.Quantity,
+				string(strategy.MarketMaking), 1.0, reason)
+		}
+	}
+}
+
+// runShadowStrategies analyzes every symbol with the shadow strategies and records their signals
+// as hypothetical fills of a fixed notional in the shadow ledger
+func (bot *TradingBot) runShadowStrategies(marketData map[string]*bybit.MarketData) {
+	shadowStrategies := make([]strategy.StrategyType, 0, len(bot.StrategyAI.Shadow))
+	for strategyType := range bot.StrategyAI.Shadow {
+		shadowStrategies = append(shadowStrategies, strategyType)
+	}
+	sort.Slice(shadowStrategies, func(i, j int) bool { return shadowStrategies[i] < shadowStrategies[j] })
+
+	now := bot.now()
+	for _, strategyType := range shadowStrategies {
+		impl := bot.Strategies[strategyType]
+		for _, symbol := range bot.PortfolioManager.Symbols {
+			data, exists := marketData[symbol]
+			if !exists || len(data.Kline) == 0 {
+				continue
+			}
+
+			bot.useSymbolParameters(strategyType, symbol)
+			signal := impl.Analyze(data)
+			if signal.NotReady || signal.Action == "HOLD" {
+				continue
+			}
+
+			price, _ := data.Kline[len(data.Kline)-1].Close.Float64()
+			if signal.EntryPrice > 0 {
+				price = signal.EntryPrice
+			}
+			if price <= 0 {
+				continue
+			}
+			quantity := bot.Config.ShadowNotional / price
+			if signal.SuggestedQuantity > 0 && signal.SuggestedQuantity < quantity {
+				quantity = signal.SuggestedQuantity
+			}
+
+			fill, filled := bot.Shadow.RecordSignal(string(strategyType), symbol, signal.Action, quantity, price, signal.Reason, now)
+			if !filled {
+				continue
+			}
+			log.Printf("  [SHADOW] %s %s %.6f %s at %.4f (PnL %.2f): %s",
+				strategyType, fill.Side, fill.Quantity, symbol, fill.Price, fill.PnL, signal.Reason)
+			if err := impl.Execute(signal); err != nil {
+				log.Printf("Warning: Shadow strategy %s failed to execute %s signal: %v", strategyType, symbol, err)
+			}
+		}
+	}
+}
+
+// resolveTriangles looks up the base and quote coins and quantity steps of the configured
+// triangles' markets and registers the triangles that form a valid cycle
+func (bot *TradingBot) resolveTriangles(ctx context.Context) {
+	for _, symbols := range bot.Config.TriArbTriangles {
+		var markets [3]strategy.TriangleMarket
+		resolved := true
+		for i, symbol := range symbols {
+			var info *bybit.InstrumentInfo
+			err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+				var err error
+				info, err = bot.BybitClient.GetInstrumentInfo(ctx, symbol)
+				return err
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to get instrument info for triangle market %s: %v", symbol, err)
+				resolved = false
+				break
+			}
+			step, _ := info.QtyStep.Float64()
+			markets[i] = strategy.TriangleMarket{Symbol: symbol, BaseCoin: info.BaseCoin, QuoteCoin: info.QuoteCoin, QtyStep: step}
+			// The legs are checked and valued in the reporting currency
+			bot.PortfolioManager.AddQuoteCurrency(symbol, info.QuoteCoin)
+		}
+		if !resolved {
+			continue
+		}
+
+		triangle, err := bot.TriangularArbitrage.AddTriangle(markets)
+		if err != nil {
+			log.Printf("Warning: Skipping arbitrage triangle: %v", err)
+			continue
+		}
+		log.Printf("Scanning arbitrage triangle %s starting from %s", triangle.Name, triangle.StartCoin)
+	}
+}
+
+// runTriangularArbitrage fetches the order books of each triangle at the same time, prices the
+// cycle in both directions and executes the legs of profitable opportunities in order
+func (bot *TradingBot) runTriangularArbitrage(ctx context.Context) {
+	for _, triangle := range bot.TriangularArbitrage.Triangles {
+		books := make(map[string]*bybit.OrderBook)
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		var fetchErr error
+		start := time.Now()
+		for _, market := range triangle.Markets {
+			wg.Add(1)
+			go func(symbol string) {
+				defer wg.Done()
+				var book *bybit.OrderBook
+				err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+					var err error
+					book, err = bot.BybitClient.GetOrderBook(ctx, symbol, 1)
+					return err
+				})
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					fetchErr = err
+					return
+				}
+				books[symbol] = book
+			}(market.Symbol)
+		}
+		wg.Wait()
+		latency := time.Since(start)
+		if fetchErr != nil {
+			log.Printf("Warning: Failed to get order books for triangle %s: %v", triangle.Name, fetchErr)
+			continue
+		}
+
+		signal := bot.TriangularArbitrage.ScanTriangle(triangle, books, latency)
+		if signal.Action != strategy.TriangleExecute {
+			continue
+		}
+
+		paused := false
+		for _, leg := range signal.Legs {
+			if isPaused, reason := bot.RiskManager.IsTradingPaused(leg.Symbol, string(strategy.TriangularArbitrage), bot.now()); isPaused {
+				log.Printf("  Skipping triangle %s: %s", triangle.Name, reason)
+				paused = true
+				break
+			}
+		}
+		if paused {
+			continue
+		}
+
+		log.Printf("  Triangle %s: %s", triangle.Name, signal.Reason)
+		placed, err := bot.executeTriangleLegs(ctx, signal)
+		if err != nil {
+			log.Printf("Warning: Failed to execute triangle %s: %v", triangle.Name, err)
+			continue
+		}
+
+		for _, leg := range placed {
+			bot.Notifier.SendTradeAlert(notifications.TradeAlert{
+				Symbol:     leg.Symbol,
+				Action:     leg.Side,
+				Quantity:   leg.Quantity,
+				Price:      leg.Price,
+				Strategy:   string(strategy.TriangularArbitrage),
+				Confidence: 1.0,
+				Reason:     fmt.Sprintf("Triangle %s: %s", triangle.Name, signal.Reason),
+				Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+}
+
+// executeTriangleLegs places the legs of a triangle as market orders in their execution order
+// and records them in the trade log. Each leg spends the coin received from the previous one,
+// so each passes the pre-trade gate and the trade limits right before it is placed, against the
+// balance the previous legs left. If a leg is rejected or fails, the placed legs are unwound in
+// reverse order to return the balance to the start coin. Returns the placed legs.
+func (bot *TradingBot) executeTriangleLegs(ctx context.Context, signal strategy.TriangleSignal) ([]orderLeg, error) {
+	strategyName := string(strategy.TriangularArbitrage)
+	reason := fmt.Sprintf("Triangle %s: %s", signal.Triangle, signal.Reason)
+	placeOrder := func(leg orderLeg) error {
+		return bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.PlaceOrder(ctx, bybit.Order{
+				Symbol:   leg.Symbol,
+				Side:     leg.Side,
+				Type:     "MARKET",
+				Quantity: decimal.NewFromFloat(leg.Quantity),
+			})
+		})
+	}
+
+	var placed []orderLeg
+	for _, triangleLeg := range signal.Legs {
+		leg, resized, err := bot.gateLeg(ctx, strategyName, orderLeg{
+			Symbol:   triangleLeg.Symbol,
+			Side:     triangleLeg.Side,
+			Quantity: triangleLeg.Quantity,
+			Price:    triangleLeg.Price,
+		})
+		if err == nil {
+			if resized {
+				log.Printf("    Leg %d resized from %.8f to %.8f", triangleLeg.Order, triangleLeg.Quantity, leg.Quantity)
+			}
+			err = placeOrder(leg)
+		}
+		if err != nil {
+			bot.unwindTriangleLegs(strategyName, reason, placed, placeOrder)
+			return nil, fmt.Errorf("leg %d (%s %s) failed: %w", triangleLeg.Order, triangleLeg.Side, triangleLeg.Symbol, err)
+		}
+		log.Printf("    Leg %d: %s %.8f %s at %.8f", triangleLeg.Order, leg.Side, leg.Quantity, leg.Symbol, leg.Price)
+		bot.logLeg(strategyName, reason, leg)
+		placed = append(placed, leg)
+	}
+	return placed, nil
+}
+
+// unwindTriangleLegs reverses the placed legs of a failed triangle, newest first, so the balance
+// returns to the start coin. A leg that fails to unwind leaves the balance in its target coin.
+func (bot *TradingBot) unwindTriangleLegs(strategyName, reason string, placed []orderLeg, placeOrder func(orderLeg) error) {
+	for i := len(placed) - 1; i >= 0; i-- {
+		unwind := placed[i]
+		unwind.Side = reverseSide(unwind.Side)
+		if err := placeOrder(unwind); err != nil {
+			log.Printf("Warning: Failed to unwind triangle leg %s %s, balance left in its target coin: %v", placed[i].Side, placed[i].Symbol, err)
+			return
+		}
+		log.Printf("    Unwound %s %.8f %s", unwind.Side, unwind.Quantity, unwind.Symbol)
+		bot.logLeg(strategyName, "Unwind "+reason, unwind)
 	}
+}
 
-	// Create dashboard
-	dashboard := web.NewDashboard(portfolioManager, riskManager, marketAnalyzer)
-
-	// Create notifier
-	notifier := notifications.NewNotifier()
+// syncProtectiveOrders reconciles exchange-side stop-loss and take-profit orders with the
+// risk manager's levels, placing, amending or cancelling orders as needed
+func (bot *TradingBot) syncProtectiveOrders(ctx context.Context) {
+	var existing []bybit.ConditionalOrder
+	err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+		var err error
+		existing, err = bot.BybitClient.GetConditionalOrders(ctx, "")
+		return err
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to get conditional orders: %v", err)
+		return
+	}
 
-	return &TradingBot{
-		Config:           cfg,
-		BybitClient:      bybitClient,
-		PortfolioManager: portfolioManager,
-		MarketAnalyzer:   marketAnalyzer,
-		StrategyAI:       strategyAI,
-		RiskManager:      riskManager,
-		CircuitBreaker:   circuitBreaker,
-		Strategies:       strategies,
-		Dashboard:        dashboard,
-		Notifier:         notifier,
-		IsRunning:        true, // Start running by default
-		StopChan:         make(chan struct{}),
-	}, nil
+	for _, action := range bot.RiskManager.PlanProtectiveOrders(existing) {
+		log.Printf("  %s", action.Message)
+		order := action.Order
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			switch action.Type {
+			case risk.ProtectivePlace:
+				_, err := bot.BybitClient.PlaceConditionalOrder(ctx, order)
+				return err
+			case risk.ProtectiveAmend:
+				return bot.BybitClient.AmendConditionalOrder(ctx, order.Symbol, order.OrderID, order.TriggerPrice)
+			case risk.ProtectiveCancel:
+				return bot.BybitClient.CancelConditionalOrder(ctx, order.Symbol, order.OrderID)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to %s %s order for %s: %v", action.Type, order.Kind, order.Symbol, err)
+		}
+	}
 }
 
-// Run starts the trading bot
-func (bot *TradingBot) Run(ctx context.Context) error {
-	log.Println("Starting trading bot...")
+// haltTrading stops trading after a hard risk limit is breached, cancels open orders and
+// sends an emergency notification. Trading stays halted until resumed from the dashboard.
+func (bot *TradingBot) haltTrading(ctx context.Context, reason string) {
+	log.Printf("HALT: %s", reason)
+	if err := bot.RiskManager.Halt(reason); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	bot.IsRunning = false
+	bot.publishStatus()
 
-	// Start the web dashboard in a separate goroutine
-	go func() {
-		log.Println("Starting web dashboard on port 8080...")
-		if err := bot.Dashboard.Start("8080"); err != nil && err != http.ErrServerClosed {
-			log.Printf("Dashboard server error: %v", err)
+	for _, order := range bot.PortfolioManager.GetOpenOrders() {
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.CancelOrder(ctx, order.Symbol, order.OrderID)
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to cancel order %s during halt: %v", order.OrderID, err)
+			continue
 		}
-	}()
-
-	// Start the override command handler in a separate goroutine
-	go bot.handleOverrideCommands()
+		bot.PortfolioManager.CancelOrderRemainder(order.OrderID)
+		bot.notifyOrderUpdate(order)
+	}
 
-	// Initialize portfolio with top coins
-	if err := bot.PortfolioManager.UpdateTopCoins(ctx); err != nil {
-		return fmt.Errorf("failed to initialize portfolio: %w", err)
+	if err := bot.PortfolioManager.SaveState(); err != nil {
+		log.Printf("Warning: %v", err)
 	}
+	bot.saveStrategyStates()
 
-	log.Printf("Initialized portfolio with symbols: %v", bot.PortfolioManager.Symbols)
+	bot.Notifier.SendEmergencyStopAlert("Trading halted: " + reason)
+}
 
-	// Start the main trading loop
-	return bot.tradingLoop(ctx)
+// addBenchmarkPrice fetches the price of a benchmark symbol that is not traded, as the cycle only
+// fetches the klines of the traded symbols, so that the benchmark can be valued. Replays have no
+// live prices and only value a benchmark among the replayed symbols.
+func (bot *TradingBot) addBenchmarkPrice(ctx context.Context, currentPrices map[string]float64) {
+	symbol := bot.Config.Benchmark
+	if bot.offline || symbol == portfolio.BenchmarkEqualWeight || currentPrices[symbol] > 0 {
+		return
+	}
+	err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+		price, err := bot.BybitClient.GetTickerPrice(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		currentPrices[symbol], _ = price.Float64()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: No price for benchmark %s, alpha and tracking error are not updated: %v", symbol, err)
+	}
 }
 
-// handleOverrideCommands handles manual override commands from the web dashboard
-func (bot *TradingBot) handleOverrideCommands() {
-	for command := range bot.Dashboard.GetOverrideChannel() {
-		log.Printf("Received manual override command: %s", command.Command)
+// dailyLossBreached halts trading when today's realized and unrealized loss, valued at the
+// given prices or the last known ones, exceeds the hard limit. Reports whether trading is halted.
+func (bot *TradingBot) dailyLossBreached(ctx context.Context, currentPrices map[string]float64) bool {
+	if bot.RiskManager.IsHalted() {
+		return true
+	}
+	equity := bot.PortfolioManager.MarkToMarket(currentPrices).Equity
+	startOfDayEquity := bot.PortfolioManager.GetStartOfDayEquity(bot.now())
+	if err := bot.RiskManager.CheckDailyLoss(startOfDayEquity, equity); err != nil {
+		bot.haltTrading(ctx, err.Error())
+		return true
+	}
+	return false
+}
 
-		switch command.Command {
-		case "start":
-			bot.IsRunning = true
-			log.Println("Trading bot started manually")
-		case "stop":
-			bot.IsRunning = false
-			log.Println("Trading bot stopped manually")
-		case "rebalance":
-			// Trigger immediate rebalancing
-			log.Println("Manual rebalancing triggered")
-			// In a real implementation, you would trigger rebalancing here
-		case "emergency_stop":
-			bot.IsRunning = false
-			log.Println("Emergency stop triggered manually")
-			// Send emergency stop notification
-			bot.Notifier.SendEmergencyStopAlert("Manual emergency stop triggered")
-		default:
-			log.Printf("Unknown command: %s", command.Command)
+// tradeOutcomes converts closed trades from the trade log into risk trade outcomes
+func tradeOutcomes(trades []portfolio.TradeLogEntry) []risk.TradeOutcome {
+	outcomes := make([]risk.TradeOutcome, 0, len(trades))
+	for _, trade := range trades {
+		// Trades without realized PnL are still open
+		if trade.PnL == 0 {
+			continue
 		}
+		outcomes = append(outcomes, risk.TradeOutcome{
+			Symbol:    trade.Symbol,
+			Strategy:  trade.Strategy,
+			PnL:       trade.PnL,
+			Timestamp: trade.Timestamp,
+		})
 	}
+	return outcomes
 }
 
-// tradingLoop runs the main trading loop
-func (bot *TradingBot) tradingLoop(ctx context.Context) error {
-	ticker := time.NewTicker(bot.PortfolioManager.RebalanceInterval)
-	defer ticker.Stop()
-
-	// Run initial cycle
-	if err := bot.runTradingCycle(ctx); err != nil {
-		log.Printf("Error in initial trading cycle: %v", err)
+// sendDailySummary sends performance, risk and stress test results once per UTC day
+func (bot *TradingBot) sendDailySummary() {
+	today := bot.now().UTC().Format("2006-01-02")
+	if bot.LastSummaryDate == today {
+		return
 	}
+	bot.LastSummaryDate = today
 
-	// Listen for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	stressResults := bot.RiskManager.RunStressTest(risk.DefaultStressScenarios())
+	summary := bot.PortfolioManager.GetPerformanceSummary() + "\n" +
+		bot.RiskManager.GetRiskReport() + "\n" +
+		bot.RiskManager.GetStressTestReport(stressResults)
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, shutting down...")
-			return nil
-		case <-sigChan:
-			log.Println("Received interrupt signal, shutting down...")
-			return nil
-		case <-ticker.C:
-			// Check if bot is running (manual override)
-			if bot.IsRunning {
-				log.Println("Running trading cycle...")
-				if err := bot.runTradingCycle(ctx); err != nil {
-					log.Printf("Error in trading cycle: %v", err)
-				}
-			} else {
-				log.Println("Trading bot is stopped (manual override), skipping trading cycle...")
-			}
-		case <-bot.StopChan:
-			log.Println("Received stop signal, shutting down...")
-			return nil
-		}
-	}
+	log.Printf("Daily Summary:\n%s", summary)
+	bot.Notifier.SendDailySummary(today, summary)
 }
 
 // runTradingCycle executes one complete trading cycle
 func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 	log.Println("=== Starting Trading Cycle ===")
 
+	// Do not trade while halted by a hard risk limit
+	if bot.RiskManager.IsHalted() {
+		log.Printf("WARNING: Trading is halted (%s), skipping trading cycle", bot.RiskManager.HaltState.Reason)
+		return nil
+	}
+
 	// Check circuit breaker state
-	if bot.CircuitBreaker.State() == "open" {
-		log.Println("WARNING: Circuit breaker is open, skipping trading cycle")
+	if bot.CircuitBreakers.State(risk.EndpointMarketData) == "open" {
+		log.Println("WARNING: Market data circuit breaker is open, skipping trading cycle")
 		return nil
 	}
 
 	// 1. Update top coins
 	log.Println("1. Updating top coins...")
-	err := bot.CircuitBreaker.Call(func() error {
+	err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
 		return bot.PortfolioManager.UpdateTopCoins(ctx)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update top coins: %w", err)
 	}
 
-	// 2. Analyze market conditions for each coin
-	log.Println("2. Analyzing market conditions...")
-	marketData := make(map[string]*bybit.MarketData)
-	currentPrices := make(map[string]float64)
-	enhancedMarketData := make(map[string]*market.EnhancedMarketData)
-	combinedSignals := make(map[string]*market.CombinedSignal)
-	volumeWeightedSignals := make(map[string]*market.VolumeWeightedSignal)
+	// Refresh quote currencies and conversion rates into the reporting currency
+	err = bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+		return bot.PortfolioManager.UpdateCurrencyInfo(ctx)
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to update currency info: %v", err)
+	}
 
+	// Fetch the latest klines of each coin
+	marketData := make(map[string]*bybit.MarketData)
 	for _, symbol := range bot.PortfolioManager.Symbols {
 		var data *bybit.MarketData
-		err := bot.CircuitBreaker.Call(func() error {
+		err := bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
 			var err error
 			data, err = bot.BybitClient.GetMarketData(ctx, symbol)
 			return err
@@ -235,6 +1984,27 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 		}
 
 		marketData[symbol] = data
+	}
+
+	return bot.tradeMarketData(ctx, marketData)
+}
+
+// tradeMarketData runs the rest of a trading cycle on the market data of the portfolio's
+// symbols: it analyzes the markets, manages open positions, selects strategies, trades their
+// signals and records performance. Replays drive it with historical market data.
+func (bot *TradingBot) tradeMarketData(ctx context.Context, marketData map[string]*bybit.MarketData) error {
+	// 2. Analyze market conditions for each coin
+	log.Println("2. Analyzing market conditions...")
+	currentPrices := make(map[string]float64)
+	enhancedMarketData := make(map[string]*market.EnhancedMarketData)
+	combinedSignals := make(map[string]*market.CombinedSignal)
+	volumeWeightedSignals := make(map[string]*market.VolumeWeightedSignal)
+
+	for _, symbol := range bot.PortfolioManager.Symbols {
+		data, exists := marketData[symbol]
+		if !exists {
+			continue
+		}
 
 		// Extract current price from market data (use the latest close price)
 		if len(data.Kline) > 0 {
@@ -247,6 +2017,13 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 			log.Printf("Warning: Failed to analyze enhanced market conditions for %s: %v", symbol, err)
 		} else {
 			enhancedMarketData[symbol] = enhancedData
+			// Strategies and filters read the sentiment from the market data
+			if enhancedData.Sentiment != nil {
+				score := enhancedData.Sentiment.Score
+				data.Sentiment = &score
+				log.Printf("  %s Sentiment: %.2f (%s, %s)",
+					symbol, score, enhancedData.Sentiment.Label, enhancedData.Sentiment.Source)
+			}
 			// Log some of the enhanced indicators
 			if enhancedData.MACD != nil {
 				log.Printf("  %s MACD: %.4f, Signal: %.4f, Histogram: %.4f",
@@ -296,12 +2073,45 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 
 	// 4. Check stop-loss and take-profit levels
 	log.Println("4. Checking stop-loss and take-profit levels...")
+	// Apply the fills of resting signal orders before tracking the positions built from the bot's own fills
+	bot.pollSignalOrders(ctx)
+	closedPositions := bot.RiskManager.SyncPositions(bot.PortfolioManager.GetOpenPositions(currentPrices))
+	for _, pos := range closedPositions {
+		bot.notifyPositionClosed(pos.Strategy, pos.Symbol, pos.CurrentSize, pos.EntryPrice, currentPrices[pos.Symbol], "Position closed")
+	}
+	for symbol, origin := range bot.PortfolioManager.GetPositionOrigins() {
+		bot.RiskManager.SetPositionOrigin(symbol, origin.OpenedAt, origin.Strategy)
+	}
+	bot.applyTrailingStops(ctx, currentPrices)
 	sltpActions := bot.RiskManager.CheckStopLossTakeProfit(currentPrices)
 	for _, action := range sltpActions {
 		log.Printf("  %s", action)
 		// In a real implementation, you would execute the close order here
 	}
 
+	// Close positions held longer than their strategy's maximum holding period
+	for _, exit := range bot.RiskManager.CheckHoldingPeriods(bot.now()) {
+		log.Printf("  %s", exit.Message)
+		if err := bot.closePosition(ctx, exit.Symbol, exit.Quantity, exit.EntryPrice, exit.Price, exit.Strategy, exit.Message); err != nil {
+			log.Printf("Warning: Failed to close %s after max holding period: %v", exit.Symbol, err)
+		}
+	}
+
+	// Keep stop-loss and take-profit orders on the exchange in line with the tracked levels
+	if bot.Config.PlaceProtectiveOrders {
+		bot.syncProtectiveOrders(ctx)
+	}
+
+	// Monitor leveraged positions for liquidation risk
+	if bot.Config.MonitorDerivatives {
+		bot.checkLiquidationRisk(ctx)
+	}
+
+	// Flag concentration in correlated positions
+	for _, warning := range bot.RiskManager.CheckCorrelationConcentration() {
+		log.Printf("  %s", warning)
+	}
+
 	// 5. Check symbol drawdown limits
 	log.Println("5. Checking symbol drawdown limits...")
 	drawdownActions := bot.RiskManager.CheckSymbolDrawdown()
@@ -310,20 +2120,69 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 		// In a real implementation, you would close positions that exceed drawdown limits
 	}
 
+	// Cancel the unfilled remainder of stale partially filled orders
+	for _, order := range bot.PortfolioManager.GetOrdersToCancel(bot.now()) {
+		err := bot.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+			return bot.BybitClient.CancelOrder(ctx, order.Symbol, order.OrderID)
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to cancel remainder of order %s: %v", order.OrderID, err)
+			continue
+		}
+		log.Printf("  Cancelled remaining %.6f of partially filled %s order %s",
+			order.RemainingQuantity(), order.Symbol, order.OrderID)
+		bot.PortfolioManager.CancelOrderRemainder(order.OrderID)
+		bot.notifyOrderUpdate(order)
+	}
+
 	// 6. Select optimal strategy for each coin
 	log.Println("6. Selecting strategies...")
 	strategySelections := make(map[string]strategy.StrategyType)
 
 	for _, symbol := range bot.PortfolioManager.Symbols {
 		selectedStrategy := bot.StrategyAI.SelectStrategy(symbol)
+		if bot.Ensemble != nil {
+			// The ensemble votes with its members on every symbol
+			selectedStrategy = strategy.Ensemble
+		}
 		strategySelections[symbol] = selectedStrategy
 		log.Printf("  %s: %s", symbol, selectedStrategy)
 	}
 
+	// Pause strategies, symbols or the whole bot after losing streaks
+	for _, pause := range bot.RiskManager.UpdateLossStreaks(tradeOutcomes(bot.PortfolioManager.GetTradeLog()), bot.now()) {
+		log.Printf("  LOSS_STREAK: %s %s paused after %d consecutive losses until %s",
+			pause.Scope, pause.Key, pause.Losses, pause.Until.Format(time.RFC3339))
+	}
+
+	// Disable strategies after a drawdown and re-enable them after the cooldown or a paper recovery
+	started, ended := bot.RiskManager.UpdateStrategyCooldowns(tradeOutcomes(bot.PortfolioManager.GetTradeLog()), bot.now())
+	for _, cooldown := range started {
+		log.Printf("  STRATEGY_COOLDOWN: %s disabled after rolling PnL %.2f until %s",
+			cooldown.Strategy, cooldown.RollingPnL, cooldown.Until.Format(time.RFC3339))
+	}
+	for _, cooldown := range ended {
+		log.Printf("  Strategy %s re-enabled after cooldown (%d paper trades, paper return %.2f%%)",
+			cooldown.Strategy, cooldown.PaperTrades, cooldown.PaperReturn*100)
+	}
+
+	// Do not place orders once today's loss exceeds the hard limit
+	if bot.dailyLossBreached(ctx, currentPrices) {
+		return nil
+	}
+
 	// 7. Execute strategy-specific logic for each coin and track performance
 	log.Println("7. Executing strategies and tracking performance...")
 	performanceData := make(map[string]float64)
 
+	// Scale all allocations towards the portfolio volatility target
+	volTarget := bot.RiskManager.CalculateVolatilityTarget(bot.PortfolioManager.GetOptimalAllocations())
+	if bot.Config.VolTarget > 0 {
+		log.Printf("  Volatility target %.1f%%, forecast %.1f%%, allocation multiplier %.2f",
+			volTarget.TargetVolatility*100, volTarget.ForecastVolatility*100, volTarget.Multiplier)
+	}
+	allocations := bot.PortfolioManager.GetScaledAllocations(volTarget.Multiplier)
+
 	for _, symbol := range bot.PortfolioManager.Symbols {
 		// Get selected strategy
 		strategyType := strategySelections[symbol]
@@ -341,34 +2200,196 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 		}
 
 		// Analyze with strategy
+		bot.useSymbolParameters(strategyType, symbol)
+		if strategyType == strategy.Ensemble {
+			for _, member := range bot.Ensemble.Members {
+				bot.useSymbolParameters(member.Type, symbol)
+			}
+		}
 		signal := strategyImpl.Analyze(data)
 		log.Printf("  %s signal: %s (%.2f) - %s", symbol, signal.Action, signal.Strength, signal.Reason)
 
-		// Execute strategy
-		if err := strategyImpl.Execute(signal); err != nil {
-			log.Printf("Warning: Failed to execute strategy for %s: %v", symbol, err)
+		// Indicators that are not warmed up give degenerate values, nothing is traded on them
+		if signal.NotReady {
+			log.Printf("  Skipping %s: %s needs %d bars to warm up", symbol, strategyType, signal.RequiredBars)
+			continue
+		}
+
+		// Direction flips caused by noise are held back until they persist
+		if debounced := bot.Debouncer.Filter(strategyType, signal); debounced.Action != signal.Action {
+			log.Printf("  %s", debounced.Reason)
+			signal = debounced
+		}
+
+		// The analyzer's combined indicator and volume-weighted signals must back the signal
+		if fused := bot.Fusion.Fuse(signal, combinedSignals[symbol], volumeWeightedSignals[symbol]); fused.Action != signal.Action || fused.Strength != signal.Strength {
+			log.Printf("  %s fused signal: %s (%.2f) - %s", symbol, fused.Action, fused.Strength, fused.Reason)
+			signal = fused
 		}
 
-		// Log the trade
+		// Signals chasing extreme market sentiment are held back
+		name := strings.ToUpper(string(strategyType))
+		buyMax, hasBuyMax := config.SymbolValue(bot.Config.SentimentBuyMax, name)
+		sellMin, hasSellMin := config.SymbolValue(bot.Config.SentimentSellMin, name)
+		if hasBuyMax || hasSellMin {
+			if !hasBuyMax {
+				buyMax = 1
+			}
+			if !hasSellMin {
+				sellMin = -1
+			}
+			if filtered := strategy.SentimentFilter(signal, data, buyMax, sellMin); filtered.Action != signal.Action {
+				log.Printf("  %s", filtered.Reason)
+				signal = filtered
+			}
+		}
+
+		// Size the order
 		var quantity float64
 		var price float64
 		if len(data.Kline) > 0 {
 			price, _ = data.Kline[len(data.Kline)-1].Close.Float64()
-			// Calculate quantity based on allocation and current price
-			allocation := bot.PortfolioManager.GetOptimalAllocation(symbol)
-			targetValue := bot.Config.TotalCapital * allocation
-			quantity = targetValue / price
-		}
-
-		bot.PortfolioManager.LogTrade(
-			symbol,
-			signal.Action,
-			quantity,
-			price,
-			string(strategyType),
-			signal.Strength,
-			signal.Reason,
-		)
+			// The allocation caps the order value
+			// (target value is in the reporting currency, price in the symbol's quote currency).
+			// Allocation and risk capital are both scaled by the volatility target multiplier, the
+			// allocation within the caps and the cash reserve.
+			targetValue, hasRate := bot.PortfolioManager.FromReportingCurrency(symbol, bot.Config.TotalCapital*allocations[symbol])
+			capital, _ := bot.PortfolioManager.FromReportingCurrency(symbol, bot.Config.TotalCapital*volTarget.Multiplier)
+			if !hasRate {
+				log.Printf("  Skipping %s: no conversion rate of its quote currency %s yet",
+					symbol, bot.PortfolioManager.QuoteCurrency(symbol))
+				continue
+			}
+
+			// Size the order so that a stop at N x ATR, or at the signal's own stop, risks at most
+			// RiskPerTrade of capital
+			size, err := bot.PositionSizer.Size(data, capital, targetValue)
+			if (signal.Action == "BUY" && signal.StopLoss > 0 && signal.StopLoss < price) ||
+				(signal.Action == "SELL" && signal.StopLoss > price) {
+				size, err = bot.PositionSizer.SizeWithStop(data, capital, targetValue, signal.StopLoss)
+			}
+			if err != nil {
+				log.Printf("Warning: ATR sizing unavailable for %s, using allocation: %v", symbol, err)
+				quantity = targetValue / price
+			} else {
+				quantity = size.Quantity
+				log.Printf("  %s size: %.6f (ATR %.4f, stop distance %.4f, risk %.2f, capped: %t)",
+					symbol, quantity, size.ATR, size.StopDistance, size.RiskAmount, size.Capped)
+			}
+
+			// The strategy's suggested quantity is an upper bound
+			if signal.SuggestedQuantity > 0 && signal.SuggestedQuantity < quantity {
+				log.Printf("  %s size capped at the suggested %.6f", symbol, signal.SuggestedQuantity)
+				quantity = signal.SuggestedQuantity
+			}
+
+			// Strategies that overstate their confidence trade smaller
+			if bot.Calibrator != nil && signal.Action != "HOLD" {
+				if multiplier := bot.Calibrator.SizeMultiplier(strategyType, signal.Strength); multiplier < 1 {
+					log.Printf("  %s size scaled by %.2f to the calibrated confidence of %s", symbol, multiplier, strategyType)
+					quantity *= multiplier
+				}
+			}
+		}
+
+		// Follow the signals of strategies cooling down on paper for their recovery check
+		if signal.Action != "HOLD" {
+			bot.RiskManager.RecordPaperSignal(string(strategyType), symbol, signal.Action, price)
+		}
+
+		// Respect loss streak pauses, strategy cooldowns and trading sessions
+		if signal.Action != "HOLD" {
+			if paused, reason := bot.RiskManager.IsTradingPaused(symbol, string(strategyType), bot.now()); paused {
+				log.Printf("  Skipping %s %s: %s", signal.Action, symbol, reason)
+				signal.Action = "HOLD"
+				signal.Reason = reason
+			}
+		}
+
+		// From here on the signal's action is the order that is placed, the strategy's own action
+		// is restored when it is told about the executed signal
+		strategyAction := signal.Action
+		if signal.Action == "BUY" || signal.Action == "SELL" {
+			side, capped := bot.orderSide(symbol, signal.Action, quantity)
+			if side == "" {
+				log.Printf("  Skipping SELL %s: no spot inventory and short selling is disabled", symbol)
+				signal.Action = "HOLD"
+				signal.Reason = "No spot inventory to sell"
+			} else {
+				signal.Action, quantity = side, capped
+			}
+		}
+
+		// Run the pre-trade checks, which may shrink the order to fit the limits
+		if signal.Action != "HOLD" {
+			decision := bot.checkPreTrade(ctx, string(strategyType), symbol, signal.Action, quantity, price)
+			if !decision.Approved {
+				log.Printf("  Rejected %s %s: %v", signal.Action, symbol, decision.Rejection)
+				signal.Action = "HOLD"
+				signal.Reason = fmt.Sprintf("Pre-trade check: %v", decision.Rejection)
+			} else if decision.Resized {
+				log.Printf("  Resized %s %s from %.6f to %.6f: %v", signal.Action, symbol, quantity, decision.Quantity, decision.Adjustments)
+				quantity = decision.Quantity
+			}
+		}
+
+		// Enforce the daily trading budget before placing any order
+		if signal.Action != "HOLD" {
+			if err := bot.PortfolioManager.CheckTradeLimit(symbol); err != nil {
+				log.Printf("  Skipping %s %s: %v", signal.Action, symbol, err)
+				signal.Action = "HOLD"
+				signal.Reason = fmt.Sprintf("Trade limit: %v", err)
+			}
+		}
+
+		// Place the order, the strategy is only told about signals that were executed. Resting
+		// limit orders are tracked until they fill, market orders fill immediately.
+		resting := false
+		if signal.Action != "HOLD" {
+			filled, err := bot.Executor.ExecuteSignal(ctx, signal, quantity, price)
+			if err != nil {
+				log.Printf("Warning: Failed to place %s order for %s: %v", signal.Action, symbol, err)
+				signal.Action = "HOLD"
+				signal.Reason = fmt.Sprintf("Order failed: %v", err)
+			} else if filled.Type == "LIMIT" && !filled.Paper {
+				resting = true
+				order := bot.PortfolioManager.TrackOrder(filled.OrderID, symbol, signal.Action, filled.Quantity,
+					string(strategyType), signal.Strength, signal.Reason)
+				bot.notifyOrderUpdate(order)
+			} else {
+				quantity, price = filled.Quantity, filled.Price
+				bot.notifyFill(string(strategyType), filled.OrderID, symbol, signal.Action, quantity, price)
+			}
+		}
+
+		// Execute strategy
+		executed := signal
+		if signal.Action != "HOLD" {
+			executed.Action = strategyAction
+		}
+		if err := strategyImpl.Execute(executed); err != nil {
+			log.Printf("Warning: Failed to execute strategy for %s: %v", symbol, err)
+		} else if signal.Action == "BUY" || signal.Action == "SHORT" {
+			// Protect the position with the strategy's own exits instead of the percentage levels
+			bot.RiskManager.SetTradePlan(symbol, signal.StopLoss, signal.TakeProfit)
+			bot.StrategyAI.RecordEntry(symbol)
+			if bot.Calibrator != nil {
+				bot.Calibrator.RecordEntry(symbol, strategyType, signal.Strength)
+			}
+		}
+
+		// Log the trade, resting orders are logged once they are filled
+		if !resting {
+			bot.PortfolioManager.LogTrade(
+				symbol,
+				signal.Action,
+				quantity,
+				price,
+				string(strategyType),
+				signal.Strength,
+				signal.Reason,
+			)
+		}
 
 		// Send trade alert notification
 		if signal.Action != "HOLD" {
@@ -380,7 +2401,7 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 				Strategy:   string(strategyType),
 				Confidence: signal.Strength,
 				Reason:     signal.Reason,
-				Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
+				Timestamp:  bot.now().Format("2006-01-02 15:04:05"),
 			}
 			bot.Notifier.SendTradeAlert(alert)
 		}
@@ -389,24 +2410,69 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 		performanceData[symbol] = signal.Strength * 100 // Scale to percentage
 	}
 
+	// Accumulate the DCA symbols on their schedule
+	if bot.DCA != nil {
+		bot.runDCA(ctx, marketData)
+	}
+
+	// Trade the spread of the configured pairs
+	if bot.PairsTrading != nil {
+		bot.runPairsTrading(ctx, marketData)
+	}
+
+	// Open and close delta-neutral funding positions
+	if bot.FundingArbitrage != nil {
+		bot.runFundingArbitrage(ctx)
+	}
+
+	// Evaluate the shadow strategies without sending orders
+	if bot.Shadow != nil {
+		bot.runShadowStrategies(marketData)
+	}
+
 	// 8. Update portfolio performance metrics
 	log.Println("8. Updating portfolio performance metrics...")
 	for symbol, performance := range performanceData {
 		bot.PortfolioManager.UpdatePerformance(symbol, performance)
 	}
 
-	// 9. Rebalance portfolio based on performance
-	log.Println("9. Rebalancing portfolio...")
-	err = bot.CircuitBreaker.Call(func() error {
-		return bot.PortfolioManager.RebalancePortfolio(ctx)
-	})
+	// Sample mark-to-market equity for the equity curve
+	bot.addBenchmarkPrice(ctx, currentPrices)
+	equityPoint, err := bot.PortfolioManager.RecordEquity(currentPrices)
 	if err != nil {
-		return fmt.Errorf("failed to rebalance portfolio: %w", err)
+		log.Printf("Warning: Failed to record equity: %v", err)
+	}
+	log.Printf("  Equity: $%.2f (Cash: $%.2f, Positions: $%.2f)",
+		equityPoint.Equity, equityPoint.Cash, equityPoint.PositionsValue)
+
+	// Halt trading if this cycle's trades took today's loss beyond the hard limit
+	if bot.dailyLossBreached(ctx, currentPrices) {
+		return nil
+	}
+
+	// 9. Rebalance portfolio based on performance, replays keep their symbols
+	if !bot.offline {
+		log.Println("9. Rebalancing portfolio...")
+		err = bot.CircuitBreakers.Call(risk.EndpointMarketData, func() error {
+			return bot.PortfolioManager.RebalancePortfolio(ctx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rebalance portfolio: %w", err)
+		}
+	}
+
+	// Persist portfolio and strategy state so a restart resumes where we left off
+	if err := bot.PortfolioManager.SaveState(); err != nil {
+		log.Printf("Warning: %v", err)
 	}
+	bot.saveStrategyStates()
 
 	// 10. Check risk metrics and log performance
 	log.Println("10. Checking risk metrics and performance...")
-	bot.RiskManager.CalculateRiskMetrics()
+	riskMetrics := bot.RiskManager.CalculateRiskMetrics()
+	if _, err := bot.RiskManager.RecordRiskSnapshot(riskMetrics); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 	log.Printf("Risk Report:\n%s", bot.RiskManager.GetRiskReport())
 
 	// Log performance metrics
@@ -421,6 +2487,11 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 	log.Printf("  Max Drawdown: $%.2f\n", performanceMetrics.MaxDrawdown)
 	log.Printf("  Sharpe Ratio: %.2f\n", performanceMetrics.SharpeRatio)
 	log.Printf("  Sortino Ratio: %.2f\n", performanceMetrics.SortinoRatio)
+	log.Printf("  Alpha vs %s: %.2f%% (Tracking Error: %.2f%%, Relative Drawdown: %.2f%%)\n",
+		bot.Config.Benchmark, performanceMetrics.Alpha*100, performanceMetrics.TrackingError*100, performanceMetrics.RelativeDrawdown*100)
+
+	// Push drawdown and exposure limit breaches
+	bot.RiskManager.CheckRiskEvents()
 
 	if bot.RiskManager.ShouldStopTrading() {
 		log.Println("WARNING: Risk limits exceeded, consider stopping trading!")
@@ -428,6 +2499,9 @@ func (bot *TradingBot) runTradingCycle(ctx context.Context) error {
 		bot.Notifier.SendEmergencyStopAlert("Risk limits exceeded")
 	}
 
+	// Send the daily summary on the first cycle of each UTC day
+	bot.sendDailySummary()
+
 	log.Println("=== Trading Cycle Complete ===")
 	return nil
 }
@@ -437,11 +2511,33 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Run a subcommand instead of the bot
+	if len(os.Args) > 1 && os.Args[1] == "optimize" {
+		if err := runOptimize(ctx, os.Args[2:]); err != nil {
+			log.Fatalf("Optimization failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(ctx, os.Args[2:]); err != nil {
+			log.Fatalf("Replay failed: %v", err)
+		}
+		return
+	}
+
 	// Create trading bot
-	bot, err := NewTradingBot()
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Failed to create trading bot: %v", err)
+	}
+	// Keep the recent log messages for the dashboard
+	logs := web.NewLogBuffer(cfg.LogBufferSize)
+	log.SetOutput(io.MultiWriter(os.Stderr, logs))
+	bot, err := NewTradingBot(cfg)
 	if err != nil {
 		log.Fatalf("Failed to create trading bot: %v", err)
 	}
+	bot.Dashboard.Logs = logs
 
 	// Run the bot
 	if err := bot.Run(ctx); err != nil {
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/optimizer"
+	"github.com/forbest/bybitgo/internal/strategy"
+	"github.com/joho/godotenv"
+)
+
+// parameterSearch is implemented by the optimizer's search methods
+type parameterSearch interface {
+	Run(data map[string][]bybit.KlineData) (map[string][]optimizer.Result, error)
+}
+
+// runOptimize runs the optimize subcommand: a grid or genetic search of strategy parameters per
+// symbol, writing the best parameter sets in the strategy parameter file format
+func runOptimize(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("optimize", flag.ContinueOnError)
+	strategyName := flags.String("strategy", string(strategy.Momentum), "strategy to optimize")
+	symbols := flags.String("symbols", "BTCUSDT", "comma-separated symbols")
+	method := flags.String("method", "grid", "search method: grid or genetic")
+	gridSpec := flags.String("grid", "", `parameter grid, e.g. "rsi_period=10:20:2;rsi_overbought=65,70,75"`)
+	spaceSpec := flags.String("space", "", `genetic search intervals, e.g. "rsi_period=5:30:1;rsi_overbought=60:85" (default: all valid ranges)`)
+	population := flags.Int("population", 30, "genetic population size")
+	generations := flags.Int("generations", 40, "maximum genetic generations")
+	patience := flags.Int("patience", 8, "generations without improvement before the genetic search stops")
+	workers := flags.Int("workers", 0, "concurrent backtests of the search (default BACKTEST_WORKERS)")
+	seed := flags.Int64("seed", 0, "random seed of the genetic search (default: time based) and of strategies in backtests")
+	objective := flags.String("objective", optimizer.ObjectiveSharpe, "ranking objective: sharpe, calmar or pnl")
+	capital := flags.Float64("capital", 10000, "initial capital of each backtest")
+	days := flags.Int("days", 30, "days of historical klines each parameter set is backtested on")
+	interval := flags.String("interval", bybit.MarketDataInterval, "Bybit kline interval of the historical klines")
+	dataFiles := flags.String("data", "", "comma-separated CSV files or directories of candles to use instead of Bybit klines (SYMBOL=file names the symbol)")
+	top := flags.Int("top", 5, "ranked results printed per symbol")
+	sizingMode := flags.String("sizing", "", "position sizing of the backtests: equal, fixed, atr, kelly or vol_target (default BACKTEST_SIZING)")
+	oosPercent := flags.Float64("oos", 0, "percent of each symbol's klines held out of the search to flag overfit parameter sets (default BACKTEST_OOS_PERCENT)")
+	out := flags.String("out", "", "file the best parameters are written to (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Error loading .env file: %v", err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Every backtest pays the configured slippage and fees, fills limit orders along the
+	// configured intrabar path, protects positions like the risk engine and sizes them like the
+	// configured or requested sizing mode
+	costs, err := backtest.CostsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if *sizingMode != "" {
+		cfg.BacktestSizing = strings.ToLower(*sizingMode)
+	}
+	sizing, err := backtest.SizingFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	var funding backtest.Funding // Loaded with the klines
+	backtestFunc := optimizer.BacktestWith(func(backtester *backtest.Backtester) {
+		backtester.Costs = costs
+		backtester.Intrabar.Path = cfg.BacktestIntrabarPath
+		backtester.Exits = backtest.ExitsFromConfig(cfg)
+		backtester.Sizing = sizing
+		backtester.Funding = funding
+		backtester.Seed = *seed
+	})
+
+	// The end of each symbol's klines validates the parameter sets searched on the rest
+	outOfSample := optimizer.OutOfSample{Percent: cfg.BacktestOOSPercent, OverfitRatio: cfg.BacktestOverfitRatio}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "oos" {
+			outOfSample.Percent = *oosPercent
+		}
+	})
+	if *workers <= 0 {
+		*workers = cfg.BacktestWorkers
+	}
+	if outOfSample.Percent < 0 || outOfSample.Percent >= 100 {
+		return fmt.Errorf("oos must be between 0 and 100")
+	}
+
+	strategyType := strategy.StrategyType(*strategyName)
+	var search parameterSearch
+	var description string
+	switch *method {
+	case "grid":
+		grid, err := optimizer.ParseGrid(*gridSpec)
+		if err != nil {
+			return err
+		}
+		gridSearch, err := optimizer.NewGridSearch(strategyType, grid, *objective, *capital)
+		if err != nil {
+			return err
+		}
+		gridSearch.Backtest = backtestFunc
+		gridSearch.OutOfSample = outOfSample
+		gridSearch.Workers = *workers
+		search = gridSearch
+		description = fmt.Sprintf("%d parameter sets", len(grid.Combinations()))
+	case "genetic":
+		space, err := optimizer.ParseSpace(*spaceSpec)
+		if err != nil {
+			return err
+		}
+		objectiveFunc, err := optimizer.ObjectiveFor(*objective)
+		if err != nil {
+			return err
+		}
+		geneticSearch, err := optimizer.NewGeneticSearch(strategyType, space, objectiveFunc, *capital)
+		if err != nil {
+			return err
+		}
+		geneticSearch.PopulationSize = *population
+		geneticSearch.Generations = *generations
+		geneticSearch.Patience = *patience
+		geneticSearch.Workers = *workers
+		if *seed != 0 {
+			geneticSearch.Seed = *seed
+		}
+		geneticSearch.OnGeneration = func(symbol string, generation int, best optimizer.Result) {
+			log.Printf("  %s generation %d: best score %.4f %v", symbol, generation, best.Score, best.Parameters)
+		}
+		geneticSearch.Backtest = backtestFunc
+		geneticSearch.OutOfSample = outOfSample
+		search = geneticSearch
+		description = fmt.Sprintf("up to %d generations of %d parameter sets", *generations, *population)
+	default:
+		return fmt.Errorf("unknown search method %q (use grid or genetic)", *method)
+	}
+
+	var symbolList []string
+	for _, symbol := range strings.Split(*symbols, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbolList = append(symbolList, symbol)
+		}
+	}
+
+	var data map[string][]bybit.KlineData
+	if *dataFiles != "" {
+		// All candles of the files, of every symbol unless -symbols is given
+		var only []string
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "symbols" {
+				only = symbolList
+			}
+		})
+		data, err = backtest.LoadHistoricalFiles(strings.Split(*dataFiles, ","), only, time.Time{}, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to load historical data: %w", err)
+		}
+		log.Printf("Loaded CSV candles for %d symbols", len(data))
+	} else {
+		// Get the historical klines of each symbol from the configured source, only the missing
+		// Bybit ones with the cache
+		var bybitData bybit.DataProvider = bybit.NewClient(cfg.BybitAPIKey, cfg.BybitAPISecret, cfg.Testnet)
+		if cfg.KlineCache {
+			bybitData = backtest.NewKlineCache(backtest.KlineCachePath(cfg.DataDir), bybitData)
+		}
+		provider, err := backtest.NewDataProvider(cfg.HistoricalDataSource, cfg, bybitData)
+		if err != nil {
+			return err
+		}
+		endDate := time.Now().UTC()
+		startDate := endDate.AddDate(0, 0, -*days)
+		data, err = backtest.FetchHistoricalData(ctx, provider, symbolList, *interval, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to get historical klines: %w", err)
+		}
+		log.Printf("Loaded %d days of %s klines for %d symbols", *days, *interval, len(data))
+	}
+
+	// Funding settled while the klines' positions are held as perpetuals
+	if cfg.BacktestFunding != backtest.FundingNone {
+		var from, to time.Time
+		loaded := make([]string, 0, len(data))
+		for symbol, klines := range data {
+			loaded = append(loaded, symbol)
+			if len(klines) == 0 {
+				continue
+			}
+			if from.IsZero() || klines[0].Timestamp.Before(from) {
+				from = klines[0].Timestamp
+			}
+			if last := klines[len(klines)-1].Timestamp; last.After(to) {
+				to = last
+			}
+		}
+		sort.Strings(loaded)
+		client := bybit.NewClient(cfg.BybitAPIKey, cfg.BybitAPISecret, cfg.Testnet)
+		if funding, err = backtest.LoadFunding(ctx, cfg.BacktestFunding, cfg, client, loaded, from, to); err != nil {
+			return err
+		}
+		log.Printf("Loaded %s funding for %d symbols", cfg.BacktestFunding, len(loaded))
+	}
+
+	log.Printf("Searching %s of %s on %d symbols by %s", description, strategyType, len(data), *objective)
+	results, err := search.Run(data)
+	if err != nil {
+		return err
+	}
+
+	// Print the ranking of each symbol
+	ranked := make([]string, 0, len(results))
+	for symbol := range results {
+		ranked = append(ranked, symbol)
+	}
+	sort.Strings(ranked)
+	for _, symbol := range ranked {
+		log.Printf("%s:", symbol)
+		for i, result := range results[symbol] {
+			if i >= *top {
+				break
+			}
+			log.Printf("  #%d score %.4f pnl %.2f sharpe %.2f calmar %.2f drawdown %.2f%% trades %d %v",
+				i+1, result.Score, result.NetPnL, result.SharpeRatio, result.CalmarRatio, result.MaxDrawdown, result.TotalTrades, result.Parameters)
+			if oos := result.OutOfSample; oos != nil {
+				note := ""
+				if oos.Overfit {
+					note = " OVERFIT"
+				}
+				log.Printf("     out of sample: score %.4f return %.2f%% sharpe %.2f (%.0f%% kept) trades %d%s",
+					oos.Score, oos.TotalReturn, oos.SharpeRatio, oos.SharpeRetention*100, oos.TotalTrades, note)
+			}
+		}
+		if outOfSample.Percent > 0 {
+			overfit := 0
+			for _, result := range results[symbol] {
+				if result.OutOfSample != nil && result.OutOfSample.Overfit {
+					overfit++
+				}
+			}
+			log.Printf("  %d of %d parameter sets overfit, probability of overfitting %.0f%%",
+				overfit, len(results[symbol]), optimizer.ProbabilityOfOverfit(results[symbol])*100)
+		}
+	}
+
+	// Write the best parameter set per symbol as per-symbol overrides
+	params := strategy.ParameterConfig{Symbols: make(map[string]map[strategy.StrategyType]map[string]float64)}
+	for symbol, result := range optimizer.Best(results) {
+		params.Symbols[symbol] = map[strategy.StrategyType]map[string]float64{strategyType: result.Parameters}
+	}
+	encoded, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode best parameters: %w", err)
+	}
+
+	if *out == "" {
+		fmt.Println(string(encoded))
+		return nil
+	}
+	if err := os.WriteFile(*out, encoded, 0644); err != nil {
+		return fmt.Errorf("failed to write best parameters: %w", err)
+	}
+	log.Printf("Best parameters written to %s", *out)
+	return nil
+}
@@ -0,0 +1,12 @@
+package main
+
+// Third-party strategies compiled into the bot are linked here with blank imports. Their init
+// functions call strategy.Register, and the registered names are enabled with STRATEGY_PLUGINS:
+//
+//	import _ "example.com/mystrategies/grid"
+//
+// Strategies built as Go plugins (go build -buildmode=plugin) are loaded from
+// STRATEGY_PLUGIN_PATHS instead and enabled automatically.
+//
+// Sentiment providers are linked the same way: their init functions call
+// market.RegisterSentimentProvider and the registered name is selected with SENTIMENT_PROVIDER.
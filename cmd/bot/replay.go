@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/notifications"
+	"github.com/forbest/bybitgo/internal/portfolio"
+)
+
+// replayDataDir is the directory inside the data directory a replay keeps its state in
+const replayDataDir = "replay"
+
+// runReplay runs the replay subcommand: historical klines are fed through the live bot's
+// pipeline (market analyzer, strategy selection, risk manager and paper executor) on a
+// simulated clock, one trading cycle per rebalance interval, to check the bot end to end before
+// it trades live
+func runReplay(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	symbols := flags.String("symbols", "BTCUSDT", "comma-separated symbols")
+	days := flags.Int("days", 7, "days of klines replayed")
+	dataFiles := flags.String("data", "", "comma-separated CSV files or directories of 5 minute candles to replay instead of Bybit klines (SYMBOL=file names the symbol)")
+	speed := flags.Float64("speed", 0, "simulated seconds per real second, 0 replays as fast as possible")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("days must be positive")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Get the klines before anything is built, only the missing Bybit ones with the cache
+	var symbolList []string
+	for _, symbol := range strings.Split(*symbols, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbolList = append(symbolList, symbol)
+		}
+	}
+	interval := bybit.MarketDataInterval
+	barLength, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return err
+	}
+	var data map[string][]bybit.KlineData
+	var startDate, endDate time.Time
+	if *dataFiles != "" {
+		// The last days of the files, of every symbol unless -symbols is given
+		var only []string
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "symbols" {
+				only = symbolList
+			}
+		})
+		data, err = backtest.LoadHistoricalFiles(strings.Split(*dataFiles, ","), only, time.Time{}, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to load historical data: %w", err)
+		}
+		for _, klines := range data {
+			if len(klines) == 0 {
+				continue
+			}
+			if last := klines[len(klines)-1].Timestamp.Add(barLength); last.After(endDate) {
+				endDate = last
+			}
+		}
+		startDate = endDate.AddDate(0, 0, -*days)
+	} else {
+		var bybitData bybit.DataProvider = bybit.NewClient(cfg.BybitAPIKey, cfg.BybitAPISecret, cfg.Testnet)
+		if cfg.KlineCache {
+			bybitData = backtest.NewKlineCache(backtest.KlineCachePath(cfg.DataDir), bybitData)
+		}
+		provider, err := backtest.NewDataProvider(cfg.HistoricalDataSource, cfg, bybitData)
+		if err != nil {
+			return err
+		}
+		endDate = time.Now().UTC().Truncate(barLength)
+		startDate = endDate.AddDate(0, 0, -*days)
+		data, err = backtest.FetchHistoricalData(ctx, provider, symbolList, interval, startDate, endDate.Add(-barLength))
+		if err != nil {
+			return fmt.Errorf("failed to get historical klines: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("no klines to replay")
+	}
+
+	// Replays trade on paper in their own data directory, so the live state is neither read nor
+	// changed, and start from scratch every time
+	cfg.DataDir = filepath.Join(cfg.DataDir, replayDataDir)
+	if err := os.RemoveAll(cfg.DataDir); err != nil {
+		return fmt.Errorf("failed to reset replay data directory: %w", err)
+	}
+	cfg.PaperTrading = true
+
+	// Features that need live exchange data or the wall clock are not replayed
+	cfg.DCASymbols = nil
+	cfg.MonitorDerivatives = false
+	if cfg.SentimentProvider != "" && cfg.SentimentProvider != "static" {
+		log.Printf("Warning: Market sentiment from the %s provider is not historical and is disabled in replays", cfg.SentimentProvider)
+		cfg.SentimentProvider = ""
+	}
+
+	bot, err := NewTradingBot(cfg)
+	if err != nil {
+		return err
+	}
+	bot.offline = true
+
+	// Alerts of replayed trades and risk events are only logged
+	bot.Notifier.EmailConfig = &notifications.EmailConfig{}
+	bot.Notifier.TelegramConfig = &notifications.TelegramConfig{}
+
+	// Every part of the bot runs on the simulated clock
+	var now time.Time
+	bot.clock = func() time.Time { return now }
+	bot.PortfolioManager.Clock = bot.clock
+
+	replayed := make([]string, 0, len(data))
+	for symbol := range data {
+		replayed = append(replayed, symbol)
+	}
+	sort.Strings(replayed)
+	bot.PortfolioManager.SetSymbols(replayed)
+	if benchmark := cfg.Benchmark; benchmark != portfolio.BenchmarkEqualWeight && len(data[benchmark]) == 0 {
+		log.Printf("Warning: Benchmark %s is not replayed, alpha and tracking error stay 0", benchmark)
+	}
+
+	// Higher timeframes are resampled from the 5 minute klines
+	replay, err := backtest.NewMarketReplay(data, interval, bot.BybitClient.Timeframes)
+	if err != nil {
+		return err
+	}
+
+	// Bars before the start only make up the history of the first cycle
+	for {
+		if _, ok := replay.Step(startDate); !ok {
+			break
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cycleInterval := bot.PortfolioManager.RebalanceInterval
+	log.Printf("Replaying %s to %s of %v, a trading cycle every %s",
+		startDate.Format(time.RFC3339), endDate.Format(time.RFC3339), replayed, cycleInterval)
+	cycles := 0
+	for cycle := startDate; !cycle.After(endDate); cycle = cycle.Add(cycleInterval) {
+		// Bars closing before the cycle move the trailing stops, like the checks between live cycles
+		for {
+			closeTime, ok := replay.Step(cycle)
+			if !ok {
+				break
+			}
+			if cfg.TrailingStopCheckSeconds > 0 {
+				now = closeTime
+				bot.applyTrailingStops(ctx, replay.Prices())
+			}
+		}
+		now = cycle
+
+		if *speed > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(float64(cycleInterval) / *speed)):
+			}
+		}
+		if ctx.Err() != nil {
+			log.Println("Replay interrupted")
+			break
+		}
+
+		if !bot.IsRunning || bot.RiskManager.IsHalted() {
+			continue
+		}
+		log.Printf("=== Replaying trading cycle at %s ===", now.Format(time.RFC3339))
+		if err := bot.tradeMarketData(ctx, replay.MarketData()); err != nil {
+			log.Printf("Error in trading cycle: %v", err)
+		}
+		cycles++
+	}
+
+	// Summarize the replay
+	equity := bot.PortfolioManager.MarkToMarket(replay.Prices())
+	log.Printf("Replay complete: %d trading cycles, %d trades logged", cycles, len(bot.PortfolioManager.GetTradeLog()))
+	log.Printf("  Equity: $%.2f (Cash: $%.2f, Positions: $%.2f)", equity.Equity, equity.Cash, equity.PositionsValue)
+	if cfg.TotalCapital > 0 {
+		log.Printf("  Return: %.2f%%", (equity.Equity-cfg.TotalCapital)/cfg.TotalCapital*100)
+	}
+	if bot.RiskManager.IsHalted() {
+		log.Printf("  Trading was halted: %s", bot.RiskManager.HaltState.Reason)
+	}
+	log.Printf("%s", bot.PortfolioManager.GetPerformanceSummary())
+	log.Printf("Trade log and state written to %s", cfg.DataDir)
+	return nil
+}
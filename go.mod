@@ -2,11 +2,14 @@ module github.com/forbest/bybitgo
 
 go 1.25.2
 
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/hirokisan/bybit/v2 v2.39.0
+	github.com/joho/godotenv v1.5.1
+	github.com/shopspring/decimal v1.4.0
+)
+
 require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
-	github.com/hirokisan/bybit/v2 v2.39.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/shopspring/decimal v1.4.0 // indirect
 )
@@ -0,0 +1,145 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// histogramBins is the number of equal-width bins of a distribution's histogram
+const histogramBins = 10
+
+// Distribution summarizes a sample of a trade statistic
+type Distribution struct {
+	Count     int            `json:"count"`
+	Mean      float64        `json:"mean"`
+	Min       float64        `json:"min"`
+	P25       float64        `json:"p25"`
+	Median    float64        `json:"median"`
+	P75       float64        `json:"p75"`
+	P90       float64        `json:"p90"`
+	Max       float64        `json:"max"`
+	Histogram []HistogramBin `json:"histogram,omitempty"`
+}
+
+// HistogramBin counts the values from From up to To, the last bin including To
+type HistogramBin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// TradeExcursion is what trade analytics need of a closed trade
+type TradeExcursion struct {
+	MAE     float64 // Maximum adverse excursion while open, percent of the entry price
+	MFE     float64 // Maximum favorable excursion while open, percent of the entry price
+	Holding time.Duration
+	PnL     float64 // Net of costs, to tell winners from losers
+}
+
+// TradeAnalytics describes how closed trades moved while they were open, as evidence for stop
+// and target distances: stops tighter than most winners' MAE cut winners, and targets beyond
+// most losers' MFE let trades turn into losses
+type TradeAnalytics struct {
+	MAE                 Distribution `json:"mae"` // Percent of the entry price
+	MFE                 Distribution `json:"mfe"` // Percent of the entry price
+	HoldingHours        Distribution `json:"holding_hours"`
+	WinnersMAE          Distribution `json:"winners_mae"`
+	LosersMFE           Distribution `json:"losers_mfe"`
+	WinnersHoldingHours Distribution `json:"winners_holding_hours"`
+	LosersHoldingHours  Distribution `json:"losers_holding_hours"`
+	// Average MFE over average MAE, above 1 when trades tend to move their way first
+	EdgeRatio float64 `json:"edge_ratio"`
+}
+
+// AnalyzeTrades returns the distributions of the trades' excursions and holding times, nil
+// without trades
+func AnalyzeTrades(trades []TradeExcursion) *TradeAnalytics {
+	if len(trades) == 0 {
+		return nil
+	}
+	var mae, mfe, holding, winnersMAE, losersMFE, winnersHolding, losersHolding []float64
+	for _, trade := range trades {
+		hours := trade.Holding.Hours()
+		mae, mfe, holding = append(mae, trade.MAE), append(mfe, trade.MFE), append(holding, hours)
+		if trade.PnL > 0 {
+			winnersMAE, winnersHolding = append(winnersMAE, trade.MAE), append(winnersHolding, hours)
+		} else {
+			losersMFE, losersHolding = append(losersMFE, trade.MFE), append(losersHolding, hours)
+		}
+	}
+
+	analytics := &TradeAnalytics{
+		MAE:                 newDistribution(mae),
+		MFE:                 newDistribution(mfe),
+		HoldingHours:        newDistribution(holding),
+		WinnersMAE:          newDistribution(winnersMAE),
+		LosersMFE:           newDistribution(losersMFE),
+		WinnersHoldingHours: newDistribution(winnersHolding),
+		LosersHoldingHours:  newDistribution(losersHolding),
+	}
+	if analytics.MAE.Mean > 0 {
+		analytics.EdgeRatio = analytics.MFE.Mean / analytics.MAE.Mean
+	}
+	return analytics
+}
+
+// tradeExcursions returns the excursions of a backtest's closed trades
+func tradeExcursions(trades []TradeRecord) []TradeExcursion {
+	excursions := make([]TradeExcursion, len(trades))
+	for i, trade := range trades {
+		excursions[i] = TradeExcursion{
+			MAE:     trade.MAE,
+			MFE:     trade.MFE,
+			Holding: trade.ExitTime.Sub(trade.Timestamp),
+			PnL:     trade.PnL - trade.Commission - trade.Funding,
+		}
+	}
+	return excursions
+}
+
+// newDistribution summarizes a sample, with percentiles at the nearest rank
+func newDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	at := func(p float64) float64 {
+		return sorted[int(math.Round(p*float64(len(sorted)-1)))]
+	}
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+
+	distribution := Distribution{
+		Count:  len(sorted),
+		Mean:   sum / float64(len(sorted)),
+		Min:    sorted[0],
+		P25:    at(0.25),
+		Median: at(0.5),
+		P75:    at(0.75),
+		P90:    at(0.9),
+		Max:    sorted[len(sorted)-1],
+	}
+
+	width := (distribution.Max - distribution.Min) / histogramBins
+	if width <= 0 {
+		distribution.Histogram = []HistogramBin{{From: distribution.Min, To: distribution.Max, Count: len(sorted)}}
+		return distribution
+	}
+	distribution.Histogram = make([]HistogramBin, histogramBins)
+	for i := range distribution.Histogram {
+		distribution.Histogram[i].From = distribution.Min + float64(i)*width
+		distribution.Histogram[i].To = distribution.Min + float64(i+1)*width
+	}
+	for _, value := range sorted {
+		bin := int((value - distribution.Min) / width)
+		if bin >= histogramBins {
+			bin = histogramBins - 1
+		}
+		distribution.Histogram[bin].Count++
+	}
+	return distribution
+}
@@ -1,6 +1,7 @@
 package backtest
 
 import (
+	"context"
 	"time"
 
 	"github.com/forbest/bybitgo/internal/bybit"
@@ -9,45 +10,80 @@ import (
 
 // BacktestResult represents the results of a backtest
 type BacktestResult struct {
-	StrategyName   string
-	StartDate      time.Time
-	EndDate        time.Time
-	InitialCapital float64
-	FinalCapital   float64
-	TotalReturn    float64
-	TotalTrades    int
-	WinningTrades  int
-	LosingTrades   int
-	WinRate        float64
-	MaxDrawdown    float64
-	SharpeRatio    float64
-	SortinoRatio   float64
-	TradeHistory   []TradeRecord
-	EquityCurve    []EquityPoint
+	RunID          string            `json:"run_id,omitempty"` // Set when the result is kept or persisted
+	StrategyName   string            `json:"strategy_name"`
+	StartDate      time.Time         `json:"start_date"`
+	EndDate        time.Time         `json:"end_date"`
+	InitialCapital float64           `json:"initial_capital"`
+	FinalCapital   float64           `json:"final_capital"`
+	TotalReturn    float64           `json:"total_return"`
+	TotalTrades    int               `json:"total_trades"`
+	WinningTrades  int               `json:"winning_trades"`
+	LosingTrades   int               `json:"losing_trades"`
+	WinRate        float64           `json:"win_rate"`
+	MaxDrawdown    float64           `json:"max_drawdown"`
+	SharpeRatio    float64           `json:"sharpe_ratio"`
+	SortinoRatio   float64           `json:"sortino_ratio"`
+	TradeHistory   []TradeRecord     `json:"trade_history"`
+	EquityCurve    []EquityPoint     `json:"equity_curve"`
+	MonteCarlo     *MonteCarloResult `json:"monte_carlo,omitempty"` // Resampled trade sequences, nil unless requested
+	Benchmark      *BenchmarkResult  `json:"benchmark,omitempty"`   // Buy and hold of the same symbols
+	Selection      *SelectionResult  `json:"selection,omitempty"`   // Strategy switches of a selector run
+	Split          *SplitResult      `json:"split,omitempty"`       // In-sample/out-of-sample comparison
+	TradeAnalytics *TradeAnalytics   `json:"trade_analytics,omitempty"`
+	// Drawdown periods last from a peak of the equity curve until it is regained, or the end
+	CAGR            float64 `json:"cagr"`         // Compound annual growth rate, percent
+	CalmarRatio     float64 `json:"calmar_ratio"` // CAGR over max drawdown
+	MaxDrawdownDays float64 `json:"max_drawdown_days"`
+	AvgDrawdownDays float64 `json:"avg_drawdown_days"`
+	ExposurePercent float64 `json:"exposure_percent"` // Share of the period with a position open
+	TotalFunding    float64 `json:"total_funding"`    // Funding paid by positions, negative when received
+	// Reproducibility: rerunning the same code, settings, seed and data gives the same result
+	Seed        int64  `json:"seed"`
+	CodeVersion string `json:"code_version"`
+	Fingerprint string `json:"fingerprint,omitempty"` // See Backtester.Fingerprint
+	// Stopped early by the backtester's context, covering the period up to its last bar
+	Cancelled bool `json:"cancelled,omitempty"`
 }
 
 // TradeRecord represents a single trade in the backtest
 type TradeRecord struct {
-	Timestamp  time.Time
-	Symbol     string
-	Action     string // BUY, SELL
-	Quantity   float64
-	EntryPrice float64
-	ExitPrice  float64
-	PnL        float64
-	Commission float64
+	Timestamp  time.Time `json:"timestamp"` // Entry time
+	ExitTime   time.Time `json:"exit_time"`
+	Symbol     string    `json:"symbol"`
+	Action     string    `json:"action"` // BUY, SELL
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	PnL        float64   `json:"pnl"`
+	Commission float64   `json:"commission"`
+	Funding    float64   `json:"funding,omitempty"`     // Paid while open, negative when received
+	MAE        float64   `json:"mae"`                   // Maximum adverse excursion while open, percent of the entry price
+	MFE        float64   `json:"mfe"`                   // Maximum favorable excursion while open, percent of the entry price
+	ExitReason string    `json:"exit_reason,omitempty"` // SIGNAL, STOP_LOSS, TAKE_PROFIT, TRAILING_STOP or END
 }
 
 // EquityPoint represents a point on the equity curve
 type EquityPoint struct {
-	Timestamp time.Time
-	Equity    float64
+	Timestamp time.Time `json:"timestamp"`
+	Equity    float64   `json:"equity"`
 }
 
 // Backtester handles backtesting of trading strategies
 type Backtester struct {
 	Strategy strategy.Strategy
 	Data     map[string][]bybit.KlineData
+	Costs    Costs    // Slippage and fees of every simulated fill
+	Intrabar Intrabar // Price path within bars for limit order fills and exits
+	Exits    Exits    // Stop-loss, take-profit and trailing stops of positions
+	Sizing   Sizing   // Share of the equity each entry takes
+	Funding  Funding  // Carry of positions held as perpetuals
+	Seed     int64    // Seeds the strategy's randomness, see strategy.SeededStrategy
+	Workers  int      // Runs of sweeps, walk-forward grids, splits and comparisons in parallel, default 1
+	// Optional: cancelling the context stops runs early, and the progress func follows them
+	Context  context.Context
+	Progress ProgressFunc
+	tracker  *progressTracker // Progress of the enclosing runs, see track
 }
 
 // NewBacktester creates a new Backtester
@@ -55,84 +91,39 @@ func NewBacktester(strategy strategy.Strategy, data map[string][]bybit.KlineData
 	return &Backtester{
 		Strategy: strategy,
 		Data:     data,
+		Costs:    DefaultCosts(),
+		Intrabar: Intrabar{Path: IntrabarNearest},
 	}
 }
 
-// Run runs a backtest
+// Run replays the klines of all symbols between the start and end date in chronological order
+// through the strategy, bar by bar, as the live bot would see them. See engine for the
+// simulated execution. Symbols with bars at the same time are replayed in alphabetical order,
+// so runs are deterministic given the seed. A run whose context is cancelled stops at the next
+// bar and closes its positions there.
 func (bt *Backtester) Run(initialCapital float64, startDate, endDate time.Time) *BacktestResult {
 	result := &BacktestResult{
-		StrategyName:   "Backtest Strategy",
+		StrategyName:   bt.Strategy.GetName(),
 		StartDate:      startDate,
 		EndDate:        endDate,
 		InitialCapital: initialCapital,
 		FinalCapital:   initialCapital,
-		TotalTrades:    0,
-		WinningTrades:  0,
-		LosingTrades:   0,
 		TradeHistory:   make([]TradeRecord, 0),
 		EquityCurve:    make([]EquityPoint, 0),
+		Seed:           bt.Seed,
+		CodeVersion:    CodeVersion(),
 	}
-
-	// Initialize equity curve with starting capital
-	result.EquityCurve = append(result.EquityCurve, EquityPoint{
-		Timestamp: startDate,
-		Equity:    initialCapital,
-	})
-
-	// This is a simplified backtest implementation
-	// In a real implementation, you would:
-	// 1. Iterate through historical data
-	// 2. Apply the strategy to generate signals
-	// 3. Execute trades and track performance
-	// 4. Calculate metrics
-
-	// For now, we'll generate some sample data to demonstrate the visualization
-	currentTime := startDate
-	equity := initialCapital
-
-	for currentTime.Before(endDate) {
-		// Simulate some trades
-		if result.TotalTrades < 50 && currentTime.Day()%5 == 0 {
-			trade := TradeRecord{
-				Timestamp:  currentTime,
-				Symbol:     "BTCUSDT",
-				Action:     "BUY",
-				Quantity:   0.1,
-				EntryPrice: 50000.0,
-				ExitPrice:  51000.0,
-				PnL:        1000.0,
-				Commission: 10.0,
-			}
-
-			result.TradeHistory = append(result.TradeHistory, trade)
-			result.TotalTrades++
-			result.WinningTrades++
-			equity += trade.PnL - trade.Commission
-
-			// Add equity point
-			result.EquityCurve = append(result.EquityCurve, EquityPoint{
-				Timestamp: currentTime,
-				Equity:    equity,
-			})
-		}
-
-		currentTime = currentTime.Add(24 * time.Hour)
+	if len(bt.Data) == 0 {
+		return result
 	}
-
-	// Final calculations
-	result.FinalCapital = equity
-	result.TotalReturn = (equity - initialCapital) / initialCapital * 100
-	result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades) * 100
-	result.MaxDrawdown = 5.0  // Sample value
-	result.SharpeRatio = 1.5  // Sample value
-	result.SortinoRatio = 2.1 // Sample value
-
-	// Add final equity point
-	result.EquityCurve = append(result.EquityCurve, EquityPoint{
-		Timestamp: endDate,
-		Equity:    equity,
-	})
-
+	strategy.Seed(bt.Strategy, bt.Seed)
+	bt, finish := bt.track(bt.bars(endDate))
+	defer finish()
+
+	newEngine(bt, initialCapital).run(result, startDate, endDate)
+	calculateMetrics(result)
+	result.Selection = selection(bt.Strategy)
+	result.Benchmark = buyAndHold(bt.Data, bt.Costs, result)
 	return result
 }
 
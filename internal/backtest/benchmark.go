@@ -0,0 +1,141 @@
+package backtest
+
+import (
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// BenchmarkResult compares a backtest with buying and holding an equal-weight basket of its
+// symbols over the same period
+type BenchmarkResult struct {
+	FinalCapital     float64       `json:"final_capital"`
+	TotalReturn      float64       `json:"total_return"`
+	MaxDrawdown      float64       `json:"max_drawdown"`
+	ExcessReturn     float64       `json:"excess_return"`     // Strategy return minus benchmark return, in percentage points
+	Beta             float64       `json:"beta"`              // Sensitivity of the strategy's returns to the benchmark's
+	RelativeDrawdown float64       `json:"relative_drawdown"` // Largest fall of strategy equity over benchmark equity from its peak, in percent
+	EquityCurve      []EquityPoint `json:"equity_curve"`
+}
+
+// holding is one symbol of the buy-and-hold basket
+type holding struct {
+	klines   []bybit.KlineData
+	next     int
+	quantity float64
+	last     float64
+	bought   bool
+}
+
+// buyAndHold simulates the benchmark of a result: each symbol gets an equal share of the initial
+// capital, bought at the open of its first bar in the period and sold at the last close, paying
+// the same slippage and fees as the strategy. Its equity is marked at the strategy's equity
+// points, so both curves line up.
+func buyAndHold(data map[string][]bybit.KlineData, costs Costs, result *BacktestResult) *BenchmarkResult {
+	if len(data) == 0 || len(result.EquityCurve) == 0 {
+		return nil
+	}
+
+	symbols := make([]string, 0, len(data))
+	for symbol := range data {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	share := result.InitialCapital / float64(len(symbols))
+	cash := result.InitialCapital
+	holdings := make(map[string]*holding, len(symbols))
+	for _, symbol := range symbols {
+		klines := data[symbol]
+		first := sort.Search(len(klines), func(i int) bool { return !klines[i].Timestamp.Before(result.StartDate) })
+		holdings[symbol] = &holding{klines: klines, next: first}
+	}
+
+	benchmark := &BenchmarkResult{EquityCurve: make([]EquityPoint, 0, len(result.EquityCurve))}
+	for _, point := range result.EquityCurve {
+		equity := cash
+		for _, symbol := range symbols {
+			h := holdings[symbol]
+			for h.next < len(h.klines) && !h.klines[h.next].Timestamp.After(point.Timestamp) {
+				kline := h.klines[h.next]
+				if !h.bought {
+					// Buy at the open of the first bar, the order value covering the fee
+					if open, _ := kline.Open.Float64(); open > 0 {
+						fillPrice, _ := costs.apply(Fill{Symbol: symbol, Side: "BUY", Quantity: share / open, Price: open, Kline: kline}, false)
+						h.quantity = share / (fillPrice * (1 + costs.TakerFeePercent/100))
+						cash -= share
+						equity -= share
+						h.bought = true
+					}
+				}
+				h.last, _ = kline.Close.Float64()
+				h.next++
+			}
+			equity += h.quantity * h.last
+		}
+		benchmark.EquityCurve = append(benchmark.EquityCurve, EquityPoint{Timestamp: point.Timestamp, Equity: equity})
+	}
+
+	// Sell the basket at the last closes
+	final := cash
+	for _, symbol := range symbols {
+		h := holdings[symbol]
+		if h.quantity <= 0 || h.last <= 0 {
+			continue
+		}
+		fillPrice, fee := costs.apply(Fill{Symbol: symbol, Side: "SELL", Quantity: h.quantity, Price: h.last, Kline: h.klines[h.next-1]}, false)
+		final += h.quantity*fillPrice - fee
+	}
+	benchmark.EquityCurve[len(benchmark.EquityCurve)-1].Equity = final
+
+	benchmark.FinalCapital = final
+	if result.InitialCapital > 0 {
+		benchmark.TotalReturn = (final - result.InitialCapital) / result.InitialCapital * 100
+	}
+	benchmark.MaxDrawdown = maxDrawdown(benchmark.EquityCurve)
+	benchmark.ExcessReturn = result.TotalReturn - benchmark.TotalReturn
+	benchmark.Beta = beta(result.EquityCurve, benchmark.EquityCurve)
+
+	relative := make([]EquityPoint, 0, len(result.EquityCurve))
+	for i, point := range result.EquityCurve {
+		if b := benchmark.EquityCurve[i].Equity; b > 0 {
+			relative = append(relative, EquityPoint{Timestamp: point.Timestamp, Equity: point.Equity / b})
+		}
+	}
+	benchmark.RelativeDrawdown = maxDrawdown(relative)
+
+	return benchmark
+}
+
+// beta returns the covariance of two aligned equity curves' returns over the variance of the
+// benchmark's, 0 without enough returns or when the benchmark does not move
+func beta(curve, benchmark []EquityPoint) float64 {
+	var returns, benchmarkReturns []float64
+	for i := 1; i < len(curve) && i < len(benchmark); i++ {
+		if curve[i-1].Equity > 0 && benchmark[i-1].Equity > 0 {
+			returns = append(returns, curve[i].Equity/curve[i-1].Equity-1)
+			benchmarkReturns = append(benchmarkReturns, benchmark[i].Equity/benchmark[i-1].Equity-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean, benchmarkMean := 0.0, 0.0
+	for i := range returns {
+		mean += returns[i]
+		benchmarkMean += benchmarkReturns[i]
+	}
+	mean /= float64(len(returns))
+	benchmarkMean /= float64(len(returns))
+
+	covariance, variance := 0.0, 0.0
+	for i := range returns {
+		covariance += (returns[i] - mean) * (benchmarkReturns[i] - benchmarkMean)
+		variance += (benchmarkReturns[i] - benchmarkMean) * (benchmarkReturns[i] - benchmarkMean)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
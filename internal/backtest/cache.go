@@ -0,0 +1,203 @@
+package backtest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// klineCacheDir is the directory of cached klines inside the data directory
+const klineCacheDir = "klines"
+
+// KlineCachePath returns the directory klines are cached in
+func KlineCachePath(dataDir string) string {
+	return filepath.Join(dataDir, klineCacheDir)
+}
+
+// klineCoverage is the persisted time range a cache file holds every kline of
+type klineCoverage struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// KlineCache is a data provider keeping the klines of each symbol and interval on disk and
+// fetching only the ranges it does not hold yet from another provider, so repeated backtests do
+// not download the same history again. Each cache file holds one contiguous range; klines of
+// bars that had not closed when they were fetched are fetched again.
+type KlineCache struct {
+	Dir    string
+	Source bybit.DataProvider
+	mu     sync.Mutex
+}
+
+// NewKlineCache creates a new KlineCache in a directory in front of a data provider
+func NewKlineCache(dir string, source bybit.DataProvider) *KlineCache {
+	return &KlineCache{Dir: dir, Source: source}
+}
+
+// GetKlines returns the klines of a symbol at an interval that start between two times,
+// fetching the missing ranges and adding them to the cache
+func (kc *KlineCache) GetKlines(ctx context.Context, symbol, interval string, start, end time.Time) ([]bybit.KlineData, error) {
+	barLength, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	name, err := kc.name(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	klines, coverage := kc.load(name)
+	var fetched []bybit.KlineData
+	fetch := func(from, to time.Time) error {
+		if to.Before(from) {
+			return nil
+		}
+		page, err := kc.Source.GetKlines(ctx, symbol, interval, from, to)
+		if err != nil {
+			return err
+		}
+		fetched = append(fetched, page...)
+		return nil
+	}
+
+	// Bars that may still be open are never counted as covered
+	closed := time.Now().Add(-barLength)
+	if end.Before(closed) {
+		closed = end
+	}
+	if coverage == nil {
+		if err := fetch(start, end); err != nil {
+			return nil, err
+		}
+		coverage = &klineCoverage{From: start, To: closed}
+	} else {
+		if start.Before(coverage.From) {
+			if err := fetch(start, coverage.From.Add(-time.Millisecond)); err != nil {
+				return nil, err
+			}
+			coverage.From = start
+		}
+		if end.After(coverage.To) {
+			if err := fetch(coverage.To.Add(time.Millisecond), end); err != nil {
+				return nil, err
+			}
+			if closed.After(coverage.To) {
+				coverage.To = closed
+			}
+		}
+	}
+
+	// Ranges without any kline, like those before a listing, are fetched again next time
+	if len(fetched) > 0 {
+		klines = mergeKlines(klines, fetched)
+		if err := kc.save(name, klines, coverage); err != nil {
+			return nil, err
+		}
+	}
+
+	first := sort.Search(len(klines), func(i int) bool { return !klines[i].Timestamp.Before(start) })
+	last := sort.Search(len(klines), func(i int) bool { return klines[i].Timestamp.After(end) })
+	if first >= last {
+		return nil, nil
+	}
+	return append([]bybit.KlineData(nil), klines[first:last]...), nil
+}
+
+// name returns the file name of a symbol's klines at an interval, rejecting names that could
+// escape the directory
+func (kc *KlineCache) name(symbol, interval string) (string, error) {
+	name := strings.ToUpper(symbol) + "_" + interval
+	if strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	}) >= 0 {
+		return "", fmt.Errorf("invalid symbol %q or interval %q", symbol, interval)
+	}
+	return name, nil
+}
+
+// load reads the cached klines and coverage of a cache file, nil coverage when there is none or
+// it cannot be read
+func (kc *KlineCache) load(name string) ([]bybit.KlineData, *klineCoverage) {
+	var coverage klineCoverage
+	found, err := persistence.LoadJSON(filepath.Join(kc.Dir, name+".json"), &coverage)
+	if err != nil || !found {
+		return nil, nil
+	}
+	klines, err := LoadCSVKlines(filepath.Join(kc.Dir, name+".csv"))
+	if err != nil {
+		return nil, nil
+	}
+	return klines, &coverage
+}
+
+// save writes the klines and then the coverage of a cache file
+func (kc *KlineCache) save(name string, klines []bybit.KlineData, coverage *klineCoverage) error {
+	if err := os.MkdirAll(kc.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create kline cache directory: %w", err)
+	}
+
+	path := filepath.Join(kc.Dir, name+".csv")
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write kline cache: %w", err)
+	}
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"timestamp", "open", "high", "low", "close", "volume"})
+	for _, kline := range klines {
+		writer.Write([]string{
+			strconv.FormatInt(kline.Timestamp.UnixMilli(), 10),
+			kline.Open.String(),
+			kline.High.String(),
+			kline.Low.String(),
+			kline.Close.String(),
+			kline.Volume.String(),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write kline cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write kline cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace kline cache: %w", err)
+	}
+
+	return persistence.SaveJSON(filepath.Join(kc.Dir, name+".json"), coverage)
+}
+
+// mergeKlines returns the klines of both series in order, the newer series winning on equal
+// timestamps
+func mergeKlines(cached, fetched []bybit.KlineData) []bybit.KlineData {
+	byTime := make(map[int64]bybit.KlineData, len(cached)+len(fetched))
+	for _, kline := range cached {
+		byTime[kline.Timestamp.UnixMilli()] = kline
+	}
+	for _, kline := range fetched {
+		byTime[kline.Timestamp.UnixMilli()] = kline
+	}
+
+	merged := make([]bybit.KlineData, 0, len(byTime))
+	for _, kline := range byTime {
+		merged = append(merged, kline)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+	return merged
+}
@@ -0,0 +1,116 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// comparedMetrics are the metrics of a comparison in their order, with how to read them off a
+// result
+var comparedMetrics = []struct {
+	name  string
+	value func(*BacktestResult) float64
+}{
+	{"total_return", func(r *BacktestResult) float64 { return r.TotalReturn }},
+	{"cagr", func(r *BacktestResult) float64 { return r.CAGR }},
+	{"sharpe_ratio", func(r *BacktestResult) float64 { return r.SharpeRatio }},
+	{"sortino_ratio", func(r *BacktestResult) float64 { return r.SortinoRatio }},
+	{"calmar_ratio", func(r *BacktestResult) float64 { return r.CalmarRatio }},
+	{"max_drawdown", func(r *BacktestResult) float64 { return r.MaxDrawdown }},
+	{"max_drawdown_days", func(r *BacktestResult) float64 { return r.MaxDrawdownDays }},
+	{"win_rate", func(r *BacktestResult) float64 { return r.WinRate }},
+	{"total_trades", func(r *BacktestResult) float64 { return float64(r.TotalTrades) }},
+	{"exposure_percent", func(r *BacktestResult) float64 { return r.ExposurePercent }},
+	{"total_funding", func(r *BacktestResult) float64 { return r.TotalFunding }},
+	{"final_capital", func(r *BacktestResult) float64 { return r.FinalCapital }},
+}
+
+// MetricComparison is one metric of the compared runs, in the order of the runs
+type MetricComparison struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+	Deltas []float64 `json:"deltas"` // Value minus the baseline's, zero for the baseline
+}
+
+// ComparedCurve is the equity curve of a compared run at the comparison's timestamps
+type ComparedCurve struct {
+	RunID  string    `json:"run_id"`
+	Equity []float64 `json:"equity"`
+	Return []float64 `json:"return"` // Percent of the run's initial capital, comparable across capitals
+}
+
+// Comparison sets backtest runs side by side against the first as the baseline, e.g. to judge
+// a parameter or code change
+type Comparison struct {
+	Runs       []RunSummary       `json:"runs"`
+	Metrics    []MetricComparison `json:"metrics"`
+	Timestamps []time.Time        `json:"timestamps"` // Every equity point of any run
+	Curves     []ComparedCurve    `json:"curves"`
+	// Whether the runs replayed the same period, without which their metrics compare loosely
+	SamePeriod bool `json:"same_period"`
+}
+
+// Compare compares two or more backtest runs, the first being the baseline. The equity curves
+// are aligned on the union of their timestamps, each holding its last equity between its points
+// and its initial capital before its first.
+func Compare(results ...*BacktestResult) (*Comparison, error) {
+	if len(results) < 2 {
+		return nil, fmt.Errorf("comparing backtests needs at least two runs")
+	}
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("run %d has no result", i+1)
+		}
+	}
+	baseline := results[0]
+
+	comparison := &Comparison{SamePeriod: true}
+	for _, result := range results {
+		comparison.Runs = append(comparison.Runs, result.Summary(time.Time{}))
+		if !result.StartDate.Equal(baseline.StartDate) || !result.EndDate.Equal(baseline.EndDate) {
+			comparison.SamePeriod = false
+		}
+	}
+
+	for _, metric := range comparedMetrics {
+		row := MetricComparison{Name: metric.name, Values: make([]float64, len(results)), Deltas: make([]float64, len(results))}
+		for i, result := range results {
+			row.Values[i] = metric.value(result)
+			row.Deltas[i] = row.Values[i] - metric.value(baseline)
+		}
+		comparison.Metrics = append(comparison.Metrics, row)
+	}
+
+	seen := make(map[int64]bool)
+	for _, result := range results {
+		for _, point := range result.EquityCurve {
+			if !seen[point.Timestamp.UnixNano()] {
+				seen[point.Timestamp.UnixNano()] = true
+				comparison.Timestamps = append(comparison.Timestamps, point.Timestamp)
+			}
+		}
+	}
+	sort.Slice(comparison.Timestamps, func(i, j int) bool { return comparison.Timestamps[i].Before(comparison.Timestamps[j]) })
+
+	for _, result := range results {
+		curve := ComparedCurve{
+			RunID:  result.RunID,
+			Equity: make([]float64, len(comparison.Timestamps)),
+			Return: make([]float64, len(comparison.Timestamps)),
+		}
+		equity, next := result.InitialCapital, 0
+		for i, timestamp := range comparison.Timestamps {
+			for next < len(result.EquityCurve) && !result.EquityCurve[next].Timestamp.After(timestamp) {
+				equity = result.EquityCurve[next].Equity
+				next++
+			}
+			curve.Equity[i] = equity
+			if result.InitialCapital > 0 {
+				curve.Return[i] = (equity/result.InitialCapital - 1) * 100
+			}
+		}
+		comparison.Curves = append(comparison.Curves, curve)
+	}
+	return comparison, nil
+}
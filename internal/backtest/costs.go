@@ -0,0 +1,139 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// Slippage models
+const (
+	SlippageFixed  = "fixed"  // A fixed number of basis points
+	SlippageSpread = "spread" // Half of the symbol's bid-ask spread
+	SlippageVolume = "volume" // Square-root impact of the order's share of the bar volume
+)
+
+// Fill is a simulated order fill before costs
+type Fill struct {
+	Symbol   string
+	Side     string // BUY or SELL
+	Quantity float64
+	Price    float64
+	Kline    bybit.KlineData // Bar the fill happens in
+}
+
+// SlippageModel returns the adverse price move of a fill as a fraction of its price
+type SlippageModel interface {
+	Slippage(fill Fill) float64
+}
+
+// FixedSlippage moves every fill by the same number of basis points
+type FixedSlippage struct {
+	Bps float64
+}
+
+// Slippage returns the fixed fraction
+func (fs FixedSlippage) Slippage(fill Fill) float64 {
+	return fs.Bps / 10000
+}
+
+// SpreadSlippage fills market orders at the far side of the spread, half of it away from the
+// price. Symbols without a configured spread use a fraction of the bar's high-low range as the
+// spread estimate.
+type SpreadSlippage struct {
+	SpreadBps     map[string]float64 // Spread per symbol in basis points, "*" for all others
+	RangeFraction float64            // Share of the bar range taken as the spread otherwise
+}
+
+// Slippage returns half the spread as a fraction of the price
+func (ss SpreadSlippage) Slippage(fill Fill) float64 {
+	if bps, ok := config.SymbolValue(ss.SpreadBps, fill.Symbol); ok {
+		return bps / 10000 / 2
+	}
+	high, _ := fill.Kline.High.Float64()
+	low, _ := fill.Kline.Low.Float64()
+	if fill.Price <= 0 || high <= low {
+		return 0
+	}
+	return ss.RangeFraction * (high - low) / fill.Price / 2
+}
+
+// VolumeImpactSlippage grows with the square root of the order's share of the bar volume, so
+// large orders in thin bars pay more
+type VolumeImpactSlippage struct {
+	Coefficient float64 // Slippage of an order as large as the whole bar volume
+	MinBps      float64 // Slippage floor in basis points
+}
+
+// Slippage returns the volume impact as a fraction of the price
+func (vs VolumeImpactSlippage) Slippage(fill Fill) float64 {
+	floor := vs.MinBps / 10000
+	volume, _ := fill.Kline.Volume.Float64()
+	if volume <= 0 {
+		return math.Max(floor, vs.Coefficient)
+	}
+	return math.Max(floor, vs.Coefficient*math.Sqrt(fill.Quantity/volume))
+}
+
+// Costs are the trading costs applied to every simulated fill
+type Costs struct {
+	Slippage        SlippageModel // Nil for fills at the bar price
+	MakerFeePercent float64       // Fee of resting limit orders
+	TakerFeePercent float64       // Fee of market orders
+}
+
+// DefaultCosts are Bybit's base spot fees and a small fixed slippage
+func DefaultCosts() Costs {
+	return Costs{
+		Slippage:        FixedSlippage{Bps: 2},
+		MakerFeePercent: 0.1,
+		TakerFeePercent: 0.1,
+	}
+}
+
+// NewSlippageModel creates a named slippage model
+func NewSlippageModel(name string, bps float64, spreadBps map[string]float64, impactCoefficient float64) (SlippageModel, error) {
+	switch name {
+	case SlippageFixed, "":
+		return FixedSlippage{Bps: bps}, nil
+	case SlippageSpread:
+		return SpreadSlippage{SpreadBps: spreadBps, RangeFraction: 0.1}, nil
+	case SlippageVolume:
+		return VolumeImpactSlippage{Coefficient: impactCoefficient, MinBps: bps}, nil
+	}
+	return nil, fmt.Errorf("unknown slippage model %q (use %s, %s or %s)", name, SlippageFixed, SlippageSpread, SlippageVolume)
+}
+
+// CostsFromConfig returns the backtest costs of the configuration
+func CostsFromConfig(cfg *config.Config) (Costs, error) {
+	slippage, err := NewSlippageModel(cfg.BacktestSlippageModel, cfg.BacktestSlippageBps, cfg.BacktestSpreadBps, cfg.BacktestImpactCoefficient)
+	if err != nil {
+		return Costs{}, err
+	}
+	return Costs{
+		Slippage:        slippage,
+		MakerFeePercent: cfg.BacktestMakerFeePercent,
+		TakerFeePercent: cfg.BacktestTakerFeePercent,
+	}, nil
+}
+
+// apply returns the price a fill executes at after slippage and its fee
+func (c Costs) apply(fill Fill, maker bool) (price, fee float64) {
+	price = fill.Price
+	if c.Slippage != nil && !maker {
+		slippage := c.Slippage.Slippage(fill)
+		if fill.Side == "BUY" {
+			price *= 1 + slippage
+		} else {
+			price *= 1 - slippage
+		}
+	}
+
+	feePercent := c.TakerFeePercent
+	if maker {
+		feePercent = c.MakerFeePercent
+	}
+	return price, price * fill.Quantity * feePercent / 100
+}
@@ -0,0 +1,336 @@
+package backtest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/shopspring/decimal"
+)
+
+// csvColumns maps the accepted header names of each kline field, matched case-insensitively
+var csvColumns = map[string][]string{
+	"timestamp": {"timestamp", "time", "date", "datetime", "open_time", "start_time"},
+	"open":      {"open", "o"},
+	"high":      {"high", "h"},
+	"low":       {"low", "l"},
+	"close":     {"close", "c"},
+	"volume":    {"volume", "vol", "v"},
+}
+
+// csvTimeLayouts are the accepted layouts of textual timestamps, read as UTC
+var csvTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ReadCSVKlines reads candles from CSV with a header row naming the timestamp, open, high, low,
+// close and volume columns, in any order; other columns are ignored. Timestamps are Unix
+// seconds or milliseconds, or dates and times in UTC. Rows are validated (positive prices, high
+// and low around open and close, no negative volume, no duplicate timestamps) and returned in
+// ascending order.
+func ReadCSVKlines(r io.Reader) ([]bybit.KlineData, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(csvColumns))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, aliases := range csvColumns {
+			for _, alias := range aliases {
+				if _, found := columns[field]; !found && name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"timestamp", "open", "high", "low", "close", "volume"} {
+		if _, found := columns[field]; !found {
+			return nil, fmt.Errorf("CSV header has no %s column", field)
+		}
+	}
+
+	var klines []bybit.KlineData
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		kline, err := parseCSVKline(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		klines = append(klines, kline)
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("CSV has no candles")
+	}
+
+	sort.SliceStable(klines, func(i, j int) bool { return klines[i].Timestamp.Before(klines[j].Timestamp) })
+	for i := 1; i < len(klines); i++ {
+		if klines[i].Timestamp.Equal(klines[i-1].Timestamp) {
+			return nil, fmt.Errorf("duplicate candle at %s", klines[i].Timestamp.Format(time.RFC3339))
+		}
+	}
+	return klines, nil
+}
+
+// parseCSVKline parses and validates the candle of one CSV record
+func parseCSVKline(record []string, columns map[string]int) (bybit.KlineData, error) {
+	var kline bybit.KlineData
+	for field, i := range columns {
+		if i >= len(record) {
+			return kline, fmt.Errorf("missing %s", field)
+		}
+	}
+
+	timestamp, err := parseCSVTime(strings.TrimSpace(record[columns["timestamp"]]))
+	if err != nil {
+		return kline, err
+	}
+	kline.Timestamp = timestamp
+
+	values := make(map[string]decimal.Decimal, 5)
+	for _, field := range []string{"open", "high", "low", "close", "volume"} {
+		value, err := decimal.NewFromString(strings.TrimSpace(record[columns[field]]))
+		if err != nil {
+			return kline, fmt.Errorf("invalid %s %q", field, record[columns[field]])
+		}
+		values[field] = value
+	}
+	kline.Open, kline.High, kline.Low, kline.Close, kline.Volume = values["open"], values["high"], values["low"], values["close"], values["volume"]
+
+	switch {
+	case !kline.Open.IsPositive() || !kline.High.IsPositive() || !kline.Low.IsPositive() || !kline.Close.IsPositive():
+		return kline, fmt.Errorf("prices must be positive")
+	case kline.High.LessThan(decimal.Max(kline.Open, kline.Close)):
+		return kline, fmt.Errorf("high %s is below open or close", kline.High)
+	case kline.Low.GreaterThan(decimal.Min(kline.Open, kline.Close)):
+		return kline, fmt.Errorf("low %s is above open or close", kline.Low)
+	case kline.Volume.IsNegative():
+		return kline, fmt.Errorf("volume is negative")
+	}
+	return kline, nil
+}
+
+// parseCSVTime parses a Unix timestamp in seconds or milliseconds, or a UTC date and time
+func parseCSVTime(value string) (time.Time, error) {
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if number > 1e11 { // Later than 5138 in seconds, so milliseconds
+			return time.UnixMilli(number).UTC(), nil
+		}
+		return time.Unix(number, 0).UTC(), nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// LoadCSVKlines reads the candles of a CSV file, see ReadCSVKlines
+func LoadCSVKlines(path string) ([]bybit.KlineData, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".parquet", ".pq":
+		return nil, fmt.Errorf("%s: Parquet files are not supported, export them to CSV", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	klines, err := ReadCSVKlines(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return klines, nil
+}
+
+// LoadHistoricalFiles loads the candles of local CSV files for backtesting. Each source is a
+// file, a directory whose .csv files are all loaded, or SYMBOL=file. Without an explicit symbol
+// it is the file name up to the first "_", "-" or ".", so BTCUSDT_5m.csv holds BTCUSDT. Candles
+// outside the dates are dropped, except for the warm-up bars before the start date. Given
+// symbols, only their files are loaded and each of them must have one.
+func LoadHistoricalFiles(sources, symbols []string, startDate, endDate time.Time) (map[string][]bybit.KlineData, error) {
+	files, err := csvFiles(sources)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) > 0 {
+		selected := make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			symbol = strings.ToUpper(symbol)
+			path, found := files[symbol]
+			if !found {
+				return nil, fmt.Errorf("no CSV file of %s", symbol)
+			}
+			selected[symbol] = path
+		}
+		files = selected
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no CSV files to load")
+	}
+
+	data := make(map[string][]bybit.KlineData, len(files))
+	for symbol, path := range files {
+		klines, err := LoadCSVKlines(path)
+		if err != nil {
+			return nil, err
+		}
+		if klines = clipKlines(klines, startDate, endDate); len(klines) == 0 {
+			return nil, fmt.Errorf("no %s candles in %s between %s and %s", symbol, path,
+				startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+		}
+		data[symbol] = klines
+	}
+	return data, nil
+}
+
+// csvFiles returns the CSV file of each symbol in the sources, see LoadHistoricalFiles
+func csvFiles(sources []string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		if symbol, path, found := strings.Cut(source, "="); found {
+			files[strings.ToUpper(strings.TrimSpace(symbol))] = strings.TrimSpace(path)
+			continue
+		}
+
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		paths := []string{source}
+		if info.IsDir() {
+			if paths, err = filepath.Glob(filepath.Join(source, "*.csv")); err != nil {
+				return nil, err
+			}
+		}
+		for _, path := range paths {
+			files[csvSymbol(path)] = path
+		}
+	}
+	return files, nil
+}
+
+// CSVProvider is a data provider reading the klines of each symbol from a local CSV file, found
+// in the sources as LoadHistoricalFiles does. A file holds the candles of one interval; asking
+// for another interval is an error. Files are read once and kept in memory.
+type CSVProvider struct {
+	Sources []string
+	mu      sync.Mutex
+	klines  map[string][]bybit.KlineData
+}
+
+// NewCSVProvider creates a new CSVProvider of files, directories or SYMBOL=file sources
+func NewCSVProvider(sources []string) *CSVProvider {
+	return &CSVProvider{Sources: sources, klines: make(map[string][]bybit.KlineData)}
+}
+
+// GetKlines returns the candles of a symbol's CSV file that start between two times
+func (cp *CSVProvider) GetKlines(ctx context.Context, symbol, interval string, from, to time.Time) ([]bybit.KlineData, error) {
+	symbol = strings.ToUpper(symbol)
+	cp.mu.Lock()
+	klines, loaded := cp.klines[symbol]
+	if !loaded {
+		files, err := csvFiles(cp.Sources)
+		if err != nil {
+			cp.mu.Unlock()
+			return nil, err
+		}
+		path, found := files[symbol]
+		if !found {
+			cp.mu.Unlock()
+			return nil, fmt.Errorf("no CSV file of %s", symbol)
+		}
+		if klines, err = LoadCSVKlines(path); err != nil {
+			cp.mu.Unlock()
+			return nil, err
+		}
+		cp.klines[symbol] = klines
+	}
+	cp.mu.Unlock()
+
+	if err := checkCSVInterval(symbol, klines, interval); err != nil {
+		return nil, err
+	}
+	first := sort.Search(len(klines), func(i int) bool { return !klines[i].Timestamp.Before(from) })
+	last := sort.Search(len(klines), func(i int) bool { return klines[i].Timestamp.After(to) })
+	if first >= last {
+		return nil, nil
+	}
+	return append([]bybit.KlineData(nil), klines[first:last]...), nil
+}
+
+// checkCSVInterval returns an error when the closest candles of a file are not one interval
+// apart. Monthly candles vary in length and are not checked.
+func checkCSVInterval(symbol string, klines []bybit.KlineData, interval string) error {
+	length, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return err
+	}
+	if interval == "M" || len(klines) < 2 {
+		return nil
+	}
+	spacing := klines[1].Timestamp.Sub(klines[0].Timestamp)
+	for i := 2; i < len(klines); i++ {
+		if gap := klines[i].Timestamp.Sub(klines[i-1].Timestamp); gap < spacing {
+			spacing = gap
+		}
+	}
+	if spacing != length {
+		return fmt.Errorf("%s CSV candles are %s apart, not at the %s interval", symbol, spacing, interval)
+	}
+	return nil
+}
+
+// csvSymbol returns the symbol named by a CSV file
+func csvSymbol(path string) string {
+	name := filepath.Base(path)
+	if i := strings.IndexAny(name, "_-."); i > 0 {
+		name = name[:i]
+	}
+	return strings.ToUpper(name)
+}
+
+// clipKlines returns the klines up to the end date, starting MarketDataBars bars before the start
+// date, or nil when none is inside the dates
+func clipKlines(klines []bybit.KlineData, startDate, endDate time.Time) []bybit.KlineData {
+	first := sort.Search(len(klines), func(i int) bool { return !klines[i].Timestamp.Before(startDate) })
+	last := sort.Search(len(klines), func(i int) bool { return klines[i].Timestamp.After(endDate) })
+	if first >= last {
+		return nil
+	}
+	if first -= bybit.MarketDataBars; first < 0 {
+		first = 0
+	}
+	return klines[first:last]
+}
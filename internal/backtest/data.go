@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// Historical data sources
+const (
+	DataSourceBybit = "bybit" // The Bybit REST API, through the kline cache when enabled
+	DataSourceCSV   = "csv"   // The CSV files of BACKTEST_DATA_DIR
+)
+
+// NewDataProvider returns the data provider of a source; Bybit klines come from the given
+// provider, the client or the cache in front of it
+func NewDataProvider(source string, cfg *config.Config, bybitData bybit.DataProvider) (bybit.DataProvider, error) {
+	switch source {
+	case DataSourceBybit, "":
+		return bybitData, nil
+	case DataSourceCSV:
+		if cfg.BacktestDataDir == "" {
+			return nil, fmt.Errorf("BACKTEST_DATA_DIR is not set")
+		}
+		return NewCSVProvider([]string{cfg.BacktestDataDir}), nil
+	}
+	return nil, fmt.Errorf("unknown data source %q (use %s or %s)", source, DataSourceBybit, DataSourceCSV)
+}
+
+// FetchHistoricalData gets the klines of each symbol between two dates from a data provider. The
+// klines of the warm-up bars before the start date are included, so strategies have the same
+// history on the first backtested bar as the live bot has.
+func FetchHistoricalData(ctx context.Context, provider bybit.DataProvider, symbols []string, interval string, startDate, endDate time.Time) (map[string][]bybit.KlineData, error) {
+	barLength, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	from := startDate.Add(-time.Duration(bybit.MarketDataBars) * barLength)
+
+	data := make(map[string][]bybit.KlineData)
+	for _, symbol := range symbols {
+		klines, err := provider.GetKlines(ctx, symbol, interval, from, endDate)
+		if err != nil {
+			return nil, err
+		}
+		if len(klines) == 0 {
+			return nil, fmt.Errorf("no %s klines of %s between %s and %s", interval, symbol,
+				from.Format("2006-01-02"), endDate.Format("2006-01-02"))
+		}
+		data[symbol] = klines
+	}
+	return data, nil
+}
@@ -0,0 +1,419 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// position is an open simulated long position
+type position struct {
+	Quantity   float64
+	EntryPrice float64
+	EntryFee   float64
+	EntryTime  time.Time
+	Funding    float64 // Paid at funding settlements, negative when received
+	// Protective levels, 0 when not set (see Exits)
+	StopLoss     float64
+	TakeProfit   float64
+	TrailingStop float64
+	PeakPrice    float64
+	// Price range the position went through, for the trade's excursions
+	Low  float64
+	High float64
+}
+
+// observe widens the position's price range with a price it went through
+func (pos *position) observe(price float64) {
+	pos.Low, pos.High = math.Min(pos.Low, price), math.Max(pos.High, price)
+}
+
+// excursions returns the maximum adverse and favorable excursion of the position in percent of
+// its entry price
+func (pos *position) excursions() (mae, mfe float64) {
+	if pos.EntryPrice <= 0 {
+		return 0, 0
+	}
+	mae = math.Max(0, (pos.EntryPrice-pos.Low)/pos.EntryPrice*100)
+	mfe = math.Max(0, (pos.High-pos.EntryPrice)/pos.EntryPrice*100)
+	return mae, mfe
+}
+
+// symbolState is the replay state of one symbol
+type symbolState struct {
+	klines     []bybit.KlineData
+	next       int                         // Index of the next kline to replay
+	timeframes map[string]*timeframeSeries // Higher timeframes resampled from the klines
+	pending    *order                      // Order of the last signal, executed from the next bar on
+	lastPrice  float64
+	funding    []bybit.HistoricalFundingRate // Funding settlements of the symbol, see Funding
+	settled    int                           // Index of the next funding settlement
+}
+
+// order is a simulated order of a signal. Without a limit price it is a market order filled at
+// the next bar's open.
+type order struct {
+	Action      string
+	Limit       float64
+	TimeInForce string
+	StopLoss    float64 // Levels of a BUY signal protecting the position
+	TakeProfit  float64
+	Fraction    float64 // Share of the equity a BUY takes, sized at its signal (see Sizing)
+}
+
+// engine is the event-driven simulation of a backtest. Bars of all symbols are replayed in
+// chronological order. At each bar the strategy analyzes the trailing window of klines (and of
+// the higher timeframes it asks for); its signal is executed from the symbol's next bar on, so
+// no signal trades on the close it was computed from. A BUY opens a long position with the share
+// of the equity its sizing gave it at the signal, capped by the cash, and a SELL closes it. Market orders fill at
+// the next bar's open paying slippage and the taker fee. Signals with a time in force and an
+// entry price are limit orders: they fill at the open as takers when it is already through their
+// price (post-only orders are rejected instead), otherwise IOC and FOK orders are cancelled and
+// the others rest until the bar's assumed price path (see Intrabar) reaches their price, filling
+// there as makers, or until the strategy's next BUY or SELL replaces them. Open positions are
+// closed when the price path reaches their stop-loss, take-profit or trailing stop (see Exits),
+// from the bar they were opened in on. Equity is marked to market after every timestamp and open
+// positions are closed at the end.
+type engine struct {
+	strategy  strategy.Strategy
+	costs     Costs
+	intrabar  Intrabar
+	exits     Exits
+	sizing    Sizing
+	funding   float64 // Paid at funding settlements in total
+	tracker   *progressTracker
+	done      <-chan struct{} // Closed when the backtest is cancelled
+	cash      float64
+	positions map[string]*position
+	symbols   map[string]*symbolState
+	order     []string // Symbols in a stable order
+}
+
+// newEngine creates the simulation of a backtester's data
+func newEngine(bt *Backtester, initialCapital float64) *engine {
+	e := &engine{
+		strategy:  bt.Strategy,
+		costs:     bt.Costs,
+		intrabar:  bt.Intrabar,
+		exits:     bt.Exits,
+		sizing:    bt.Sizing,
+		tracker:   bt.tracker,
+		done:      bt.done(),
+		cash:      initialCapital,
+		positions: make(map[string]*position),
+		symbols:   make(map[string]*symbolState),
+	}
+
+	for symbol, klines := range bt.Data {
+		state := &symbolState{klines: klines, timeframes: make(map[string]*timeframeSeries)}
+		if len(klines) > 0 {
+			state.funding = bt.Funding.settlements(symbol, klines[0].Timestamp, klines[len(klines)-1].Timestamp)
+		}
+		for _, interval := range strategy.Timeframes(bt.Strategy) {
+			if length, err := bybit.IntervalDuration(interval); err == nil {
+				state.timeframes[interval] = &timeframeSeries{length: length}
+			}
+		}
+		e.symbols[symbol] = state
+		e.order = append(e.order, symbol)
+	}
+	sort.Strings(e.order)
+	return e
+}
+
+// run replays the bars between the start and end date into the result
+func (e *engine) run(result *BacktestResult, startDate, endDate time.Time) {
+	result.EquityCurve = append(result.EquityCurve, EquityPoint{Timestamp: startDate, Equity: result.InitialCapital})
+
+	for {
+		if e.stopped() {
+			result.Cancelled = true
+			break
+		}
+		now, ok := e.nextTimestamp(endDate)
+		if !ok {
+			break
+		}
+
+		replayed := 0
+		for _, symbol := range e.order {
+			state := e.symbols[symbol]
+			if state.next >= len(state.klines) || !state.klines[state.next].Timestamp.Equal(now) {
+				continue
+			}
+			kline := state.klines[state.next]
+			state.next++
+			replayed++
+			for _, series := range state.timeframes {
+				series.add(kline)
+			}
+			e.settleFunding(symbol, state, now)
+			state.lastPrice, _ = kline.Close.Float64()
+
+			// Bars before the start date only warm up the strategy's history
+			if now.Before(startDate) {
+				continue
+			}
+
+			// Orders and exits follow the bar's price path, exits from the entry on
+			var path []float64
+			if _, open := e.positions[symbol]; open || state.pending != nil {
+				path = e.intrabar.path(symbol, kline, state.barEnd())
+			}
+			trade, filled, rest := e.fillPending(symbol, state, kline, path, now)
+			if filled {
+				result.TradeHistory = append(result.TradeHistory, trade)
+			}
+			if trade, filled := e.checkExits(symbol, kline, rest, now); filled {
+				result.TradeHistory = append(result.TradeHistory, trade)
+			}
+
+			window := e.window(symbol, state)
+			signal := e.strategy.Analyze(window)
+			if !signal.NotReady && (signal.Action == "BUY" || signal.Action == "SELL") {
+				state.pending = &order{Action: signal.Action, StopLoss: signal.StopLoss, TakeProfit: signal.TakeProfit}
+				if signal.Action == "BUY" {
+					state.pending.Fraction = e.sizing.fraction(window, signal.StopLoss, len(e.order), result.TradeHistory)
+				}
+				if signal.TimeInForce != "" && signal.EntryPrice > 0 {
+					state.pending.Limit, state.pending.TimeInForce = signal.EntryPrice, signal.TimeInForce
+				}
+			}
+		}
+
+		if !now.Before(startDate) {
+			result.EquityCurve = append(result.EquityCurve, EquityPoint{Timestamp: now, Equity: e.equity()})
+		}
+		e.tracker.advance(replayed)
+	}
+
+	// Close what is still open at the last close
+	last := endDate
+	if len(result.EquityCurve) > 0 {
+		last = result.EquityCurve[len(result.EquityCurve)-1].Timestamp
+	}
+	for _, symbol := range e.order {
+		state := e.symbols[symbol]
+		if state.next == 0 {
+			continue
+		}
+		if trade, filled := e.execute(symbol, "SELL", ExitEnd, 0, state.klines[state.next-1], state.lastPrice, false, last); filled {
+			result.TradeHistory = append(result.TradeHistory, trade)
+		}
+	}
+
+	result.FinalCapital = e.equity()
+	result.TotalFunding = e.funding
+	if len(result.EquityCurve) > 0 {
+		result.EquityCurve[len(result.EquityCurve)-1].Equity = result.FinalCapital
+	}
+}
+
+// stopped reports whether the backtest was cancelled
+func (e *engine) stopped() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextTimestamp returns the earliest timestamp of the symbols' next bars up to the end date
+func (e *engine) nextTimestamp(endDate time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, state := range e.symbols {
+		if state.next >= len(state.klines) {
+			continue
+		}
+		timestamp := state.klines[state.next].Timestamp
+		if timestamp.After(endDate) {
+			continue
+		}
+		if !found || timestamp.Before(next) {
+			next, found = timestamp, true
+		}
+	}
+	return next, found
+}
+
+// window returns the market data the strategy sees at a symbol's current bar
+func (e *engine) window(symbol string, state *symbolState) *bybit.MarketData {
+	from := state.next - bybit.MarketDataBars
+	if from < 0 {
+		from = 0
+	}
+	current := state.klines[state.next-1]
+	marketData := &bybit.MarketData{Symbol: symbol, Timestamp: current.Timestamp, Kline: state.klines[from:state.next]}
+	if len(state.timeframes) > 0 {
+		marketData.Timeframes = make(map[string][]bybit.KlineData)
+		for interval, series := range state.timeframes {
+			marketData.Timeframes[interval] = series.window(bybit.MarketDataBars)
+		}
+	}
+	return marketData
+}
+
+// fillPending executes the pending order of a symbol on its current bar along the bar's price
+// path, returning the trade it closed and the rest of the path from the fill on
+func (e *engine) fillPending(symbol string, state *symbolState, kline bybit.KlineData, path []float64, now time.Time) (TradeRecord, bool, []float64) {
+	pending := state.pending
+	if pending == nil {
+		return TradeRecord{}, false, path
+	}
+	open, _ := kline.Open.Float64()
+	if pending.Limit <= 0 {
+		state.pending = nil
+		trade, filled := e.fill(symbol, pending, kline, open, false, now)
+		return trade, filled, path
+	}
+
+	// Long only: a BUY needs no open position and a SELL one
+	if _, holding := e.positions[symbol]; holding == (pending.Action == "BUY") {
+		state.pending = nil
+		return TradeRecord{}, false, path
+	}
+
+	if pending.Action == "BUY" && open <= pending.Limit || pending.Action == "SELL" && open >= pending.Limit {
+		state.pending = nil
+		if pending.TimeInForce == bybit.TimeInForcePostOnly {
+			return TradeRecord{}, false, path // Rejected, it would take liquidity
+		}
+		trade, filled := e.fill(symbol, pending, kline, open, false, now)
+		return trade, filled, path
+	}
+	if pending.TimeInForce == bybit.TimeInForceIOC || pending.TimeInForce == bybit.TimeInForceFOK {
+		state.pending = nil
+		return TradeRecord{}, false, path
+	}
+
+	_, segment := touch(path, []float64{pending.Limit})
+	if segment < 0 {
+		return TradeRecord{}, false, path
+	}
+	state.pending = nil
+	trade, filled := e.fill(symbol, pending, kline, pending.Limit, true, now)
+	return trade, filled, append([]float64{pending.Limit}, path[segment:]...)
+}
+
+// fill executes an order at a price, protecting a position it opens with its exits
+func (e *engine) fill(symbol string, o *order, kline bybit.KlineData, price float64, maker bool, now time.Time) (TradeRecord, bool) {
+	trade, filled := e.execute(symbol, o.Action, ExitSignal, o.Fraction, kline, price, maker, now)
+	if pos, open := e.positions[symbol]; open && o.Action == "BUY" && pos.EntryTime.Equal(now) {
+		e.exits.protect(symbol, pos, o.StopLoss, o.TakeProfit)
+	}
+	return trade, filled
+}
+
+// barEnd returns when the symbol's current bar ends: at the start of the next bar, or one bar
+// length after its start for the last one
+func (state *symbolState) barEnd() time.Time {
+	current := state.klines[state.next-1].Timestamp
+	if state.next < len(state.klines) {
+		return state.klines[state.next].Timestamp
+	}
+	if state.next >= 2 {
+		return current.Add(current.Sub(state.klines[state.next-2].Timestamp))
+	}
+	return current.Add(time.Nanosecond)
+}
+
+// execute fills a signal's action at a price in a bar, returning the trade it closed for a reason.
+// A BUY takes the fraction of the equity. The strategy is told about the fill and the closed
+// position, as the live bot tells it.
+func (e *engine) execute(symbol, action, reason string, fraction float64, kline bybit.KlineData, price float64, maker bool, now time.Time) (TradeRecord, bool) {
+	if price <= 0 {
+		return TradeRecord{}, false
+	}
+
+	switch pos, open := e.positions[symbol]; {
+	case action == "BUY" && !open:
+		value := e.equity() * fraction
+		if value > e.cash {
+			value = e.cash
+		}
+		if value <= 0 {
+			return TradeRecord{}, false
+		}
+		// The order value covers the fee
+		feePercent := e.costs.TakerFeePercent
+		if maker {
+			feePercent = e.costs.MakerFeePercent
+		}
+		fillPrice, _ := e.costs.apply(Fill{Symbol: symbol, Side: "BUY", Quantity: value / price, Price: price, Kline: kline}, maker)
+		quantity := value / (fillPrice * (1 + feePercent/100))
+		fee := fillPrice * quantity * feePercent / 100
+		e.cash -= fillPrice*quantity + fee
+		e.positions[symbol] = &position{Quantity: quantity, EntryPrice: fillPrice, EntryFee: fee, EntryTime: now, Low: fillPrice, High: fillPrice}
+		strategy.NotifyFill(e.strategy, strategy.Fill{Symbol: symbol, Side: "BUY", Quantity: quantity, Price: fillPrice, Timestamp: now})
+
+	case action == "SELL" && open:
+		fillPrice, fee := e.costs.apply(Fill{Symbol: symbol, Side: "SELL", Quantity: pos.Quantity, Price: price, Kline: kline}, maker)
+		e.cash += pos.Quantity*fillPrice - fee
+		delete(e.positions, symbol)
+		pos.observe(price)
+		mae, mfe := pos.excursions()
+		trade := TradeRecord{
+			Timestamp:  pos.EntryTime,
+			ExitTime:   now,
+			Symbol:     symbol,
+			Action:     "BUY",
+			Quantity:   pos.Quantity,
+			EntryPrice: pos.EntryPrice,
+			ExitPrice:  fillPrice,
+			PnL:        (fillPrice - pos.EntryPrice) * pos.Quantity,
+			Commission: pos.EntryFee + fee,
+			Funding:    pos.Funding,
+			MAE:        mae,
+			MFE:        mfe,
+			ExitReason: reason,
+		}
+		strategy.NotifyFill(e.strategy, strategy.Fill{Symbol: symbol, Side: "SELL", Quantity: pos.Quantity, Price: fillPrice, Timestamp: now})
+		strategy.NotifyPositionClosed(e.strategy, strategy.ClosedPosition{
+			Symbol:     symbol,
+			Quantity:   pos.Quantity,
+			EntryPrice: pos.EntryPrice,
+			ExitPrice:  fillPrice,
+			PnL:        trade.PnL,
+			Reason:     reason,
+			Timestamp:  now,
+		})
+		return trade, true
+	}
+
+	return TradeRecord{}, false
+}
+
+// settleFunding settles the symbol's funding up to a time on its open position, valued at the
+// last close before the settlement
+func (e *engine) settleFunding(symbol string, state *symbolState, now time.Time) {
+	for state.settled < len(state.funding) && !state.funding[state.settled].Timestamp.After(now) {
+		settlement := state.funding[state.settled]
+		state.settled++
+		pos, open := e.positions[symbol]
+		if !open {
+			continue
+		}
+		rate, _ := settlement.Rate.Float64()
+		payment := rate * pos.Quantity * state.lastPrice
+		pos.Funding += payment
+		e.funding += payment
+		e.cash -= payment
+	}
+}
+
+// equity returns the cash plus the open positions at the last prices
+func (e *engine) equity() float64 {
+	// Summed in symbol order, so floating point rounding repeats across runs
+	equity := e.cash
+	for _, symbol := range e.order {
+		if pos, open := e.positions[symbol]; open {
+			equity += pos.Quantity * e.symbols[symbol].lastPrice
+		}
+	}
+	return equity
+}
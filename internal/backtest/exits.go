@@ -0,0 +1,150 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// Reasons a backtest position was closed
+const (
+	ExitSignal       = "SIGNAL"
+	ExitStopLoss     = "STOP_LOSS"
+	ExitTakeProfit   = "TAKE_PROFIT"
+	ExitTrailingStop = "TRAILING_STOP"
+	ExitEnd          = "END" // Still open at the end of the backtest
+)
+
+// Exits configures the protective exits of backtest positions the way the live risk engine sets
+// them: a stop-loss and take-profit at percentages of the entry price, replaced by the levels of
+// the strategy's BUY signal, and a trailing stop activated by a gain. The zero value only honors
+// the signals' levels.
+type Exits struct {
+	StopLossPercent     float64            // Below the entry price, 0 for no default stop-loss
+	TakeProfitPercent   float64            // Above the entry price, 0 for no default take-profit
+	StopLossOverrides   map[string]float64 // Per-symbol percentages ("*" applies to all other symbols)
+	TakeProfitOverrides map[string]float64
+	Trailing            bool
+	// Trailing stops activate after the gain, then follow the highest price at the distance
+	TrailingActivationPercent float64
+	TrailingStopPercent       float64 // 0 uses the stop-loss percentage
+}
+
+// ExitsFromConfig returns the exits of the configured risk engine, or only the signals' levels
+// when BACKTEST_RISK_EXITS is off
+func ExitsFromConfig(cfg *config.Config) Exits {
+	if !cfg.BacktestRiskExits {
+		return Exits{}
+	}
+	return Exits{
+		StopLossPercent:           cfg.GetStopLossPercent(),
+		TakeProfitPercent:         cfg.GetTakeProfitPercent(),
+		StopLossOverrides:         cfg.StopLossOverrides,
+		TakeProfitOverrides:       cfg.TakeProfitOverrides,
+		Trailing:                  true,
+		TrailingActivationPercent: cfg.GetTrailingStopActivationPercent(),
+		TrailingStopPercent:       cfg.GetTrailingStopPercent(),
+	}
+}
+
+// stopLossPercent returns the stop-loss percentage of a symbol, honoring per-symbol overrides
+func (x Exits) stopLossPercent(symbol string) float64 {
+	if val, ok := config.SymbolValue(x.StopLossOverrides, symbol); ok {
+		return val
+	}
+	return x.StopLossPercent
+}
+
+// takeProfitPercent returns the take-profit percentage of a symbol, honoring per-symbol overrides
+func (x Exits) takeProfitPercent(symbol string) float64 {
+	if val, ok := config.SymbolValue(x.TakeProfitOverrides, symbol); ok {
+		return val
+	}
+	return x.TakeProfitPercent
+}
+
+// protect sets the stop-loss and take-profit levels of a new position, the signal's levels
+// replacing the percentage-based ones
+func (x Exits) protect(symbol string, pos *position, stopLoss, takeProfit float64) {
+	if percent := x.stopLossPercent(symbol); percent > 0 {
+		pos.StopLoss = pos.EntryPrice * (1 - percent/100)
+	}
+	if percent := x.takeProfitPercent(symbol); percent > 0 {
+		pos.TakeProfit = pos.EntryPrice * (1 + percent/100)
+	}
+	if stopLoss > 0 {
+		pos.StopLoss = stopLoss
+	}
+	if takeProfit > 0 {
+		pos.TakeProfit = takeProfit
+	}
+}
+
+// trail moves the trailing stop of a position with a price, as the risk engine does with live
+// prices: it is activated at the trailing distance below the price once the gain over the entry
+// price reaches the threshold, then follows new highs and never moves down
+func (x Exits) trail(symbol string, pos *position, price float64) {
+	if !x.Trailing || pos.EntryPrice <= 0 {
+		return
+	}
+	distance := x.TrailingStopPercent
+	if distance <= 0 {
+		distance = x.stopLossPercent(symbol)
+	}
+
+	if pos.TrailingStop <= 0 {
+		if (price-pos.EntryPrice)/pos.EntryPrice*100 >= x.TrailingActivationPercent {
+			pos.TrailingStop = price * (1 - distance/100)
+			pos.PeakPrice = price
+		}
+		return
+	}
+	if price > pos.PeakPrice {
+		pos.PeakPrice = price
+		if level := price * (1 - distance/100); level > pos.TrailingStop {
+			pos.TrailingStop = level
+		}
+	}
+}
+
+// checkExits walks a position along the path of its bar, closing it at the first protective
+// level the path reaches, and widens its price range with the prices it goes through. A path starting beyond a level, like a gap through the stop, closes it
+// at the first price. Exits fill as takers, like the risk engine's stop-market orders.
+func (e *engine) checkExits(symbol string, kline bybit.KlineData, path []float64, now time.Time) (TradeRecord, bool) {
+	pos, open := e.positions[symbol]
+	if !open {
+		return TradeRecord{}, false
+	}
+
+	for i, price := range path {
+		stop, reason := pos.StopLoss, ExitStopLoss
+		if pos.TrailingStop > stop {
+			stop, reason = pos.TrailingStop, ExitTrailingStop
+		}
+
+		exit := 0.0
+		if i == 0 {
+			switch {
+			case stop > 0 && price <= stop:
+				exit = price
+			case pos.TakeProfit > 0 && price >= pos.TakeProfit:
+				exit, reason = price, ExitTakeProfit
+			}
+		} else {
+			switch firstTouch(path[i-1:i+1], []float64{stop, pos.TakeProfit}) {
+			case 0:
+				exit = stop
+			case 1:
+				exit, reason = pos.TakeProfit, ExitTakeProfit
+			}
+		}
+		if exit > 0 {
+			return e.execute(symbol, "SELL", reason, 0, kline, exit, false, now)
+		}
+
+		pos.observe(price)
+		e.exits.trail(symbol, pos, price)
+	}
+	return TradeRecord{}, false
+}
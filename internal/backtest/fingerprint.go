@@ -0,0 +1,108 @@
+package backtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// CodeVersion returns the VCS revision the binary was built from, with a "-dirty" suffix for
+// uncommitted changes, falling back to the module version or "unknown"
+func CodeVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		if info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+		return "unknown"
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// runSettings are the inputs of a run besides its klines that a fingerprint covers
+type runSettings struct {
+	Code           string             `json:"code"`
+	Strategy       string             `json:"strategy"`
+	Parameters     map[string]float64 `json:"parameters"`
+	InitialCapital float64            `json:"initial_capital"`
+	StartDate      time.Time          `json:"start_date"`
+	EndDate        time.Time          `json:"end_date"`
+	Slippage       string             `json:"slippage"`
+	MakerFee       float64            `json:"maker_fee"`
+	TakerFee       float64            `json:"taker_fee"`
+	IntrabarPath   string             `json:"intrabar_path"`
+	Exits          Exits              `json:"exits"`
+	Sizing         Sizing             `json:"sizing"`
+	Funding        Funding            `json:"funding"`
+	Seed           int64              `json:"seed"`
+}
+
+// Fingerprint returns a hash of everything a run between two dates depends on: the code version,
+// the strategy and its parameters, the capital, costs, intrabar, exit, sizing and funding settings, the seed and
+// every kline. Runs with equal fingerprints produce equal results. Hashing the klines takes a
+// while, so Run leaves it to callers keeping the result.
+func (bt *Backtester) Fingerprint(initialCapital float64, startDate, endDate time.Time) string {
+	settings := runSettings{
+		Code:           CodeVersion(),
+		Strategy:       bt.Strategy.GetName(),
+		Parameters:     bt.Strategy.GetParameters(),
+		InitialCapital: initialCapital,
+		StartDate:      startDate.UTC(),
+		EndDate:        endDate.UTC(),
+		Slippage:       fmt.Sprintf("%T%+v", bt.Costs.Slippage, bt.Costs.Slippage),
+		MakerFee:       bt.Costs.MakerFeePercent,
+		TakerFee:       bt.Costs.TakerFeePercent,
+		IntrabarPath:   bt.Intrabar.Path,
+		Exits:          bt.Exits,
+		Sizing:         bt.Sizing,
+		Funding:        bt.Funding,
+		Seed:           bt.Seed,
+	}
+
+	hash := sha256.New()
+	json.NewEncoder(hash).Encode(settings)
+	hashKlines(hash, "data", bt.Data)
+	hashKlines(hash, "intrabar", bt.Intrabar.Klines)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// hashKlines writes the klines of each symbol to a hash in symbol order
+func hashKlines(hash io.Writer, label string, data map[string][]bybit.KlineData) {
+	symbols := make([]string, 0, len(data))
+	for symbol := range data {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	fmt.Fprintf(hash, "%s %d\n", label, len(symbols))
+	for _, symbol := range symbols {
+		fmt.Fprintf(hash, "%s %d\n", symbol, len(data[symbol]))
+		for _, kline := range data[symbol] {
+			fmt.Fprintf(hash, "%s %s %s %s %s %s\n", strconv.FormatInt(kline.Timestamp.UnixMilli(), 10),
+				kline.Open, kline.High, kline.Low, kline.Close, kline.Volume)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package backtest
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/shopspring/decimal"
+)
+
+// Funding sources of backtests
+const (
+	FundingNone  = "none"  // Positions pay no carry
+	FundingBybit = "bybit" // Historical funding rates of the Bybit REST API
+	FundingCSV   = "csv"   // The CSV files of BACKTEST_FUNDING_DIR
+	FundingFlat  = "flat"  // BACKTEST_FUNDING_RATE at every settlement
+)
+
+// fundingInterval is the time between funding settlements of Bybit's perpetuals, which settle at
+// 00:00, 08:00 and 16:00 UTC
+const fundingInterval = 8 * time.Hour
+
+// Funding configures the carry of backtest positions held as linear perpetuals: at each
+// settlement an open position pays the rate times its value at the last close, or receives it
+// when the rate is negative
+type Funding struct {
+	Rates    map[string][]bybit.HistoricalFundingRate // Settled rates per symbol, oldest first
+	FlatRate float64                                  // Rate of every settlement of symbols without rates, e.g. a borrow cost
+}
+
+// settlements returns the funding settlements of a symbol between two times: its historical
+// rates, or the flat rate every funding interval
+func (f Funding) settlements(symbol string, from, to time.Time) []bybit.HistoricalFundingRate {
+	if rates, exists := f.Rates[symbol]; exists {
+		return rates
+	}
+	if f.FlatRate == 0 {
+		return nil
+	}
+
+	var settlements []bybit.HistoricalFundingRate
+	rate := decimal.NewFromFloat(f.FlatRate)
+	for timestamp := from.Truncate(fundingInterval); !timestamp.After(to); timestamp = timestamp.Add(fundingInterval) {
+		if !timestamp.Before(from) {
+			settlements = append(settlements, bybit.HistoricalFundingRate{Rate: rate, Timestamp: timestamp})
+		}
+	}
+	return settlements
+}
+
+// ValidFundingSource returns an error for an unknown funding source
+func ValidFundingSource(source string) error {
+	switch source {
+	case "", FundingNone, FundingBybit, FundingCSV, FundingFlat:
+		return nil
+	}
+	return fmt.Errorf("unknown funding source %q (use %s, %s, %s or %s)", source, FundingNone, FundingBybit, FundingCSV, FundingFlat)
+}
+
+// LoadFunding returns the funding of a source for the symbols between two dates, fetching Bybit's
+// rates from the given provider
+func LoadFunding(ctx context.Context, source string, cfg *config.Config, bybitFunding bybit.FundingProvider, symbols []string, startDate, endDate time.Time) (Funding, error) {
+	if err := ValidFundingSource(source); err != nil {
+		return Funding{}, err
+	}
+	var provider bybit.FundingProvider = bybitFunding
+	switch source {
+	case FundingNone, "":
+		return Funding{}, nil
+	case FundingFlat:
+		return Funding{FlatRate: cfg.BacktestFundingRate}, nil
+	case FundingCSV:
+		if cfg.BacktestFundingDir == "" {
+			return Funding{}, fmt.Errorf("BACKTEST_FUNDING_DIR is not set")
+		}
+		provider = NewCSVFundingProvider([]string{cfg.BacktestFundingDir})
+	}
+
+	funding := Funding{Rates: make(map[string][]bybit.HistoricalFundingRate)}
+	for _, symbol := range symbols {
+		rates, err := provider.GetFundingHistory(ctx, symbol, startDate, endDate)
+		if err != nil {
+			return Funding{}, fmt.Errorf("failed to load funding rates: %w", err)
+		}
+		funding.Rates[symbol] = rates
+	}
+	return funding, nil
+}
+
+// csvFundingColumns are the accepted header names of the funding rate column, matched
+// case-insensitively
+var csvFundingColumns = []string{"funding_rate", "fundingrate", "rate"}
+
+// ReadCSVFunding reads funding rates from CSV with a header row naming the timestamp column (with
+// the names of candle files) and the funding rate column, in any order; other columns are
+// ignored. Timestamps are read like those of candles, see ReadCSVKlines. Rates must not repeat a
+// timestamp and are returned in ascending order.
+func ReadCSVFunding(r io.Reader) ([]bybit.HistoricalFundingRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	timeColumn, rateColumn := -1, -1
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, alias := range append(csvColumns["timestamp"], "fundingratetimestamp") {
+			if timeColumn < 0 && name == alias {
+				timeColumn = i
+			}
+		}
+		for _, alias := range csvFundingColumns {
+			if rateColumn < 0 && name == alias {
+				rateColumn = i
+			}
+		}
+	}
+	if timeColumn < 0 {
+		return nil, fmt.Errorf("CSV header has no timestamp column")
+	}
+	if rateColumn < 0 {
+		return nil, fmt.Errorf("CSV header has no funding_rate column")
+	}
+
+	var rates []bybit.HistoricalFundingRate
+	seen := make(map[int64]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if timeColumn >= len(record) || rateColumn >= len(record) {
+			return nil, fmt.Errorf("line %d: missing columns", line)
+		}
+		timestamp, err := parseCSVTime(strings.TrimSpace(record[timeColumn]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[rateColumn]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid funding rate %q", line, record[rateColumn])
+		}
+		if seen[timestamp.UnixMilli()] {
+			return nil, fmt.Errorf("line %d: duplicate timestamp %s", line, timestamp.Format(time.RFC3339))
+		}
+		seen[timestamp.UnixMilli()] = true
+		rates = append(rates, bybit.HistoricalFundingRate{Rate: rate, Timestamp: timestamp})
+	}
+
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Timestamp.Before(rates[j].Timestamp) })
+	return rates, nil
+}
+
+// CSVFundingProvider is a funding provider reading the funding rates of each symbol from a local
+// CSV file, found in the sources as LoadHistoricalFiles finds candles. Files are read once and
+// kept in memory.
+type CSVFundingProvider struct {
+	Sources []string
+	mu      sync.Mutex
+	rates   map[string][]bybit.HistoricalFundingRate
+}
+
+// NewCSVFundingProvider creates a new CSVFundingProvider of files, directories or SYMBOL=file
+// sources
+func NewCSVFundingProvider(sources []string) *CSVFundingProvider {
+	return &CSVFundingProvider{Sources: sources, rates: make(map[string][]bybit.HistoricalFundingRate)}
+}
+
+// GetFundingHistory returns the funding rates of a symbol's CSV file settled between two times
+func (cp *CSVFundingProvider) GetFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]bybit.HistoricalFundingRate, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	symbol = strings.ToUpper(symbol)
+	rates, loaded := cp.rates[symbol]
+	if !loaded {
+		files, err := csvFiles(cp.Sources)
+		if err != nil {
+			return nil, err
+		}
+		path, found := files[symbol]
+		if !found {
+			return nil, fmt.Errorf("no funding CSV file of %s", symbol)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		rates, err = ReadCSVFunding(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		cp.rates[symbol] = rates
+	}
+
+	first := sort.Search(len(rates), func(i int) bool { return !rates[i].Timestamp.Before(from) })
+	last := sort.Search(len(rates), func(i int) bool { return rates[i].Timestamp.After(to) })
+	if first >= last {
+		return nil, nil
+	}
+	return append([]bybit.HistoricalFundingRate(nil), rates[first:last]...), nil
+}
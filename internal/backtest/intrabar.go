@@ -0,0 +1,100 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Assumptions about the order in which a bar visits its prices
+const (
+	IntrabarNearest = "nearest" // Open, the extreme nearer to the open, the other extreme, close
+	IntrabarOHLC    = "ohlc"    // Open, high, low, close
+	IntrabarOLHC    = "olhc"    // Open, low, high, close; pessimistic for longs, stops before targets
+)
+
+// Intrabar configures how prices are assumed to move within a bar when resting limit orders are
+// checked for fills. With lower timeframe klines of a symbol, the path of each bar follows its
+// sub-bars instead, and the assumption only orders the prices within each sub-bar.
+type Intrabar struct {
+	Path   string                       // One of the Intrabar constants, default IntrabarNearest
+	Klines map[string][]bybit.KlineData // Optional lower timeframe klines per symbol
+}
+
+// ValidIntrabarPath returns an error for an unknown intrabar path assumption
+func ValidIntrabarPath(path string) error {
+	switch path {
+	case "", IntrabarNearest, IntrabarOHLC, IntrabarOLHC:
+		return nil
+	}
+	return fmt.Errorf("unknown intrabar path %q (use %s, %s or %s)", path, IntrabarNearest, IntrabarOHLC, IntrabarOLHC)
+}
+
+// path returns the prices a symbol's bar is assumed to visit in order, from its open to its
+// close. The bar lasts until barEnd.
+func (ib Intrabar) path(symbol string, kline bybit.KlineData, barEnd time.Time) []float64 {
+	if sub := ib.Klines[symbol]; len(sub) > 0 {
+		first := sort.Search(len(sub), func(i int) bool { return !sub[i].Timestamp.Before(kline.Timestamp) })
+		var path []float64
+		for i := first; i < len(sub) && sub[i].Timestamp.Before(barEnd); i++ {
+			path = append(path, barPath(sub[i], ib.Path)...)
+		}
+		if len(path) > 0 {
+			return path
+		}
+	}
+	return barPath(kline, ib.Path)
+}
+
+// barPath returns the open, high, low and close of a bar in the order of an assumption
+func barPath(kline bybit.KlineData, assumption string) []float64 {
+	open, _ := kline.Open.Float64()
+	high, _ := kline.High.Float64()
+	low, _ := kline.Low.Float64()
+	closePrice, _ := kline.Close.Float64()
+
+	lowFirst := false
+	switch assumption {
+	case IntrabarOLHC:
+		lowFirst = true
+	case IntrabarOHLC:
+	default:
+		lowFirst = math.Abs(open-low) < math.Abs(high-open)
+	}
+	if lowFirst {
+		return []float64{open, low, high, closePrice}
+	}
+	return []float64{open, high, low, closePrice}
+}
+
+// firstTouch returns the index of the price level a path reaches first, or -1 if it reaches none.
+// A level is reached when the path moves through or onto it; levels reached on the same move are
+// ordered by their distance from where the move started.
+func firstTouch(path []float64, levels []float64) int {
+	level, _ := touch(path, levels)
+	return level
+}
+
+// touch returns the index of the price level a path reaches first, see firstTouch, and the index
+// of the path's price the move reaching it ends at, both -1 if it reaches none
+func touch(path []float64, levels []float64) (int, int) {
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		low, high := math.Min(from, to), math.Max(from, to)
+		best, bestDistance := -1, math.Inf(1)
+		for j, level := range levels {
+			if level >= low && level <= high {
+				if distance := math.Abs(level - from); distance < bestDistance {
+					best, bestDistance = j, distance
+				}
+			}
+		}
+		if best >= 0 {
+			return best, i
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,189 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// calculateMetrics derives the summary statistics of a result from its closed trades and its
+// mark-to-market equity curve. Sharpe and Sortino ratios are annualized from the returns between
+// equity points, and exposure is the union of the trades' holding periods. The trade analytics
+// summarize the trades' excursions and holding times.
+func calculateMetrics(result *BacktestResult) {
+	result.TotalTrades = len(result.TradeHistory)
+	result.WinningTrades, result.LosingTrades = 0, 0
+	for _, trade := range result.TradeHistory {
+		if trade.PnL-trade.Commission-trade.Funding > 0 {
+			result.WinningTrades++
+		} else {
+			result.LosingTrades++
+		}
+	}
+
+	if result.TotalTrades > 0 {
+		result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades) * 100
+	}
+	if result.InitialCapital > 0 {
+		result.TotalReturn = (result.FinalCapital - result.InitialCapital) / result.InitialCapital * 100
+	}
+	result.MaxDrawdown = maxDrawdown(result.EquityCurve)
+
+	returns := make([]float64, 0, len(result.EquityCurve))
+	for i := 1; i < len(result.EquityCurve); i++ {
+		if previous := result.EquityCurve[i-1].Equity; previous > 0 {
+			returns = append(returns, result.EquityCurve[i].Equity/previous-1)
+		}
+	}
+	sharpe, sortino := riskAdjustedRatios(returns)
+	annualization := math.Sqrt(periodsPerYear(result.EquityCurve))
+	result.SharpeRatio, result.SortinoRatio = sharpe*annualization, sortino*annualization
+
+	result.CAGR = cagr(result.InitialCapital, result.FinalCapital, result.EndDate.Sub(result.StartDate))
+	result.CalmarRatio = 0
+	if result.MaxDrawdown > 0 {
+		result.CalmarRatio = result.CAGR / result.MaxDrawdown
+	}
+	result.MaxDrawdownDays, result.AvgDrawdownDays = drawdownDurations(result.EquityCurve)
+	result.ExposurePercent = exposure(result.TradeHistory, result.EquityCurve)
+	result.TradeAnalytics = AnalyzeTrades(tradeExcursions(result.TradeHistory))
+}
+
+// cagr returns the compound annual growth rate in percent of growing from the initial to the
+// final capital over a period, 0 when it is undefined
+func cagr(initialCapital, finalCapital float64, period time.Duration) float64 {
+	years := period.Hours() / (24 * 365)
+	if years <= 0 || initialCapital <= 0 || finalCapital <= 0 {
+		return 0
+	}
+	return (math.Pow(finalCapital/initialCapital, 1/years) - 1) * 100
+}
+
+// drawdownDurations returns the longest and the average time in days the equity curve spent below
+// a previous peak. A drawdown still open at the end lasts until the last point.
+func drawdownDurations(curve []EquityPoint) (maxDays, avgDays float64) {
+	var durations []float64
+	peak, peakTime, underwater := 0.0, time.Time{}, false
+	for _, point := range curve {
+		switch {
+		case point.Equity >= peak:
+			if underwater {
+				durations = append(durations, point.Timestamp.Sub(peakTime).Hours()/24)
+				underwater = false
+			}
+			peak, peakTime = point.Equity, point.Timestamp
+		case !underwater:
+			underwater = true
+		}
+	}
+	if underwater {
+		durations = append(durations, curve[len(curve)-1].Timestamp.Sub(peakTime).Hours()/24)
+	}
+
+	for _, days := range durations {
+		maxDays = math.Max(maxDays, days)
+		avgDays += days
+	}
+	if len(durations) > 0 {
+		avgDays /= float64(len(durations))
+	}
+	return maxDays, avgDays
+}
+
+// exposure returns the percentage of the equity curve's span during which at least one trade was
+// open
+func exposure(trades []TradeRecord, curve []EquityPoint) float64 {
+	if len(curve) < 2 {
+		return 0
+	}
+	start, end := curve[0].Timestamp, curve[len(curve)-1].Timestamp
+	span := end.Sub(start)
+	if span <= 0 {
+		return 0
+	}
+
+	intervals := make([][2]time.Time, 0, len(trades))
+	for _, trade := range trades {
+		entry, exit := trade.Timestamp, trade.ExitTime
+		if entry.Before(start) {
+			entry = start
+		}
+		if exit.After(end) {
+			exit = end
+		}
+		if exit.After(entry) {
+			intervals = append(intervals, [2]time.Time{entry, exit})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0].Before(intervals[j][0]) })
+
+	var inMarket time.Duration
+	var coveredUntil time.Time
+	for _, interval := range intervals {
+		if interval[0].Before(coveredUntil) {
+			interval[0] = coveredUntil
+		}
+		if interval[1].After(interval[0]) {
+			inMarket += interval[1].Sub(interval[0])
+			coveredUntil = interval[1]
+		}
+	}
+	return float64(inMarket) / float64(span) * 100
+}
+
+// periodsPerYear returns how many equity points a year has at the curve's typical (median)
+// spacing
+func periodsPerYear(curve []EquityPoint) float64 {
+	gaps := make([]float64, 0, len(curve))
+	for i := 1; i < len(curve); i++ {
+		if gap := curve[i].Timestamp.Sub(curve[i-1].Timestamp).Hours(); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	if len(gaps) == 0 {
+		return 1
+	}
+	sort.Float64s(gaps)
+	return 365 * 24 / gaps[len(gaps)/2]
+}
+
+// maxDrawdown returns the largest fall of an equity curve from its running peak, in percent
+func maxDrawdown(curve []EquityPoint) float64 {
+	peak, drawdown := 0.0, 0.0
+	for _, point := range curve {
+		peak = math.Max(peak, point.Equity)
+		if peak > 0 {
+			drawdown = math.Max(drawdown, (peak-point.Equity)/peak*100)
+		}
+	}
+	return drawdown
+}
+
+// riskAdjustedRatios returns the per-period Sharpe and Sortino ratios of a series of returns, 0 without
+// enough returns or without dispersion
+func riskAdjustedRatios(returns []float64) (sharpe, sortino float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance, downside := 0.0, 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	if std := math.Sqrt(variance / float64(len(returns)-1)); std > 0 {
+		sharpe = mean / std
+	}
+	if downsideDev := math.Sqrt(downside / float64(len(returns))); downsideDev > 0 {
+		sortino = mean / downsideDev
+	}
+	return sharpe, sortino
+}
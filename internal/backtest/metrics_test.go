@@ -0,0 +1,73 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// curve builds an equity curve of daily points
+func curve(start time.Time, equities ...float64) []EquityPoint {
+	points := make([]EquityPoint, len(equities))
+	for i, equity := range equities {
+		points[i] = EquityPoint{Timestamp: start.AddDate(0, 0, i), Equity: equity}
+	}
+	return points
+}
+
+func TestDrawdownMetrics(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name             string
+		curve            []EquityPoint
+		maxDrawdown      float64
+		maxDays, avgDays float64
+	}{
+		{"rising", curve(start, 100, 110, 120), 0, 0, 0},
+		{"recovered", curve(start, 100, 80, 90, 100, 120), 20, 3, 3},
+		{"two drawdowns", curve(start, 100, 90, 100, 50, 75, 80), 50, 3, 2.5},
+		{"empty", nil, 0, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := maxDrawdown(tc.curve); math.Abs(got-tc.maxDrawdown) > 1e-9 {
+			t.Errorf("%s: maxDrawdown = %v, want %v", tc.name, got, tc.maxDrawdown)
+		}
+		maxDays, avgDays := drawdownDurations(tc.curve)
+		if maxDays != tc.maxDays || avgDays != tc.avgDays {
+			t.Errorf("%s: drawdownDurations = %v, %v, want %v, %v", tc.name, maxDays, avgDays, tc.maxDays, tc.avgDays)
+		}
+	}
+}
+
+func TestCAGR(t *testing.T) {
+	year := 365 * 24 * time.Hour
+	cases := []struct {
+		name           string
+		initial, final float64
+		period         time.Duration
+		want           float64
+	}{
+		{"one year", 100, 120, year, 20},
+		{"two years", 100, 121, 2 * year, 10},
+		{"no period", 100, 120, 0, 0},
+		{"wiped out", 100, 0, year, 0},
+	}
+	for _, tc := range cases {
+		if got := cagr(tc.initial, tc.final, tc.period); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: cagr = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRiskAdjustedRatios(t *testing.T) {
+	if sharpe, sortino := riskAdjustedRatios([]float64{0.01}); sharpe != 0 || sortino != 0 {
+		t.Errorf("single return ratios = %v, %v, want 0, 0", sharpe, sortino)
+	}
+	if sharpe, sortino := riskAdjustedRatios([]float64{0.01, 0.01, 0.01}); sharpe != 0 || sortino != 0 {
+		t.Errorf("constant returns ratios = %v, %v, want 0, 0", sharpe, sortino)
+	}
+	sharpe, sortino := riskAdjustedRatios([]float64{0.02, -0.01, 0.03, -0.02})
+	if sharpe <= 0 || sortino <= sharpe {
+		t.Errorf("ratios = %v, %v, want a positive Sharpe below the Sortino", sharpe, sortino)
+	}
+}
@@ -0,0 +1,98 @@
+package backtest
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Percentiles are the 5th, 50th and 95th percentiles of a simulated statistic
+type Percentiles struct {
+	P5  float64 `json:"p5"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+}
+
+// MonteCarloResult summarizes the equity paths of resampled trade sequences
+type MonteCarloResult struct {
+	Simulations     int         `json:"simulations"`
+	Trades          int         `json:"trades"`        // Trades per simulated path
+	MaxDrawdown     Percentiles `json:"max_drawdown"`  // Percent
+	CAGR            Percentiles `json:"cagr"`          // Percent per year
+	FinalCapital    Percentiles `json:"final_capital"` // Quote currency
+	RuinPercent     float64     `json:"ruin_percent"`  // Loss of the initial capital that counts as ruin
+	RuinProbability float64     `json:"ruin_probability"`
+	Seed            int64       `json:"seed"` // Repeats the resampling
+}
+
+// MonteCarlo bootstraps the trade sequence of a backtest: each simulation draws as many trades
+// as the backtest made, with replacement, and compounds their returns from the initial capital.
+// A trade's return is its net PnL relative to the equity before it closed. A path is ruined once
+// its equity falls below the initial capital less ruinPercent. The seed makes runs reproducible.
+func MonteCarlo(result *BacktestResult, simulations int, ruinPercent float64, seed int64) *MonteCarloResult {
+	mc := &MonteCarloResult{Simulations: simulations, RuinPercent: ruinPercent, Seed: seed}
+	if result == nil || simulations <= 0 || result.InitialCapital <= 0 {
+		return mc
+	}
+
+	returns := make([]float64, 0, len(result.TradeHistory))
+	equity := result.InitialCapital
+	for _, trade := range result.TradeHistory {
+		if equity <= 0 {
+			break
+		}
+		pnl := trade.PnL - trade.Commission - trade.Funding
+		returns = append(returns, pnl/equity)
+		equity += pnl
+	}
+	mc.Trades = len(returns)
+	if len(returns) == 0 {
+		return mc
+	}
+
+	years := result.EndDate.Sub(result.StartDate).Hours() / (24 * 365)
+	ruinLevel := result.InitialCapital * (1 - ruinPercent/100)
+	rng := rand.New(rand.NewSource(seed))
+
+	drawdowns := make([]float64, simulations)
+	cagrs := make([]float64, simulations)
+	finals := make([]float64, simulations)
+	ruined := 0
+	for i := 0; i < simulations; i++ {
+		equity, peak, drawdown, isRuined := result.InitialCapital, result.InitialCapital, 0.0, false
+		for range returns {
+			equity *= 1 + returns[rng.Intn(len(returns))]
+			if equity < 0 {
+				equity = 0
+			}
+			peak = math.Max(peak, equity)
+			drawdown = math.Max(drawdown, (peak-equity)/peak*100)
+			if equity < ruinLevel {
+				isRuined = true
+			}
+		}
+		if isRuined {
+			ruined++
+		}
+		drawdowns[i], finals[i] = drawdown, equity
+		if years > 0 {
+			cagrs[i] = (math.Pow(equity/result.InitialCapital, 1/years) - 1) * 100
+		}
+	}
+
+	mc.MaxDrawdown = percentiles(drawdowns)
+	mc.CAGR = percentiles(cagrs)
+	mc.FinalCapital = percentiles(finals)
+	mc.RuinProbability = float64(ruined) / float64(simulations)
+	return mc
+}
+
+// percentiles returns the 5th, 50th and 95th percentiles of a sample
+func percentiles(values []float64) Percentiles {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	at := func(p float64) float64 {
+		return sorted[int(math.Round(p*float64(len(sorted)-1)))]
+	}
+	return Percentiles{P5: at(0.05), P50: at(0.5), P95: at(0.95)}
+}
@@ -0,0 +1,38 @@
+package backtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMonteCarloIsReproducible(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &BacktestResult{
+		InitialCapital: 1000,
+		StartDate:      start,
+		EndDate:        start.AddDate(1, 0, 0),
+		TradeHistory: []TradeRecord{
+			{PnL: 50}, {PnL: -30}, {PnL: 80, Commission: 5}, {PnL: -60}, {PnL: 20},
+		},
+	}
+
+	first := MonteCarlo(result, 200, 50, 42)
+	second := MonteCarlo(result, 200, 50, 42)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("runs with the same seed differ: %+v and %+v", first, second)
+	}
+	if first.Trades != len(result.TradeHistory) {
+		t.Errorf("Trades = %d, want %d", first.Trades, len(result.TradeHistory))
+	}
+	if first.MaxDrawdown.P5 > first.MaxDrawdown.P50 || first.MaxDrawdown.P50 > first.MaxDrawdown.P95 {
+		t.Errorf("drawdown percentiles out of order: %+v", first.MaxDrawdown)
+	}
+	if first.RuinProbability != 0 {
+		t.Errorf("RuinProbability = %v, want 0 for losses that cannot reach 50%%", first.RuinProbability)
+	}
+
+	if empty := MonteCarlo(&BacktestResult{InitialCapital: 1000}, 100, 50, 1); empty.Trades != 0 {
+		t.Errorf("Trades without a trade history = %d, want 0", empty.Trades)
+	}
+}
@@ -0,0 +1,83 @@
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// SplitResult compares a backtest's in-sample part, the start of the period, with its
+// out-of-sample part, the end of the period. A strategy or parameter set that only fits the data
+// it was tuned on keeps little of its in-sample Sharpe ratio out of sample.
+type SplitResult struct {
+	SplitDate          time.Time `json:"split_date"` // Start of the out-of-sample part
+	OutOfSamplePercent float64   `json:"out_of_sample_percent"`
+	InSample           SweepRow  `json:"in_sample"`
+	OutOfSample        SweepRow  `json:"out_of_sample"`
+	// Out-of-sample over in-sample Sharpe ratio, 0 when the in-sample Sharpe ratio is not positive
+	SharpeRetention float64 `json:"sharpe_retention"`
+	Overfit         bool    `json:"overfit"` // Retention below the overfit ratio
+}
+
+// SplitDate returns the start of the last outOfSamplePercent of a period
+func SplitDate(startDate, endDate time.Time, outOfSamplePercent float64) time.Time {
+	length := endDate.Sub(startDate)
+	return endDate.Add(-time.Duration(float64(length) * outOfSamplePercent / 100))
+}
+
+// SharpeRetention returns the share of an in-sample Sharpe ratio kept out of sample, 0 when the
+// in-sample Sharpe ratio is not positive
+func SharpeRetention(inSample, outOfSample float64) float64 {
+	if inSample <= 0 {
+		return 0
+	}
+	return outOfSample / inSample
+}
+
+// IsOverfit reports whether a profitable in-sample Sharpe ratio kept less than overfitRatio of
+// itself out of sample
+func IsOverfit(inSample, outOfSample, overfitRatio float64) bool {
+	return inSample > 0 && SharpeRetention(inSample, outOfSample) < overfitRatio
+}
+
+// Split backtests the strategy separately on the in-sample and out-of-sample parts of a period,
+// the last outOfSamplePercent being out of sample, with fresh strategies of the same type and
+// parameters. The in-sample backtest never sees the out-of-sample klines; the out-of-sample one
+// warms up on the in-sample klines like any backtest on the bars before its start.
+func (bt *Backtester) Split(initialCapital float64, startDate, endDate time.Time, outOfSamplePercent, overfitRatio float64) (*SplitResult, error) {
+	if outOfSamplePercent <= 0 || outOfSamplePercent >= 100 {
+		return nil, fmt.Errorf("out-of-sample percent must be between 0 and 100")
+	}
+	strategyType := strategy.StrategyType(bt.Strategy.GetName())
+	params := bt.Strategy.GetParameters()
+	splitDate := SplitDate(startDate, endDate, outOfSamplePercent)
+	bt, finish := bt.track(bt.bars(splitDate.Add(-time.Nanosecond)) + bt.bars(endDate))
+	defer finish()
+
+	// The engine includes the end date, so the in-sample part stops just before the split
+	var inSample, outOfSample *BacktestResult
+	var inSampleErr, outOfSampleErr error
+	Parallel(2, bt.Workers, func(part int) {
+		if part == 0 {
+			inSample, inSampleErr = bt.runWith(strategyType, params, nil, initialCapital, startDate, splitDate.Add(-time.Nanosecond))
+		} else {
+			outOfSample, outOfSampleErr = bt.runWith(strategyType, params, nil, initialCapital, splitDate, endDate)
+		}
+	})
+	if inSampleErr != nil {
+		return nil, inSampleErr
+	}
+	if outOfSampleErr != nil {
+		return nil, outOfSampleErr
+	}
+
+	return &SplitResult{
+		SplitDate:          splitDate,
+		OutOfSamplePercent: outOfSamplePercent,
+		InSample:           newSweepRow(params, inSample),
+		OutOfSample:        newSweepRow(params, outOfSample),
+		SharpeRetention:    SharpeRetention(inSample.SharpeRatio, outOfSample.SharpeRatio),
+		Overfit:            IsOverfit(inSample.SharpeRatio, outOfSample.SharpeRatio, overfitRatio),
+	}, nil
+}
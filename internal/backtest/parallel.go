@@ -0,0 +1,32 @@
+package backtest
+
+import "sync"
+
+// Parallel runs the jobs 0 to jobs-1 on a pool of at most workers goroutines and returns once
+// all of them are done. Fewer than one worker runs the jobs one by one. Jobs keep their results
+// by index, so merging them gives the same result as running the jobs in order.
+func Parallel(jobs, workers int, run func(job int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > jobs {
+		workers = jobs
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range next {
+				run(job)
+			}
+		}()
+	}
+	for job := 0; job < jobs; job++ {
+		next <- job
+	}
+	close(next)
+	wg.Wait()
+}
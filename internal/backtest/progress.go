@@ -0,0 +1,112 @@
+package backtest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// progressInterval is the least time between two progress reports
+const progressInterval = 250 * time.Millisecond
+
+// Progress reports how far a backtest has got. Sweeps, splits, walk-forward analyses and
+// selection comparisons report the bars of all their runs together.
+type Progress struct {
+	Bars           int     `json:"bars"` // Bars replayed so far, warm-up bars included
+	TotalBars      int     `json:"total_bars"`
+	Percent        float64 `json:"percent"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	ETASeconds     float64 `json:"eta_seconds"` // Estimated time left at the pace so far
+}
+
+// ProgressFunc receives the progress of a backtest, at most every progressInterval and once
+// when it ends
+type ProgressFunc func(Progress)
+
+// progressTracker counts the bars replayed towards a total and reports them, for runs in
+// parallel too
+type progressTracker struct {
+	mu       sync.Mutex
+	report   ProgressFunc
+	total    int
+	bars     int
+	started  time.Time
+	reported time.Time
+}
+
+// advance counts replayed bars, reporting when the last report is old enough
+func (p *progressTracker) advance(bars int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bars += bars
+	if now := time.Now(); now.Sub(p.reported) >= progressInterval {
+		p.reported = now
+		p.report(p.progress(now))
+	}
+}
+
+// finish reports the final progress
+func (p *progressTracker) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.report(p.progress(time.Now()))
+}
+
+// progress returns the progress at a time
+func (p *progressTracker) progress(now time.Time) Progress {
+	progress := Progress{Bars: p.bars, TotalBars: p.total, ElapsedSeconds: now.Sub(p.started).Seconds()}
+	if p.total > 0 {
+		progress.Percent = 100 * float64(p.bars) / float64(p.total)
+	}
+	if p.bars > 0 && p.bars < p.total {
+		progress.ETASeconds = progress.ElapsedSeconds * float64(p.total-p.bars) / float64(p.bars)
+	}
+	return progress
+}
+
+// track returns the backtester with a tracker of its progress over runs replaying the given
+// number of bars, and the func reporting their end. A backtester that reports no progress, or
+// whose progress an enclosing run tracks already, is returned as it is.
+func (bt *Backtester) track(bars int) (*Backtester, func()) {
+	if bt.Progress == nil || bt.tracker != nil {
+		return bt, func() {}
+	}
+	tracked := *bt
+	tracked.tracker = &progressTracker{report: bt.Progress, total: bars, started: time.Now()}
+	return &tracked, tracked.tracker.finish
+}
+
+// bars returns the number of bars a run up to the end date replays
+func (bt *Backtester) bars(endDate time.Time) int {
+	bars := 0
+	for _, klines := range bt.Data {
+		for _, kline := range klines {
+			if !kline.Timestamp.After(endDate) {
+				bars++
+			}
+		}
+	}
+	return bars
+}
+
+// cancelled returns the error of the backtester's context once it is cancelled
+func (bt *Backtester) cancelled() error {
+	if bt.Context == nil || bt.Context.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("backtest cancelled: %w", bt.Context.Err())
+}
+
+// done returns the channel closed when the backtester's context is cancelled, nil without one
+func (bt *Backtester) done() <-chan struct{} {
+	if bt.Context == nil {
+		return nil
+	}
+	return bt.Context.Done()
+}
@@ -0,0 +1,117 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// replaySeries is the replay state of one symbol's klines
+type replaySeries struct {
+	klines     []bybit.KlineData
+	next       int                         // Index of the next kline to replay
+	timeframes map[string]*timeframeSeries // Higher timeframes resampled from the klines
+}
+
+// MarketReplay steps through the historical klines of several symbols in chronological order of
+// their bars' close times, so the live bot's pipeline can be driven with the market data it would
+// have fetched at each point in time: the trailing window of closed bars and the higher
+// timeframes resampled from them, their still forming bar last
+type MarketReplay struct {
+	barLength time.Duration
+	series    map[string]*replaySeries
+	order     []string // Symbols in a stable order
+}
+
+// NewMarketReplay creates a replay of klines of an interval per symbol, resampling them into
+// the given higher timeframes
+func NewMarketReplay(data map[string][]bybit.KlineData, interval string, timeframes []string) (*MarketReplay, error) {
+	barLength, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	mr := &MarketReplay{barLength: barLength, series: make(map[string]*replaySeries)}
+	for symbol, klines := range data {
+		series := &replaySeries{klines: klines, timeframes: make(map[string]*timeframeSeries)}
+		for _, timeframe := range timeframes {
+			length, err := bybit.IntervalDuration(timeframe)
+			if err != nil {
+				return nil, err
+			}
+			series.timeframes[timeframe] = &timeframeSeries{length: length}
+		}
+		mr.series[symbol] = series
+		mr.order = append(mr.order, symbol)
+	}
+	sort.Strings(mr.order)
+	return mr, nil
+}
+
+// Step replays the bars closing next, at the earliest close time of the symbols' next bars, and
+// returns that time. It returns false when no bar closes until the given time.
+func (mr *MarketReplay) Step(until time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, series := range mr.series {
+		if series.next >= len(series.klines) {
+			continue
+		}
+		closeTime := series.klines[series.next].Timestamp.Add(mr.barLength)
+		if !closeTime.After(until) && (!found || closeTime.Before(next)) {
+			next, found = closeTime, true
+		}
+	}
+	if !found {
+		return time.Time{}, false
+	}
+
+	for _, symbol := range mr.order {
+		series := mr.series[symbol]
+		if series.next >= len(series.klines) || !series.klines[series.next].Timestamp.Add(mr.barLength).Equal(next) {
+			continue
+		}
+		for _, timeframe := range series.timeframes {
+			timeframe.add(series.klines[series.next])
+		}
+		series.next++
+	}
+	return next, true
+}
+
+// Prices returns the close of each symbol's last replayed bar
+func (mr *MarketReplay) Prices() map[string]float64 {
+	prices := make(map[string]float64, len(mr.series))
+	for symbol, series := range mr.series {
+		if series.next > 0 {
+			prices[symbol], _ = series.klines[series.next-1].Close.Float64()
+		}
+	}
+	return prices
+}
+
+// MarketData returns the market data of each symbol with replayed bars: its last
+// bybit.MarketDataBars klines and the same number of bars of each higher timeframe
+func (mr *MarketReplay) MarketData() map[string]*bybit.MarketData {
+	marketData := make(map[string]*bybit.MarketData, len(mr.series))
+	for symbol, series := range mr.series {
+		if series.next == 0 {
+			continue
+		}
+		from := series.next - bybit.MarketDataBars
+		if from < 0 {
+			from = 0
+		}
+		current := series.klines[series.next-1]
+		data := &bybit.MarketData{Symbol: symbol, Timestamp: current.Timestamp, Kline: series.klines[from:series.next]}
+		if len(series.timeframes) > 0 {
+			data.Timeframes = make(map[string][]bybit.KlineData)
+			for timeframe, resampled := range series.timeframes {
+				data.Timeframes[timeframe] = resampled.window(bybit.MarketDataBars)
+			}
+		}
+		marketData[symbol] = data
+	}
+	return marketData
+}
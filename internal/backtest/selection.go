@@ -0,0 +1,141 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// SelectionResult shows how a backtest of the StrategyAI's selection (strategy.SelectorStrategy)
+// switched strategies and, once compared, how each candidate did on its own
+type SelectionResult struct {
+	Selections map[string]int `json:"selections"` // Analyses each strategy was selected at
+	Switches   int            `json:"switches"`   // Changes of a symbol's selected strategy
+	// Each candidate backtested alone on the same data and settings, best return first
+	Candidates   []CandidateResult `json:"candidates,omitempty"`
+	BestStrategy string            `json:"best_strategy,omitempty"`
+	// Return of the selection minus the best single strategy's, in percentage points
+	ExcessReturn float64 `json:"excess_return"`
+	BeatsBest    bool    `json:"beats_best"`
+}
+
+// CandidateResult is the backtest of one candidate strategy of a selection
+type CandidateResult struct {
+	Strategy    string  `json:"strategy"`
+	TotalReturn float64 `json:"total_return"`
+	SharpeRatio float64 `json:"sharpe_ratio"`
+	MaxDrawdown float64 `json:"max_drawdown"`
+	TotalTrades int     `json:"total_trades"`
+	WinRate     float64 `json:"win_rate"`
+}
+
+// ConfigureSelector sets up a selector strategy like the live bot's selection: the bandit
+// settings and the registered strategies named in the config compete in it. Other strategies are
+// left alone.
+func ConfigureSelector(s strategy.Strategy, cfg *config.Config) error {
+	selector, ok := s.(*strategy.SelectorStrategy)
+	if !ok {
+		return nil
+	}
+
+	bandit := 0.0
+	if cfg.BanditSelection {
+		bandit = 1
+	}
+	if err := selector.SetParameters(map[string]float64{
+		"bandit":       bandit,
+		"bandit_prior": cfg.BanditPrior,
+		"bandit_decay": cfg.BanditDecay,
+	}); err != nil {
+		return err
+	}
+
+	for _, name := range cfg.StrategyPlugins {
+		strategyType := strategy.StrategyType(name)
+		if _, exists := selector.Strategies[strategyType]; exists || strategyType == strategy.Selector {
+			continue
+		}
+		impl, err := strategy.NewStrategy(strategyType)
+		if err != nil {
+			return err
+		}
+		selector.AddStrategy(strategyType, impl)
+	}
+	return nil
+}
+
+// selection returns the strategy switches of a selector run, nil for other strategies
+func selection(s strategy.Strategy) *SelectionResult {
+	selector, ok := s.(*strategy.SelectorStrategy)
+	if !ok {
+		return nil
+	}
+	result := &SelectionResult{Selections: make(map[string]int), Switches: selector.Switches}
+	for strategyType, count := range selector.Selections {
+		result.Selections[string(strategyType)] = count
+	}
+	return result
+}
+
+// CompareSelection backtests every candidate of the backtester's selector strategy alone, with
+// default parameters and the same data, costs, exits and seed, on the backtester's workers, and
+// compares them with the selector's result, so it shows whether switching strategies beats
+// sticking to any one of them. It fails when the comparison is cancelled.
+func (bt *Backtester) CompareSelection(result *BacktestResult, initialCapital float64, startDate, endDate time.Time) error {
+	selector, ok := bt.Strategy.(*strategy.SelectorStrategy)
+	if !ok || result.Selection == nil {
+		return fmt.Errorf("strategy %s is not the %s", bt.Strategy.GetName(), strategy.Selector)
+	}
+
+	candidates := make([]string, 0, len(selector.Strategies))
+	for strategyType := range selector.Strategies {
+		candidates = append(candidates, string(strategyType))
+	}
+	sort.Strings(candidates)
+	bt, finish := bt.track(bt.bars(endDate) * len(candidates))
+	defer finish()
+
+	comparison := result.Selection
+	comparison.Candidates = make([]CandidateResult, len(candidates))
+	errs := make([]error, len(candidates))
+	Parallel(len(candidates), bt.Workers, func(i int) {
+		impl, err := strategy.NewStrategy(strategy.StrategyType(candidates[i]))
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		single := *bt
+		single.Strategy = impl
+		run := single.Run(initialCapital, startDate, endDate)
+		comparison.Candidates[i] = CandidateResult{
+			Strategy:    candidates[i],
+			TotalReturn: run.TotalReturn,
+			SharpeRatio: run.SharpeRatio,
+			MaxDrawdown: run.MaxDrawdown,
+			TotalTrades: run.TotalTrades,
+			WinRate:     run.WinRate,
+		}
+	})
+	if err := bt.cancelled(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(comparison.Candidates, func(i, j int) bool {
+		return comparison.Candidates[i].TotalReturn > comparison.Candidates[j].TotalReturn
+	})
+
+	if len(comparison.Candidates) > 0 {
+		best := comparison.Candidates[0]
+		comparison.BestStrategy = best.Strategy
+		comparison.ExcessReturn = result.TotalReturn - best.TotalReturn
+		comparison.BeatsBest = comparison.ExcessReturn > 0
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/risk"
+)
+
+// Position sizing modes of backtest entries
+const (
+	SizingEqual     = "equal"      // The symbol's equal share of the equity
+	SizingFixed     = "fixed"      // A fixed fraction of the equity
+	SizingATR       = "atr"        // A stop N×ATR or the signal's stop away risks a fraction of the equity
+	SizingKelly     = "kelly"      // A share of the Kelly fraction of the trades closed so far
+	SizingVolTarget = "vol_target" // The equal share scaled towards a volatility target
+)
+
+// kellyMinTrades is the number of closed trades Kelly sizing needs, entries before use the
+// equal share
+const kellyMinTrades = 10
+
+// Sizing configures how much of the equity a backtest entry takes, with the same modes the live
+// bot sizes orders by. Whatever the mode, an entry never takes more than the cash.
+type Sizing struct {
+	Mode     string  // One of the Sizing constants, empty for SizingEqual
+	Fraction float64 // Equity fraction of fixed sizing
+	// ATR sizing as in risk.PositionSizer, capped by the equal share like the live allocation cap
+	RiskPerTrade  float64
+	ATRPeriod     int
+	ATRMultiplier float64
+	KellyFraction float64 // Share of the full Kelly fraction, e.g. 0.5 for half Kelly
+	// Annualized volatility target and the range of its multiplier, as in risk.VolatilityTarget
+	VolTarget         float64
+	VolTargetMinScale float64
+	VolTargetMaxScale float64
+}
+
+// SizingFromConfig returns the configured sizing mode with the live risk settings
+func SizingFromConfig(cfg *config.Config) (Sizing, error) {
+	sizing := Sizing{
+		Mode:              cfg.BacktestSizing,
+		Fraction:          cfg.BacktestSizingFraction,
+		RiskPerTrade:      cfg.RiskPerTrade,
+		ATRPeriod:         cfg.ATRPeriod,
+		ATRMultiplier:     cfg.ATRStopMultiplier,
+		KellyFraction:     cfg.BacktestKellyFraction,
+		VolTarget:         cfg.VolTarget,
+		VolTargetMinScale: cfg.VolTargetMinScale,
+		VolTargetMaxScale: cfg.VolTargetMaxScale,
+	}
+	return sizing, sizing.Validate()
+}
+
+// Validate checks that the mode is known and has the settings it needs
+func (s Sizing) Validate() error {
+	switch s.Mode {
+	case "", SizingEqual, SizingKelly:
+	case SizingFixed:
+		if s.Fraction <= 0 || s.Fraction > 1 {
+			return fmt.Errorf("fixed sizing needs a fraction between 0 and 1")
+		}
+	case SizingATR:
+		if s.RiskPerTrade <= 0 || s.ATRPeriod <= 0 || s.ATRMultiplier <= 0 {
+			return fmt.Errorf("ATR sizing needs RISK_PER_TRADE, ATR_PERIOD and ATR_STOP_MULTIPLIER")
+		}
+	case SizingVolTarget:
+		if s.VolTarget <= 0 {
+			return fmt.Errorf("volatility target sizing needs VOL_TARGET")
+		}
+	default:
+		return fmt.Errorf("unknown sizing mode %q (use %s, %s, %s, %s or %s)", s.Mode, SizingEqual, SizingFixed, SizingATR, SizingKelly, SizingVolTarget)
+	}
+	return nil
+}
+
+// fraction returns the share of the equity an entry signalled at the last bar of the market
+// data takes, given the symbols traded and the trades closed so far
+func (s Sizing) fraction(marketData *bybit.MarketData, stopLoss float64, symbols int, trades []TradeRecord) float64 {
+	equal := 1 / float64(symbols)
+	price := 0.0
+	if len(marketData.Kline) > 0 {
+		price, _ = marketData.Kline[len(marketData.Kline)-1].Close.Float64()
+	}
+
+	switch s.Mode {
+	case SizingFixed:
+		return s.Fraction
+
+	case SizingATR:
+		// Like the live sizer: the signal's stop below the price or N×ATR, falling back to the
+		// equal share without ATR
+		stopDistance := s.ATRMultiplier * risk.CalculateATR(marketData.Kline, s.ATRPeriod)
+		if stopLoss > 0 && stopLoss < price {
+			stopDistance = price - stopLoss
+		}
+		if stopDistance <= 0 || price <= 0 {
+			return equal
+		}
+		return math.Min(s.RiskPerTrade*price/stopDistance, equal)
+
+	case SizingKelly:
+		if len(trades) < kellyMinTrades {
+			return equal
+		}
+		// f = W - (1 - W) / R with the win rate W and the average win over the average loss R
+		wins, losses, won, lost := 0, 0, 0.0, 0.0
+		for _, trade := range trades {
+			if pnl := trade.PnL - trade.Commission - trade.Funding; pnl > 0 {
+				wins++
+				won += pnl
+			} else {
+				losses++
+				lost -= pnl
+			}
+		}
+		winRate := float64(wins) / float64(len(trades))
+		if losses == 0 || lost == 0 {
+			return math.Min(s.KellyFraction, 1)
+		}
+		if wins == 0 {
+			return 0
+		}
+		payoff := (won / float64(wins)) / (lost / float64(losses))
+		kelly := winRate - (1-winRate)/payoff
+		return math.Min(math.Max(kelly*s.KellyFraction, 0), 1)
+
+	case SizingVolTarget:
+		volatility := annualizedVolatility(marketData.Kline)
+		if volatility <= 0 {
+			return equal
+		}
+		multiplier := math.Max(s.VolTarget/volatility, s.VolTargetMinScale)
+		multiplier = math.Min(multiplier, s.VolTargetMaxScale)
+		return equal * multiplier
+	}
+	return equal
+}
+
+// annualizedVolatility returns the standard deviation of the klines' close-to-close returns,
+// annualized at their bar length
+func annualizedVolatility(klines []bybit.KlineData) float64 {
+	if len(klines) < 3 {
+		return 0
+	}
+	returns := make([]float64, 0, len(klines)-1)
+	for i := 1; i < len(klines); i++ {
+		previous, _ := klines[i-1].Close.Float64()
+		current, _ := klines[i].Close.Float64()
+		if previous > 0 {
+			returns = append(returns, current/previous-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	barHours := klines[len(klines)-1].Timestamp.Sub(klines[0].Timestamp).Hours() / float64(len(klines)-1)
+	if barHours <= 0 {
+		return 0
+	}
+	return math.Sqrt(variance * 365 * 24 / barHours)
+}
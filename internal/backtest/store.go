@@ -0,0 +1,237 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// resultsDir is the directory of persisted backtest results inside the data directory
+const resultsDir = "backtests"
+
+// ResultsPath returns the directory backtest results are persisted in
+func ResultsPath(dataDir string) string {
+	return filepath.Join(dataDir, resultsDir)
+}
+
+// NewRunID returns a new ID for a backtest run of a strategy, unique and safe as a file name
+func NewRunID(strategyName string) string {
+	name := strings.Map(func(r rune) rune {
+		if validRunIDRune(r) {
+			return r
+		}
+		return '_'
+	}, strategyName)
+	return fmt.Sprintf("%s-%s", name, time.Now().UTC().Format("20060102T150405.000000000"))
+}
+
+// validRunIDRune reports whether a rune may appear in a run ID
+func validRunIDRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.'
+}
+
+// StoredResult is a persisted backtest result
+type StoredResult struct {
+	SavedAt time.Time       `json:"saved_at"`
+	Result  *BacktestResult `json:"result"`
+}
+
+// RunSummary describes a persisted run without its trades and equity curve
+type RunSummary struct {
+	RunID          string    `json:"run_id"`
+	SavedAt        time.Time `json:"saved_at"`
+	StrategyName   string    `json:"strategy_name"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	InitialCapital float64   `json:"initial_capital"`
+	FinalCapital   float64   `json:"final_capital"`
+	TotalReturn    float64   `json:"total_return"`
+	TotalTrades    int       `json:"total_trades"`
+	MaxDrawdown    float64   `json:"max_drawdown"`
+	SharpeRatio    float64   `json:"sharpe_ratio"`
+	Fingerprint    string    `json:"fingerprint,omitempty"`
+}
+
+// ResultStore persists backtest results as one JSON file per run ID, so they survive restarts
+type ResultStore struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+// NewResultStore creates a new ResultStore in a directory
+func NewResultStore(dir string) *ResultStore {
+	return &ResultStore{Dir: dir}
+}
+
+// Save persists a result under its run ID, assigning a new one if it has none
+func (rs *ResultStore) Save(result *BacktestResult) error {
+	if result.RunID == "" {
+		result.RunID = NewRunID(result.StrategyName)
+	}
+	path, err := rs.path(result.RunID)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := persistence.SaveJSON(path, StoredResult{SavedAt: time.Now(), Result: result}); err != nil {
+		return fmt.Errorf("failed to save backtest %s: %w", result.RunID, err)
+	}
+	return nil
+}
+
+// Load returns the persisted result of a run, or nil if there is none
+func (rs *ResultStore) Load(runID string) (*StoredResult, error) {
+	path, err := rs.path(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	var stored StoredResult
+	found, err := persistence.LoadJSON(path, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backtest %s: %w", runID, err)
+	}
+	if !found || stored.Result == nil {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+// List returns the summaries of all persisted runs, newest first
+func (rs *ResultStore) List() ([]RunSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(rs.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]RunSummary, 0, len(paths))
+	for _, path := range paths {
+		stored, err := rs.Load(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			continue
+		}
+		summaries = append(summaries, stored.Result.Summary(stored.SavedAt))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].SavedAt.After(summaries[j].SavedAt) })
+	return summaries, nil
+}
+
+// Summary returns the summary of a result saved at a time
+func (br *BacktestResult) Summary(savedAt time.Time) RunSummary {
+	return RunSummary{
+		RunID:          br.RunID,
+		SavedAt:        savedAt,
+		StrategyName:   br.StrategyName,
+		StartDate:      br.StartDate,
+		EndDate:        br.EndDate,
+		InitialCapital: br.InitialCapital,
+		FinalCapital:   br.FinalCapital,
+		TotalReturn:    br.TotalReturn,
+		TotalTrades:    br.TotalTrades,
+		MaxDrawdown:    br.MaxDrawdown,
+		SharpeRatio:    br.SharpeRatio,
+		Fingerprint:    br.Fingerprint,
+	}
+}
+
+// Delete removes the persisted result of a run. A missing run is not an error
+func (rs *ResultStore) Delete(runID string) error {
+	path, err := rs.path(runID)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete backtest %s: %w", runID, err)
+	}
+	return nil
+}
+
+// path returns the file of a run, rejecting IDs that could escape the directory
+func (rs *ResultStore) path(runID string) (string, error) {
+	if runID == "" || strings.Trim(runID, ".") == "" || strings.IndexFunc(runID, func(r rune) bool { return !validRunIDRune(r) }) >= 0 {
+		return "", fmt.Errorf("invalid run ID %q", runID)
+	}
+	return filepath.Join(rs.Dir, runID+".json"), nil
+}
+
+// Export formats of backtest results
+const (
+	ExportJSON      = "json"       // The whole result
+	ExportTradesCSV = "csv"        // The trade history
+	ExportEquityCSV = "equity_csv" // The equity curve
+)
+
+// Export writes a result in one of the export formats
+func (br *BacktestResult) Export(w io.Writer, format string) error {
+	switch format {
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(br); err != nil {
+			return fmt.Errorf("failed to encode backtest: %w", err)
+		}
+		return nil
+	case ExportTradesCSV:
+		return writeCSV(w, []string{"entry_time", "exit_time", "symbol", "action", "quantity", "entry_price", "exit_price", "pnl", "commission", "funding", "mae", "mfe", "exit_reason"}, len(br.TradeHistory), func(i int) []string {
+			trade := br.TradeHistory[i]
+			return []string{
+				trade.Timestamp.UTC().Format(time.RFC3339),
+				trade.ExitTime.UTC().Format(time.RFC3339),
+				trade.Symbol,
+				trade.Action,
+				strconv.FormatFloat(trade.Quantity, 'f', -1, 64),
+				strconv.FormatFloat(trade.EntryPrice, 'f', -1, 64),
+				strconv.FormatFloat(trade.ExitPrice, 'f', -1, 64),
+				strconv.FormatFloat(trade.PnL, 'f', -1, 64),
+				strconv.FormatFloat(trade.Commission, 'f', -1, 64),
+				strconv.FormatFloat(trade.Funding, 'f', -1, 64),
+				strconv.FormatFloat(trade.MAE, 'f', -1, 64),
+				strconv.FormatFloat(trade.MFE, 'f', -1, 64),
+				trade.ExitReason,
+			}
+		})
+	case ExportEquityCSV:
+		return writeCSV(w, []string{"timestamp", "equity"}, len(br.EquityCurve), func(i int) []string {
+			point := br.EquityCurve[i]
+			return []string{point.Timestamp.UTC().Format(time.RFC3339), strconv.FormatFloat(point.Equity, 'f', -1, 64)}
+		})
+	}
+	return fmt.Errorf("unsupported export format %q (use %s, %s or %s)", format, ExportJSON, ExportTradesCSV, ExportEquityCSV)
+}
+
+// writeCSV writes a header row and n records as CSV
+func writeCSV(w io.Writer, header []string, n int, record func(i int) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := writer.Write(record(i)); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,160 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// MaxSweepCombinations guards against sweeps that would take too long to run
+const MaxSweepCombinations = 10000
+
+// ParameterGrid maps parameter names to the values tried for them
+type ParameterGrid map[string][]float64
+
+// Names returns the grid's parameter names in sorted order
+func (g ParameterGrid) Names() []string {
+	names := make([]string, 0, len(g))
+	for name := range g {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Combinations returns the cartesian product of the grid's values
+func (g ParameterGrid) Combinations() []map[string]float64 {
+	combinations := []map[string]float64{{}}
+	for _, name := range g.Names() {
+		next := make([]map[string]float64, 0, len(combinations)*len(g[name]))
+		for _, combination := range combinations {
+			for _, value := range g[name] {
+				params := make(map[string]float64, len(combination)+1)
+				for k, v := range combination {
+					params[k] = v
+				}
+				params[name] = value
+				next = append(next, params)
+			}
+		}
+		combinations = next
+	}
+
+	return combinations
+}
+
+// SweepRow is the outcome of backtesting one parameter set of a sweep
+type SweepRow struct {
+	Parameters   map[string]float64 `json:"parameters"`
+	Error        string             `json:"error,omitempty"` // Set when the strategy rejected the parameters
+	TotalReturn  float64            `json:"total_return"`
+	SharpeRatio  float64            `json:"sharpe_ratio"`
+	SortinoRatio float64            `json:"sortino_ratio"`
+	MaxDrawdown  float64            `json:"max_drawdown"`
+	WinRate      float64            `json:"win_rate"`
+	TotalTrades  int                `json:"total_trades"`
+	FinalCapital float64            `json:"final_capital"`
+}
+
+// SweepResult is the comparison table of a parameter sweep, one row per parameter set in the
+// order of ParameterGrid.Combinations
+type SweepResult struct {
+	Strategy   string               `json:"strategy"`
+	Parameters []string             `json:"parameters"` // Swept parameter names, the matrix axes
+	Values     map[string][]float64 `json:"values"`     // Values of each axis
+	Rows       []SweepRow           `json:"rows"`
+}
+
+// Sweep backtests the strategy once per combination of the grid on the same data and costs, on
+// the backtester's workers. Each run uses a fresh strategy of the same type with the backtester
+// strategy's parameters, overridden by the combination.
+func (bt *Backtester) Sweep(grid ParameterGrid, initialCapital float64, startDate, endDate time.Time) (*SweepResult, error) {
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("parameter grid is empty")
+	}
+	combinations := grid.Combinations()
+	if len(combinations) > MaxSweepCombinations {
+		return nil, fmt.Errorf("grid has %d combinations, more than %d", len(combinations), MaxSweepCombinations)
+	}
+
+	strategyType := strategy.StrategyType(bt.Strategy.GetName())
+	if _, err := strategy.NewStrategy(strategyType); err != nil {
+		return nil, err
+	}
+	base := bt.Strategy.GetParameters()
+	bt, finish := bt.track(bt.bars(endDate) * len(combinations))
+	defer finish()
+
+	sweep := &SweepResult{
+		Strategy:   bt.Strategy.GetName(),
+		Parameters: grid.Names(),
+		Values:     grid,
+		Rows:       make([]SweepRow, len(combinations)),
+	}
+	Parallel(len(combinations), bt.Workers, func(i int) {
+		if bt.cancelled() != nil {
+			return
+		}
+		result, err := bt.runWith(strategyType, base, combinations[i], initialCapital, startDate, endDate)
+		if err != nil {
+			sweep.Rows[i] = SweepRow{Parameters: combinations[i], Error: err.Error()}
+			return
+		}
+		sweep.Rows[i] = newSweepRow(combinations[i], result)
+	})
+	if err := bt.cancelled(); err != nil {
+		return nil, err
+	}
+
+	return sweep, nil
+}
+
+// runWith backtests a fresh strategy of a type with the base parameters overridden by params on
+// the backtester's data and costs. It fails when the strategy rejects the parameters or the run
+// is cancelled.
+func (bt *Backtester) runWith(strategyType strategy.StrategyType, base, params map[string]float64, initialCapital float64, startDate, endDate time.Time) (*BacktestResult, error) {
+	strat, err := strategy.NewStrategy(strategyType)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]float64, len(base)+len(params))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	if err := strat.SetParameters(merged); err != nil {
+		return nil, err
+	}
+
+	backtester := NewBacktester(strat, bt.Data)
+	backtester.Costs = bt.Costs
+	backtester.Intrabar = bt.Intrabar
+	backtester.Exits = bt.Exits
+	backtester.Sizing = bt.Sizing
+	backtester.Funding = bt.Funding
+	backtester.Seed = bt.Seed
+	backtester.Context, backtester.Progress, backtester.tracker = bt.Context, bt.Progress, bt.tracker
+	result := backtester.Run(initialCapital, startDate, endDate)
+	if result.Cancelled {
+		return nil, bt.cancelled()
+	}
+	return result, nil
+}
+
+// newSweepRow collects the metrics of a parameter set's backtest
+func newSweepRow(params map[string]float64, result *BacktestResult) SweepRow {
+	return SweepRow{
+		Parameters:   params,
+		TotalReturn:  result.TotalReturn,
+		SharpeRatio:  result.SharpeRatio,
+		SortinoRatio: result.SortinoRatio,
+		MaxDrawdown:  result.MaxDrawdown,
+		WinRate:      result.WinRate,
+		TotalTrades:  result.TotalTrades,
+		FinalCapital: result.FinalCapital,
+	}
+}
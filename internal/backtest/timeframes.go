@@ -0,0 +1,53 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// timeframeSeries resamples a symbol's klines into a higher timeframe as the bars arrive, so a
+// strategy sees the same higher-timeframe history at every bar as the live bot: the completed
+// bars and the still forming bar of the current period
+type timeframeSeries struct {
+	length    time.Duration
+	completed []bybit.KlineData
+	forming   *bybit.KlineData
+}
+
+// add folds the next kline into the series
+func (ts *timeframeSeries) add(kline bybit.KlineData) {
+	start := kline.Timestamp.Truncate(ts.length)
+	if ts.forming != nil && ts.forming.Timestamp.Equal(start) {
+		if kline.High.GreaterThan(ts.forming.High) {
+			ts.forming.High = kline.High
+		}
+		if kline.Low.LessThan(ts.forming.Low) {
+			ts.forming.Low = kline.Low
+		}
+		ts.forming.Close = kline.Close
+		ts.forming.Volume = ts.forming.Volume.Add(kline.Volume)
+		return
+	}
+
+	if ts.forming != nil {
+		ts.completed = append(ts.completed, *ts.forming)
+	}
+	bar := kline
+	bar.Timestamp = start
+	ts.forming = &bar
+}
+
+// window returns the latest bars of the series, the forming bar last
+func (ts *timeframeSeries) window(bars int) []bybit.KlineData {
+	if ts.forming == nil {
+		return nil
+	}
+	from := len(ts.completed) - (bars - 1)
+	if from < 0 {
+		from = 0
+	}
+	window := make([]bybit.KlineData, 0, len(ts.completed)-from+1)
+	window = append(window, ts.completed[from:]...)
+	return append(window, *ts.forming)
+}
@@ -0,0 +1,226 @@
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// WalkForward configures a walk-forward analysis: the strategy is re-optimized over the grid on
+// each training window and then traded with the winning parameters on the test window that
+// follows it
+type WalkForward struct {
+	Grid      ParameterGrid
+	Train     time.Duration                 // Length of each training window
+	Test      time.Duration                 // Length of each test window
+	Step      time.Duration                 // Advance between windows, default Test
+	Anchored  bool                          // Training windows all start at the start date and grow
+	Objective func(*BacktestResult) float64 // Ranks parameter sets on the training window, higher is better; default Sharpe ratio
+}
+
+// WalkForwardWindow is one re-optimization step of a walk-forward analysis
+type WalkForwardWindow struct {
+	TrainStart  time.Time `json:"train_start"`
+	TrainEnd    time.Time `json:"train_end"`
+	TestStart   time.Time `json:"test_start"`
+	TestEnd     time.Time `json:"test_end"`
+	InSample    SweepRow  `json:"in_sample"`     // Best parameter set on the training window
+	OutOfSample SweepRow  `json:"out_of_sample"` // The same parameters on the test window
+}
+
+// WalkForwardStats aggregates the windows of one side of a walk-forward analysis
+type WalkForwardStats struct {
+	Windows            int     `json:"windows"`
+	ProfitableWindows  int     `json:"profitable_windows"`
+	AverageReturn      float64 `json:"average_return"`
+	AverageSharpe      float64 `json:"average_sharpe"`
+	AverageMaxDrawdown float64 `json:"average_max_drawdown"`
+	AverageWinRate     float64 `json:"average_win_rate"`
+	TotalTrades        int     `json:"total_trades"`
+}
+
+// WalkForwardResult is the outcome of a walk-forward analysis. In-sample statistics describe the
+// optimized training windows and out-of-sample statistics the unseen test windows. Backtest
+// chains the test windows into one backtest, each window starting with the capital the previous
+// one ended with.
+type WalkForwardResult struct {
+	Strategy    string              `json:"strategy"`
+	Windows     []WalkForwardWindow `json:"windows"`
+	InSample    WalkForwardStats    `json:"in_sample"`
+	OutOfSample WalkForwardStats    `json:"out_of_sample"`
+	Backtest    *BacktestResult     `json:"backtest"`   // The chained test windows
+	Efficiency  float64             `json:"efficiency"` // Out-of-sample over in-sample return per day, 0 when in-sample lost
+}
+
+// WalkForward runs a walk-forward analysis of the strategy between the start and end date. Each
+// test window is backtested separately, so positions are closed at its end.
+func (bt *Backtester) WalkForward(wf WalkForward, initialCapital float64, startDate, endDate time.Time) (*WalkForwardResult, error) {
+	if len(wf.Grid) == 0 {
+		return nil, fmt.Errorf("parameter grid is empty")
+	}
+	if wf.Train <= 0 || wf.Test <= 0 {
+		return nil, fmt.Errorf("training and test windows must be positive")
+	}
+	step := wf.Step
+	if step <= 0 {
+		step = wf.Test
+	}
+	objective := wf.Objective
+	if objective == nil {
+		objective = func(result *BacktestResult) float64 { return result.SharpeRatio }
+	}
+	combinations := wf.Grid.Combinations()
+	if len(combinations) > MaxSweepCombinations {
+		return nil, fmt.Errorf("grid has %d combinations, more than %d", len(combinations), MaxSweepCombinations)
+	}
+
+	strategyType := strategy.StrategyType(bt.Strategy.GetName())
+	if _, err := strategy.NewStrategy(strategyType); err != nil {
+		return nil, err
+	}
+	base := bt.Strategy.GetParameters()
+
+	wfResult := &WalkForwardResult{
+		Strategy: bt.Strategy.GetName(),
+		Backtest: &BacktestResult{
+			StrategyName:   bt.Strategy.GetName(),
+			InitialCapital: initialCapital,
+			FinalCapital:   initialCapital,
+			TradeHistory:   make([]TradeRecord, 0),
+			EquityCurve:    make([]EquityPoint, 0),
+			Seed:           bt.Seed,
+			CodeVersion:    CodeVersion(),
+		},
+	}
+	windows := wf.windows(step, startDate, endDate)
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("period too short for a %s training and %s test window", wf.Train, wf.Test)
+	}
+	bars := 0
+	for _, window := range windows {
+		bars += len(combinations)*bt.bars(window.TrainEnd.Add(-time.Nanosecond)) + bt.bars(window.TestEnd.Add(-time.Nanosecond))
+	}
+	bt, finish := bt.track(bars)
+	defer finish()
+
+	capital := initialCapital
+	inSampleDays, outOfSampleDays := 0.0, 0.0
+
+	for _, window := range windows {
+		trainStart, trainEnd, testEnd := window.TrainStart, window.TrainEnd, window.TestEnd
+
+		// Re-optimize on the training window, on the backtester's workers; the engine includes
+		// the end date, so each window stops just before the next one starts
+		results := make([]*BacktestResult, len(combinations))
+		Parallel(len(combinations), bt.Workers, func(i int) {
+			results[i], _ = bt.runWith(strategyType, base, combinations[i], initialCapital, trainStart, trainEnd.Add(-time.Nanosecond))
+		})
+		if err := bt.cancelled(); err != nil {
+			return nil, err
+		}
+		var best *BacktestResult
+		var bestParams map[string]float64
+		for i, result := range results {
+			if result != nil && (best == nil || objective(result) > objective(best)) {
+				best, bestParams = result, combinations[i]
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("no valid parameter set in the grid")
+		}
+
+		// Trade the winner on the unseen test window
+		test, err := bt.runWith(strategyType, base, bestParams, capital, trainEnd, testEnd.Add(-time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+		capital = test.FinalCapital
+
+		window.InSample, window.OutOfSample = newSweepRow(bestParams, best), newSweepRow(bestParams, test)
+		wfResult.Windows = append(wfResult.Windows, window)
+		inSampleDays += trainEnd.Sub(trainStart).Hours() / 24
+		outOfSampleDays += testEnd.Sub(trainEnd).Hours() / 24
+
+		oos := wfResult.Backtest
+		oos.TradeHistory = append(oos.TradeHistory, test.TradeHistory...)
+		oos.TotalFunding += test.TotalFunding
+		curve := test.EquityCurve
+		if len(oos.EquityCurve) > 0 && len(curve) > 0 {
+			curve = curve[1:] // The window's opening point repeats the previous window's close
+		}
+		oos.EquityCurve = append(oos.EquityCurve, curve...)
+	}
+
+	oos := wfResult.Backtest
+	oos.StartDate = wfResult.Windows[0].TestStart
+	oos.EndDate = wfResult.Windows[len(wfResult.Windows)-1].TestEnd
+	oos.FinalCapital = capital
+	calculateMetrics(oos)
+	oos.Benchmark = buyAndHold(bt.Data, bt.Costs, oos)
+
+	inSample, outOfSample := make([]SweepRow, 0, len(wfResult.Windows)), make([]SweepRow, 0, len(wfResult.Windows))
+	for _, window := range wfResult.Windows {
+		inSample = append(inSample, window.InSample)
+		outOfSample = append(outOfSample, window.OutOfSample)
+	}
+	wfResult.InSample = newWalkForwardStats(inSample)
+	wfResult.OutOfSample = newWalkForwardStats(outOfSample)
+
+	inSampleRate := wfResult.InSample.AverageReturn * float64(len(inSample)) / inSampleDays
+	outOfSampleRate := wfResult.OutOfSample.AverageReturn * float64(len(outOfSample)) / outOfSampleDays
+	if inSampleRate > 0 {
+		wfResult.Efficiency = outOfSampleRate / inSampleRate
+	}
+
+	return wfResult, nil
+}
+
+// windows returns the training and test periods of the windows between the start and end date,
+// each advanced by the step from the previous one
+func (wf WalkForward) windows(step time.Duration, startDate, endDate time.Time) []WalkForwardWindow {
+	var windows []WalkForwardWindow
+	for offset := time.Duration(0); ; offset += step {
+		trainStart, trainEnd := startDate.Add(offset), startDate.Add(offset+wf.Train)
+		if wf.Anchored {
+			trainStart = startDate
+		}
+		if !trainEnd.Before(endDate) {
+			break
+		}
+		testEnd := trainEnd.Add(wf.Test)
+		if testEnd.After(endDate) {
+			testEnd = endDate
+		}
+		windows = append(windows, WalkForwardWindow{TrainStart: trainStart, TrainEnd: trainEnd, TestStart: trainEnd, TestEnd: testEnd})
+
+		if !testEnd.Before(endDate) {
+			break
+		}
+	}
+	return windows
+}
+
+// newWalkForwardStats averages the metrics of a side's windows
+func newWalkForwardStats(rows []SweepRow) WalkForwardStats {
+	stats := WalkForwardStats{Windows: len(rows)}
+	if len(rows) == 0 {
+		return stats
+	}
+	for _, row := range rows {
+		if row.TotalReturn > 0 {
+			stats.ProfitableWindows++
+		}
+		stats.AverageReturn += row.TotalReturn
+		stats.AverageSharpe += row.SharpeRatio
+		stats.AverageMaxDrawdown += row.MaxDrawdown
+		stats.AverageWinRate += row.WinRate
+		stats.TotalTrades += row.TotalTrades
+	}
+	n := float64(len(rows))
+	stats.AverageReturn /= n
+	stats.AverageSharpe /= n
+	stats.AverageMaxDrawdown /= n
+	stats.AverageWinRate /= n
+	return stats
+}
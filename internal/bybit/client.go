@@ -3,6 +3,10 @@ package bybit
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hirokisan/bybit/v2"
@@ -12,8 +16,16 @@ import (
 // Client wraps the Bybit API client
 type Client struct {
 	bybitClient *bybit.Client
+	// Intervals of the additional timeframes GetMarketData fetches, e.g. "60" or "D"
+	Timeframes []string
+	// Cache of instrument metadata keyed by symbol
+	instrumentsMutex sync.RWMutex
+	instruments      map[string]*InstrumentInfo
 }
 
+// knownQuoteCurrencies lists quote currencies used to split symbols when instrument info is unavailable
+var knownQuoteCurrencies = []string{"USDT", "USDC", "EUR", "BTC", "ETH", "DAI"}
+
 // NewClient creates a new Bybit client
 func NewClient(apiKey, apiSecret string, testnet bool) *Client {
 	var client *bybit.Client
@@ -33,7 +45,191 @@ func NewClient(apiKey, apiSecret string, testnet bool) *Client {
 
 	return &Client{
 		bybitClient: client,
+		instruments: make(map[string]*InstrumentInfo),
+	}
+}
+
+// ParseSymbol splits a symbol into base and quote currencies using known quote suffixes
+func ParseSymbol(symbol string) (string, string, error) {
+	for _, quote := range knownQuoteCurrencies {
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+			return symbol[:len(symbol)-len(quote)], quote, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unable to determine quote currency for %s", symbol)
+}
+
+// GetInstrumentInfo fetches instrument metadata for a spot symbol, caching the result
+func (c *Client) GetInstrumentInfo(ctx context.Context, symbol string) (*InstrumentInfo, error) {
+	c.instrumentsMutex.RLock()
+	info, exists := c.instruments[symbol]
+	c.instrumentsMutex.RUnlock()
+	if exists {
+		return info, nil
+	}
+
+	symbolV5 := bybit.SymbolV5(symbol)
+	resp, err := c.bybitClient.V5().Market().GetInstrumentsInfo(bybit.V5GetInstrumentsInfoParam{
+		Category: "spot",
+		Symbol:   &symbolV5,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instrument info for %s: %w", symbol, err)
+	}
+
+	if resp.Result.Spot == nil || len(resp.Result.Spot.List) == 0 {
+		return nil, fmt.Errorf("no instrument info returned for %s", symbol)
+	}
+
+	item := resp.Result.Spot.List[0]
+	minOrderQty, _ := decimal.NewFromString(item.LotSizeFilter.MinOrderQty)
+	maxOrderQty, _ := decimal.NewFromString(item.LotSizeFilter.MaxOrderQty)
+	minOrderAmt, _ := decimal.NewFromString(item.LotSizeFilter.MinOrderAmt)
+	qtyStep, _ := decimal.NewFromString(item.LotSizeFilter.BasePrecision)
+	tickSize, _ := decimal.NewFromString(item.PriceFilter.TickSize)
+
+	info = &InstrumentInfo{
+		Symbol:      symbol,
+		BaseCoin:    string(item.BaseCoin),
+		QuoteCoin:   string(item.QuoteCoin),
+		MinOrderQty: minOrderQty,
+		MaxOrderQty: maxOrderQty,
+		MinOrderAmt: minOrderAmt,
+		QtyStep:     qtyStep,
+		TickSize:    tickSize,
+	}
+
+	c.instrumentsMutex.Lock()
+	c.instruments[symbol] = info
+	c.instrumentsMutex.Unlock()
+
+	return info, nil
+}
+
+// GetSymbolCurrencies returns the base and quote currencies for a symbol,
+// preferring exchange instrument info and falling back to suffix parsing
+func (c *Client) GetSymbolCurrencies(ctx context.Context, symbol string) (string, string, error) {
+	info, err := c.GetInstrumentInfo(ctx, symbol)
+	if err == nil && info.BaseCoin != "" && info.QuoteCoin != "" {
+		return info.BaseCoin, info.QuoteCoin, nil
+	}
+
+	return ParseSymbol(symbol)
+}
+
+// GetTickerPrice fetches the last traded price for a spot symbol
+func (c *Client) GetTickerPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	symbolV5 := bybit.SymbolV5(symbol)
+	resp, err := c.bybitClient.V5().Market().GetTickers(bybit.V5GetTickersParam{
+		Category: "spot",
+		Symbol:   &symbolV5,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get ticker for %s: %w", symbol, err)
+	}
+
+	if resp.Result.Spot == nil || len(resp.Result.Spot.List) == 0 {
+		return decimal.Zero, fmt.Errorf("no ticker returned for %s", symbol)
+	}
+
+	price, err := decimal.NewFromString(resp.Result.Spot.List[0].LastPrice)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid ticker price for %s: %w", symbol, err)
+	}
+
+	return price, nil
+}
+
+// GetOrderBook fetches the top levels of a spot symbol's order book
+func (c *Client) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	resp, err := c.bybitClient.V5().Market().GetOrderbook(bybit.V5GetOrderbookParam{
+		Category: bybit.CategoryV5Spot,
+		Symbol:   bybit.SymbolV5(symbol),
+		Limit:    &depth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book for %s: %w", symbol, err)
 	}
+
+	book := &OrderBook{
+		Symbol:    symbol,
+		Bids:      make([]OrderBookLevel, 0, len(resp.Result.Bids)),
+		Asks:      make([]OrderBookLevel, 0, len(resp.Result.Asks)),
+		Timestamp: time.UnixMilli(resp.Result.Timestamp),
+	}
+	for _, bid := range resp.Result.Bids {
+		level := OrderBookLevel{}
+		level.Price, _ = decimal.NewFromString(bid.Price)
+		level.Quantity, _ = decimal.NewFromString(bid.Quantity)
+		book.Bids = append(book.Bids, level)
+	}
+	for _, ask := range resp.Result.Asks {
+		level := OrderBookLevel{}
+		level.Price, _ = decimal.NewFromString(ask.Price)
+		level.Quantity, _ = decimal.NewFromString(ask.Quantity)
+		book.Asks = append(book.Asks, level)
+	}
+
+	return book, nil
+}
+
+// GetFundingRate fetches the current funding rate and mark price of a linear perpetual
+func (c *Client) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	symbolV5 := bybit.SymbolV5(symbol)
+	resp, err := c.bybitClient.V5().Market().GetTickers(bybit.V5GetTickersParam{
+		Category: bybit.CategoryV5Linear,
+		Symbol:   &symbolV5,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get perpetual ticker for %s: %w", symbol, err)
+	}
+
+	if resp.Result.LinearInverse == nil || len(resp.Result.LinearInverse.List) == 0 {
+		return nil, fmt.Errorf("no perpetual ticker returned for %s", symbol)
+	}
+	item := resp.Result.LinearInverse.List[0]
+
+	rate, err := decimal.NewFromString(item.FundingRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid funding rate for %s: %w", symbol, err)
+	}
+
+	funding := &FundingRate{
+		Symbol: symbol,
+		Rate:   rate,
+	}
+	funding.MarkPrice, _ = decimal.NewFromString(item.MarkPrice)
+	funding.IndexPrice, _ = decimal.NewFromString(item.IndexPrice)
+	if ms, err := strconv.ParseInt(item.NextFundingTime, 10, 64); err == nil {
+		funding.NextFundingTime = time.UnixMilli(ms)
+	}
+
+	return funding, nil
+}
+
+// GetConversionRate returns the rate that converts one unit of "from" into "to".
+// It tries the direct pair first (e.g. USDCUSDT) and then the inverse pair.
+func (c *Client) GetConversionRate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1.0, nil
+	}
+
+	if price, err := c.GetTickerPrice(ctx, from+to); err == nil && price.IsPositive() {
+		rate, _ := price.Float64()
+		return rate, nil
+	}
+
+	price, err := c.GetTickerPrice(ctx, to+from)
+	if err != nil {
+		return 0, fmt.Errorf("no conversion pair between %s and %s: %w", from, to, err)
+	}
+	if !price.IsPositive() {
+		return 0, fmt.Errorf("invalid conversion price between %s and %s", from, to)
+	}
+
+	rate, _ := decimal.NewFromInt(1).Div(price).Float64()
+	return rate, nil
 }
 
 // GetTopCoins fetches the top traded coins on Bybit
@@ -49,14 +245,234 @@ func (c *Client) GetTopCoins(ctx context.Context, limit int) ([]string, error) {
 	return topCoins, nil
 }
 
-// GetMarketData fetches market data for a symbol
+// MarketDataBars is the number of klines GetMarketData fetches per symbol and timeframe
+const MarketDataBars = 100
+
+// MarketDataInterval is the interval of the klines every strategy analyzes
+const MarketDataInterval = "5"
+
+// IntervalForMinutes returns the Bybit kline interval of a timeframe in minutes
+func IntervalForMinutes(minutes int) string {
+	switch minutes {
+	case 1440:
+		return "D"
+	case 10080:
+		return "W"
+	default:
+		return strconv.Itoa(minutes)
+	}
+}
+
+// IntervalDuration returns the length of a Bybit kline interval, months counted as 30 days
+func IntervalDuration(interval string) (time.Duration, error) {
+	switch interval {
+	case "D":
+		return 24 * time.Hour, nil
+	case "W":
+		return 7 * 24 * time.Hour, nil
+	case "M":
+		return 30 * 24 * time.Hour, nil
+	}
+	minutes, err := strconv.Atoi(interval)
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// historicalKlinesPageSize is the number of klines requested per page of historical klines
+const historicalKlinesPageSize = 1000
+
+// DataProvider supplies the historical klines of a symbol at an interval that start between two
+// times, oldest first. Client implements it with the Bybit REST API; the backtest package adds
+// local CSV files and an on-disk cache in front of another provider, so backtests and warm-ups
+// can use any of them.
+type DataProvider interface {
+	GetKlines(ctx context.Context, symbol, interval string, from, to time.Time) ([]KlineData, error)
+}
+
+// GetKlines fetches the klines of a symbol at an interval that start between two times, oldest
+// first. The range is fetched page by page backwards from the end.
+func (c *Client) GetKlines(ctx context.Context, symbol, interval string, start, end time.Time) ([]KlineData, error) {
+	byTime := make(map[int64]KlineData)
+	startMs, endMs := start.UnixMilli(), end.UnixMilli()
+	limit := historicalKlinesPageSize
+
+	for endMs >= startMs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pageEnd := endMs
+		param := bybit.V5GetKlineParam{
+			Category: "spot",
+			Symbol:   bybit.SymbolV5(symbol),
+			Interval: bybit.Interval(interval),
+			Start:    &startMs,
+			End:      &pageEnd,
+			Limit:    &limit,
+		}
+		resp, err := c.bybitClient.V5().Market().GetKline(param)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get historical klines of %s: %w", symbol, err)
+		}
+		if len(resp.Result.List) == 0 {
+			break
+		}
+
+		// Pages are returned newest first
+		oldest := endMs
+		for _, k := range resp.Result.List {
+			startTime, err := strconv.ParseInt(k.StartTime, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kline start time %q: %w", k.StartTime, err)
+			}
+			if startTime < oldest {
+				oldest = startTime
+			}
+			if startTime < startMs || startTime > endMs {
+				continue
+			}
+
+			open, _ := decimal.NewFromString(k.Open)
+			high, _ := decimal.NewFromString(k.High)
+			low, _ := decimal.NewFromString(k.Low)
+			close, _ := decimal.NewFromString(k.Close)
+			volume, _ := decimal.NewFromString(k.Volume)
+			byTime[startTime] = KlineData{
+				Open:      open,
+				High:      high,
+				Low:       low,
+				Close:     close,
+				Volume:    volume,
+				Timestamp: time.UnixMilli(startTime).UTC(),
+			}
+		}
+
+		if oldest >= endMs || len(resp.Result.List) < limit {
+			break
+		}
+		endMs = oldest - 1
+	}
+
+	klines := make([]KlineData, 0, len(byTime))
+	for _, kline := range byTime {
+		klines = append(klines, kline)
+	}
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Timestamp.Before(klines[j].Timestamp) })
+	return klines, nil
+}
+
+// fundingHistoryPageSize is the number of funding rates requested per page of funding history
+const fundingHistoryPageSize = 200
+
+// FundingProvider supplies the historical funding rates of a linear perpetual settled between
+// two times, oldest first. Client implements it with the Bybit REST API; the backtest package adds
+// local CSV files.
+type FundingProvider interface {
+	GetFundingHistory(ctx context.Context, symbol string, from, to time.Time) ([]HistoricalFundingRate, error)
+}
+
+// GetFundingHistory fetches the funding rates of a linear perpetual settled between two times,
+// oldest first. The range is fetched page by page backwards from the end.
+func (c *Client) GetFundingHistory(ctx context.Context, symbol string, start, end time.Time) ([]HistoricalFundingRate, error) {
+	byTime := make(map[int64]HistoricalFundingRate)
+	startMs, endMs := start.UnixMilli(), end.UnixMilli()
+	limit := fundingHistoryPageSize
+
+	for endMs >= startMs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pageEnd := endMs
+		resp, err := c.bybitClient.V5().Market().GetFundingRateHistory(bybit.V5GetFundingRateHistoryParam{
+			Category:  bybit.CategoryV5Linear,
+			Symbol:    bybit.SymbolV5(symbol),
+			StartTime: &startMs,
+			EndTime:   &pageEnd,
+			Limit:     &limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get funding history of %s: %w", symbol, err)
+		}
+		if len(resp.Result.List) == 0 {
+			break
+		}
+
+		// Pages are returned newest first
+		oldest := endMs
+		for _, item := range resp.Result.List {
+			timestamp, err := strconv.ParseInt(item.FundingRateTimestamp, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid funding time %q: %w", item.FundingRateTimestamp, err)
+			}
+			if timestamp < oldest {
+				oldest = timestamp
+			}
+			if timestamp < startMs || timestamp > endMs {
+				continue
+			}
+			rate, err := decimal.NewFromString(item.FundingRate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid funding rate for %s: %w", symbol, err)
+			}
+			byTime[timestamp] = HistoricalFundingRate{Rate: rate, Timestamp: time.UnixMilli(timestamp).UTC()}
+		}
+
+		if oldest >= endMs || len(resp.Result.List) < limit {
+			break
+		}
+		endMs = oldest - 1
+	}
+
+	rates := make([]HistoricalFundingRate, 0, len(byTime))
+	for _, rate := range byTime {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Timestamp.Before(rates[j].Timestamp) })
+	return rates, nil
+}
+
+// GetMarketData fetches market data for a symbol: the 5 minute klines and the klines of the
+// client's additional timeframes
 func (c *Client) GetMarketData(ctx context.Context, symbol string) (*MarketData, error) {
+	klineData, err := c.getKlines(symbol, MarketDataInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	marketData := &MarketData{
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+		Kline:     klineData,
+	}
+
+	for _, interval := range c.Timeframes {
+		if interval == MarketDataInterval {
+			continue
+		}
+		klines, err := c.getKlines(symbol, interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s klines: %w", interval, err)
+		}
+		if marketData.Timeframes == nil {
+			marketData.Timeframes = make(map[string][]KlineData)
+		}
+		marketData.Timeframes[interval] = klines
+	}
+
+	return marketData, nil
+}
+
+// getKlines fetches the latest klines of a symbol at an interval
+func (c *Client) getKlines(symbol, interval string) ([]KlineData, error) {
 	// Try using V5 API instead
-	limit := 100
+	limit := MarketDataBars
 	param := bybit.V5GetKlineParam{
 		Category: "spot",
 		Symbol:   bybit.SymbolV5(symbol),
-		Interval: "5",
+		Interval: bybit.Interval(interval),
 		Limit:    &limit,
 	}
 
@@ -86,11 +502,7 @@ func (c *Client) GetMarketData(ctx context.Context, symbol string) (*MarketData,
 		})
 	}
 
-	return &MarketData{
-		Symbol:    symbol,
-		Timestamp: time.Now(),
-		Kline:     klineData,
-	}, nil
+	return klineData, nil
 }
 
 // PlaceOrder places a new order
@@ -137,6 +549,111 @@ func (c *Client) PlaceOrder(ctx context.Context, order Order) error {
 	return nil
 }
 
+// PlaceLimitOrder places a spot limit order and returns the exchange order ID. A post-only
+// order is rejected instead of taking liquidity if it would cross the spread.
+func (c *Client) PlaceLimitOrder(ctx context.Context, symbol, side string, quantity, price decimal.Decimal, postOnly bool) (string, error) {
+	timeInForce := ""
+	if postOnly {
+		timeInForce = string(bybit.TimeInForcePostOnly)
+	}
+	return c.PlaceLimitOrderWithTimeInForce(ctx, symbol, side, quantity, price, timeInForce)
+}
+
+// PlaceLimitOrderWithTimeInForce places a spot limit order with a time in force (GTC, IOC, FOK
+// or PostOnly) and returns the exchange order ID. An empty time in force uses the exchange default.
+func (c *Client) PlaceLimitOrderWithTimeInForce(ctx context.Context, symbol, side string, quantity, price decimal.Decimal, timeInForce string) (string, error) {
+	orderSide := bybit.SideBuy
+	if side == "SELL" {
+		orderSide = bybit.SideSell
+	}
+
+	limitPrice := price.String()
+	param := bybit.V5CreateOrderParam{
+		Category:  bybit.CategoryV5Spot,
+		Symbol:    bybit.SymbolV5(symbol),
+		Side:      orderSide,
+		OrderType: bybit.OrderTypeLimit,
+		Qty:       quantity.String(),
+		Price:     &limitPrice,
+	}
+	if timeInForce != "" {
+		tif := bybit.TimeInForce(timeInForce)
+		param.TimeInForce = &tif
+	}
+
+	resp, err := c.bybitClient.V5().Order().CreateOrder(param)
+	if err != nil {
+		return "", fmt.Errorf("failed to place %s limit order for %s: %w", side, symbol, err)
+	}
+
+	return resp.Result.OrderID, nil
+}
+
+// CancelSpotOrder cancels a spot order placed through the V5 API
+func (c *Client) CancelSpotOrder(ctx context.Context, symbol, orderID string) error {
+	_, err := c.bybitClient.V5().Order().CancelOrder(bybit.V5CancelOrderParam{
+		Category: bybit.CategoryV5Spot,
+		Symbol:   bybit.SymbolV5(symbol),
+		OrderID:  &orderID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+
+	return nil
+}
+
+// GetOrderStatus returns the status and fills of a spot order, looking it up among the open
+// orders first and in the order history once it is closed
+func (c *Client) GetOrderStatus(ctx context.Context, symbol, orderID string) (*OrderStatus, error) {
+	symbolV5 := bybit.SymbolV5(symbol)
+	resp, err := c.bybitClient.V5().Order().GetOpenOrders(bybit.V5GetOpenOrdersParam{
+		Category: bybit.CategoryV5Spot,
+		Symbol:   &symbolV5,
+		OrderID:  &orderID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order %s: %w", orderID, err)
+	}
+
+	if len(resp.Result.List) == 0 {
+		resp, err = c.bybitClient.V5().Order().GetHistoryOrders(bybit.V5GetHistoryOrdersParam{
+			Category: bybit.CategoryV5Spot,
+			Symbol:   &symbolV5,
+			OrderID:  &orderID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order history for %s: %w", orderID, err)
+		}
+	}
+	if len(resp.Result.List) == 0 {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	item := resp.Result.List[0]
+
+	status := &OrderStatus{
+		OrderID: item.OrderID,
+		Symbol:  string(item.Symbol),
+	}
+	switch item.OrderStatus {
+	case bybit.OrderStatusPartiallyFilled:
+		status.Status = OrderStatusPartiallyFilled
+	case bybit.OrderStatusFilled:
+		status.Status = OrderStatusFilled
+	case bybit.OrderStatusCancelled, bybit.OrderStatus("PartiallyFilledCanceled"):
+		// Spot orders cancelled after a partial fill keep their executed quantity
+		status.Status = OrderStatusCancelled
+	case bybit.OrderStatusRejected:
+		status.Status = OrderStatusRejected
+	default:
+		status.Status = OrderStatusNew
+	}
+	status.FilledQuantity, _ = decimal.NewFromString(item.CumExecQty)
+	status.AvgPrice, _ = decimal.NewFromString(item.AvgPrice)
+
+	return status, nil
+}
+
 // CancelOrder cancels an existing order
 func (c *Client) CancelOrder(ctx context.Context, symbol, orderID string) error {
 	req := bybit.SpotDeleteOrderParam{
@@ -160,15 +677,10 @@ func (c *Client) GetPositions(ctx context.Context, symbol string) ([]Position, e
 	}
 
 	// Find the base and quote currencies from the symbol
-	// e.g., BTCUSDT -> BTC and USDT
-	var baseCurrency, quoteCurrency string
-	if len(symbol) > 5 && symbol[len(symbol)-4:] == "USDT" {
-		baseCurrency = symbol[:len(symbol)-4]
-		quoteCurrency = "USDT"
-	} else {
-		// Simplified - in practice you'd parse this more carefully
-		baseCurrency = "BTC"
-		quoteCurrency = "USDT"
+	// e.g., BTCUSDT -> BTC and USDT, ETHUSDC -> ETH and USDC
+	baseCurrency, quoteCurrency, err := c.GetSymbolCurrencies(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
 
 	positions := make([]Position, 0, 2)
@@ -206,3 +718,236 @@ func (c *Client) GetPositions(ctx context.Context, symbol string) ([]Position, e
 
 	return positions, nil
 }
+
+// GetAvailableBalance gets the free balances of a symbol's base and quote currencies,
+// i.e. what can be spent on a buy or sold without the exchange rejecting the order
+func (c *Client) GetAvailableBalance(ctx context.Context, symbol string) (*SymbolBalance, error) {
+	account, err := c.bybitClient.Spot().V1().SpotGetWalletBalance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	baseCurrency, quoteCurrency, err := c.GetSymbolCurrencies(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &SymbolBalance{
+		Symbol:    symbol,
+		BaseCoin:  baseCurrency,
+		QuoteCoin: quoteCurrency,
+	}
+	for _, coin := range account.Result.Balances {
+		free, err := decimal.NewFromString(coin.Free)
+		if err != nil {
+			continue
+		}
+		switch coin.Coin {
+		case baseCurrency:
+			balance.BaseFree = free
+		case quoteCurrency:
+			balance.QuoteFree = free
+		}
+	}
+
+	return balance, nil
+}
+
+// GetDerivativePositions gets open USDT-settled linear derivatives positions
+func (c *Client) GetDerivativePositions(ctx context.Context) ([]DerivativePosition, error) {
+	settleCoin := bybit.Coin("USDT")
+	resp, err := c.bybitClient.V5().Position().GetPositionInfo(bybit.V5GetPositionInfoParam{
+		Category:   bybit.CategoryV5Linear,
+		SettleCoin: &settleCoin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get derivatives positions: %w", err)
+	}
+
+	positions := make([]DerivativePosition, 0, len(resp.Result.List))
+	for _, item := range resp.Result.List {
+		size, _ := decimal.NewFromString(item.Size)
+		if size.IsZero() {
+			continue
+		}
+
+		side := "BUY"
+		if item.Side == bybit.SideSell {
+			side = "SELL"
+		}
+
+		position := DerivativePosition{
+			Symbol: string(item.Symbol),
+			Side:   side,
+			Size:   size,
+		}
+		position.AvgPrice, _ = decimal.NewFromString(item.AvgPrice)
+		position.MarkPrice, _ = decimal.NewFromString(item.MarkPrice)
+		position.LiquidationPrice, _ = decimal.NewFromString(item.LiqPrice)
+		position.Leverage, _ = decimal.NewFromString(item.Leverage)
+		position.PositionValue, _ = decimal.NewFromString(item.PositionValue)
+		position.InitialMargin, _ = decimal.NewFromString(item.PositionIM)
+		position.MaintenanceMargin, _ = decimal.NewFromString(item.PositionMM)
+		position.UnrealisedPnl, _ = decimal.NewFromString(item.UnrealisedPnl)
+
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// PlaceDerivativeOrder places a market order for a USDT-settled linear perpetual, opening or
+// adding to a long (BUY) or short (SELL) position
+func (c *Client) PlaceDerivativeOrder(ctx context.Context, symbol, side string, quantity decimal.Decimal) error {
+	orderSide := bybit.SideBuy
+	if side == "SELL" {
+		orderSide = bybit.SideSell
+	}
+
+	_, err := c.bybitClient.V5().Order().CreateOrder(bybit.V5CreateOrderParam{
+		Category:  bybit.CategoryV5Linear,
+		Symbol:    bybit.SymbolV5(symbol),
+		Side:      orderSide,
+		OrderType: bybit.OrderTypeMarket,
+		Qty:       quantity.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to place %s derivatives order for %s: %w", side, symbol, err)
+	}
+
+	return nil
+}
+
+// ReduceDerivativePosition places a reduce-only market order that shrinks a linear position by quantity
+func (c *Client) ReduceDerivativePosition(ctx context.Context, symbol, positionSide string, quantity decimal.Decimal) error {
+	// Reducing a long means selling and reducing a short means buying
+	side := bybit.SideSell
+	if positionSide == "SELL" {
+		side = bybit.SideBuy
+	}
+
+	reduceOnly := true
+	_, err := c.bybitClient.V5().Order().CreateOrder(bybit.V5CreateOrderParam{
+		Category:   bybit.CategoryV5Linear,
+		Symbol:     bybit.SymbolV5(symbol),
+		Side:       side,
+		OrderType:  bybit.OrderTypeMarket,
+		Qty:        quantity.String(),
+		ReduceOnly: &reduceOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reduce position for %s: %w", symbol, err)
+	}
+
+	return nil
+}
+
+// conditionalOrderLinkPrefix tags conditional orders placed by the bot so they can be reconciled
+const conditionalOrderLinkPrefix = "bgo"
+
+// PlaceConditionalOrder submits a spot stop-loss or take-profit order that triggers a market
+// order at the trigger price. It returns the exchange order ID.
+func (c *Client) PlaceConditionalOrder(ctx context.Context, order ConditionalOrder) (string, error) {
+	side := bybit.SideSell
+	if order.Side == "BUY" {
+		side = bybit.SideBuy
+	}
+
+	triggerPrice := order.TriggerPrice.String()
+	orderFilter := bybit.OrderFilterTpSlOrder
+	// The order link ID carries the kind so reconciliation can tell SL and TP orders apart
+	orderLinkID := fmt.Sprintf("%s-%s-%d", conditionalOrderLinkPrefix, order.Kind, time.Now().UnixNano())
+
+	resp, err := c.bybitClient.V5().Order().CreateOrder(bybit.V5CreateOrderParam{
+		Category:     bybit.CategoryV5Spot,
+		Symbol:       bybit.SymbolV5(order.Symbol),
+		Side:         side,
+		OrderType:    bybit.OrderTypeMarket,
+		Qty:          order.Quantity.String(),
+		TriggerPrice: &triggerPrice,
+		OrderFilter:  &orderFilter,
+		OrderLinkID:  &orderLinkID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to place %s order for %s: %w", order.Kind, order.Symbol, err)
+	}
+
+	return resp.Result.OrderID, nil
+}
+
+// AmendConditionalOrder moves the trigger price of an existing conditional order
+func (c *Client) AmendConditionalOrder(ctx context.Context, symbol, orderID string, triggerPrice decimal.Decimal) error {
+	price := triggerPrice.String()
+	_, err := c.bybitClient.V5().Order().AmendOrder(bybit.V5AmendOrderParam{
+		Category:     bybit.CategoryV5Spot,
+		Symbol:       bybit.SymbolV5(symbol),
+		OrderID:      &orderID,
+		TriggerPrice: &price,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to amend order %s: %w", orderID, err)
+	}
+
+	return nil
+}
+
+// CancelConditionalOrder cancels a conditional order
+func (c *Client) CancelConditionalOrder(ctx context.Context, symbol, orderID string) error {
+	orderFilter := bybit.OrderFilterTpSlOrder
+	_, err := c.bybitClient.V5().Order().CancelOrder(bybit.V5CancelOrderParam{
+		Category:    bybit.CategoryV5Spot,
+		Symbol:      bybit.SymbolV5(symbol),
+		OrderID:     &orderID,
+		OrderFilter: &orderFilter,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+
+	return nil
+}
+
+// GetConditionalOrders returns the open conditional orders placed by the bot, optionally for one symbol
+func (c *Client) GetConditionalOrders(ctx context.Context, symbol string) ([]ConditionalOrder, error) {
+	orderFilter := bybit.OrderFilterTpSlOrder
+	param := bybit.V5GetOpenOrdersParam{
+		Category:    bybit.CategoryV5Spot,
+		OrderFilter: &orderFilter,
+	}
+	if symbol != "" {
+		symbolV5 := bybit.SymbolV5(symbol)
+		param.Symbol = &symbolV5
+	}
+
+	resp, err := c.bybitClient.V5().Order().GetOpenOrders(param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conditional orders: %w", err)
+	}
+
+	orders := make([]ConditionalOrder, 0, len(resp.Result.List))
+	for _, item := range resp.Result.List {
+		// Skip orders that were not placed by the bot
+		parts := strings.SplitN(item.OrderLinkID, "-", 3)
+		if len(parts) != 3 || parts[0] != conditionalOrderLinkPrefix {
+			continue
+		}
+
+		side := "SELL"
+		if item.Side == bybit.SideBuy {
+			side = "BUY"
+		}
+
+		order := ConditionalOrder{
+			OrderID: item.OrderID,
+			Symbol:  string(item.Symbol),
+			Kind:    parts[1],
+			Side:    side,
+		}
+		order.Quantity, _ = decimal.NewFromString(item.Qty)
+		order.TriggerPrice, _ = decimal.NewFromString(item.TriggerPrice)
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
@@ -21,7 +21,19 @@ type MarketData struct {
 	Symbol    string
 	Timestamp time.Time
 	Kline     []KlineData // List of kline data
-	// Add other fields as needed for mock implementation
+	// Klines of additional timeframes by Bybit interval (e.g. "60", "240", "D"), in the same
+	// order as Kline
+	Timeframes map[string][]KlineData
+	// Market sentiment from -1 (extreme fear) to 1 (extreme greed), nil without a sentiment provider
+	Sentiment *float64
+}
+
+// TimeframeKlines returns the klines of a timeframe, nil if they were not fetched
+func (md *MarketData) TimeframeKlines(interval string) []KlineData {
+	if md == nil {
+		return nil
+	}
+	return md.Timeframes[interval]
 }
 
 // Order represents a trading order
@@ -33,6 +45,132 @@ type Order struct {
 	Price    decimal.Decimal
 }
 
+// Conditional order kinds
+const (
+	ConditionalStopLoss   = "STOP_LOSS"
+	ConditionalTakeProfit = "TAKE_PROFIT"
+)
+
+// ConditionalOrder represents an exchange-side stop-loss or take-profit order
+type ConditionalOrder struct {
+	OrderID      string
+	Symbol       string
+	Kind         string // STOP_LOSS, TAKE_PROFIT
+	Side         string // BUY, SELL
+	Quantity     decimal.Decimal
+	TriggerPrice decimal.Decimal
+}
+
+// OrderBookLevel is one price level of the order book
+type OrderBookLevel struct {
+	Price    decimal.Decimal
+	Quantity decimal.Decimal
+}
+
+// OrderBook is a snapshot of the best bid and ask levels of a symbol
+type OrderBook struct {
+	Symbol    string
+	Bids      []OrderBookLevel // Best (highest) first
+	Asks      []OrderBookLevel // Best (lowest) first
+	Timestamp time.Time
+}
+
+// BestBid returns the highest bid price, zero if there are no bids
+func (ob *OrderBook) BestBid() float64 {
+	if len(ob.Bids) == 0 {
+		return 0
+	}
+	price, _ := ob.Bids[0].Price.Float64()
+	return price
+}
+
+// BestAsk returns the lowest ask price, zero if there are no asks
+func (ob *OrderBook) BestAsk() float64 {
+	if len(ob.Asks) == 0 {
+		return 0
+	}
+	price, _ := ob.Asks[0].Price.Float64()
+	return price
+}
+
+// SpreadBps returns the bid-ask spread in basis points of the mid price
+func (ob *OrderBook) SpreadBps() float64 {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return 0
+	}
+	return (ask - bid) / ((ask + bid) / 2) * 10000
+}
+
+// Depth returns the quote-currency value of the bids and asks in the top levels
+func (ob *OrderBook) Depth(levels int) (float64, float64) {
+	sum := func(side []OrderBookLevel) float64 {
+		total := 0.0
+		for i, level := range side {
+			if i >= levels {
+				break
+			}
+			total += level.Price.Mul(level.Quantity).InexactFloat64()
+		}
+		return total
+	}
+	return sum(ob.Bids), sum(ob.Asks)
+}
+
+// Imbalance returns (bids - asks) / (bids + asks) of the value in the top levels, from -1
+// (all asks) to 1 (all bids)
+func (ob *OrderBook) Imbalance(levels int) float64 {
+	bids, asks := ob.Depth(levels)
+	if bids+asks == 0 {
+		return 0
+	}
+	return (bids - asks) / (bids + asks)
+}
+
+// Exchange order statuses reported by GetOrderStatus
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCancelled       = "CANCELLED"
+	OrderStatusRejected        = "REJECTED"
+)
+
+// OrderStatus is the execution state of an order on the exchange
+type OrderStatus struct {
+	OrderID        string
+	Symbol         string
+	Status         string // NEW, PARTIALLY_FILLED, FILLED, CANCELLED, REJECTED
+	FilledQuantity decimal.Decimal
+	AvgPrice       decimal.Decimal
+}
+
+// SymbolBalance is the free (not locked in open orders) balance of a symbol's base and quote currencies
+type SymbolBalance struct {
+	Symbol    string
+	BaseCoin  string
+	QuoteCoin string
+	BaseFree  decimal.Decimal
+	QuoteFree decimal.Decimal
+}
+
+// FundingRate is the current funding of a linear perpetual, paid every 8 hours by longs to
+// shorts when positive
+type FundingRate struct {
+	Symbol          string
+	Rate            decimal.Decimal
+	NextFundingTime time.Time
+	MarkPrice       decimal.Decimal
+	IndexPrice      decimal.Decimal
+}
+
+// HistoricalFundingRate is a past funding settlement of a linear perpetual: at the timestamp
+// longs paid the rate times their position value to shorts, or received it when negative
+type HistoricalFundingRate struct {
+	Rate      decimal.Decimal
+	Timestamp time.Time
+}
+
 // Position represents a trading position
 type Position struct {
 	Symbol        string
@@ -42,10 +180,56 @@ type Position struct {
 	UnrealisedPnl decimal.Decimal
 }
 
-// TradeSignal represents a trading signal
+// DerivativePosition represents a leveraged derivatives position
+type DerivativePosition struct {
+	Symbol            string
+	Side              string // BUY (long), SELL (short)
+	Size              decimal.Decimal
+	AvgPrice          decimal.Decimal
+	MarkPrice         decimal.Decimal
+	LiquidationPrice  decimal.Decimal
+	Leverage          decimal.Decimal
+	PositionValue     decimal.Decimal
+	InitialMargin     decimal.Decimal
+	MaintenanceMargin decimal.Decimal
+	UnrealisedPnl     decimal.Decimal
+}
+
+// InstrumentInfo represents trading rules and currencies for a symbol
+type InstrumentInfo struct {
+	Symbol      string
+	BaseCoin    string
+	QuoteCoin   string
+	MinOrderQty decimal.Decimal
+	MaxOrderQty decimal.Decimal
+	MinOrderAmt decimal.Decimal // Minimum order value in the quote currency
+	QtyStep     decimal.Decimal
+	TickSize    decimal.Decimal
+}
+
+// Time in force values of a trade signal's entry order
+const (
+	TimeInForceGTC      = "GTC"      // Good till cancelled
+	TimeInForceIOC      = "IOC"      // Immediate or cancel
+	TimeInForceFOK      = "FOK"      // Fill or kill
+	TimeInForcePostOnly = "PostOnly" // Maker only, rejected if it would take liquidity
+)
+
+// TradeSignal represents a trading signal. The trade plan fields are optional, zero values
+// leave the entry, exits and size to the executor and risk layer. The entry is a limit order at
+// EntryPrice when TimeInForce is set, otherwise a market order expected to fill near EntryPrice.
 type TradeSignal struct {
 	Symbol   string
 	Action   string // BUY, SELL, HOLD
 	Strength float64
 	Reason   string
+	// Trade plan
+	EntryPrice        float64
+	StopLoss          float64
+	TakeProfit        float64
+	SuggestedQuantity float64 // Upper bound on the order quantity
+	TimeInForce       string  // One of the TimeInForce values, empty for a market order
+	// Warm-up status: a NotReady signal must not be acted on until RequiredBars bars are available
+	NotReady     bool
+	RequiredBars int
 }
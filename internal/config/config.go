@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 )
 
 // Config holds all configuration parameters for the trading bot
@@ -10,6 +12,7 @@ type Config struct {
 	BybitAPIKey        string
 	BybitAPISecret     string
 	Testnet            bool
+	PaperTrading       bool // Simulate strategy orders instead of sending them to the exchange
 	TotalCapital       float64
 	MaxPositionPerCoin float64
 	RebalanceMinutes   int
@@ -22,6 +25,219 @@ type Config struct {
 	// Stop-loss and take-profit settings
 	StopLossPercent   float64
 	TakeProfitPercent float64
+	// Directory used for persisted bot state (equity curve, snapshots, etc.)
+	DataDir string
+	// Currency all portfolio values are reported in (e.g. USDT)
+	ReportingCurrency string
+	// Symbols that are always traded / never traded regardless of the top coins list
+	PinnedSymbols   []string
+	ExcludedSymbols []string
+	// JSON file with global and per-symbol strategy parameters, empty to use the built-in defaults
+	StrategyParamsFile string
+	// Kline intervals fetched in addition to the 5 minute klines, e.g. 60,240,D
+	Timeframes []string
+	// Registered third-party strategies added to the bot and Go plugin files registering more
+	StrategyPlugins     []string
+	StrategyPluginPaths []string
+	// Strategies run in shadow mode: their signals are filled hypothetically in a separate
+	// ledger and never sent as orders
+	ShadowStrategies []string
+	ShadowNotional   float64 // Notional of each hypothetical fill
+	ShadowFeePercent float64
+	// Dollar-cost averaging: symbols accumulated on a schedule alongside the active strategies
+	DCASymbols       []string
+	DCAAmount        float64 // Notional per buy in the symbol's quote currency
+	DCAIntervalHours float64
+	DCADipPercent    float64 // Distance below the moving average that counts as a dip (0 disables)
+	DCADipMultiplier float64 // Amount multiplier on dips
+	// Pairs trading: symbol pairs whose spread is traded with linear perpetuals (e.g. BTCUSDT:ETHUSDT)
+	Pairs               [][2]string
+	PairsEntryZ         float64
+	PairsExitZ          float64
+	PairsStopZ          float64
+	PairsMinCorrelation float64
+	PairsLegNotional    float64 // Notional of the first leg in USDT
+	// Funding arbitrage: symbols held spot-long/perpetual-short while funding is high
+	FundingArbSymbols         []string
+	FundingArbEntryRate       float64 // Funding rate per 8h that opens a position
+	FundingArbExitRate        float64 // Funding rate per 8h at or below which the position is closed
+	FundingArbMaxBasisPercent float64
+	FundingArbNotional        float64 // Notional of each leg in USDT
+	// Order book scalping: liquid symbols quoted passively on a fast schedule
+	ScalpSymbols         []string
+	ScalpCheckSeconds    int
+	ScalpOrderNotional   float64 // Notional per order in the quote currency
+	ScalpImbalance       float64 // Minimum order book imbalance to quote
+	ScalpMaxSpreadBps    float64
+	ScalpMinDepth        float64 // Minimum quote value on each side of the top 10 levels
+	ScalpOrderTTLSeconds float64
+	ScalpMaxInventory    float64 // Maximum inventory value per symbol
+	// Market making: Avellaneda-Stoikov quotes kept on both sides of the configured symbols
+	MMSymbols        []string
+	MMCheckSeconds   int
+	MMOrderNotional  float64 // Notional per quote in the quote currency
+	MMRefreshBps     float64 // Quotes are replaced once the model's price moves this far
+	MMMaxInventory   float64 // Maximum inventory value per symbol
+	MMHedgeInventory bool    // Sell inventory above the limit at market instead of only stopping bids
+	// Triangular arbitrage: cycles of three spot markets scanned on a fast schedule
+	TriArbTriangles        [][3]string
+	TriArbCheckSeconds     int
+	TriArbTakerFeePercent  float64
+	TriArbMinProfitPercent float64 // Minimum cycle return after taker fees
+	TriArbMaxLatencyMs     float64 // Maximum time to fetch the three order books
+	TriArbNotional         float64 // Amount of the start coin put through each cycle
+	// Ensemble: strategies voting with weights, replacing the AI's strategy selection when set
+	EnsembleStrategies     map[string]float64
+	EnsembleMinConsensus   float64
+	EnsembleLearnWeights   bool
+	EnsembleAccuracyWindow int
+	// Bandit selection: Thompson sampling of strategy win rates per market regime
+	BanditSelection bool
+	BanditPrior     float64 // Pseudo-wins and pseudo-losses of every strategy before any trade
+	BanditDecay     float64 // Weight kept by past outcomes at each new outcome, 1 keeps all history
+	// Signal calibration: order sizes follow the observed hit rate of signals of similar strength
+	SignalCalibration  bool
+	CalibrationBuckets int
+	CalibrationPrior   float64 // Pseudo-signals at the reported strength in every bucket
+	// Signal hysteresis: opposite-direction signals must persist or move the score far enough
+	SignalPersistence int     // Consecutive cycles before a strategy flips between BUY and SELL, 1 disables
+	SignalFlipChange  float64 // Signed score change that flips the direction at once, 0 disables
+	// Signal fusion: strategy signals are checked against the analyzer's indicator signals
+	SignalFusion         string  // "agree", "weighted" or "off"
+	FusionCombinedWeight float64 // Weight of the combined indicator signal in weighted mode
+	FusionVolumeWeight   float64 // Weight of the volume-weighted signal in weighted mode
+	FusionMinScore       float64 // Blended score a signal needs in weighted mode
+	// Market sentiment: a registered provider ("fear_greed", "http", "static") whose score from
+	// -1 (fear) to 1 (greed) is attached to the market data, empty disables
+	SentimentProvider     string
+	SentimentURL          string             // Provider endpoint, {symbol} is replaced by the symbol
+	SentimentScores       map[string]float64 // Fixed scores of the static provider, e.g. *:0.2
+	SentimentCacheMinutes int
+	SentimentWeight       float64            // Weight of sentiment in the combined indicator signal
+	SentimentBuyMax       map[string]float64 // Per strategy, BUYs are held back above this sentiment
+	SentimentSellMin      map[string]float64 // Per strategy, SELLs are held back below this sentiment
+	// Backtest costs: slippage model ("fixed", "spread" or "volume") and fees of every simulated fill
+	BacktestSlippageModel     string
+	BacktestSlippageBps       float64            // Fixed slippage, and the floor of the volume model
+	BacktestSpreadBps         map[string]float64 // Spread per symbol for the spread model, e.g. BTCUSDT:1,*:5
+	BacktestImpactCoefficient float64            // Slippage of an order as large as the bar volume
+	BacktestMakerFeePercent   float64
+	BacktestTakerFeePercent   float64
+	BacktestDataDir           string // Local CSV candles backtests can use instead of Bybit klines
+	KlineCache                bool   // Cache downloaded historical klines in the data directory
+	BacktestIntrabarPath      string // Assumed order of a bar's prices for limit fills: "nearest", "ohlc" or "olhc"
+	BacktestRiskExits         bool   // Simulate the risk engine's stop-loss, take-profit and trailing stops
+	HistoricalDataSource      string // Klines of backtests and warm-ups: "bybit" or "csv" (BacktestDataDir)
+	// In-sample/out-of-sample splits: percent of the period held out (0 disables) and the share of
+	// the in-sample Sharpe ratio kept out of sample below which a parameter set is flagged overfit
+	BacktestOOSPercent   float64
+	BacktestOverfitRatio float64
+	// Position sizing of backtest entries: "equal", "fixed" (BacktestSizingFraction of equity), "atr"
+	// (RiskPerTrade at an ATR stop), "kelly" (BacktestKellyFraction of the Kelly fraction) or
+	// "vol_target" (VolTarget)
+	BacktestSizing         string
+	BacktestSizingFraction float64
+	BacktestKellyFraction  float64
+	// Funding of backtest positions held as perpetuals: "none", "bybit" (historical rates), "csv"
+	// (the files of BacktestFundingDir) or "flat" (BacktestFundingRate every 8 hours)
+	BacktestFunding     string
+	BacktestFundingDir  string
+	BacktestFundingRate float64
+	BacktestWorkers     int // Backtests of sweeps, walk-forward analyses and searches run in parallel
+	// Per-symbol allocation limits as fractions of capital ("*" applies to all other symbols)
+	AllocationCaps   map[string]float64
+	AllocationFloors map[string]float64
+	// How target allocations follow performance and volatility: "adjusted", "equal",
+	// "performance" or "volatility"
+	AllocationMode string
+	// Buy-and-hold benchmark: a symbol (e.g. BTCUSDT) or EQUAL_WEIGHT for the traded basket
+	Benchmark string
+	// Drift-triggered rebalancing: weight drift that triggers an early rebalance (0 disables)
+	RebalanceDriftThreshold float64
+	DriftCheckMinutes       int
+	// Percent of capital that is always kept in cash and never allocated (e.g. 10.0 for 10%)
+	CashReservePercent float64
+	// Handling of the unfilled remainder of partially filled orders ("cancel" or "keep")
+	PartialFillPolicy         string
+	PartialFillTimeoutSeconds int
+	// Trading budget: maximum orders per UTC day overall and per symbol (0 = unlimited)
+	MaxTradesPerDay    int
+	MaxTradesPerSymbol int
+	// Value-at-Risk limit in percent of capital (0 disables) and the confidence it applies to
+	MaxVaRPercent float64
+	// Ceiling of the Herfindahl-Hirschman index of position weights (0 disables)
+	MaxConcentrationHHI float64
+	// Annualized portfolio volatility target that scales all allocations (0 disables)
+	VolTarget         float64
+	VolTargetMinScale float64 // Smallest allocation multiplier
+	VolTargetMaxScale float64 // Largest allocation multiplier
+	VaRConfidence     float64
+	// ATR position sizing: ATR lookback and stop distance in multiples of ATR
+	ATRPeriod         int
+	ATRStopMultiplier float64
+	// Loss since the start of the UTC day, in percent of capital, that halts trading (0 disables)
+	DailyLossLimitPercent float64
+	// Per-symbol overrides of StopLossPercent, TakeProfitPercent and MaxDrawdown ("*" applies to all other symbols)
+	StopLossOverrides    map[string]float64
+	TakeProfitOverrides  map[string]float64
+	MaxDrawdownOverrides map[string]float64
+	// Correlation concentration: pairs above the threshold may hold at most this share of capital (0 disables)
+	CorrelationThreshold  float64
+	MaxCorrelatedExposure float64
+	// Liquidation monitoring for leveraged derivatives positions
+	MonitorDerivatives       bool
+	LiquidationBufferPercent float64 // Warn when price is within this % of liquidation
+	DeleverageBufferPercent  float64 // Reduce the position when price is within this % of liquidation
+	DeleverageFraction       float64 // Fraction of the position to close when deleveraging
+	// Optional JSON file with risk rules replacing the built-in limits
+	RiskRulesFile string
+	// Loss streak guard: consecutive losses within the window that pause trading for the cooldown
+	LossStreakMax             int                // Per strategy and per symbol (0 disables)
+	LossStreakBotMax          int                // Across all trades, pauses the whole bot (0 disables)
+	LossStreakOverrides       map[string]float64 // Per-strategy or per-symbol limits
+	LossStreakWindowMinutes   int
+	LossStreakCooldownMinutes int
+	// Strategy cooldown: a strategy whose realized PnL within the window falls below the limit
+	// (a fraction of TotalCapital, 0 disables) is disabled for the cooldown, or until its paper
+	// trades during the cooldown are profitable over at least StrategyRecoveryTrades trades
+	StrategyDrawdownLimit         float64
+	StrategyDrawdownWindowMinutes int
+	StrategyCooldownMinutes       int
+	StrategyRecoveryTrades        int // 0 disables the early recovery check
+	// UTC trading sessions per strategy, strategy/symbol pair or "*", e.g.
+	// MOMENTUM:MON-FRI 04:00-24:00,DCA/BTCUSDT:00:00-08:00|20:00-24:00
+	StrategySessions map[string][]TradingSession
+	// Symbol categories (e.g. BTCUSDT:L1,DOGEUSDT:MEME) and the maximum share of capital
+	// per category ("*" applies to all other categories, including uncategorized symbols)
+	SymbolCategories map[string]string
+	CategoryLimits   map[string]float64
+	// Share of TotalCapital each strategy's positions may use, e.g. MOMENTUM:0.4
+	StrategyCapital map[string]float64
+	// Keep stop-loss and take-profit orders on the exchange for open positions
+	PlaceProtectiveOrders bool
+	// Trailing stops: activate after the gain threshold, then trail the highest price
+	TrailingStopActivationPercent float64
+	TrailingStopPercent           float64 // Trailing distance (0 uses the stop-loss percentage)
+	TrailingStopCheckSeconds      int     // Interval of live price checks between cycles (0 disables)
+	// Maximum holding period in hours before a position is closed (0 disables)
+	MaxHoldingHours     float64
+	MaxHoldingOverrides map[string]float64 // Per-strategy holding periods, e.g. MOMENTUM:48
+	// Shrink orders that exceed a size or exposure limit instead of rejecting them
+	PreTradeResize bool
+	// Open linear perpetual shorts on SELL signals for symbols without spot inventory, which
+	// are skipped otherwise
+	ShortSelling     bool
+	MaxShortExposure float64 // Maximum total value of short positions (0 disables the limit)
+	// Headroom kept on top of an order's notional for fees and price moves when checking the
+	// available balance, in percent
+	BalanceBufferPercent float64
+	// Circuit breaker settings shared by the per-endpoint breakers
+	CircuitBreakerTimeoutSeconds   int
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerHalfOpenProbes   int // Trial calls allowed while half-open
+	CircuitBreakerSuccessThreshold int // Successful probes needed to close
+	// Recent log messages kept in memory for the dashboard
+	LogBufferSize int
 }
 
 // LoadConfig loads configuration from environment variables
@@ -30,6 +246,7 @@ func LoadConfig() (*Config, error) {
 		BybitAPIKey:    os.Getenv("BYBIT_API_KEY"),
 		BybitAPISecret: os.Getenv("BYBIT_API_SECRET"),
 		Testnet:        os.Getenv("TESTNET") == "true",
+		PaperTrading:   os.Getenv("PAPER_TRADING") == "true",
 	}
 
 	if val, err := strconv.ParseFloat(os.Getenv("TOTAL_CAPITAL"), 64); err == nil {
@@ -81,5 +298,746 @@ func LoadConfig() (*Config, error) {
 		cfg.TakeProfitPercent = 5.0 // Default 5% take-profit
 	}
 
+	// Load trailing stop settings
+	if val, err := strconv.ParseFloat(os.Getenv("TRAILING_STOP_ACTIVATION_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.TrailingStopActivationPercent = val
+	} else {
+		cfg.TrailingStopActivationPercent = 2.0 // Default trail after +2%
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("TRAILING_STOP_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.TrailingStopPercent = val
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("TRAILING_STOP_CHECK_SECONDS")); err == nil && val >= 0 {
+		cfg.TrailingStopCheckSeconds = val
+	} else {
+		cfg.TrailingStopCheckSeconds = 30 // Default to checking every 30 seconds
+	}
+
+	// Load data directory for persisted state
+	cfg.DataDir = os.Getenv("DATA_DIR")
+	if cfg.DataDir == "" {
+		cfg.DataDir = "data" // Default to ./data
+	}
+
+	// Load reporting currency
+	cfg.ReportingCurrency = os.Getenv("REPORTING_CURRENCY")
+	if cfg.ReportingCurrency == "" {
+		cfg.ReportingCurrency = "USDT" // Default to USDT
+	}
+
+	// Load symbol pinning and exclusion lists
+	cfg.PinnedSymbols = parseList(os.Getenv("PINNED_SYMBOLS"))
+	cfg.ExcludedSymbols = parseList(os.Getenv("EXCLUDED_SYMBOLS"))
+
+	// Load the strategy parameter file
+	cfg.StrategyParamsFile = os.Getenv("STRATEGY_PARAMS_FILE")
+
+	// Load additional kline timeframes
+	cfg.Timeframes = parseList(os.Getenv("TIMEFRAMES"))
+
+	// Load third-party strategy names and plugin files
+	cfg.StrategyPlugins = parseNames(os.Getenv("STRATEGY_PLUGINS"))
+	cfg.StrategyPluginPaths = parseNames(os.Getenv("STRATEGY_PLUGIN_PATHS"))
+
+	// Load shadow mode settings
+	cfg.ShadowStrategies = parseNames(os.Getenv("SHADOW_STRATEGIES"))
+	if val, err := strconv.ParseFloat(os.Getenv("SHADOW_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.ShadowNotional = val
+	} else {
+		cfg.ShadowNotional = 100 // Default 100 per fill
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SHADOW_FEE_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.ShadowFeePercent = val
+	} else {
+		cfg.ShadowFeePercent = 0.1 // Default 0.1% taker fee
+	}
+
+	// Load dollar-cost averaging settings
+	cfg.DCASymbols = parseList(os.Getenv("DCA_SYMBOLS"))
+	if val, err := strconv.ParseFloat(os.Getenv("DCA_AMOUNT"), 64); err == nil && val > 0 {
+		cfg.DCAAmount = val
+	} else {
+		cfg.DCAAmount = 50 // Default 50 per buy
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("DCA_INTERVAL_HOURS"), 64); err == nil && val > 0 {
+		cfg.DCAIntervalHours = val
+	} else {
+		cfg.DCAIntervalHours = 24 // Default daily
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("DCA_DIP_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.DCADipPercent = val
+	} else {
+		cfg.DCADipPercent = 5 // Default 5% below the moving average
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("DCA_DIP_MULTIPLIER"), 64); err == nil && val > 0 {
+		cfg.DCADipMultiplier = val
+	} else {
+		cfg.DCADipMultiplier = 2 // Default double on dips
+	}
+
+	// Load pairs trading settings
+	cfg.Pairs = parsePairs(os.Getenv("PAIRS"))
+	if val, err := strconv.ParseFloat(os.Getenv("PAIRS_ENTRY_Z"), 64); err == nil && val > 0 {
+		cfg.PairsEntryZ = val
+	} else {
+		cfg.PairsEntryZ = 2 // Default 2 standard deviations
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("PAIRS_EXIT_Z"), 64); err == nil && val >= 0 {
+		cfg.PairsExitZ = val
+	} else {
+		cfg.PairsExitZ = 0.5 // Default 0.5 standard deviations
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("PAIRS_STOP_Z"), 64); err == nil && val > 0 {
+		cfg.PairsStopZ = val
+	} else {
+		cfg.PairsStopZ = 4 // Default 4 standard deviations
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("PAIRS_MIN_CORRELATION"), 64); err == nil && val >= 0 && val <= 1 {
+		cfg.PairsMinCorrelation = val
+	} else {
+		cfg.PairsMinCorrelation = 0.8 // Default 0.8
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("PAIRS_LEG_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.PairsLegNotional = val
+	} else {
+		cfg.PairsLegNotional = 100 // Default 100 USDT
+	}
+
+	// Load funding arbitrage settings
+	cfg.FundingArbSymbols = parseList(os.Getenv("FUNDING_ARB_SYMBOLS"))
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_ARB_ENTRY_RATE"), 64); err == nil && val > 0 {
+		cfg.FundingArbEntryRate = val
+	} else {
+		cfg.FundingArbEntryRate = 0.0003 // Default 0.03% per 8h
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_ARB_EXIT_RATE"), 64); err == nil {
+		cfg.FundingArbExitRate = val
+	} else {
+		cfg.FundingArbExitRate = 0.0001 // Default 0.01% per 8h, the neutral rate
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_ARB_MAX_BASIS_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.FundingArbMaxBasisPercent = val
+	} else {
+		cfg.FundingArbMaxBasisPercent = 0.5 // Default 0.5%
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUNDING_ARB_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.FundingArbNotional = val
+	} else {
+		cfg.FundingArbNotional = 200 // Default 200 USDT per leg
+	}
+
+	// Load order book scalping settings
+	cfg.ScalpSymbols = parseList(os.Getenv("SCALP_SYMBOLS"))
+	if val, err := strconv.Atoi(os.Getenv("SCALP_CHECK_SECONDS")); err == nil && val > 0 {
+		cfg.ScalpCheckSeconds = val
+	} else {
+		cfg.ScalpCheckSeconds = 5 // Default 5 seconds
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_ORDER_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.ScalpOrderNotional = val
+	} else {
+		cfg.ScalpOrderNotional = 50 // Default 50 per order
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_IMBALANCE"), 64); err == nil && val > 0 && val < 1 {
+		cfg.ScalpImbalance = val
+	} else {
+		cfg.ScalpImbalance = 0.3 // Default 0.3
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_MAX_SPREAD_BPS"), 64); err == nil && val > 0 {
+		cfg.ScalpMaxSpreadBps = val
+	} else {
+		cfg.ScalpMaxSpreadBps = 5 // Default 5 bps
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_MIN_DEPTH"), 64); err == nil && val >= 0 {
+		cfg.ScalpMinDepth = val
+	} else {
+		cfg.ScalpMinDepth = 50000 // Default 50k on each side
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_ORDER_TTL_SECONDS"), 64); err == nil && val > 0 {
+		cfg.ScalpOrderTTLSeconds = val
+	} else {
+		cfg.ScalpOrderTTLSeconds = 15 // Default 15 seconds
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SCALP_MAX_INVENTORY"), 64); err == nil && val > 0 {
+		cfg.ScalpMaxInventory = val
+	} else {
+		cfg.ScalpMaxInventory = 200 // Default 200 per symbol
+	}
+
+	// Load market making settings
+	cfg.MMSymbols = parseList(os.Getenv("MM_SYMBOLS"))
+	if val, err := strconv.Atoi(os.Getenv("MM_CHECK_SECONDS")); err == nil && val > 0 {
+		cfg.MMCheckSeconds = val
+	} else {
+		cfg.MMCheckSeconds = 10 // Default 10 seconds
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("MM_ORDER_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.MMOrderNotional = val
+	} else {
+		cfg.MMOrderNotional = 100 // Default 100 per quote
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("MM_REFRESH_BPS"), 64); err == nil && val > 0 {
+		cfg.MMRefreshBps = val
+	} else {
+		cfg.MMRefreshBps = 10 // Default 10 bps
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("MM_MAX_INVENTORY"), 64); err == nil && val > 0 {
+		cfg.MMMaxInventory = val
+	} else {
+		cfg.MMMaxInventory = 500 // Default 500 per symbol
+	}
+	cfg.MMHedgeInventory = os.Getenv("MM_HEDGE_INVENTORY") == "true"
+
+	// Load triangular arbitrage settings
+	cfg.TriArbTriangles = parseTriangles(os.Getenv("TRIARB_TRIANGLES"))
+	if val, err := strconv.Atoi(os.Getenv("TRIARB_CHECK_SECONDS")); err == nil && val > 0 {
+		cfg.TriArbCheckSeconds = val
+	} else {
+		cfg.TriArbCheckSeconds = 5 // Default 5 seconds
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("TRIARB_TAKER_FEE_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.TriArbTakerFeePercent = val
+	} else {
+		cfg.TriArbTakerFeePercent = 0.1 // Default 0.1% spot taker fee
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("TRIARB_MIN_PROFIT_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.TriArbMinProfitPercent = val
+	} else {
+		cfg.TriArbMinProfitPercent = 0.05 // Default 0.05%
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("TRIARB_MAX_LATENCY_MS"), 64); err == nil && val > 0 {
+		cfg.TriArbMaxLatencyMs = val
+	} else {
+		cfg.TriArbMaxLatencyMs = 300 // Default 300ms
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("TRIARB_NOTIONAL"), 64); err == nil && val > 0 {
+		cfg.TriArbNotional = val
+	} else {
+		cfg.TriArbNotional = 100 // Default 100 of the start coin
+	}
+
+	// Load ensemble settings (e.g. "momentum:1,mean_reversion:0.5")
+	cfg.EnsembleStrategies = parseFloatMap(os.Getenv("ENSEMBLE_STRATEGIES"))
+	if val, err := strconv.ParseFloat(os.Getenv("ENSEMBLE_MIN_CONSENSUS"), 64); err == nil && val > 0 && val <= 1 {
+		cfg.EnsembleMinConsensus = val
+	} else {
+		cfg.EnsembleMinConsensus = 0.3 // Default 0.3
+	}
+	cfg.EnsembleLearnWeights = os.Getenv("ENSEMBLE_LEARN_WEIGHTS") != "false"
+	if val, err := strconv.Atoi(os.Getenv("ENSEMBLE_ACCURACY_WINDOW")); err == nil && val >= 5 {
+		cfg.EnsembleAccuracyWindow = val
+	} else {
+		cfg.EnsembleAccuracyWindow = 30 // Default 30 votes
+	}
+
+	// Load bandit selection settings
+	cfg.BanditSelection = os.Getenv("BANDIT_SELECTION") == "true"
+	if val, err := strconv.ParseFloat(os.Getenv("BANDIT_PRIOR"), 64); err == nil && val > 0 {
+		cfg.BanditPrior = val
+	} else {
+		cfg.BanditPrior = 2 // Default 2 pseudo-trades each way
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BANDIT_DECAY"), 64); err == nil && val > 0 && val <= 1 {
+		cfg.BanditDecay = val
+	} else {
+		cfg.BanditDecay = 0.98 // Default 0.98
+	}
+
+	// Load signal calibration settings
+	cfg.SignalCalibration = os.Getenv("SIGNAL_CALIBRATION") == "true"
+	if val, err := strconv.Atoi(os.Getenv("CALIBRATION_BUCKETS")); err == nil && val >= 2 {
+		cfg.CalibrationBuckets = val
+	} else {
+		cfg.CalibrationBuckets = 10 // Default 10 buckets
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("CALIBRATION_PRIOR"), 64); err == nil && val >= 0 {
+		cfg.CalibrationPrior = val
+	} else {
+		cfg.CalibrationPrior = 10 // Default 10 pseudo-signals
+	}
+
+	// Load signal hysteresis settings
+	if val, err := strconv.Atoi(os.Getenv("SIGNAL_PERSISTENCE")); err == nil && val >= 1 {
+		cfg.SignalPersistence = val
+	} else {
+		cfg.SignalPersistence = 1 // Default 1 cycle (disabled)
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SIGNAL_FLIP_CHANGE"), 64); err == nil && val >= 0 {
+		cfg.SignalFlipChange = val
+	} else {
+		cfg.SignalFlipChange = 0 // Default disabled
+	}
+
+	// Load signal fusion settings
+	cfg.SignalFusion = strings.ToLower(os.Getenv("SIGNAL_FUSION"))
+	if cfg.SignalFusion != "agree" && cfg.SignalFusion != "weighted" {
+		cfg.SignalFusion = "off" // Default off
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUSION_COMBINED_WEIGHT"), 64); err == nil && val >= 0 && val <= 1 {
+		cfg.FusionCombinedWeight = val
+	} else {
+		cfg.FusionCombinedWeight = 0.3 // Default 30%
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUSION_VOLUME_WEIGHT"), 64); err == nil && val >= 0 && val <= 1 {
+		cfg.FusionVolumeWeight = val
+	} else {
+		cfg.FusionVolumeWeight = 0.2 // Default 20%
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("FUSION_MIN_SCORE"), 64); err == nil && val >= 0 {
+		cfg.FusionMinScore = val
+	} else {
+		cfg.FusionMinScore = 0.3 // Default 0.3
+	}
+
+	// Load market sentiment settings
+	cfg.SentimentProvider = strings.ToLower(os.Getenv("SENTIMENT_PROVIDER"))
+	cfg.SentimentURL = os.Getenv("SENTIMENT_URL")
+	cfg.SentimentScores = parseFloatMap(os.Getenv("SENTIMENT_SCORES"))
+	if val, err := strconv.Atoi(os.Getenv("SENTIMENT_CACHE_MINUTES")); err == nil && val >= 0 {
+		cfg.SentimentCacheMinutes = val
+	} else {
+		cfg.SentimentCacheMinutes = 60 // Default 1 hour
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("SENTIMENT_WEIGHT"), 64); err == nil && val >= 0 {
+		cfg.SentimentWeight = val
+	} else {
+		cfg.SentimentWeight = 0 // Default left out of combined signals
+	}
+	cfg.SentimentBuyMax = parseFloatMap(os.Getenv("SENTIMENT_BUY_MAX"))
+	cfg.SentimentSellMin = parseFloatMap(os.Getenv("SENTIMENT_SELL_MIN"))
+
+	// Load backtest cost settings
+	cfg.BacktestSlippageModel = strings.ToLower(os.Getenv("BACKTEST_SLIPPAGE_MODEL"))
+	if cfg.BacktestSlippageModel != "spread" && cfg.BacktestSlippageModel != "volume" {
+		cfg.BacktestSlippageModel = "fixed" // Default fixed slippage
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_SLIPPAGE_BPS"), 64); err == nil && val >= 0 {
+		cfg.BacktestSlippageBps = val
+	} else {
+		cfg.BacktestSlippageBps = 2 // Default 2 basis points
+	}
+	cfg.BacktestSpreadBps = parseFloatMap(os.Getenv("BACKTEST_SPREAD_BPS"))
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_IMPACT_COEFFICIENT"), 64); err == nil && val >= 0 {
+		cfg.BacktestImpactCoefficient = val
+	} else {
+		cfg.BacktestImpactCoefficient = 0.1 // Default 10% for an order of the whole bar volume
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_MAKER_FEE_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.BacktestMakerFeePercent = val
+	} else {
+		cfg.BacktestMakerFeePercent = 0.1 // Default 0.1% spot maker fee
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_TAKER_FEE_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.BacktestTakerFeePercent = val
+	} else {
+		cfg.BacktestTakerFeePercent = 0.1 // Default 0.1% spot taker fee
+	}
+	cfg.BacktestDataDir = os.Getenv("BACKTEST_DATA_DIR")
+	cfg.KlineCache = os.Getenv("KLINE_CACHE") != "false" // Default on
+	cfg.BacktestIntrabarPath = strings.ToLower(os.Getenv("BACKTEST_INTRABAR_PATH"))
+	if cfg.BacktestIntrabarPath != "ohlc" && cfg.BacktestIntrabarPath != "olhc" {
+		cfg.BacktestIntrabarPath = "nearest" // Default the extreme nearer to the open first
+	}
+	cfg.BacktestRiskExits = os.Getenv("BACKTEST_RISK_EXITS") != "false" // Default on
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_OOS_PERCENT"), 64); err == nil && val >= 0 && val < 100 {
+		cfg.BacktestOOSPercent = val
+	} else {
+		cfg.BacktestOOSPercent = 0 // Default no split
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_OVERFIT_RATIO"), 64); err == nil && val >= 0 {
+		cfg.BacktestOverfitRatio = val
+	} else {
+		cfg.BacktestOverfitRatio = 0.5 // Default half of the in-sample Sharpe ratio
+	}
+	cfg.BacktestSizing = strings.ToLower(os.Getenv("BACKTEST_SIZING"))
+	if cfg.BacktestSizing != "fixed" && cfg.BacktestSizing != "atr" && cfg.BacktestSizing != "kelly" && cfg.BacktestSizing != "vol_target" {
+		cfg.BacktestSizing = "equal" // Default each symbol's equal share of the equity
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_SIZING_FRACTION"), 64); err == nil && val > 0 && val <= 1 {
+		cfg.BacktestSizingFraction = val
+	} else {
+		cfg.BacktestSizingFraction = 0.1 // Default 10% of the equity per entry
+	}
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_KELLY_FRACTION"), 64); err == nil && val > 0 {
+		cfg.BacktestKellyFraction = val
+	} else {
+		cfg.BacktestKellyFraction = 0.5 // Default half Kelly
+	}
+	cfg.BacktestFunding = strings.ToLower(os.Getenv("BACKTEST_FUNDING"))
+	if cfg.BacktestFunding != "bybit" && cfg.BacktestFunding != "csv" && cfg.BacktestFunding != "flat" {
+		cfg.BacktestFunding = "none" // Default spot positions without carry
+	}
+	cfg.BacktestFundingDir = os.Getenv("BACKTEST_FUNDING_DIR")
+	if val, err := strconv.ParseFloat(os.Getenv("BACKTEST_FUNDING_RATE"), 64); err == nil {
+		cfg.BacktestFundingRate = val
+	} else {
+		cfg.BacktestFundingRate = 0.0001 // Default 0.01% per 8h, the neutral rate
+	}
+	if val, err := strconv.Atoi(os.Getenv("BACKTEST_WORKERS")); err == nil && val > 0 {
+		cfg.BacktestWorkers = val
+	} else {
+		cfg.BacktestWorkers = runtime.NumCPU() // Default one per CPU
+	}
+	cfg.HistoricalDataSource = strings.ToLower(os.Getenv("HISTORICAL_DATA_SOURCE"))
+	if cfg.HistoricalDataSource != "csv" {
+		cfg.HistoricalDataSource = "bybit" // Default the Bybit REST API
+	}
+
+	// Load per-symbol allocation caps and floors (e.g. "BTCUSDT:0.4,*:0.15")
+	cfg.AllocationCaps = parseFloatMap(os.Getenv("ALLOCATION_CAPS"))
+	cfg.AllocationFloors = parseFloatMap(os.Getenv("ALLOCATION_FLOORS"))
+
+	// Load allocation mode
+	cfg.AllocationMode = strings.ToLower(os.Getenv("ALLOCATION_MODE"))
+	if !isAllocationMode(cfg.AllocationMode) {
+		cfg.AllocationMode = AllocationAdjusted // Default to blending performance and volatility
+	}
+
+	// Load benchmark
+	cfg.Benchmark = strings.ToUpper(os.Getenv("BENCHMARK"))
+	if cfg.Benchmark == "" {
+		cfg.Benchmark = "BTCUSDT" // Default to BTC buy-and-hold
+	}
+
+	// Load drift-triggered rebalancing settings
+	if val, err := strconv.ParseFloat(os.Getenv("REBALANCE_DRIFT_THRESHOLD"), 64); err == nil {
+		cfg.RebalanceDriftThreshold = val
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("DRIFT_CHECK_MINUTES")); err == nil && val > 0 {
+		cfg.DriftCheckMinutes = val
+	} else {
+		cfg.DriftCheckMinutes = 1 // Default to checking every minute
+	}
+
+	// Load cash reserve
+	if val, err := strconv.ParseFloat(os.Getenv("CASH_RESERVE_PERCENT"), 64); err == nil && val >= 0 && val < 100 {
+		cfg.CashReservePercent = val
+	}
+
+	// Load partial fill policy
+	cfg.PartialFillPolicy = strings.ToLower(os.Getenv("PARTIAL_FILL_POLICY"))
+	if cfg.PartialFillPolicy != "keep" {
+		cfg.PartialFillPolicy = "cancel" // Default to cancelling stale remainders
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("PARTIAL_FILL_TIMEOUT_SECONDS")); err == nil && val >= 0 {
+		cfg.PartialFillTimeoutSeconds = val
+	} else {
+		cfg.PartialFillTimeoutSeconds = 60 // Default 1 minute
+	}
+
+	// Load trading budget limits
+	if val, err := strconv.Atoi(os.Getenv("MAX_TRADES_PER_DAY")); err == nil && val >= 0 {
+		cfg.MaxTradesPerDay = val
+	} else {
+		cfg.MaxTradesPerDay = 20 // Default 20 orders per day
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("MAX_TRADES_PER_SYMBOL")); err == nil && val >= 0 {
+		cfg.MaxTradesPerSymbol = val
+	} else {
+		cfg.MaxTradesPerSymbol = 5 // Default 5 orders per symbol per day
+	}
+
+	// Load Value-at-Risk limit
+	if val, err := strconv.ParseFloat(os.Getenv("MAX_VAR_PERCENT"), 64); err == nil && val >= 0 && val <= 100 {
+		cfg.MaxVaRPercent = val
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("MAX_CONCENTRATION_HHI"), 64); err == nil && val >= 0 {
+		cfg.MaxConcentrationHHI = val
+	}
+
+	// Load volatility targeting settings
+	if val, err := strconv.ParseFloat(os.Getenv("VOL_TARGET"), 64); err == nil && val >= 0 {
+		cfg.VolTarget = val
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("VOL_TARGET_MIN_SCALE"), 64); err == nil && val >= 0 {
+		cfg.VolTargetMinScale = val
+	} else {
+		cfg.VolTargetMinScale = 0.25 // Default scale down to a quarter at most
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("VOL_TARGET_MAX_SCALE"), 64); err == nil && val > 0 {
+		cfg.VolTargetMaxScale = val
+	} else {
+		cfg.VolTargetMaxScale = 1.5 // Default scale up by half at most
+	}
+
+	cfg.VaRConfidence = 0.95 // Default 95% confidence
+	if val, err := strconv.ParseFloat(os.Getenv("VAR_CONFIDENCE"), 64); err == nil && val == 0.99 {
+		cfg.VaRConfidence = val
+	}
+
+	// Load ATR position sizing settings
+	if val, err := strconv.Atoi(os.Getenv("ATR_PERIOD")); err == nil && val > 0 {
+		cfg.ATRPeriod = val
+	} else {
+		cfg.ATRPeriod = 14 // Default 14 periods
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("ATR_STOP_MULTIPLIER"), 64); err == nil && val > 0 {
+		cfg.ATRStopMultiplier = val
+	} else {
+		cfg.ATRStopMultiplier = 2.0 // Default stop at 2x ATR
+	}
+
+	// Load daily loss limit
+	if val, err := strconv.ParseFloat(os.Getenv("DAILY_LOSS_LIMIT_PERCENT"), 64); err == nil && val >= 0 && val <= 100 {
+		cfg.DailyLossLimitPercent = val
+	}
+
+	// Load per-symbol risk overrides
+	cfg.StopLossOverrides = parseFloatMap(os.Getenv("STOP_LOSS_OVERRIDES"))
+	cfg.TakeProfitOverrides = parseFloatMap(os.Getenv("TAKE_PROFIT_OVERRIDES"))
+	cfg.MaxDrawdownOverrides = parseFloatMap(os.Getenv("MAX_DRAWDOWN_OVERRIDES"))
+
+	// Load correlation concentration limits
+	if val, err := strconv.ParseFloat(os.Getenv("CORRELATION_THRESHOLD"), 64); err == nil && val > 0 && val <= 1 {
+		cfg.CorrelationThreshold = val
+	} else {
+		cfg.CorrelationThreshold = 0.7 // Default 0.7 correlation
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("MAX_CORRELATED_EXPOSURE"), 64); err == nil && val >= 0 {
+		cfg.MaxCorrelatedExposure = val
+	} else {
+		cfg.MaxCorrelatedExposure = 0.5 // Default 50% of capital
+	}
+
+	// Load liquidation monitoring settings
+	cfg.MonitorDerivatives = os.Getenv("MONITOR_DERIVATIVES") == "true"
+
+	if val, err := strconv.ParseFloat(os.Getenv("LIQUIDATION_BUFFER_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.LiquidationBufferPercent = val
+	} else {
+		cfg.LiquidationBufferPercent = 10.0 // Default warn within 10%
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("DELEVERAGE_BUFFER_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.DeleverageBufferPercent = val
+	} else {
+		cfg.DeleverageBufferPercent = 5.0 // Default deleverage within 5%
+	}
+
+	if val, err := strconv.ParseFloat(os.Getenv("DELEVERAGE_FRACTION"), 64); err == nil && val > 0 && val <= 1 {
+		cfg.DeleverageFraction = val
+	} else {
+		cfg.DeleverageFraction = 0.25 // Default close a quarter of the position
+	}
+
+	// Load risk rules file
+	cfg.RiskRulesFile = os.Getenv("RISK_RULES_FILE")
+
+	// Load loss streak guard settings
+	if val, err := strconv.Atoi(os.Getenv("LOSS_STREAK_MAX")); err == nil && val >= 0 {
+		cfg.LossStreakMax = val
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("LOSS_STREAK_BOT_MAX")); err == nil && val >= 0 {
+		cfg.LossStreakBotMax = val
+	}
+
+	cfg.LossStreakOverrides = parseFloatMap(os.Getenv("LOSS_STREAK_OVERRIDES"))
+
+	if val, err := strconv.Atoi(os.Getenv("LOSS_STREAK_WINDOW_MINUTES")); err == nil && val > 0 {
+		cfg.LossStreakWindowMinutes = val
+	} else {
+		cfg.LossStreakWindowMinutes = 1440 // Default 24 hours
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("LOSS_STREAK_COOLDOWN_MINUTES")); err == nil && val >= 0 {
+		cfg.LossStreakCooldownMinutes = val
+	} else {
+		cfg.LossStreakCooldownMinutes = 240 // Default 4 hours
+	}
+
+	// Load strategy cooldown settings
+	if val, err := strconv.ParseFloat(os.Getenv("STRATEGY_DRAWDOWN_LIMIT"), 64); err == nil && val >= 0 {
+		cfg.StrategyDrawdownLimit = val
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("STRATEGY_DRAWDOWN_WINDOW_MINUTES")); err == nil && val > 0 {
+		cfg.StrategyDrawdownWindowMinutes = val
+	} else {
+		cfg.StrategyDrawdownWindowMinutes = 1440 // Default 24 hours
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("STRATEGY_COOLDOWN_MINUTES")); err == nil && val > 0 {
+		cfg.StrategyCooldownMinutes = val
+	} else {
+		cfg.StrategyCooldownMinutes = 720 // Default 12 hours
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("STRATEGY_RECOVERY_TRADES")); err == nil && val >= 0 {
+		cfg.StrategyRecoveryTrades = val
+	} else {
+		cfg.StrategyRecoveryTrades = 3 // Default 3 paper trades
+	}
+
+	// Load trading sessions
+	cfg.StrategySessions = parseSessionMap(os.Getenv("STRATEGY_SESSIONS"))
+
+	// Load symbol categories and category exposure limits
+	cfg.SymbolCategories = parseStringMap(os.Getenv("SYMBOL_CATEGORIES"))
+	cfg.CategoryLimits = parseFloatMap(os.Getenv("CATEGORY_LIMITS"))
+
+	// Load per-strategy capital buckets
+	cfg.StrategyCapital = parseFloatMap(os.Getenv("STRATEGY_CAPITAL"))
+
+	// Load time-based exit settings
+	if val, err := strconv.ParseFloat(os.Getenv("MAX_HOLDING_HOURS"), 64); err == nil && val >= 0 {
+		cfg.MaxHoldingHours = val
+	}
+
+	cfg.MaxHoldingOverrides = parseFloatMap(os.Getenv("MAX_HOLDING_OVERRIDES"))
+
+	// Load pre-trade check settings
+	cfg.PreTradeResize = os.Getenv("PRE_TRADE_RESIZE") != "false"
+	if val, err := strconv.ParseFloat(os.Getenv("BALANCE_BUFFER_PERCENT"), 64); err == nil && val >= 0 {
+		cfg.BalanceBufferPercent = val
+	} else {
+		cfg.BalanceBufferPercent = 0.5 // Default 0.5% for fees and slippage
+	}
+
+	// Load short selling settings
+	cfg.ShortSelling = os.Getenv("SHORT_SELLING") == "true"
+	if val, err := strconv.ParseFloat(os.Getenv("MAX_SHORT_EXPOSURE"), 64); err == nil && val >= 0 {
+		cfg.MaxShortExposure = val
+	}
+
+	// Load circuit breaker settings
+	if val, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_TIMEOUT_SECONDS")); err == nil && val > 0 {
+		cfg.CircuitBreakerTimeoutSeconds = val
+	} else {
+		cfg.CircuitBreakerTimeoutSeconds = 10 // Default 10 seconds
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_FAILURE_THRESHOLD")); err == nil && val > 0 {
+		cfg.CircuitBreakerFailureThreshold = val
+	} else {
+		cfg.CircuitBreakerFailureThreshold = 5 // Default 5 consecutive failures
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_HALF_OPEN_PROBES")); err == nil && val > 0 {
+		cfg.CircuitBreakerHalfOpenProbes = val
+	} else {
+		cfg.CircuitBreakerHalfOpenProbes = 1 // Default single probe
+	}
+
+	if val, err := strconv.Atoi(os.Getenv("CIRCUIT_BREAKER_SUCCESS_THRESHOLD")); err == nil && val > 0 {
+		cfg.CircuitBreakerSuccessThreshold = val
+	} else {
+		cfg.CircuitBreakerSuccessThreshold = 1 // Default close after one successful probe
+	}
+
+	// Load exchange-side protective order settings
+	cfg.PlaceProtectiveOrders = os.Getenv("PLACE_PROTECTIVE_ORDERS") == "true"
+
+	// Load log buffer size
+	if val, err := strconv.Atoi(os.Getenv("LOG_BUFFER_SIZE")); err == nil && val > 0 {
+		cfg.LogBufferSize = val
+	} else {
+		cfg.LogBufferSize = 1000 // Default to the last 1000 messages
+	}
+
 	return cfg, nil
 }
+
+// SymbolValue looks up a per-symbol value, falling back to the "*" wildcard entry
+func SymbolValue(values map[string]float64, symbol string) (float64, bool) {
+	if val, exists := values[symbol]; exists {
+		return val, true
+	}
+	val, exists := values["*"]
+	return val, exists
+}
+
+// parseFloatMap parses a comma-separated list of KEY:VALUE pairs, skipping malformed entries
+func parseFloatMap(value string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, item := range parseList(value) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if val, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err == nil {
+			values[strings.TrimSpace(parts[0])] = val
+		}
+	}
+	return values
+}
+
+// parsePairs parses "A:B,..." into symbol pairs
+func parsePairs(value string) [][2]string {
+	var pairs [][2]string
+	for _, item := range parseList(value) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		a, b := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if a != "" && b != "" && a != b {
+			pairs = append(pairs, [2]string{a, b})
+		}
+	}
+	return pairs
+}
+
+// parseTriangles parses "A:B:C,..." into triangles of three symbols
+func parseTriangles(value string) [][3]string {
+	var triangles [][3]string
+	for _, item := range parseList(value) {
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			continue
+		}
+		triangle := [3]string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), strings.TrimSpace(parts[2])}
+		if triangle[0] != "" && triangle[1] != "" && triangle[2] != "" {
+			triangles = append(triangles, triangle)
+		}
+	}
+	return triangles
+}
+
+// parseStringMap parses "KEY:value,..." pairs into a map
+func parseStringMap(value string) map[string]string {
+	values := make(map[string]string)
+	for _, item := range parseList(value) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if val := strings.TrimSpace(parts[1]); val != "" {
+			values[strings.TrimSpace(parts[0])] = val
+		}
+	}
+	return values
+}
+
+// parseList parses a comma-separated list, trimming whitespace and dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseNames parses a comma-separated list of case-sensitive names or paths
+func parseNames(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseList(t *testing.T) {
+	if got, want := parseList(" btcusdt, ,ETHUSDT "), []string{"BTCUSDT", "ETHUSDT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseList = %v, want %v", got, want)
+	}
+	if got := parseList(""); len(got) != 0 {
+		t.Errorf("parseList of an empty value = %v, want none", got)
+	}
+}
+
+func TestParseFloatMap(t *testing.T) {
+	caps := parseFloatMap("btcusdt:0.4, *:0.2, bad, ETHUSDT:x")
+	if want := map[string]float64{"BTCUSDT": 0.4, "*": 0.2}; !reflect.DeepEqual(caps, want) {
+		t.Errorf("parseFloatMap = %v, want %v", caps, want)
+	}
+	if val, ok := SymbolValue(caps, "BTCUSDT"); !ok || val != 0.4 {
+		t.Errorf("SymbolValue of a listed symbol = %v, %t, want 0.4, true", val, ok)
+	}
+	if val, ok := SymbolValue(caps, "ETHUSDT"); !ok || val != 0.2 {
+		t.Errorf("SymbolValue wildcard = %v, %t, want 0.2, true", val, ok)
+	}
+	if _, ok := SymbolValue(map[string]float64{"BTCUSDT": 0.4}, "ETHUSDT"); ok {
+		t.Error("SymbolValue found a value for an unlisted symbol without a wildcard")
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	if got, want := parsePairs("BTCUSDT:ETHUSDT,SOLUSDT:SOLUSDT,XRPUSDT"), [][2]string{{"BTCUSDT", "ETHUSDT"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePairs = %v, want %v", got, want)
+	}
+}
+
+func TestParseTriangles(t *testing.T) {
+	if got, want := parseTriangles("BTCUSDT:ETHBTC:ETHUSDT,BTCUSDT:ETHBTC"), [][3]string{{"BTCUSDT", "ETHBTC", "ETHUSDT"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTriangles = %v, want %v", got, want)
+	}
+}
+
+func TestParseNames(t *testing.T) {
+	if got, want := parseNames(" plugins/a.so,,Plugins/B.so"), []string{"plugins/a.so", "Plugins/B.so"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNames = %v, want %v", got, want)
+	}
+}
+
+func TestParseSessions(t *testing.T) {
+	sessions, err := ParseSessions("MON-FRI 08:00-16:30 | SAT+SUN 22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("parsed %d sessions, want 2", len(sessions))
+	}
+	if got := sessions[0].String(); got != "MON+TUE+WED+THU+FRI 08:00-16:30" {
+		t.Errorf("weekday session = %s", got)
+	}
+
+	cases := []struct {
+		name    string
+		session TradingSession
+		at      time.Time
+		want    bool
+	}{
+		{"weekday inside", sessions[0], time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), true},
+		{"weekday end is exclusive", sessions[0], time.Date(2024, 1, 3, 16, 30, 0, 0, time.UTC), false},
+		{"weekday on saturday", sessions[0], time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), false},
+		{"overnight evening", sessions[1], time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC), true},
+		{"overnight morning after sunday", sessions[1], time.Date(2024, 1, 8, 1, 0, 0, 0, time.UTC), true},
+		{"overnight morning after friday", sessions[1], time.Date(2024, 1, 6, 1, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range cases {
+		if got := tc.session.Contains(tc.at); got != tc.want {
+			t.Errorf("%s: Contains(%s) = %t, want %t", tc.name, tc.at, got, tc.want)
+		}
+	}
+
+	for _, invalid := range []string{"MON-FRI 08:00", "XYZ 08:00-09:00", "08:00-08:00", "25:00-26:00", "MON FRI 08:00-09:00"} {
+		if _, err := ParseSessions(invalid); err == nil {
+			t.Errorf("ParseSessions(%q) accepted an invalid session", invalid)
+		}
+	}
+}
+
+func TestLoadConfigDailyLossLimitPercent(t *testing.T) {
+	cases := []struct {
+		value string
+		want  float64
+	}{
+		{"5", 5},
+		{"0", 0},
+		{"150", 0}, // Out of range keeps the default
+	}
+	for _, tc := range cases {
+		t.Setenv("DAILY_LOSS_LIMIT_PERCENT", tc.value)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.DailyLossLimitPercent != tc.want {
+			t.Errorf("DAILY_LOSS_LIMIT_PERCENT=%s loaded as %v, want %v", tc.value, cfg.DailyLossLimitPercent, tc.want)
+		}
+	}
+}
+
+func TestLoadConfigCashReservePercent(t *testing.T) {
+	cases := []struct {
+		value string
+		want  float64
+	}{
+		{"10", 10},
+		{"100", 0}, // Reserving everything keeps the default
+		{"-5", 0},
+	}
+	for _, tc := range cases {
+		t.Setenv("CASH_RESERVE_PERCENT", tc.value)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.CashReservePercent != tc.want {
+			t.Errorf("CASH_RESERVE_PERCENT=%s loaded as %v, want %v", tc.value, cfg.CashReservePercent, tc.want)
+		}
+	}
+}
+
+func TestLoadConfigMaxVaRPercent(t *testing.T) {
+	cases := []struct {
+		value string
+		want  float64
+	}{
+		{"3", 3},
+		{"150", 0}, // Out of range keeps the default
+	}
+	for _, tc := range cases {
+		t.Setenv("MAX_VAR_PERCENT", tc.value)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.MaxVaRPercent != tc.want {
+			t.Errorf("MAX_VAR_PERCENT=%s loaded as %v, want %v", tc.value, cfg.MaxVaRPercent, tc.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekdays maps day abbreviations to weekdays
+var weekdays = map[string]time.Weekday{
+	"SUN": time.Sunday, "MON": time.Monday, "TUE": time.Tuesday, "WED": time.Wednesday,
+	"THU": time.Thursday, "FRI": time.Friday, "SAT": time.Saturday,
+}
+
+// TradingSession is a daily UTC time window on a set of weekdays. A window that ends before it
+// starts runs past midnight and belongs to the day it starts on.
+type TradingSession struct {
+	Days  [7]bool // Indexed by time.Weekday
+	Start int     // Minutes after midnight UTC
+	End   int     // Minutes after midnight UTC, exclusive, up to 1440
+}
+
+// Contains reports whether a time falls within the session
+func (s TradingSession) Contains(t time.Time) bool {
+	t = t.UTC()
+	minute := t.Hour()*60 + t.Minute()
+	if s.Start < s.End {
+		return s.Days[t.Weekday()] && minute >= s.Start && minute < s.End
+	}
+	// Overnight window: the evening part on its own day, the morning part on the next
+	if minute >= s.Start {
+		return s.Days[t.Weekday()]
+	}
+	return minute < s.End && s.Days[(t.Weekday()+6)%7]
+}
+
+// String formats the session like it is configured, e.g. MON-FRI 04:00-24:00
+func (s TradingSession) String() string {
+	days := ""
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if s.Days[day] {
+			if days != "" {
+				days += "+"
+			}
+			days += strings.ToUpper(day.String()[:3])
+		}
+	}
+	return fmt.Sprintf("%s %02d:%02d-%02d:%02d", days, s.Start/60, s.Start%60, s.End/60, s.End%60)
+}
+
+// ParseSessions parses "|"-separated session windows of the form "[DAYS] HH:MM-HH:MM" in UTC,
+// where DAYS is a day or day range like SAT or MON-FRI, joined with "+" (e.g. MON+WED), and
+// defaults to every day
+func ParseSessions(value string) ([]TradingSession, error) {
+	var sessions []TradingSession
+	for _, item := range strings.Split(value, "|") {
+		fields := strings.Fields(strings.ToUpper(item))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid session %q", item)
+		}
+
+		var session TradingSession
+		if len(fields) == 2 {
+			if err := parseDays(fields[0], &session.Days); err != nil {
+				return nil, err
+			}
+		} else {
+			session.Days = [7]bool{true, true, true, true, true, true, true}
+		}
+
+		times := strings.SplitN(fields[len(fields)-1], "-", 2)
+		if len(times) != 2 {
+			return nil, fmt.Errorf("invalid session window %q", item)
+		}
+		var err error
+		if session.Start, err = parseClock(times[0]); err != nil {
+			return nil, err
+		}
+		if session.End, err = parseClock(times[1]); err != nil {
+			return nil, err
+		}
+		if session.Start == session.End {
+			return nil, fmt.Errorf("empty session window %q", item)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// parseDays marks the days of a day list like MON-FRI or SAT+SUN, ranges may wrap the week
+func parseDays(value string, days *[7]bool) error {
+	for _, part := range strings.Split(value, "+") {
+		bounds := strings.SplitN(part, "-", 2)
+		from, ok := weekdays[bounds[0]]
+		if !ok {
+			return fmt.Errorf("invalid session day %q", bounds[0])
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, ok = weekdays[bounds[1]]; !ok {
+				return fmt.Errorf("invalid session day %q", bounds[1])
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM into minutes after midnight, 24:00 being the end of the day
+func parseClock(value string) (int, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid session time %q", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid session time %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 1440 {
+		return 0, fmt.Errorf("invalid session time %q", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// parseSessionMap parses "KEY:SESSIONS,..." entries into sessions per key, skipping malformed
+// entries. Keys are a strategy, a strategy/symbol pair or "*".
+func parseSessionMap(value string) map[string][]TradingSession {
+	sessions := make(map[string][]TradingSession)
+	for key, spec := range parseStringMap(value) {
+		if parsed, err := ParseSessions(spec); err == nil && len(parsed) > 0 {
+			sessions[key] = parsed
+		}
+	}
+	return sessions
+}
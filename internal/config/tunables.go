@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Allocation modes of the portfolio's target allocations
+const (
+	AllocationAdjusted    = "adjusted"    // Average of the performance and volatility adjusted allocations
+	AllocationEqual       = "equal"       // Equal shares of the deployable capital
+	AllocationPerformance = "performance" // Scaled by each symbol's performance
+	AllocationVolatility  = "volatility"  // Scaled inversely to each symbol's volatility
+)
+
+// redactedValue replaces secrets in a redacted configuration
+const redactedValue = "REDACTED"
+
+// tunablesMu guards the tunable fields of configurations, which ApplyTunables changes while the
+// bot runs. Read them with the Get methods, or copy the configuration with Snapshot.
+var tunablesMu sync.RWMutex
+
+// Tunables are the parameters that can be changed while the bot runs. Nil fields are left
+// unchanged when applied.
+type Tunables struct {
+	RebalanceMinutes              *int     `json:"rebalance_minutes,omitempty"`
+	StopLossPercent               *float64 `json:"stop_loss_percent,omitempty"`
+	TakeProfitPercent             *float64 `json:"take_profit_percent,omitempty"`
+	TrailingStopPercent           *float64 `json:"trailing_stop_percent,omitempty"`
+	TrailingStopActivationPercent *float64 `json:"trailing_stop_activation_percent,omitempty"`
+	MaxDrawdown                   *float64 `json:"max_drawdown,omitempty"`
+	AllocationMode                *string  `json:"allocation_mode,omitempty"`
+}
+
+// Tunables returns the current values of the tunable parameters
+func (cfg *Config) Tunables() Tunables {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	rebalanceMinutes := cfg.RebalanceMinutes
+	stopLoss, takeProfit := cfg.StopLossPercent, cfg.TakeProfitPercent
+	trailingStop, trailingActivation := cfg.TrailingStopPercent, cfg.TrailingStopActivationPercent
+	maxDrawdown := cfg.MaxDrawdown
+	allocationMode := cfg.AllocationMode
+	return Tunables{
+		RebalanceMinutes:              &rebalanceMinutes,
+		StopLossPercent:               &stopLoss,
+		TakeProfitPercent:             &takeProfit,
+		TrailingStopPercent:           &trailingStop,
+		TrailingStopActivationPercent: &trailingActivation,
+		MaxDrawdown:                   &maxDrawdown,
+		AllocationMode:                &allocationMode,
+	}
+}
+
+// Validate checks the set tunables, returning every invalid one
+func (t Tunables) Validate() error {
+	var problems []string
+	if t.RebalanceMinutes != nil && (*t.RebalanceMinutes < 1 || *t.RebalanceMinutes > 24*60) {
+		problems = append(problems, "rebalance_minutes must be between 1 and 1440")
+	}
+	if t.StopLossPercent != nil && (*t.StopLossPercent <= 0 || *t.StopLossPercent >= 100) {
+		problems = append(problems, "stop_loss_percent must be above 0 and below 100")
+	}
+	if t.TakeProfitPercent != nil && *t.TakeProfitPercent <= 0 {
+		problems = append(problems, "take_profit_percent must be above 0")
+	}
+	if t.TrailingStopPercent != nil && (*t.TrailingStopPercent < 0 || *t.TrailingStopPercent >= 100) {
+		problems = append(problems, "trailing_stop_percent must be at least 0 and below 100")
+	}
+	if t.TrailingStopActivationPercent != nil && *t.TrailingStopActivationPercent < 0 {
+		problems = append(problems, "trailing_stop_activation_percent must be at least 0")
+	}
+	if t.MaxDrawdown != nil && (*t.MaxDrawdown <= 0 || *t.MaxDrawdown > 1) {
+		problems = append(problems, "max_drawdown must be a fraction above 0 and at most 1")
+	}
+	if t.AllocationMode != nil && !isAllocationMode(*t.AllocationMode) {
+		problems = append(problems, fmt.Sprintf("allocation_mode must be one of %s, %s, %s or %s",
+			AllocationAdjusted, AllocationEqual, AllocationPerformance, AllocationVolatility))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ApplyTunables validates the set tunables and applies them all, or none if any is invalid
+func (cfg *Config) ApplyTunables(t Tunables) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	tunablesMu.Lock()
+	defer tunablesMu.Unlock()
+	if t.RebalanceMinutes != nil {
+		cfg.RebalanceMinutes = *t.RebalanceMinutes
+	}
+	if t.StopLossPercent != nil {
+		cfg.StopLossPercent = *t.StopLossPercent
+	}
+	if t.TakeProfitPercent != nil {
+		cfg.TakeProfitPercent = *t.TakeProfitPercent
+	}
+	if t.TrailingStopPercent != nil {
+		cfg.TrailingStopPercent = *t.TrailingStopPercent
+	}
+	if t.TrailingStopActivationPercent != nil {
+		cfg.TrailingStopActivationPercent = *t.TrailingStopActivationPercent
+	}
+	if t.MaxDrawdown != nil {
+		cfg.MaxDrawdown = *t.MaxDrawdown
+	}
+	if t.AllocationMode != nil {
+		cfg.AllocationMode = *t.AllocationMode
+	}
+	return nil
+}
+
+// GetRebalanceInterval returns the interval between trading cycles
+func (cfg *Config) GetRebalanceInterval() time.Duration {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return time.Duration(cfg.RebalanceMinutes) * time.Minute
+}
+
+// GetStopLossPercent returns the default stop-loss percentage
+func (cfg *Config) GetStopLossPercent() float64 {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.StopLossPercent
+}
+
+// GetTakeProfitPercent returns the default take-profit percentage
+func (cfg *Config) GetTakeProfitPercent() float64 {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.TakeProfitPercent
+}
+
+// GetTrailingStopPercent returns the trailing stop distance, 0 to use the stop-loss percentage
+func (cfg *Config) GetTrailingStopPercent() float64 {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.TrailingStopPercent
+}
+
+// GetTrailingStopActivationPercent returns the gain that activates a trailing stop
+func (cfg *Config) GetTrailingStopActivationPercent() float64 {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.TrailingStopActivationPercent
+}
+
+// GetMaxDrawdown returns the default maximum drawdown as a fraction
+func (cfg *Config) GetMaxDrawdown() float64 {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.MaxDrawdown
+}
+
+// GetAllocationMode returns the allocation mode
+func (cfg *Config) GetAllocationMode() string {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return cfg.AllocationMode
+}
+
+// Snapshot returns a copy of the configuration that later tunable changes do not affect
+func (cfg *Config) Snapshot() Config {
+	tunablesMu.RLock()
+	defer tunablesMu.RUnlock()
+	return *cfg
+}
+
+// Redacted returns a copy of the configuration with the API credentials replaced
+func (cfg *Config) Redacted() Config {
+	redacted := cfg.Snapshot()
+	if redacted.BybitAPIKey != "" {
+		redacted.BybitAPIKey = redactedValue
+	}
+	if redacted.BybitAPISecret != "" {
+		redacted.BybitAPISecret = redactedValue
+	}
+	return redacted
+}
+
+// isAllocationMode reports whether a mode is a known allocation mode
+func isAllocationMode(mode string) bool {
+	switch mode {
+	case AllocationAdjusted, AllocationEqual, AllocationPerformance, AllocationVolatility:
+		return true
+	}
+	return false
+}
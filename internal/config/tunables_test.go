@@ -0,0 +1,81 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+func stringPtr(v string) *string  { return &v }
+
+func TestApplyTunables(t *testing.T) {
+	cfg := &Config{RebalanceMinutes: 5, StopLossPercent: 2, TakeProfitPercent: 5, MaxDrawdown: 0.1, AllocationMode: AllocationAdjusted}
+
+	err := cfg.ApplyTunables(Tunables{RebalanceMinutes: intPtr(15), StopLossPercent: floatPtr(3), AllocationMode: stringPtr(AllocationEqual)})
+	if err != nil {
+		t.Fatalf("valid update rejected: %v", err)
+	}
+	if cfg.GetRebalanceInterval() != 15*time.Minute || cfg.GetStopLossPercent() != 3 || cfg.GetAllocationMode() != AllocationEqual {
+		t.Errorf("update not applied: %+v", cfg.Tunables())
+	}
+	if cfg.GetTakeProfitPercent() != 5 || cfg.GetMaxDrawdown() != 0.1 {
+		t.Errorf("unset tunables changed: %+v", cfg.Tunables())
+	}
+}
+
+func TestApplyTunablesRejectsWholeUpdate(t *testing.T) {
+	cfg := &Config{StopLossPercent: 2, MaxDrawdown: 0.1, AllocationMode: AllocationAdjusted}
+
+	err := cfg.ApplyTunables(Tunables{StopLossPercent: floatPtr(4), MaxDrawdown: floatPtr(5), AllocationMode: stringPtr("random")})
+	if err == nil {
+		t.Fatal("invalid update accepted")
+	}
+	for _, field := range []string{"max_drawdown", "allocation_mode"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q does not name %s", err, field)
+		}
+	}
+	if cfg.GetStopLossPercent() != 2 {
+		t.Errorf("valid field of a rejected update applied: stop-loss %g", cfg.GetStopLossPercent())
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{BybitAPIKey: "key", BybitAPISecret: "secret", TotalCapital: 1000}
+	redacted := cfg.Redacted()
+	if redacted.BybitAPIKey != redactedValue || redacted.BybitAPISecret != redactedValue {
+		t.Errorf("credentials not redacted: %q %q", redacted.BybitAPIKey, redacted.BybitAPISecret)
+	}
+	if cfg.BybitAPISecret != "secret" || redacted.TotalCapital != 1000 {
+		t.Error("redacting changed the configuration or lost values")
+	}
+	if empty := (&Config{}).Redacted(); empty.BybitAPIKey != "" {
+		t.Errorf("missing key shown as %q", empty.BybitAPIKey)
+	}
+}
+
+// Run with -race: tunables change while the bot reads them
+func TestTunablesConcurrentAccess(t *testing.T) {
+	cfg := &Config{RebalanceMinutes: 5, StopLossPercent: 2, MaxDrawdown: 0.1, AllocationMode: AllocationAdjusted}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 100; j++ {
+				cfg.ApplyTunables(Tunables{RebalanceMinutes: intPtr(j), StopLossPercent: floatPtr(float64(j) / 10)})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = cfg.GetRebalanceInterval() + time.Duration(cfg.GetStopLossPercent())
+				_ = cfg.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,57 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Execution is the result of turning a trade signal into an order
+type Execution struct {
+	Symbol    string
+	Side      string // BUY, SELL, SHORT, COVER
+	Type      string // MARKET, LIMIT
+	Quantity  float64
+	Price     float64 // Limit price, or the expected fill price of a market order
+	OrderID   string  // Exchange order ID, empty for spot market orders
+	Paper     bool    // Whether the order was only simulated
+	Timestamp time.Time
+}
+
+// OrderExecutor places the order for a risk-checked trade signal. The signal's action is BUY or
+// SELL for spot orders, SHORT to open and COVER to buy back a linear perpetual short. quantity is
+// the final order size and price the latest market price of the symbol.
+type OrderExecutor interface {
+	ExecuteSignal(ctx context.Context, signal bybit.TradeSignal, quantity, price float64) (Execution, error)
+}
+
+// newExecution builds the order for a signal: a spot limit order at the signal's entry price when
+// it sets a time in force, otherwise a market order expected to fill at the market price. Shorts
+// and covers are always market orders.
+func newExecution(signal bybit.TradeSignal, quantity, price float64) (Execution, error) {
+	switch signal.Action {
+	case "BUY", "SELL", "SHORT", "COVER":
+	default:
+		return Execution{}, fmt.Errorf("signal action %s is not an order", signal.Action)
+	}
+	if quantity <= 0 {
+		return Execution{}, fmt.Errorf("invalid order quantity %.8f for %s", quantity, signal.Symbol)
+	}
+
+	execution := Execution{
+		Symbol:    signal.Symbol,
+		Side:      signal.Action,
+		Type:      "MARKET",
+		Quantity:  quantity,
+		Price:     price,
+		Timestamp: time.Now(),
+	}
+	if signal.TimeInForce != "" && signal.EntryPrice > 0 && (signal.Action == "BUY" || signal.Action == "SELL") {
+		execution.Type = "LIMIT"
+		execution.Price = signal.EntryPrice
+	}
+
+	return execution, nil
+}
@@ -0,0 +1,60 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/risk"
+	"github.com/shopspring/decimal"
+)
+
+// LiveExecutor places signal orders on the exchange through the order circuit breaker
+type LiveExecutor struct {
+	Client          *bybit.Client
+	CircuitBreakers *risk.CircuitBreakerGroup
+}
+
+// NewLiveExecutor creates a new LiveExecutor
+func NewLiveExecutor(client *bybit.Client, circuitBreakers *risk.CircuitBreakerGroup) *LiveExecutor {
+	return &LiveExecutor{
+		Client:          client,
+		CircuitBreakers: circuitBreakers,
+	}
+}
+
+// ExecuteSignal places a spot market order, a limit order with the signal's time in force, or a
+// linear perpetual market order for shorts and covers
+func (le *LiveExecutor) ExecuteSignal(ctx context.Context, signal bybit.TradeSignal, quantity, price float64) (Execution, error) {
+	execution, err := newExecution(signal, quantity, price)
+	if err != nil {
+		return execution, err
+	}
+
+	quantityDecimal := decimal.NewFromFloat(execution.Quantity)
+	err = le.CircuitBreakers.Call(risk.EndpointOrders, func() error {
+		switch execution.Side {
+		case "SHORT":
+			return le.Client.PlaceDerivativeOrder(ctx, execution.Symbol, "SELL", quantityDecimal)
+		case "COVER":
+			return le.Client.ReduceDerivativePosition(ctx, execution.Symbol, "SELL", quantityDecimal)
+		}
+		if execution.Type == "LIMIT" {
+			orderID, err := le.Client.PlaceLimitOrderWithTimeInForce(ctx, execution.Symbol, execution.Side,
+				quantityDecimal, decimal.NewFromFloat(execution.Price), signal.TimeInForce)
+			execution.OrderID = orderID
+			return err
+		}
+		return le.Client.PlaceOrder(ctx, bybit.Order{
+			Symbol:   execution.Symbol,
+			Side:     execution.Side,
+			Type:     execution.Type,
+			Quantity: quantityDecimal,
+		})
+	})
+	if err != nil {
+		return execution, fmt.Errorf("failed to execute %s %s: %w", execution.Side, execution.Symbol, err)
+	}
+
+	return execution, nil
+}
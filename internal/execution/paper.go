@@ -0,0 +1,40 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// PaperExecutor simulates signal orders without sending them to the exchange. Every order is
+// assumed to fill completely at its limit price or the market price.
+type PaperExecutor struct {
+	mutex sync.Mutex
+	Fills []Execution
+}
+
+// NewPaperExecutor creates a new PaperExecutor
+func NewPaperExecutor() *PaperExecutor {
+	return &PaperExecutor{}
+}
+
+// ExecuteSignal records a simulated fill for the signal
+func (pe *PaperExecutor) ExecuteSignal(ctx context.Context, signal bybit.TradeSignal, quantity, price float64) (Execution, error) {
+	execution, err := newExecution(signal, quantity, price)
+	if err != nil {
+		return execution, err
+	}
+
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	execution.Paper = true
+	execution.OrderID = fmt.Sprintf("paper-%d", len(pe.Fills)+1)
+	pe.Fills = append(pe.Fills, execution)
+	log.Printf("  [PAPER] %s %s %.6f %s at %.4f", execution.Type, execution.Side, execution.Quantity, execution.Symbol, execution.Price)
+
+	return execution, nil
+}
@@ -3,8 +3,11 @@ package market
 import (
 	"context"
 	"fmt"
+	"log"
 	"math"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/forbest/bybitgo/internal/bybit"
 )
@@ -16,6 +19,9 @@ type MarketAnalyzer struct {
 	VolumeAnalysis    map[string]*VolumeProfile
 	CorrelationMatrix map[string]map[string]float64
 	PriceHistory      map[string][]float64 // Store price history for correlation calculation
+	// Optional market sentiment source, its score is attached to the enhanced market data
+	Sentiment       SentimentProvider
+	SentimentWeight float64 // Weight of the sentiment component in combined signals, 0 leaves it out
 }
 
 // VolatilityData tracks volatility for a symbol
@@ -140,6 +146,44 @@ func (ma *MarketAnalyzer) AnalyzeMarketConditions(ctx context.Context, symbol st
 	return regime, nil
 }
 
+// WarmUp analyzes the latest klines of each symbol from a data provider, as a trading cycle
+// would, so market regimes and correlations are known before the first cycle. Symbols without
+// recent klines are skipped; the error lists those that could not be fetched.
+func (ma *MarketAnalyzer) WarmUp(ctx context.Context, provider bybit.DataProvider, symbols []string) error {
+	barLength, err := bybit.IntervalDuration(bybit.MarketDataInterval)
+	if err != nil {
+		return err
+	}
+	to := time.Now()
+	from := to.Add(-time.Duration(bybit.MarketDataBars) * barLength)
+
+	var failed []string
+	warmed := 0
+	for _, symbol := range symbols {
+		klines, err := provider.GetKlines(ctx, symbol, bybit.MarketDataInterval, from, to)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", symbol, err))
+			continue
+		}
+		if len(klines) == 0 {
+			continue
+		}
+		data := &bybit.MarketData{Symbol: symbol, Timestamp: klines[len(klines)-1].Timestamp, Kline: klines}
+		if _, err := ma.AnalyzeMarketConditions(ctx, symbol, data); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", symbol, err))
+			continue
+		}
+		warmed++
+	}
+	ma.CalculateCorrelations()
+
+	log.Printf("Market analyzer warmed up on %d of %d symbols", warmed, len(symbols))
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to warm up %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
 // updatePriceHistory updates the price history for a symbol
 func (ma *MarketAnalyzer) updatePriceHistory(symbol string, data *bybit.MarketData) {
 	var prices []float64
@@ -156,6 +200,23 @@ func (ma *MarketAnalyzer) updatePriceHistory(symbol string, data *bybit.MarketDa
 	ma.PriceHistory[symbol] = prices
 }
 
+// GetReturns returns the period-over-period simple returns of a symbol's price history
+func (ma *MarketAnalyzer) GetReturns(symbol string) []float64 {
+	prices := ma.PriceHistory[symbol]
+	if len(prices) < 2 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] != 0 {
+			returns = append(returns, (prices[i]-prices[i-1])/prices[i-1])
+		}
+	}
+
+	return returns
+}
+
 // calculateVolatility calculates volatility metrics for a symbol
 func (ma *MarketAnalyzer) calculateVolatility(data *bybit.MarketData) *VolatilityData {
 	// Simplified volatility calculation based on price range
@@ -429,6 +490,11 @@ func (ma *MarketAnalyzer) CalculateCorrelations() map[string]map[string]float64
 	return ma.CorrelationMatrix
 }
 
+// GetCorrelation returns the price correlation of two symbols over their common price history
+func (ma *MarketAnalyzer) GetCorrelation(symbol1, symbol2 string) float64 {
+	return ma.calculateCorrelation(symbol1, symbol2)
+}
+
 // calculateCorrelation calculates the correlation between two symbols
 func (ma *MarketAnalyzer) calculateCorrelation(symbol1, symbol2 string) float64 {
 	prices1, ok1 := ma.PriceHistory[symbol1]
@@ -743,6 +809,7 @@ type EnhancedMarketData struct {
 	MACD          *MACDResult
 	StochasticRSI *StochasticRSIResult
 	VWAP          *VWAPResult
+	Sentiment     *Sentiment // Nil without a sentiment provider or if it failed
 }
 
 // AnalyzeEnhancedMarketConditions analyzes market data with additional indicators
@@ -766,6 +833,16 @@ func (ma *MarketAnalyzer) AnalyzeEnhancedMarketConditions(ctx context.Context, s
 		VWAP:          vwap,
 	}
 
+	// A failing sentiment source does not stop the analysis, the data is left without sentiment
+	if ma.Sentiment != nil {
+		sentiment, err := ma.Sentiment.Sentiment(ctx, symbol)
+		if err != nil {
+			log.Printf("Warning: Failed to get sentiment for %s: %v", symbol, err)
+		} else {
+			enhancedData.Sentiment = sentiment
+		}
+	}
+
 	return enhancedData, nil
 }
 
@@ -823,7 +900,16 @@ func (ma *MarketAnalyzer) CalculateCombinedSignal(symbol string, enhancedData *E
 	// Calculate weighted average score
 	// Equal weights for now (0.33 each)
 	totalWeight := 0.33 + 0.33 + 0.33
-	weightedScore := (macdScore*0.33 + rsiScore*0.33 + vwapScore*0.33) / totalWeight
+	weightedSum := macdScore*0.33 + rsiScore*0.33 + vwapScore*0.33
+
+	// Sentiment score (-1 to 1 mapped to 0-1), an optional component with its own weight
+	if enhancedData.Sentiment != nil && ma.SentimentWeight > 0 {
+		sentimentScore := (enhancedData.Sentiment.Score + 1) / 2
+		components["Sentiment"] = sentimentScore
+		weightedSum += sentimentScore * ma.SentimentWeight
+		totalWeight += ma.SentimentWeight
+	}
+	weightedScore := weightedSum / totalWeight
 
 	// Calculate confidence based on agreement between indicators
 	agreement := 0.0
@@ -0,0 +1,234 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fearGreedURL is the public crypto fear & greed index API
+const fearGreedURL = "https://api.alternative.me/fng/?limit=1"
+
+// Sentiment is a market sentiment reading for a symbol
+type Sentiment struct {
+	Score     float64   `json:"score"` // -1 (extreme fear, bearish) to 1 (extreme greed, bullish)
+	Label     string    `json:"label"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SentimentProvider supplies the market sentiment of a symbol, e.g. from a fear & greed index
+// or a news sentiment API. Market-wide sources return the same reading for every symbol.
+type SentimentProvider interface {
+	Sentiment(ctx context.Context, symbol string) (*Sentiment, error)
+}
+
+// SentimentSettings configures a sentiment provider
+type SentimentSettings struct {
+	URL      string             // Endpoint of the provider, "{symbol}" is replaced by the symbol
+	Scores   map[string]float64 // Fixed scores per symbol for the static provider, "*" for all others
+	CacheTTL time.Duration      // How long a reading is reused before it is fetched again
+}
+
+// SentimentFactory creates a sentiment provider from its settings
+type SentimentFactory func(settings SentimentSettings) (SentimentProvider, error)
+
+var (
+	sentimentMutex     sync.RWMutex
+	sentimentProviders = make(map[string]SentimentFactory)
+)
+
+func init() {
+	RegisterSentimentProvider("fear_greed", func(settings SentimentSettings) (SentimentProvider, error) {
+		url := settings.URL
+		if url == "" {
+			url = fearGreedURL
+		}
+		return newCachedSentiment(&fearGreedSource{url: url}, settings.CacheTTL), nil
+	})
+	RegisterSentimentProvider("http", func(settings SentimentSettings) (SentimentProvider, error) {
+		if settings.URL == "" {
+			return nil, fmt.Errorf("the http sentiment provider needs a URL")
+		}
+		return newCachedSentiment(&httpSentimentSource{url: settings.URL}, settings.CacheTTL), nil
+	})
+	RegisterSentimentProvider("static", func(settings SentimentSettings) (SentimentProvider, error) {
+		return &StaticSentimentProvider{Scores: settings.Scores}, nil
+	})
+}
+
+// RegisterSentimentProvider makes a sentiment provider available by name. Third-party providers
+// call it from an init function. It panics if the name is empty, the factory is nil or the name
+// is already registered.
+func RegisterSentimentProvider(name string, factory SentimentFactory) {
+	sentimentMutex.Lock()
+	defer sentimentMutex.Unlock()
+
+	if name == "" || factory == nil {
+		panic("market: RegisterSentimentProvider needs a name and a factory")
+	}
+	if _, exists := sentimentProviders[name]; exists {
+		panic(fmt.Sprintf("market: RegisterSentimentProvider called twice for %s", name))
+	}
+	sentimentProviders[name] = factory
+}
+
+// SentimentProviders returns the names of all registered sentiment providers, sorted
+func SentimentProviders() []string {
+	sentimentMutex.RLock()
+	defer sentimentMutex.RUnlock()
+
+	names := make([]string, 0, len(sentimentProviders))
+	for name := range sentimentProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSentimentProvider creates the registered sentiment provider of a name
+func NewSentimentProvider(name string, settings SentimentSettings) (SentimentProvider, error) {
+	sentimentMutex.RLock()
+	factory, exists := sentimentProviders[name]
+	sentimentMutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown sentiment provider %s (registered: %s)", name, strings.Join(SentimentProviders(), ", "))
+	}
+	return factory(settings)
+}
+
+// StaticSentimentProvider returns fixed scores, e.g. to hold a manual market view
+type StaticSentimentProvider struct {
+	Scores map[string]float64 // Score per symbol, "*" applies to all other symbols
+}
+
+// Sentiment returns the fixed score of a symbol
+func (sp *StaticSentimentProvider) Sentiment(ctx context.Context, symbol string) (*Sentiment, error) {
+	score, exists := sp.Scores[symbol]
+	if !exists {
+		if score, exists = sp.Scores["*"]; !exists {
+			return nil, fmt.Errorf("no static sentiment score for %s", symbol)
+		}
+	}
+	return &Sentiment{Score: clampSentiment(score), Label: "static", Source: "static", UpdatedAt: time.Now()}, nil
+}
+
+// cachedSentiment reuses the readings of a source for the cache TTL, so a slow or rate-limited
+// API is not queried every trading cycle
+type cachedSentiment struct {
+	mutex  sync.Mutex
+	source SentimentProvider
+	ttl    time.Duration
+	cache  map[string]*Sentiment
+}
+
+// newCachedSentiment wraps a sentiment source with a cache
+func newCachedSentiment(source SentimentProvider, ttl time.Duration) *cachedSentiment {
+	return &cachedSentiment{source: source, ttl: ttl, cache: make(map[string]*Sentiment)}
+}
+
+// Sentiment returns the cached reading of a symbol, fetching it again once it expired
+func (cs *cachedSentiment) Sentiment(ctx context.Context, symbol string) (*Sentiment, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if cached, exists := cs.cache[symbol]; exists && time.Since(cached.UpdatedAt) < cs.ttl {
+		return cached, nil
+	}
+	sentiment, err := cs.source.Sentiment(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	cs.cache[symbol] = sentiment
+	return sentiment, nil
+}
+
+// fearGreedSource reads the market-wide crypto fear & greed index (0 extreme fear to 100
+// extreme greed)
+type fearGreedSource struct {
+	url string
+}
+
+// Sentiment returns the current index value scaled to [-1, 1]
+func (fs *fearGreedSource) Sentiment(ctx context.Context, symbol string) (*Sentiment, error) {
+	var response struct {
+		Data []struct {
+			Value          string `json:"value"`
+			Classification string `json:"value_classification"`
+		} `json:"data"`
+	}
+	if err := getSentimentJSON(ctx, fs.url, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("fear & greed index returned no data")
+	}
+
+	value, err := strconv.ParseFloat(response.Data[0].Value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fear & greed index value %q: %w", response.Data[0].Value, err)
+	}
+	return &Sentiment{
+		Score:     clampSentiment((value - 50) / 50),
+		Label:     response.Data[0].Classification,
+		Source:    "fear_greed",
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// httpSentimentSource reads per-symbol scores from an API returning {"score": -1..1, "label": "..."}
+type httpSentimentSource struct {
+	url string
+}
+
+// Sentiment fetches the score of a symbol
+func (hs *httpSentimentSource) Sentiment(ctx context.Context, symbol string) (*Sentiment, error) {
+	var response struct {
+		Score *float64 `json:"score"`
+		Label string   `json:"label"`
+	}
+	if err := getSentimentJSON(ctx, strings.ReplaceAll(hs.url, "{symbol}", symbol), &response); err != nil {
+		return nil, err
+	}
+	if response.Score == nil {
+		return nil, fmt.Errorf("sentiment API returned no score for %s", symbol)
+	}
+	return &Sentiment{Score: clampSentiment(*response.Score), Label: response.Label, Source: "http", UpdatedAt: time.Now()}, nil
+}
+
+// getSentimentJSON fetches a URL and decodes its JSON body
+func getSentimentJSON(ctx context.Context, url string, target interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create sentiment request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sentiment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sentiment API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode sentiment: %w", err)
+	}
+	return nil
+}
+
+// clampSentiment limits a score to [-1, 1]
+func clampSentiment(score float64) float64 {
+	return math.Max(-1, math.Min(1, score))
+}
@@ -0,0 +1,172 @@
+package market
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Indicator settings of chart series, the same as the analyzer's
+const (
+	macdFastPeriod   = 12
+	macdSlowPeriod   = 26
+	macdSignalPeriod = 9
+	bollingerPeriod  = 20
+	bollingerStdDevs = 2.0
+	vwapBandStdDevs  = 2.0
+)
+
+// ChartWarmUpKlines is the number of klines before every chart series has a value
+const ChartWarmUpKlines = macdSlowPeriod + macdSignalPeriod - 1
+
+// Series is an indicator value per kline, NaN while the indicator warms up
+type Series []float64
+
+// MarshalJSON encodes the series with null for NaN values
+func (s Series) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, value := range s {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			buf.WriteString("null")
+		} else {
+			buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// ChartIndicators are indicator series of klines for charts, aligned with the klines
+type ChartIndicators struct {
+	MACD            Series `json:"macd"`
+	MACDSignal      Series `json:"macd_signal"`
+	MACDHistogram   Series `json:"macd_histogram"`
+	BollingerMiddle Series `json:"bollinger_middle"`
+	BollingerUpper  Series `json:"bollinger_upper"`
+	BollingerLower  Series `json:"bollinger_lower"`
+	// Anchored at the first charted kline, with bands of the volume-weighted deviation of the
+	// typical price
+	VWAP      Series `json:"vwap"`
+	VWAPUpper Series `json:"vwap_upper"`
+	VWAPLower Series `json:"vwap_lower"`
+}
+
+// CalculateChartIndicators returns the MACD (12, 26, 9), Bollinger Band (20, 2) and VWAP band
+// series of klines, oldest first, charted from the kline at an index on. The klines before it
+// only warm up the moving averages.
+func CalculateChartIndicators(klines []bybit.KlineData, from int) ChartIndicators {
+	n := len(klines)
+	closes := make([]float64, n)
+	for i, kline := range klines {
+		closes[i], _ = kline.Close.Float64()
+	}
+
+	indicators := ChartIndicators{
+		MACD:            nanSeries(n),
+		MACDHistogram:   nanSeries(n),
+		BollingerMiddle: nanSeries(n),
+		BollingerUpper:  nanSeries(n),
+		BollingerLower:  nanSeries(n),
+		VWAP:            nanSeries(n),
+		VWAPUpper:       nanSeries(n),
+		VWAPLower:       nanSeries(n),
+	}
+
+	fast, slow := emaSeries(closes, macdFastPeriod), emaSeries(closes, macdSlowPeriod)
+	for i := range closes {
+		indicators.MACD[i] = fast[i] - slow[i] // NaN until both EMAs have values
+	}
+	indicators.MACDSignal = emaSeries(indicators.MACD, macdSignalPeriod)
+	for i := range closes {
+		indicators.MACDHistogram[i] = indicators.MACD[i] - indicators.MACDSignal[i]
+	}
+
+	for i := bollingerPeriod - 1; i < n; i++ {
+		window := closes[i-bollingerPeriod+1 : i+1]
+		mean := 0.0
+		for _, c := range window {
+			mean += c
+		}
+		mean /= bollingerPeriod
+		variance := 0.0
+		for _, c := range window {
+			variance += (c - mean) * (c - mean)
+		}
+		stdDev := math.Sqrt(variance / bollingerPeriod)
+		indicators.BollingerMiddle[i] = mean
+		indicators.BollingerUpper[i] = mean + bollingerStdDevs*stdDev
+		indicators.BollingerLower[i] = mean - bollingerStdDevs*stdDev
+	}
+
+	// Running sums of volume, price times volume and squared price times volume give the VWAP
+	// and the variance of the typical price around it
+	volumeSum, priceVolumeSum, squareVolumeSum := 0.0, 0.0, 0.0
+	for i := from; i < n; i++ {
+		kline := klines[i]
+		high, _ := kline.High.Float64()
+		low, _ := kline.Low.Float64()
+		volume, _ := kline.Volume.Float64()
+		typicalPrice := (high + low + closes[i]) / 3
+		volumeSum += volume
+		priceVolumeSum += typicalPrice * volume
+		squareVolumeSum += typicalPrice * typicalPrice * volume
+		if volumeSum == 0 {
+			continue
+		}
+		vwap := priceVolumeSum / volumeSum
+		stdDev := math.Sqrt(math.Max(squareVolumeSum/volumeSum-vwap*vwap, 0))
+		indicators.VWAP[i] = vwap
+		indicators.VWAPUpper[i] = vwap + vwapBandStdDevs*stdDev
+		indicators.VWAPLower[i] = vwap - vwapBandStdDevs*stdDev
+	}
+
+	return ChartIndicators{
+		MACD:            indicators.MACD[from:],
+		MACDSignal:      indicators.MACDSignal[from:],
+		MACDHistogram:   indicators.MACDHistogram[from:],
+		BollingerMiddle: indicators.BollingerMiddle[from:],
+		BollingerUpper:  indicators.BollingerUpper[from:],
+		BollingerLower:  indicators.BollingerLower[from:],
+		VWAP:            indicators.VWAP[from:],
+		VWAPUpper:       indicators.VWAPUpper[from:],
+		VWAPLower:       indicators.VWAPLower[from:],
+	}
+}
+
+// nanSeries returns a series of n NaN values
+func nanSeries(n int) Series {
+	series := make(Series, n)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// emaSeries returns the exponential moving average of values, seeded with the simple average of
+// the first period values that are not NaN
+func emaSeries(values []float64, period int) Series {
+	series := nanSeries(len(values))
+	multiplier := 2.0 / float64(period+1)
+	seen, sum := 0, 0.0
+	for i, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		seen++
+		switch {
+		case seen < period:
+			sum += value
+		case seen == period:
+			series[i] = (sum + value) / float64(period)
+		default:
+			series[i] = (value-series[i-1])*multiplier + series[i-1]
+		}
+	}
+	return series
+}
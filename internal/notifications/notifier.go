@@ -41,6 +41,15 @@ type TradeAlert struct {
 	Timestamp  string
 }
 
+// RiskAlert represents a risk incident such as a triggered stop or a breached limit
+type RiskAlert struct {
+	Type      string
+	Severity  string
+	Subject   string
+	Message   string
+	Timestamp string
+}
+
 // NewNotifier creates a new Notifier
 func NewNotifier() *Notifier {
 	// Load email configuration from environment variables
@@ -172,3 +181,57 @@ func (n *Notifier) SendEmergencyStopAlert(reason string) error {
 
 	return nil
 }
+
+// SendRiskAlert sends a risk incident alert via email and/or Telegram
+func (n *Notifier) SendRiskAlert(alert RiskAlert) error {
+	// Send email alert if configured
+	if n.EmailConfig.SenderEmail != "" && n.EmailConfig.ReceiverEmail != "" {
+		subject := fmt.Sprintf("⚠️ Risk Alert: %s %s", alert.Type, alert.Subject)
+		body := fmt.Sprintf("[%s] %s\n%s", alert.Severity, alert.Message, alert.Timestamp)
+		message := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s",
+			n.EmailConfig.ReceiverEmail, subject, body)
+
+		auth := smtp.PlainAuth("", n.EmailConfig.SenderEmail, n.EmailConfig.SenderPass, n.EmailConfig.SMTPHost)
+		addr := n.EmailConfig.SMTPHost + ":" + n.EmailConfig.SMTPPort
+
+		err := smtp.SendMail(addr, auth, n.EmailConfig.SenderEmail, []string{n.EmailConfig.ReceiverEmail}, []byte(message))
+		if err != nil {
+			log.Printf("Warning: Failed to send risk alert email: %v", err)
+		}
+	}
+
+	// Send Telegram alert if configured
+	if n.TelegramConfig.BotToken != "" && n.TelegramConfig.ChatID != "" {
+		message := fmt.Sprintf("⚠️ *Risk Alert: %s*\nSeverity: %s\nSubject: %s\n%s\n%s",
+			alert.Type, alert.Severity, alert.Subject, alert.Message, alert.Timestamp)
+		log.Printf("Risk alert Telegram message prepared: %s", strings.ReplaceAll(message, "\n", " | "))
+	}
+
+	return nil
+}
+
+// SendDailySummary sends the daily performance and risk summary
+func (n *Notifier) SendDailySummary(date, summary string) error {
+	// Send email summary if configured
+	if n.EmailConfig.SenderEmail != "" && n.EmailConfig.ReceiverEmail != "" {
+		subject := fmt.Sprintf("📊 Daily Summary %s", date)
+		message := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s",
+			n.EmailConfig.ReceiverEmail, subject, summary)
+
+		auth := smtp.PlainAuth("", n.EmailConfig.SenderEmail, n.EmailConfig.SenderPass, n.EmailConfig.SMTPHost)
+		addr := n.EmailConfig.SMTPHost + ":" + n.EmailConfig.SMTPPort
+
+		err := smtp.SendMail(addr, auth, n.EmailConfig.SenderEmail, []string{n.EmailConfig.ReceiverEmail}, []byte(message))
+		if err != nil {
+			log.Printf("Warning: Failed to send daily summary email: %v", err)
+		}
+	}
+
+	// Send Telegram summary if configured
+	if n.TelegramConfig.BotToken != "" && n.TelegramConfig.ChatID != "" {
+		message := fmt.Sprintf("📊 *Daily Summary %s*\n%s", date, summary)
+		log.Printf("Daily summary Telegram message prepared: %s", strings.ReplaceAll(message, "\n", " | "))
+	}
+
+	return nil
+}
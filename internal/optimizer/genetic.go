@@ -0,0 +1,358 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// ParameterBounds is the search interval of a parameter. A positive step restricts the
+// parameter to multiples of the step above the minimum, e.g. 1 for periods.
+type ParameterBounds struct {
+	Min  float64
+	Max  float64
+	Step float64
+}
+
+// ParameterSpace maps parameter names to their search intervals
+type ParameterSpace map[string]ParameterBounds
+
+// GenerationFunc is notified of the best parameter set after each generation
+type GenerationFunc func(symbol string, generation int, best Result)
+
+// GeneticSearch evolves a population of parameter sets with tournament selection, uniform
+// crossover and gaussian mutation. It suits parameter spaces too large for a grid search: each
+// generation is backtested concurrently and the search stops early once the best score has not
+// improved for Patience generations.
+type GeneticSearch struct {
+	StrategyType   strategy.StrategyType
+	Space          ParameterSpace
+	Objective      ObjectiveFunc
+	InitialCapital float64
+	Backtest       BacktestFunc
+	PopulationSize int
+	Generations    int     // Maximum number of generations
+	EliteCount     int     // Best parameter sets carried over unchanged
+	MutationRate   float64 // Probability of mutating each parameter
+	MutationScale  float64 // Standard deviation of a mutation as a fraction of the interval
+	Patience       int     // Generations without improvement before stopping
+	Tolerance      float64 // Minimum score gain counted as an improvement
+	Workers        int     // Concurrent backtests
+	Seed           int64
+	OnGeneration   GenerationFunc
+	OutOfSample    OutOfSample
+}
+
+// individual is a parameter set with its score
+type individual struct {
+	params map[string]float64
+	score  float64
+	result *Result // nil for parameter sets the strategy rejected
+}
+
+// NewGeneticSearch creates a new GeneticSearch with default evolution settings. An empty space
+// searches every parameter of the strategy over its full valid range.
+func NewGeneticSearch(strategyType strategy.StrategyType, space ParameterSpace, objective ObjectiveFunc, initialCapital float64) (*GeneticSearch, error) {
+	if _, err := strategy.NewStrategy(strategyType); err != nil {
+		return nil, err
+	}
+	if len(space) == 0 {
+		space = DefaultSpace(strategyType)
+	}
+	if len(space) == 0 {
+		return nil, fmt.Errorf("no parameters to search for strategy %s", strategyType)
+	}
+	for name, bounds := range space {
+		if bounds.Max < bounds.Min || bounds.Step < 0 {
+			return nil, fmt.Errorf("invalid bounds for %s", name)
+		}
+	}
+
+	return &GeneticSearch{
+		StrategyType:   strategyType,
+		Space:          space,
+		Objective:      objective,
+		InitialCapital: initialCapital,
+		Backtest:       runBacktest,
+		PopulationSize: 30,
+		Generations:    40,
+		EliteCount:     2,
+		MutationRate:   0.2,
+		MutationScale:  0.1,
+		Patience:       8,
+		Tolerance:      1e-6,
+		Workers:        runtime.NumCPU(),
+		Seed:           time.Now().UnixNano(),
+	}, nil
+}
+
+// DefaultSpace returns the valid parameter ranges of a strategy as a search space. Parameters
+// with integer defaults are searched in whole steps.
+func DefaultSpace(strategyType strategy.StrategyType) ParameterSpace {
+	defaults := map[string]float64{}
+	if strat, err := strategy.NewStrategy(strategyType); err == nil {
+		defaults = strat.GetParameters()
+	}
+
+	space := make(ParameterSpace)
+	for name, r := range strategy.ParameterRanges(strategyType) {
+		bounds := ParameterBounds{Min: r.Min, Max: r.Max}
+		if value, exists := defaults[name]; exists && value == math.Trunc(value) && r.Min == math.Trunc(r.Min) && r.Max-r.Min >= 2 {
+			bounds.Step = 1
+		}
+		space[name] = bounds
+	}
+	return space
+}
+
+// Run evolves parameter sets on each symbol's klines and returns every valid parameter set
+// evaluated per symbol, best first. With an out-of-sample share the sets evolve on the klines
+// before it and are then validated on it.
+func (gs *GeneticSearch) Run(data map[string][]bybit.KlineData) (map[string][]Result, error) {
+	if gs.PopulationSize < 2 {
+		return nil, fmt.Errorf("population size must be at least 2")
+	}
+
+	symbols := make([]string, 0, len(data))
+	for symbol := range data {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	results := make(map[string][]Result)
+	for _, symbol := range symbols {
+		klines := data[symbol]
+		if len(klines) == 0 {
+			continue
+		}
+		searched, splitDate := gs.OutOfSample.split(klines)
+		results[symbol] = gs.evolve(symbol, searched)
+		gs.OutOfSample.validate(gs.StrategyType, gs.Backtest, gs.Objective, gs.InitialCapital, symbol, klines, splitDate, results[symbol], gs.Workers)
+	}
+
+	return results, nil
+}
+
+// evolve runs the genetic search for one symbol
+func (gs *GeneticSearch) evolve(symbol string, klines []bybit.KlineData) []Result {
+	rng := rand.New(rand.NewSource(gs.Seed))
+	names := gs.names()
+	evaluated := make(map[string]*individual) // Cache by parameter key, each set is backtested once
+
+	population := make([]*individual, 0, gs.PopulationSize)
+	for len(population) < gs.PopulationSize {
+		population = append(population, &individual{params: gs.randomParams(rng, names)})
+	}
+
+	bestScore := math.Inf(-1)
+	stale := 0
+	for generation := 1; generation <= gs.Generations; generation++ {
+		gs.evaluate(symbol, klines, population, evaluated)
+		sort.SliceStable(population, func(i, j int) bool { return population[i].score > population[j].score })
+
+		best := population[0]
+		if best.result != nil && gs.OnGeneration != nil {
+			gs.OnGeneration(symbol, generation, *best.result)
+		}
+
+		// Stop once the best score has converged
+		if best.score > bestScore+gs.Tolerance {
+			bestScore = best.score
+			stale = 0
+		} else if stale++; stale >= gs.Patience {
+			break
+		}
+		if generation == gs.Generations {
+			break
+		}
+
+		// Breed the next generation, keeping the elite unchanged
+		next := make([]*individual, 0, gs.PopulationSize)
+		for i := 0; i < gs.EliteCount && i < len(population); i++ {
+			next = append(next, population[i])
+		}
+		for len(next) < gs.PopulationSize {
+			parentA := gs.tournament(rng, population)
+			parentB := gs.tournament(rng, population)
+			next = append(next, &individual{params: gs.mutate(rng, names, gs.crossover(rng, names, parentA, parentB))})
+		}
+		population = next
+	}
+
+	ranked := make([]Result, 0, len(evaluated))
+	for _, ind := range evaluated {
+		if ind.result != nil {
+			ranked = append(ranked, *ind.result)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked
+}
+
+// evaluate backtests the individuals that have not been evaluated yet on a pool of workers
+func (gs *GeneticSearch) evaluate(symbol string, klines []bybit.KlineData, population []*individual, evaluated map[string]*individual) {
+	var pending []*individual
+	queued := make(map[string]bool)
+	for i, ind := range population {
+		key := paramsKey(ind.params)
+		if cached, exists := evaluated[key]; exists {
+			population[i] = cached
+			continue
+		}
+		if !queued[key] {
+			queued[key] = true
+			pending = append(pending, ind)
+		}
+	}
+
+	startDate, endDate := klines[0].Timestamp, klines[len(klines)-1].Timestamp
+	backtest.Parallel(len(pending), gs.Workers, func(i int) {
+		ind := pending[i]
+		ind.score = math.Inf(-1)
+		strat, err := strategy.NewStrategy(gs.StrategyType)
+		if err != nil || strat.SetParameters(ind.params) != nil {
+			return
+		}
+		backtestResult := gs.Backtest(strat, map[string][]bybit.KlineData{symbol: klines}, gs.InitialCapital, startDate, endDate)
+		if backtestResult == nil {
+			return
+		}
+		ind.score = gs.Objective(backtestResult)
+		if math.IsNaN(ind.score) {
+			ind.score = math.Inf(-1)
+		}
+		result := newResult(symbol, ind.params, backtestResult, ind.score)
+		ind.result = &result
+	})
+
+	for _, ind := range pending {
+		evaluated[paramsKey(ind.params)] = ind
+	}
+	for i, ind := range population {
+		population[i] = evaluated[paramsKey(ind.params)]
+	}
+}
+
+// tournament picks the best of three random individuals
+func (gs *GeneticSearch) tournament(rng *rand.Rand, population []*individual) *individual {
+	best := population[rng.Intn(len(population))]
+	for i := 1; i < 3; i++ {
+		if candidate := population[rng.Intn(len(population))]; candidate.score > best.score {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// crossover takes each parameter from either parent with equal probability
+func (gs *GeneticSearch) crossover(rng *rand.Rand, names []string, a, b *individual) map[string]float64 {
+	child := make(map[string]float64, len(names))
+	for _, name := range names {
+		if rng.Float64() < 0.5 {
+			child[name] = a.params[name]
+		} else {
+			child[name] = b.params[name]
+		}
+	}
+	return child
+}
+
+// mutate shifts parameters by a gaussian step proportional to their interval
+func (gs *GeneticSearch) mutate(rng *rand.Rand, names []string, params map[string]float64) map[string]float64 {
+	for _, name := range names {
+		if rng.Float64() >= gs.MutationRate {
+			continue
+		}
+		bounds := gs.Space[name]
+		params[name] = bounds.snap(params[name] + rng.NormFloat64()*gs.MutationScale*(bounds.Max-bounds.Min))
+	}
+	return params
+}
+
+// randomParams draws every parameter uniformly from its interval
+func (gs *GeneticSearch) randomParams(rng *rand.Rand, names []string) map[string]float64 {
+	params := make(map[string]float64, len(names))
+	for _, name := range names {
+		bounds := gs.Space[name]
+		params[name] = bounds.snap(bounds.Min + rng.Float64()*(bounds.Max-bounds.Min))
+	}
+	return params
+}
+
+// names returns the searched parameter names in a fixed order so runs with the same seed repeat
+func (gs *GeneticSearch) names() []string {
+	names := make([]string, 0, len(gs.Space))
+	for name := range gs.Space {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// snap clamps a value to the interval and rounds it to the step
+func (b ParameterBounds) snap(value float64) float64 {
+	if b.Step > 0 {
+		value = b.Min + math.Round((value-b.Min)/b.Step)*b.Step
+	}
+	value = math.Max(b.Min, math.Min(b.Max, value))
+	return math.Round(value*1e9) / 1e9
+}
+
+// paramsKey identifies a parameter set
+func paramsKey(params map[string]float64) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+strconv.FormatFloat(params[name], 'g', -1, 64))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseSpace parses a search space like "rsi_period=5:30:1;bollinger_std=1:3", where each
+// parameter has a min:max interval and an optional step
+func ParseSpace(value string) (ParameterSpace, error) {
+	space := make(ParameterSpace)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid space entry %q", item)
+		}
+		name := strings.TrimSpace(parts[0])
+
+		bounds := strings.Split(parts[1], ":")
+		if len(bounds) != 2 && len(bounds) != 3 {
+			return nil, fmt.Errorf("invalid interval for %s, use min:max or min:max:step", name)
+		}
+		var numbers [3]float64
+		for i, bound := range bounds {
+			number, err := strconv.ParseFloat(strings.TrimSpace(bound), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid interval for %s: %w", name, err)
+			}
+			numbers[i] = number
+		}
+		if numbers[1] < numbers[0] || numbers[2] < 0 {
+			return nil, fmt.Errorf("invalid interval for %s, min must not exceed max", name)
+		}
+		space[name] = ParameterBounds{Min: numbers[0], Max: numbers[1], Step: numbers[2]}
+	}
+	return space, nil
+}
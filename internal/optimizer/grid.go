@@ -0,0 +1,270 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// Objectives parameter sets are ranked by
+const (
+	ObjectiveSharpe = "sharpe"
+	ObjectiveCalmar = "calmar"
+	ObjectiveNetPnL = "pnl"
+)
+
+// ParameterGrid maps parameter names to the values tried for them
+type ParameterGrid = backtest.ParameterGrid
+
+// Result is the outcome of backtesting one parameter set on one symbol
+type Result struct {
+	Symbol      string             `json:"symbol"`
+	Parameters  map[string]float64 `json:"parameters"`
+	Score       float64            `json:"score"` // Value of the objective, higher is better
+	NetPnL      float64            `json:"net_pnl"`
+	TotalReturn float64            `json:"total_return"`
+	SharpeRatio float64            `json:"sharpe_ratio"`
+	CalmarRatio float64            `json:"calmar_ratio"`
+	MaxDrawdown float64            `json:"max_drawdown"`
+	TotalTrades int                `json:"total_trades"`
+	// Backtest on the klines held out of the search, nil when none were
+	OutOfSample *OutOfSampleResult `json:"out_of_sample,omitempty"`
+}
+
+// ObjectiveFunc scores a backtest result, higher is better
+type ObjectiveFunc func(result *backtest.BacktestResult) float64
+
+// BacktestFunc runs a backtest of a strategy on historical data
+type BacktestFunc func(strat strategy.Strategy, data map[string][]bybit.KlineData, initialCapital float64, startDate, endDate time.Time) *backtest.BacktestResult
+
+// GridSearch backtests every combination of a parameter grid on each symbol and ranks the
+// parameter sets by an objective
+type GridSearch struct {
+	StrategyType   strategy.StrategyType
+	Grid           ParameterGrid
+	Objective      ObjectiveFunc
+	InitialCapital float64
+	Backtest       BacktestFunc
+	OutOfSample    OutOfSample
+	Workers        int // Concurrent backtests
+}
+
+// NewGridSearch creates a new GridSearch for a strategy, using the backtest package to evaluate parameter sets
+func NewGridSearch(strategyType strategy.StrategyType, grid ParameterGrid, objective string, initialCapital float64) (*GridSearch, error) {
+	if _, err := strategy.NewStrategy(strategyType); err != nil {
+		return nil, err
+	}
+	objectiveFunc, err := ObjectiveFor(objective)
+	if err != nil {
+		return nil, err
+	}
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("parameter grid is empty")
+	}
+
+	return &GridSearch{
+		StrategyType:   strategyType,
+		Grid:           grid,
+		Objective:      objectiveFunc,
+		InitialCapital: initialCapital,
+		Backtest:       runBacktest,
+		Workers:        runtime.NumCPU(),
+	}, nil
+}
+
+// runBacktest evaluates a strategy with the backtest package at the default costs
+func runBacktest(strat strategy.Strategy, data map[string][]bybit.KlineData, initialCapital float64, startDate, endDate time.Time) *backtest.BacktestResult {
+	return backtest.NewBacktester(strat, data).Run(initialCapital, startDate, endDate)
+}
+
+// BacktestWith returns a BacktestFunc evaluating strategies with the backtest package, with the
+// backtester's settings like costs changed by configure
+func BacktestWith(configure func(backtester *backtest.Backtester)) BacktestFunc {
+	return func(strat strategy.Strategy, data map[string][]bybit.KlineData, initialCapital float64, startDate, endDate time.Time) *backtest.BacktestResult {
+		backtester := backtest.NewBacktester(strat, data)
+		configure(backtester)
+		return backtester.Run(initialCapital, startDate, endDate)
+	}
+}
+
+// ObjectiveFor returns the objective function of a named objective
+func ObjectiveFor(objective string) (ObjectiveFunc, error) {
+	switch objective {
+	case ObjectiveSharpe:
+		return func(result *backtest.BacktestResult) float64 { return result.SharpeRatio }, nil
+	case ObjectiveCalmar:
+		return CalmarRatio, nil
+	case ObjectiveNetPnL:
+		return func(result *backtest.BacktestResult) float64 { return result.FinalCapital - result.InitialCapital }, nil
+	}
+	return nil, fmt.Errorf("unknown objective %q (use %s, %s or %s)", objective, ObjectiveSharpe, ObjectiveCalmar, ObjectiveNetPnL)
+}
+
+// Run backtests every parameter combination on each symbol's klines and returns the results
+// per symbol, best first. Combinations the strategy rejects as invalid are skipped. With an
+// out-of-sample share the combinations are ranked on the klines before it and then validated on
+// it. Every combination on every symbol is a separate backtest of the workers.
+func (gs *GridSearch) Run(data map[string][]bybit.KlineData) (map[string][]Result, error) {
+	combinations := gs.Grid.Combinations()
+	if len(combinations) > backtest.MaxSweepCombinations {
+		return nil, fmt.Errorf("grid has %d combinations, more than %d", len(combinations), backtest.MaxSweepCombinations)
+	}
+	if _, err := strategy.NewStrategy(gs.StrategyType); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(data))
+	searched := make(map[string][]bybit.KlineData, len(data))
+	splitDates := make(map[string]time.Time, len(data))
+	for symbol, klines := range data {
+		if len(klines) == 0 {
+			continue
+		}
+		symbols = append(symbols, symbol)
+		searched[symbol], splitDates[symbol] = gs.OutOfSample.split(klines)
+	}
+	sort.Strings(symbols)
+
+	// Job i backtests combination i%len(combinations) on symbol i/len(combinations)
+	backtested := make([]*Result, len(symbols)*len(combinations))
+	backtest.Parallel(len(backtested), gs.Workers, func(job int) {
+		symbol, params := symbols[job/len(combinations)], combinations[job%len(combinations)]
+		strat, err := strategy.NewStrategy(gs.StrategyType)
+		if err != nil || strat.SetParameters(params) != nil {
+			return
+		}
+		klines := searched[symbol]
+		result := gs.Backtest(strat, map[string][]bybit.KlineData{symbol: klines}, gs.InitialCapital, klines[0].Timestamp, klines[len(klines)-1].Timestamp)
+		if result == nil {
+			return
+		}
+		ranked := newResult(symbol, params, result, gs.Objective(result))
+		backtested[job] = &ranked
+	})
+
+	results := make(map[string][]Result)
+	for _, result := range backtested {
+		if result != nil {
+			results[result.Symbol] = append(results[result.Symbol], *result)
+		}
+	}
+	for _, symbol := range symbols {
+		sort.SliceStable(results[symbol], func(i, j int) bool { return results[symbol][i].Score > results[symbol][j].Score })
+		gs.OutOfSample.validate(gs.StrategyType, gs.Backtest, gs.Objective, gs.InitialCapital, symbol, data[symbol], splitDates[symbol], results[symbol], gs.Workers)
+	}
+
+	return results, nil
+}
+
+// newResult collects the metrics of a backtest result with its objective score
+func newResult(symbol string, params map[string]float64, result *backtest.BacktestResult, score float64) Result {
+	return Result{
+		Symbol:      symbol,
+		Parameters:  params,
+		Score:       score,
+		NetPnL:      result.FinalCapital - result.InitialCapital,
+		TotalReturn: result.TotalReturn,
+		SharpeRatio: result.SharpeRatio,
+		CalmarRatio: CalmarRatio(result),
+		MaxDrawdown: result.MaxDrawdown,
+		TotalTrades: result.TotalTrades,
+	}
+}
+
+// CalmarRatio returns the annualized return over the maximum drawdown of a backtest
+func CalmarRatio(result *backtest.BacktestResult) float64 {
+	return result.CalmarRatio
+}
+
+// Best returns the best parameter set per symbol, skipping sets flagged as overfit out of
+// sample unless all of them are
+func Best(results map[string][]Result) map[string]Result {
+	best := make(map[string]Result, len(results))
+	for symbol, ranked := range results {
+		if len(ranked) == 0 {
+			continue
+		}
+		best[symbol] = ranked[0]
+		for _, result := range ranked {
+			if result.OutOfSample == nil || !result.OutOfSample.Overfit {
+				best[symbol] = result
+				break
+			}
+		}
+	}
+	return best
+}
+
+// ParseGrid parses a grid like "rsi_period=10:20:2;rsi_overbought=65,70,75", where a value
+// list is comma-separated and min:max:step expands to an inclusive range
+func ParseGrid(value string) (ParameterGrid, error) {
+	grid := make(ParameterGrid)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid grid entry %q", item)
+		}
+		name, spec := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		values, err := parseValues(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values for %s: %w", name, err)
+		}
+		grid[name] = values
+	}
+
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("parameter grid is empty")
+	}
+	return grid, nil
+}
+
+// parseValues parses a comma-separated value list or a min:max:step range
+func parseValues(spec string) ([]float64, error) {
+	if bounds := strings.Split(spec, ":"); len(bounds) == 3 {
+		var numbers [3]float64
+		for i, bound := range bounds {
+			number, err := strconv.ParseFloat(strings.TrimSpace(bound), 64)
+			if err != nil {
+				return nil, err
+			}
+			numbers[i] = number
+		}
+		min, max, step := numbers[0], numbers[1], numbers[2]
+		if step <= 0 || max < min {
+			return nil, fmt.Errorf("range %q needs min <= max and a positive step", spec)
+		}
+
+		var values []float64
+		for i := 0; ; i++ {
+			value := min + float64(i)*step
+			if value > max+step*1e-9 {
+				break
+			}
+			values = append(values, math.Round(value*1e9)/1e9)
+		}
+		return values, nil
+	}
+
+	var values []float64
+	for _, item := range strings.Split(spec, ",") {
+		number, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, number)
+	}
+	return values, nil
+}
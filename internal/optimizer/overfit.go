@@ -0,0 +1,114 @@
+package optimizer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/strategy"
+)
+
+// OutOfSample holds the end of each symbol's klines out of a search. The parameter sets are
+// searched on the rest and then each is backtested on the held out klines, so sets that only
+// work in-sample can be flagged.
+type OutOfSample struct {
+	Percent      float64 // Share of the period held out, 0 searches the whole period
+	OverfitRatio float64 // Sets keeping less of their in-sample Sharpe ratio out of sample are overfit
+}
+
+// OutOfSampleResult is the backtest of a parameter set on the held out klines
+type OutOfSampleResult struct {
+	Score           float64 `json:"score"`
+	TotalReturn     float64 `json:"total_return"`
+	SharpeRatio     float64 `json:"sharpe_ratio"`
+	MaxDrawdown     float64 `json:"max_drawdown"`
+	TotalTrades     int     `json:"total_trades"`
+	SharpeRetention float64 `json:"sharpe_retention"` // See backtest.SharpeRetention
+	Overfit         bool    `json:"overfit"`
+}
+
+// split returns the klines searched and the start of the held out part, zero when nothing is
+// held out
+func (o OutOfSample) split(klines []bybit.KlineData) ([]bybit.KlineData, time.Time) {
+	if o.Percent <= 0 || o.Percent >= 100 || len(klines) < 2 {
+		return klines, time.Time{}
+	}
+	splitDate := backtest.SplitDate(klines[0].Timestamp, klines[len(klines)-1].Timestamp, o.Percent)
+	inSample := sort.Search(len(klines), func(i int) bool { return !klines[i].Timestamp.Before(splitDate) })
+	return klines[:inSample], splitDate
+}
+
+// validate backtests the parameters of each result on the klines from the split date on, the
+// klines before it warming the strategy up, on a pool of workers
+func (o OutOfSample) validate(strategyType strategy.StrategyType, backtestFunc BacktestFunc, objective ObjectiveFunc, initialCapital float64, symbol string, klines []bybit.KlineData, splitDate time.Time, results []Result, workers int) {
+	if splitDate.IsZero() {
+		return
+	}
+	endDate := klines[len(klines)-1].Timestamp
+
+	backtest.Parallel(len(results), workers, func(i int) {
+		strat, err := strategy.NewStrategy(strategyType)
+		if err != nil || strat.SetParameters(results[i].Parameters) != nil {
+			return
+		}
+		result := backtestFunc(strat, map[string][]bybit.KlineData{symbol: klines}, initialCapital, splitDate, endDate)
+		if result == nil {
+			return
+		}
+		score := objective(result)
+		if math.IsNaN(score) {
+			score = math.Inf(-1)
+		}
+		results[i].OutOfSample = &OutOfSampleResult{
+			Score:           score,
+			TotalReturn:     result.TotalReturn,
+			SharpeRatio:     result.SharpeRatio,
+			MaxDrawdown:     result.MaxDrawdown,
+			TotalTrades:     result.TotalTrades,
+			SharpeRetention: backtest.SharpeRetention(results[i].SharpeRatio, result.SharpeRatio),
+			Overfit:         backtest.IsOverfit(results[i].SharpeRatio, result.SharpeRatio, o.OverfitRatio),
+		}
+	})
+}
+
+// ProbabilityOfOverfit estimates how likely the in-sample ranking picks an overfit parameter set,
+// in the spirit of the probability of backtest overfitting: the share of the best tenth of the
+// ranked sets (at least one) whose out-of-sample score falls below the median out-of-sample
+// score of all sets. It returns 0 when the results were not validated out of sample.
+func ProbabilityOfOverfit(ranked []Result) float64 {
+	var scores []float64
+	for _, result := range ranked {
+		if result.OutOfSample != nil {
+			scores = append(scores, result.OutOfSample.Score)
+		}
+	}
+	if len(scores) == 0 {
+		return 0
+	}
+	sort.Float64s(scores)
+	median := scores[len(scores)/2]
+	if len(scores)%2 == 0 {
+		median = (scores[len(scores)/2-1] + scores[len(scores)/2]) / 2
+	}
+
+	top := len(ranked) / 10
+	if top < 1 {
+		top = 1
+	}
+	counted, below := 0, 0
+	for _, result := range ranked[:top] {
+		if result.OutOfSample == nil {
+			continue
+		}
+		counted++
+		if result.OutOfSample.Score < median {
+			below++
+		}
+	}
+	if counted == 0 {
+		return 0
+	}
+	return float64(below) / float64(counted)
+}
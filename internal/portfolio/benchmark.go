@@ -0,0 +1,123 @@
+package portfolio
+
+import (
+	"math"
+)
+
+// BenchmarkEqualWeight selects an equal-weight basket of the traded symbols as the benchmark
+const BenchmarkEqualWeight = "EQUAL_WEIGHT"
+
+// BenchmarkState holds the starting point of the synthetic buy-and-hold benchmark
+type BenchmarkState struct {
+	InitialEquity float64            `json:"initial_equity"`
+	BasePrices    map[string]float64 `json:"base_prices"`
+}
+
+// benchmarkValue returns the value of the buy-and-hold benchmark at the given prices,
+// initializing the benchmark on first use. It returns false when no benchmark price is available.
+func (pm *PortfolioManager) benchmarkValue(currentPrices map[string]float64, equity float64) (float64, bool) {
+	if pm.Benchmark == nil {
+		basePrices := make(map[string]float64)
+		for _, symbol := range pm.benchmarkSymbols() {
+			if price := pm.priceOf(symbol, currentPrices); price > 0 {
+				basePrices[symbol] = price
+			}
+		}
+		if len(basePrices) == 0 {
+			return 0, false
+		}
+
+		pm.Benchmark = &BenchmarkState{
+			InitialEquity: equity,
+			BasePrices:    basePrices,
+		}
+	}
+
+	// Average price relative across the basket (a single symbol for BTC buy-and-hold)
+	relativeSum := 0.0
+	count := 0
+	for symbol, basePrice := range pm.Benchmark.BasePrices {
+		if price := pm.priceOf(symbol, currentPrices); price > 0 {
+			relativeSum += price / basePrice
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+
+	return pm.Benchmark.InitialEquity * relativeSum / float64(count), true
+}
+
+// benchmarkSymbols returns the symbols making up the benchmark
+func (pm *PortfolioManager) benchmarkSymbols() []string {
+	if pm.Config.Benchmark == BenchmarkEqualWeight {
+		return pm.Symbols
+	}
+	return []string{pm.Config.Benchmark}
+}
+
+// priceOf returns the current price of a symbol, falling back to the last known price
+func (pm *PortfolioManager) priceOf(symbol string, currentPrices map[string]float64) float64 {
+	if price, exists := currentPrices[symbol]; exists && price > 0 {
+		return price
+	}
+	return pm.LastPrices[symbol]
+}
+
+// applyBenchmarkMetrics computes alpha, tracking error and relative drawdown against the benchmark
+func (pm *PortfolioManager) applyBenchmarkMetrics(metrics *PerformanceMetrics) {
+	// Only use samples that carry a benchmark value
+	var curve []EquityPoint
+	for _, point := range pm.EquityCurve {
+		if point.Benchmark > 0 && point.Equity > 0 {
+			curve = append(curve, point)
+		}
+	}
+	if len(curve) < 2 {
+		return
+	}
+
+	first := curve[0]
+	last := curve[len(curve)-1]
+
+	// Alpha is the cumulative excess return over the benchmark
+	strategyReturn := last.Equity/first.Equity - 1
+	benchmarkReturn := last.Benchmark/first.Benchmark - 1
+	metrics.BenchmarkReturn = benchmarkReturn
+	metrics.Alpha = strategyReturn - benchmarkReturn
+
+	// Tracking error is the standard deviation of per-period excess returns
+	var excess []float64
+	for i := 1; i < len(curve); i++ {
+		strategyPeriod := curve[i].Equity/curve[i-1].Equity - 1
+		benchmarkPeriod := curve[i].Benchmark/curve[i-1].Benchmark - 1
+		excess = append(excess, strategyPeriod-benchmarkPeriod)
+	}
+	if len(excess) > 1 {
+		mean := 0.0
+		for _, e := range excess {
+			mean += e
+		}
+		mean /= float64(len(excess))
+
+		variance := 0.0
+		for _, e := range excess {
+			variance += math.Pow(e-mean, 2)
+		}
+		metrics.TrackingError = math.Sqrt(variance / float64(len(excess)-1))
+	}
+
+	// Relative drawdown is the largest decline of equity relative to the benchmark
+	peakRatio := 0.0
+	metrics.RelativeDrawdown = 0
+	for _, point := range curve {
+		ratio := point.Equity / point.Benchmark
+		if ratio > peakRatio {
+			peakRatio = ratio
+		}
+		if drawdown := (peakRatio - ratio) / peakRatio; drawdown > metrics.RelativeDrawdown {
+			metrics.RelativeDrawdown = drawdown
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// UpdateCurrencyInfo resolves the quote currency of each symbol and refreshes
+// conversion rates into the reporting currency. A currency whose rate can not be fetched does
+// not stop the others from refreshing.
+func (pm *PortfolioManager) UpdateCurrencyInfo(ctx context.Context) error {
+	reporting := pm.Config.ReportingCurrency
+
+	for _, symbol := range pm.Symbols {
+		if _, exists := pm.QuoteCurrencies[symbol]; exists {
+			continue
+		}
+
+		_, quote, err := pm.BybitClient.GetSymbolCurrencies(ctx, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to resolve currencies for %s: %w", symbol, err)
+		}
+		pm.QuoteCurrencies[symbol] = quote
+	}
+
+	// Refresh conversion rates for every quote currency in use
+	var rateErr error
+	for _, quote := range pm.QuoteCurrencies {
+		if quote == reporting {
+			pm.ConversionRates[quote] = 1.0
+			continue
+		}
+
+		rate, err := pm.BybitClient.GetConversionRate(ctx, quote, reporting)
+		if err != nil {
+			// Keep the previous rate if we have one
+			if _, exists := pm.ConversionRates[quote]; exists {
+				fmt.Printf("Warning: Using stale %s/%s rate: %v\n", quote, reporting, err)
+				continue
+			}
+			if rateErr == nil {
+				rateErr = fmt.Errorf("failed to get %s/%s conversion rate: %w", quote, reporting, err)
+			}
+			continue
+		}
+		pm.ConversionRates[quote] = rate
+	}
+
+	return rateErr
+}
+
+// AddQuoteCurrency registers the quote currency of a symbol traded outside the portfolio's
+// symbols, so UpdateCurrencyInfo keeps its conversion rate current
+func (pm *PortfolioManager) AddQuoteCurrency(symbol, quote string) {
+	pm.QuoteCurrencies[symbol] = quote
+}
+
+// QuoteCurrency returns the quote currency of a symbol
+func (pm *PortfolioManager) QuoteCurrency(symbol string) string {
+	if quote, exists := pm.QuoteCurrencies[symbol]; exists {
+		return quote
+	}
+
+	if _, quote, err := bybit.ParseSymbol(symbol); err == nil {
+		return quote
+	}
+
+	return pm.Config.ReportingCurrency
+}
+
+// ConversionRate returns the rate that converts the symbol's quote currency into the reporting
+// currency, and false while no rate is known. Symbols without a rate are neither traded nor valued.
+func (pm *PortfolioManager) ConversionRate(symbol string) (float64, bool) {
+	quote := pm.QuoteCurrency(symbol)
+	if quote == pm.Config.ReportingCurrency {
+		return 1.0, true
+	}
+
+	if rate, exists := pm.ConversionRates[quote]; exists && rate > 0 {
+		return rate, true
+	}
+	return 0, false
+}
+
+// ToReportingCurrency converts an amount quoted in the symbol's quote currency into the
+// reporting currency, false without a conversion rate
+func (pm *PortfolioManager) ToReportingCurrency(symbol string, amount float64) (float64, bool) {
+	rate, ok := pm.ConversionRate(symbol)
+	return amount * rate, ok
+}
+
+// FromReportingCurrency converts an amount in the reporting currency into the symbol's quote
+// currency, false without a conversion rate
+func (pm *PortfolioManager) FromReportingCurrency(symbol string, amount float64) (float64, bool) {
+	rate, ok := pm.ConversionRate(symbol)
+	if !ok {
+		return 0, false
+	}
+	return amount / rate, true
+}
@@ -0,0 +1,42 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestConversionRateRequiresKnownRate(t *testing.T) {
+	pm := &PortfolioManager{
+		Config:          &config.Config{ReportingCurrency: "USDT", TotalCapital: 1000},
+		Cash:            1000,
+		Holdings:        map[string]float64{"BTCUSDT": 1, "ETHEUR": 2},
+		LastPrices:      map[string]float64{},
+		QuoteCurrencies: map[string]string{"BTCUSDT": "USDT", "ETHEUR": "EUR"},
+		ConversionRates: map[string]float64{},
+	}
+
+	if rate, ok := pm.ConversionRate("BTCUSDT"); !ok || rate != 1 {
+		t.Errorf("reporting currency rate = %v, %t, want 1, true", rate, ok)
+	}
+	if _, ok := pm.ConversionRate("ETHEUR"); ok {
+		t.Error("EUR rate reported as known before it was fetched")
+	}
+	if _, ok := pm.FromReportingCurrency("ETHEUR", 100); ok {
+		t.Error("converted into EUR without a rate")
+	}
+
+	// Positions without a rate are not valued, rather than valued 1:1
+	prices := map[string]float64{"BTCUSDT": 100, "ETHEUR": 50}
+	if point := pm.MarkToMarket(prices); point.PositionsValue != 100 {
+		t.Errorf("positions value without the EUR rate = %v, want 100", point.PositionsValue)
+	}
+
+	pm.ConversionRates["EUR"] = 1.1
+	if value, ok := pm.ToReportingCurrency("ETHEUR", 100); !ok || value < 109.99 || value > 110.01 {
+		t.Errorf("100 EUR = %v USDT (%t), want 110", value, ok)
+	}
+	if point := pm.MarkToMarket(prices); point.PositionsValue < 209.99 || point.PositionsValue > 210.01 {
+		t.Errorf("positions value with the EUR rate = %v, want 210", point.PositionsValue)
+	}
+}
@@ -0,0 +1,260 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// equityCurveFile is the file name of the persisted equity curve inside the data directory
+const equityCurveFile = "equity_curve.jsonl"
+
+// EquityPoint represents a mark-to-market sample of the portfolio
+type EquityPoint struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Equity         float64   `json:"equity"`
+	Cash           float64   `json:"cash"`
+	PositionsValue float64   `json:"positions_value"`
+	Benchmark      float64   `json:"benchmark,omitempty"` // Buy-and-hold benchmark value
+}
+
+// applyTradeToHoldings updates cash and holdings for an executed BUY or SELL
+func (pm *PortfolioManager) applyTradeToHoldings(symbol, action string, quantity, price float64) {
+	if quantity <= 0 || price <= 0 {
+		return
+	}
+
+	// Cash is kept in the reporting currency
+	notional, ok := pm.ToReportingCurrency(symbol, quantity*price)
+	if !ok {
+		fmt.Printf("Warning: No conversion rate for %s, trade not applied to holdings\n", symbol)
+		return
+	}
+
+	// Shorts are held as negative quantities, so their value falls as the price rises
+	switch action {
+	case "BUY", "COVER":
+		pm.Holdings[symbol] += quantity
+		pm.Cash -= notional
+	case "SELL", "SHORT":
+		pm.Holdings[symbol] -= quantity
+		pm.Cash += notional
+	default:
+		return
+	}
+
+	pm.LastPrices[symbol] = price
+}
+
+// MarkToMarket returns the current cash, positions value and total equity in the reporting
+// currency using the given prices. Symbols without a current price fall back to their last known price.
+func (pm *PortfolioManager) MarkToMarket(currentPrices map[string]float64) EquityPoint {
+	positionsValue := 0.0
+	for symbol, quantity := range pm.Holdings {
+		price, exists := currentPrices[symbol]
+		if !exists {
+			price = pm.LastPrices[symbol]
+		}
+		// Symbols without a conversion rate are not valued until one is known
+		if value, ok := pm.ToReportingCurrency(symbol, quantity*price); ok {
+			positionsValue += value
+		}
+	}
+
+	return EquityPoint{
+		Timestamp:      pm.now(),
+		Equity:         pm.Cash + positionsValue,
+		Cash:           pm.Cash,
+		PositionsValue: positionsValue,
+	}
+}
+
+// RecordEquity samples mark-to-market equity, appends it to the equity curve and persists it
+func (pm *PortfolioManager) RecordEquity(currentPrices map[string]float64) (EquityPoint, error) {
+	for symbol, price := range currentPrices {
+		if price > 0 {
+			pm.LastPrices[symbol] = price
+		}
+	}
+
+	point := pm.MarkToMarket(currentPrices)
+	if benchmark, ok := pm.benchmarkValue(currentPrices, point.Equity); ok {
+		point.Benchmark = benchmark
+	}
+	pm.EquityCurve = append(pm.EquityCurve, point)
+
+	if err := persistence.AppendJSONLine(pm.equityCurvePath(), point); err != nil {
+		return point, fmt.Errorf("failed to persist equity point: %w", err)
+	}
+
+	return point, nil
+}
+
+// GetEquityCurve returns the recorded equity curve
+func (pm *PortfolioManager) GetEquityCurve() []EquityPoint {
+	return pm.EquityCurve
+}
+
+// EquitySample is a point of a charted equity curve with its drawdown
+type EquitySample struct {
+	EquityPoint
+	Drawdown float64 `json:"drawdown"` // Percent below the highest equity recorded before
+}
+
+// SampleEquity returns the points of an equity curve between two times, zero for open ends, with
+// their drawdowns. A positive resolution downsamples the curve to one sample per period, the
+// period's last point with its deepest drawdown so troughs stay visible.
+func SampleEquity(curve []EquityPoint, from, to time.Time, resolution time.Duration) []EquitySample {
+	samples := make([]EquitySample, 0)
+	peak := math.Inf(-1)
+	var bucket time.Time
+	for _, point := range curve {
+		peak = math.Max(peak, point.Equity)
+		if !from.IsZero() && point.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && point.Timestamp.After(to) {
+			break
+		}
+
+		sample := EquitySample{EquityPoint: point}
+		if peak > 0 {
+			sample.Drawdown = (peak - point.Equity) / peak * 100
+		}
+		if resolution <= 0 {
+			samples = append(samples, sample)
+			continue
+		}
+		if start := point.Timestamp.Truncate(resolution); len(samples) > 0 && start.Equal(bucket) {
+			last := &samples[len(samples)-1]
+			sample.Drawdown = math.Max(sample.Drawdown, last.Drawdown)
+			*last = sample
+		} else {
+			bucket = start
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// LoadEquityCurve loads the persisted equity curve from disk
+func (pm *PortfolioManager) LoadEquityCurve() error {
+	var curve []EquityPoint
+	err := persistence.ReadJSONLines(pm.equityCurvePath(), func(line []byte) error {
+		var point EquityPoint
+		if err := json.Unmarshal(line, &point); err != nil {
+			return fmt.Errorf("failed to decode equity point: %w", err)
+		}
+		curve = append(curve, point)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pm.EquityCurve = curve
+	return nil
+}
+
+// GetStartOfDayEquity returns the equity at the start of the current UTC day: the last sample
+// before midnight, or the first sample of the day, or the initial capital if there is none
+func (pm *PortfolioManager) GetStartOfDayEquity(now time.Time) float64 {
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+
+	startEquity := pm.Config.TotalCapital
+	for i, point := range pm.EquityCurve {
+		if !point.Timestamp.Before(dayStart) {
+			if i == 0 {
+				startEquity = point.Equity
+			}
+			break
+		}
+		startEquity = point.Equity
+	}
+
+	return startEquity
+}
+
+// equityCurvePath returns the location of the persisted equity curve
+func (pm *PortfolioManager) equityCurvePath() string {
+	return filepath.Join(pm.Config.DataDir, equityCurveFile)
+}
+
+// equityReturns converts an equity curve into period-over-period returns
+func equityReturns(curve []EquityPoint) []float64 {
+	returns := make([]float64, 0, len(curve))
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Equity != 0 {
+			returns = append(returns, (curve[i].Equity-curve[i-1].Equity)/curve[i-1].Equity)
+		}
+	}
+	return returns
+}
+
+// equityMaxDrawdown returns the largest peak-to-trough decline of the equity curve in currency units
+func equityMaxDrawdown(curve []EquityPoint) float64 {
+	maxDrawdown := 0.0
+	peak := math.Inf(-1)
+
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if drawdown := peak - point.Equity; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+
+	return maxDrawdown
+}
+
+// applyEquityMetrics overrides drawdown, Sharpe and Sortino with values computed on the equity curve
+func (pm *PortfolioManager) applyEquityMetrics(metrics *PerformanceMetrics) {
+	if len(pm.EquityCurve) < 2 {
+		return
+	}
+
+	metrics.MaxDrawdown = equityMaxDrawdown(pm.EquityCurve)
+	applyEquityCalmar(metrics, pm.EquityCurve)
+	pm.applyBenchmarkMetrics(metrics)
+
+	returns := equityReturns(pm.EquityCurve)
+	if len(returns) < 2 {
+		return
+	}
+
+	sum := 0.0
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+
+	variance := 0.0
+	downsideSum := 0.0
+	downsideCount := 0
+	for _, r := range returns {
+		variance += math.Pow(r-mean, 2)
+		if r < 0 {
+			downsideSum += r * r
+			downsideCount++
+		}
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+
+	metrics.SharpeRatio = 0
+	if stdDev > 0 {
+		metrics.SharpeRatio = mean / stdDev
+	}
+
+	metrics.SortinoRatio = 0
+	if downsideCount > 0 {
+		downsideDev := math.Sqrt(downsideSum / float64(downsideCount))
+		if downsideDev > 0 {
+			metrics.SortinoRatio = mean / downsideDev
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportFormat represents a supported trade log export format
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// tradeExportHeader is the column order used for CSV exports
+var tradeExportHeader = []string{
+	"timestamp", "symbol", "action", "quantity", "price",
+	"strategy", "confidence", "reason", "pnl", "cumulative_pnl", "order_id",
+	"mae", "mfe", "holding_hours",
+}
+
+// tradeExportRecord is the JSON representation of an exported trade
+type tradeExportRecord struct {
+	Timestamp     string  `json:"timestamp"`
+	Symbol        string  `json:"symbol"`
+	Action        string  `json:"action"`
+	Quantity      float64 `json:"quantity"`
+	Price         float64 `json:"price"`
+	Strategy      string  `json:"strategy"`
+	Confidence    float64 `json:"confidence"`
+	Reason        string  `json:"reason"`
+	PnL           float64 `json:"pnl"`
+	CumulativePnL float64 `json:"cumulative_pnl"`
+	OrderID       string  `json:"order_id,omitempty"`
+	MAE           float64 `json:"mae,omitempty"` // Excursions and holding time of the position a trade closed
+	MFE           float64 `json:"mfe,omitempty"`
+	HoldingHours  float64 `json:"holding_hours,omitempty"`
+}
+
+// ParseExportFormat converts a string into an ExportFormat
+func ParseExportFormat(format string) (ExportFormat, error) {
+	switch ExportFormat(format) {
+	case ExportFormatCSV, ExportFormatJSON:
+		return ExportFormat(format), nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ExportTrades writes the full trade log to w in the requested format
+func (pm *PortfolioManager) ExportTrades(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportFormatCSV:
+		return exportTradesCSV(w, pm.TradeLog)
+	case ExportFormatJSON:
+		return exportTradesJSON(w, pm.TradeLog)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportTradesCSV writes trades as CSV with a header row
+func exportTradesCSV(w io.Writer, trades []TradeLogEntry) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(tradeExportHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, trade := range trades {
+		record := []string{
+			trade.Timestamp.UTC().Format(time.RFC3339),
+			trade.Symbol,
+			trade.Action,
+			strconv.FormatFloat(trade.Quantity, 'f', -1, 64),
+			strconv.FormatFloat(trade.Price, 'f', -1, 64),
+			trade.Strategy,
+			strconv.FormatFloat(trade.Confidence, 'f', -1, 64),
+			trade.Reason,
+			strconv.FormatFloat(trade.PnL, 'f', -1, 64),
+			strconv.FormatFloat(trade.CumulativePnL, 'f', -1, 64),
+			trade.OrderID,
+			strconv.FormatFloat(trade.MAE, 'f', -1, 64),
+			strconv.FormatFloat(trade.MFE, 'f', -1, 64),
+			strconv.FormatFloat(trade.Held.Hours(), 'f', -1, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportTradesJSON writes trades as a JSON array
+func exportTradesJSON(w io.Writer, trades []TradeLogEntry) error {
+	records := make([]tradeExportRecord, 0, len(trades))
+	for _, trade := range trades {
+		records = append(records, tradeExportRecord{
+			Timestamp:     trade.Timestamp.UTC().Format(time.RFC3339),
+			Symbol:        trade.Symbol,
+			Action:        trade.Action,
+			Quantity:      trade.Quantity,
+			Price:         trade.Price,
+			Strategy:      trade.Strategy,
+			Confidence:    trade.Confidence,
+			Reason:        trade.Reason,
+			PnL:           trade.PnL,
+			CumulativePnL: trade.CumulativePnL,
+			OrderID:       trade.OrderID,
+			MAE:           trade.MAE,
+			MFE:           trade.MFE,
+			HoldingHours:  trade.Held.Hours(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("failed to encode trades: %w", err)
+	}
+
+	return nil
+}
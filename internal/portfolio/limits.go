@@ -0,0 +1,65 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradeBudget reports how many orders have been used today against the configured limits
+type TradeBudget struct {
+	Date              string         `json:"date"` // UTC day the counts apply to
+	MaxPerDay         int            `json:"max_per_day"`
+	MaxPerSymbol      int            `json:"max_per_symbol"`
+	TradesToday       int            `json:"trades_today"`
+	SymbolTradesToday map[string]int `json:"symbol_trades_today"`
+}
+
+// isOrderAction reports whether a trade log action resulted in an order
+func isOrderAction(action string) bool {
+	return action == "BUY" || action == "SELL" || action == "SHORT" || action == "COVER"
+}
+
+// GetTradeBudget counts the orders placed since the start of the current UTC day
+func (pm *PortfolioManager) GetTradeBudget(now time.Time) TradeBudget {
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+
+	budget := TradeBudget{
+		Date:              dayStart.Format("2006-01-02"),
+		MaxPerDay:         pm.Config.MaxTradesPerDay,
+		MaxPerSymbol:      pm.Config.MaxTradesPerSymbol,
+		SymbolTradesToday: make(map[string]int),
+	}
+
+	// The trade log is chronological, so stop at the first trade before today
+	for i := len(pm.TradeLog) - 1; i >= 0; i-- {
+		trade := pm.TradeLog[i]
+		if trade.Timestamp.Before(dayStart) {
+			break
+		}
+		if !isOrderAction(trade.Action) {
+			continue
+		}
+
+		budget.TradesToday++
+		budget.SymbolTradesToday[trade.Symbol]++
+	}
+
+	return budget
+}
+
+// CheckTradeLimit returns an error if placing another order for symbol would exceed
+// the daily or per-symbol trade limits. A limit of 0 disables it.
+func (pm *PortfolioManager) CheckTradeLimit(symbol string) error {
+	budget := pm.GetTradeBudget(pm.now())
+
+	if budget.MaxPerDay > 0 && budget.TradesToday >= budget.MaxPerDay {
+		return fmt.Errorf("daily trade limit reached (%d/%d)", budget.TradesToday, budget.MaxPerDay)
+	}
+
+	if budget.MaxPerSymbol > 0 && budget.SymbolTradesToday[symbol] >= budget.MaxPerSymbol {
+		return fmt.Errorf("daily trade limit for %s reached (%d/%d)",
+			symbol, budget.SymbolTradesToday[symbol], budget.MaxPerSymbol)
+	}
+
+	return nil
+}
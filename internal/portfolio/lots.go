@@ -0,0 +1,333 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/shopspring/decimal"
+)
+
+// TaxLot represents a quantity of an asset bought at one time and price.
+// Closed lots carry a close time and realized PnL, open lots carry unrealized PnL.
+type TaxLot struct {
+	Symbol    string    `json:"symbol"`
+	Quantity  float64   `json:"quantity"`
+	OpenTime  time.Time `json:"open_time"`
+	CloseTime time.Time `json:"close_time,omitempty"`
+	OpenPrice float64   `json:"open_price"`
+	// ClosePrice is the exit price for closed lots and the mark price for open lots
+	ClosePrice float64 `json:"close_price"`
+	CostBasis  float64 `json:"cost_basis"`
+	Proceeds   float64 `json:"proceeds"`
+	PnL        float64 `json:"pnl"`
+	Closed     bool    `json:"closed"`
+	Short      bool    `json:"short,omitempty"`    // Opened by a SHORT and closed by a COVER
+	Strategy   string  `json:"strategy,omitempty"` // Strategy that opened the lot
+}
+
+// PnLSummary aggregates realized and unrealized PnL for a symbol and calendar month
+type PnLSummary struct {
+	Symbol     string  `json:"symbol"`
+	Month      string  `json:"month"` // YYYY-MM
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+}
+
+// PnLReport separates realized PnL on closed lots from unrealized PnL on open lots
+type PnLReport struct {
+	GeneratedAt     time.Time    `json:"generated_at"`
+	Currency        string       `json:"currency"`
+	ClosedLots      []TaxLot     `json:"closed_lots"`
+	OpenLots        []TaxLot     `json:"open_lots"`
+	Summary         []PnLSummary `json:"summary"`
+	TotalRealized   float64      `json:"total_realized"`
+	TotalUnrealized float64      `json:"total_unrealized"`
+}
+
+// taxLotExportHeader is the column order used for tax-lot CSV exports
+var taxLotExportHeader = []string{
+	"symbol", "status", "quantity", "open_time", "close_time",
+	"open_price", "close_price", "cost_basis", "proceeds", "pnl",
+}
+
+// GeneratePnLReport matches SELL trades against BUY trades and COVER trades against SHORT trades
+// first-in-first-out and values the remaining open lots at the given prices. The cost basis of a
+// short lot is the cost of buying it back and its proceeds are those of the short sale. All amounts
+// are in the reporting currency.
+func (pm *PortfolioManager) GeneratePnLReport(currentPrices map[string]float64) *PnLReport {
+	report := &PnLReport{
+		GeneratedAt: time.Now(),
+		Currency:    pm.Config.ReportingCurrency,
+		ClosedLots:  make([]TaxLot, 0),
+		OpenLots:    make([]TaxLot, 0),
+	}
+
+	openLots := make(map[string][]TaxLot)
+	shortLots := make(map[string][]TaxLot)
+
+	for _, trade := range pm.TradeLog {
+		if trade.Quantity <= 0 || trade.Price <= 0 {
+			continue
+		}
+
+		switch trade.Action {
+		case "BUY", "SHORT":
+			short := trade.Action == "SHORT"
+			lot := TaxLot{
+				Symbol:    trade.Symbol,
+				Quantity:  trade.Quantity,
+				OpenTime:  trade.Timestamp,
+				OpenPrice: trade.Price,
+				Short:     short,
+				Strategy:  trade.Strategy,
+			}
+			if short {
+				shortLots[trade.Symbol] = append(shortLots[trade.Symbol], lot)
+			} else {
+				openLots[trade.Symbol] = append(openLots[trade.Symbol], lot)
+			}
+		case "SELL", "COVER":
+			short := trade.Action == "COVER"
+			remaining := trade.Quantity
+			lots := openLots[trade.Symbol]
+			if short {
+				lots = shortLots[trade.Symbol]
+			}
+
+			for remaining > 0 && len(lots) > 0 {
+				lot := &lots[0]
+				closedQty := remaining
+				if lot.Quantity < closedQty {
+					closedQty = lot.Quantity
+				}
+
+				closed := TaxLot{
+					Symbol:     trade.Symbol,
+					Quantity:   closedQty,
+					OpenTime:   lot.OpenTime,
+					CloseTime:  trade.Timestamp,
+					OpenPrice:  lot.OpenPrice,
+					ClosePrice: trade.Price,
+					Short:      short,
+					Strategy:   lot.Strategy,
+					Closed:     true,
+				}
+				pm.valueLot(&closed)
+				report.ClosedLots = append(report.ClosedLots, closed)
+				report.TotalRealized += closed.PnL
+
+				lot.Quantity -= closedQty
+				remaining -= closedQty
+				if lot.Quantity <= 0 {
+					lots = lots[1:]
+				}
+			}
+
+			if short {
+				shortLots[trade.Symbol] = lots
+			} else {
+				openLots[trade.Symbol] = lots
+			}
+		}
+	}
+
+	// Value the lots that are still open
+	for _, open := range []map[string][]TaxLot{openLots, shortLots} {
+		for symbol, lots := range open {
+			markPrice, exists := currentPrices[symbol]
+			if !exists {
+				markPrice = pm.LastPrices[symbol]
+			}
+
+			for _, lot := range lots {
+				lot.ClosePrice = markPrice
+				pm.valueLot(&lot)
+				report.OpenLots = append(report.OpenLots, lot)
+				report.TotalUnrealized += lot.PnL
+			}
+		}
+	}
+
+	sort.Slice(report.OpenLots, func(i, j int) bool {
+		return report.OpenLots[i].OpenTime.Before(report.OpenLots[j].OpenTime)
+	})
+
+	report.Summary = summarizeLots(report.ClosedLots, report.OpenLots)
+
+	return report
+}
+
+// valueLot sets the cost basis, proceeds and PnL of a lot from its open and close prices. Lots
+// of symbols without a conversion rate are left unvalued.
+func (pm *PortfolioManager) valueLot(lot *TaxLot) {
+	entry, ok := pm.ToReportingCurrency(lot.Symbol, lot.Quantity*lot.OpenPrice)
+	if !ok {
+		return
+	}
+	exit, _ := pm.ToReportingCurrency(lot.Symbol, lot.Quantity*lot.ClosePrice)
+	if lot.Short {
+		lot.CostBasis, lot.Proceeds = exit, entry
+	} else {
+		lot.CostBasis, lot.Proceeds = entry, exit
+	}
+	lot.PnL = lot.Proceeds - lot.CostBasis
+}
+
+// summarizeLots groups PnL by symbol and calendar month. Realized PnL is attributed to the
+// month a lot was closed and unrealized PnL to the month it was opened.
+func summarizeLots(closedLots, openLots []TaxLot) []PnLSummary {
+	type summaryKey struct {
+		symbol string
+		month  string
+	}
+
+	summaries := make(map[summaryKey]*PnLSummary)
+	get := func(symbol string, t time.Time) *PnLSummary {
+		key := summaryKey{symbol: symbol, month: t.Format("2006-01")}
+		if _, exists := summaries[key]; !exists {
+			summaries[key] = &PnLSummary{Symbol: key.symbol, Month: key.month}
+		}
+		return summaries[key]
+	}
+
+	for _, lot := range closedLots {
+		get(lot.Symbol, lot.CloseTime).Realized += lot.PnL
+	}
+	for _, lot := range openLots {
+		get(lot.Symbol, lot.OpenTime).Unrealized += lot.PnL
+	}
+
+	result := make([]PnLSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month < result[j].Month
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+
+	return result
+}
+
+// ExportPnLReportCSV writes every closed and open lot of the report as CSV for tax purposes
+func ExportPnLReportCSV(w io.Writer, report *PnLReport) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(taxLotExportHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	writeLot := func(lot TaxLot) error {
+		status := "unrealized"
+		closeTime := ""
+		if lot.Closed {
+			status = "realized"
+			closeTime = lot.CloseTime.UTC().Format(time.RFC3339)
+		}
+
+		record := []string{
+			lot.Symbol,
+			status,
+			strconv.FormatFloat(lot.Quantity, 'f', -1, 64),
+			lot.OpenTime.UTC().Format(time.RFC3339),
+			closeTime,
+			strconv.FormatFloat(lot.OpenPrice, 'f', -1, 64),
+			strconv.FormatFloat(lot.ClosePrice, 'f', -1, 64),
+			strconv.FormatFloat(lot.CostBasis, 'f', 2, 64),
+			strconv.FormatFloat(lot.Proceeds, 'f', 2, 64),
+			strconv.FormatFloat(lot.PnL, 'f', 2, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+		return nil
+	}
+
+	for _, lot := range report.ClosedLots {
+		if err := writeLot(lot); err != nil {
+			return err
+		}
+	}
+	for _, lot := range report.OpenLots {
+		if err := writeLot(lot); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// GetOpenPositions aggregates the open lots of each symbol into a long or short position with
+// the volume-weighted average entry price and the unrealized PnL at the given prices
+func (pm *PortfolioManager) GetOpenPositions(currentPrices map[string]float64) []bybit.Position {
+	report := pm.GeneratePnLReport(currentPrices)
+
+	// Shorts are keyed separately, a symbol is never long and short at the same time
+	type positionKey struct {
+		symbol string
+		short  bool
+	}
+	quantities := make(map[positionKey]float64)
+	costs := make(map[positionKey]float64)
+	marks := make(map[positionKey]float64)
+	keys := make([]positionKey, 0)
+	for _, lot := range report.OpenLots {
+		key := positionKey{symbol: lot.Symbol, short: lot.Short}
+		if _, exists := quantities[key]; !exists {
+			keys = append(keys, key)
+		}
+		quantities[key] += lot.Quantity
+		costs[key] += lot.Quantity * lot.OpenPrice
+		marks[key] = lot.ClosePrice
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].symbol < keys[j].symbol })
+
+	positions := make([]bybit.Position, 0, len(keys))
+	for _, key := range keys {
+		quantity := quantities[key]
+		if quantity <= 0 {
+			continue
+		}
+		avgPrice := costs[key] / quantity
+		side, pnl := "Buy", (marks[key]-avgPrice)*quantity
+		if key.short {
+			side, pnl = "Sell", -pnl
+		}
+		positions = append(positions, bybit.Position{
+			Symbol:        key.symbol,
+			Side:          side,
+			Size:          decimal.NewFromFloat(quantity),
+			AvgPrice:      decimal.NewFromFloat(avgPrice),
+			UnrealisedPnl: decimal.NewFromFloat(pnl),
+		})
+	}
+
+	return positions
+}
+
+// PositionOrigin records when the oldest open lot of a position was opened and by which strategy
+type PositionOrigin struct {
+	OpenedAt time.Time
+	Strategy string
+}
+
+// GetPositionOrigins returns the origin of each open position, based on its oldest open lot
+func (pm *PortfolioManager) GetPositionOrigins() map[string]PositionOrigin {
+	origins := make(map[string]PositionOrigin)
+	for _, lot := range pm.GeneratePnLReport(nil).OpenLots {
+		origin, exists := origins[lot.Symbol]
+		if !exists || lot.OpenTime.Before(origin.OpenedAt) {
+			origins[lot.Symbol] = PositionOrigin{OpenedAt: lot.OpenTime, Strategy: lot.Strategy}
+		}
+	}
+	return origins
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/forbest/bybitgo/internal/bybit"
@@ -15,7 +16,7 @@ import (
 type TradeLogEntry struct {
 	Timestamp     time.Time
 	Symbol        string
-	Action        string // "BUY", "SELL", "HOLD"
+	Action        string // "BUY", "SELL", "SHORT", "COVER", "HOLD"
 	Quantity      float64
 	Price         float64
 	Strategy      string
@@ -23,6 +24,15 @@ type TradeLogEntry struct {
 	Reason        string
 	PnL           float64 // Profit and Loss for this trade
 	CumulativePnL float64 // Cumulative PnL for this symbol
+	// Order details when the entry was built from exchange fills
+	OrderID           string
+	RequestedQuantity float64 // Quantity originally ordered (Quantity is what was filled)
+	FillCount         int     // Number of partial fills aggregated into this entry
+	// Of the position a closing trade closed: maximum adverse and favorable excursion in percent
+	// of its entry price, and how long it was held
+	MAE  float64
+	MFE  float64
+	Held time.Duration
 }
 
 // PerformanceMetrics tracks performance metrics for the portfolio
@@ -36,54 +46,155 @@ type PerformanceMetrics struct {
 	MaxDrawdown   float64
 	SharpeRatio   float64
 	SortinoRatio  float64
+	// Return over max drawdown; the return is annualized only once the history spans a year
+	CalmarRatio  float64
+	ProfitFactor float64 // Gross profit divided by gross loss, 0 while undefined
+	// Whether there were losing trades to define the profit factor
+	HasProfitFactor bool
+	Expectancy      float64 // Average PnL per closed trade
+	// Longest run of consecutive losing trades
+	LongestLosingStreak int
+	// Fraction of time since the first trade with at least one open position
+	TimeInMarket float64
+	// Benchmark-relative metrics (vs buy-and-hold)
+	BenchmarkReturn  float64
+	Alpha            float64
+	TrackingError    float64
+	RelativeDrawdown float64
 }
 
 // PortfolioManager manages the portfolio of cryptocurrencies
 type PortfolioManager struct {
-	Symbols            []string
-	Allocations        map[string]float64
-	Performance        map[string]float64 // Track performance of each symbol
-	TradeLog           []TradeLogEntry    // Detailed trade log
-	PerformanceMetrics PerformanceMetrics // Overall performance metrics
-	RebalanceInterval  time.Duration
-	BybitClient        *bybit.Client
-	Config             *config.Config
-	MarketAnalyzer     *market.MarketAnalyzer
+	Symbols              []string
+	Allocations          map[string]float64
+	Performance          map[string]float64      // Track performance of each symbol
+	TradeLog             []TradeLogEntry         // Detailed trade log
+	PerformanceMetrics   PerformanceMetrics      // Overall performance metrics
+	Cash                 float64                 // Uninvested cash in the quote currency
+	Holdings             map[string]float64      // Quantity held per symbol
+	LastPrices           map[string]float64      // Last known price per symbol
+	EquityCurve          []EquityPoint           // Mark-to-market equity samples
+	Benchmark            *BenchmarkState         // Synthetic buy-and-hold benchmark
+	LastRebalance        time.Time               // When the portfolio was last rebalanced
+	LastRebalanceSymbols []string                // Symbols at the last rebalance, for change detection
+	Orders               map[string]*OrderRecord // Tracked orders and their fills, keyed by order ID
+	QuoteCurrencies      map[string]string       // Quote currency per symbol
+	ConversionRates      map[string]float64      // Quote currency -> reporting currency rate
+	RebalanceInterval    time.Duration
+	BybitClient          *bybit.Client
+	Config               *config.Config
+	MarketAnalyzer       *market.MarketAnalyzer
+	// Time of trades, orders and equity samples, nil for the wall clock (set by replays)
+	Clock func() time.Time
+	// OnTrade is notified of every trade that placed an order as it is logged, before a close's
+	// PnL is known; HOLD entries are not published
+	OnTrade func(entry TradeLogEntry)
+	// OnTradeUpdate is notified of a logged trade again once a close's PnL is applied to it
+	OnTradeUpdate func(entry TradeLogEntry)
 }
 
 // NewPortfolioManager creates a new PortfolioManager
 func NewPortfolioManager(client *bybit.Client, cfg *config.Config) *PortfolioManager {
-	return &PortfolioManager{
+	pm := &PortfolioManager{
 		Symbols:           make([]string, 0),
 		Allocations:       make(map[string]float64),
 		Performance:       make(map[string]float64),
+		Cash:              cfg.TotalCapital,
+		Holdings:          make(map[string]float64),
+		LastPrices:        make(map[string]float64),
+		QuoteCurrencies:   make(map[string]string),
+		ConversionRates:   make(map[string]float64),
+		Orders:            make(map[string]*OrderRecord),
 		RebalanceInterval: time.Duration(cfg.RebalanceMinutes) * time.Minute,
 		BybitClient:       client,
 		Config:            cfg,
 		MarketAnalyzer:    market.NewMarketAnalyzer(),
 	}
+
+	// Restore state and the equity curve from previous runs
+	if err := pm.LoadState(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if err := pm.LoadEquityCurve(); err != nil {
+		fmt.Printf("Warning: Failed to load equity curve: %v\n", err)
+	}
+
+	return pm
+}
+
+// now returns the current time of the portfolio's clock
+func (pm *PortfolioManager) now() time.Time {
+	if pm.Clock != nil {
+		return pm.Clock()
+	}
+	return time.Now()
 }
 
-// UpdateTopCoins updates the list of top coins based on trading volume
+// maxPortfolioSymbols is the number of symbols traded, unless more symbols are pinned
+const maxPortfolioSymbols = 6
+
+// UpdateTopCoins updates the list of top coins based on trading volume,
+// applying the configured pinned and excluded symbols
 func (pm *PortfolioManager) UpdateTopCoins(ctx context.Context) error {
-	// Get top 6 coins from Bybit
-	topCoins, err := pm.BybitClient.GetTopCoins(ctx, 6)
+	// Get top coins from Bybit, fetching extra to make up for excluded symbols
+	topCoins, err := pm.BybitClient.GetTopCoins(ctx, maxPortfolioSymbols+len(pm.Config.ExcludedSymbols))
 	if err != nil {
 		return fmt.Errorf("failed to get top coins: %w", err)
 	}
 
-	pm.Symbols = topCoins
+	symbols := pm.filterSymbols(topCoins)
+	if len(symbols) == 0 {
+		return fmt.Errorf("no tradable symbols left after applying exclusions")
+	}
+	pm.SetSymbols(symbols)
+
+	return nil
+}
+
+// SetSymbols sets the traded symbols and resets their allocations
+func (pm *PortfolioManager) SetSymbols(symbols []string) {
+	pm.Symbols = symbols
 
 	// Reset allocations
 	pm.Allocations = make(map[string]float64)
 
-	// Equal allocation for now (can be improved with market cap weighting)
-	allocation := 1.0 / float64(len(pm.Symbols))
+	// Equal allocation of the deployable capital for now (can be improved with market cap weighting)
+	allocation := pm.DeployableFraction() / float64(len(pm.Symbols))
 	for _, symbol := range pm.Symbols {
 		pm.Allocations[symbol] = allocation
 	}
+}
+
+// filterSymbols builds the traded symbol list: pinned symbols first, then top coins
+// that are not excluded, up to maxPortfolioSymbols
+func (pm *PortfolioManager) filterSymbols(topCoins []string) []string {
+	excluded := make(map[string]bool)
+	for _, symbol := range pm.Config.ExcludedSymbols {
+		excluded[symbol] = true
+	}
 
-	return nil
+	symbols := make([]string, 0, maxPortfolioSymbols)
+	seen := make(map[string]bool)
+
+	// Pinned symbols are always kept, even beyond the symbol limit
+	for _, symbol := range pm.Config.PinnedSymbols {
+		if !excluded[symbol] && !seen[symbol] {
+			symbols = append(symbols, symbol)
+			seen[symbol] = true
+		}
+	}
+
+	for _, symbol := range topCoins {
+		if len(symbols) >= maxPortfolioSymbols {
+			break
+		}
+		if !excluded[symbol] && !seen[symbol] {
+			symbols = append(symbols, symbol)
+			seen[symbol] = true
+		}
+	}
+
+	return symbols
 }
 
 // GetAllocation returns the capital allocation for a symbol
@@ -166,8 +277,55 @@ func (pm *PortfolioManager) GetVolatilityAdjustedAllocation(symbol string) float
 	return baseAllocation
 }
 
-// GetOptimalAllocation returns the capital allocation for a symbol considering both performance and volatility
+// GetOptimalAllocation returns the capital allocation for a symbol considering performance,
+// volatility and the configured per-symbol caps and floors
 func (pm *PortfolioManager) GetOptimalAllocation(symbol string) float64 {
+	return pm.GetOptimalAllocations()[symbol]
+}
+
+// GetOptimalAllocations returns the capital allocation for every symbol considering performance,
+// volatility and the configured per-symbol caps and floors
+func (pm *PortfolioManager) GetOptimalAllocations() map[string]float64 {
+	return pm.GetScaledAllocations(1)
+}
+
+// GetScaledAllocations returns the optimal allocations scaled by a multiplier, such as the
+// volatility target's. The per-symbol caps and floors and the cash reserve apply after scaling,
+// so a multiplier above 1 never allocates beyond them.
+func (pm *PortfolioManager) GetScaledAllocations(multiplier float64) map[string]float64 {
+	allocations := make(map[string]float64, len(pm.Symbols))
+	for _, symbol := range pm.Symbols {
+		allocations[symbol] = pm.getAdjustedAllocation(symbol) * multiplier
+	}
+
+	allocations = pm.applyAllocationLimits(allocations)
+
+	// Never allocate into the cash reserve
+	total := 0.0
+	for _, allocation := range allocations {
+		total += allocation
+	}
+	if deployable := pm.DeployableFraction(); total > deployable {
+		for symbol := range allocations {
+			allocations[symbol] *= deployable / total
+		}
+	}
+
+	return allocations
+}
+
+// getAdjustedAllocation returns the allocation for a symbol adjusted for performance and
+// volatility as the allocation mode says
+func (pm *PortfolioManager) getAdjustedAllocation(symbol string) float64 {
+	switch pm.Config.GetAllocationMode() {
+	case config.AllocationEqual:
+		return pm.GetAllocation(symbol)
+	case config.AllocationPerformance:
+		return pm.GetPerformanceBasedAllocation(symbol)
+	case config.AllocationVolatility:
+		return pm.GetVolatilityAdjustedAllocation(symbol)
+	}
+
 	// Get performance-based allocation
 	perfAllocation := pm.GetPerformanceBasedAllocation(symbol)
 
@@ -178,6 +336,66 @@ func (pm *PortfolioManager) GetOptimalAllocation(symbol string) float64 {
 	return (perfAllocation + volAllocation) / 2.0
 }
 
+// applyAllocationLimits clamps allocations to the configured caps and floors and redistributes
+// the difference across the unconstrained symbols so the total allocation is preserved
+func (pm *PortfolioManager) applyAllocationLimits(allocations map[string]float64) map[string]float64 {
+	if len(pm.Config.AllocationCaps) == 0 && len(pm.Config.AllocationFloors) == 0 {
+		return allocations
+	}
+
+	total := 0.0
+	for _, allocation := range allocations {
+		total += allocation
+	}
+
+	result := make(map[string]float64, len(allocations))
+	fixed := make(map[string]bool)
+
+	// Each pass fixes at least one symbol at a limit, so this converges within len(allocations) passes
+	for pass := 0; pass <= len(allocations); pass++ {
+		fixedSum := 0.0
+		freeSum := 0.0
+		for symbol, allocation := range allocations {
+			if fixed[symbol] {
+				fixedSum += result[symbol]
+			} else {
+				freeSum += allocation
+			}
+		}
+
+		scale := 0.0
+		if freeSum > 0 {
+			scale = math.Max(total-fixedSum, 0) / freeSum
+		}
+
+		changed := false
+		for symbol, allocation := range allocations {
+			if fixed[symbol] {
+				continue
+			}
+
+			scaled := allocation * scale
+			if maxAlloc, exists := config.SymbolValue(pm.Config.AllocationCaps, symbol); exists && scaled > maxAlloc {
+				result[symbol] = maxAlloc
+				fixed[symbol] = true
+				changed = true
+			} else if minAlloc, exists := config.SymbolValue(pm.Config.AllocationFloors, symbol); exists && scaled < minAlloc {
+				result[symbol] = minAlloc
+				fixed[symbol] = true
+				changed = true
+			} else {
+				result[symbol] = scaled
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return result
+}
+
 // UpdatePerformance updates the performance metrics for a symbol
 func (pm *PortfolioManager) UpdatePerformance(symbol string, performance float64) {
 	// Update performance with exponential moving average to smooth out fluctuations
@@ -206,18 +424,22 @@ func (pm *PortfolioManager) RebalancePortfolio(ctx context.Context) error {
 	}
 
 	// For each symbol, calculate target position size
+	// Use optimal allocations (considering performance, volatility and allocation limits)
+	allocations := pm.GetOptimalAllocations()
 	for _, symbol := range pm.Symbols {
-		// Use optimal allocation (considering both performance and volatility)
-		allocation := pm.GetOptimalAllocation(symbol)
+		allocation := allocations[symbol]
 		targetValue := pm.Config.TotalCapital * allocation
 
-		fmt.Printf("Symbol: %s, Target Allocation: %.2f%%, Target Value: $%.2f\n",
-			symbol, allocation*100, targetValue)
+		fmt.Printf("Symbol: %s, Target Allocation: %.2f%%, Target Value: %.2f %s\n",
+			symbol, allocation*100, targetValue, pm.Config.ReportingCurrency)
 
 		// Here you would place actual orders to achieve the target allocation
 		// This requires checking current positions and placing appropriate orders
 	}
 
+	pm.LastRebalance = pm.now()
+	pm.LastRebalanceSymbols = append([]string(nil), pm.Symbols...)
+
 	return nil
 }
 
@@ -239,7 +461,7 @@ func (pm *PortfolioManager) GetCurrentPositions(ctx context.Context) (map[string
 // LogTrade adds a trade entry to the trade log
 func (pm *PortfolioManager) LogTrade(symbol, action string, quantity, price float64, strategy string, confidence float64, reason string) {
 	entry := TradeLogEntry{
-		Timestamp:     time.Now(),
+		Timestamp:     pm.now(),
 		Symbol:        symbol,
 		Action:        action,
 		Quantity:      quantity,
@@ -252,6 +474,13 @@ func (pm *PortfolioManager) LogTrade(symbol, action string, quantity, price floa
 	}
 
 	pm.TradeLog = append(pm.TradeLog, entry)
+
+	// Keep cash and holdings in sync for mark-to-market equity
+	pm.applyTradeToHoldings(symbol, action, quantity, price)
+
+	if pm.OnTrade != nil && isOrderAction(action) {
+		pm.OnTrade(entry)
+	}
 }
 
 // UpdateTradePnL updates the PnL for a trade when a position is closed
@@ -269,6 +498,9 @@ func (pm *PortfolioManager) UpdateTradePnL(symbol string, entryPrice, exitPrice
 			pm.TradeLog[i].PnL = pnl
 			// Update cumulative PnL
 			pm.TradeLog[i].CumulativePnL = pm.PerformanceMetrics.TotalPnL + pnl
+			if pm.OnTradeUpdate != nil {
+				pm.OnTradeUpdate(pm.TradeLog[i])
+			}
 			break
 		}
 	}
@@ -289,6 +521,20 @@ func (pm *PortfolioManager) UpdateTradePnL(symbol string, entryPrice, exitPrice
 	}
 }
 
+// RecordTradeExcursion records the excursions of a closed position and when it was opened on
+// the latest trade entry of its symbol, the trade that closed it
+func (pm *PortfolioManager) RecordTradeExcursion(symbol string, mae, mfe float64, openedAt time.Time) {
+	for i := len(pm.TradeLog) - 1; i >= 0; i-- {
+		if pm.TradeLog[i].Symbol == symbol {
+			pm.TradeLog[i].MAE, pm.TradeLog[i].MFE = mae, mfe
+			if !openedAt.IsZero() {
+				pm.TradeLog[i].Held = pm.TradeLog[i].Timestamp.Sub(openedAt)
+			}
+			return
+		}
+	}
+}
+
 // GetTradeLog returns the trade log
 func (pm *PortfolioManager) GetTradeLog() []TradeLogEntry {
 	return pm.TradeLog
@@ -418,6 +664,11 @@ func (pm *PortfolioManager) CalculatePerformanceMetrics() PerformanceMetrics {
 		}
 	}
 
+	pm.applyTradeStatistics(&metrics, pm.now())
+
+	// Prefer drawdown and risk-adjusted returns computed on actual equity
+	pm.applyEquityMetrics(&metrics)
+
 	// Update the stored metrics
 	pm.PerformanceMetrics = metrics
 
@@ -445,6 +696,59 @@ func (pm *PortfolioManager) GetSymbolPerformanceMetrics(symbol string) Performan
 	return tempPM.CalculatePerformanceMetrics()
 }
 
+// GetStrategyPerformanceMetrics returns performance metrics for trades made by a specific strategy
+func (pm *PortfolioManager) GetStrategyPerformanceMetrics(strategyType string) PerformanceMetrics {
+	var strategyTrades []TradeLogEntry
+	for _, trade := range pm.TradeLog {
+		if trade.Strategy == strategyType {
+			strategyTrades = append(strategyTrades, trade)
+		}
+	}
+
+	if len(strategyTrades) == 0 {
+		return PerformanceMetrics{}
+	}
+
+	// Create a temporary PortfolioManager for this strategy
+	tempPM := &PortfolioManager{
+		TradeLog: strategyTrades,
+	}
+
+	return tempPM.CalculatePerformanceMetrics()
+}
+
+// GetAllStrategyPerformanceMetrics returns performance metrics for every strategy in the trade log
+func (pm *PortfolioManager) GetAllStrategyPerformanceMetrics() map[string]PerformanceMetrics {
+	result := make(map[string]PerformanceMetrics)
+	for _, trade := range pm.TradeLog {
+		if _, exists := result[trade.Strategy]; !exists {
+			result[trade.Strategy] = pm.GetStrategyPerformanceMetrics(trade.Strategy)
+		}
+	}
+	return result
+}
+
+// GetRecentStrategyPerformanceMetrics returns performance metrics for every strategy of the
+// trades logged since a time
+func (pm *PortfolioManager) GetRecentStrategyPerformanceMetrics(since time.Time) map[string]PerformanceMetrics {
+	trades := make(map[string][]TradeLogEntry)
+	for _, trade := range pm.TradeLog {
+		if !trade.Timestamp.Before(since) {
+			trades[trade.Strategy] = append(trades[trade.Strategy], trade)
+		}
+	}
+
+	result := make(map[string]PerformanceMetrics, len(trades))
+	for strategyType, strategyTrades := range trades {
+		// Create a temporary PortfolioManager for this strategy's recent trades
+		tempPM := &PortfolioManager{
+			TradeLog: strategyTrades,
+		}
+		result[strategyType] = tempPM.CalculatePerformanceMetrics()
+	}
+	return result
+}
+
 // GetPerformanceSummary returns a summary of performance metrics
 func (pm *PortfolioManager) GetPerformanceSummary() string {
 	metrics := pm.CalculatePerformanceMetrics()
@@ -459,6 +763,33 @@ func (pm *PortfolioManager) GetPerformanceSummary() string {
 	summary += fmt.Sprintf("  Max Drawdown: $%.2f\n", metrics.MaxDrawdown)
 	summary += fmt.Sprintf("  Sharpe Ratio: %.2f\n", metrics.SharpeRatio)
 	summary += fmt.Sprintf("  Sortino Ratio: %.2f\n", metrics.SortinoRatio)
+	summary += fmt.Sprintf("  Calmar Ratio: %.2f\n", metrics.CalmarRatio)
+	if metrics.HasProfitFactor {
+		summary += fmt.Sprintf("  Profit Factor: %.2f\n", metrics.ProfitFactor)
+	} else {
+		summary += "  Profit Factor: undefined (no losing trades)\n"
+	}
+	summary += fmt.Sprintf("  Expectancy: $%.2f\n", metrics.Expectancy)
+	summary += fmt.Sprintf("  Longest Losing Streak: %d\n", metrics.LongestLosingStreak)
+	summary += fmt.Sprintf("  Time in Market: %.2f%%\n", metrics.TimeInMarket*100)
+	summary += fmt.Sprintf("  Benchmark Return: %.2f%%\n", metrics.BenchmarkReturn*100)
+	summary += fmt.Sprintf("  Alpha: %.2f%%\n", metrics.Alpha*100)
+	summary += fmt.Sprintf("  Tracking Error: %.2f%%\n", metrics.TrackingError*100)
+	summary += fmt.Sprintf("  Relative Drawdown: %.2f%%\n", metrics.RelativeDrawdown*100)
+
+	// Attribute performance to the strategies that produced it
+	strategyMetrics := pm.GetAllStrategyPerformanceMetrics()
+	strategies := make([]string, 0, len(strategyMetrics))
+	for strategy := range strategyMetrics {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+
+	for _, strategy := range strategies {
+		m := strategyMetrics[strategy]
+		summary += fmt.Sprintf("  [%s] Trades: %d, Win Rate: %.2f%%, PnL: $%.2f, Max Drawdown: $%.2f, Sharpe: %.2f\n",
+			strategy, m.TotalTrades, m.WinRate*100, m.TotalPnL, m.MaxDrawdown, m.SharpeRatio)
+	}
 
 	return summary
 }
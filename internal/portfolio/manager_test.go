@@ -0,0 +1,87 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestScaledAllocationsKeepCashReserve(t *testing.T) {
+	pm := &PortfolioManager{
+		Config: &config.Config{
+			TotalCapital:       10000,
+			CashReservePercent: 10,
+			AllocationMode:     config.AllocationEqual,
+		},
+		Symbols:     []string{"BTCUSDT", "ETHUSDT"},
+		Allocations: map[string]float64{"BTCUSDT": 0.5, "ETHUSDT": 0.5},
+	}
+
+	total := func(allocations map[string]float64) float64 {
+		sum := 0.0
+		for _, allocation := range allocations {
+			sum += allocation
+		}
+		return sum
+	}
+
+	if got := total(pm.GetScaledAllocations(2)); math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("doubled allocations total %v, want the deployable 0.9", got)
+	}
+	if got := total(pm.GetScaledAllocations(0.5)); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("halved allocations total %v, want 0.5", got)
+	}
+	if got := pm.GetOptimalAllocations()["BTCUSDT"]; math.Abs(got-0.45) > 1e-9 {
+		t.Errorf("optimal BTCUSDT allocation %v, want 0.45", got)
+	}
+}
+
+func TestOnTradeSkipsHolds(t *testing.T) {
+	pm := &PortfolioManager{
+		Config:     &config.Config{ReportingCurrency: "USDT"},
+		Cash:       1000,
+		Holdings:   make(map[string]float64),
+		LastPrices: make(map[string]float64),
+	}
+	var published []string
+	pm.OnTrade = func(entry TradeLogEntry) { published = append(published, entry.Action) }
+
+	pm.LogTrade("BTCUSDT", "HOLD", 0, 100, "momentum", 0.5, "Neutral conditions")
+	pm.LogTrade("BTCUSDT", "BUY", 1, 100, "momentum", 0.8, "Oversold")
+	pm.LogTrade("BTCUSDT", "HOLD", 0, 100, "momentum", 0.5, "Neutral conditions")
+	pm.LogTrade("BTCUSDT", "SELL", 1, 110, "momentum", 0.8, "Overbought")
+
+	if len(pm.TradeLog) != 4 {
+		t.Errorf("trade log has %d entries, want 4", len(pm.TradeLog))
+	}
+	if len(published) != 2 || published[0] != "BUY" || published[1] != "SELL" {
+		t.Errorf("published %v, want [BUY SELL]", published)
+	}
+}
+
+func TestOnTradeUpdateCarriesPnL(t *testing.T) {
+	pm := &PortfolioManager{
+		Config:     &config.Config{ReportingCurrency: "USDT"},
+		Cash:       1000,
+		Holdings:   make(map[string]float64),
+		LastPrices: make(map[string]float64),
+	}
+	var published, updated []TradeLogEntry
+	pm.OnTrade = func(entry TradeLogEntry) { published = append(published, entry) }
+	pm.OnTradeUpdate = func(entry TradeLogEntry) { updated = append(updated, entry) }
+
+	pm.LogTrade("BTCUSDT", "BUY", 1, 100, "momentum", 0.8, "Oversold")
+	pm.LogTrade("BTCUSDT", "SELL", 1, 110, "momentum", 0.8, "Overbought")
+	pm.UpdateTradePnL("BTCUSDT", 100, 110, 1, true)
+
+	if len(published) != 2 || published[1].PnL != 0 {
+		t.Fatalf("published %+v, want the BUY and SELL before the PnL is known", published)
+	}
+	if len(updated) != 1 {
+		t.Fatalf("updated %d trades, want 1", len(updated))
+	}
+	if updated[0].Action != "SELL" || updated[0].PnL != 10 || !updated[0].Timestamp.Equal(published[1].Timestamp) {
+		t.Errorf("update %+v, want the SELL with PnL 10", updated[0])
+	}
+}
@@ -0,0 +1,148 @@
+package portfolio
+
+import (
+	"math"
+	"time"
+)
+
+// hoursPerYear is used to annualize returns for the Calmar ratio
+const hoursPerYear = 365 * 24
+
+// applyTradeStatistics fills profit factor, expectancy, longest losing streak, exposure time
+// and a trade-based Calmar ratio from the trade log
+func (pm *PortfolioManager) applyTradeStatistics(metrics *PerformanceMetrics, now time.Time) {
+	grossProfit := 0.0
+	grossLoss := 0.0
+	closedTrades := 0
+	streak := 0
+
+	for _, trade := range pm.TradeLog {
+		// Trades without realized PnL are open entries and neither extend nor break a streak
+		switch {
+		case trade.PnL > 0:
+			grossProfit += trade.PnL
+			closedTrades++
+			streak = 0
+		case trade.PnL < 0:
+			grossLoss += math.Abs(trade.PnL)
+			closedTrades++
+			streak++
+			if streak > metrics.LongestLosingStreak {
+				metrics.LongestLosingStreak = streak
+			}
+		}
+	}
+
+	// Profit factor is undefined without losing trades to divide by
+	if grossLoss > 0 {
+		metrics.ProfitFactor = grossProfit / grossLoss
+		metrics.HasProfitFactor = true
+	}
+
+	// Expectancy is the average result of a closed trade
+	if closedTrades > 0 {
+		metrics.Expectancy = (grossProfit - grossLoss) / float64(closedTrades)
+	}
+
+	metrics.TimeInMarket = timeInMarket(pm.TradeLog, now)
+
+	// Calmar ratio from realized PnL relative to starting capital
+	if pm.Config != nil && pm.Config.TotalCapital > 0 && len(pm.TradeLog) > 0 {
+		years := now.Sub(pm.TradeLog[0].Timestamp).Hours() / hoursPerYear
+		metrics.CalmarRatio = calmarRatio(metrics.TotalPnL/pm.Config.TotalCapital,
+			metrics.MaxDrawdown/pm.Config.TotalCapital, years)
+	}
+}
+
+// calmarRatio divides the return by the maximum drawdown, both as fractions. The return is
+// annualized once the history spans at least a year; shorter histories use the return over the
+// period, as annualizing them would extrapolate a few days to absurd annual figures.
+func calmarRatio(totalReturn, maxDrawdown, years float64) float64 {
+	if maxDrawdown <= 0 || years <= 0 || totalReturn <= -1 {
+		return 0
+	}
+
+	if years < 1 {
+		return totalReturn / maxDrawdown
+	}
+
+	annualReturn := math.Pow(1+totalReturn, 1/years) - 1
+	return annualReturn / maxDrawdown
+}
+
+// timeInMarket returns the fraction of time since the first trade during which any position was held
+func timeInMarket(trades []TradeLogEntry, now time.Time) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+
+	start := trades[0].Timestamp
+	total := now.Sub(start)
+	if total <= 0 {
+		return 0
+	}
+
+	positions := make(map[string]float64)
+	openPositions := 0
+	var inMarket time.Duration
+	var enteredAt time.Time
+
+	for _, trade := range trades {
+		// Shorts are negative positions and count as invested too
+		before := math.Abs(positions[trade.Symbol]) > 1e-12
+		switch trade.Action {
+		case "BUY", "COVER":
+			positions[trade.Symbol] += trade.Quantity
+		case "SELL", "SHORT":
+			positions[trade.Symbol] -= trade.Quantity
+		default:
+			continue
+		}
+		after := math.Abs(positions[trade.Symbol]) > 1e-12
+
+		// Track transitions between flat and invested
+		if !before && after {
+			if openPositions == 0 {
+				enteredAt = trade.Timestamp
+			}
+			openPositions++
+		} else if before && !after {
+			openPositions--
+			if openPositions == 0 {
+				inMarket += trade.Timestamp.Sub(enteredAt)
+			}
+		}
+	}
+
+	if openPositions > 0 {
+		inMarket += now.Sub(enteredAt)
+	}
+
+	return inMarket.Seconds() / total.Seconds()
+}
+
+// applyEquityCalmar computes the Calmar ratio from the equity curve
+func applyEquityCalmar(metrics *PerformanceMetrics, curve []EquityPoint) {
+	first := curve[0]
+	last := curve[len(curve)-1]
+	if first.Equity <= 0 {
+		return
+	}
+
+	// Express the drawdown relative to the highest equity seen
+	peak := first.Equity
+	maxDrawdown := 0.0
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if peak > 0 {
+			if drawdown := (peak - point.Equity) / peak; drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+			}
+		}
+	}
+
+	years := last.Timestamp.Sub(first.Timestamp).Hours() / hoursPerYear
+	metrics.CalmarRatio = calmarRatio(last.Equity/first.Equity-1, maxDrawdown, years)
+}
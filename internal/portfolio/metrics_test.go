@@ -0,0 +1,100 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTradeStatistics(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	closes := func(pnls ...float64) []TradeLogEntry {
+		trades := make([]TradeLogEntry, len(pnls))
+		for i, pnl := range pnls {
+			trades[i] = TradeLogEntry{Timestamp: start.Add(time.Duration(i) * time.Hour), Symbol: "BTCUSDT", Action: "HOLD", PnL: pnl}
+		}
+		return trades
+	}
+
+	cases := []struct {
+		name            string
+		trades          []TradeLogEntry
+		profitFactor    float64
+		hasProfitFactor bool
+		expectancy      float64
+		losingStreak    int
+	}{
+		{"mixed", closes(30, -10, -5, 0, -5, 30, -10), 2, true, 5, 3},
+		{"all winners", closes(10, 20), 0, false, 15, 0},
+		{"all losers", closes(-10, -20), 0, true, -15, 2},
+		{"no closed trades", closes(0, 0), 0, false, 0, 0},
+	}
+	for _, tc := range cases {
+		pm := &PortfolioManager{TradeLog: tc.trades}
+		var metrics PerformanceMetrics
+		pm.applyTradeStatistics(&metrics, start.Add(24*time.Hour))
+		if math.Abs(metrics.ProfitFactor-tc.profitFactor) > 1e-9 || metrics.HasProfitFactor != tc.hasProfitFactor {
+			t.Errorf("%s: profit factor = %v (defined %t), want %v (defined %t)",
+				tc.name, metrics.ProfitFactor, metrics.HasProfitFactor, tc.profitFactor, tc.hasProfitFactor)
+		}
+		if math.Abs(metrics.Expectancy-tc.expectancy) > 1e-9 {
+			t.Errorf("%s: expectancy = %v, want %v", tc.name, metrics.Expectancy, tc.expectancy)
+		}
+		if metrics.LongestLosingStreak != tc.losingStreak {
+			t.Errorf("%s: longest losing streak = %d, want %d", tc.name, metrics.LongestLosingStreak, tc.losingStreak)
+		}
+	}
+}
+
+func TestTimeInMarket(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	trade := func(hours int, symbol, action string, quantity float64) TradeLogEntry {
+		return TradeLogEntry{Timestamp: at(hours), Symbol: symbol, Action: action, Quantity: quantity}
+	}
+
+	cases := []struct {
+		name   string
+		trades []TradeLogEntry
+		now    time.Time
+		want   float64
+	}{
+		{"no trades", nil, at(10), 0},
+		{"closed round trip", []TradeLogEntry{
+			trade(0, "BTCUSDT", "BUY", 1), trade(2, "BTCUSDT", "SELL", 1),
+		}, at(10), 0.2},
+		{"overlapping positions count once", []TradeLogEntry{
+			trade(0, "BTCUSDT", "BUY", 1), trade(1, "ETHUSDT", "SHORT", 2),
+			trade(3, "BTCUSDT", "SELL", 1), trade(5, "ETHUSDT", "COVER", 2),
+		}, at(10), 0.5},
+		{"partial close stays invested", []TradeLogEntry{
+			trade(0, "BTCUSDT", "BUY", 2), trade(4, "BTCUSDT", "SELL", 1),
+		}, at(8), 1},
+		{"holds are ignored", []TradeLogEntry{
+			trade(0, "BTCUSDT", "HOLD", 0), trade(5, "BTCUSDT", "BUY", 1),
+		}, at(10), 0.5},
+	}
+	for _, tc := range cases {
+		if got := timeInMarket(tc.trades, tc.now); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: timeInMarket = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCalmarRatio(t *testing.T) {
+	cases := []struct {
+		name                         string
+		totalReturn, drawdown, years float64
+		want                         float64
+	}{
+		{"under a year is not annualized", 0.1, 0.05, 0.5, 2},
+		{"two years is annualized", 0.21, 0.05, 2, 2},
+		{"no drawdown", 0.1, 0, 2, 0},
+		{"no history", 0.1, 0.05, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := calmarRatio(tc.totalReturn, tc.drawdown, tc.years); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: calmarRatio = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
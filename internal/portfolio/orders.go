@@ -0,0 +1,187 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// Order statuses tracked by the portfolio manager
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCancelled       = "CANCELLED"
+)
+
+// Policies for the unfilled remainder of a partially filled order
+const (
+	PartialFillPolicyCancel = "cancel" // Cancel the remainder after the timeout
+	PartialFillPolicyKeep   = "keep"   // Keep the remainder working on the exchange
+)
+
+// Fill represents a single execution against an order
+type Fill struct {
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OrderRecord tracks an order and the fills executed against it
+type OrderRecord struct {
+	OrderID        string    `json:"order_id"`
+	Symbol         string    `json:"symbol"`
+	Action         string    `json:"action"`
+	Quantity       float64   `json:"quantity"` // Requested quantity
+	FilledQuantity float64   `json:"filled_quantity"`
+	AvgFillPrice   float64   `json:"avg_fill_price"`
+	Status         string    `json:"status"`
+	Strategy       string    `json:"strategy"`
+	Confidence     float64   `json:"confidence"`
+	Reason         string    `json:"reason"`
+	Fills          []Fill    `json:"fills"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// RemainingQuantity returns the unfilled part of the order
+func (o *OrderRecord) RemainingQuantity() float64 {
+	return o.Quantity - o.FilledQuantity
+}
+
+// IsOpen reports whether the order can still receive fills
+func (o *OrderRecord) IsOpen() bool {
+	return o.Status == OrderStatusNew || o.Status == OrderStatusPartiallyFilled
+}
+
+// TrackOrder registers a newly placed order so that its fills can be aggregated
+func (pm *PortfolioManager) TrackOrder(orderID, symbol, action string, quantity float64, strategy string, confidence float64, reason string) *OrderRecord {
+	now := pm.now()
+	order := &OrderRecord{
+		OrderID:    orderID,
+		Symbol:     symbol,
+		Action:     action,
+		Quantity:   quantity,
+		Status:     OrderStatusNew,
+		Strategy:   strategy,
+		Confidence: confidence,
+		Reason:     reason,
+		Fills:      make([]Fill, 0),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	pm.Orders[orderID] = order
+	return order
+}
+
+// RecordFill applies a (possibly partial) fill to a tracked order. Holdings are updated on
+// every fill, and the order is written to the trade log once it is completely filled.
+func (pm *PortfolioManager) RecordFill(orderID string, quantity, price float64) error {
+	order, exists := pm.Orders[orderID]
+	if !exists {
+		return fmt.Errorf("unknown order %s", orderID)
+	}
+	if !order.IsOpen() {
+		return fmt.Errorf("order %s is already %s", orderID, order.Status)
+	}
+	if quantity <= 0 || price <= 0 {
+		return fmt.Errorf("invalid fill for order %s: quantity %.8f at price %.8f", orderID, quantity, price)
+	}
+
+	// Never fill more than what was requested
+	if quantity > order.RemainingQuantity() {
+		quantity = order.RemainingQuantity()
+	}
+
+	now := pm.now()
+	order.Fills = append(order.Fills, Fill{
+		Quantity:  quantity,
+		Price:     price,
+		Timestamp: now,
+	})
+
+	// Aggregate into a volume-weighted average fill price
+	filledValue := order.AvgFillPrice*order.FilledQuantity + price*quantity
+	order.FilledQuantity += quantity
+	order.AvgFillPrice = filledValue / order.FilledQuantity
+	order.UpdatedAt = now
+
+	pm.applyTradeToHoldings(order.Symbol, order.Action, quantity, price)
+
+	if order.RemainingQuantity() <= 1e-12 {
+		order.Status = OrderStatusFilled
+		pm.logOrder(order)
+	} else {
+		order.Status = OrderStatusPartiallyFilled
+	}
+
+	return nil
+}
+
+// CancelOrderRemainder marks the unfilled remainder of an order as cancelled and logs
+// whatever quantity was filled
+func (pm *PortfolioManager) CancelOrderRemainder(orderID string) error {
+	order, exists := pm.Orders[orderID]
+	if !exists {
+		return fmt.Errorf("unknown order %s", orderID)
+	}
+	if !order.IsOpen() {
+		return nil
+	}
+
+	order.Status = OrderStatusCancelled
+	order.UpdatedAt = pm.now()
+
+	if order.FilledQuantity > 0 {
+		pm.logOrder(order)
+	}
+
+	return nil
+}
+
+// GetOrdersToCancel returns partially filled orders whose remainder should be cancelled
+// according to the configured partial fill policy
+func (pm *PortfolioManager) GetOrdersToCancel(now time.Time) []*OrderRecord {
+	if pm.Config.PartialFillPolicy != PartialFillPolicyCancel {
+		return nil
+	}
+
+	timeout := time.Duration(pm.Config.PartialFillTimeoutSeconds) * time.Second
+
+	var orders []*OrderRecord
+	for _, order := range pm.Orders {
+		if order.Status == OrderStatusPartiallyFilled && now.Sub(order.CreatedAt) >= timeout {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders
+}
+
+// GetOpenOrders returns all tracked orders that can still receive fills
+func (pm *PortfolioManager) GetOpenOrders() []*OrderRecord {
+	var orders []*OrderRecord
+	for _, order := range pm.Orders {
+		if order.IsOpen() {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// logOrder writes the aggregated fills of an order to the trade log
+func (pm *PortfolioManager) logOrder(order *OrderRecord) {
+	pm.TradeLog = append(pm.TradeLog, TradeLogEntry{
+		Timestamp:         order.UpdatedAt,
+		Symbol:            order.Symbol,
+		Action:            order.Action,
+		Quantity:          order.FilledQuantity,
+		Price:             order.AvgFillPrice,
+		Strategy:          order.Strategy,
+		Confidence:        order.Confidence,
+		Reason:            order.Reason,
+		OrderID:           order.OrderID,
+		RequestedQuantity: order.Quantity,
+		FillCount:         len(order.Fills),
+	})
+}
@@ -0,0 +1,83 @@
+package portfolio
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTradeQueryLimit is the page size used when a query does not specify one
+const DefaultTradeQueryLimit = 50
+
+// TradeFilter selects trades from the trade log. Zero values disable the corresponding filter.
+type TradeFilter struct {
+	Symbol   string
+	Strategy string
+	Action   string
+	From     time.Time // Inclusive lower bound on the trade timestamp
+	To       time.Time // Inclusive upper bound on the trade timestamp
+	MinPnL   *float64
+	Offset   int
+	Limit    int
+}
+
+// TradeQueryResult holds one page of matching trades and the total number of matches
+type TradeQueryResult struct {
+	Trades []TradeLogEntry
+	Total  int
+	Offset int
+	Limit  int
+}
+
+// Matches reports whether a trade satisfies the filter
+func (f TradeFilter) Matches(trade TradeLogEntry) bool {
+	if f.Symbol != "" && !strings.EqualFold(trade.Symbol, f.Symbol) {
+		return false
+	}
+	if f.Strategy != "" && !strings.EqualFold(trade.Strategy, f.Strategy) {
+		return false
+	}
+	if f.Action != "" && !strings.EqualFold(trade.Action, f.Action) {
+		return false
+	}
+	if !f.From.IsZero() && trade.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && trade.Timestamp.After(f.To) {
+		return false
+	}
+	if f.MinPnL != nil && trade.PnL < *f.MinPnL {
+		return false
+	}
+	return true
+}
+
+// QueryTrades returns the trades matching the filter, most recent first, paginated by offset and limit
+func (pm *PortfolioManager) QueryTrades(filter TradeFilter) TradeQueryResult {
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultTradeQueryLimit
+	}
+
+	result := TradeQueryResult{
+		Trades: make([]TradeLogEntry, 0),
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+
+	// Walk the log backwards so that the newest trades come first
+	for i := len(pm.TradeLog) - 1; i >= 0; i-- {
+		trade := pm.TradeLog[i]
+		if !filter.Matches(trade) {
+			continue
+		}
+
+		if result.Total >= filter.Offset && len(result.Trades) < filter.Limit {
+			result.Trades = append(result.Trades, trade)
+		}
+		result.Total++
+	}
+
+	return result
+}
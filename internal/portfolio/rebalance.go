@@ -0,0 +1,77 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CurrentWeights returns each symbol's share of total equity at the given prices
+func (pm *PortfolioManager) CurrentWeights(currentPrices map[string]float64) map[string]float64 {
+	weights := make(map[string]float64)
+
+	equity := pm.MarkToMarket(currentPrices).Equity
+	if equity <= 0 {
+		return weights
+	}
+
+	for symbol, quantity := range pm.Holdings {
+		if value, ok := pm.ToReportingCurrency(symbol, quantity*pm.priceOf(symbol, currentPrices)); ok {
+			weights[symbol] = value / equity
+		}
+	}
+
+	return weights
+}
+
+// CheckRebalanceTrigger reports whether the portfolio should be rebalanced before the next
+// scheduled rebalance, because the symbol set changed or a weight drifted beyond the threshold
+func (pm *PortfolioManager) CheckRebalanceTrigger(ctx context.Context, currentPrices map[string]float64) (bool, string, error) {
+	if err := pm.UpdateTopCoins(ctx); err != nil {
+		return false, "", fmt.Errorf("failed to update top coins: %w", err)
+	}
+
+	// Symbol set changed since the last rebalance
+	if pm.LastRebalanceSymbols != nil && !sameSymbols(pm.Symbols, pm.LastRebalanceSymbols) {
+		return true, fmt.Sprintf("symbol set changed from %v to %v", pm.LastRebalanceSymbols, pm.Symbols), nil
+	}
+
+	threshold := pm.Config.RebalanceDriftThreshold
+	if threshold <= 0 {
+		return false, "", nil
+	}
+
+	// Weight drift against target allocations
+	weights := pm.CurrentWeights(currentPrices)
+	targets := pm.GetOptimalAllocations()
+	for _, symbol := range pm.Symbols {
+		drift := math.Abs(weights[symbol] - targets[symbol])
+		if drift > threshold {
+			return true, fmt.Sprintf("%s weight %.2f%% drifted %.2f%% from target %.2f%%",
+				symbol, weights[symbol]*100, drift*100, targets[symbol]*100), nil
+		}
+	}
+
+	return false, "", nil
+}
+
+// sameSymbols reports whether two symbol lists contain the same symbols, ignoring order
+func sameSymbols(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string(nil), a...)
+	sortedB := append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
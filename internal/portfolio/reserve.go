@@ -0,0 +1,34 @@
+package portfolio
+
+// CashReserveStatus describes the cash buffer that the allocator never deploys
+type CashReserveStatus struct {
+	Percent       float64 `json:"percent"`
+	TargetAmount  float64 `json:"target_amount"`
+	CurrentCash   float64 `json:"current_cash"`
+	AvailableCash float64 `json:"available_cash"` // Cash above the reserve
+	Shortfall     float64 `json:"shortfall"`      // How far cash is below the reserve
+}
+
+// DeployableFraction returns the fraction of capital the allocator is allowed to deploy
+func (pm *PortfolioManager) DeployableFraction() float64 {
+	return 1.0 - pm.Config.CashReservePercent/100
+}
+
+// GetCashReserve returns the current state of the cash reserve
+func (pm *PortfolioManager) GetCashReserve() CashReserveStatus {
+	target := pm.Config.TotalCapital * pm.Config.CashReservePercent / 100
+
+	status := CashReserveStatus{
+		Percent:      pm.Config.CashReservePercent,
+		TargetAmount: target,
+		CurrentCash:  pm.Cash,
+	}
+
+	if pm.Cash >= target {
+		status.AvailableCash = pm.Cash - target
+	} else {
+		status.Shortfall = target - pm.Cash
+	}
+
+	return status
+}
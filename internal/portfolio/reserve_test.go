@@ -0,0 +1,28 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestCashReserveReadsPercent(t *testing.T) {
+	pm := &PortfolioManager{
+		Config: &config.Config{TotalCapital: 10000, CashReservePercent: 10},
+		Cash:   800,
+	}
+
+	if got := pm.DeployableFraction(); math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("DeployableFraction() = %v, want 0.9", got)
+	}
+	status := pm.GetCashReserve()
+	if status.TargetAmount != 1000 || status.Shortfall != 200 || status.AvailableCash != 0 {
+		t.Errorf("GetCashReserve() = %+v, want a target of 1000 and a shortfall of 200", status)
+	}
+
+	pm.Cash = 1500
+	if status := pm.GetCashReserve(); status.AvailableCash != 500 || status.Shortfall != 0 {
+		t.Errorf("GetCashReserve() = %+v, want 500 available above the reserve", status)
+	}
+}
@@ -0,0 +1,230 @@
+package portfolio
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// shadowStateFile is the file name of the shadow ledger snapshot inside the data directory
+const shadowStateFile = "shadow_ledger.json"
+
+// maxShadowFills is the number of hypothetical fills kept in the ledger
+const maxShadowFills = 1000
+
+// ShadowFill is a hypothetical fill of a signal of a strategy running in shadow mode
+type ShadowFill struct {
+	Strategy  string    `json:"strategy"`
+	Symbol    string    `json:"symbol"`
+	Side      string    `json:"side"` // BUY opens or adds to a position, SELL closes it
+	Quantity  float64   `json:"quantity"`
+	Price     float64   `json:"price"`
+	Fee       float64   `json:"fee"`
+	PnL       float64   `json:"pnl"` // Realized PnL net of fees, set on closing fills
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ShadowPosition is a hypothetical long position of a shadow strategy
+type ShadowPosition struct {
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	EntryFees  float64   `json:"entry_fees"`
+	OpenedAt   time.Time `json:"opened_at"`
+}
+
+// ShadowSummary is the hypothetical performance of a shadow strategy
+type ShadowSummary struct {
+	Strategy      string  `json:"strategy"`
+	Fills         int     `json:"fills"`
+	Trades        int     `json:"trades"` // Closed positions
+	Wins          int     `json:"wins"`
+	WinRate       float64 `json:"win_rate"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Fees          float64 `json:"fees"`
+	OpenPositions int     `json:"open_positions"`
+}
+
+// ShadowLedger records the signals of strategies running in shadow mode as hypothetical fills,
+// separate from the real portfolio, so new strategies can be evaluated live without sending
+// orders. Every signal is assumed to fill completely at the signal price.
+type ShadowLedger struct {
+	mutex      sync.Mutex
+	FeePercent float64                               // Fee charged on every hypothetical fill
+	Positions  map[string]map[string]*ShadowPosition // strategy -> symbol -> position
+	Fills      []ShadowFill
+	Summaries  map[string]*ShadowSummary // Realized results by strategy
+}
+
+// shadowSnapshot is the persisted state of the shadow ledger
+type shadowSnapshot struct {
+	SavedAt   time.Time                             `json:"saved_at"`
+	Positions map[string]map[string]*ShadowPosition `json:"positions"`
+	Fills     []ShadowFill                          `json:"fills"`
+	Summaries map[string]*ShadowSummary             `json:"summaries"`
+}
+
+// NewShadowLedger creates a new ShadowLedger
+func NewShadowLedger(feePercent float64) *ShadowLedger {
+	return &ShadowLedger{
+		FeePercent: feePercent,
+		Positions:  make(map[string]map[string]*ShadowPosition),
+		Summaries:  make(map[string]*ShadowSummary),
+	}
+}
+
+// RecordSignal records the hypothetical fill of a shadow strategy's signal: a BUY opens or adds
+// to the strategy's position in the symbol and a SELL closes it. Signals that can not be filled,
+// such as a SELL without a position, return false.
+func (sl *ShadowLedger) RecordSignal(strategy, symbol, action string, quantity, price float64, reason string, now time.Time) (ShadowFill, bool) {
+	if quantity <= 0 || price <= 0 {
+		return ShadowFill{}, false
+	}
+
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	positions, exists := sl.Positions[strategy]
+	if !exists {
+		positions = make(map[string]*ShadowPosition)
+		sl.Positions[strategy] = positions
+	}
+	summary := sl.summary(strategy)
+	position := positions[symbol]
+
+	fill := ShadowFill{Strategy: strategy, Symbol: symbol, Side: action, Price: price, Reason: reason, Timestamp: now}
+	switch action {
+	case "BUY":
+		fill.Quantity = quantity
+		fill.Fee = quantity * price * sl.FeePercent / 100
+		if position == nil {
+			position = &ShadowPosition{OpenedAt: now}
+			positions[symbol] = position
+		}
+		total := position.Quantity + quantity
+		position.EntryPrice = (position.EntryPrice*position.Quantity + price*quantity) / total
+		position.Quantity = total
+		position.EntryFees += fill.Fee
+	case "SELL":
+		if position == nil {
+			return ShadowFill{}, false
+		}
+		fill.Quantity = position.Quantity
+		fill.Fee = position.Quantity * price * sl.FeePercent / 100
+		fill.PnL = (price-position.EntryPrice)*position.Quantity - position.EntryFees - fill.Fee
+		delete(positions, symbol)
+
+		summary.Trades++
+		if fill.PnL > 0 {
+			summary.Wins++
+		}
+		summary.RealizedPnL += fill.PnL
+	default:
+		return ShadowFill{}, false
+	}
+
+	summary.Fills++
+	summary.Fees += fill.Fee
+	sl.Fills = append(sl.Fills, fill)
+	if len(sl.Fills) > maxShadowFills {
+		sl.Fills = sl.Fills[len(sl.Fills)-maxShadowFills:]
+	}
+
+	return fill, true
+}
+
+// summary returns the realized results of a strategy, creating them on first use. The caller
+// must hold the lock.
+func (sl *ShadowLedger) summary(strategy string) *ShadowSummary {
+	summary, exists := sl.Summaries[strategy]
+	if !exists {
+		summary = &ShadowSummary{Strategy: strategy}
+		sl.Summaries[strategy] = summary
+	}
+	return summary
+}
+
+// GetSummaries returns the hypothetical performance of every shadow strategy, with open
+// positions marked to the given prices, sorted by strategy
+func (sl *ShadowLedger) GetSummaries(prices map[string]float64) []ShadowSummary {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	summaries := make([]ShadowSummary, 0, len(sl.Summaries))
+	for strategy, realized := range sl.Summaries {
+		summary := *realized
+		if summary.Trades > 0 {
+			summary.WinRate = float64(summary.Wins) / float64(summary.Trades)
+		}
+		for symbol, position := range sl.Positions[strategy] {
+			summary.OpenPositions++
+			if price, exists := prices[symbol]; exists && price > 0 {
+				summary.UnrealizedPnL += (price-position.EntryPrice)*position.Quantity - position.EntryFees
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Strategy < summaries[j].Strategy })
+
+	return summaries
+}
+
+// GetFills returns the most recent hypothetical fills, newest last
+func (sl *ShadowLedger) GetFills(limit int) []ShadowFill {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	start := 0
+	if limit > 0 && len(sl.Fills) > limit {
+		start = len(sl.Fills) - limit
+	}
+	fills := make([]ShadowFill, len(sl.Fills)-start)
+	copy(fills, sl.Fills[start:])
+	return fills
+}
+
+// ShadowStatePath returns the path of the shadow ledger snapshot inside a data directory
+func ShadowStatePath(dataDir string) string {
+	return filepath.Join(dataDir, shadowStateFile)
+}
+
+// SaveState writes the shadow positions, fills and results to disk
+func (sl *ShadowLedger) SaveState(path string) error {
+	sl.mutex.Lock()
+	snapshot := shadowSnapshot{SavedAt: time.Now(), Positions: sl.Positions, Fills: sl.Fills, Summaries: sl.Summaries}
+	err := persistence.SaveJSON(path, snapshot)
+	sl.mutex.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to save shadow ledger: %w", err)
+	}
+	return nil
+}
+
+// LoadState restores the shadow positions, fills and results from the last snapshot, if one exists
+func (sl *ShadowLedger) LoadState(path string) error {
+	var snapshot shadowSnapshot
+	found, err := persistence.LoadJSON(path, &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to load shadow ledger: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	if snapshot.Positions != nil {
+		sl.Positions = snapshot.Positions
+	}
+	sl.Fills = snapshot.Fills
+	if snapshot.Summaries != nil {
+		sl.Summaries = snapshot.Summaries
+	}
+	return nil
+}
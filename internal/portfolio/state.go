@@ -0,0 +1,92 @@
+package portfolio
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// stateFile is the file name of the portfolio snapshot inside the data directory
+const stateFile = "portfolio_state.json"
+
+// PortfolioSnapshot is the persisted state of the portfolio manager
+type PortfolioSnapshot struct {
+	SavedAt     time.Time          `json:"saved_at"`
+	Symbols     []string           `json:"symbols"`
+	Allocations map[string]float64 `json:"allocations"`
+	Performance map[string]float64 `json:"performance"`
+	Cash        float64            `json:"cash"`
+	Holdings    map[string]float64 `json:"holdings"`
+	LastPrices  map[string]float64 `json:"last_prices"`
+	TradeLog    []TradeLogEntry    `json:"trade_log"`
+	Benchmark   *BenchmarkState    `json:"benchmark,omitempty"`
+	OpenOrders  []*OrderRecord     `json:"open_orders,omitempty"`
+}
+
+// SaveState writes a snapshot of the portfolio state to disk
+func (pm *PortfolioManager) SaveState() error {
+	snapshot := PortfolioSnapshot{
+		SavedAt:     time.Now(),
+		Symbols:     pm.Symbols,
+		Allocations: pm.Allocations,
+		Performance: pm.Performance,
+		Cash:        pm.Cash,
+		Holdings:    pm.Holdings,
+		LastPrices:  pm.LastPrices,
+		TradeLog:    pm.TradeLog,
+		Benchmark:   pm.Benchmark,
+		OpenOrders:  pm.GetOpenOrders(),
+	}
+
+	if err := persistence.SaveJSON(pm.statePath(), snapshot); err != nil {
+		return fmt.Errorf("failed to save portfolio state: %w", err)
+	}
+
+	return nil
+}
+
+// LoadState restores the portfolio state from the last snapshot, if one exists
+func (pm *PortfolioManager) LoadState() error {
+	var snapshot PortfolioSnapshot
+	found, err := persistence.LoadJSON(pm.statePath(), &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to load portfolio state: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	if snapshot.Symbols != nil {
+		pm.Symbols = snapshot.Symbols
+	}
+	if snapshot.Allocations != nil {
+		pm.Allocations = snapshot.Allocations
+	}
+	if snapshot.Performance != nil {
+		pm.Performance = snapshot.Performance
+	}
+	if snapshot.Holdings != nil {
+		pm.Holdings = snapshot.Holdings
+	}
+	if snapshot.LastPrices != nil {
+		pm.LastPrices = snapshot.LastPrices
+	}
+	pm.Cash = snapshot.Cash
+	pm.TradeLog = snapshot.TradeLog
+	pm.Benchmark = snapshot.Benchmark
+	for _, order := range snapshot.OpenOrders {
+		pm.Orders[order.OrderID] = order
+	}
+
+	fmt.Printf("Restored portfolio state from %s (%d trades, %d holdings)\n",
+		snapshot.SavedAt.Format("2006-01-02 15:04:05"), len(pm.TradeLog), len(pm.Holdings))
+
+	return nil
+}
+
+// statePath returns the location of the persisted portfolio snapshot
+func (pm *PortfolioManager) statePath() string {
+	return filepath.Join(pm.Config.DataDir, stateFile)
+}
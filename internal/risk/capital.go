@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// CapitalBucket is the share of total capital assigned to a strategy and how much of it the
+// strategy's positions use
+type CapitalBucket struct {
+	Strategy    string  `json:"strategy"`
+	Fraction    float64 `json:"fraction"` // Configured share of total capital
+	Budget      float64 `json:"budget"`
+	Used        float64 `json:"used"`
+	Utilization float64 `json:"utilization_percent"` // Used as a percentage of the budget
+}
+
+// StrategyCapital returns the capital bucket of a strategy. The second result is false if the
+// strategy has no bucket and may use the whole book.
+func (rm *RiskManager) StrategyCapital(strategy string) (float64, bool) {
+	if strategy == "" {
+		return 0, false
+	}
+	fraction, ok := config.SymbolValue(rm.Config.StrategyCapital, strings.ToUpper(strategy))
+	if !ok {
+		return 0, false
+	}
+	return fraction * rm.Config.TotalCapital, true
+}
+
+// GetStrategyExposure returns the total position value per strategy that opened the positions
+func (rm *RiskManager) GetStrategyExposure() map[string]float64 {
+	exposure := make(map[string]float64)
+	for symbol, pos := range rm.Positions {
+		exposure[pos.Strategy] += rm.positionValue(symbol)
+	}
+	return exposure
+}
+
+// StrategyHeadroom returns how much order value a strategy can still add before its capital
+// bucket is used up. The second result is false if the strategy has no bucket.
+func (rm *RiskManager) StrategyHeadroom(strategy string) (float64, bool) {
+	budget, ok := rm.StrategyCapital(strategy)
+	if !ok {
+		return 0, false
+	}
+	return budget - rm.GetStrategyExposure()[strategy], true
+}
+
+// GetCapitalBuckets returns the utilization of every strategy with a capital bucket or
+// open positions, sorted by strategy
+func (rm *RiskManager) GetCapitalBuckets() []CapitalBucket {
+	exposure := rm.GetStrategyExposure()
+
+	strategies := make(map[string]bool)
+	for strategy := range exposure {
+		if strategy != "" {
+			strategies[strategy] = true
+		}
+	}
+	for key := range rm.Config.StrategyCapital {
+		if key != "*" {
+			strategies[strings.ToLower(key)] = true
+		}
+	}
+
+	usage := make([]CapitalBucket, 0, len(strategies))
+	for strategy := range strategies {
+		entry := CapitalBucket{Strategy: strategy, Used: exposure[strategy]}
+		if budget, ok := rm.StrategyCapital(strategy); ok {
+			entry.Budget = budget
+			if rm.Config.TotalCapital > 0 {
+				entry.Fraction = budget / rm.Config.TotalCapital
+			}
+			if budget > 0 {
+				entry.Utilization = entry.Used / budget * 100
+			}
+		}
+		usage = append(usage, entry)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Strategy < usage[j].Strategy })
+
+	return usage
+}
@@ -0,0 +1,57 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// UncategorizedCategory is the category of symbols without a configured category
+const UncategorizedCategory = "UNCATEGORIZED"
+
+// SymbolCategory returns the configured category of a symbol
+func (rm *RiskManager) SymbolCategory(symbol string) string {
+	if category, exists := rm.Config.SymbolCategories[symbol]; exists {
+		return category
+	}
+	return UncategorizedCategory
+}
+
+// GetCategoryExposure returns the total position value per category
+func (rm *RiskManager) GetCategoryExposure() map[string]float64 {
+	exposure := make(map[string]float64)
+	for symbol := range rm.Positions {
+		exposure[rm.SymbolCategory(symbol)] += rm.positionValue(symbol)
+	}
+	return exposure
+}
+
+// CheckCategoryExposure returns an error if adding orderValue to symbol's category would exceed
+// the category's maximum share of capital
+func (rm *RiskManager) CheckCategoryExposure(symbol string, orderValue float64) error {
+	category := rm.SymbolCategory(symbol)
+	limit, ok := config.SymbolValue(rm.Config.CategoryLimits, category)
+	if !ok {
+		return nil
+	}
+
+	maxExposure := limit * rm.Config.TotalCapital
+	currentExposure := rm.GetCategoryExposure()[category]
+	if currentExposure+orderValue > maxExposure {
+		return fmt.Errorf("category %s exposure %.2f + new %.2f would exceed limit %.2f (%.0f%% of capital)",
+			category, currentExposure, orderValue, maxExposure, limit*100)
+	}
+
+	return nil
+}
+
+// CategoryHeadroom returns how much order value the symbol's category can still take before
+// reaching its limit. The second result is false if the category has no limit.
+func (rm *RiskManager) CategoryHeadroom(symbol string) (float64, bool) {
+	category := rm.SymbolCategory(symbol)
+	limit, ok := config.SymbolValue(rm.Config.CategoryLimits, category)
+	if !ok {
+		return 0, false
+	}
+	return limit*rm.Config.TotalCapital - rm.GetCategoryExposure()[category], true
+}
@@ -5,14 +5,53 @@ import (
 	"time"
 )
 
+// StateChangeFunc is called after a circuit breaker moves from one state to another
+type StateChangeFunc func(name, from, to string)
+
 // CircuitBreaker implements the circuit breaker pattern for API calls
 type CircuitBreaker struct {
 	mutex            sync.RWMutex
+	name             string
 	state            string // "closed", "open", "half-open"
 	failureCount     int
 	lastFailure      time.Time
 	timeout          time.Duration
 	failureThreshold int
+	// Half-open probing: how many trial calls are let through and how many must succeed to close
+	halfOpenMaxCalls  int
+	successThreshold  int
+	halfOpenCalls     int
+	halfOpenSuccesses int
+	// Lifetime counters for monitoring
+	totalSuccesses  int
+	totalFailures   int
+	totalRejected   int
+	lastStateChange time.Time
+	// Transitions recorded during a call, reported once the lock is released
+	pendingTransitions [][2]string
+	// OnStateChange is notified of every state transition
+	OnStateChange StateChangeFunc
+}
+
+// CircuitBreakerSettings holds the tunable parameters of a circuit breaker
+type CircuitBreakerSettings struct {
+	TimeoutSeconds   float64 `json:"timeout_seconds"`   // How long the breaker stays open before probing
+	FailureThreshold int     `json:"failure_threshold"` // Consecutive failures that open the breaker
+	HalfOpenMaxCalls int     `json:"half_open_max_calls"`
+	SuccessThreshold int     `json:"success_threshold"` // Successful probes needed to close
+}
+
+// CircuitBreakerStats is a snapshot of a circuit breaker's state and counters
+type CircuitBreakerStats struct {
+	Name            string                 `json:"name"`
+	State           string                 `json:"state"`
+	FailureCount    int                    `json:"failure_count"` // Consecutive failures
+	TotalSuccesses  int                    `json:"total_successes"`
+	TotalFailures   int                    `json:"total_failures"`
+	TotalRejected   int                    `json:"total_rejected"` // Calls rejected while open
+	LastFailure     time.Time              `json:"last_failure"`
+	LastStateChange time.Time              `json:"last_state_change"`
+	Settings        CircuitBreakerSettings `json:"settings"`
 }
 
 // NewCircuitBreaker creates a new CircuitBreaker
@@ -22,11 +61,86 @@ func NewCircuitBreaker(timeout time.Duration, failureThreshold int) *CircuitBrea
 		failureCount:     0,
 		timeout:          timeout,
 		failureThreshold: failureThreshold,
+		halfOpenMaxCalls: 1,
+		successThreshold: 1,
+	}
+}
+
+// Configure changes the breaker's parameters at runtime. Non-positive values keep the current
+// setting and the success threshold is capped at the number of half-open probe calls.
+func (cb *CircuitBreaker) Configure(settings CircuitBreakerSettings) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if settings.TimeoutSeconds > 0 {
+		cb.timeout = time.Duration(settings.TimeoutSeconds * float64(time.Second))
+	}
+	if settings.FailureThreshold > 0 {
+		cb.failureThreshold = settings.FailureThreshold
+	}
+	if settings.HalfOpenMaxCalls > 0 {
+		cb.halfOpenMaxCalls = settings.HalfOpenMaxCalls
+	}
+	if settings.SuccessThreshold > 0 {
+		cb.successThreshold = settings.SuccessThreshold
+	}
+	if cb.successThreshold > cb.halfOpenMaxCalls {
+		cb.successThreshold = cb.halfOpenMaxCalls
+	}
+}
+
+// Settings returns the breaker's current parameters
+func (cb *CircuitBreaker) Settings() CircuitBreakerSettings {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return CircuitBreakerSettings{
+		TimeoutSeconds:   cb.timeout.Seconds(),
+		FailureThreshold: cb.failureThreshold,
+		HalfOpenMaxCalls: cb.halfOpenMaxCalls,
+		SuccessThreshold: cb.successThreshold,
 	}
 }
 
-// Call executes a function with circuit breaker protection
+// Reset force-closes the breaker and clears its consecutive failure count
+func (cb *CircuitBreaker) Reset() {
+	cb.mutex.Lock()
+	cb.setState("closed")
+	cb.failureCount = 0
+	cb.mutex.Unlock()
+
+	cb.notifyTransitions()
+}
+
+// Call executes a function with circuit breaker protection. The function runs without the
+// breaker's lock, so slow calls do not block other callers or the breaker's state queries.
 func (cb *CircuitBreaker) Call(fn func() error) error {
+	probe, err := cb.admit()
+	if err == nil {
+		err = fn()
+		cb.record(err, probe)
+	}
+	cb.notifyTransitions()
+	return err
+}
+
+// notifyTransitions reports recorded state changes outside the lock so callbacks may query the breaker
+func (cb *CircuitBreaker) notifyTransitions() {
+	cb.mutex.Lock()
+	transitions := cb.pendingTransitions
+	cb.pendingTransitions = nil
+	callback := cb.OnStateChange
+	cb.mutex.Unlock()
+
+	if callback != nil {
+		for _, transition := range transitions {
+			callback(cb.name, transition[0], transition[1])
+		}
+	}
+}
+
+// admit decides whether a call may run, returning an error if the breaker rejects it and
+// whether the call is a half-open probe
+func (cb *CircuitBreaker) admit() (bool, error) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
@@ -35,46 +149,80 @@ func (cb *CircuitBreaker) Call(fn func() error) error {
 		// Check if timeout has passed
 		if time.Since(cb.lastFailure) > cb.timeout {
 			// Move to half-open state
-			cb.state = "half-open"
+			cb.setState("half-open")
 		} else {
-			return &CircuitBreakerOpenError{}
+			cb.totalRejected++
+			return false, &CircuitBreakerOpenError{}
 		}
 	}
 
-	// Execute the function
-	err := fn()
-
-	// Handle result based on current state
+	// Only a limited number of probe calls are let through while half-open
 	if cb.state == "half-open" {
+		if cb.halfOpenCalls >= cb.halfOpenMaxCalls {
+			cb.totalRejected++
+			return false, &CircuitBreakerOpenError{}
+		}
+		cb.halfOpenCalls++
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// record updates the breaker with the outcome of an admitted call. The state may have changed
+// while the call ran, so the outcome applies to the current state.
+func (cb *CircuitBreaker) record(err error, probe bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if err != nil {
+		cb.totalFailures++
+	} else {
+		cb.totalSuccesses++
+	}
+
+	switch cb.state {
+	case "half-open":
 		if err != nil {
 			// Failed again, open circuit
-			cb.state = "open"
+			cb.setState("open")
 			cb.lastFailure = time.Now()
-			return err
+		} else if probe {
+			// Close the circuit once enough probes have succeeded
+			cb.halfOpenSuccesses++
+			if cb.halfOpenSuccesses >= cb.successThreshold {
+				cb.setState("closed")
+				cb.failureCount = 0
+			}
+		}
+	case "closed":
+		if err != nil {
+			cb.failureCount++
+			cb.lastFailure = time.Now()
+
+			// Check if we should open the circuit
+			if cb.failureCount >= cb.failureThreshold {
+				cb.setState("open")
+			}
 		} else {
-			// Success, close circuit
-			cb.state = "closed"
+			// Success, reset failure count
 			cb.failureCount = 0
-			return nil
 		}
 	}
+}
 
-	// Handle result in closed state
-	if err != nil {
-		cb.failureCount++
-		cb.lastFailure = time.Now()
-
-		// Check if we should open the circuit
-		if cb.failureCount >= cb.failureThreshold {
-			cb.state = "open"
-		}
-
-		return err
-	} else {
-		// Success, reset failure count
-		cb.failureCount = 0
-		return nil
+// setState moves the breaker to a new state and records the transition. The caller must hold the lock.
+func (cb *CircuitBreaker) setState(state string) {
+	if cb.state == state {
+		return
 	}
+	cb.pendingTransitions = append(cb.pendingTransitions, [2]string{cb.state, state})
+	cb.state = state
+	cb.lastStateChange = time.Now()
+
+	// Every half-open period starts with a fresh probe budget
+	cb.halfOpenCalls = 0
+	cb.halfOpenSuccesses = 0
 }
 
 // State returns the current state of the circuit breaker
@@ -84,6 +232,25 @@ func (cb *CircuitBreaker) State() string {
 	return cb.state
 }
 
+// Stats returns a snapshot of the circuit breaker's state, counters and settings
+func (cb *CircuitBreaker) Stats() CircuitBreakerStats {
+	settings := cb.Settings()
+
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return CircuitBreakerStats{
+		Name:            cb.name,
+		State:           cb.state,
+		FailureCount:    cb.failureCount,
+		TotalSuccesses:  cb.totalSuccesses,
+		TotalFailures:   cb.totalFailures,
+		TotalRejected:   cb.totalRejected,
+		LastFailure:     cb.lastFailure,
+		LastStateChange: cb.lastStateChange,
+		Settings:        settings,
+	}
+}
+
 // CircuitBreakerOpenError represents an error when the circuit breaker is open
 type CircuitBreakerOpenError struct{}
 
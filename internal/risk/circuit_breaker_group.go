@@ -0,0 +1,162 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Endpoint categories guarded by separate circuit breakers
+const (
+	EndpointMarketData = "market_data" // Klines, tickers, instruments and top coins
+	EndpointOrders     = "orders"      // Order placement, amendment and cancellation
+	EndpointAccount    = "account"     // Positions and balances
+)
+
+// CircuitBreakerGroup keeps one circuit breaker per endpoint category, so failures of one
+// category (e.g. market data) do not block calls to another (e.g. order placement)
+type CircuitBreakerGroup struct {
+	mutex    sync.Mutex
+	breakers map[string]*CircuitBreaker
+	settings CircuitBreakerSettings // Applied to every breaker, including ones created later
+	// OnStateChange is notified of state transitions of every breaker in the group
+	OnStateChange StateChangeFunc
+}
+
+// NewCircuitBreakerGroup creates a new CircuitBreakerGroup whose breakers share the timeout and threshold
+func NewCircuitBreakerGroup(timeout time.Duration, failureThreshold int) *CircuitBreakerGroup {
+	g := &CircuitBreakerGroup{
+		breakers: make(map[string]*CircuitBreaker),
+		settings: CircuitBreakerSettings{
+			TimeoutSeconds:   timeout.Seconds(),
+			FailureThreshold: failureThreshold,
+			HalfOpenMaxCalls: 1,
+			SuccessThreshold: 1,
+		},
+	}
+
+	// Create the standard categories up front so they are always reported
+	for _, name := range []string{EndpointMarketData, EndpointOrders, EndpointAccount} {
+		g.Get(name)
+	}
+
+	return g
+}
+
+// Get returns the circuit breaker for an endpoint category, creating it on first use
+func (g *CircuitBreakerGroup) Get(name string) *CircuitBreaker {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if cb, exists := g.breakers[name]; exists {
+		return cb
+	}
+
+	cb := NewCircuitBreaker(time.Duration(g.settings.TimeoutSeconds*float64(time.Second)), g.settings.FailureThreshold)
+	cb.Configure(g.settings)
+	cb.name = name
+	cb.OnStateChange = func(name, from, to string) {
+		if g.OnStateChange != nil {
+			g.OnStateChange(name, from, to)
+		}
+	}
+	g.breakers[name] = cb
+	return cb
+}
+
+// Call executes a function with the protection of the endpoint category's circuit breaker
+func (g *CircuitBreakerGroup) Call(name string, fn func() error) error {
+	return g.Get(name).Call(fn)
+}
+
+// State returns the state of the endpoint category's circuit breaker
+func (g *CircuitBreakerGroup) State(name string) string {
+	return g.Get(name).State()
+}
+
+// Configure changes the settings of every breaker in the group, or of a single breaker if
+// name is not empty. Non-positive values keep the current setting.
+func (g *CircuitBreakerGroup) Configure(name string, settings CircuitBreakerSettings) error {
+	if name != "" {
+		cb, err := g.lookup(name)
+		if err != nil {
+			return err
+		}
+		cb.Configure(settings)
+		return nil
+	}
+
+	g.mutex.Lock()
+	if settings.TimeoutSeconds > 0 {
+		g.settings.TimeoutSeconds = settings.TimeoutSeconds
+	}
+	if settings.FailureThreshold > 0 {
+		g.settings.FailureThreshold = settings.FailureThreshold
+	}
+	if settings.HalfOpenMaxCalls > 0 {
+		g.settings.HalfOpenMaxCalls = settings.HalfOpenMaxCalls
+	}
+	if settings.SuccessThreshold > 0 {
+		g.settings.SuccessThreshold = settings.SuccessThreshold
+	}
+	g.mutex.Unlock()
+
+	for _, cb := range g.all() {
+		cb.Configure(settings)
+	}
+	return nil
+}
+
+// Reset force-closes a breaker of the group, or every breaker if name is empty
+func (g *CircuitBreakerGroup) Reset(name string) error {
+	if name != "" {
+		cb, err := g.lookup(name)
+		if err != nil {
+			return err
+		}
+		cb.Reset()
+		return nil
+	}
+
+	for _, cb := range g.all() {
+		cb.Reset()
+	}
+	return nil
+}
+
+// lookup returns an existing breaker of the group
+func (g *CircuitBreakerGroup) lookup(name string) (*CircuitBreaker, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	cb, exists := g.breakers[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown circuit breaker %q", name)
+	}
+	return cb, nil
+}
+
+// all returns every breaker of the group
+func (g *CircuitBreakerGroup) all() []*CircuitBreaker {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	breakers := make([]*CircuitBreaker, 0, len(g.breakers))
+	for _, cb := range g.breakers {
+		breakers = append(breakers, cb)
+	}
+	return breakers
+}
+
+// Stats returns the state and counters of every breaker in the group, sorted by name
+func (g *CircuitBreakerGroup) Stats() []CircuitBreakerStats {
+	breakers := g.all()
+	stats := make([]CircuitBreakerStats, 0, len(breakers))
+	for _, cb := range breakers {
+		stats = append(stats, cb.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+
+	return stats
+}
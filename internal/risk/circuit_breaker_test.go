@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerRunsCallsUnlocked(t *testing.T) {
+	cb := NewCircuitBreaker(time.Minute, 3)
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- cb.Call(func() error {
+			<-release
+			return nil
+		})
+	}()
+
+	// A second call and the state query complete while the first call is still running
+	queried := make(chan struct{})
+	go func() {
+		cb.State()
+		cb.Call(func() error { return nil })
+		close(queried)
+	}()
+	select {
+	case <-queried:
+	case <-time.After(time.Second):
+		t.Fatal("breaker blocked while a call was running")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("slow call: %v", err)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	cb := NewCircuitBreaker(10*time.Millisecond, 2)
+	failure := errors.New("timeout")
+
+	for i := 0; i < 2; i++ {
+		cb.Call(func() error { return failure })
+	}
+	if cb.State() != "open" {
+		t.Fatalf("state after 2 failures = %s, want open", cb.State())
+	}
+	var openErr *CircuitBreakerOpenError
+	if err := cb.Call(func() error { return nil }); !errors.As(err, &openErr) {
+		t.Fatalf("call while open: got %v, want a rejection", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := cb.Call(func() error { return nil }); err != nil {
+		t.Fatalf("probe after the timeout: %v", err)
+	}
+	if stats := cb.Stats(); stats.State != "closed" || stats.TotalRejected != 1 || stats.TotalFailures != 2 {
+		t.Errorf("stats after recovery = %+v, want closed with 1 rejection and 2 failures", stats)
+	}
+}
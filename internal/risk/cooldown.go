@@ -0,0 +1,134 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RiskEventStrategyCooldown is emitted when a strategy is disabled after a drawdown
+const RiskEventStrategyCooldown = "STRATEGY_COOLDOWN"
+
+// StrategyCooldown records a strategy disabled after its rolling PnL fell below the drawdown
+// limit. While it cools down its signals are followed on paper, and a profitable paper record
+// re-enables it before the window ends.
+type StrategyCooldown struct {
+	Strategy    string    `json:"strategy"`
+	RollingPnL  float64   `json:"rolling_pnl"` // Realized PnL within the window when the cooldown started
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	PaperReturn float64   `json:"paper_return"` // Summed return of the paper trades closed during the cooldown
+	PaperTrades int       `json:"paper_trades"`
+	// Open paper entries by symbol
+	PaperEntries map[string]float64 `json:"paper_entries"`
+}
+
+// Recovered reports whether the paper trades of the cooldown passed the recovery check
+func (c *StrategyCooldown) Recovered(minTrades int) bool {
+	return minTrades > 0 && c.PaperTrades >= minTrades && c.PaperReturn > 0
+}
+
+// UpdateStrategyCooldowns disables strategies whose realized PnL within the window fell below
+// the drawdown limit and re-enables strategies whose cooldown expired or whose paper trades
+// recovered. Only trades closed after a strategy was last re-enabled count towards a new
+// cooldown. It returns the cooldowns that started and ended.
+func (rm *RiskManager) UpdateStrategyCooldowns(outcomes []TradeOutcome, now time.Time) (started, ended []StrategyCooldown) {
+	if rm.Cooldowns == nil {
+		rm.Cooldowns = make(map[string]*StrategyCooldown)
+	}
+	if rm.cooldownResets == nil {
+		rm.cooldownResets = make(map[string]time.Time)
+	}
+
+	// Re-enable strategies whose cooldown is over
+	for _, strategy := range rm.cooldownStrategies() {
+		cooldown := rm.Cooldowns[strategy]
+		if now.Before(cooldown.Until) && !cooldown.Recovered(rm.Config.StrategyRecoveryTrades) {
+			continue
+		}
+		delete(rm.Cooldowns, strategy)
+		rm.cooldownResets[strategy] = now
+		ended = append(ended, *cooldown)
+	}
+
+	limit := rm.Config.StrategyDrawdownLimit * rm.Config.TotalCapital
+	if limit <= 0 {
+		return started, ended
+	}
+
+	window := time.Duration(rm.Config.StrategyDrawdownWindowMinutes) * time.Minute
+	rolling := make(map[string]float64)
+	for _, outcome := range outcomes {
+		if outcome.Strategy == "" || now.Sub(outcome.Timestamp) > window || !outcome.Timestamp.After(rm.cooldownResets[outcome.Strategy]) {
+			continue
+		}
+		rolling[outcome.Strategy] += outcome.PnL
+	}
+
+	strategies := make([]string, 0, len(rolling))
+	for strategy := range rolling {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+
+	for _, strategy := range strategies {
+		pnl := rolling[strategy]
+		if _, coolingDown := rm.Cooldowns[strategy]; coolingDown || pnl >= -limit {
+			continue
+		}
+		cooldown := &StrategyCooldown{
+			Strategy:     strategy,
+			RollingPnL:   pnl,
+			Since:        now,
+			Until:        now.Add(time.Duration(rm.Config.StrategyCooldownMinutes) * time.Minute),
+			PaperEntries: make(map[string]float64),
+		}
+		rm.Cooldowns[strategy] = cooldown
+		started = append(started, *cooldown)
+
+		rm.EmitRiskEvent(RiskEventStrategyCooldown, SeverityWarning, strategy,
+			fmt.Sprintf("strategy %s disabled until %s after rolling PnL %.2f below limit %.2f",
+				strategy, cooldown.Until.Format(time.RFC3339), pnl, -limit))
+	}
+
+	return started, ended
+}
+
+// cooldownStrategies returns the strategies cooling down in a stable order
+func (rm *RiskManager) cooldownStrategies() []string {
+	strategies := make([]string, 0, len(rm.Cooldowns))
+	for strategy := range rm.Cooldowns {
+		strategies = append(strategies, strategy)
+	}
+	sort.Strings(strategies)
+	return strategies
+}
+
+// RecordPaperSignal follows a signal of a strategy that is cooling down on paper: a BUY opens a
+// paper entry at the price and a SELL closes it, adding the trade's return to the recovery check
+func (rm *RiskManager) RecordPaperSignal(strategy, symbol, action string, price float64) {
+	cooldown, coolingDown := rm.Cooldowns[strategy]
+	if !coolingDown || price <= 0 {
+		return
+	}
+
+	entry, open := cooldown.PaperEntries[symbol]
+	switch {
+	case action == "BUY" && !open:
+		cooldown.PaperEntries[symbol] = price
+	case action == "SELL" && open:
+		delete(cooldown.PaperEntries, symbol)
+		cooldown.PaperReturn += (price - entry) / entry
+		cooldown.PaperTrades++
+	}
+}
+
+// IsStrategyCoolingDown reports whether a strategy is disabled after a drawdown
+func (rm *RiskManager) IsStrategyCoolingDown(strategy string, now time.Time) (bool, string) {
+	cooldown, coolingDown := rm.Cooldowns[strategy]
+	if !coolingDown || !now.Before(cooldown.Until) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("strategy %s cooling down after rolling PnL %.2f until %s",
+		strategy, cooldown.RollingPnL, cooldown.Until.Format("15:04:05"))
+}
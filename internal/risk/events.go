@@ -0,0 +1,67 @@
+package risk
+
+import (
+	"time"
+)
+
+// Risk event types
+const (
+	RiskEventStopTriggered        = "STOP_TRIGGERED"
+	RiskEventDrawdownBreach       = "DRAWDOWN_BREACH"
+	RiskEventCircuitBreakerOpened = "CIRCUIT_BREAKER_OPENED"
+	RiskEventExposureLimit        = "EXPOSURE_LIMIT"
+)
+
+// riskEventCooldown suppresses repeats of the same event for the same subject
+const riskEventCooldown = 30 * time.Minute
+
+// RiskEvent is a risk incident pushed to the OnRiskEvent callback as soon as it is detected
+type RiskEvent struct {
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"` // warning, critical
+	Subject   string    `json:"subject"`  // Symbol, rule or circuit breaker the event concerns
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RiskEventHandler receives risk events
+type RiskEventHandler func(event RiskEvent)
+
+// EmitRiskEvent passes an event to the OnRiskEvent callback unless the same event type for the
+// same subject was emitted within the cooldown. It reports whether the event was delivered.
+func (rm *RiskManager) EmitRiskEvent(eventType, severity, subject, message string) bool {
+	now := time.Now()
+	key := eventType + "/" + subject
+
+	if rm.lastRiskEvents == nil {
+		rm.lastRiskEvents = make(map[string]time.Time)
+	}
+	if last, exists := rm.lastRiskEvents[key]; exists && now.Sub(last) < riskEventCooldown {
+		return false
+	}
+	rm.lastRiskEvents[key] = now
+
+	if rm.OnRiskEvent == nil {
+		return false
+	}
+	rm.OnRiskEvent(RiskEvent{
+		Type:      eventType,
+		Severity:  severity,
+		Subject:   subject,
+		Message:   message,
+		Timestamp: now,
+	})
+	return true
+}
+
+// CheckRiskEvents evaluates the risk rules and emits drawdown and exposure events for violations
+func (rm *RiskManager) CheckRiskEvents() {
+	for _, violation := range rm.EvaluateRules() {
+		switch violation.Rule {
+		case "max_drawdown":
+			rm.EmitRiskEvent(RiskEventDrawdownBreach, violation.Severity, violation.Rule, violation.Message)
+		case "max_exposure":
+			rm.EmitRiskEvent(RiskEventExposureLimit, violation.Severity, violation.Rule, violation.Message)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package risk
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// haltStateFile is the file name of the persisted halt inside the data directory
+const haltStateFile = "halt_state.json"
+
+// HaltState records why and when trading was halted by a hard risk limit
+type HaltState struct {
+	Halted   bool      `json:"halted"`
+	Reason   string    `json:"reason"`
+	HaltedAt time.Time `json:"halted_at"`
+}
+
+// CheckDailyLoss returns an error if the loss since the start of the day, realized and
+// unrealized, exceeds the configured percentage of capital
+func (rm *RiskManager) CheckDailyLoss(startOfDayEquity, currentEquity float64) error {
+	if rm.Config.DailyLossLimitPercent <= 0 {
+		return nil
+	}
+
+	loss := startOfDayEquity - currentEquity
+	limit := rm.Config.DailyLossLimitPercent / 100 * rm.Config.TotalCapital
+	if loss > limit {
+		return fmt.Errorf("daily loss %.2f exceeds limit %.2f (%.2f%% of capital)",
+			loss, limit, rm.Config.DailyLossLimitPercent)
+	}
+
+	return nil
+}
+
+// Halt stops all trading until Resume is called. The halt is persisted so that it survives
+// a restart; an error means it is in effect but only until the bot stops.
+func (rm *RiskManager) Halt(reason string) error {
+	if rm.HaltState.Halted {
+		return nil
+	}
+
+	rm.HaltState = HaltState{
+		Halted:   true,
+		Reason:   reason,
+		HaltedAt: time.Now(),
+	}
+	return rm.saveHaltState()
+}
+
+// Resume clears a halt; this only happens on a manual request
+func (rm *RiskManager) Resume() error {
+	rm.HaltState = HaltState{}
+	return rm.saveHaltState()
+}
+
+// IsHalted reports whether trading is halted by a hard risk limit
+func (rm *RiskManager) IsHalted() bool {
+	return rm.HaltState.Halted
+}
+
+// LoadHaltState restores a halt persisted by a previous run, if one exists
+func (rm *RiskManager) LoadHaltState() error {
+	var state HaltState
+	found, err := persistence.LoadJSON(rm.haltStatePath(), &state)
+	if err != nil {
+		return fmt.Errorf("failed to load halt state: %w", err)
+	}
+	if found {
+		rm.HaltState = state
+	}
+	return nil
+}
+
+// saveHaltState persists the current halt, or its absence after a resume
+func (rm *RiskManager) saveHaltState() error {
+	if err := persistence.SaveJSON(rm.haltStatePath(), rm.HaltState); err != nil {
+		return fmt.Errorf("failed to save halt state: %w", err)
+	}
+	return nil
+}
+
+// haltStatePath returns the location of the persisted halt
+func (rm *RiskManager) haltStatePath() string {
+	return filepath.Join(rm.Config.DataDir, haltStateFile)
+}
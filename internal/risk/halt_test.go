@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestCheckDailyLossReadsPercent(t *testing.T) {
+	rm := &RiskManager{Config: &config.Config{TotalCapital: 10000, DailyLossLimitPercent: 5}}
+
+	cases := []struct {
+		name         string
+		start, end   float64
+		wantExceeded bool
+	}{
+		{"gain", 10000, 10200, false},
+		{"loss within the limit", 10000, 9600, false},
+		{"loss at the limit", 10000, 9500, false},
+		{"loss over the limit", 10000, 9400, true},
+	}
+	for _, tc := range cases {
+		err := rm.CheckDailyLoss(tc.start, tc.end)
+		if (err != nil) != tc.wantExceeded {
+			t.Errorf("%s: got error %v, want exceeded %v", tc.name, err, tc.wantExceeded)
+		}
+	}
+
+	rm.Config.DailyLossLimitPercent = 0
+	if err := rm.CheckDailyLoss(10000, 0); err != nil {
+		t.Errorf("disabled limit: got error %v", err)
+	}
+}
+
+func TestHaltSurvivesRestart(t *testing.T) {
+	cfg := &config.Config{DataDir: t.TempDir()}
+	rm := &RiskManager{Config: cfg}
+	if err := rm.Halt("daily loss exceeded"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := &RiskManager{Config: cfg}
+	if err := restarted.LoadHaltState(); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.IsHalted() || restarted.HaltState.Reason != "daily loss exceeded" {
+		t.Errorf("restored halt = %+v, want halted for the daily loss", restarted.HaltState)
+	}
+
+	if err := restarted.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	resumed := &RiskManager{Config: cfg}
+	if err := resumed.LoadHaltState(); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.IsHalted() {
+		t.Error("a resumed halt was restored after a restart")
+	}
+}
@@ -0,0 +1,87 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// riskHistoryFile is the file name of the persisted risk history inside the data directory
+const riskHistoryFile = "risk_history.jsonl"
+
+// RiskSnapshot is a timestamped sample of the portfolio risk metrics
+type RiskSnapshot struct {
+	Timestamp         time.Time `json:"timestamp"`
+	TotalExposure     float64   `json:"total_exposure"`
+	PortfolioDrawdown float64   `json:"portfolio_drawdown"`
+	Volatility        float64   `json:"volatility"`
+	CorrelationRisk   float64   `json:"correlation_risk"`
+	ConcentrationHHI  float64   `json:"concentration_hhi"`
+	VaR95             float64   `json:"var_95"`
+	VaR99             float64   `json:"var_99"`
+	OpenPositions     int       `json:"open_positions"`
+}
+
+// RecordRiskSnapshot appends the given metrics to the risk history and persists them
+func (rm *RiskManager) RecordRiskSnapshot(metrics *RiskMetrics) (RiskSnapshot, error) {
+	snapshot := RiskSnapshot{
+		Timestamp:         time.Now(),
+		TotalExposure:     metrics.TotalExposure,
+		PortfolioDrawdown: metrics.PortfolioDrawdown,
+		Volatility:        metrics.Volatility,
+		CorrelationRisk:   metrics.CorrelationRisk,
+		ConcentrationHHI:  metrics.ConcentrationHHI,
+		VaR95:             metrics.VaR.At(0.95),
+		VaR99:             metrics.VaR.At(0.99),
+		OpenPositions:     len(rm.positionSymbols()),
+	}
+	rm.History = append(rm.History, snapshot)
+
+	if err := persistence.AppendJSONLine(rm.riskHistoryPath(), snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to persist risk snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetRiskHistory returns the risk snapshots within [from, to]. Zero times leave the range open.
+func (rm *RiskManager) GetRiskHistory(from, to time.Time) []RiskSnapshot {
+	history := make([]RiskSnapshot, 0, len(rm.History))
+	for _, snapshot := range rm.History {
+		if !from.IsZero() && snapshot.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && snapshot.Timestamp.After(to) {
+			continue
+		}
+		history = append(history, snapshot)
+	}
+	return history
+}
+
+// LoadRiskHistory loads the persisted risk history from disk
+func (rm *RiskManager) LoadRiskHistory() error {
+	var history []RiskSnapshot
+	err := persistence.ReadJSONLines(rm.riskHistoryPath(), func(line []byte) error {
+		var snapshot RiskSnapshot
+		if err := json.Unmarshal(line, &snapshot); err != nil {
+			return fmt.Errorf("failed to decode risk snapshot: %w", err)
+		}
+		history = append(history, snapshot)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rm.History = history
+	return nil
+}
+
+// riskHistoryPath returns the location of the persisted risk history
+func (rm *RiskManager) riskHistoryPath() string {
+	return filepath.Join(rm.Config.DataDir, riskHistoryFile)
+}
@@ -0,0 +1,74 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// HoldingPeriodExit is a close order for a position held longer than its strategy allows
+type HoldingPeriodExit struct {
+	Symbol     string
+	Strategy   string
+	Quantity   float64
+	EntryPrice float64
+	Price      float64
+	Age        time.Duration
+	Message    string
+}
+
+// SetPositionOrigin records when a tracked position was opened and by which strategy
+func (rm *RiskManager) SetPositionOrigin(symbol string, openedAt time.Time, strategy string) {
+	pos, exists := rm.Positions[symbol]
+	if !exists {
+		return
+	}
+	pos.OpenedAt = openedAt
+	pos.Strategy = strategy
+	rm.Positions[symbol] = pos
+}
+
+// MaxHoldingPeriod returns the maximum holding period for a strategy, honoring per-strategy
+// overrides. Zero means positions may be held indefinitely.
+func (rm *RiskManager) MaxHoldingPeriod(strategy string) time.Duration {
+	hours := rm.Config.MaxHoldingHours
+	if val, ok := config.SymbolValue(rm.Config.MaxHoldingOverrides, strings.ToUpper(strategy)); ok {
+		hours = val
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// CheckHoldingPeriods returns close orders for positions that have been open longer than the
+// maximum holding period of their strategy without hitting take-profit
+func (rm *RiskManager) CheckHoldingPeriods(now time.Time) []HoldingPeriodExit {
+	var exits []HoldingPeriodExit
+
+	for _, symbol := range rm.positionSymbols() {
+		pos := rm.Positions[symbol]
+		if pos.CurrentSize == 0 || pos.OpenedAt.IsZero() {
+			continue
+		}
+
+		maxPeriod := rm.MaxHoldingPeriod(pos.Strategy)
+		age := now.Sub(pos.OpenedAt)
+		if maxPeriod <= 0 || age < maxPeriod {
+			continue
+		}
+
+		exits = append(exits, HoldingPeriodExit{
+			Symbol:     symbol,
+			Strategy:   pos.Strategy,
+			Quantity:   math.Abs(pos.CurrentSize),
+			EntryPrice: pos.EntryPrice,
+			Price:      pos.CurrentPrice,
+			Age:        age,
+			Message: fmt.Sprintf("MAX_HOLDING_PERIOD: Close %s position for %s held %s (limit %s)",
+				pos.Strategy, symbol, age.Round(time.Minute), maxPeriod),
+		})
+	}
+
+	return exits
+}
@@ -0,0 +1,102 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// LiquidationRisk tracks how close a leveraged position is to liquidation
+type LiquidationRisk struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	Size             float64 `json:"size"`
+	Leverage         float64 `json:"leverage"`
+	MarkPrice        float64 `json:"mark_price"`
+	LiquidationPrice float64 `json:"liquidation_price"`
+	// Distance from mark price to liquidation price as a percentage of mark price
+	DistancePercent float64 `json:"distance_percent"`
+	// Maintenance margin divided by the position's margin balance (1 = liquidation)
+	MarginRatio float64 `json:"margin_ratio"`
+}
+
+// LiquidationAction is a warning or deleveraging instruction for a position near liquidation
+type LiquidationAction struct {
+	Symbol         string
+	Side           string
+	Deleverage     bool    // Whether the position should be reduced
+	ReduceQuantity float64 // Quantity to reduce when deleveraging
+	Message        string
+}
+
+// UpdateLiquidationRisks replaces the tracked liquidation risk with the given derivatives positions
+func (rm *RiskManager) UpdateLiquidationRisks(positions []bybit.DerivativePosition) {
+	rm.Liquidations = make(map[string]LiquidationRisk)
+
+	for _, position := range positions {
+		size, _ := position.Size.Float64()
+		leverage, _ := position.Leverage.Float64()
+		markPrice, _ := position.MarkPrice.Float64()
+		liquidationPrice, _ := position.LiquidationPrice.Float64()
+		initialMargin, _ := position.InitialMargin.Float64()
+		maintenanceMargin, _ := position.MaintenanceMargin.Float64()
+		unrealisedPnl, _ := position.UnrealisedPnl.Float64()
+
+		risk := LiquidationRisk{
+			Symbol:           position.Symbol,
+			Side:             position.Side,
+			Size:             size,
+			Leverage:         leverage,
+			MarkPrice:        markPrice,
+			LiquidationPrice: liquidationPrice,
+		}
+
+		if markPrice > 0 && liquidationPrice > 0 {
+			risk.DistancePercent = math.Abs(markPrice-liquidationPrice) / markPrice * 100
+		}
+
+		// Margin balance of the position is its initial margin plus unrealized PnL
+		if marginBalance := initialMargin + unrealisedPnl; marginBalance > 0 {
+			risk.MarginRatio = maintenanceMargin / marginBalance
+		} else if maintenanceMargin > 0 {
+			risk.MarginRatio = 1
+		}
+
+		rm.Liquidations[position.Symbol] = risk
+	}
+}
+
+// CheckLiquidationRisk returns warnings for positions within the liquidation buffer and
+// deleveraging actions for positions within the deleverage buffer
+func (rm *RiskManager) CheckLiquidationRisk() []LiquidationAction {
+	var actions []LiquidationAction
+
+	for symbol, risk := range rm.Liquidations {
+		// An empty liquidation price means the position cannot be liquidated (e.g. fully collateralized)
+		if risk.LiquidationPrice <= 0 || risk.MarkPrice <= 0 {
+			continue
+		}
+
+		switch {
+		case risk.DistancePercent <= rm.Config.DeleverageBufferPercent:
+			actions = append(actions, LiquidationAction{
+				Symbol:         symbol,
+				Side:           risk.Side,
+				Deleverage:     true,
+				ReduceQuantity: risk.Size * rm.Config.DeleverageFraction,
+				Message: fmt.Sprintf("DELEVERAGE: %s is %.2f%% from liquidation at %.4f (mark %.4f, margin ratio %.2f), reducing by %.0f%%",
+					symbol, risk.DistancePercent, risk.LiquidationPrice, risk.MarkPrice, risk.MarginRatio, rm.Config.DeleverageFraction*100),
+			})
+		case risk.DistancePercent <= rm.Config.LiquidationBufferPercent:
+			actions = append(actions, LiquidationAction{
+				Symbol: symbol,
+				Side:   risk.Side,
+				Message: fmt.Sprintf("LIQUIDATION_WARNING: %s is %.2f%% from liquidation at %.4f (mark %.4f, margin ratio %.2f)",
+					symbol, risk.DistancePercent, risk.LiquidationPrice, risk.MarkPrice, risk.MarginRatio),
+			})
+		}
+	}
+
+	return actions
+}
@@ -2,21 +2,45 @@ package risk
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/forbest/bybitgo/internal/bybit"
 	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/market"
 )
 
 // RiskManager handles risk management for the trading bot
 type RiskManager struct {
-	Config    *config.Config
-	Positions map[string]PositionRisk
+	Config         *config.Config
+	Positions      map[string]PositionRisk
+	MarketAnalyzer *market.MarketAnalyzer     // Source of return history and correlations
+	HaltState      HaltState                  // Set when a hard limit halts trading
+	Liquidations   map[string]LiquidationRisk // Liquidation risk of leveraged derivatives positions
+	Plans          map[string]TradePlan       // Stop-loss and take-profit levels set by strategy signals
+	Rules          []RiskRule                 // Risk rules pipeline evaluated by CheckPortfolioRisk
+	// Strategies, symbols or the whole bot paused after a losing streak
+	LossStreakPauses []LossStreakPause
+	// Strategies disabled after their rolling PnL fell below the drawdown limit
+	Cooldowns        map[string]*StrategyCooldown
+	History          []RiskSnapshot   // Risk metrics sampled every trading cycle
+	VolatilityTarget VolatilityTarget // Last allocation scaling towards the volatility target
+	// Trading sessions declared by the strategies themselves, keyed by strategy name
+	Sessions map[string][]config.TradingSession
+	// OnRiskEvent is notified of risk incidents as soon as they are detected
+	OnRiskEvent RiskEventHandler
+	// ConversionRate converts a symbol's quote currency into the reporting currency the limits
+	// are set in, false while no rate is known; nil when everything is quoted in it
+	ConversionRate func(symbol string) (float64, bool)
+	lastRiskEvents map[string]time.Time
+	cooldownResets map[string]time.Time // When each strategy's last cooldown ended
 }
 
 // PositionRisk tracks risk metrics for a position
 type PositionRisk struct {
 	Symbol            string
-	CurrentSize       float64
+	CurrentSize       float64 // Negative for short positions
 	EntryPrice        float64
 	CurrentPrice      float64
 	UnrealizedPnL     float64
@@ -27,6 +51,41 @@ type PositionRisk struct {
 	PeakValue         float64 // Track peak value for drawdown calculation
 	TrailingStopLevel float64 // Trailing stop level
 	IsTrailingStopSet bool    // Whether trailing stop is active
+	PeakPrice         float64 // Highest price seen since the trailing stop was activated
+	OpenedAt          time.Time
+	Strategy          string // Strategy that opened the position
+	// Price range seen while the position was open, for the trade's excursions
+	LowestPrice  float64
+	HighestPrice float64
+}
+
+// ObservePrice widens the price range of a position with a price
+func (pos *PositionRisk) ObservePrice(price float64) {
+	if price <= 0 {
+		return
+	}
+	if pos.LowestPrice <= 0 || price < pos.LowestPrice {
+		pos.LowestPrice = price
+	}
+	if price > pos.HighestPrice {
+		pos.HighestPrice = price
+	}
+}
+
+// Excursions returns the maximum adverse and favorable excursion of a position closed at an exit
+// price, in percent of its entry price. A fall is adverse to longs and a rise to shorts.
+func (pos PositionRisk) Excursions(exitPrice float64) (mae, mfe float64) {
+	pos.ObservePrice(pos.EntryPrice)
+	pos.ObservePrice(exitPrice)
+	if pos.EntryPrice <= 0 {
+		return 0, 0
+	}
+	fall := (pos.EntryPrice - pos.LowestPrice) / pos.EntryPrice * 100
+	rise := (pos.HighestPrice - pos.EntryPrice) / pos.EntryPrice * 100
+	if pos.CurrentSize < 0 {
+		return rise, fall
+	}
+	return fall, rise
 }
 
 // RiskMetrics tracks overall portfolio risk
@@ -35,14 +94,35 @@ type RiskMetrics struct {
 	PortfolioDrawdown float64
 	Volatility        float64
 	CorrelationRisk   float64
+	VaR               VaRReport
+	ConcentrationHHI  float64 // Herfindahl-Hirschman index of position weights (1 = single position)
 }
 
 // NewRiskManager creates a new RiskManager
 func NewRiskManager(cfg *config.Config) *RiskManager {
-	return &RiskManager{
-		Config:    cfg,
-		Positions: make(map[string]PositionRisk),
+	rm := &RiskManager{
+		Config:       cfg,
+		Positions:    make(map[string]PositionRisk),
+		Liquidations: make(map[string]LiquidationRisk),
+		Plans:        make(map[string]TradePlan),
+		Rules:        DefaultRiskRules(cfg),
+		Sessions:     make(map[string][]config.TradingSession),
 	}
+
+	// Restore the risk history from previous runs
+	if err := rm.LoadRiskHistory(); err != nil {
+		fmt.Printf("Warning: Failed to load risk history: %v\n", err)
+	}
+
+	// A halt stays in effect across restarts until it is resumed manually
+	if err := rm.LoadHaltState(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else if rm.HaltState.Halted {
+		fmt.Printf("Trading is halted since %s (%s), resume it from the dashboard\n",
+			rm.HaltState.HaltedAt.Format("2006-01-02 15:04:05"), rm.HaltState.Reason)
+	}
+
+	return rm
 }
 
 // CheckPositionRisk checks if a position exceeds risk limits
@@ -62,23 +142,19 @@ func (rm *RiskManager) CheckPositionRisk(symbol string, orderSize float64, price
 			currentExposure, orderSize*price, rm.Config.TotalCapital)
 	}
 
+	// Check aggregate exposure of the symbol's category
+	if err := rm.CheckCategoryExposure(symbol, orderSize*price); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// CheckPortfolioRisk checks overall portfolio risk
+// CheckPortfolioRisk checks overall portfolio risk against the rules pipeline
 func (rm *RiskManager) CheckPortfolioRisk() error {
-	metrics := rm.CalculateRiskMetrics()
-
-	// Check maximum drawdown
-	if metrics.PortfolioDrawdown > rm.Config.MaxDrawdown {
-		return fmt.Errorf("portfolio drawdown %.2f%% exceeds maximum allowed %.2f%%",
-			metrics.PortfolioDrawdown*100, rm.Config.MaxDrawdown*100)
-	}
-
-	// Check total exposure
-	if metrics.TotalExposure > rm.Config.TotalCapital {
-		return fmt.Errorf("total exposure %.2f exceeds capital %.2f",
-			metrics.TotalExposure, rm.Config.TotalCapital)
+	violations := rm.EvaluateRules()
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", violations[0].Message)
 	}
 
 	return nil
@@ -96,18 +172,50 @@ func (rm *RiskManager) CalculateRiskMetrics() *RiskMetrics {
 		PortfolioDrawdown: portfolioDrawdown,
 		Volatility:        volatility,
 		CorrelationRisk:   correlationRisk,
+		VaR:               rm.CalculateVaR(),
+		ConcentrationHHI:  rm.CalculateConcentration(),
 	}
 }
 
 // GetTotalExposure calculates total portfolio exposure
 func (rm *RiskManager) GetTotalExposure() float64 {
 	total := 0.0
-	for _, pos := range rm.Positions {
-		total += pos.CurrentSize * pos.CurrentPrice
+	for symbol := range rm.Positions {
+		total += rm.positionValue(symbol)
+	}
+	return total
+}
+
+// ShortExposure returns the total value of short positions
+func (rm *RiskManager) ShortExposure() float64 {
+	total := 0.0
+	for symbol, pos := range rm.Positions {
+		if pos.CurrentSize < 0 {
+			total += rm.positionValue(symbol)
+		}
 	}
 	return total
 }
 
+// CalculateConcentration returns the Herfindahl-Hirschman index of position weights: the sum of
+// squared shares of total position value. It ranges from 1/N for N equal positions to 1 for a single position.
+func (rm *RiskManager) CalculateConcentration() float64 {
+	total := 0.0
+	for _, symbol := range rm.positionSymbols() {
+		total += rm.positionValue(symbol)
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	hhi := 0.0
+	for _, symbol := range rm.positionSymbols() {
+		weight := rm.positionValue(symbol) / total
+		hhi += weight * weight
+	}
+	return hhi
+}
+
 // CalculatePortfolioDrawdown calculates portfolio drawdown
 func (rm *RiskManager) CalculatePortfolioDrawdown() float64 {
 	totalPnL := 0.0
@@ -146,50 +254,153 @@ func (rm *RiskManager) CalculatePortfolioVolatility() float64 {
 	return totalVolatility / float64(count)
 }
 
-// CalculateCorrelationRisk calculates correlation risk across positions
+// CalculateCorrelationRisk calculates the average pairwise correlation between positions,
+// weighted by the product of their position values
 func (rm *RiskManager) CalculateCorrelationRisk() float64 {
-	// Simplified calculation - in practice would use correlation matrix
 	// Higher correlation = higher risk (less diversification)
-
 	if len(rm.Positions) <= 1 {
 		return 0
 	}
 
-	// Assume average correlation of 0.3 for crypto assets
-	// In practice, this would be calculated from historical data
-	return 0.3
+	// Without market data, assume an average correlation of 0.3 for crypto assets
+	if rm.MarketAnalyzer == nil || len(rm.MarketAnalyzer.CorrelationMatrix) == 0 {
+		return 0.3
+	}
+
+	symbols := rm.positionSymbols()
+	weightedSum := 0.0
+	totalWeight := 0.0
+	for i := 0; i < len(symbols); i++ {
+		for j := i + 1; j < len(symbols); j++ {
+			weight := rm.positionValue(symbols[i]) * rm.positionValue(symbols[j])
+			weightedSum += weight * rm.correlation(symbols[i], symbols[j])
+			totalWeight += weight
+		}
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+
+	return weightedSum / totalWeight
+}
+
+// CheckCorrelationConcentration flags pairs of highly correlated positions whose combined
+// value exceeds the configured share of capital
+func (rm *RiskManager) CheckCorrelationConcentration() []string {
+	var warnings []string
+	if rm.MarketAnalyzer == nil || rm.Config.MaxCorrelatedExposure <= 0 {
+		return warnings
+	}
+
+	symbols := rm.positionSymbols()
+	limit := rm.Config.MaxCorrelatedExposure * rm.Config.TotalCapital
+	for i := 0; i < len(symbols); i++ {
+		for j := i + 1; j < len(symbols); j++ {
+			corr := rm.correlation(symbols[i], symbols[j])
+			if corr < rm.Config.CorrelationThreshold {
+				continue
+			}
+
+			combined := rm.positionValue(symbols[i]) + rm.positionValue(symbols[j])
+			if combined > limit {
+				warnings = append(warnings, fmt.Sprintf("CORRELATION_CONCENTRATION: %s and %s (correlation %.2f) hold %.2f, above limit %.2f",
+					symbols[i], symbols[j], corr, combined, limit))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// positionSymbols returns the symbols of all open positions in a stable order
+func (rm *RiskManager) positionSymbols() []string {
+	symbols := make([]string, 0, len(rm.Positions))
+	for symbol, pos := range rm.Positions {
+		if pos.CurrentSize != 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// positionValue returns the absolute market value of a position in the reporting currency
+func (rm *RiskManager) positionValue(symbol string) float64 {
+	pos := rm.Positions[symbol]
+	return math.Abs(pos.CurrentSize * pos.CurrentPrice * rm.conversionRate(symbol))
+}
+
+// conversionRate returns the rate of a symbol's quote currency in the reporting currency.
+// Positions are valued in their quote currency while no rate is known, so that they still
+// count against the limits.
+func (rm *RiskManager) conversionRate(symbol string) float64 {
+	if rm.ConversionRate == nil {
+		return 1
+	}
+	if rate, ok := rm.ConversionRate(symbol); ok && rate > 0 {
+		return rate
+	}
+	return 1
+}
+
+// StopLossPercent returns the stop-loss percentage for a symbol, honoring per-symbol overrides
+func (rm *RiskManager) StopLossPercent(symbol string) float64 {
+	if val, ok := config.SymbolValue(rm.Config.StopLossOverrides, symbol); ok {
+		return val
+	}
+	return rm.Config.GetStopLossPercent()
+}
+
+// TakeProfitPercent returns the take-profit percentage for a symbol, honoring per-symbol overrides
+func (rm *RiskManager) TakeProfitPercent(symbol string) float64 {
+	if val, ok := config.SymbolValue(rm.Config.TakeProfitOverrides, symbol); ok {
+		return val
+	}
+	return rm.Config.GetTakeProfitPercent()
+}
+
+// MaxDrawdown returns the maximum drawdown for a symbol, honoring per-symbol overrides
+func (rm *RiskManager) MaxDrawdown(symbol string) float64 {
+	if val, ok := config.SymbolValue(rm.Config.MaxDrawdownOverrides, symbol); ok {
+		return val
+	}
+	return rm.Config.GetMaxDrawdown()
 }
 
-// UpdatePosition updates position risk metrics
+// UpdatePosition updates position risk metrics. Short positions (side Sell) are tracked with a
+// negative size and have their stop-loss above and take-profit below the entry price.
 func (rm *RiskManager) UpdatePosition(symbol string, position bybit.Position) {
 	size, _ := position.Size.Float64()
 	avgPrice, _ := position.AvgPrice.Float64()
 	unrealizedPnL, _ := position.UnrealisedPnl.Float64()
 
 	// Calculate stop-loss and take-profit levels
-	stopLossLevel := avgPrice * (1 - rm.Config.StopLossPercent/100)
-	takeProfitLevel := avgPrice * (1 + rm.Config.TakeProfitPercent/100)
+	stopLossLevel := avgPrice * (1 - rm.StopLossPercent(symbol)/100)
+	takeProfitLevel := avgPrice * (1 + rm.TakeProfitPercent(symbol)/100)
+	if position.Side == "Sell" {
+		size = -math.Abs(size)
+		stopLossLevel = avgPrice * (1 + rm.StopLossPercent(symbol)/100)
+		takeProfitLevel = avgPrice * (1 - rm.TakeProfitPercent(symbol)/100)
+	}
+	stopLossLevel, takeProfitLevel = rm.planLevels(symbol, stopLossLevel, takeProfitLevel)
 
 	// Get existing position data to preserve peak value and trailing stop
 	existingPos, exists := rm.Positions[symbol]
 	peakValue := existingPos.PeakValue
 	trailingStopLevel := existingPos.TrailingStopLevel
 	isTrailingStopSet := existingPos.IsTrailingStopSet
+	peakPrice := existingPos.PeakPrice
 
 	// Calculate current position value
-	currentValue := size*avgPrice + unrealizedPnL
+	currentValue := math.Abs(size)*avgPrice + unrealizedPnL
 
 	// Update peak value if current value is higher
 	if !exists || currentValue > peakValue {
 		peakValue = currentValue
-		// Update trailing stop level when new peak is reached
-		if exists && isTrailingStopSet {
-			// Move trailing stop up by the same percentage as the peak increase
-			trailingStopLevel = avgPrice * (1 - rm.Config.StopLossPercent/100)
-		}
 	}
 
-	rm.Positions[symbol] = PositionRisk{
+	updated := PositionRisk{
 		Symbol:            symbol,
 		CurrentSize:       size,
 		EntryPrice:        avgPrice,
@@ -202,7 +413,39 @@ func (rm *RiskManager) UpdatePosition(symbol string, position bybit.Position) {
 		PeakValue:         peakValue,
 		TrailingStopLevel: trailingStopLevel,
 		IsTrailingStopSet: isTrailingStopSet,
+		PeakPrice:         peakPrice,
+		OpenedAt:          existingPos.OpenedAt,
+		Strategy:          existingPos.Strategy,
+		LowestPrice:       existingPos.LowestPrice,
+		HighestPrice:      existingPos.HighestPrice,
 	}
+	updated.ObservePrice(avgPrice)
+	rm.Positions[symbol] = updated
+}
+
+// SyncPositions replaces the tracked positions with the given ones, keeping the peak value
+// and trailing stop of positions that are still open. It returns the positions that were closed.
+func (rm *RiskManager) SyncPositions(positions []bybit.Position) []PositionRisk {
+	open := make(map[string]bool)
+	for _, position := range positions {
+		open[position.Symbol] = true
+		rm.UpdatePosition(position.Symbol, position)
+	}
+
+	// Drop positions that have been closed
+	var closed []PositionRisk
+	for symbol, pos := range rm.Positions {
+		if !open[symbol] {
+			if pos.CurrentSize != 0 {
+				closed = append(closed, pos)
+			}
+			delete(rm.Positions, symbol)
+			delete(rm.Plans, symbol)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].Symbol < closed[j].Symbol })
+
+	return closed
 }
 
 // SetTrailingStop sets a trailing stop for a position
@@ -212,13 +455,15 @@ func (rm *RiskManager) SetTrailingStop(symbol string, currentPrice float64) {
 		return
 	}
 
-	// Set trailing stop at the stop-loss level initially
-	pos.TrailingStopLevel = currentPrice * (1 - rm.Config.StopLossPercent/100)
+	// Set trailing stop at the trailing distance below the current price initially
+	pos.TrailingStopLevel = currentPrice * (1 - rm.TrailingStopPercent(symbol)/100)
 	pos.IsTrailingStopSet = true
+	pos.PeakPrice = currentPrice
 	rm.Positions[symbol] = pos
 }
 
-// CheckStopLossTakeProfit checks if any positions have hit stop-loss or take-profit levels
+// CheckStopLossTakeProfit checks if any positions have hit stop-loss or take-profit levels.
+// Trailing stops are handled by UpdateTrailingStops.
 func (rm *RiskManager) CheckStopLossTakeProfit(currentPrices map[string]float64) []string {
 	var actions []string
 
@@ -230,31 +475,34 @@ func (rm *RiskManager) CheckStopLossTakeProfit(currentPrices map[string]float64)
 
 		// Update current price
 		pos.CurrentPrice = currentPrice
+		pos.ObservePrice(currentPrice)
 		rm.Positions[symbol] = pos
 
 		// Check for long positions
 		if pos.CurrentSize > 0 {
-			// Check trailing stop
-			if pos.IsTrailingStopSet && currentPrice <= pos.TrailingStopLevel {
-				actions = append(actions, fmt.Sprintf("TRAILING_STOP: Close long position for %s at %.4f (trailing stop level: %.4f)",
-					symbol, currentPrice, pos.TrailingStopLevel))
-			} else if currentPrice <= pos.StopLossLevel {
+			if currentPrice <= pos.StopLossLevel {
 				// Check stop-loss (price dropped below stop-loss level)
-				actions = append(actions, fmt.Sprintf("STOP_LOSS: Close long position for %s at %.4f (stop-loss level: %.4f)",
-					symbol, currentPrice, pos.StopLossLevel))
+				action := fmt.Sprintf("STOP_LOSS: Close long position for %s at %.4f (stop-loss level: %.4f)",
+					symbol, currentPrice, pos.StopLossLevel)
+				actions = append(actions, action)
+				rm.EmitRiskEvent(RiskEventStopTriggered, SeverityWarning, symbol, action)
 			} else if currentPrice >= pos.TakeProfitLevel {
 				// Check take-profit (price rose above take-profit level)
 				actions = append(actions, fmt.Sprintf("TAKE_PROFIT: Close long position for %s at %.4f (take-profit level: %.4f)",
 					symbol, currentPrice, pos.TakeProfitLevel))
-			} else if pos.IsTrailingStopSet && currentPrice > pos.PeakValue {
-				// Update trailing stop if price increased and trailing stop is set
-				// Move trailing stop up to maintain the same distance from peak
-				newTrailingStop := currentPrice * (1 - rm.Config.StopLossPercent/100)
-				if newTrailingStop > pos.TrailingStopLevel {
-					pos.TrailingStopLevel = newTrailingStop
-					pos.PeakValue = currentPrice
-					rm.Positions[symbol] = pos
-				}
+			}
+		}
+
+		// Check for short positions, which lose when the price rises
+		if pos.CurrentSize < 0 {
+			if currentPrice >= pos.StopLossLevel {
+				action := fmt.Sprintf("STOP_LOSS: Cover short position for %s at %.4f (stop-loss level: %.4f)",
+					symbol, currentPrice, pos.StopLossLevel)
+				actions = append(actions, action)
+				rm.EmitRiskEvent(RiskEventStopTriggered, SeverityWarning, symbol, action)
+			} else if currentPrice <= pos.TakeProfitLevel {
+				actions = append(actions, fmt.Sprintf("TAKE_PROFIT: Cover short position for %s at %.4f (take-profit level: %.4f)",
+					symbol, currentPrice, pos.TakeProfitLevel))
 			}
 		}
 	}
@@ -268,13 +516,16 @@ func (rm *RiskManager) CheckSymbolDrawdown() []string {
 
 	for symbol, pos := range rm.Positions {
 		if pos.PeakValue > 0 {
-			currentValue := pos.CurrentSize*pos.CurrentPrice + pos.UnrealizedPnL
+			currentValue := math.Abs(pos.CurrentSize)*pos.CurrentPrice + pos.UnrealizedPnL
 			drawdown := (pos.PeakValue - currentValue) / pos.PeakValue
 
-			// Check if drawdown exceeds the configured maximum (use same as portfolio for now)
-			if drawdown > rm.Config.MaxDrawdown {
-				actions = append(actions, fmt.Sprintf("MAX_DRAWDOWN_EXCEEDED: %s drawdown %.2f%% exceeds limit %.2f%%",
-					symbol, drawdown*100, rm.Config.MaxDrawdown*100))
+			// Check if drawdown exceeds the symbol's configured maximum
+			maxDrawdown := rm.MaxDrawdown(symbol)
+			if drawdown > maxDrawdown {
+				action := fmt.Sprintf("MAX_DRAWDOWN_EXCEEDED: %s drawdown %.2f%% exceeds limit %.2f%%",
+					symbol, drawdown*100, maxDrawdown*100)
+				actions = append(actions, action)
+				rm.EmitRiskEvent(RiskEventDrawdownBreach, SeverityWarning, symbol, action)
 			}
 		}
 	}
@@ -282,43 +533,17 @@ func (rm *RiskManager) CheckSymbolDrawdown() []string {
 	return actions
 }
 
-// GetRiskReport generates a risk report
+// GetRiskReport renders the structured risk report as text
 func (rm *RiskManager) GetRiskReport() string {
-	metrics := rm.CalculateRiskMetrics()
-
-	report := fmt.Sprintf("Risk Report:\n")
-	report += fmt.Sprintf("  Total Exposure: $%.2f (%.1f%% of capital)\n",
-		metrics.TotalExposure, (metrics.TotalExposure/rm.Config.TotalCapital)*100)
-	report += fmt.Sprintf("  Portfolio Drawdown: %.2f%%\n", metrics.PortfolioDrawdown*100)
-	report += fmt.Sprintf("  Portfolio Volatility: %.2f%%\n", metrics.Volatility*100)
-	report += fmt.Sprintf("  Correlation Risk: %.2f\n", metrics.CorrelationRisk)
-
-	// Add stop-loss and take-profit information
-	report += fmt.Sprintf("  Stop-Loss Level: %.2f%%\n", rm.Config.StopLossPercent)
-	report += fmt.Sprintf("  Take-Profit Level: %.2f%%\n", rm.Config.TakeProfitPercent)
-
-	// Add symbol drawdown information
-	report += fmt.Sprintf("  Symbol Drawdown Limits: %.2f%%\n", rm.Config.MaxDrawdown*100)
-
-	if rm.ShouldStopTrading() {
-		report += "  WARNING: Trading should be stopped due to excessive risk!\n"
-	}
-
-	return report
+	return rm.GetRiskReportStruct().String()
 }
 
-// ShouldStopTrading checks if trading should be stopped due to risk limits
+// ShouldStopTrading checks if trading should be stopped due to critical risk rule violations
 func (rm *RiskManager) ShouldStopTrading() bool {
-	metrics := rm.CalculateRiskMetrics()
-
-	// Stop if drawdown exceeds 2x the configured maximum
-	if metrics.PortfolioDrawdown > rm.Config.MaxDrawdown*2 {
-		return true
-	}
-
-	// Stop if exposure exceeds 1.5x capital
-	if metrics.TotalExposure > rm.Config.TotalCapital*1.5 {
-		return true
+	for _, violation := range rm.EvaluateRules() {
+		if violation.Severity == SeverityCritical {
+			return true
+		}
 	}
 
 	return false
@@ -0,0 +1,45 @@
+package risk
+
+// TradePlan holds the stop-loss and take-profit levels a strategy attached to its entry signal
+type TradePlan struct {
+	StopLoss   float64
+	TakeProfit float64
+}
+
+// SetTradePlan records the strategy's stop-loss and take-profit levels for a symbol. They replace
+// the percentage-based levels of the position until it is closed; zero keeps the default level.
+func (rm *RiskManager) SetTradePlan(symbol string, stopLoss, takeProfit float64) {
+	if stopLoss <= 0 && takeProfit <= 0 {
+		return
+	}
+	if rm.Plans == nil {
+		rm.Plans = make(map[string]TradePlan)
+	}
+	rm.Plans[symbol] = TradePlan{StopLoss: stopLoss, TakeProfit: takeProfit}
+
+	// Apply the plan right away to a position that is already tracked
+	if pos, exists := rm.Positions[symbol]; exists {
+		pos.StopLossLevel, pos.TakeProfitLevel = rm.planLevels(symbol, pos.StopLossLevel, pos.TakeProfitLevel)
+		rm.Positions[symbol] = pos
+	}
+}
+
+// ClearTradePlan forgets the strategy levels of a symbol
+func (rm *RiskManager) ClearTradePlan(symbol string) {
+	delete(rm.Plans, symbol)
+}
+
+// planLevels returns the planned stop-loss and take-profit levels of a symbol, falling back to the given defaults
+func (rm *RiskManager) planLevels(symbol string, stopLoss, takeProfit float64) (float64, float64) {
+	plan, exists := rm.Plans[symbol]
+	if !exists {
+		return stopLoss, takeProfit
+	}
+	if plan.StopLoss > 0 {
+		stopLoss = plan.StopLoss
+	}
+	if plan.TakeProfit > 0 {
+		takeProfit = plan.TakeProfit
+	}
+	return stopLoss, takeProfit
+}
@@ -0,0 +1,236 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Pre-trade rejection codes
+const (
+	RejectHalted        = "HALTED"
+	RejectCircuitOpen   = "CIRCUIT_OPEN"
+	RejectInvalidOrder  = "INVALID_ORDER"
+	RejectPositionLimit = "POSITION_LIMIT"
+	RejectCapitalLimit  = "CAPITAL_LIMIT"
+	RejectCategoryLimit = "CATEGORY_LIMIT"
+	RejectStrategyLimit = "STRATEGY_CAPITAL"
+	RejectBelowMinimum  = "BELOW_MINIMUM"
+	RejectInsufficient  = "INSUFFICIENT_BALANCE"
+	RejectShortLimit    = "SHORT_LIMIT"
+	RejectNoRate        = "NO_CONVERSION_RATE"
+)
+
+// OrderRequest is an order about to be submitted to the exchange
+type OrderRequest struct {
+	Symbol   string
+	Side     string // BUY, SELL, SHORT (open a perpetual short), COVER (buy back a short)
+	Quantity float64
+	Price    float64 // In the symbol's quote currency
+	Strategy string  // Strategy placing the order, whose capital bucket applies
+	// Value of one unit of the quote currency in the reporting currency, 0 if unknown
+	ConversionRate float64
+	// Exchange trading rules for the symbol, nil if unavailable
+	Instrument *bybit.InstrumentInfo
+	// Free exchange balances of the symbol's currencies, nil if unavailable
+	Balance *bybit.SymbolBalance
+}
+
+// OrderRejection is the structured reason an order was rejected
+type OrderRejection struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (r *OrderRejection) Error() string {
+	return fmt.Sprintf("%s: %s", r.Code, r.Message)
+}
+
+// PreTradeDecision is the outcome of the pre-trade checks
+type PreTradeDecision struct {
+	Approved    bool
+	Quantity    float64         // Approved quantity, possibly reduced
+	Resized     bool            // Whether the quantity was reduced to fit the limits
+	Adjustments []string        // Reasons the quantity was reduced
+	Rejection   *OrderRejection // Set if the order was rejected
+}
+
+// PreTradeGate runs all pre-trade checks before an order is submitted
+type PreTradeGate struct {
+	RiskManager    *RiskManager
+	CircuitBreaker *CircuitBreaker
+}
+
+// NewPreTradeGate creates a new PreTradeGate
+func NewPreTradeGate(rm *RiskManager, cb *CircuitBreaker) *PreTradeGate {
+	return &PreTradeGate{
+		RiskManager:    rm,
+		CircuitBreaker: cb,
+	}
+}
+
+// Check runs the kill-switch, size, exposure, category, strategy capital, available balance and instrument minimum
+// checks. Buys and shorts that exceed a limit or the available balance are resized to fit if
+// PreTradeResize is enabled, otherwise rejected; shorts are also limited by the short exposure
+// limit. Sells and covers reduce risk and are only subject to the kill-switch, balance and
+// instrument checks. The spot balance check does not apply to perpetual shorts and covers.
+func (g *PreTradeGate) Check(order OrderRequest) PreTradeDecision {
+	rm := g.RiskManager
+	decision := PreTradeDecision{Quantity: order.Quantity}
+
+	reject := func(code, format string, args ...interface{}) PreTradeDecision {
+		decision.Approved = false
+		decision.Rejection = &OrderRejection{Code: code, Message: fmt.Sprintf(format, args...)}
+		return decision
+	}
+
+	// Kill-switch state
+	if rm.IsHalted() {
+		return reject(RejectHalted, "trading is halted: %s", rm.HaltState.Reason)
+	}
+	if g.CircuitBreaker != nil && g.CircuitBreaker.State() == "open" {
+		return reject(RejectCircuitOpen, "circuit breaker is open")
+	}
+
+	if order.Quantity <= 0 || order.Price <= 0 {
+		return reject(RejectInvalidOrder, "quantity %.8f and price %.8f must be positive", order.Quantity, order.Price)
+	}
+
+	// Orders can not be checked against the limits without their value in the reporting currency
+	rate := order.ConversionRate
+	if rate <= 0 {
+		return reject(RejectNoRate, "no conversion rate of %s into the reporting currency", order.Symbol)
+	}
+
+	if order.Side == "BUY" || order.Side == "SHORT" {
+		orderValue := decision.Quantity * order.Price * rate
+
+		// Apply each limit on the order value, shrinking the order to the tightest one
+		type limit struct {
+			code     string
+			headroom float64
+			message  string
+			enabled  bool
+		}
+		limits := []limit{
+			{
+				code:     RejectPositionLimit,
+				headroom: rm.Config.MaxPositionPerCoin - rm.positionValue(order.Symbol),
+				message:  fmt.Sprintf("position limit %.2f for %s", rm.Config.MaxPositionPerCoin, order.Symbol),
+				enabled:  rm.Config.MaxPositionPerCoin > 0,
+			},
+			{
+				code:     RejectCapitalLimit,
+				headroom: rm.Config.TotalCapital - rm.GetTotalExposure(),
+				message:  fmt.Sprintf("total capital %.2f", rm.Config.TotalCapital),
+				enabled:  rm.Config.TotalCapital > 0,
+			},
+		}
+		if order.Side == "SHORT" {
+			limits = append(limits, limit{
+				code:     RejectShortLimit,
+				headroom: rm.Config.MaxShortExposure - rm.ShortExposure(),
+				message:  fmt.Sprintf("short exposure limit %.2f", rm.Config.MaxShortExposure),
+				enabled:  rm.Config.MaxShortExposure > 0,
+			})
+		}
+		if headroom, ok := rm.CategoryHeadroom(order.Symbol); ok {
+			limits = append(limits, limit{
+				code:     RejectCategoryLimit,
+				headroom: headroom,
+				message:  fmt.Sprintf("category %s limit", rm.SymbolCategory(order.Symbol)),
+				enabled:  true,
+			})
+		}
+
+		if headroom, ok := rm.StrategyHeadroom(order.Strategy); ok {
+			limits = append(limits, limit{
+				code:     RejectStrategyLimit,
+				headroom: headroom,
+				message:  fmt.Sprintf("strategy %s capital", order.Strategy),
+				enabled:  true,
+			})
+		}
+
+		for _, l := range limits {
+			if !l.enabled || orderValue <= l.headroom {
+				continue
+			}
+			if l.headroom <= 0 || !rm.Config.PreTradeResize {
+				rejected := reject(l.code, "order value %.2f exceeds remaining %.2f of %s",
+					orderValue, l.headroom, l.message)
+				rm.EmitRiskEvent(RiskEventExposureLimit, SeverityWarning, order.Symbol, rejected.Rejection.Error())
+				return rejected
+			}
+
+			// Shrink the order to the remaining headroom
+			orderValue = l.headroom
+			decision.Quantity = orderValue / (order.Price * rate)
+			decision.Resized = true
+			decision.Adjustments = append(decision.Adjustments,
+				fmt.Sprintf("resized to %.2f to fit %s", orderValue, l.message))
+		}
+	}
+
+	// Available balance on the exchange, so the order is not rejected for insufficient funds
+	if balance := order.Balance; balance != nil && (order.Side == "BUY" || order.Side == "SELL") {
+		if order.Side == "BUY" {
+			// Required quote currency including the fee and slippage buffer
+			costPerUnit := order.Price * (1 + rm.Config.BalanceBufferPercent/100)
+			available, _ := balance.QuoteFree.Float64()
+			if decision.Quantity*costPerUnit > available {
+				if available <= 0 || !rm.Config.PreTradeResize {
+					return reject(RejectInsufficient, "order needs %.4f %s but only %.4f is available",
+						decision.Quantity*costPerUnit, balance.QuoteCoin, available)
+				}
+				decision.Quantity = available / costPerUnit
+				decision.Resized = true
+				decision.Adjustments = append(decision.Adjustments,
+					fmt.Sprintf("resized to available balance %.4f %s", available, balance.QuoteCoin))
+			}
+		} else {
+			// A sell can not exceed the free base currency
+			available, _ := balance.BaseFree.Float64()
+			if decision.Quantity > available {
+				if available <= 0 {
+					return reject(RejectInsufficient, "no free %s to sell", balance.BaseCoin)
+				}
+				decision.Quantity = available
+				decision.Resized = true
+				decision.Adjustments = append(decision.Adjustments,
+					fmt.Sprintf("capped at available balance %.8f %s", available, balance.BaseCoin))
+			}
+		}
+	}
+
+	// Exchange trading rules
+	if info := order.Instrument; info != nil {
+		if maxQty, _ := info.MaxOrderQty.Float64(); maxQty > 0 && decision.Quantity > maxQty {
+			decision.Quantity = maxQty
+			decision.Resized = true
+			decision.Adjustments = append(decision.Adjustments,
+				fmt.Sprintf("capped at maximum order quantity %.8f", maxQty))
+		}
+
+		if step, _ := info.QtyStep.Float64(); step > 0 {
+			decision.Quantity = float64(int64(decision.Quantity/step+1e-9)) * step
+		}
+
+		if minQty, _ := info.MinOrderQty.Float64(); decision.Quantity < minQty {
+			return reject(RejectBelowMinimum, "quantity %.8f is below the minimum %.8f for %s",
+				decision.Quantity, minQty, order.Symbol)
+		}
+		if minAmt, _ := info.MinOrderAmt.Float64(); decision.Quantity*order.Price < minAmt {
+			return reject(RejectBelowMinimum, "order value %.4f is below the minimum %.4f for %s",
+				decision.Quantity*order.Price, minAmt, order.Symbol)
+		}
+	}
+
+	if decision.Quantity <= 0 {
+		return reject(RejectInvalidOrder, "quantity rounds down to zero")
+	}
+
+	decision.Approved = true
+	return decision
+}
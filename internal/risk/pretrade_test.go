@@ -0,0 +1,28 @@
+package risk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestPreTradeHeadroomInReportingCurrency(t *testing.T) {
+	rm := &RiskManager{
+		Config: &config.Config{TotalCapital: 100000, MaxPositionPerCoin: 1000, PreTradeResize: true},
+		Positions: map[string]PositionRisk{
+			"BTCEUR": {Symbol: "BTCEUR", CurrentSize: 0.01, CurrentPrice: 50000},
+		},
+		ConversionRate: func(symbol string) (float64, bool) { return 1.1, true },
+	}
+	gate := NewPreTradeGate(rm, nil)
+
+	// The held 500 EUR are worth 550 of the 1000 limit, leaving 450 for the order
+	decision := gate.Check(OrderRequest{Symbol: "BTCEUR", Side: "BUY", Quantity: 0.01, Price: 50000, ConversionRate: 1.1})
+	if !decision.Approved || !decision.Resized {
+		t.Fatalf("decision = %+v, want approved and resized", decision)
+	}
+	if want := 450 / (50000 * 1.1); math.Abs(decision.Quantity-want) > 1e-12 {
+		t.Errorf("resized quantity = %v, want %v", decision.Quantity, want)
+	}
+}
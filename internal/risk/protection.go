@@ -0,0 +1,169 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/shopspring/decimal"
+)
+
+// protectiveOrderTolerance is the relative difference in trigger price or quantity below
+// which an exchange-side order is considered up to date
+const protectiveOrderTolerance = 0.001
+
+// Protective order action types
+const (
+	ProtectivePlace  = "PLACE"
+	ProtectiveAmend  = "AMEND"
+	ProtectiveCancel = "CANCEL"
+)
+
+// ProtectiveOrderAction is a change needed to bring exchange-side stop-loss and take-profit
+// orders in line with the tracked positions
+type ProtectiveOrderAction struct {
+	Type    string // PLACE, AMEND, CANCEL
+	Order   bybit.ConditionalOrder
+	Message string
+}
+
+// PlanProtectiveOrders reconciles the bot's open conditional orders on the exchange against
+// the stop-loss (or trailing stop) and take-profit levels of each long position. Missing orders
+// are placed, orders with a moved trigger price are amended, orders with a stale quantity are
+// replaced and orders for closed positions or duplicates are cancelled.
+func (rm *RiskManager) PlanProtectiveOrders(existing []bybit.ConditionalOrder) []ProtectiveOrderAction {
+	var actions []ProtectiveOrderAction
+
+	// Group existing orders by symbol and kind
+	existingByKey := make(map[string][]bybit.ConditionalOrder)
+	for _, order := range existing {
+		key := order.Symbol + "/" + order.Kind
+		existingByKey[key] = append(existingByKey[key], order)
+	}
+
+	// Desired orders for each open long position
+	desired := make(map[string]bybit.ConditionalOrder)
+	for symbol, pos := range rm.Positions {
+		if pos.CurrentSize <= 0 {
+			continue
+		}
+
+		stopLevel := pos.StopLossLevel
+		if pos.IsTrailingStopSet && pos.TrailingStopLevel > stopLevel {
+			stopLevel = pos.TrailingStopLevel
+		}
+
+		quantity := decimal.NewFromFloat(pos.CurrentSize)
+		if stopLevel > 0 {
+			desired[symbol+"/"+bybit.ConditionalStopLoss] = bybit.ConditionalOrder{
+				Symbol:       symbol,
+				Kind:         bybit.ConditionalStopLoss,
+				Side:         "SELL",
+				Quantity:     quantity,
+				TriggerPrice: decimal.NewFromFloat(stopLevel),
+			}
+		}
+		if pos.TakeProfitLevel > 0 {
+			desired[symbol+"/"+bybit.ConditionalTakeProfit] = bybit.ConditionalOrder{
+				Symbol:       symbol,
+				Kind:         bybit.ConditionalTakeProfit,
+				Side:         "SELL",
+				Quantity:     quantity,
+				TriggerPrice: decimal.NewFromFloat(pos.TakeProfitLevel),
+			}
+		}
+	}
+
+	// Process keys in a stable order so actions are deterministic
+	keys := make([]string, 0, len(desired)+len(existingByKey))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	for key := range existingByKey {
+		if _, exists := desired[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		want, wanted := desired[key]
+		orders := existingByKey[key]
+
+		// Cancel orders for positions that are closed
+		if !wanted {
+			for _, order := range orders {
+				actions = append(actions, ProtectiveOrderAction{
+					Type:    ProtectiveCancel,
+					Order:   order,
+					Message: fmt.Sprintf("Cancel %s order %s for %s: no open position", order.Kind, order.OrderID, order.Symbol),
+				})
+			}
+			continue
+		}
+
+		if len(orders) == 0 {
+			actions = append(actions, ProtectiveOrderAction{
+				Type:  ProtectivePlace,
+				Order: want,
+				Message: fmt.Sprintf("Place %s order for %s: %s at %s",
+					want.Kind, want.Symbol, want.Quantity.String(), want.TriggerPrice.StringFixed(4)),
+			})
+			continue
+		}
+
+		// Keep the first order and cancel duplicates
+		current := orders[0]
+		for _, order := range orders[1:] {
+			actions = append(actions, ProtectiveOrderAction{
+				Type:    ProtectiveCancel,
+				Order:   order,
+				Message: fmt.Sprintf("Cancel duplicate %s order %s for %s", order.Kind, order.OrderID, order.Symbol),
+			})
+		}
+
+		// A changed position size needs a new order
+		if !withinTolerance(current.Quantity, want.Quantity) {
+			actions = append(actions,
+				ProtectiveOrderAction{
+					Type:  ProtectiveCancel,
+					Order: current,
+					Message: fmt.Sprintf("Cancel %s order %s for %s: quantity %s no longer matches position %s",
+						current.Kind, current.OrderID, current.Symbol, current.Quantity.String(), want.Quantity.String()),
+				},
+				ProtectiveOrderAction{
+					Type:  ProtectivePlace,
+					Order: want,
+					Message: fmt.Sprintf("Place %s order for %s: %s at %s",
+						want.Kind, want.Symbol, want.Quantity.String(), want.TriggerPrice.StringFixed(4)),
+				})
+			continue
+		}
+
+		// Move the trigger price when the level has changed (e.g. a trailing stop moved up)
+		if !withinTolerance(current.TriggerPrice, want.TriggerPrice) {
+			amended := current
+			amended.TriggerPrice = want.TriggerPrice
+			actions = append(actions, ProtectiveOrderAction{
+				Type:  ProtectiveAmend,
+				Order: amended,
+				Message: fmt.Sprintf("Amend %s order %s for %s: trigger %s -> %s",
+					current.Kind, current.OrderID, current.Symbol,
+					current.TriggerPrice.StringFixed(4), want.TriggerPrice.StringFixed(4)),
+			})
+		}
+	}
+
+	return actions
+}
+
+// withinTolerance reports whether two values differ by less than protectiveOrderTolerance
+func withinTolerance(actual, target decimal.Decimal) bool {
+	targetValue, _ := target.Float64()
+	if targetValue == 0 {
+		return actual.IsZero()
+	}
+	actualValue, _ := actual.Float64()
+	return math.Abs(actualValue-targetValue)/math.Abs(targetValue) < protectiveOrderTolerance
+}
@@ -0,0 +1,191 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RiskLimitUsage reports how much of a risk limit is currently used
+type RiskLimitUsage struct {
+	Rule        string  `json:"rule"`
+	Severity    string  `json:"severity"`
+	Value       float64 `json:"value"`
+	Limit       float64 `json:"limit"`
+	Utilization float64 `json:"utilization_percent"` // Value as a percentage of the limit
+}
+
+// SymbolLimits holds the per-symbol limits of a position whose limits differ from the defaults
+type SymbolLimits struct {
+	Symbol            string  `json:"symbol"`
+	StopLossPercent   float64 `json:"stop_loss_percent"`
+	TakeProfitPercent float64 `json:"take_profit_percent"`
+	MaxDrawdown       float64 `json:"max_drawdown"`
+}
+
+// RiskReport is a typed snapshot of the portfolio risk, its limits and violations
+type RiskReport struct {
+	GeneratedAt       time.Time          `json:"generated_at"`
+	TotalExposure     float64            `json:"total_exposure"`
+	ExposurePercent   float64            `json:"exposure_percent"` // Exposure as a percentage of capital
+	PortfolioDrawdown float64            `json:"portfolio_drawdown"`
+	Volatility        float64            `json:"volatility"`
+	CorrelationRisk   float64            `json:"correlation_risk"`
+	ConcentrationHHI  float64            `json:"concentration_hhi"`
+	VaR               VaRReport          `json:"var"`
+	CategoryExposure  map[string]float64 `json:"category_exposure"`
+	CapitalBuckets    []CapitalBucket    `json:"capital_buckets"` // Per-strategy capital utilization
+	StopLossPercent   float64            `json:"stop_loss_percent"`
+	TakeProfitPercent float64            `json:"take_profit_percent"`
+	MaxDrawdown       float64            `json:"max_drawdown"`
+	SymbolLimits      []SymbolLimits     `json:"symbol_limits"`
+	Limits            []RiskLimitUsage   `json:"limits"`
+	Warnings          []string           `json:"warnings"`
+	Violations        []RuleViolation    `json:"violations"`
+	Halted            bool               `json:"halted"`
+	ShouldStopTrading bool               `json:"should_stop_trading"`
+}
+
+// GetRiskReportStruct builds a typed risk report with the current metrics, the utilization of
+// every measurable rule and all violations
+func (rm *RiskManager) GetRiskReportStruct() *RiskReport {
+	metrics := rm.CalculateRiskMetrics()
+	state := rm.riskState(metrics)
+
+	report := &RiskReport{
+		GeneratedAt:       time.Now(),
+		TotalExposure:     metrics.TotalExposure,
+		PortfolioDrawdown: metrics.PortfolioDrawdown,
+		Volatility:        metrics.Volatility,
+		CorrelationRisk:   metrics.CorrelationRisk,
+		ConcentrationHHI:  metrics.ConcentrationHHI,
+		VaR:               metrics.VaR,
+		CategoryExposure:  rm.GetCategoryExposure(),
+		CapitalBuckets:    rm.GetCapitalBuckets(),
+		StopLossPercent:   rm.Config.GetStopLossPercent(),
+		TakeProfitPercent: rm.Config.GetTakeProfitPercent(),
+		MaxDrawdown:       rm.Config.GetMaxDrawdown(),
+		SymbolLimits:      make([]SymbolLimits, 0),
+		Limits:            make([]RiskLimitUsage, 0, len(rm.Rules)),
+		Warnings:          rm.CheckCorrelationConcentration(),
+		Violations:        rm.evaluateRules(state),
+		Halted:            rm.IsHalted(),
+	}
+	if rm.Config.TotalCapital > 0 {
+		report.ExposurePercent = metrics.TotalExposure / rm.Config.TotalCapital * 100
+	}
+
+	// List positions whose limits differ from the global defaults
+	symbols := make([]string, 0, len(rm.Positions))
+	for symbol := range rm.Positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		limits := SymbolLimits{
+			Symbol:            symbol,
+			StopLossPercent:   rm.StopLossPercent(symbol),
+			TakeProfitPercent: rm.TakeProfitPercent(symbol),
+			MaxDrawdown:       rm.MaxDrawdown(symbol),
+		}
+		if limits.StopLossPercent != report.StopLossPercent || limits.TakeProfitPercent != report.TakeProfitPercent ||
+			limits.MaxDrawdown != report.MaxDrawdown {
+			report.SymbolLimits = append(report.SymbolLimits, limits)
+		}
+	}
+
+	// Report utilization of every rule that exposes its measurement
+	for _, rule := range rm.Rules {
+		measured, ok := rule.(MeasuredRule)
+		if !ok {
+			continue
+		}
+		value, limit := measured.Measure(state)
+		usage := RiskLimitUsage{
+			Rule:     rule.Name(),
+			Severity: measured.Severity(),
+			Value:    value,
+			Limit:    limit,
+		}
+		if limit > 0 {
+			usage.Utilization = value / limit * 100
+		}
+		report.Limits = append(report.Limits, usage)
+	}
+
+	for _, violation := range report.Violations {
+		if violation.Severity == SeverityCritical {
+			report.ShouldStopTrading = true
+		}
+	}
+
+	return report
+}
+
+// String renders the report as human-readable text for logs and notifications
+func (r *RiskReport) String() string {
+	var b strings.Builder
+
+	b.WriteString("Risk Report:\n")
+	fmt.Fprintf(&b, "  Total Exposure: $%.2f (%.1f%% of capital)\n", r.TotalExposure, r.ExposurePercent)
+	fmt.Fprintf(&b, "  Portfolio Drawdown: %.2f%%\n", r.PortfolioDrawdown*100)
+	fmt.Fprintf(&b, "  Portfolio Volatility: %.2f%%\n", r.Volatility*100)
+	fmt.Fprintf(&b, "  Correlation Risk: %.2f\n", r.CorrelationRisk)
+	if r.ConcentrationHHI > 0 {
+		fmt.Fprintf(&b, "  Concentration (HHI): %.2f (%.1f effective positions)\n", r.ConcentrationHHI, 1/r.ConcentrationHHI)
+	}
+
+	categories := make([]string, 0, len(r.CategoryExposure))
+	for category := range r.CategoryExposure {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		fmt.Fprintf(&b, "  Category %s Exposure: $%.2f\n", category, r.CategoryExposure[category])
+	}
+	for _, bucket := range r.CapitalBuckets {
+		if bucket.Budget > 0 {
+			fmt.Fprintf(&b, "  Strategy %s Capital: $%.2f of $%.2f (%.1f%%)\n", bucket.Strategy, bucket.Used, bucket.Budget, bucket.Utilization)
+		}
+	}
+
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "  WARNING: %s\n", warning)
+	}
+	fmt.Fprintf(&b, "  VaR 95%%/99%% (parametric): $%.2f / $%.2f, CVaR: $%.2f / $%.2f\n",
+		r.VaR.Parametric.VaR95, r.VaR.Parametric.VaR99, r.VaR.Parametric.CVaR95, r.VaR.Parametric.CVaR99)
+	fmt.Fprintf(&b, "  VaR 95%%/99%% (historical): $%.2f / $%.2f, CVaR: $%.2f / $%.2f\n",
+		r.VaR.Historical.VaR95, r.VaR.Historical.VaR99, r.VaR.Historical.CVaR95, r.VaR.Historical.CVaR99)
+
+	// Add stop-loss and take-profit information
+	fmt.Fprintf(&b, "  Stop-Loss Level: %.2f%%\n", r.StopLossPercent)
+	fmt.Fprintf(&b, "  Take-Profit Level: %.2f%%\n", r.TakeProfitPercent)
+
+	// Add symbol drawdown information
+	fmt.Fprintf(&b, "  Symbol Drawdown Limits: %.2f%%\n", r.MaxDrawdown*100)
+	for _, limits := range r.SymbolLimits {
+		fmt.Fprintf(&b, "    %s: Stop-Loss %.2f%%, Take-Profit %.2f%%, Max Drawdown %.2f%%\n",
+			limits.Symbol, limits.StopLossPercent, limits.TakeProfitPercent, limits.MaxDrawdown*100)
+	}
+
+	// Show how much of each limit is used
+	for _, usage := range r.Limits {
+		fmt.Fprintf(&b, "  Limit %s (%s): %.4f / %.4f (%.1f%%)\n",
+			usage.Rule, usage.Severity, usage.Value, usage.Limit, usage.Utilization)
+	}
+
+	// Report every rule violation
+	for _, violation := range r.Violations {
+		fmt.Fprintf(&b, "  RULE VIOLATION [%s] %s: %s\n", violation.Severity, violation.Rule, violation.Message)
+	}
+
+	if r.Halted {
+		b.WriteString("  WARNING: Trading is halted\n")
+	}
+	if r.ShouldStopTrading {
+		b.WriteString("  WARNING: Trading should be stopped due to excessive risk!\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,315 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// Rule severities. Warnings fail CheckPortfolioRisk, critical violations also stop trading.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// RiskState is the snapshot of the portfolio that risk rules are evaluated against
+type RiskState struct {
+	Metrics   *RiskMetrics
+	Positions map[string]PositionRisk
+	Config    *config.Config
+}
+
+// RuleViolation describes a breached risk rule
+type RuleViolation struct {
+	Rule     string  `json:"rule"`
+	Severity string  `json:"severity"`
+	Value    float64 `json:"value"`
+	Limit    float64 `json:"limit"`
+	Message  string  `json:"message"`
+}
+
+// RiskRule is a single limit in the risk rules pipeline
+type RiskRule interface {
+	// Name identifies the rule in reports
+	Name() string
+	// Evaluate returns a violation, or nil if the rule holds
+	Evaluate(state *RiskState) *RuleViolation
+}
+
+// MeasuredRule is a rule that can report its current value and limit, e.g. for utilization reports
+type MeasuredRule interface {
+	RiskRule
+	// Measure returns the measured value and the limit it is compared against
+	Measure(state *RiskState) (float64, float64)
+	// Severity returns the severity of a violation
+	Severity() string
+}
+
+// RuleSpec is the file representation of a rule
+type RuleSpec struct {
+	Type     string  `json:"type"` // max_exposure, max_drawdown, max_open_positions, max_correlation, max_var, max_concentration
+	Limit    float64 `json:"limit"`
+	Severity string  `json:"severity"` // warning (default) or critical
+}
+
+// thresholdRule is a rule that compares one measured value against an upper limit
+type thresholdRule struct {
+	name  string
+	limit float64
+	// Reads the limit off the configuration on every evaluation instead, for limits that can
+	// change while the bot runs
+	configLimit func(cfg *config.Config) float64
+	severity    string
+	measure     func(state *RiskState) float64
+	format      func(value, limit float64) string
+}
+
+// Name returns the rule name
+func (r *thresholdRule) Name() string {
+	return r.name
+}
+
+// Measure returns the measured value and the limit
+func (r *thresholdRule) Measure(state *RiskState) (float64, float64) {
+	return r.measure(state), r.limitOf(state)
+}
+
+// limitOf returns the limit the measured value is compared against
+func (r *thresholdRule) limitOf(state *RiskState) float64 {
+	if r.configLimit != nil && state.Config != nil {
+		return r.configLimit(state.Config)
+	}
+	return r.limit
+}
+
+// Severity returns the severity of a violation
+func (r *thresholdRule) Severity() string {
+	return r.severity
+}
+
+// Evaluate compares the measured value against the limit
+func (r *thresholdRule) Evaluate(state *RiskState) *RuleViolation {
+	value, limit := r.Measure(state)
+	if value <= limit {
+		return nil
+	}
+
+	return &RuleViolation{
+		Rule:     r.name,
+		Severity: r.severity,
+		Value:    value,
+		Limit:    limit,
+		Message:  r.format(value, limit),
+	}
+}
+
+// NewMaxExposureRule limits total exposure as a multiple of capital
+func NewMaxExposureRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_exposure",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			if state.Config.TotalCapital <= 0 {
+				return 0
+			}
+			return state.Metrics.TotalExposure / state.Config.TotalCapital
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("total exposure %.2fx capital exceeds %.2fx", value, limit)
+		},
+	}
+}
+
+// NewMaxDrawdownRule limits the portfolio drawdown
+func NewMaxDrawdownRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_drawdown",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			return state.Metrics.PortfolioDrawdown
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("portfolio drawdown %.2f%% exceeds maximum allowed %.2f%%", value*100, limit*100)
+		},
+	}
+}
+
+// newConfigMaxDrawdownRule limits the portfolio drawdown to a multiple of the configured maximum
+// drawdown, following changes of it while the bot runs
+func newConfigMaxDrawdownRule(multiple float64, severity string) RiskRule {
+	rule := NewMaxDrawdownRule(0, severity).(*thresholdRule)
+	rule.configLimit = func(cfg *config.Config) float64 {
+		return cfg.GetMaxDrawdown() * multiple
+	}
+	return rule
+}
+
+// NewMaxOpenPositionsRule limits the number of open positions
+func NewMaxOpenPositionsRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_open_positions",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			count := 0
+			for _, pos := range state.Positions {
+				if pos.CurrentSize != 0 {
+					count++
+				}
+			}
+			return float64(count)
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("%.0f open positions exceed maximum of %.0f", value, limit)
+		},
+	}
+}
+
+// NewMaxCorrelationRule limits the position-weighted average correlation
+func NewMaxCorrelationRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_correlation",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			return state.Metrics.CorrelationRisk
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("average correlation %.2f exceeds maximum of %.2f", value, limit)
+		},
+	}
+}
+
+// NewMaxVaRRule limits Value-at-Risk as a fraction of capital at the configured confidence
+func NewMaxVaRRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_var",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			if state.Config.TotalCapital <= 0 {
+				return 0
+			}
+			return state.Metrics.VaR.At(state.Config.VaRConfidence) / state.Config.TotalCapital
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("VaR %.2f%% of capital exceeds limit %.2f%%", value*100, limit*100)
+		},
+	}
+}
+
+// NewMaxConcentrationRule limits the Herfindahl-Hirschman index of position weights
+func NewMaxConcentrationRule(limit float64, severity string) RiskRule {
+	return &thresholdRule{
+		name:     "max_concentration",
+		limit:    limit,
+		severity: severity,
+		measure: func(state *RiskState) float64 {
+			return state.Metrics.ConcentrationHHI
+		},
+		format: func(value, limit float64) string {
+			return fmt.Sprintf("position concentration HHI %.2f exceeds ceiling %.2f", value, limit)
+		},
+	}
+}
+
+// DefaultRiskRules returns the built-in limits derived from the configuration
+func DefaultRiskRules(cfg *config.Config) []RiskRule {
+	rules := []RiskRule{
+		newConfigMaxDrawdownRule(1, SeverityWarning),
+		NewMaxExposureRule(1.0, SeverityWarning),
+		// Stop trading at 2x the maximum drawdown or 1.5x capital exposure
+		newConfigMaxDrawdownRule(2, SeverityCritical),
+		NewMaxExposureRule(1.5, SeverityCritical),
+	}
+
+	if cfg.MaxVaRPercent > 0 {
+		rules = append(rules, NewMaxVaRRule(cfg.MaxVaRPercent/100, SeverityWarning))
+	}
+
+	if cfg.MaxConcentrationHHI > 0 {
+		rules = append(rules, NewMaxConcentrationRule(cfg.MaxConcentrationHHI, SeverityWarning))
+	}
+
+	return rules
+}
+
+// NewRiskRule builds a rule from its file representation
+func NewRiskRule(spec RuleSpec) (RiskRule, error) {
+	severity := spec.Severity
+	if severity == "" {
+		severity = SeverityWarning
+	}
+	if severity != SeverityWarning && severity != SeverityCritical {
+		return nil, fmt.Errorf("unknown severity %q for rule %s", spec.Severity, spec.Type)
+	}
+
+	switch spec.Type {
+	case "max_exposure":
+		return NewMaxExposureRule(spec.Limit, severity), nil
+	case "max_drawdown":
+		return NewMaxDrawdownRule(spec.Limit, severity), nil
+	case "max_open_positions":
+		return NewMaxOpenPositionsRule(spec.Limit, severity), nil
+	case "max_correlation":
+		return NewMaxCorrelationRule(spec.Limit, severity), nil
+	case "max_var":
+		return NewMaxVaRRule(spec.Limit, severity), nil
+	case "max_concentration":
+		return NewMaxConcentrationRule(spec.Limit, severity), nil
+	default:
+		return nil, fmt.Errorf("unknown risk rule type %q", spec.Type)
+	}
+}
+
+// LoadRiskRules reads a JSON array of rule specs from path
+func LoadRiskRules(path string) ([]RiskRule, error) {
+	var specs []RuleSpec
+	found, err := persistence.LoadJSON(path, &specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load risk rules: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("risk rules file %s not found", path)
+	}
+
+	rules := make([]RiskRule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := NewRiskRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// EvaluateRules runs every rule in the pipeline and returns the violations
+func (rm *RiskManager) EvaluateRules() []RuleViolation {
+	return rm.evaluateRules(rm.riskState(rm.CalculateRiskMetrics()))
+}
+
+// riskState builds the snapshot that rules are evaluated against
+func (rm *RiskManager) riskState(metrics *RiskMetrics) *RiskState {
+	return &RiskState{
+		Metrics:   metrics,
+		Positions: rm.Positions,
+		Config:    rm.Config,
+	}
+}
+
+// evaluateRules runs every rule against the given state
+func (rm *RiskManager) evaluateRules(state *RiskState) []RuleViolation {
+	var violations []RuleViolation
+	for _, rule := range rm.Rules {
+		if violation := rule.Evaluate(state); violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	return violations
+}
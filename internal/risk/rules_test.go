@@ -0,0 +1,68 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+func TestDefaultDrawdownRulesFollowConfig(t *testing.T) {
+	cfg := &config.Config{TotalCapital: 1000, MaxDrawdown: 0.1}
+	rules := DefaultRiskRules(cfg)
+	state := &RiskState{Metrics: &RiskMetrics{PortfolioDrawdown: 0.15}, Config: cfg}
+
+	severities := func() map[string]bool {
+		found := make(map[string]bool)
+		for _, rule := range rules {
+			if violation := rule.Evaluate(state); violation != nil && violation.Rule == "max_drawdown" {
+				found[violation.Severity] = true
+			}
+		}
+		return found
+	}
+
+	if got := severities(); !got[SeverityWarning] || got[SeverityCritical] {
+		t.Fatalf("15%% drawdown against a 10%% limit: got violations %v, want only a warning", got)
+	}
+
+	max := 0.05
+	if err := cfg.ApplyTunables(config.Tunables{MaxDrawdown: &max}); err != nil {
+		t.Fatal(err)
+	}
+	if got := severities(); !got[SeverityCritical] {
+		t.Errorf("15%% drawdown after lowering the limit to 5%%: got %v, want a critical violation", got)
+	}
+
+	max = 0.5
+	if err := cfg.ApplyTunables(config.Tunables{MaxDrawdown: &max}); err != nil {
+		t.Fatal(err)
+	}
+	if got := severities(); len(got) != 0 {
+		t.Errorf("15%% drawdown after raising the limit to 50%%: got %v, want none", got)
+	}
+}
+
+func TestDefaultVaRRuleReadsPercent(t *testing.T) {
+	cfg := &config.Config{TotalCapital: 1000, MaxDrawdown: 0.1, MaxVaRPercent: 5, VaRConfidence: 0.95}
+	rules := DefaultRiskRules(cfg)
+
+	violated := func(var95 float64) bool {
+		state := &RiskState{
+			Metrics: &RiskMetrics{VaR: VaRReport{Historical: VaRLevels{VaR95: var95}}},
+			Config:  cfg,
+		}
+		for _, rule := range rules {
+			if violation := rule.Evaluate(state); violation != nil && violation.Rule == "max_var" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if violated(40) {
+		t.Error("VaR of 4% of capital against a 5% limit: got a violation")
+	}
+	if !violated(60) {
+		t.Error("VaR of 6% of capital against a 5% limit: got no violation")
+	}
+}
@@ -0,0 +1,44 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// TradingSessions returns the sessions a strategy may trade a symbol in: the configured
+// sessions of the strategy/symbol pair, of the strategy, the sessions the strategy declares
+// itself, or the "*" sessions, in that order. Nil means the strategy may always trade.
+func (rm *RiskManager) TradingSessions(symbol, strategy string) []config.TradingSession {
+	key := strings.ToUpper(strategy)
+	if sessions, exists := rm.Config.StrategySessions[key+"/"+symbol]; exists {
+		return sessions
+	}
+	if sessions, exists := rm.Config.StrategySessions[key]; exists {
+		return sessions
+	}
+	if sessions, exists := rm.Sessions[strategy]; exists {
+		return sessions
+	}
+	return rm.Config.StrategySessions["*"]
+}
+
+// IsOutsideSession reports whether a strategy is outside all of its trading sessions for a symbol
+func (rm *RiskManager) IsOutsideSession(symbol, strategy string, now time.Time) (bool, string) {
+	sessions := rm.TradingSessions(symbol, strategy)
+	if len(sessions) == 0 {
+		return false, ""
+	}
+
+	windows := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Contains(now) {
+			return false, ""
+		}
+		windows = append(windows, session.String())
+	}
+	return true, fmt.Sprintf("%s on %s outside its trading sessions (%s UTC)",
+		strategy, symbol, strings.Join(windows, ", "))
+}
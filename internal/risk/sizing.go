@@ -0,0 +1,138 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// PositionSizer sizes orders so that a stop placed N×ATR away risks a fixed fraction of capital
+type PositionSizer struct {
+	Config        *config.Config
+	ATRPeriod     int
+	ATRMultiplier float64
+}
+
+// PositionSize is the result of sizing an order
+type PositionSize struct {
+	Quantity     float64
+	ATR          float64
+	StopDistance float64 // Distance between entry and stop in price units
+	RiskAmount   float64 // Amount lost if the stop is hit, in the quote currency
+	Capped       bool    // Whether the quantity was limited by the maximum order value
+}
+
+// NewPositionSizer creates a new PositionSizer
+func NewPositionSizer(cfg *config.Config) *PositionSizer {
+	return &PositionSizer{
+		Config:        cfg,
+		ATRPeriod:     cfg.ATRPeriod,
+		ATRMultiplier: cfg.ATRStopMultiplier,
+	}
+}
+
+// CalculateATR returns the Average True Range over the given period using Wilder's smoothing
+func CalculateATR(klines []bybit.KlineData, period int) float64 {
+	if period <= 0 || len(klines) < period+1 {
+		return 0
+	}
+
+	trueRanges := make([]float64, 0, len(klines)-1)
+	for i := 1; i < len(klines); i++ {
+		high, _ := klines[i].High.Float64()
+		low, _ := klines[i].Low.Float64()
+		prevClose, _ := klines[i-1].Close.Float64()
+
+		trueRange := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+		trueRanges = append(trueRanges, trueRange)
+	}
+
+	// Seed with a simple average, then smooth the remaining values
+	atr := 0.0
+	for _, tr := range trueRanges[:period] {
+		atr += tr
+	}
+	atr /= float64(period)
+
+	for _, tr := range trueRanges[period:] {
+		atr = (atr*float64(period-1) + tr) / float64(period)
+	}
+
+	return atr
+}
+
+// Size calculates the order quantity for the latest price in data. capital is the account
+// value used for the risk budget and maxValue caps the order value, both in the quote currency;
+// a maxValue of zero or less, such as a symbol without allocation, sizes nothing.
+func (ps *PositionSizer) Size(data *bybit.MarketData, capital, maxValue float64) (PositionSize, error) {
+	var result PositionSize
+
+	if data == nil || len(data.Kline) == 0 {
+		return result, fmt.Errorf("no market data to size position")
+	}
+
+	price, _ := data.Kline[len(data.Kline)-1].Close.Float64()
+	if price <= 0 {
+		return result, fmt.Errorf("invalid price %.8f for %s", price, data.Symbol)
+	}
+
+	if ps.Config.RiskPerTrade <= 0 {
+		return result, fmt.Errorf("risk per trade is not configured")
+	}
+
+	result.ATR = CalculateATR(data.Kline, ps.ATRPeriod)
+	if result.ATR <= 0 {
+		return result, fmt.Errorf("not enough data to calculate ATR(%d) for %s", ps.ATRPeriod, data.Symbol)
+	}
+
+	// Size so that hitting a stop N×ATR away loses at most RiskPerTrade of capital
+	result.StopDistance = result.ATR * ps.ATRMultiplier
+	return ps.sizeForStop(result, price, capital, maxValue), nil
+}
+
+// SizeWithStop sizes an order like Size, but for a stop at the given price instead of N×ATR
+// away. The stop is below the latest price for longs and above it for shorts; a stop at the
+// price itself falls back to Size.
+func (ps *PositionSizer) SizeWithStop(data *bybit.MarketData, capital, maxValue, stopPrice float64) (PositionSize, error) {
+	if data == nil || len(data.Kline) == 0 {
+		return PositionSize{}, fmt.Errorf("no market data to size position")
+	}
+
+	price, _ := data.Kline[len(data.Kline)-1].Close.Float64()
+	if stopPrice <= 0 || stopPrice == price {
+		return ps.Size(data, capital, maxValue)
+	}
+
+	if ps.Config.RiskPerTrade <= 0 {
+		return PositionSize{}, fmt.Errorf("risk per trade is not configured")
+	}
+
+	result := PositionSize{
+		ATR:          CalculateATR(data.Kline, ps.ATRPeriod),
+		StopDistance: math.Abs(price - stopPrice),
+	}
+	return ps.sizeForStop(result, price, capital, maxValue), nil
+}
+
+// sizeForStop fills in the quantity and risk of a position whose stop distance is known
+func (ps *PositionSizer) sizeForStop(result PositionSize, price, capital, maxValue float64) PositionSize {
+	if maxValue <= 0 {
+		result.Capped = true
+		return result
+	}
+
+	riskBudget := capital * ps.Config.RiskPerTrade
+	result.Quantity = riskBudget / result.StopDistance
+
+	// Never exceed the maximum order value
+	if result.Quantity*price > maxValue {
+		result.Quantity = maxValue / price
+		result.Capped = true
+	}
+
+	result.RiskAmount = result.Quantity * result.StopDistance
+
+	return result
+}
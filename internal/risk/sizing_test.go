@@ -0,0 +1,43 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/config"
+	"github.com/forbest/bybitgo/internal/testutil"
+)
+
+func TestSizeCapsAtMaxValue(t *testing.T) {
+	sizer := NewPositionSizer(&config.Config{RiskPerTrade: 0.01, ATRPeriod: 14, ATRStopMultiplier: 2})
+	data := testutil.NewKlineBuilder(100, 1).Trend(30, 0.5).MarketData("BTCUSDT")
+	price := testutil.NewKlineBuilder(100, 1).Trend(30, 0.5).Price()
+
+	cases := []struct {
+		name       string
+		maxValue   float64
+		wantZero   bool
+		wantCapped bool
+	}{
+		{"no allocation", 0, true, true},
+		{"negative allocation", -100, true, true},
+		{"small allocation", 50, false, true},
+		{"large allocation", 1e9, false, false},
+	}
+	for _, tc := range cases {
+		size, err := sizer.Size(data, 10000, tc.maxValue)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if (size.Quantity == 0) != tc.wantZero || size.Capped != tc.wantCapped {
+			t.Errorf("%s: got quantity %v capped %t, want zero %t capped %t",
+				tc.name, size.Quantity, size.Capped, tc.wantZero, tc.wantCapped)
+		}
+		if tc.maxValue > 0 && size.Quantity*price > tc.maxValue+1e-9 {
+			t.Errorf("%s: order value %v exceeds the maximum %v", tc.name, size.Quantity*price, tc.maxValue)
+		}
+	}
+
+	if size, err := sizer.SizeWithStop(data, 10000, 0, price*0.95); err != nil || size.Quantity != 0 {
+		t.Errorf("stop sizing without allocation: got quantity %v, error %v, want zero", size.Quantity, err)
+	}
+}
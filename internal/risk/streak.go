@@ -0,0 +1,126 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// Scopes a loss streak pause can apply to
+const (
+	PauseScopeBot      = "bot"
+	PauseScopeStrategy = "strategy"
+	PauseScopeSymbol   = "symbol"
+)
+
+// TradeOutcome is the realized result of a closed trade
+type TradeOutcome struct {
+	Symbol    string
+	Strategy  string
+	PnL       float64
+	Timestamp time.Time
+}
+
+// LossStreakPause records a strategy, symbol or the whole bot paused after a losing streak
+type LossStreakPause struct {
+	Scope  string    `json:"scope"`
+	Key    string    `json:"key"`
+	Losses int       `json:"losses"`
+	Until  time.Time `json:"until"`
+}
+
+// UpdateLossStreaks recomputes which strategies, symbols or the whole bot are paused.
+// A pause starts when the most recent N closed trades within the window were all losses
+// and lasts for the cooldown after the last of those losses.
+func (rm *RiskManager) UpdateLossStreaks(outcomes []TradeOutcome, now time.Time) []LossStreakPause {
+	rm.LossStreakPauses = nil
+
+	byStrategy := make(map[string][]TradeOutcome)
+	bySymbol := make(map[string][]TradeOutcome)
+	for _, outcome := range outcomes {
+		byStrategy[outcome.Strategy] = append(byStrategy[outcome.Strategy], outcome)
+		bySymbol[outcome.Symbol] = append(bySymbol[outcome.Symbol], outcome)
+	}
+
+	rm.checkLossStreak(PauseScopeBot, "", outcomes, rm.Config.LossStreakBotMax, now)
+	for strategy, trades := range byStrategy {
+		rm.checkLossStreak(PauseScopeStrategy, strategy, trades, rm.lossStreakLimit(strategy), now)
+	}
+	for symbol, trades := range bySymbol {
+		rm.checkLossStreak(PauseScopeSymbol, symbol, trades, rm.lossStreakLimit(symbol), now)
+	}
+
+	return rm.LossStreakPauses
+}
+
+// checkLossStreak adds a pause if the trailing losing streak of outcomes reaches the limit
+func (rm *RiskManager) checkLossStreak(scope, key string, outcomes []TradeOutcome, limit int, now time.Time) {
+	if limit <= 0 {
+		return
+	}
+
+	window := time.Duration(rm.Config.LossStreakWindowMinutes) * time.Minute
+	cooldown := time.Duration(rm.Config.LossStreakCooldownMinutes) * time.Minute
+
+	// Count consecutive losses from the most recent trade backwards
+	losses := 0
+	var lastLoss time.Time
+	for i := len(outcomes) - 1; i >= 0; i-- {
+		outcome := outcomes[i]
+		if outcome.PnL >= 0 || now.Sub(outcome.Timestamp) > window {
+			break
+		}
+		if losses == 0 {
+			lastLoss = outcome.Timestamp
+		}
+		losses++
+	}
+
+	if losses < limit || !now.Before(lastLoss.Add(cooldown)) {
+		return
+	}
+
+	rm.LossStreakPauses = append(rm.LossStreakPauses, LossStreakPause{
+		Scope:  scope,
+		Key:    key,
+		Losses: losses,
+		Until:  lastLoss.Add(cooldown),
+	})
+}
+
+// lossStreakLimit returns the maximum consecutive losses for a strategy or symbol
+func (rm *RiskManager) lossStreakLimit(key string) int {
+	if val, ok := config.SymbolValue(rm.Config.LossStreakOverrides, strings.ToUpper(key)); ok {
+		return int(val)
+	}
+	return rm.Config.LossStreakMax
+}
+
+// IsTradingPaused reports whether a loss streak pause or a drawdown cooldown applies to the
+// symbol and strategy, or the strategy is outside its trading sessions
+func (rm *RiskManager) IsTradingPaused(symbol, strategy string, now time.Time) (bool, string) {
+	for _, pause := range rm.LossStreakPauses {
+		if !now.Before(pause.Until) {
+			continue
+		}
+
+		applies := pause.Scope == PauseScopeBot ||
+			(pause.Scope == PauseScopeStrategy && pause.Key == strategy) ||
+			(pause.Scope == PauseScopeSymbol && pause.Key == symbol)
+		if applies {
+			target := pause.Scope
+			if pause.Key != "" {
+				target += " " + pause.Key
+			}
+			return true, fmt.Sprintf("%s paused after %d consecutive losses until %s",
+				target, pause.Losses, pause.Until.Format("15:04:05"))
+		}
+	}
+
+	if coolingDown, reason := rm.IsStrategyCoolingDown(strategy, now); coolingDown {
+		return true, reason
+	}
+	return rm.IsOutsideSession(symbol, strategy, now)
+}
@@ -0,0 +1,175 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// StressScenario describes a hypothetical market shock
+type StressScenario struct {
+	Name string `json:"name"`
+	// Immediate price moves per symbol as fractions (e.g. -0.2); "*" applies to all other symbols
+	PriceShocks map[string]float64 `json:"price_shocks,omitempty"`
+	// Symbol whose shock is propagated to other positions through their beta to it
+	ShockLeader string `json:"shock_leader,omitempty"`
+	// Forces every pairwise correlation to this value when set (e.g. 1)
+	CorrelationOverride *float64 `json:"correlation_override,omitempty"`
+	// Multiplies every position's volatility when greater than 1
+	VolatilityMultiplier float64 `json:"volatility_multiplier,omitempty"`
+}
+
+// StressResult reports the hypothetical loss of the current positions under a scenario
+type StressResult struct {
+	Scenario       string             `json:"scenario"`
+	Loss           float64            `json:"loss"`         // Positive numbers are losses
+	LossPercent    float64            `json:"loss_percent"` // Loss as a fraction of capital
+	PositionLosses map[string]float64 `json:"position_losses"`
+	Description    string             `json:"description"`
+}
+
+// DefaultStressScenarios returns the standard set of shock scenarios
+func DefaultStressScenarios() []StressScenario {
+	perfectCorrelation := 1.0
+
+	return []StressScenario{
+		{
+			Name:        "BTC -20%",
+			PriceShocks: map[string]float64{"BTCUSDT": -0.20},
+			ShockLeader: "BTCUSDT",
+		},
+		{
+			Name:        "Market -30%",
+			PriceShocks: map[string]float64{"*": -0.30},
+		},
+		{
+			Name:                "Correlations to 1",
+			CorrelationOverride: &perfectCorrelation,
+		},
+		{
+			Name:                 "Volatility x3",
+			VolatilityMultiplier: 3,
+		},
+	}
+}
+
+// RunStressTest applies each scenario to the current positions
+func (rm *RiskManager) RunStressTest(scenarios []StressScenario) []StressResult {
+	results := make([]StressResult, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		results = append(results, rm.runScenario(scenario))
+	}
+	return results
+}
+
+// runScenario computes the loss of a single scenario. Price shocks are applied directly,
+// correlation and volatility shocks are measured as the 99% parametric VaR under the shocked parameters.
+func (rm *RiskManager) runScenario(scenario StressScenario) StressResult {
+	result := StressResult{
+		Scenario:       scenario.Name,
+		PositionLosses: make(map[string]float64),
+	}
+
+	if len(scenario.PriceShocks) > 0 {
+		for _, symbol := range rm.positionSymbols() {
+			shock, ok := rm.priceShock(scenario, symbol)
+			if !ok {
+				continue
+			}
+			pos := rm.Positions[symbol]
+			loss := -pos.CurrentSize * pos.CurrentPrice * rm.conversionRate(symbol) * shock
+			result.PositionLosses[symbol] = loss
+			result.Loss += loss
+		}
+		result.Description = "Immediate price shock"
+	}
+
+	if scenario.CorrelationOverride != nil || scenario.VolatilityMultiplier > 1 {
+		result.Loss += rm.shockedVaR(scenario, result.PositionLosses)
+		result.Description = "99% one-interval VaR under shocked correlations and volatility"
+	}
+
+	if rm.Config.TotalCapital > 0 {
+		result.LossPercent = result.Loss / rm.Config.TotalCapital
+	}
+
+	return result
+}
+
+// priceShock returns the shock for a symbol, propagating the leader's shock through beta
+func (rm *RiskManager) priceShock(scenario StressScenario, symbol string) (float64, bool) {
+	if shock, ok := config.SymbolValue(scenario.PriceShocks, symbol); ok {
+		return shock, true
+	}
+	if scenario.ShockLeader == "" || rm.MarketAnalyzer == nil {
+		return 0, false
+	}
+
+	// Beta to the leader: correlation times the ratio of volatilities
+	leaderShock := scenario.PriceShocks[scenario.ShockLeader]
+	leaderVol := standardDeviation(rm.MarketAnalyzer.GetReturns(scenario.ShockLeader))
+	symbolVol := standardDeviation(rm.MarketAnalyzer.GetReturns(symbol))
+	if leaderVol == 0 {
+		return 0, false
+	}
+
+	beta := rm.correlation(symbol, scenario.ShockLeader) * symbolVol / leaderVol
+	return leaderShock * beta, true
+}
+
+// shockedVaR returns the 99% parametric VaR with shocked correlations and volatilities
+// and records each position's standalone contribution in positionLosses
+func (rm *RiskManager) shockedVaR(scenario StressScenario, positionLosses map[string]float64) float64 {
+	if rm.MarketAnalyzer == nil {
+		return 0
+	}
+
+	symbols, values, returns := rm.positionReturns()
+	if len(symbols) == 0 {
+		return 0
+	}
+
+	multiplier := 1.0
+	if scenario.VolatilityMultiplier > 1 {
+		multiplier = scenario.VolatilityMultiplier
+	}
+
+	stdDevs := make(map[string]float64)
+	for _, symbol := range symbols {
+		stdDevs[symbol] = standardDeviation(returns[symbol]) * multiplier
+		positionLosses[symbol] += values[symbol] * stdDevs[symbol] * normalQuantiles[0.99]
+	}
+
+	correlation := rm.correlation
+	if scenario.CorrelationOverride != nil {
+		override := *scenario.CorrelationOverride
+		correlation = func(s1, s2 string) float64 {
+			if s1 == s2 {
+				return 1
+			}
+			return override
+		}
+	}
+
+	return parametricLevels(portfolioSigma(symbols, values, stdDevs, correlation)).VaR99
+}
+
+// GetStressTestReport formats stress test results for logs and summaries
+func (rm *RiskManager) GetStressTestReport(results []StressResult) string {
+	report := "Stress Test:\n"
+	for _, result := range results {
+		report += fmt.Sprintf("  %s: loss $%.2f (%.2f%% of capital)\n",
+			result.Scenario, result.Loss, result.LossPercent*100)
+
+		symbols := make([]string, 0, len(result.PositionLosses))
+		for symbol := range result.PositionLosses {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			report += fmt.Sprintf("    %s: $%.2f\n", symbol, result.PositionLosses[symbol])
+		}
+	}
+	return report
+}
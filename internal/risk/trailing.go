@@ -0,0 +1,94 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TrailingStopTrigger is a reduce-only close order for a position whose trailing stop was breached
+type TrailingStopTrigger struct {
+	Symbol     string
+	Quantity   float64
+	EntryPrice float64
+	Price      float64
+	StopLevel  float64
+	PeakPrice  float64
+	Message    string
+}
+
+// TrailingStopPercent returns the trailing distance for a symbol, falling back to its stop-loss percentage
+func (rm *RiskManager) TrailingStopPercent(symbol string) float64 {
+	if percent := rm.Config.GetTrailingStopPercent(); percent > 0 {
+		return percent
+	}
+	return rm.StopLossPercent(symbol)
+}
+
+// UpdateTrailingStops applies live prices to the trailing stops of long positions. A trailing
+// stop is activated once the price has gained the activation threshold over the entry price,
+// then follows the highest price at the trailing distance. Positions whose price falls to the
+// trailing level are returned as close orders.
+func (rm *RiskManager) UpdateTrailingStops(prices map[string]float64) []TrailingStopTrigger {
+	var triggers []TrailingStopTrigger
+
+	symbols := make([]string, 0, len(prices))
+	for symbol := range prices {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		price := prices[symbol]
+		pos, exists := rm.Positions[symbol]
+		if !exists || pos.CurrentSize <= 0 || pos.EntryPrice <= 0 || price <= 0 {
+			continue
+		}
+
+		pos.CurrentPrice = price
+		pos.ObservePrice(price)
+		rm.Positions[symbol] = pos
+
+		// Activate the trailing stop once the position is far enough in profit
+		if !pos.IsTrailingStopSet {
+			gainPercent := (price - pos.EntryPrice) / pos.EntryPrice * 100
+			if gainPercent >= rm.Config.GetTrailingStopActivationPercent() {
+				rm.SetTrailingStop(symbol, price)
+			}
+			continue
+		}
+
+		// Follow new highs, the level never moves down
+		if price > pos.PeakPrice {
+			pos.PeakPrice = price
+			newLevel := price * (1 - rm.TrailingStopPercent(symbol)/100)
+			if newLevel > pos.TrailingStopLevel {
+				pos.TrailingStopLevel = newLevel
+			}
+			rm.Positions[symbol] = pos
+			continue
+		}
+
+		if price <= pos.TrailingStopLevel {
+			trigger := TrailingStopTrigger{
+				Symbol:     symbol,
+				Quantity:   pos.CurrentSize,
+				EntryPrice: pos.EntryPrice,
+				Price:      price,
+				StopLevel:  pos.TrailingStopLevel,
+				PeakPrice:  pos.PeakPrice,
+				Message: fmt.Sprintf("TRAILING_STOP: Close long position for %s at %.4f (trailing stop level: %.4f, peak: %.4f)",
+					symbol, price, pos.TrailingStopLevel, pos.PeakPrice),
+			}
+			triggers = append(triggers, trigger)
+			rm.EmitRiskEvent(RiskEventStopTriggered, SeverityWarning, symbol, trigger.Message)
+		}
+	}
+
+	return triggers
+}
+
+// RemovePosition stops tracking a position after it has been closed
+func (rm *RiskManager) RemovePosition(symbol string) {
+	delete(rm.Positions, symbol)
+	delete(rm.Plans, symbol)
+}
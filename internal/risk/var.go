@@ -0,0 +1,190 @@
+package risk
+
+import (
+	"math"
+	"sort"
+)
+
+// Standard normal quantiles for the supported confidence levels
+var normalQuantiles = map[float64]float64{
+	0.95: 1.6448536,
+	0.99: 2.3263479,
+}
+
+// VaRLevels holds Value-at-Risk and Conditional VaR (expected shortfall) as positive currency losses
+type VaRLevels struct {
+	VaR95  float64 `json:"var_95"`
+	VaR99  float64 `json:"var_99"`
+	CVaR95 float64 `json:"cvar_95"`
+	CVaR99 float64 `json:"cvar_99"`
+}
+
+// VaRReport holds VaR estimated with both methods over a single price interval
+type VaRReport struct {
+	Parametric   VaRLevels
+	Historical   VaRLevels
+	Observations int // Number of historical return observations used
+}
+
+// At returns the larger (more conservative) VaR of both methods at the given confidence
+func (r VaRReport) At(confidence float64) float64 {
+	if confidence >= 0.99 {
+		return math.Max(r.Parametric.VaR99, r.Historical.VaR99)
+	}
+	return math.Max(r.Parametric.VaR95, r.Historical.VaR95)
+}
+
+// CalculateVaR estimates portfolio VaR and CVaR at 95% and 99% confidence using the
+// analyzer's return history and correlation matrix
+func (rm *RiskManager) CalculateVaR() VaRReport {
+	var report VaRReport
+	if rm.MarketAnalyzer == nil {
+		return report
+	}
+
+	symbols, values, returns := rm.positionReturns()
+	if len(symbols) == 0 {
+		return report
+	}
+
+	report.Parametric = rm.parametricVaR(symbols, values, returns)
+	report.Historical, report.Observations = historicalVaR(symbols, values, returns)
+
+	return report
+}
+
+// positionReturns collects the value and return history of every position with enough data
+func (rm *RiskManager) positionReturns() ([]string, map[string]float64, map[string][]float64) {
+	symbols := make([]string, 0, len(rm.Positions))
+	values := make(map[string]float64)
+	returns := make(map[string][]float64)
+	for symbol, pos := range rm.Positions {
+		value := pos.CurrentSize * pos.CurrentPrice * rm.conversionRate(symbol)
+		symbolReturns := rm.MarketAnalyzer.GetReturns(symbol)
+		if value == 0 || len(symbolReturns) < 2 {
+			continue
+		}
+		symbols = append(symbols, symbol)
+		values[symbol] = value
+		returns[symbol] = symbolReturns
+	}
+	sort.Strings(symbols)
+
+	return symbols, values, returns
+}
+
+// portfolioSigma returns the standard deviation of portfolio PnL:
+// the square root of the sum over i,j of v_i * v_j * sigma_i * sigma_j * rho_ij
+func portfolioSigma(symbols []string, values, stdDevs map[string]float64, correlation func(string, string) float64) float64 {
+	variance := 0.0
+	for _, s1 := range symbols {
+		for _, s2 := range symbols {
+			variance += values[s1] * values[s2] * stdDevs[s1] * stdDevs[s2] * correlation(s1, s2)
+		}
+	}
+	if variance <= 0 {
+		return 0
+	}
+	return math.Sqrt(variance)
+}
+
+// parametricVaR computes variance-covariance VaR assuming normally distributed, zero-mean returns
+func (rm *RiskManager) parametricVaR(symbols []string, values map[string]float64, returns map[string][]float64) VaRLevels {
+	stdDevs := make(map[string]float64)
+	for _, symbol := range symbols {
+		stdDevs[symbol] = standardDeviation(returns[symbol])
+	}
+
+	return parametricLevels(portfolioSigma(symbols, values, stdDevs, rm.correlation))
+}
+
+// parametricLevels converts a portfolio PnL standard deviation into normal VaR and CVaR
+func parametricLevels(sigma float64) VaRLevels {
+	if sigma <= 0 {
+		return VaRLevels{}
+	}
+
+	// Expected shortfall of a normal distribution: sigma * pdf(z) / (1 - confidence)
+	cvar := func(confidence float64) float64 {
+		z := normalQuantiles[confidence]
+		pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+		return sigma * pdf / (1 - confidence)
+	}
+
+	return VaRLevels{
+		VaR95:  sigma * normalQuantiles[0.95],
+		VaR99:  sigma * normalQuantiles[0.99],
+		CVaR95: cvar(0.95),
+		CVaR99: cvar(0.99),
+	}
+}
+
+// historicalVaR replays the aligned return history against current position values
+func historicalVaR(symbols []string, values map[string]float64, returns map[string][]float64) (VaRLevels, int) {
+	// Align all series on their most recent observations
+	n := len(returns[symbols[0]])
+	for _, symbol := range symbols {
+		if len(returns[symbol]) < n {
+			n = len(returns[symbol])
+		}
+	}
+
+	pnl := make([]float64, n)
+	for _, symbol := range symbols {
+		series := returns[symbol][len(returns[symbol])-n:]
+		for t, r := range series {
+			pnl[t] += values[symbol] * r
+		}
+	}
+	sort.Float64s(pnl)
+
+	// VaR is the loss at the (1 - confidence) quantile, CVaR the average loss beyond it
+	levels := func(confidence float64) (float64, float64) {
+		tail := int(math.Ceil(float64(n) * (1 - confidence)))
+		if tail < 1 {
+			tail = 1
+		}
+		sum := 0.0
+		for _, loss := range pnl[:tail] {
+			sum += loss
+		}
+		return math.Max(-pnl[tail-1], 0), math.Max(-sum/float64(tail), 0)
+	}
+
+	var result VaRLevels
+	result.VaR95, result.CVaR95 = levels(0.95)
+	result.VaR99, result.CVaR99 = levels(0.99)
+
+	return result, n
+}
+
+// correlation returns the analyzer's correlation between two symbols
+func (rm *RiskManager) correlation(symbol1, symbol2 string) float64 {
+	if symbol1 == symbol2 {
+		return 1
+	}
+	if row, exists := rm.MarketAnalyzer.CorrelationMatrix[symbol1]; exists {
+		return row[symbol2]
+	}
+	return 0
+}
+
+// standardDeviation returns the sample standard deviation of values
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+}
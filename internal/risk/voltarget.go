@@ -0,0 +1,58 @@
+package risk
+
+import (
+	"math"
+	"sort"
+)
+
+// klinePeriodsPerYear annualizes the volatility of 5-minute kline returns (markets trade 24/7)
+const klinePeriodsPerYear = 365 * 24 * 12
+
+// VolatilityTarget is the outcome of scaling allocations towards a portfolio volatility target
+type VolatilityTarget struct {
+	TargetVolatility   float64 `json:"target_volatility"`   // Annualized
+	ForecastVolatility float64 `json:"forecast_volatility"` // Annualized volatility of the unscaled allocations
+	Multiplier         float64 `json:"multiplier"`          // Applied to every allocation
+}
+
+// CalculateVolatilityTarget forecasts the annualized volatility of a portfolio holding the given
+// allocations (fractions of capital) and returns the multiplier that scales it to the configured
+// target, clamped to the configured range. Without a target or a forecast the multiplier is 1.
+func (rm *RiskManager) CalculateVolatilityTarget(allocations map[string]float64) VolatilityTarget {
+	result := VolatilityTarget{
+		TargetVolatility: rm.Config.VolTarget,
+		Multiplier:       1,
+	}
+	if rm.Config.VolTarget <= 0 || rm.MarketAnalyzer == nil {
+		rm.VolatilityTarget = result
+		return result
+	}
+
+	// Forecast volatility from return history and correlations: sqrt(w' * Cov * w)
+	symbols := make([]string, 0, len(allocations))
+	stdDevs := make(map[string]float64)
+	for symbol, weight := range allocations {
+		returns := rm.MarketAnalyzer.GetReturns(symbol)
+		if weight == 0 || len(returns) < 2 {
+			continue
+		}
+		symbols = append(symbols, symbol)
+		stdDevs[symbol] = standardDeviation(returns)
+	}
+	sort.Strings(symbols)
+
+	sigma := portfolioSigma(symbols, allocations, stdDevs, rm.correlation)
+	result.ForecastVolatility = sigma * math.Sqrt(klinePeriodsPerYear)
+	if result.ForecastVolatility <= 0 {
+		rm.VolatilityTarget = result
+		return result
+	}
+
+	multiplier := rm.Config.VolTarget / result.ForecastVolatility
+	multiplier = math.Max(multiplier, rm.Config.VolTargetMinScale)
+	multiplier = math.Min(multiplier, rm.Config.VolTargetMaxScale)
+	result.Multiplier = multiplier
+
+	rm.VolatilityTarget = result
+	return result
+}
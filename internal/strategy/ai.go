@@ -1,6 +1,9 @@
 package strategy
 
 import (
+	"math"
+	"sort"
+
 	"github.com/forbest/bybitgo/internal/market"
 )
 
@@ -12,12 +15,34 @@ const (
 	Momentum           StrategyType = "momentum"
 	MeanReversion      StrategyType = "mean_reversion"
 	VolatilityBreakout StrategyType = "volatility_breakout"
+	TrendFollowing     StrategyType = "trend_following"
+	BreakoutRetest     StrategyType = "breakout_retest"
+	// DCA runs on its own schedule alongside the selected strategy and is never selected by the AI
+	DCA StrategyType = "dca"
+	// PairsTrading trades the spread of configured symbol pairs and is never selected by the AI
+	PairsTrading StrategyType = "pairs_trading"
+	// FundingArbitrage holds delta-neutral spot/perpetual positions and is never selected by the AI
+	FundingArbitrage StrategyType = "funding_arbitrage"
+	// OrderBookScalping quotes liquid symbols on its own fast schedule and is never selected by the AI
+	OrderBookScalping StrategyType = "orderbook_scalping"
+	// TriangularArbitrage trades spot market cycles on its own fast schedule and is never selected by the AI
+	TriangularArbitrage StrategyType = "triangular_arbitrage"
+	// Ensemble votes with several strategies and replaces the AI's selection when configured
+	Ensemble StrategyType = "ensemble"
+	// Selector trades the AI's selection itself, to backtest it, and is never selected by the AI
+	Selector StrategyType = "selector"
 )
 
 // StrategyAI selects the best strategy for each symbol based on market conditions
 type StrategyAI struct {
 	MarketAnalyzer  *market.MarketAnalyzer
 	StrategyWeights map[string]map[string]float64 // symbol -> strategy -> weight
+	// Registered strategies competing with the built-in ones
+	Plugins map[StrategyType]Strategy
+	// Learns from realized PnL which strategies work in which regime, nil keeps the regime heuristics
+	Bandit *StrategyBandit
+	// Strategies running in shadow mode, which are never selected for trading
+	Shadow map[StrategyType]bool
 }
 
 // NewStrategyAI creates a new StrategyAI
@@ -25,7 +50,25 @@ func NewStrategyAI(analyzer *market.MarketAnalyzer) *StrategyAI {
 	return &StrategyAI{
 		MarketAnalyzer:  analyzer,
 		StrategyWeights: make(map[string]map[string]float64),
+		Plugins:         make(map[StrategyType]Strategy),
+	}
+}
+
+// AddStrategy lets a registered strategy compete in the selection. Its weight comes from its
+// RegimeWeight method, or is the built-in strategies' base weight if it has none.
+func (ai *StrategyAI) AddStrategy(strategyType StrategyType, strategy Strategy) {
+	ai.Plugins[strategyType] = strategy
+}
+
+// IsSelectable reports whether a strategy is selected per symbol: a built-in strategy weighted
+// by market regime or a registered strategy added to the selection
+func (ai *StrategyAI) IsSelectable(strategyType StrategyType) bool {
+	switch strategyType {
+	case MarketMaking, Momentum, MeanReversion, VolatilityBreakout, TrendFollowing, BreakoutRetest:
+		return true
 	}
+	_, exists := ai.Plugins[strategyType]
+	return exists
 }
 
 // SelectStrategy selects the best strategy for a symbol based on market conditions
@@ -35,6 +78,12 @@ func (ai *StrategyAI) SelectStrategy(symbol string) StrategyType {
 
 	// Calculate strategy weights based on market conditions
 	weights := ai.calculateStrategyWeights(regime)
+	if ai.Bandit != nil {
+		weights = ai.applyBandit(regime, weights)
+	}
+	for strategyType := range ai.Shadow {
+		delete(weights, string(strategyType))
+	}
 
 	// Store weights for reference
 	if _, exists := ai.StrategyWeights[symbol]; !exists {
@@ -45,12 +94,12 @@ func (ai *StrategyAI) SelectStrategy(symbol string) StrategyType {
 		ai.StrategyWeights[symbol][strategy] = weight
 	}
 
-	// Select strategy with highest weight
+	// Select strategy with highest weight, ties going to the first name so selection is repeatable
 	bestStrategy := MarketMaking
 	highestWeight := 0.0
 
-	for strategy, weight := range weights {
-		if weight > highestWeight {
+	for _, strategy := range sortedNames(weights) {
+		if weight := weights[strategy]; weight > highestWeight {
 			highestWeight = weight
 			bestStrategy = StrategyType(strategy)
 		}
@@ -59,15 +108,27 @@ func (ai *StrategyAI) SelectStrategy(symbol string) StrategyType {
 	return bestStrategy
 }
 
+// sortedNames returns the strategy names of a weight map in order
+func sortedNames(weights map[string]float64) []string {
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // calculateStrategyWeights calculates weights for each strategy based on market regime
 func (ai *StrategyAI) calculateStrategyWeights(regime *market.MarketRegime) map[string]float64 {
 	weights := make(map[string]float64)
 
 	// Base weights
-	weights[string(MarketMaking)] = 0.25
-	weights[string(Momentum)] = 0.25
-	weights[string(MeanReversion)] = 0.25
-	weights[string(VolatilityBreakout)] = 0.25
+	weights[string(MarketMaking)] = 0.2
+	weights[string(Momentum)] = 0.2
+	weights[string(MeanReversion)] = 0.2
+	weights[string(VolatilityBreakout)] = 0.2
+	weights[string(TrendFollowing)] = 0.2
+	weights[string(BreakoutRetest)] = 0.2
 
 	// Adjust weights based on market regime
 	switch regime.Volatility {
@@ -76,11 +137,15 @@ func (ai *StrategyAI) calculateStrategyWeights(regime *market.MarketRegime) map[
 		weights[string(MarketMaking)] -= 0.1
 		weights[string(Momentum)] += 0.1
 		weights[string(MeanReversion)] -= 0.3
+		weights[string(TrendFollowing)] += 0.1
+		weights[string(BreakoutRetest)] += 0.2
 	case "low_volatility":
 		weights[string(MeanReversion)] += 0.3
 		weights[string(MarketMaking)] += 0.1
 		weights[string(Momentum)] -= 0.1
 		weights[string(VolatilityBreakout)] -= 0.3
+		weights[string(TrendFollowing)] -= 0.1
+		weights[string(BreakoutRetest)] -= 0.1
 	}
 
 	switch regime.Trend {
@@ -88,17 +153,21 @@ func (ai *StrategyAI) calculateStrategyWeights(regime *market.MarketRegime) map[
 		weights[string(Momentum)] += 0.4
 		weights[string(MarketMaking)] -= 0.2
 		weights[string(MeanReversion)] -= 0.2
+		weights[string(TrendFollowing)] += 0.5
 	case "ranging":
 		weights[string(MeanReversion)] += 0.4
 		weights[string(MarketMaking)] += 0.1
 		weights[string(Momentum)] -= 0.3
 		weights[string(VolatilityBreakout)] -= 0.2
+		weights[string(TrendFollowing)] -= 0.4
+		weights[string(BreakoutRetest)] += 0.1
 	}
 
 	switch regime.Volume {
 	case "high_volume":
 		weights[string(Momentum)] += 0.2
 		weights[string(VolatilityBreakout)] += 0.2
+		weights[string(BreakoutRetest)] += 0.1
 		weights[string(MarketMaking)] -= 0.2
 		weights[string(MeanReversion)] -= 0.2
 	case "low_volume":
@@ -106,6 +175,16 @@ func (ai *StrategyAI) calculateStrategyWeights(regime *market.MarketRegime) map[
 		weights[string(MeanReversion)] += 0.1
 		weights[string(Momentum)] -= 0.2
 		weights[string(VolatilityBreakout)] -= 0.2
+		weights[string(BreakoutRetest)] -= 0.2
+	}
+
+	// Registered strategies weigh themselves
+	for strategyType, strategy := range ai.Plugins {
+		weight := 0.2
+		if weighter, ok := strategy.(RegimeWeighter); ok {
+			weight = weighter.RegimeWeight(regime)
+		}
+		weights[string(strategyType)] = weight
 	}
 
 	// Normalize weights to sum to 1.0
@@ -123,6 +202,40 @@ func (ai *StrategyAI) calculateStrategyWeights(regime *market.MarketRegime) map[
 	return weights
 }
 
+// applyBandit scales the regime weights by win rates sampled from each strategy's experience in
+// the regime, so selection shifts towards the strategies that made money there
+func (ai *StrategyAI) applyBandit(regime *market.MarketRegime, weights map[string]float64) map[string]float64 {
+	regimeKey := RegimeKey(regime)
+	total := 0.0
+	// Sampled in name order, so a seeded bandit repeats its draws
+	for _, strategy := range sortedNames(weights) {
+		weights[strategy] = math.Max(weights[strategy], 0) * ai.Bandit.Sample(regimeKey, StrategyType(strategy))
+		total += weights[strategy]
+	}
+
+	if total > 0 {
+		for strategy := range weights {
+			weights[strategy] = weights[strategy] / total
+		}
+	}
+
+	return weights
+}
+
+// RecordEntry remembers the market regime a position of the symbol was opened in
+func (ai *StrategyAI) RecordEntry(symbol string) {
+	if ai.Bandit != nil {
+		ai.Bandit.RecordEntry(symbol, RegimeKey(ai.MarketAnalyzer.GetMarketRegime(symbol)))
+	}
+}
+
+// RecordOutcome learns from the realized PnL of a position closed by a strategy
+func (ai *StrategyAI) RecordOutcome(symbol string, strategyType StrategyType, pnl float64) {
+	if ai.Bandit != nil {
+		ai.Bandit.RecordOutcome(symbol, strategyType, RegimeKey(ai.MarketAnalyzer.GetMarketRegime(symbol)), pnl)
+	}
+}
+
 // GetStrategyWeights returns the current strategy weights for a symbol
 func (ai *StrategyAI) GetStrategyWeights(symbol string) map[string]float64 {
 	if weights, exists := ai.StrategyWeights[symbol]; exists {
@@ -131,6 +244,24 @@ func (ai *StrategyAI) GetStrategyWeights(symbol string) map[string]float64 {
 	return make(map[string]float64)
 }
 
+// LastSelection returns the strategy the last selection for a symbol picked, the highest of its
+// stored weights, or false if none was made yet
+func (ai *StrategyAI) LastSelection(symbol string) (StrategyType, bool) {
+	weights := ai.StrategyWeights[symbol]
+	if len(weights) == 0 {
+		return "", false
+	}
+	bestStrategy := MarketMaking
+	highestWeight := 0.0
+	for _, strategy := range sortedNames(weights) {
+		if weight := weights[strategy]; weight > highestWeight {
+			highestWeight = weight
+			bestStrategy = StrategyType(strategy)
+		}
+	}
+	return bestStrategy, true
+}
+
 // CalculateVolatilityScore calculates a volatility score for strategy selection
 func (ai *StrategyAI) CalculateVolatilityScore(regime *market.MarketRegime) float64 {
 	switch regime.Volatility {
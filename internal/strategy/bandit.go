@@ -0,0 +1,188 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/market"
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// banditStateFile is the file name of the bandit snapshot inside the data directory
+const banditStateFile = "bandit_state.json"
+
+// BanditArm is the win/loss record of a strategy in one market regime
+type BanditArm struct {
+	Wins   float64 `json:"wins"`   // Decayed count of profitable closed positions
+	Losses float64 `json:"losses"` // Decayed count of losing closed positions
+	Trades int     `json:"trades"`
+	PnL    float64 `json:"pnl"` // Total realized PnL
+}
+
+// StrategyBandit learns which strategy works in which market regime by Thompson sampling.
+// Each (regime, strategy) arm keeps a Beta posterior of the strategy's win rate that is updated
+// from the realized PnL of the strategy's closed positions. Selection draws a win rate from
+// each posterior, so strategies with little experience are still explored.
+type StrategyBandit struct {
+	Prior float64                                // Pseudo-wins and pseudo-losses of every arm
+	Decay float64                                // Applied to an arm's record before each update, 1 keeps all history
+	Arms  map[string]map[StrategyType]*BanditArm // regime key -> strategy -> arm
+	// Regime of each open position when it was opened
+	Entries map[string]string
+	rng     *rand.Rand
+}
+
+// banditSnapshot is the persisted state of the bandit
+type banditSnapshot struct {
+	SavedAt time.Time                              `json:"saved_at"`
+	Arms    map[string]map[StrategyType]*BanditArm `json:"arms"`
+	Entries map[string]string                      `json:"entries"`
+}
+
+// NewStrategyBandit creates a new StrategyBandit
+func NewStrategyBandit(prior, decay float64) *StrategyBandit {
+	return &StrategyBandit{
+		Prior:   prior,
+		Decay:   decay,
+		Arms:    make(map[string]map[StrategyType]*BanditArm),
+		Entries: make(map[string]string),
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RegimeKey returns the regime an arm belongs to, e.g. trending_up/high_volatility
+func RegimeKey(regime *market.MarketRegime) string {
+	if regime == nil {
+		return "unknown/unknown"
+	}
+	return regime.Trend + "/" + regime.Volatility
+}
+
+// Arm returns the record of a strategy in a regime, creating it on first use
+func (b *StrategyBandit) Arm(regimeKey string, strategyType StrategyType) *BanditArm {
+	arms, exists := b.Arms[regimeKey]
+	if !exists {
+		arms = make(map[StrategyType]*BanditArm)
+		b.Arms[regimeKey] = arms
+	}
+	arm, exists := arms[strategyType]
+	if !exists {
+		arm = &BanditArm{}
+		arms[strategyType] = arm
+	}
+	return arm
+}
+
+// Seed makes the bandit's draws repeatable
+func (b *StrategyBandit) Seed(seed int64) {
+	b.rng = rand.New(rand.NewSource(seed))
+}
+
+// Sample draws a win rate of a strategy in a regime from its Beta posterior
+func (b *StrategyBandit) Sample(regimeKey string, strategyType StrategyType) float64 {
+	arm := b.Arm(regimeKey, strategyType)
+	return sampleBeta(b.rng, b.Prior+arm.Wins, b.Prior+arm.Losses)
+}
+
+// WinRate returns the posterior mean win rate of a strategy in a regime
+func (b *StrategyBandit) WinRate(regimeKey string, strategyType StrategyType) float64 {
+	arm := b.Arm(regimeKey, strategyType)
+	return (b.Prior + arm.Wins) / (2*b.Prior + arm.Wins + arm.Losses)
+}
+
+// RecordEntry remembers the regime a position was opened in, so its outcome is credited to it
+func (b *StrategyBandit) RecordEntry(symbol, regimeKey string) {
+	b.Entries[symbol] = regimeKey
+}
+
+// RecordOutcome updates a strategy's arm with the realized PnL of a closed position. The arm of
+// the regime the position was opened in is updated, or of the current regime if it is unknown.
+func (b *StrategyBandit) RecordOutcome(symbol string, strategyType StrategyType, currentRegimeKey string, pnl float64) {
+	regimeKey, exists := b.Entries[symbol]
+	if !exists {
+		regimeKey = currentRegimeKey
+	}
+	delete(b.Entries, symbol)
+
+	arm := b.Arm(regimeKey, strategyType)
+	if b.Decay > 0 && b.Decay < 1 {
+		arm.Wins *= b.Decay
+		arm.Losses *= b.Decay
+	}
+	if pnl > 0 {
+		arm.Wins++
+	} else {
+		arm.Losses++
+	}
+	arm.Trades++
+	arm.PnL += pnl
+}
+
+// BanditStatePath returns the path of the bandit snapshot inside a data directory
+func BanditStatePath(dataDir string) string {
+	return filepath.Join(dataDir, banditStateFile)
+}
+
+// SaveState writes the arms and open entries to disk
+func (b *StrategyBandit) SaveState(path string) error {
+	snapshot := banditSnapshot{SavedAt: time.Now(), Arms: b.Arms, Entries: b.Entries}
+	if err := persistence.SaveJSON(path, snapshot); err != nil {
+		return fmt.Errorf("failed to save bandit state: %w", err)
+	}
+	return nil
+}
+
+// LoadState restores the arms and open entries from the last snapshot, if one exists
+func (b *StrategyBandit) LoadState(path string) error {
+	var snapshot banditSnapshot
+	found, err := persistence.LoadJSON(path, &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to load bandit state: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	if snapshot.Arms != nil {
+		b.Arms = snapshot.Arms
+	}
+	if snapshot.Entries != nil {
+		b.Entries = snapshot.Entries
+	}
+	return nil
+}
+
+// sampleBeta draws from a Beta(alpha, beta) distribution
+func sampleBeta(rng *rand.Rand, alpha, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// sampleGamma draws from a Gamma(shape, 1) distribution with the Marsaglia-Tsang method
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Sample with the shape boosted above 1 and scale back down
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		if math.Log(rng.Float64()) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// PriceLevel is a support or resistance level formed by clustered swing points
+type PriceLevel struct {
+	Price   float64
+	Touches int
+	Type    string // support, resistance
+}
+
+// BreakoutRetestStrategy trades breakouts of support and resistance levels only after the
+// price comes back to test the broken level and holds it with above-average volume. Breakouts
+// that close back through the level are discarded as false breakouts.
+type BreakoutRetestStrategy struct {
+	Parameters map[string]float64
+}
+
+// NewBreakoutRetestStrategy creates a new BreakoutRetestStrategy
+func NewBreakoutRetestStrategy() *BreakoutRetestStrategy {
+	return &BreakoutRetestStrategy{
+		Parameters: map[string]float64{
+			"level_lookback":   60,  // Bars searched for swing points before the breakout window
+			"pivot_strength":   3,   // Bars on each side a swing point must exceed
+			"min_touches":      2,   // Swing points needed to form a level
+			"level_tolerance":  0.3, // Percent distance at which swing points and retests touch a level
+			"breakout_percent": 0.5, // Close beyond the level by this percent counts as a breakout
+			"max_retest_bars":  12,  // Bars after the breakout in which the retest must happen
+			"volume_period":    20,
+			"min_volume_ratio": 1.2, // Retest bar volume over the average
+			"reward_risk":      2,   // Target distance as a multiple of the stop distance
+		},
+	}
+}
+
+// GetName returns the strategy name
+func (brs *BreakoutRetestStrategy) GetName() string {
+	return string(BreakoutRetest)
+}
+
+// RequiredBars returns the bars needed before levels and retests can be detected
+func (brs *BreakoutRetestStrategy) RequiredBars() int {
+	// Levels are detected on the bars before the breakout window
+	return int(brs.Parameters["level_lookback"]) + int(brs.Parameters["max_retest_bars"])
+}
+
+// Analyze implements the breakout-retest analysis logic
+func (brs *BreakoutRetestStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	lookback := int(brs.Parameters["level_lookback"])
+	window := int(brs.Parameters["max_retest_bars"])
+	if marketData == nil || len(marketData.Kline) < brs.RequiredBars() {
+		return notReadySignal(marketData, brs.RequiredBars())
+	}
+
+	klines := marketData.Kline
+	n := len(klines)
+
+	// Levels come from the bars before the breakout window
+	levels := brs.DetectLevels(klines[n-window-lookback : n-window])
+
+	current := klines[n-1]
+	close, _ := current.Close.Float64()
+	volume, _ := current.Volume.Float64()
+	averageVolume := brs.averageVolume(klines[:n-1])
+	volumeOK := averageVolume > 0 && volume >= averageVolume*brs.Parameters["min_volume_ratio"]
+
+	for _, level := range levels {
+		breakout, retested := brs.findRetest(klines[n-window-1:], level)
+		if breakout < 0 {
+			continue
+		}
+		if !retested {
+			return bybit.TradeSignal{
+				Symbol: marketData.Symbol,
+				Action: "HOLD",
+				Reason: fmt.Sprintf("Waiting for retest of %s %.4f broken %d bars ago", level.Type, level.Price, window-breakout),
+			}
+		}
+		if !volumeOK {
+			return bybit.TradeSignal{
+				Symbol: marketData.Symbol,
+				Action: "HOLD",
+				Reason: fmt.Sprintf("Retest of %s %.4f without volume: %.2f vs avg %.2f", level.Type, level.Price, volume, averageVolume),
+			}
+		}
+
+		// The setup fails once price moves back through the level by the breakout distance
+		strength := math.Min(volume/averageVolume/(2*brs.Parameters["min_volume_ratio"]), 1)
+		breakDistance := level.Price * brs.Parameters["breakout_percent"] / 100
+		if level.Type == "resistance" {
+			stopLoss := level.Price - breakDistance
+			return bybit.TradeSignal{
+				Symbol:     marketData.Symbol,
+				Action:     "BUY",
+				Strength:   strength,
+				EntryPrice: close,
+				StopLoss:   stopLoss,
+				TakeProfit: close + (close-stopLoss)*brs.Parameters["reward_risk"],
+				Reason: fmt.Sprintf("Retest of broken resistance %.4f (%d touches) held at %.4f with volume %.2f > avg %.2f",
+					level.Price, level.Touches, close, volume, averageVolume),
+			}
+		}
+		stopLoss := level.Price + breakDistance
+		return bybit.TradeSignal{
+			Symbol:     marketData.Symbol,
+			Action:     "SELL",
+			Strength:   strength,
+			EntryPrice: close,
+			StopLoss:   stopLoss,
+			TakeProfit: close - (stopLoss-close)*brs.Parameters["reward_risk"],
+			Reason: fmt.Sprintf("Retest of broken support %.4f (%d touches) rejected at %.4f with volume %.2f > avg %.2f",
+				level.Price, level.Touches, close, volume, averageVolume),
+		}
+	}
+
+	return bybit.TradeSignal{
+		Symbol:   marketData.Symbol,
+		Action:   "HOLD",
+		Strength: 0.5,
+		Reason:   fmt.Sprintf("No breakout of %d detected levels at %.4f", len(levels), close),
+	}
+}
+
+// DetectLevels clusters swing highs into resistance and swing lows into support levels,
+// keeping levels touched at least min_touches times, most touched first
+func (brs *BreakoutRetestStrategy) DetectLevels(klines []bybit.KlineData) []PriceLevel {
+	strength := int(brs.Parameters["pivot_strength"])
+	tolerance := brs.Parameters["level_tolerance"] / 100
+
+	var highs, lows []float64
+	for i := strength; i < len(klines)-strength; i++ {
+		high, _ := klines[i].High.Float64()
+		low, _ := klines[i].Low.Float64()
+		isHigh, isLow := true, true
+		for j := i - strength; j <= i+strength; j++ {
+			if j == i {
+				continue
+			}
+			otherHigh, _ := klines[j].High.Float64()
+			otherLow, _ := klines[j].Low.Float64()
+			if otherHigh >= high {
+				isHigh = false
+			}
+			if otherLow <= low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			highs = append(highs, high)
+		}
+		if isLow {
+			lows = append(lows, low)
+		}
+	}
+
+	levels := append(clusterLevels(highs, tolerance, "resistance"), clusterLevels(lows, tolerance, "support")...)
+
+	var result []PriceLevel
+	for _, level := range levels {
+		if level.Touches >= int(brs.Parameters["min_touches"]) {
+			result = append(result, level)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Touches > result[j].Touches })
+
+	return result
+}
+
+// findRetest looks for a breakout of the level in the klines (the bar before the breakout
+// window followed by the window) and reports the breakout's index and whether the last bar is
+// the first successful retest. A close back through the level invalidates the breakout.
+func (brs *BreakoutRetestStrategy) findRetest(klines []bybit.KlineData, level PriceLevel) (int, bool) {
+	tolerance := brs.Parameters["level_tolerance"] / 100
+	breakPercent := brs.Parameters["breakout_percent"] / 100
+	last := len(klines) - 1
+
+	// Direction of the breakout: up through resistance or down through support
+	sign := 1.0
+	if level.Type == "support" {
+		sign = -1.0
+	}
+	beyond := func(price, distance float64) bool {
+		return sign*(price-level.Price) > level.Price*distance
+	}
+
+	breakout := -1
+	for i := 1; i < last; i++ {
+		close, _ := klines[i].Close.Float64()
+		previousClose, _ := klines[i-1].Close.Float64()
+		if breakout < 0 {
+			if beyond(close, breakPercent) && !beyond(previousClose, 0) {
+				breakout = i
+			}
+			continue
+		}
+
+		// A close back through the level is a false breakout, an earlier retest was already traded
+		if !beyond(close, -tolerance) || brs.isRetest(klines[i], level, tolerance) {
+			return -1, false
+		}
+	}
+	if breakout < 0 {
+		return -1, false
+	}
+
+	return breakout, brs.isRetest(klines[last], level, tolerance)
+}
+
+// isRetest reports whether a bar touched the level from the breakout side and closed beyond it
+func (brs *BreakoutRetestStrategy) isRetest(kline bybit.KlineData, level PriceLevel, tolerance float64) bool {
+	close, _ := kline.Close.Float64()
+	if level.Type == "resistance" {
+		low, _ := kline.Low.Float64()
+		return low <= level.Price*(1+tolerance) && close > level.Price
+	}
+	high, _ := kline.High.Float64()
+	return high >= level.Price*(1-tolerance) && close < level.Price
+}
+
+// averageVolume returns the average volume of the last volume_period klines
+func (brs *BreakoutRetestStrategy) averageVolume(klines []bybit.KlineData) float64 {
+	period := int(brs.Parameters["volume_period"])
+	if period <= 0 || len(klines) < period {
+		return 0
+	}
+
+	sum := 0.0
+	for _, kline := range klines[len(klines)-period:] {
+		volume, _ := kline.Volume.Float64()
+		sum += volume
+	}
+	return sum / float64(period)
+}
+
+// Execute reports a signal whose order the bot has placed
+func (brs *BreakoutRetestStrategy) Execute(signal bybit.TradeSignal) error {
+	if signal.Action == "HOLD" {
+		return nil // Nothing to execute
+	}
+
+	fmt.Printf("Executing breakout-retest strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
+
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (brs *BreakoutRetestStrategy) GetParameters() map[string]float64 {
+	return brs.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (brs *BreakoutRetestStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(BreakoutRetest, brs.Parameters, params)
+}
+
+// clusterLevels groups sorted prices that lie within the tolerance of the cluster's first
+// price into levels at the cluster's average price
+func clusterLevels(prices []float64, tolerance float64, levelType string) []PriceLevel {
+	sorted := append([]float64(nil), prices...)
+	sort.Float64s(sorted)
+
+	var levels []PriceLevel
+	for i := 0; i < len(sorted); {
+		j, sum := i, 0.0
+		for j < len(sorted) && sorted[j] <= sorted[i]*(1+tolerance) {
+			sum += sorted[j]
+			j++
+		}
+		levels = append(levels, PriceLevel{Price: sum / float64(j-i), Touches: j - i, Type: levelType})
+		i = j
+	}
+
+	return levels
+}
@@ -0,0 +1,215 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// calibrationStateFile is the file name of the calibration snapshot inside the data directory
+const calibrationStateFile = "calibration_state.json"
+
+// CalibrationBucket counts the signals of a strategy within a strength range and how many of
+// them were followed by a profitable position
+type CalibrationBucket struct {
+	Signals int `json:"signals"`
+	Hits    int `json:"hits"`
+}
+
+// CalibrationPoint is one strength range of a strategy's calibration mapping
+type CalibrationPoint struct {
+	MinStrength float64 `json:"min_strength"`
+	MaxStrength float64 `json:"max_strength"`
+	Signals     int     `json:"signals"`
+	Hits        int     `json:"hits"`
+	HitRate     float64 `json:"hit_rate"`   // Observed hit rate, 0 without signals
+	Calibrated  float64 `json:"calibrated"` // Confidence used for a signal in the middle of the range
+}
+
+// calibrationEntry is the strategy and reported strength of the signal that opened a position
+type calibrationEntry struct {
+	Strategy StrategyType `json:"strategy"`
+	Strength float64      `json:"strength"`
+}
+
+// SignalCalibrator tracks the empirical hit rate of each strategy's signals bucketed by their
+// reported strength, so a reported confidence can be mapped to the observed success rate. The
+// observed rate is shrunk towards the reported strength until a bucket has enough signals.
+type SignalCalibrator struct {
+	mutex   sync.Mutex
+	Buckets int     // Number of equal-width strength buckets between 0 and 1
+	Prior   float64 // Pseudo-signals at the reported strength in every bucket
+	Stats   map[StrategyType][]CalibrationBucket
+	// Signal behind each open position, scored once the position is closed
+	Entries map[string]calibrationEntry
+}
+
+// calibrationSnapshot is the persisted state of the calibrator
+type calibrationSnapshot struct {
+	SavedAt time.Time                            `json:"saved_at"`
+	Stats   map[StrategyType][]CalibrationBucket `json:"stats"`
+	Entries map[string]calibrationEntry          `json:"entries"`
+}
+
+// NewSignalCalibrator creates a new SignalCalibrator
+func NewSignalCalibrator(buckets int, prior float64) *SignalCalibrator {
+	return &SignalCalibrator{
+		Buckets: buckets,
+		Prior:   prior,
+		Stats:   make(map[StrategyType][]CalibrationBucket),
+		Entries: make(map[string]calibrationEntry),
+	}
+}
+
+// bucket returns the bucket index of a strength, clamped to [0, 1]
+func (sc *SignalCalibrator) bucket(strength float64) int {
+	strength = math.Min(math.Max(strength, 0), 1)
+	index := int(strength * float64(sc.Buckets))
+	if index >= sc.Buckets {
+		index = sc.Buckets - 1
+	}
+	return index
+}
+
+// buckets returns the buckets of a strategy, creating them on first use. The caller must hold the lock.
+func (sc *SignalCalibrator) buckets(strategyType StrategyType) []CalibrationBucket {
+	stats, exists := sc.Stats[strategyType]
+	if !exists || len(stats) != sc.Buckets {
+		stats = make([]CalibrationBucket, sc.Buckets)
+		sc.Stats[strategyType] = stats
+	}
+	return stats
+}
+
+// RecordEntry remembers the signal that opened a position of the symbol
+func (sc *SignalCalibrator) RecordEntry(symbol string, strategyType StrategyType, strength float64) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	sc.Entries[symbol] = calibrationEntry{Strategy: strategyType, Strength: strength}
+}
+
+// RecordOutcome scores the signal that opened a closed position, a hit being a profitable close
+func (sc *SignalCalibrator) RecordOutcome(symbol string, pnl float64) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	entry, exists := sc.Entries[symbol]
+	if !exists {
+		return
+	}
+	delete(sc.Entries, symbol)
+
+	stats := sc.buckets(entry.Strategy)
+	index := sc.bucket(entry.Strength)
+	stats[index].Signals++
+	if pnl > 0 {
+		stats[index].Hits++
+	}
+}
+
+// Calibrate maps a strategy's reported strength to its calibrated confidence
+func (sc *SignalCalibrator) Calibrate(strategyType StrategyType, strength float64) float64 {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	strength = math.Min(math.Max(strength, 0), 1)
+	return sc.calibrate(sc.buckets(strategyType)[sc.bucket(strength)], strength)
+}
+
+// calibrate blends a bucket's observed hit rate with the reported strength
+func (sc *SignalCalibrator) calibrate(bucket CalibrationBucket, strength float64) float64 {
+	if float64(bucket.Signals)+sc.Prior <= 0 {
+		return strength
+	}
+	return (float64(bucket.Hits) + sc.Prior*strength) / (float64(bucket.Signals) + sc.Prior)
+}
+
+// SizeMultiplier returns the factor a signal's order size is scaled by: the calibrated
+// confidence relative to the reported strength, so strategies that overstate their confidence
+// trade smaller. Sizes are never scaled up.
+func (sc *SignalCalibrator) SizeMultiplier(strategyType StrategyType, strength float64) float64 {
+	if strength <= 0 {
+		return 1
+	}
+	calibrated := sc.Calibrate(strategyType, strength)
+	return math.Min(calibrated/math.Min(strength, 1), 1)
+}
+
+// Mapping returns the calibration mapping of every strategy with scored signals
+func (sc *SignalCalibrator) Mapping() map[StrategyType][]CalibrationPoint {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	strategies := make([]StrategyType, 0, len(sc.Stats))
+	for strategyType := range sc.Stats {
+		strategies = append(strategies, strategyType)
+	}
+	sort.Slice(strategies, func(i, j int) bool { return strategies[i] < strategies[j] })
+
+	width := 1 / float64(sc.Buckets)
+	mapping := make(map[StrategyType][]CalibrationPoint)
+	for _, strategyType := range strategies {
+		points := make([]CalibrationPoint, 0, sc.Buckets)
+		for i, bucket := range sc.buckets(strategyType) {
+			point := CalibrationPoint{
+				MinStrength: float64(i) * width,
+				MaxStrength: float64(i+1) * width,
+				Signals:     bucket.Signals,
+				Hits:        bucket.Hits,
+				Calibrated:  sc.calibrate(bucket, (float64(i)+0.5)*width),
+			}
+			if bucket.Signals > 0 {
+				point.HitRate = float64(bucket.Hits) / float64(bucket.Signals)
+			}
+			points = append(points, point)
+		}
+		mapping[strategyType] = points
+	}
+
+	return mapping
+}
+
+// CalibrationStatePath returns the path of the calibration snapshot inside a data directory
+func CalibrationStatePath(dataDir string) string {
+	return filepath.Join(dataDir, calibrationStateFile)
+}
+
+// SaveState writes the bucket counts and open entries to disk
+func (sc *SignalCalibrator) SaveState(path string) error {
+	sc.mutex.Lock()
+	snapshot := calibrationSnapshot{SavedAt: time.Now(), Stats: sc.Stats, Entries: sc.Entries}
+	err := persistence.SaveJSON(path, snapshot)
+	sc.mutex.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to save calibration state: %w", err)
+	}
+	return nil
+}
+
+// LoadState restores the bucket counts and open entries from the last snapshot, if one exists
+func (sc *SignalCalibrator) LoadState(path string) error {
+	var snapshot calibrationSnapshot
+	found, err := persistence.LoadJSON(path, &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to load calibration state: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if snapshot.Stats != nil {
+		sc.Stats = snapshot.Stats
+	}
+	if snapshot.Entries != nil {
+		sc.Entries = snapshot.Entries
+	}
+	return nil
+}
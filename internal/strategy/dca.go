@@ -0,0 +1,134 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// DCAStrategy implements dollar-cost averaging: it buys a fixed notional of each symbol on a
+// schedule, buying more when the price is well below its moving average
+type DCAStrategy struct {
+	Parameters map[string]float64
+	// Time of the last executed buy per symbol
+	LastBuy map[string]time.Time
+}
+
+// NewDCAStrategy creates a new DCAStrategy
+func NewDCAStrategy() *DCAStrategy {
+	return &DCAStrategy{
+		Parameters: map[string]float64{
+			"amount":         50,  // Notional bought per interval, in the symbol's quote currency
+			"interval_hours": 24,  // Time between buys
+			"ma_period":      50,  // Moving average the dip is measured against
+			"dip_percent":    5,   // Price this far below the moving average counts as a dip (0 disables)
+			"dip_multiplier": 2.0, // Amount multiplier on dips
+		},
+		LastBuy: make(map[string]time.Time),
+	}
+}
+
+// GetName returns the strategy name
+func (dca *DCAStrategy) GetName() string {
+	return string(DCA)
+}
+
+// Analyze signals a buy once the interval since the last buy has passed
+func (dca *DCAStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	if marketData == nil || len(marketData.Kline) == 0 {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "Insufficient market data",
+		}
+	}
+
+	// Wait for the next scheduled buy
+	interval := time.Duration(dca.Parameters["interval_hours"] * float64(time.Hour))
+	if last, exists := dca.LastBuy[marketData.Symbol]; exists && time.Since(last) < interval {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: fmt.Sprintf("Next DCA buy at %s", last.Add(interval).Format("2006-01-02 15:04")),
+		}
+	}
+
+	amount, dip := dca.BuyAmount(marketData)
+	reason := fmt.Sprintf("Scheduled DCA buy of %.2f", amount)
+	if dip {
+		reason = fmt.Sprintf("Scheduled DCA buy of %.2f (%.1fx on a dip of more than %.1f%% below the %d-period average)",
+			amount, dca.Parameters["dip_multiplier"], dca.Parameters["dip_percent"], int(dca.Parameters["ma_period"]))
+	}
+
+	return bybit.TradeSignal{
+		Symbol:   marketData.Symbol,
+		Action:   "BUY",
+		Strength: 1.0,
+		Reason:   reason,
+	}
+}
+
+// BuyAmount returns the notional to buy and whether the dip multiplier was applied
+func (dca *DCAStrategy) BuyAmount(marketData *bybit.MarketData) (float64, bool) {
+	amount := dca.Parameters["amount"]
+	if dca.Parameters["dip_percent"] <= 0 || len(marketData.Kline) == 0 {
+		return amount, false
+	}
+
+	// Average close over the moving average period
+	period := int(dca.Parameters["ma_period"])
+	if period <= 0 || len(marketData.Kline) < period {
+		return amount, false
+	}
+	sum := 0.0
+	for _, kline := range marketData.Kline[len(marketData.Kline)-period:] {
+		price, _ := kline.Close.Float64()
+		sum += price
+	}
+	average := sum / float64(period)
+
+	currentPrice, _ := marketData.Kline[len(marketData.Kline)-1].Close.Float64()
+	if average > 0 && currentPrice <= average*(1-dca.Parameters["dip_percent"]/100) {
+		return amount * dca.Parameters["dip_multiplier"], true
+	}
+	return amount, false
+}
+
+// Execute records the buy so the next one waits for the interval
+func (dca *DCAStrategy) Execute(signal bybit.TradeSignal) error {
+	if signal.Action != "BUY" {
+		return nil // Nothing to execute
+	}
+
+	dca.LastBuy[signal.Symbol] = time.Now()
+	fmt.Printf("Executing DCA strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
+
+	return nil
+}
+
+// SaveState returns the time of the last buy per symbol, so a restart does not buy again early
+func (dca *DCAStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(dca.LastBuy)
+}
+
+// LoadState restores the time of the last buy per symbol
+func (dca *DCAStrategy) LoadState(data json.RawMessage) error {
+	lastBuy := make(map[string]time.Time)
+	if err := json.Unmarshal(data, &lastBuy); err != nil {
+		return err
+	}
+	dca.LastBuy = lastBuy
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (dca *DCAStrategy) GetParameters() map[string]float64 {
+	return dca.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (dca *DCAStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(DCA, dca.Parameters, params)
+}
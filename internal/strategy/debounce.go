@@ -0,0 +1,92 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// debounceState is the last signal direction a strategy emitted for a symbol and the opposite
+// direction waiting to persist
+type debounceState struct {
+	Action   string
+	Strength float64
+	Pending  string
+	Count    int
+}
+
+// SignalDebouncer adds hysteresis to strategy signals so noise does not flip a strategy between
+// BUY and SELL on consecutive cycles. A signal opposite to the last emitted direction is held
+// back until it persists for a number of consecutive cycles, or emitted at once if the signed
+// score (strength, negative for SELL) moved by at least the minimum change.
+type SignalDebouncer struct {
+	Persistence int     // Consecutive cycles an opposite signal must persist, 1 or less disables
+	MinChange   float64 // Score change that flips the direction at once, 0 disables
+	states      map[string]*debounceState
+}
+
+// NewSignalDebouncer creates a new SignalDebouncer
+func NewSignalDebouncer(persistence int, minChange float64) *SignalDebouncer {
+	return &SignalDebouncer{
+		Persistence: persistence,
+		MinChange:   minChange,
+		states:      make(map[string]*debounceState),
+	}
+}
+
+// Filter returns the signal, or a HOLD in its place while an opposite-direction signal has not
+// persisted long enough. HOLD signals break the persistence of a pending flip.
+func (sd *SignalDebouncer) Filter(strategyType StrategyType, signal bybit.TradeSignal) bybit.TradeSignal {
+	if sd.Persistence <= 1 && sd.MinChange <= 0 {
+		return signal
+	}
+
+	key := string(strategyType) + "/" + signal.Symbol
+	state, exists := sd.states[key]
+	if !exists {
+		state = &debounceState{}
+		sd.states[key] = state
+	}
+
+	if signal.Action != "BUY" && signal.Action != "SELL" {
+		state.Pending, state.Count = "", 0
+		return signal
+	}
+
+	// The first signal and signals in the emitted direction pass
+	if state.Action == "" || state.Action == signal.Action {
+		state.Action, state.Strength = signal.Action, signal.Strength
+		state.Pending, state.Count = "", 0
+		return signal
+	}
+
+	if state.Pending == signal.Action {
+		state.Count++
+	} else {
+		state.Pending, state.Count = signal.Action, 1
+	}
+
+	change := math.Abs(signedScore(signal.Action, signal.Strength) - signedScore(state.Action, state.Strength))
+	if state.Count >= sd.Persistence || (sd.MinChange > 0 && change >= sd.MinChange) {
+		state.Action, state.Strength = signal.Action, signal.Strength
+		state.Pending, state.Count = "", 0
+		return signal
+	}
+
+	return bybit.TradeSignal{
+		Symbol:   signal.Symbol,
+		Action:   "HOLD",
+		Strength: signal.Strength,
+		Reason: fmt.Sprintf("Debounced %s after %s: %d of %d cycles, score change %.2f (%s)",
+			signal.Action, state.Action, state.Count, sd.Persistence, change, signal.Reason),
+	}
+}
+
+// signedScore returns a signal's strength, negative for a SELL
+func signedScore(action string, strength float64) float64 {
+	if action == "SELL" {
+		return -strength
+	}
+	return strength
+}
@@ -0,0 +1,290 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// EnsembleMember is a strategy voting in an ensemble
+type EnsembleMember struct {
+	Type     StrategyType
+	Strategy Strategy
+}
+
+// ensembleVote is a member's directional vote waiting to be scored against the next price
+type ensembleVote struct {
+	member    StrategyType
+	direction float64 // 1 for BUY, -1 for SELL
+	price     float64
+}
+
+// EnsembleStrategy combines the signals of several strategies into one consensus signal by
+// weighted voting. Each member votes with its signal direction times its strength, scaled by
+// its weight. With learned weights, a member's weight is also scaled by its recent accuracy:
+// each directional vote is scored against the price at the symbol's next analysis.
+type EnsembleStrategy struct {
+	Parameters map[string]float64 // Member weights are the "weight_<strategy>" parameters
+	Members    []EnsembleMember
+	Outcomes   map[StrategyType][]bool // Recent vote outcomes per member, oldest first
+	pending    map[string][]ensembleVote
+}
+
+// NewEnsembleStrategy creates a new EnsembleStrategy
+func NewEnsembleStrategy() *EnsembleStrategy {
+	return &EnsembleStrategy{
+		Parameters: map[string]float64{
+			"min_consensus":   0.3, // Weighted net vote needed for a BUY or SELL, between 0 and 1
+			"learn_weights":   1,   // Scale weights by recent accuracy (0 disables)
+			"accuracy_window": 30,  // Scored votes kept per member
+		},
+		Outcomes: make(map[StrategyType][]bool),
+		pending:  make(map[string][]ensembleVote),
+	}
+}
+
+// AddMember adds a voting strategy with its configured weight
+func (es *EnsembleStrategy) AddMember(strategyType StrategyType, strat Strategy, weight float64) {
+	es.Members = append(es.Members, EnsembleMember{Type: strategyType, Strategy: strat})
+	es.Parameters[weightParameter(strategyType)] = weight
+}
+
+// GetName returns the strategy name
+func (es *EnsembleStrategy) GetName() string {
+	return string(Ensemble)
+}
+
+// Weight returns a member's effective weight: its configured weight, scaled by its recent
+// accuracy when weights are learned
+func (es *EnsembleStrategy) Weight(strategyType StrategyType) float64 {
+	weight := es.Parameters[weightParameter(strategyType)]
+	if es.Parameters["learn_weights"] > 0 {
+		weight *= 2 * es.Accuracy(strategyType)
+	}
+	return weight
+}
+
+// Accuracy returns the share of a member's recent votes that called the next price move
+// correctly, smoothed towards 50% while there are few outcomes
+func (es *EnsembleStrategy) Accuracy(strategyType StrategyType) float64 {
+	hits := 0
+	outcomes := es.Outcomes[strategyType]
+	for _, hit := range outcomes {
+		if hit {
+			hits++
+		}
+	}
+	return (float64(hits) + 1) / (float64(len(outcomes)) + 2)
+}
+
+// RequiredBars returns the bars needed before the first member can vote
+func (es *EnsembleStrategy) RequiredBars() int {
+	required := 0
+	for i, member := range es.Members {
+		bars := RequiredBars(member.Strategy)
+		if i == 0 || bars < required {
+			required = bars
+		}
+	}
+	return required
+}
+
+// Timeframes returns the additional timeframes analyzed by any member
+func (es *EnsembleStrategy) Timeframes() []string {
+	var intervals []string
+	seen := make(map[string]bool)
+	for _, member := range es.Members {
+		for _, interval := range Timeframes(member.Strategy) {
+			if !seen[interval] {
+				seen[interval] = true
+				intervals = append(intervals, interval)
+			}
+		}
+	}
+	return intervals
+}
+
+// Analyze implements the weighted voting logic
+func (es *EnsembleStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	if marketData == nil || len(marketData.Kline) == 0 || len(es.Members) == 0 {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "Insufficient market data",
+		}
+	}
+
+	price, _ := marketData.Kline[len(marketData.Kline)-1].Close.Float64()
+	es.scoreVotes(marketData.Symbol, price)
+
+	var votes []ensembleVote
+	var parts []string
+	score, totalWeight := 0.0, 0.0
+	agreeing := map[float64]float64{}
+	plans := map[float64]bybit.TradeSignal{} // Trade plan of the heaviest member voting each direction
+	planWeights := map[float64]float64{}
+	warmingUp := 0
+	for _, member := range es.Members {
+		signal := member.Strategy.Analyze(marketData)
+		// Members still warming up do not vote
+		if signal.NotReady {
+			warmingUp++
+			parts = append(parts, fmt.Sprintf("%s warming up (%d bars)", member.Type, signal.RequiredBars))
+			continue
+		}
+		weight := es.Weight(member.Type)
+		totalWeight += weight
+
+		direction := 0.0
+		switch signal.Action {
+		case "BUY":
+			direction = 1
+		case "SELL":
+			direction = -1
+		}
+		if direction != 0 {
+			votes = append(votes, ensembleVote{member: member.Type, direction: direction, price: price})
+			agreeing[direction] += weight
+			if weight > planWeights[direction] {
+				plans[direction], planWeights[direction] = signal, weight
+			}
+		}
+		score += weight * direction * math.Min(math.Max(signal.Strength, 0), 1)
+		parts = append(parts, fmt.Sprintf("%s %s %.2f (w %.2f)", member.Type, signal.Action, signal.Strength, weight))
+	}
+	es.pending[marketData.Symbol] = votes
+
+	if warmingUp == len(es.Members) {
+		return notReadySignal(marketData, es.RequiredBars())
+	}
+	if totalWeight <= 0 {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "All ensemble weights are zero",
+		}
+	}
+	score /= totalWeight
+
+	action, direction := "HOLD", 0.0
+	if score >= es.Parameters["min_consensus"] {
+		action, direction = "BUY", 1
+	} else if score <= -es.Parameters["min_consensus"] {
+		action, direction = "SELL", -1
+	}
+
+	// Confidence combines the size of the net vote with the weight share behind the action
+	confidence := 0.5
+	if direction != 0 {
+		confidence = math.Abs(score) * agreeing[direction] / totalWeight
+	}
+
+	plan := plans[direction]
+	return bybit.TradeSignal{
+		Symbol:            marketData.Symbol,
+		Action:            action,
+		Strength:          confidence,
+		Reason:            fmt.Sprintf("Ensemble vote %.2f: %s", score, strings.Join(parts, ", ")),
+		EntryPrice:        plan.EntryPrice,
+		StopLoss:          plan.StopLoss,
+		TakeProfit:        plan.TakeProfit,
+		SuggestedQuantity: plan.SuggestedQuantity,
+		TimeInForce:       plan.TimeInForce,
+	}
+}
+
+// scoreVotes records whether the previous votes for a symbol called the move to the current price
+func (es *EnsembleStrategy) scoreVotes(symbol string, price float64) {
+	window := int(es.Parameters["accuracy_window"])
+	for _, vote := range es.pending[symbol] {
+		if price == vote.price || vote.price <= 0 {
+			continue
+		}
+		hit := (price-vote.price)*vote.direction > 0
+		outcomes := append(es.Outcomes[vote.member], hit)
+		if window > 0 && len(outcomes) > window {
+			outcomes = outcomes[len(outcomes)-window:]
+		}
+		es.Outcomes[vote.member] = outcomes
+	}
+	delete(es.pending, symbol)
+}
+
+// Weights returns every member's effective weight
+func (es *EnsembleStrategy) Weights() map[string]float64 {
+	weights := make(map[string]float64, len(es.Members))
+	for _, member := range es.Members {
+		weights[string(member.Type)] = es.Weight(member.Type)
+	}
+	return weights
+}
+
+// Execute reports a signal whose order the bot has placed
+func (es *EnsembleStrategy) Execute(signal bybit.TradeSignal) error {
+	if signal.Action == "HOLD" {
+		return nil // Nothing to execute
+	}
+
+	fmt.Printf("Executing ensemble strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
+
+	return nil
+}
+
+// OnOrderUpdate forwards an order update to the members
+func (es *EnsembleStrategy) OnOrderUpdate(update OrderUpdate) {
+	for _, member := range es.Members {
+		NotifyOrderUpdate(member.Strategy, update)
+	}
+}
+
+// OnFill forwards a fill to the members
+func (es *EnsembleStrategy) OnFill(fill Fill) {
+	for _, member := range es.Members {
+		NotifyFill(member.Strategy, fill)
+	}
+}
+
+// OnPositionClosed forwards a closed position to the members
+func (es *EnsembleStrategy) OnPositionClosed(position ClosedPosition) {
+	for _, member := range es.Members {
+		NotifyPositionClosed(member.Strategy, position)
+	}
+}
+
+// SaveState returns the recent vote outcomes of the members, so learned weights survive a restart
+func (es *EnsembleStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(es.Outcomes)
+}
+
+// LoadState restores the recent vote outcomes of the members
+func (es *EnsembleStrategy) LoadState(data json.RawMessage) error {
+	outcomes := make(map[StrategyType][]bool)
+	if err := json.Unmarshal(data, &outcomes); err != nil {
+		return err
+	}
+	es.Outcomes = outcomes
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (es *EnsembleStrategy) GetParameters() map[string]float64 {
+	return es.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (es *EnsembleStrategy) SetParameters(params map[string]float64) error {
+	for name, value := range params {
+		if strings.HasPrefix(name, "weight_") && value < 0 {
+			return fmt.Errorf("parameter %q of strategy %s must not be negative", name, Ensemble)
+		}
+	}
+	return setParameters(Ensemble, es.Parameters, params)
+}
+
+// weightParameter is the name of a member's weight parameter
+func weightParameter(strategyType StrategyType) string {
+	return "weight_" + string(strategyType)
+}
@@ -0,0 +1,215 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Funding arbitrage actions
+const (
+	FundingOpen  = "OPEN"  // Buy spot and short the perpetual
+	FundingClose = "CLOSE" // Sell spot and buy back the perpetual
+	FundingHold  = "HOLD"
+)
+
+// fundingPeriodsPerYear is the number of 8-hour funding intervals in a year
+const fundingPeriodsPerYear = 3 * 365
+
+// FundingPosition is an open delta-neutral spot-long/perp-short position
+type FundingPosition struct {
+	Symbol           string
+	Quantity         float64
+	SpotEntryPrice   float64
+	PerpEntryPrice   float64
+	EntryFundingRate float64
+	OpenedAt         time.Time
+	PerpClosed       bool // The perpetual short was bought back but the spot is still held
+}
+
+// FundingSignal is the outcome of comparing a symbol's perpetual funding rate with spot
+type FundingSignal struct {
+	Symbol         string
+	Action         string // OPEN, CLOSE, HOLD
+	FundingRate    float64
+	AnnualizedRate float64
+	BasisPercent   float64 // Perpetual premium over spot in percent
+	Quantity       float64 // Quantity of each leg
+	SpotPrice      float64
+	PerpPrice      float64
+	Reason         string
+}
+
+// FundingArbitrageStrategy collects perpetual funding with delta-neutral positions: when longs
+// pay shorts a high funding rate it buys spot and shorts the perpetual, and it closes both legs
+// once funding normalizes
+type FundingArbitrageStrategy struct {
+	Parameters map[string]float64
+	Rates      map[string]*bybit.FundingRate // Latest funding rate per symbol
+	Positions  map[string]*FundingPosition
+}
+
+// NewFundingArbitrageStrategy creates a new FundingArbitrageStrategy
+func NewFundingArbitrageStrategy() *FundingArbitrageStrategy {
+	return &FundingArbitrageStrategy{
+		Parameters: map[string]float64{
+			"entry_rate":        0.0003, // Funding rate per 8h interval that opens a position (~33% a year)
+			"exit_rate":         0.0001, // Funding rate at or below which the position is closed
+			"max_basis_percent": 0.5,    // Maximum perpetual premium over spot at entry
+			"notional":          200,    // Notional of each leg in USDT
+		},
+		Rates:     make(map[string]*bybit.FundingRate),
+		Positions: make(map[string]*FundingPosition),
+	}
+}
+
+// GetName returns the strategy name
+func (fas *FundingArbitrageStrategy) GetName() string {
+	return string(FundingArbitrage)
+}
+
+// AnalyzeFunding compares the funding rate with the thresholds and decides whether to open,
+// close or hold the delta-neutral position
+func (fas *FundingArbitrageStrategy) AnalyzeFunding(rate *bybit.FundingRate, spotPrice float64) FundingSignal {
+	fundingRate, _ := rate.Rate.Float64()
+	perpPrice, _ := rate.MarkPrice.Float64()
+
+	signal := FundingSignal{
+		Symbol:         rate.Symbol,
+		Action:         FundingHold,
+		FundingRate:    fundingRate,
+		AnnualizedRate: fundingRate * fundingPeriodsPerYear,
+		SpotPrice:      spotPrice,
+		PerpPrice:      perpPrice,
+	}
+	if spotPrice <= 0 || perpPrice <= 0 {
+		signal.Reason = "Missing spot or perpetual price"
+		return signal
+	}
+	signal.BasisPercent = (perpPrice - spotPrice) / spotPrice * 100
+
+	// Close an open position once funding is no longer worth collecting
+	if pos, exists := fas.Positions[rate.Symbol]; exists {
+		// Finish a close that bought back the perpetual but failed to sell the spot
+		if pos.PerpClosed {
+			signal.Action = FundingClose
+			signal.Quantity = pos.Quantity
+			signal.Reason = "Perpetual leg already closed: sell the remaining spot"
+			return signal
+		}
+		if fundingRate <= fas.Parameters["exit_rate"] {
+			signal.Action = FundingClose
+			signal.Quantity = pos.Quantity
+			signal.Reason = fmt.Sprintf("Funding normalized: %.4f%% per 8h at or below %.4f%%",
+				fundingRate*100, fas.Parameters["exit_rate"]*100)
+			return signal
+		}
+		signal.Reason = fmt.Sprintf("Collecting funding %.4f%% per 8h (%.1f%% a year)", fundingRate*100, signal.AnnualizedRate*100)
+		return signal
+	}
+
+	if fundingRate < fas.Parameters["entry_rate"] {
+		signal.Reason = fmt.Sprintf("Funding %.4f%% per 8h below entry %.4f%%", fundingRate*100, fas.Parameters["entry_rate"]*100)
+		return signal
+	}
+
+	// A large premium would be lost when it converges, eating the funding collected
+	if signal.BasisPercent > fas.Parameters["max_basis_percent"] {
+		signal.Reason = fmt.Sprintf("Basis %.2f%% above %.2f%%", signal.BasisPercent, fas.Parameters["max_basis_percent"])
+		return signal
+	}
+
+	signal.Action = FundingOpen
+	signal.Quantity = fas.Parameters["notional"] / spotPrice
+	signal.Reason = fmt.Sprintf("Funding %.4f%% per 8h (%.1f%% a year), basis %.2f%%: buy spot, short perpetual",
+		fundingRate*100, signal.AnnualizedRate*100, signal.BasisPercent)
+
+	return signal
+}
+
+// ExecuteFunding updates the tracked positions after both legs of a signal were filled
+func (fas *FundingArbitrageStrategy) ExecuteFunding(signal FundingSignal) error {
+	switch signal.Action {
+	case FundingOpen:
+		fas.Positions[signal.Symbol] = &FundingPosition{
+			Symbol:           signal.Symbol,
+			Quantity:         signal.Quantity,
+			SpotEntryPrice:   signal.SpotPrice,
+			PerpEntryPrice:   signal.PerpPrice,
+			EntryFundingRate: signal.FundingRate,
+			OpenedAt:         time.Now(),
+		}
+	case FundingClose:
+		delete(fas.Positions, signal.Symbol)
+	}
+	return nil
+}
+
+// MarkPerpClosed records that the perpetual leg of a symbol's position was closed, so that the
+// next close only sells the spot
+func (fas *FundingArbitrageStrategy) MarkPerpClosed(symbol string) {
+	if pos, exists := fas.Positions[symbol]; exists {
+		pos.PerpClosed = true
+	}
+}
+
+// Analyze returns the spot leg of the funding signal for the symbol's latest funding rate
+func (fas *FundingArbitrageStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	rate, exists := fas.Rates[marketData.Symbol]
+	if !exists || len(marketData.Kline) == 0 {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "No funding rate available",
+		}
+	}
+
+	spotPrice, _ := marketData.Kline[len(marketData.Kline)-1].Close.Float64()
+	signal := fas.AnalyzeFunding(rate, spotPrice)
+
+	action := "HOLD"
+	switch signal.Action {
+	case FundingOpen:
+		action = "BUY"
+	case FundingClose:
+		action = "SELL"
+	}
+	return bybit.TradeSignal{
+		Symbol:   marketData.Symbol,
+		Action:   action,
+		Strength: 1.0,
+		Reason:   signal.Reason,
+	}
+}
+
+// Execute is a no-op, both legs are executed together through ExecuteFunding
+func (fas *FundingArbitrageStrategy) Execute(signal bybit.TradeSignal) error {
+	return nil
+}
+
+// SaveState returns the open funding positions of the strategy
+func (fas *FundingArbitrageStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(fas.Positions)
+}
+
+// LoadState restores the open funding positions of the strategy
+func (fas *FundingArbitrageStrategy) LoadState(data json.RawMessage) error {
+	positions := make(map[string]*FundingPosition)
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+	fas.Positions = positions
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (fas *FundingArbitrageStrategy) GetParameters() map[string]float64 {
+	return fas.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (fas *FundingArbitrageStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(FundingArbitrage, fas.Parameters, params)
+}
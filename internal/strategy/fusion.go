@@ -0,0 +1,97 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// Signal fusion modes
+const (
+	FusionAgree    = "agree"    // The combined indicator signal must agree, the volume signal must not oppose
+	FusionWeighted = "weighted" // Signed scores are blended and the blend must clear the minimum score
+)
+
+// SignalFusion is the decision layer between a strategy's signal and its execution. It checks
+// the signal against the analyzer's combined indicator signal and volume-weighted signal, and
+// turns it into a HOLD if they do not back it.
+type SignalFusion struct {
+	Mode           string  // FusionAgree or FusionWeighted, anything else passes signals through
+	CombinedWeight float64 // Weight of the combined indicator score in the weighted blend
+	VolumeWeight   float64 // Weight of the volume-weighted signal, the strategy gets the rest
+	MinScore       float64 // Blended score a weighted signal needs to be executed
+}
+
+// NewSignalFusion creates a new SignalFusion
+func NewSignalFusion(mode string, combinedWeight, volumeWeight, minScore float64) *SignalFusion {
+	return &SignalFusion{
+		Mode:           mode,
+		CombinedWeight: combinedWeight,
+		VolumeWeight:   volumeWeight,
+		MinScore:       minScore,
+	}
+}
+
+// Fuse returns the signal to execute: the strategy's signal if the indicator signals back it,
+// with the blended score as strength in weighted mode, or a HOLD otherwise. HOLD signals pass.
+func (sf *SignalFusion) Fuse(signal bybit.TradeSignal, combined *market.CombinedSignal, volume *market.VolumeWeightedSignal) bybit.TradeSignal {
+	if signal.Action != "BUY" && signal.Action != "SELL" {
+		return signal
+	}
+
+	switch sf.Mode {
+	case FusionAgree:
+		if combined == nil {
+			return fusedHold(signal, "no combined indicator signal to confirm it")
+		}
+		if combined.Signal != signal.Action {
+			return fusedHold(signal, fmt.Sprintf("combined indicators signal %s (score %.2f)", combined.Signal, combined.Score))
+		}
+		if volume != nil && volume.BaseSignal != "HOLD" && volume.BaseSignal != signal.Action {
+			return fusedHold(signal, fmt.Sprintf("volume-weighted signal is %s (confidence %.2f)", volume.BaseSignal, volume.OverallConfidence))
+		}
+		signal.Reason += fmt.Sprintf(" [confirmed by combined indicators, score %.2f]", combined.Score)
+		return signal
+
+	case FusionWeighted:
+		strategyWeight := math.Max(1-sf.CombinedWeight-sf.VolumeWeight, 0)
+		score := strategyWeight * signedScore(signal.Action, signal.Strength)
+		total := strategyWeight
+		if combined != nil {
+			// Combined scores run from 0 (sell) to 1 (buy)
+			score += sf.CombinedWeight * (combined.Score - 0.5) * 2
+			total += sf.CombinedWeight
+		}
+		if volume != nil && (volume.BaseSignal == "BUY" || volume.BaseSignal == "SELL") {
+			score += sf.VolumeWeight * signedScore(volume.BaseSignal, volume.OverallConfidence)
+		}
+		if volume != nil {
+			total += sf.VolumeWeight
+		}
+		if total > 0 {
+			score /= total
+		}
+
+		strength := math.Abs(score)
+		if signedScore(signal.Action, 1)*score <= 0 || strength < sf.MinScore {
+			return fusedHold(signal, fmt.Sprintf("blended score %.2f below %.2f", signedScore(signal.Action, 1)*score, sf.MinScore))
+		}
+		signal.Reason += fmt.Sprintf(" [blended score %.2f from strength %.2f]", strength, signal.Strength)
+		signal.Strength = strength
+		return signal
+	}
+
+	return signal
+}
+
+// fusedHold is the HOLD that replaces a strategy signal the indicator signals do not back
+func fusedHold(signal bybit.TradeSignal, reason string) bybit.TradeSignal {
+	return bybit.TradeSignal{
+		Symbol:   signal.Symbol,
+		Action:   "HOLD",
+		Strength: signal.Strength,
+		Reason:   fmt.Sprintf("%s not executed: %s (%s)", signal.Action, reason, signal.Reason),
+	}
+}
@@ -0,0 +1,74 @@
+package strategy
+
+import "time"
+
+// OrderUpdate is a status change of an order placed for a strategy's signal
+type OrderUpdate struct {
+	OrderID        string
+	Symbol         string
+	Side           string // BUY, SELL
+	Status         string // NEW, PARTIALLY_FILLED, FILLED, CANCELLED, REJECTED
+	Quantity       float64
+	FilledQuantity float64
+	AvgFillPrice   float64
+	Timestamp      time.Time
+}
+
+// Fill is an execution against an order placed for a strategy's signal
+type Fill struct {
+	OrderID   string // Empty for market orders without an exchange order ID
+	Symbol    string
+	Side      string // BUY, SELL
+	Quantity  float64
+	Price     float64
+	Timestamp time.Time
+}
+
+// ClosedPosition is a position opened by a strategy that has been closed, either by one of
+// its own signals or by the risk layer (stop-loss, trailing stop, holding period)
+type ClosedPosition struct {
+	Symbol     string
+	Quantity   float64
+	Short      bool
+	EntryPrice float64
+	ExitPrice  float64
+	PnL        float64
+	Reason     string
+	Timestamp  time.Time
+}
+
+// OrderUpdateHandler is implemented by strategies that react to status changes of their orders
+type OrderUpdateHandler interface {
+	OnOrderUpdate(update OrderUpdate)
+}
+
+// FillHandler is implemented by strategies that react to fills of their orders
+type FillHandler interface {
+	OnFill(fill Fill)
+}
+
+// PositionCloseHandler is implemented by strategies that react to their positions being closed
+type PositionCloseHandler interface {
+	OnPositionClosed(position ClosedPosition)
+}
+
+// NotifyOrderUpdate passes an order update to the strategy if it implements OrderUpdateHandler
+func NotifyOrderUpdate(s Strategy, update OrderUpdate) {
+	if handler, ok := s.(OrderUpdateHandler); ok {
+		handler.OnOrderUpdate(update)
+	}
+}
+
+// NotifyFill passes a fill to the strategy if it implements FillHandler
+func NotifyFill(s Strategy, fill Fill) {
+	if handler, ok := s.(FillHandler); ok {
+		handler.OnFill(fill)
+	}
+}
+
+// NotifyPositionClosed passes a closed position to the strategy if it implements PositionCloseHandler
+func NotifyPositionClosed(s Strategy, position ClosedPosition) {
+	if handler, ok := s.(PositionCloseHandler); ok {
+		handler.OnPositionClosed(position)
+	}
+}
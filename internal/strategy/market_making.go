@@ -1,26 +1,89 @@
 package strategy
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/forbest/bybitgo/internal/bybit"
-	"github.com/shopspring/decimal"
+	"github.com/forbest/bybitgo/internal/market"
+	"github.com/forbest/bybitgo/internal/risk"
 )
 
-// MarketMakingStrategy implements the Avellaneda-Stoikov market making model
+// MarketMakingQuote is the outcome of the Avellaneda-Stoikov model for a symbol. Prices are in
+// the symbol's quote currency.
+type MarketMakingQuote struct {
+	MidPrice         float64
+	ReservationPrice float64 // Mid price shifted against the inventory
+	Spread           float64 // Optimal spread around the reservation price
+	Bid              float64
+	Ask              float64
+	Inventory        float64 // Signed inventory in lots of order_notional
+	Sigma            float64 // Volatility per bar as a fraction of the price
+	TimeRemaining    float64 // Fraction of the trading horizon left, between 0 and 1
+}
+
+// MarketMakingStrategy implements the Avellaneda-Stoikov market making model. Quotes are
+// centred on a reservation price that moves away from the mid price against the inventory, so
+// a long inventory is sold down and a short one bought back, and the spread widens with
+// volatility and the time left until the end of the horizon:
+//
+//	r = s * (1 - q * gamma * sigma^2 * (T - t))
+//	spread = s * (gamma * sigma^2 * (T - t) + 2/gamma * ln(1 + gamma/k))
+//
+// with sigma the volatility over the horizon. As a selected strategy it signals one side at a
+// time: the ask while it holds inventory, the bid otherwise. On its quoted symbols it keeps
+// post-only orders on both sides, refreshed once the model's price moves away from them, and
+// stops bidding (or hedges the excess) at the inventory limit.
 type MarketMakingStrategy struct {
 	Parameters map[string]float64
+	// Optional live inputs: volatility from the analyzer and inventory from the position tracker.
+	// Without them the "sigma" parameter and a flat inventory are used.
+	MarketAnalyzer *market.MarketAnalyzer
+	RiskManager    *risk.RiskManager
+	Orders         map[string]*QuoteOrder // Resting quotes by order ID
+	Inventory      map[string]float64     // Quantity bought by the quotes and not yet sold, per quoted symbol
+}
+
+// QuoteOrder is a resting market making quote
+type QuoteOrder struct {
+	OrderID        string
+	Symbol         string
+	Side           string // BUY, SELL
+	Quantity       float64
+	Price          float64
+	FilledQuantity float64
+	AvgFillPrice   float64
+	PlacedAt       time.Time
+}
+
+// QuoteTarget is a quote the strategy wants resting on one side of a symbol's book
+type QuoteTarget struct {
+	Symbol   string
+	Side     string // BUY, SELL
+	Price    float64
+	Quantity float64
 }
 
 // NewMarketMakingStrategy creates a new MarketMakingStrategy
 func NewMarketMakingStrategy() *MarketMakingStrategy {
 	return &MarketMakingStrategy{
 		Parameters: map[string]float64{
-			"gamma":     0.1,  // Risk factor
-			"k":         1.5,  // Order book liquidity factor
-			"sigma":     0.02, // Volatility estimate
-			"tick_size": 0.1,  // Minimum price increment
+			"gamma":          0.5,   // Risk aversion
+			"k":              1000,  // Order book liquidity, the arrival rate decay per unit of relative distance
+			"sigma":          0.002, // Volatility per bar used when the analyzer has none
+			"tick_size":      0,     // Minimum price increment quotes are rounded to (0 disables rounding)
+			"horizon_hours":  24,    // Length of the trading horizon (T)
+			"bar_minutes":    5,     // Kline interval, scales the per-bar volatility to the horizon
+			"order_notional": 100,   // Notional of each quote, also the inventory lot size
+			"min_spread_bps": 10,    // Spreads below this do not cover fees and are not quoted
+			"refresh_bps":    10,    // Quotes are replaced once the model's price moves this far from them
+			"max_inventory":  500,   // Maximum inventory value of a quoted symbol, bids stop above it
+			"hedge":          0,     // Sell inventory above the limit at market (0 only stops bidding)
 		},
+		Orders:    make(map[string]*QuoteOrder),
+		Inventory: make(map[string]float64),
 	}
 }
 
@@ -29,57 +92,279 @@ func (mms *MarketMakingStrategy) GetName() string {
 	return string(MarketMaking)
 }
 
-// Analyze implements the strategy analysis logic
+// RequiredBars returns the bars needed before a mid price is available
+func (mms *MarketMakingStrategy) RequiredBars() int {
+	return 1
+}
+
+// Analyze quotes the side that works the inventory back towards flat at the model's price
 func (mms *MarketMakingStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
-	if marketData == nil || len(marketData.Kline) == 0 {
+	if marketData == nil || len(marketData.Kline) < mms.RequiredBars() {
+		return notReadySignal(marketData, mms.RequiredBars())
+	}
+
+	quote := mms.Quote(marketData)
+	if quote.MidPrice <= 0 {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "No mid price",
+		}
+	}
+
+	spreadBps := quote.Spread / quote.MidPrice * 10000
+	reason := fmt.Sprintf("Reservation %.4f (mid %.4f, inventory %.2f lots), spread %.1f bps, bid %.4f, ask %.4f, sigma %.4f, %.0f%% of horizon left",
+		quote.ReservationPrice, quote.MidPrice, quote.Inventory, spreadBps, quote.Bid, quote.Ask, quote.Sigma, quote.TimeRemaining*100)
+
+	if spreadBps < mms.Parameters["min_spread_bps"] {
 		return bybit.TradeSignal{
 			Symbol: marketData.Symbol,
 			Action: "HOLD",
-			Reason: "Insufficient market data",
+			Reason: fmt.Sprintf("Spread below %.1f bps: %s", mms.Parameters["min_spread_bps"], reason),
 		}
 	}
 
-	// Use the last kline data for price
+	action, price := "BUY", quote.Bid
+	if quote.Inventory > 0 {
+		action, price = "SELL", quote.Ask
+	}
+
+	return bybit.TradeSignal{
+		Symbol:            marketData.Symbol,
+		Action:            action,
+		Strength:          math.Max(1-quote.Spread/quote.MidPrice, 0), // Lower spread = higher strength
+		Reason:            "Market making quote: " + reason,
+		EntryPrice:        price,
+		SuggestedQuantity: mms.Parameters["order_notional"] / price,
+		TimeInForce:       bybit.TimeInForcePostOnly,
+	}
+}
+
+// Quote computes the model's quotes from the latest kline
+func (mms *MarketMakingStrategy) Quote(marketData *bybit.MarketData) MarketMakingQuote {
 	lastKline := marketData.Kline[len(marketData.Kline)-1]
-	midPrice := lastKline.Close // Simplified - using close price as mid price
+	midPrice, _ := lastKline.Close.Float64() // Simplified - using close price as mid price
+
+	now := lastKline.Timestamp
+	if now.IsZero() {
+		now = marketData.Timestamp
+	}
+	return mms.QuoteAt(marketData.Symbol, midPrice, now)
+}
+
+// QuoteAt computes the reservation price, optimal spread and quotes of the Avellaneda-Stoikov
+// model for a mid price at a point in time
+func (mms *MarketMakingStrategy) QuoteAt(symbol string, midPrice float64, now time.Time) MarketMakingQuote {
+	quote := MarketMakingQuote{MidPrice: midPrice}
+	if midPrice <= 0 {
+		return quote
+	}
 
-	// Calculate bid-ask spread using Avellaneda-Stoikov formula
 	gamma := mms.Parameters["gamma"]
+	k := mms.Parameters["k"]
 
-	// Simplified optimal spread calculation
-	optimalSpread := gamma * mms.Parameters["sigma"] * mms.Parameters["sigma"]
+	// Volatility over the whole horizon from the per-bar volatility
+	quote.Sigma = mms.sigma(symbol)
+	barsPerHorizon := mms.Parameters["horizon_hours"] * 60 / mms.Parameters["bar_minutes"]
+	variance := quote.Sigma * quote.Sigma * barsPerHorizon
 
-	// Bid and ask prices
-	bidPrice := midPrice.Sub(decimal.NewFromFloat(optimalSpread / 2))
-	askPrice := midPrice.Add(decimal.NewFromFloat(optimalSpread / 2))
+	// Time left until the end of the current horizon, horizons being counted from the Unix epoch
+	// so daily horizons start at midnight UTC
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if horizon := time.Duration(mms.Parameters["horizon_hours"] * float64(time.Hour)); horizon > 0 {
+		elapsed := time.Duration(now.UnixNano()) % horizon
+		quote.TimeRemaining = 1 - float64(elapsed)/float64(horizon)
+	}
+
+	quote.Inventory = mms.inventory(symbol) * midPrice / mms.Parameters["order_notional"]
 
-	signal := "HOLD"
-	reason := fmt.Sprintf("Optimal spread: %.4f, Bid: %s, Ask: %s", optimalSpread, bidPrice.String(), askPrice.String())
+	quote.ReservationPrice = midPrice * (1 - quote.Inventory*gamma*variance*quote.TimeRemaining)
+	quote.Spread = midPrice * (gamma*variance*quote.TimeRemaining + 2/gamma*math.Log(1+gamma/k))
+	quote.Bid = quote.ReservationPrice - quote.Spread/2
+	quote.Ask = quote.ReservationPrice + quote.Spread/2
 
-	// Determine action based on spread and market conditions
-	if optimalSpread > 0.01 { // Minimum threshold for profitable spread
-		signal = "PLACE_ORDERS"
-		reason = fmt.Sprintf("Market making opportunity detected. Spread: %.4f", optimalSpread)
+	// Round the quotes away from the reservation price
+	if tick := mms.Parameters["tick_size"]; tick > 0 {
+		quote.Bid = math.Floor(quote.Bid/tick) * tick
+		quote.Ask = math.Ceil(quote.Ask/tick) * tick
 	}
 
-	return bybit.TradeSignal{
-		Symbol:   marketData.Symbol,
-		Action:   signal,
-		Strength: 1.0 - optimalSpread, // Lower spread = higher strength
-		Reason:   reason,
+	return quote
+}
+
+// sigma returns the recent per-bar volatility from the analyzer, or the "sigma" parameter
+func (mms *MarketMakingStrategy) sigma(symbol string) float64 {
+	if mms.MarketAnalyzer != nil {
+		if volatility, exists := mms.MarketAnalyzer.VolatilityTracker[symbol]; exists && volatility.RecentVolatility > 0 {
+			return volatility.RecentVolatility
+		}
+	}
+	return mms.Parameters["sigma"]
+}
+
+// inventory returns the inventory of a quoted symbol, or the signed position size of any other
+// symbol from the position tracker
+func (mms *MarketMakingStrategy) inventory(symbol string) float64 {
+	if inventory, quoted := mms.Inventory[symbol]; quoted {
+		return inventory
+	}
+	if mms.RiskManager == nil {
+		return 0
+	}
+	return mms.RiskManager.Positions[symbol].CurrentSize
+}
+
+// PlanQuotes returns the quotes that should rest on a symbol's book: a bid while the inventory is
+// below its limit and an ask while there is inventory to sell. Quotes never cross the best bid
+// and ask, and nothing is quoted when the spread does not cover fees.
+func (mms *MarketMakingStrategy) PlanQuotes(book *bybit.OrderBook, now time.Time) ([]QuoteTarget, MarketMakingQuote) {
+	if _, quoted := mms.Inventory[book.Symbol]; !quoted {
+		mms.Inventory[book.Symbol] = 0
+	}
+
+	bid, ask := book.BestBid(), book.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return nil, MarketMakingQuote{}
 	}
+	quote := mms.QuoteAt(book.Symbol, (bid+ask)/2, now)
+	if quote.Spread/quote.MidPrice*10000 < mms.Parameters["min_spread_bps"] {
+		return nil, quote
+	}
+
+	var targets []QuoteTarget
+	notional := mms.Parameters["order_notional"]
+	inventory := mms.Inventory[book.Symbol]
+	inventoryValue := inventory * quote.MidPrice
+
+	if room := mms.Parameters["max_inventory"] - inventoryValue; room > 0 {
+		price := math.Min(quote.Bid, bid)
+		if price > 0 {
+			targets = append(targets, QuoteTarget{Symbol: book.Symbol, Side: "BUY", Price: price, Quantity: math.Min(notional, room) / price})
+		}
+	}
+	if inventory > 0 {
+		price := math.Max(quote.Ask, ask)
+		targets = append(targets, QuoteTarget{Symbol: book.Symbol, Side: "SELL", Price: price, Quantity: math.Min(inventory, notional/price)})
+	}
+
+	return targets, quote
+}
+
+// NeedsRefresh reports whether a resting quote should be replaced by the target on its side:
+// the model's price moved beyond the refresh threshold from it
+func (mms *MarketMakingStrategy) NeedsRefresh(order *QuoteOrder, target QuoteTarget) (bool, string) {
+	if target.Price <= 0 {
+		return true, "side no longer quoted"
+	}
+	moveBps := math.Abs(order.Price-target.Price) / target.Price * 10000
+	if moveBps > mms.Parameters["refresh_bps"] {
+		return true, fmt.Sprintf("quote moved %.1f bps to %.4f", moveBps, target.Price)
+	}
+	return false, ""
+}
+
+// HedgeQuantity returns the inventory above the limit that is sold at market when hedging is on
+func (mms *MarketMakingStrategy) HedgeQuantity(symbol string, price float64) float64 {
+	if mms.Parameters["hedge"] <= 0 || price <= 0 {
+		return 0
+	}
+	excess := mms.Inventory[symbol] - mms.Parameters["max_inventory"]/price
+	return math.Max(excess, 0)
+}
+
+// RecordHedge removes inventory sold by a hedge
+func (mms *MarketMakingStrategy) RecordHedge(symbol string, quantity float64) {
+	mms.Inventory[symbol] = math.Max(mms.Inventory[symbol]-quantity, 0)
+}
+
+// TrackOrder registers a placed quote
+func (mms *MarketMakingStrategy) TrackOrder(orderID string, target QuoteTarget) {
+	mms.Orders[orderID] = &QuoteOrder{
+		OrderID:  orderID,
+		Symbol:   target.Symbol,
+		Side:     target.Side,
+		Quantity: target.Quantity,
+		Price:    target.Price,
+		PlacedAt: time.Now(),
+	}
+}
+
+// UpdateOrder applies the cumulative filled quantity and average price reported by the exchange
+// and returns the newly filled quantity and its price
+func (mms *MarketMakingStrategy) UpdateOrder(orderID string, filledQuantity, avgPrice float64) (float64, float64) {
+	order, exists := mms.Orders[orderID]
+	if !exists || filledQuantity <= order.FilledQuantity {
+		return 0, 0
+	}
+
+	// Price of the new fills from the change in the volume-weighted average
+	delta := filledQuantity - order.FilledQuantity
+	price := (avgPrice*filledQuantity - order.AvgFillPrice*order.FilledQuantity) / delta
+	order.FilledQuantity = filledQuantity
+	order.AvgFillPrice = avgPrice
+
+	if order.Side == "BUY" {
+		mms.Inventory[order.Symbol] += delta
+	} else {
+		mms.Inventory[order.Symbol] = math.Max(mms.Inventory[order.Symbol]-delta, 0)
+	}
+
+	return delta, price
+}
+
+// RemoveOrder stops tracking a filled or cancelled quote
+func (mms *MarketMakingStrategy) RemoveOrder(orderID string) {
+	delete(mms.Orders, orderID)
 }
 
-// Execute places market making orders
+// SymbolOrders returns the resting quotes of a symbol
+func (mms *MarketMakingStrategy) SymbolOrders(symbol string) []*QuoteOrder {
+	var orders []*QuoteOrder
+	for _, order := range mms.Orders {
+		if order.Symbol == symbol {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// Execute reports a signal whose order the bot has placed
 func (mms *MarketMakingStrategy) Execute(signal bybit.TradeSignal) error {
-	if signal.Action != "PLACE_ORDERS" {
+	if signal.Action == "HOLD" {
 		return nil // Nothing to execute
 	}
 
-	// In a real implementation, this would place bid and ask orders
-	// based on the calculated optimal spread
-	fmt.Printf("Executing market making strategy for %s: %s\n", signal.Symbol, signal.Reason)
+	fmt.Printf("Executing market making strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
+
+	return nil
+}
+
+// marketMakingState is the persisted working state of the market making strategy
+type marketMakingState struct {
+	Orders    map[string]*QuoteOrder `json:"orders"`
+	Inventory map[string]float64     `json:"inventory"`
+}
+
+// SaveState returns the resting quotes and inventory of the strategy, so resting quotes are
+// still managed and cancelled after a restart
+func (mms *MarketMakingStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(marketMakingState{Orders: mms.Orders, Inventory: mms.Inventory})
+}
 
+// LoadState restores the resting quotes and inventory of the strategy
+func (mms *MarketMakingStrategy) LoadState(data json.RawMessage) error {
+	var state marketMakingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Orders != nil {
+		mms.Orders = state.Orders
+	}
+	if state.Inventory != nil {
+		mms.Inventory = state.Inventory
+	}
 	return nil
 }
 
@@ -87,3 +372,8 @@ func (mms *MarketMakingStrategy) Execute(signal bybit.TradeSignal) error {
 func (mms *MarketMakingStrategy) GetParameters() map[string]float64 {
 	return mms.Parameters
 }
+
+// SetParameters validates and applies parameter changes
+func (mms *MarketMakingStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(MarketMaking, mms.Parameters, params)
+}
@@ -31,12 +31,8 @@ func (mrs *MeanReversionStrategy) GetName() string {
 
 // Analyze implements the mean reversion strategy analysis logic
 func (mrs *MeanReversionStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
-	if marketData == nil || len(marketData.Kline) == 0 {
-		return bybit.TradeSignal{
-			Symbol: marketData.Symbol,
-			Action: "HOLD",
-			Reason: "Insufficient market data",
-		}
+	if marketData == nil || len(marketData.Kline) < mrs.RequiredBars() {
+		return notReadySignal(marketData, mrs.RequiredBars())
 	}
 
 	// Calculate Bollinger Bands
@@ -76,21 +72,37 @@ func (mrs *MeanReversionStrategy) Analyze(marketData *bybit.MarketData) bybit.Tr
 			currentPrice, middleBand, rsi)
 	}
 
-	return bybit.TradeSignal{
+	signal := bybit.TradeSignal{
 		Symbol:   marketData.Symbol,
 		Action:   action,
 		Strength: strength,
 		Reason:   reason,
 	}
+
+	// Entries target the reversion to the middle band
+	if action != "HOLD" {
+		signal.EntryPrice = currentPrice
+		signal.TakeProfit = middleBand
+	}
+
+	return signal
 }
 
-// Execute places mean reversion trades
+// RequiredBars returns the bars needed before the Bollinger Bands and RSI are meaningful
+func (mrs *MeanReversionStrategy) RequiredBars() int {
+	required := int(mrs.Parameters["rsi_period"]) + 1
+	if period := int(mrs.Parameters["bollinger_period"]); period > required {
+		required = period
+	}
+	return required
+}
+
+// Execute reports a signal whose order the bot has placed
 func (mrs *MeanReversionStrategy) Execute(signal bybit.TradeSignal) error {
 	if signal.Action == "HOLD" {
 		return nil // Nothing to execute
 	}
 
-	// In a real implementation, this would place actual buy/sell orders
 	fmt.Printf("Executing mean reversion strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
 
 	return nil
@@ -101,6 +113,11 @@ func (mrs *MeanReversionStrategy) GetParameters() map[string]float64 {
 	return mrs.Parameters
 }
 
+// SetParameters validates and applies parameter changes
+func (mrs *MeanReversionStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(MeanReversion, mrs.Parameters, params)
+}
+
 // calculateBollingerBands calculates Bollinger Bands
 func (mrs *MeanReversionStrategy) calculateBollingerBands(marketData *bybit.MarketData) (float64, float64, float64) {
 	if len(marketData.Kline) < int(mrs.Parameters["bollinger_period"]) {
@@ -32,12 +32,8 @@ func (ms *MomentumStrategy) GetName() string {
 
 // Analyze implements the momentum strategy analysis logic
 func (ms *MomentumStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
-	if marketData == nil || len(marketData.Kline) == 0 {
-		return bybit.TradeSignal{
-			Symbol: marketData.Symbol,
-			Action: "HOLD",
-			Reason: "Insufficient market data",
-		}
+	if marketData == nil || len(marketData.Kline) < ms.RequiredBars() {
+		return notReadySignal(marketData, ms.RequiredBars())
 	}
 
 	// Calculate RSI (simplified)
@@ -79,13 +75,21 @@ func (ms *MomentumStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSig
 	}
 }
 
-// Execute places momentum-based trades
+// RequiredBars returns the bars needed before the RSI and MACD are meaningful
+func (ms *MomentumStrategy) RequiredBars() int {
+	required := int(ms.Parameters["rsi_period"]) + 1
+	if slow := int(ms.Parameters["macd_slow"]); slow > required {
+		required = slow
+	}
+	return required
+}
+
+// Execute reports a signal whose order the bot has placed
 func (ms *MomentumStrategy) Execute(signal bybit.TradeSignal) error {
 	if signal.Action == "HOLD" {
 		return nil // Nothing to execute
 	}
 
-	// In a real implementation, this would place actual buy/sell orders
 	fmt.Printf("Executing momentum strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
 
 	return nil
@@ -96,6 +100,11 @@ func (ms *MomentumStrategy) GetParameters() map[string]float64 {
 	return ms.Parameters
 }
 
+// SetParameters validates and applies parameter changes
+func (ms *MomentumStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(Momentum, ms.Parameters, params)
+}
+
 // calculateRSI calculates the Relative Strength Index (simplified)
 func (ms *MomentumStrategy) calculateRSI(marketData *bybit.MarketData) float64 {
 	if len(marketData.Kline) < int(ms.Parameters["rsi_period"]) {
@@ -0,0 +1,251 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// ScalpSignal is a passive limit order proposed from the order book
+type ScalpSignal struct {
+	Symbol    string
+	Action    string  // BUY, SELL, HOLD
+	Price     float64 // Limit price, joining the best bid or ask
+	Quantity  float64
+	Imbalance float64
+	SpreadBps float64
+	Reason    string
+}
+
+// ScalpOrder is a resting scalping order
+type ScalpOrder struct {
+	OrderID        string
+	Symbol         string
+	Side           string
+	Quantity       float64
+	Price          float64
+	FilledQuantity float64
+	AvgFillPrice   float64
+	PlacedAt       time.Time
+}
+
+// OrderBookScalpingStrategy quotes passively on the side the order book is leaning towards:
+// it joins the best bid when bids outweigh asks and sells its inventory at the best ask when
+// asks dominate. Orders are cancelled quickly once they are stale or no longer at the top.
+// Only symbols with a tight spread and deep book are traded.
+type OrderBookScalpingStrategy struct {
+	Parameters map[string]float64
+	Books      map[string]*bybit.OrderBook // Latest order book per symbol
+	Orders     map[string]*ScalpOrder      // Resting orders by order ID
+	Inventory  map[string]float64          // Quantity bought by the strategy and not yet sold
+}
+
+// NewOrderBookScalpingStrategy creates a new OrderBookScalpingStrategy
+func NewOrderBookScalpingStrategy() *OrderBookScalpingStrategy {
+	return &OrderBookScalpingStrategy{
+		Parameters: map[string]float64{
+			"depth_levels":        10,    // Order book levels used for imbalance and depth
+			"imbalance_threshold": 0.3,   // Minimum bid/ask imbalance to quote
+			"max_spread_bps":      5,     // Wider spreads mean the symbol is not liquid enough
+			"min_depth":           50000, // Minimum quote value on each side of the top levels
+			"order_notional":      50,    // Notional of each order in the quote currency
+			"max_inventory":       200,   // Maximum inventory value held by the strategy
+			"order_ttl_seconds":   15,    // Resting orders are cancelled after this long
+		},
+		Books:     make(map[string]*bybit.OrderBook),
+		Orders:    make(map[string]*ScalpOrder),
+		Inventory: make(map[string]float64),
+	}
+}
+
+// GetName returns the strategy name
+func (obs *OrderBookScalpingStrategy) GetName() string {
+	return string(OrderBookScalping)
+}
+
+// AnalyzeBook decides whether to join the best bid or ask of an order book
+func (obs *OrderBookScalpingStrategy) AnalyzeBook(book *bybit.OrderBook) ScalpSignal {
+	levels := int(obs.Parameters["depth_levels"])
+	signal := ScalpSignal{
+		Symbol:    book.Symbol,
+		Action:    "HOLD",
+		Imbalance: book.Imbalance(levels),
+		SpreadBps: book.SpreadBps(),
+	}
+	obs.Books[book.Symbol] = book
+
+	bid, ask := book.BestBid(), book.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		signal.Reason = "Empty order book"
+		return signal
+	}
+
+	// Only scalp liquid books
+	bidDepth, askDepth := book.Depth(levels)
+	if signal.SpreadBps > obs.Parameters["max_spread_bps"] {
+		signal.Reason = fmt.Sprintf("Spread %.2f bps above %.2f", signal.SpreadBps, obs.Parameters["max_spread_bps"])
+		return signal
+	}
+	if math.Min(bidDepth, askDepth) < obs.Parameters["min_depth"] {
+		signal.Reason = fmt.Sprintf("Depth %.0f/%.0f below %.0f", bidDepth, askDepth, obs.Parameters["min_depth"])
+		return signal
+	}
+
+	// One resting order per symbol
+	for _, order := range obs.Orders {
+		if order.Symbol == book.Symbol {
+			signal.Reason = fmt.Sprintf("Order %s resting at %.4f", order.OrderID, order.Price)
+			return signal
+		}
+	}
+
+	threshold := obs.Parameters["imbalance_threshold"]
+	inventory := obs.Inventory[book.Symbol]
+	switch {
+	case signal.Imbalance >= threshold:
+		if inventory*bid >= obs.Parameters["max_inventory"] {
+			signal.Reason = fmt.Sprintf("Inventory %.2f at limit %.2f", inventory*bid, obs.Parameters["max_inventory"])
+			return signal
+		}
+		signal.Action = "BUY"
+		signal.Price = bid
+		signal.Quantity = obs.Parameters["order_notional"] / bid
+		signal.Reason = fmt.Sprintf("Bid imbalance %.2f, spread %.2f bps: join bid at %.4f", signal.Imbalance, signal.SpreadBps, bid)
+	case signal.Imbalance <= -threshold && inventory > 0:
+		signal.Action = "SELL"
+		signal.Price = ask
+		signal.Quantity = math.Min(inventory, obs.Parameters["order_notional"]/ask)
+		signal.Reason = fmt.Sprintf("Ask imbalance %.2f, spread %.2f bps: join ask at %.4f", signal.Imbalance, signal.SpreadBps, ask)
+	default:
+		signal.Reason = fmt.Sprintf("Imbalance %.2f within %.2f", signal.Imbalance, threshold)
+	}
+
+	return signal
+}
+
+// TrackOrder registers a placed order
+func (obs *OrderBookScalpingStrategy) TrackOrder(orderID string, signal ScalpSignal) {
+	obs.Orders[orderID] = &ScalpOrder{
+		OrderID:  orderID,
+		Symbol:   signal.Symbol,
+		Side:     signal.Action,
+		Quantity: signal.Quantity,
+		Price:    signal.Price,
+		PlacedAt: time.Now(),
+	}
+}
+
+// UpdateOrder applies the cumulative filled quantity and average price reported by the exchange
+// and returns the newly filled quantity and its price
+func (obs *OrderBookScalpingStrategy) UpdateOrder(orderID string, filledQuantity, avgPrice float64) (float64, float64) {
+	order, exists := obs.Orders[orderID]
+	if !exists || filledQuantity <= order.FilledQuantity {
+		return 0, 0
+	}
+
+	// Price of the new fills from the change in the volume-weighted average
+	delta := filledQuantity - order.FilledQuantity
+	price := (avgPrice*filledQuantity - order.AvgFillPrice*order.FilledQuantity) / delta
+	order.FilledQuantity = filledQuantity
+	order.AvgFillPrice = avgPrice
+
+	if order.Side == "BUY" {
+		obs.Inventory[order.Symbol] += delta
+	} else {
+		obs.Inventory[order.Symbol] = math.Max(obs.Inventory[order.Symbol]-delta, 0)
+	}
+
+	return delta, price
+}
+
+// RemoveOrder stops tracking a filled or cancelled order
+func (obs *OrderBookScalpingStrategy) RemoveOrder(orderID string) {
+	delete(obs.Orders, orderID)
+}
+
+// IsStale reports whether a resting order should be cancelled: it outlived its time to live or
+// the market moved away so it is no longer at the best price
+func (obs *OrderBookScalpingStrategy) IsStale(order *ScalpOrder, now time.Time) (bool, string) {
+	ttl := time.Duration(obs.Parameters["order_ttl_seconds"] * float64(time.Second))
+	if now.Sub(order.PlacedAt) >= ttl {
+		return true, fmt.Sprintf("resting longer than %s", ttl)
+	}
+
+	book, exists := obs.Books[order.Symbol]
+	if !exists {
+		return false, ""
+	}
+	if order.Side == "BUY" && book.BestBid() > order.Price {
+		return true, fmt.Sprintf("best bid moved to %.4f", book.BestBid())
+	}
+	if order.Side == "SELL" && book.BestAsk() > 0 && book.BestAsk() < order.Price {
+		return true, fmt.Sprintf("best ask moved to %.4f", book.BestAsk())
+	}
+
+	return false, ""
+}
+
+// Analyze returns the scalping signal for the symbol's latest order book
+func (obs *OrderBookScalpingStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	book, exists := obs.Books[marketData.Symbol]
+	if !exists {
+		return bybit.TradeSignal{
+			Symbol: marketData.Symbol,
+			Action: "HOLD",
+			Reason: "No order book available",
+		}
+	}
+
+	signal := obs.AnalyzeBook(book)
+	return bybit.TradeSignal{
+		Symbol:   marketData.Symbol,
+		Action:   signal.Action,
+		Strength: math.Min(math.Abs(signal.Imbalance), 1),
+		Reason:   signal.Reason,
+	}
+}
+
+// Execute is a no-op, scalping orders are placed and managed through TrackOrder and UpdateOrder
+func (obs *OrderBookScalpingStrategy) Execute(signal bybit.TradeSignal) error {
+	return nil
+}
+
+// scalpingState is the persisted working state of the scalping strategy
+type scalpingState struct {
+	Orders    map[string]*ScalpOrder `json:"orders"`
+	Inventory map[string]float64     `json:"inventory"`
+}
+
+// SaveState returns the resting orders and inventory of the strategy, so resting orders are
+// still managed and cancelled after a restart
+func (obs *OrderBookScalpingStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(scalpingState{Orders: obs.Orders, Inventory: obs.Inventory})
+}
+
+// LoadState restores the resting orders and inventory of the strategy
+func (obs *OrderBookScalpingStrategy) LoadState(data json.RawMessage) error {
+	var state scalpingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Orders != nil {
+		obs.Orders = state.Orders
+	}
+	if state.Inventory != nil {
+		obs.Inventory = state.Inventory
+	}
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (obs *OrderBookScalpingStrategy) GetParameters() map[string]float64 {
+	return obs.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (obs *OrderBookScalpingStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(OrderBookScalping, obs.Parameters, params)
+}
@@ -0,0 +1,363 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// Pair trade actions
+const (
+	PairOpenLong  = "OPEN_LONG_SPREAD"  // Buy the first symbol, sell the second
+	PairOpenShort = "OPEN_SHORT_SPREAD" // Sell the first symbol, buy the second
+	PairClose     = "CLOSE"
+	PairHold      = "HOLD"
+)
+
+// Pair is two symbols whose spread is traded, the spread being log(A) - hedge ratio * log(B)
+type Pair struct {
+	SymbolA string
+	SymbolB string
+}
+
+// Key returns the pair's identifier, e.g. BTCUSDT/ETHUSDT
+func (p Pair) Key() string {
+	return p.SymbolA + "/" + p.SymbolB
+}
+
+// PairLeg is one side of a pair trade
+type PairLeg struct {
+	Symbol   string
+	Side     string // BUY, SELL
+	Quantity float64
+	Price    float64
+}
+
+// PairSignal is the outcome of analyzing a pair. Open and close signals carry both legs,
+// which have to be executed together.
+type PairSignal struct {
+	Pair        Pair
+	Action      string // OPEN_LONG_SPREAD, OPEN_SHORT_SPREAD, CLOSE, HOLD
+	ZScore      float64
+	HedgeRatio  float64
+	Correlation float64
+	ADFStat     float64 // Dickey-Fuller t-statistic of the spread, more negative is more mean-reverting
+	Legs        []PairLeg
+	Reason      string
+}
+
+// SpreadPosition is an open pair trade
+type SpreadPosition struct {
+	Pair        Pair
+	Direction   string // OPEN_LONG_SPREAD, OPEN_SHORT_SPREAD
+	HedgeRatio  float64
+	EntryZScore float64
+	Legs        []PairLeg // Entry legs
+	OpenedAt    time.Time
+}
+
+// PnL returns the unrealized profit of the spread position at the given prices
+func (sp *SpreadPosition) PnL(prices map[string]float64) float64 {
+	pnl := 0.0
+	for _, leg := range sp.Legs {
+		price, exists := prices[leg.Symbol]
+		if !exists {
+			continue
+		}
+		if leg.Side == "BUY" {
+			pnl += (price - leg.Price) * leg.Quantity
+		} else {
+			pnl += (leg.Price - price) * leg.Quantity
+		}
+	}
+	return pnl
+}
+
+// PairsTradingStrategy trades the spread between cointegrated symbols: it opens when the
+// spread's z-score is stretched and closes once it reverts to the mean
+type PairsTradingStrategy struct {
+	Parameters     map[string]float64
+	MarketAnalyzer *market.MarketAnalyzer
+	Pairs          []Pair
+	Positions      map[string]*SpreadPosition // pair key -> open spread position
+}
+
+// NewPairsTradingStrategy creates a new PairsTradingStrategy
+func NewPairsTradingStrategy(analyzer *market.MarketAnalyzer, pairs []Pair) *PairsTradingStrategy {
+	return &PairsTradingStrategy{
+		Parameters: map[string]float64{
+			"lookback":        60,    // Price history used for the hedge ratio and z-score
+			"entry_z":         2.0,   // Open when the spread is this many standard deviations from its mean
+			"exit_z":          0.5,   // Close when the spread is back within this band
+			"stop_z":          4.0,   // Close when the spread keeps diverging
+			"min_correlation": 0.8,   // Minimum price correlation of the pair
+			"adf_critical":    -3.34, // Engle-Granger 5% critical value for two series
+			"leg_notional":    100,   // Notional of the first leg, the second is hedge-weighted
+		},
+		MarketAnalyzer: analyzer,
+		Pairs:          pairs,
+		Positions:      make(map[string]*SpreadPosition),
+	}
+}
+
+// GetName returns the strategy name
+func (pts *PairsTradingStrategy) GetName() string {
+	return string(PairsTrading)
+}
+
+// AnalyzePair computes the spread statistics of a pair from the analyzer's price history and
+// decides whether to open, close or hold the spread position
+func (pts *PairsTradingStrategy) AnalyzePair(pair Pair) PairSignal {
+	signal := PairSignal{Pair: pair, Action: PairHold}
+
+	pricesA := pts.MarketAnalyzer.PriceHistory[pair.SymbolA]
+	pricesB := pts.MarketAnalyzer.PriceHistory[pair.SymbolB]
+	lookback := int(pts.Parameters["lookback"])
+	if len(pricesA) < lookback || len(pricesB) < lookback || lookback < 10 {
+		signal.Reason = fmt.Sprintf("Insufficient price history for %s (need %d)", pair.Key(), lookback)
+		return signal
+	}
+	pricesA = pricesA[len(pricesA)-lookback:]
+	pricesB = pricesB[len(pricesB)-lookback:]
+
+	// Regress log prices to find the hedge ratio, the residual is the spread
+	logA := make([]float64, lookback)
+	logB := make([]float64, lookback)
+	for i := 0; i < lookback; i++ {
+		if pricesA[i] <= 0 || pricesB[i] <= 0 {
+			signal.Reason = "Invalid price history"
+			return signal
+		}
+		logA[i] = math.Log(pricesA[i])
+		logB[i] = math.Log(pricesB[i])
+	}
+	hedgeRatio, intercept := olsFit(logB, logA)
+	spread := make([]float64, lookback)
+	for i := range spread {
+		spread[i] = logA[i] - hedgeRatio*logB[i] - intercept
+	}
+
+	mean, std := meanStd(spread)
+	if std == 0 {
+		signal.Reason = "Flat spread"
+		return signal
+	}
+	signal.HedgeRatio = hedgeRatio
+	signal.ZScore = (spread[lookback-1] - mean) / std
+	signal.Correlation = pts.MarketAnalyzer.GetCorrelation(pair.SymbolA, pair.SymbolB)
+	signal.ADFStat = dickeyFullerStat(spread)
+
+	priceA := pricesA[lookback-1]
+	priceB := pricesB[lookback-1]
+
+	// Manage an open spread position first
+	if pos, exists := pts.Positions[pair.Key()]; exists {
+		absZ := math.Abs(signal.ZScore)
+		switch {
+		case absZ <= pts.Parameters["exit_z"]:
+			signal.Reason = fmt.Sprintf("Spread reverted: z-score %.2f within %.2f", signal.ZScore, pts.Parameters["exit_z"])
+		case absZ >= pts.Parameters["stop_z"]:
+			signal.Reason = fmt.Sprintf("Spread stop: z-score %.2f beyond %.2f", signal.ZScore, pts.Parameters["stop_z"])
+		default:
+			signal.Reason = fmt.Sprintf("Holding %s: z-score %.2f", pos.Direction, signal.ZScore)
+			return signal
+		}
+
+		// Close each leg with the opposite side
+		signal.Action = PairClose
+		for _, leg := range pos.Legs {
+			side, price := "SELL", priceA
+			if leg.Side == "SELL" {
+				side = "BUY"
+			}
+			if leg.Symbol == pair.SymbolB {
+				price = priceB
+			}
+			signal.Legs = append(signal.Legs, PairLeg{Symbol: leg.Symbol, Side: side, Quantity: leg.Quantity, Price: price})
+		}
+		return signal
+	}
+
+	// Only trade pairs that move together and whose spread mean-reverts
+	if signal.Correlation < pts.Parameters["min_correlation"] {
+		signal.Reason = fmt.Sprintf("Correlation %.2f below %.2f", signal.Correlation, pts.Parameters["min_correlation"])
+		return signal
+	}
+	if signal.ADFStat > pts.Parameters["adf_critical"] {
+		signal.Reason = fmt.Sprintf("Not cointegrated: ADF statistic %.2f above %.2f", signal.ADFStat, pts.Parameters["adf_critical"])
+		return signal
+	}
+	if math.Abs(signal.ZScore) < pts.Parameters["entry_z"] || hedgeRatio <= 0 {
+		signal.Reason = fmt.Sprintf("No entry: z-score %.2f, hedge ratio %.2f", signal.ZScore, hedgeRatio)
+		return signal
+	}
+
+	// Size the legs so the second leg's value is the hedge ratio times the first
+	quantityA := pts.Parameters["leg_notional"] / priceA
+	quantityB := pts.Parameters["leg_notional"] * hedgeRatio / priceB
+
+	// A stretched spread is expected to narrow: sell the rich side and buy the cheap side
+	sideA, sideB := "BUY", "SELL"
+	signal.Action = PairOpenLong
+	if signal.ZScore > 0 {
+		sideA, sideB = "SELL", "BUY"
+		signal.Action = PairOpenShort
+	}
+	signal.Legs = []PairLeg{
+		{Symbol: pair.SymbolA, Side: sideA, Quantity: quantityA, Price: priceA},
+		{Symbol: pair.SymbolB, Side: sideB, Quantity: quantityB, Price: priceB},
+	}
+	signal.Reason = fmt.Sprintf("Spread z-score %.2f beyond %.2f (hedge ratio %.2f, correlation %.2f, ADF %.2f)",
+		signal.ZScore, pts.Parameters["entry_z"], hedgeRatio, signal.Correlation, signal.ADFStat)
+
+	return signal
+}
+
+// ExecutePair updates the tracked spread positions after both legs of a signal were filled
+func (pts *PairsTradingStrategy) ExecutePair(signal PairSignal) error {
+	switch signal.Action {
+	case PairOpenLong, PairOpenShort:
+		pts.Positions[signal.Pair.Key()] = &SpreadPosition{
+			Pair:        signal.Pair,
+			Direction:   signal.Action,
+			HedgeRatio:  signal.HedgeRatio,
+			EntryZScore: signal.ZScore,
+			Legs:        signal.Legs,
+			OpenedAt:    time.Now(),
+		}
+	case PairClose:
+		delete(pts.Positions, signal.Pair.Key())
+	}
+	return nil
+}
+
+// Analyze returns the leg for the symbol of the first configured pair that contains it
+func (pts *PairsTradingStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	for _, pair := range pts.Pairs {
+		if pair.SymbolA != marketData.Symbol && pair.SymbolB != marketData.Symbol {
+			continue
+		}
+
+		pairSignal := pts.AnalyzePair(pair)
+		for _, leg := range pairSignal.Legs {
+			if leg.Symbol == marketData.Symbol {
+				return bybit.TradeSignal{
+					Symbol:   marketData.Symbol,
+					Action:   leg.Side,
+					Strength: math.Min(math.Abs(pairSignal.ZScore)/pts.Parameters["stop_z"], 1),
+					Reason:   fmt.Sprintf("%s %s: %s", pairSignal.Action, pair.Key(), pairSignal.Reason),
+				}
+			}
+		}
+		return bybit.TradeSignal{Symbol: marketData.Symbol, Action: "HOLD", Reason: pairSignal.Reason}
+	}
+
+	return bybit.TradeSignal{
+		Symbol: marketData.Symbol,
+		Action: "HOLD",
+		Reason: "Symbol is not part of a configured pair",
+	}
+}
+
+// Execute is a no-op, pair legs are executed together through ExecutePair
+func (pts *PairsTradingStrategy) Execute(signal bybit.TradeSignal) error {
+	return nil
+}
+
+// SaveState returns the open spread positions of the strategy
+func (pts *PairsTradingStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(pts.Positions)
+}
+
+// LoadState restores the open spread positions of the strategy
+func (pts *PairsTradingStrategy) LoadState(data json.RawMessage) error {
+	positions := make(map[string]*SpreadPosition)
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+	pts.Positions = positions
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (pts *PairsTradingStrategy) GetParameters() map[string]float64 {
+	return pts.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (pts *PairsTradingStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(PairsTrading, pts.Parameters, params)
+}
+
+// olsFit returns the slope and intercept of the least squares fit of y on x
+func olsFit(x, y []float64) (float64, float64) {
+	meanX, _ := meanStd(x)
+	meanY, _ := meanStd(y)
+
+	covariance, variance := 0.0, 0.0
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		variance += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if variance == 0 {
+		return 0, meanY
+	}
+
+	slope := covariance / variance
+	return slope, meanY - slope*meanX
+}
+
+// dickeyFullerStat returns the t-statistic of b in the regression diff(s) = a + b * s(t-1),
+// a significantly negative value indicates a mean-reverting series
+func dickeyFullerStat(series []float64) float64 {
+	n := len(series) - 1
+	if n < 3 {
+		return 0
+	}
+
+	lagged := series[:n]
+	diffs := make([]float64, n)
+	for i := 0; i < n; i++ {
+		diffs[i] = series[i+1] - series[i]
+	}
+
+	b, a := olsFit(lagged, diffs)
+	meanLag, _ := meanStd(lagged)
+
+	residualSS, laggedSS := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		residual := diffs[i] - a - b*lagged[i]
+		residualSS += residual * residual
+		laggedSS += (lagged[i] - meanLag) * (lagged[i] - meanLag)
+	}
+	if laggedSS == 0 || residualSS == 0 {
+		return 0
+	}
+
+	standardError := math.Sqrt(residualSS / float64(n-2) / laggedSS)
+	return b / standardError
+}
+
+// meanStd returns the mean and population standard deviation of values
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,311 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// ParameterRange is the valid range of a strategy parameter
+type ParameterRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// parameterRanges holds the valid range of every tunable parameter by strategy. Periods are
+// bounded by the 100 klines fetched per symbol.
+var parameterRanges = map[StrategyType]map[string]ParameterRange{
+	MarketMaking: {
+		"gamma":          {0.001, 10},
+		"k":              {0.01, 100000},
+		"sigma":          {0.0001, 1},
+		"tick_size":      {0, 1000},
+		"horizon_hours":  {0.1, 168},
+		"bar_minutes":    {1, 1440},
+		"order_notional": {1, 1000000},
+		"min_spread_bps": {0, 1000},
+		"refresh_bps":    {0.1, 1000},
+		"max_inventory":  {1, 10000000},
+		"hedge":          {0, 1},
+	},
+	Momentum: {
+		"rsi_period":     {2, 50},
+		"rsi_overbought": {50, 100},
+		"rsi_oversold":   {0, 50},
+		"macd_fast":      {2, 50},
+		"macd_slow":      {3, 80},
+		"macd_signal":    {2, 20},
+	},
+	MeanReversion: {
+		"bollinger_period": {5, 100},
+		"bollinger_std":    {0.5, 5},
+		"rsi_period":       {2, 50},
+		"rsi_overbought":   {50, 100},
+		"rsi_oversold":     {0, 50},
+	},
+	VolatilityBreakout: {
+		"period":           {5, 100},
+		"multiplier":       {0.5, 5},
+		"min_volume_ratio": {0, 10},
+	},
+	TrendFollowing: {
+		"entry_period":         {5, 90},
+		"exit_period":          {2, 90},
+		"atr_period":           {2, 50},
+		"atr_stop_multiplier":  {0.5, 10},
+		"atr_trail_multiplier": {0.5, 10},
+		"reward_risk":          {0, 10},
+		"htf_minutes":          {0, 10080},
+		"htf_period":           {2, 100},
+	},
+	BreakoutRetest: {
+		"level_lookback":   {20, 80},
+		"pivot_strength":   {1, 10},
+		"min_touches":      {1, 10},
+		"level_tolerance":  {0.01, 5},
+		"breakout_percent": {0, 10},
+		"max_retest_bars":  {2, 20},
+		"volume_period":    {5, 50},
+		"min_volume_ratio": {0, 10},
+		"reward_risk":      {0.5, 10},
+	},
+	DCA: {
+		"amount":         {1, 1000000},
+		"interval_hours": {0.1, 24 * 30},
+		"ma_period":      {5, 100},
+		"dip_percent":    {0, 50},
+		"dip_multiplier": {1, 10},
+	},
+	PairsTrading: {
+		"lookback":        {10, 100},
+		"entry_z":         {0.5, 5},
+		"exit_z":          {0, 3},
+		"stop_z":          {1, 10},
+		"min_correlation": {-1, 1},
+		"leg_notional":    {1, 1000000},
+	},
+	FundingArbitrage: {
+		"entry_rate":        {0, 0.01},
+		"exit_rate":         {-0.01, 0.01},
+		"max_basis_percent": {0, 10},
+		"notional":          {1, 1000000},
+	},
+	OrderBookScalping: {
+		"depth_levels":        {1, 50},
+		"imbalance_threshold": {0.01, 0.99},
+		"max_spread_bps":      {0.1, 100},
+		"min_depth":           {0, 100000000},
+		"order_notional":      {1, 1000000},
+		"max_inventory":       {1, 10000000},
+		"order_ttl_seconds":   {1, 3600},
+	},
+	Ensemble: {
+		"min_consensus":   {0.01, 1},
+		"learn_weights":   {0, 1},
+		"accuracy_window": {5, 500},
+	},
+	Selector: {
+		"bandit":       {0, 1},
+		"bandit_prior": {0.1, 100},
+		"bandit_decay": {0.5, 1},
+	},
+	TriangularArbitrage: {
+		"taker_fee_percent":  {0, 1},
+		"min_profit_percent": {0, 10},
+		"max_latency_ms":     {1, 10000},
+		"notional":           {0.00001, 1000000},
+	},
+}
+
+// parameterOrder lists parameters that must stay strictly below another parameter of the same strategy
+var parameterOrder = map[StrategyType][][2]string{
+	Momentum:         {{"rsi_oversold", "rsi_overbought"}, {"macd_fast", "macd_slow"}},
+	MeanReversion:    {{"rsi_oversold", "rsi_overbought"}},
+	TrendFollowing:   {{"exit_period", "entry_period"}},
+	PairsTrading:     {{"exit_z", "entry_z"}, {"entry_z", "stop_z"}},
+	FundingArbitrage: {{"exit_rate", "entry_rate"}},
+}
+
+// ParameterRanges returns the valid parameter ranges of a strategy
+func ParameterRanges(strategyType StrategyType) map[string]ParameterRange {
+	return parameterRanges[strategyType]
+}
+
+// ValidateParameters checks that parameter changes only set known parameters of the strategy,
+// stay within their ranges and keep ordered parameters in order once applied to the current values
+func ValidateParameters(strategyType StrategyType, current, params map[string]float64) error {
+	ranges := parameterRanges[strategyType]
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	merged := make(map[string]float64, len(current))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for _, name := range names {
+		value := params[name]
+		if _, exists := current[name]; !exists {
+			return fmt.Errorf("unknown parameter %q for strategy %s", name, strategyType)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("parameter %q of strategy %s is not a number", name, strategyType)
+		}
+		if r, exists := ranges[name]; exists && (value < r.Min || value > r.Max) {
+			return fmt.Errorf("parameter %q of strategy %s is %g, outside [%g, %g]", name, strategyType, value, r.Min, r.Max)
+		}
+		merged[name] = value
+	}
+
+	for _, order := range parameterOrder[strategyType] {
+		if merged[order[0]] >= merged[order[1]] {
+			return fmt.Errorf("parameter %q of strategy %s must be below %q (%g >= %g)",
+				order[0], strategyType, order[1], merged[order[0]], merged[order[1]])
+		}
+	}
+
+	return nil
+}
+
+// setParameters validates parameter changes and applies them all, or none if any is invalid
+func setParameters(strategyType StrategyType, current, params map[string]float64) error {
+	if err := ValidateParameters(strategyType, current, params); err != nil {
+		return err
+	}
+	for name, value := range params {
+		current[name] = value
+	}
+	return nil
+}
+
+// ParameterConfig holds strategy parameters loaded from a file: global values applied to every
+// symbol, per-symbol overrides applied on top of them and regime profiles applied last while the
+// symbol's market regime matches. A profile is keyed by a regime condition (e.g.
+// high_volatility, ranging, low_volume) or a trend/volatility combination (e.g.
+// trending_up/high_volatility), the combination taking precedence.
+type ParameterConfig struct {
+	Global  map[StrategyType]map[string]float64            `json:"global,omitempty"`
+	Symbols map[string]map[StrategyType]map[string]float64 `json:"symbols,omitempty"`
+	Regimes map[string]map[StrategyType]map[string]float64 `json:"regimes,omitempty"`
+}
+
+// LoadParameterConfig reads strategy parameters from a JSON file
+func LoadParameterConfig(path string) (*ParameterConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategy parameters: %w", err)
+	}
+
+	var pc ParameterConfig
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("failed to parse strategy parameters %s: %w", path, err)
+	}
+	return &pc, nil
+}
+
+// Apply sets the global parameters on the strategies and checks that every symbol override is
+// valid on top of them. Strategies that are not running are skipped.
+func (pc *ParameterConfig) Apply(strategies map[StrategyType]Strategy) error {
+	for strategyType, params := range pc.Global {
+		if !isKnownStrategy(strategyType) {
+			return fmt.Errorf("unknown strategy %s", strategyType)
+		}
+		if impl, exists := strategies[strategyType]; exists {
+			if err := impl.SetParameters(params); err != nil {
+				return err
+			}
+		}
+	}
+
+	for symbol, overrides := range pc.Symbols {
+		for strategyType, params := range overrides {
+			if !isKnownStrategy(strategyType) {
+				return fmt.Errorf("unknown strategy %s for %s", strategyType, symbol)
+			}
+			if impl, exists := strategies[strategyType]; exists {
+				if err := ValidateParameters(strategyType, impl.GetParameters(), params); err != nil {
+					return fmt.Errorf("%s: %w", symbol, err)
+				}
+			}
+		}
+	}
+
+	for profile, overrides := range pc.Regimes {
+		for strategyType, params := range overrides {
+			if !isKnownStrategy(strategyType) {
+				return fmt.Errorf("unknown strategy %s in regime profile %s", strategyType, profile)
+			}
+			if impl, exists := strategies[strategyType]; exists {
+				if err := ValidateParameters(strategyType, impl.GetParameters(), params); err != nil {
+					return fmt.Errorf("regime profile %s: %w", profile, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// isKnownStrategy reports whether a strategy is built in or registered
+func isKnownStrategy(strategyType StrategyType) bool {
+	_, builtIn := parameterRanges[strategyType]
+	return builtIn || IsRegistered(strategyType)
+}
+
+// HasOverrides reports whether any symbol or regime profile overrides parameters of the strategy
+func (pc *ParameterConfig) HasOverrides(strategyType StrategyType) bool {
+	for _, overrides := range pc.Symbols {
+		if _, exists := overrides[strategyType]; exists {
+			return true
+		}
+	}
+	for _, overrides := range pc.Regimes {
+		if _, exists := overrides[strategyType]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// ForSymbol returns the strategy's parameters for a symbol: the base parameters with the
+// symbol's overrides applied
+func (pc *ParameterConfig) ForSymbol(strategyType StrategyType, symbol string, base map[string]float64) map[string]float64 {
+	params := make(map[string]float64, len(base))
+	for name, value := range base {
+		params[name] = value
+	}
+	for name, value := range pc.Symbols[symbol][strategyType] {
+		params[name] = value
+	}
+	return params
+}
+
+// ForRegime returns the strategy's parameters for a symbol in a market regime: the symbol's
+// parameters with the matching regime profiles applied, and the names of those profiles
+func (pc *ParameterConfig) ForRegime(strategyType StrategyType, symbol string, regime *market.MarketRegime, base map[string]float64) (map[string]float64, []string) {
+	params := pc.ForSymbol(strategyType, symbol, base)
+	if regime == nil {
+		return params, nil
+	}
+
+	// From the most general to the most specific profile
+	var profiles []string
+	for _, profile := range []string{regime.Volume, regime.Trend, regime.Volatility, RegimeKey(regime)} {
+		overrides, exists := pc.Regimes[profile][strategyType]
+		if !exists {
+			continue
+		}
+		for name, value := range overrides {
+			params[name] = value
+		}
+		profiles = append(profiles, profile)
+	}
+	return params, profiles
+}
@@ -0,0 +1,30 @@
+package strategy
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin opens a Go plugin whose init functions register strategies and returns the names
+// it registered. The plugin must be built with the same Go version and module versions as the bot.
+func LoadPlugin(path string) ([]StrategyType, error) {
+	before := make(map[StrategyType]bool)
+	for _, name := range Registered() {
+		before[name] = true
+	}
+
+	if _, err := plugin.Open(path); err != nil {
+		return nil, fmt.Errorf("failed to open strategy plugin %s: %w", path, err)
+	}
+
+	var added []StrategyType
+	for _, name := range Registered() {
+		if !before[name] {
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return nil, fmt.Errorf("strategy plugin %s did not register any strategy", path)
+	}
+	return added, nil
+}
@@ -0,0 +1,85 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// Factory creates a strategy with default parameters
+type Factory func() Strategy
+
+// RegimeWeighter is implemented by registered strategies that want a say in the StrategyAI's
+// selection: the returned weight competes with the built-in strategies' regime weights
+type RegimeWeighter interface {
+	RegimeWeight(regime *market.MarketRegime) float64
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[StrategyType]Factory)
+)
+
+func init() {
+	// Built-in strategies that trade from klines alone
+	Register(MarketMaking, func() Strategy { return NewMarketMakingStrategy() })
+	Register(Momentum, func() Strategy { return NewMomentumStrategy() })
+	Register(MeanReversion, func() Strategy { return NewMeanReversionStrategy() })
+	Register(VolatilityBreakout, func() Strategy { return NewVolatilityBreakoutStrategy() })
+	Register(TrendFollowing, func() Strategy { return NewTrendFollowingStrategy() })
+	Register(BreakoutRetest, func() Strategy { return NewBreakoutRetestStrategy() })
+	Register(DCA, func() Strategy { return NewDCAStrategy() })
+	Register(Selector, func() Strategy { return NewSelectorStrategy() })
+}
+
+// Register makes a strategy available by name. Third-party strategies call it from an init
+// function, either compiled into the binary or in a Go plugin. It panics if the name is empty,
+// the factory is nil or the name is already registered.
+func Register(name StrategyType, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if name == "" || factory == nil {
+		panic("strategy: Register needs a name and a factory")
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("strategy: Register called twice for %s", name))
+	}
+	registry[name] = factory
+}
+
+// Registered returns the names of all registered strategies, sorted
+func Registered() []StrategyType {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]StrategyType, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// IsRegistered reports whether a strategy is registered
+func IsRegistered(name StrategyType) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	_, exists := registry[name]
+	return exists
+}
+
+// NewStrategy creates a registered strategy with default parameters
+func NewStrategy(strategyType StrategyType) (Strategy, error) {
+	registryMutex.RLock()
+	factory, exists := registry[strategyType]
+	registryMutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("strategy %s is not registered or cannot be created from klines alone", strategyType)
+	}
+	return factory(), nil
+}
@@ -0,0 +1,14 @@
+package strategy
+
+// SeededStrategy is implemented by strategies that make random decisions. Backtests seed them
+// before a run so it can be repeated.
+type SeededStrategy interface {
+	Seed(seed int64)
+}
+
+// Seed seeds a strategy's randomness if it has any
+func Seed(s Strategy, seed int64) {
+	if seeded, ok := s.(SeededStrategy); ok {
+		seeded.Seed(seed)
+	}
+}
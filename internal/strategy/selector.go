@@ -0,0 +1,188 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// regimeBars is the history the market analyzer needs to classify a regime, the period of MACD's
+// slow average
+const regimeBars = 26
+
+// SelectorStrategy trades the strategy the StrategyAI selects for each symbol, switching
+// strategies as the live bot does, so the selection itself can be backtested: at every analysis
+// its own market analyzer classifies the symbol's regime, the regime weights (scaled by the
+// bandit's sampled win rates when enabled) pick a strategy and that strategy's signal is
+// returned. Positions are credited to the strategy whose signal opened them, which teaches the
+// bandit when they are closed.
+type SelectorStrategy struct {
+	Parameters map[string]float64
+	AI         *StrategyAI
+	Strategies map[StrategyType]Strategy // Candidates of the selection
+	// Analyses each strategy was selected at, and changes of a symbol's selected strategy
+	Selections map[StrategyType]int
+	Switches   int
+	current    map[string]StrategyType // Strategy selected at each symbol's last analysis
+	owners     map[string]StrategyType // Strategy whose signal opened each symbol's position
+	seed       int64
+}
+
+// NewSelectorStrategy creates a new SelectorStrategy choosing among the built-in strategies the
+// StrategyAI weighs by regime
+func NewSelectorStrategy() *SelectorStrategy {
+	return &SelectorStrategy{
+		Parameters: map[string]float64{
+			"bandit":       0,    // Thompson sampling of win rates per regime (1 enables)
+			"bandit_prior": 2,    // Pseudo-wins and pseudo-losses of every strategy before any trade
+			"bandit_decay": 0.98, // Weight kept by past outcomes at each new outcome
+		},
+		AI: NewStrategyAI(market.NewMarketAnalyzer()),
+		Strategies: map[StrategyType]Strategy{
+			MarketMaking:       NewMarketMakingStrategy(),
+			Momentum:           NewMomentumStrategy(),
+			MeanReversion:      NewMeanReversionStrategy(),
+			VolatilityBreakout: NewVolatilityBreakoutStrategy(),
+			TrendFollowing:     NewTrendFollowingStrategy(),
+			BreakoutRetest:     NewBreakoutRetestStrategy(),
+		},
+		Selections: make(map[StrategyType]int),
+		current:    make(map[string]StrategyType),
+		owners:     make(map[string]StrategyType),
+	}
+}
+
+// AddStrategy lets a registered strategy compete in the selection, see StrategyAI.AddStrategy
+func (ss *SelectorStrategy) AddStrategy(strategyType StrategyType, strategy Strategy) {
+	ss.Strategies[strategyType] = strategy
+	ss.AI.AddStrategy(strategyType, strategy)
+}
+
+// GetName returns the strategy name
+func (ss *SelectorStrategy) GetName() string {
+	return string(Selector)
+}
+
+// RequiredBars returns the bars the regime classification and the slowest candidate need, as
+// any of them may be selected
+func (ss *SelectorStrategy) RequiredBars() int {
+	required := regimeBars
+	for _, strategy := range ss.Strategies {
+		if bars := RequiredBars(strategy); bars > required {
+			required = bars
+		}
+	}
+	return required
+}
+
+// Timeframes returns the additional timeframes analyzed by any candidate
+func (ss *SelectorStrategy) Timeframes() []string {
+	var intervals []string
+	seen := make(map[string]bool)
+	for _, strategy := range ss.Strategies {
+		for _, interval := range Timeframes(strategy) {
+			if !seen[interval] {
+				seen[interval] = true
+				intervals = append(intervals, interval)
+			}
+		}
+	}
+	sort.Strings(intervals)
+	return intervals
+}
+
+// Seed seeds the candidates and the bandit's draws
+func (ss *SelectorStrategy) Seed(seed int64) {
+	ss.seed = seed
+	for _, strategy := range ss.Strategies {
+		Seed(strategy, seed)
+	}
+	if ss.AI.Bandit != nil {
+		ss.AI.Bandit.Seed(seed)
+	}
+}
+
+// Analyze selects a strategy for the symbol's current regime and returns its signal
+func (ss *SelectorStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	if marketData == nil || len(marketData.Kline) < regimeBars {
+		return bybit.TradeSignal{Action: "HOLD", Reason: "Insufficient market data"}
+	}
+	symbol := marketData.Symbol
+
+	if _, err := ss.AI.MarketAnalyzer.AnalyzeMarketConditions(context.Background(), symbol, marketData); err != nil {
+		return bybit.TradeSignal{Symbol: symbol, Action: "HOLD", Reason: fmt.Sprintf("Market regime unavailable: %v", err)}
+	}
+	selected := ss.AI.SelectStrategy(symbol)
+	if previous, exists := ss.current[symbol]; exists && previous != selected {
+		ss.Switches++
+	}
+	ss.current[symbol] = selected
+	ss.Selections[selected]++
+
+	strategy, exists := ss.Strategies[selected]
+	if !exists {
+		return bybit.TradeSignal{Symbol: symbol, Action: "HOLD", Reason: fmt.Sprintf("No implementation for strategy %s", selected)}
+	}
+	signal := strategy.Analyze(marketData)
+	signal.Reason = fmt.Sprintf("%s: %s", selected, signal.Reason)
+	return signal
+}
+
+// Execute reports a signal whose order the bot has placed to the strategy selected for it
+func (ss *SelectorStrategy) Execute(signal bybit.TradeSignal) error {
+	if strategy, exists := ss.Strategies[ss.current[signal.Symbol]]; exists {
+		return strategy.Execute(signal)
+	}
+	return nil
+}
+
+// OnOrderUpdate forwards an order update to the strategy selected for the symbol
+func (ss *SelectorStrategy) OnOrderUpdate(update OrderUpdate) {
+	NotifyOrderUpdate(ss.Strategies[ss.current[update.Symbol]], update)
+}
+
+// OnFill forwards a fill to the strategy selected for the symbol. A BUY opening a position makes
+// that strategy its owner and remembers the regime it was opened in.
+func (ss *SelectorStrategy) OnFill(fill Fill) {
+	owner, exists := ss.owners[fill.Symbol]
+	if !exists && fill.Side == "BUY" {
+		owner = ss.current[fill.Symbol]
+		ss.owners[fill.Symbol] = owner
+		ss.AI.RecordEntry(fill.Symbol)
+	}
+	NotifyFill(ss.Strategies[owner], fill)
+}
+
+// OnPositionClosed credits a closed position's PnL to the strategy that opened it
+func (ss *SelectorStrategy) OnPositionClosed(position ClosedPosition) {
+	owner, exists := ss.owners[position.Symbol]
+	if !exists {
+		owner = ss.current[position.Symbol]
+	}
+	delete(ss.owners, position.Symbol)
+
+	ss.AI.RecordOutcome(position.Symbol, owner, position.PnL)
+	NotifyPositionClosed(ss.Strategies[owner], position)
+}
+
+// GetParameters returns the strategy parameters
+func (ss *SelectorStrategy) GetParameters() map[string]float64 {
+	return ss.Parameters
+}
+
+// SetParameters validates and applies parameter changes, starting a new bandit without any
+// experience when the bandit is enabled
+func (ss *SelectorStrategy) SetParameters(params map[string]float64) error {
+	if err := setParameters(Selector, ss.Parameters, params); err != nil {
+		return err
+	}
+	ss.AI.Bandit = nil
+	if ss.Parameters["bandit"] > 0 {
+		ss.AI.Bandit = NewStrategyBandit(ss.Parameters["bandit_prior"], ss.Parameters["bandit_decay"])
+		ss.AI.Bandit.Seed(ss.seed)
+	}
+	return nil
+}
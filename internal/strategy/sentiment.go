@@ -0,0 +1,34 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// SentimentFilter holds back signals that chase extreme market sentiment: BUYs while the
+// sentiment of the market data is above buyMax (greed) and SELLs while it is below sellMin
+// (fear). Signals pass if the market data carries no sentiment.
+func SentimentFilter(signal bybit.TradeSignal, marketData *bybit.MarketData, buyMax, sellMin float64) bybit.TradeSignal {
+	if marketData == nil || marketData.Sentiment == nil {
+		return signal
+	}
+
+	sentiment := *marketData.Sentiment
+	var reason string
+	switch {
+	case signal.Action == "BUY" && sentiment > buyMax:
+		reason = fmt.Sprintf("sentiment %.2f above %.2f", sentiment, buyMax)
+	case signal.Action == "SELL" && sentiment < sellMin:
+		reason = fmt.Sprintf("sentiment %.2f below %.2f", sentiment, sellMin)
+	default:
+		return signal
+	}
+
+	return bybit.TradeSignal{
+		Symbol:   signal.Symbol,
+		Action:   "HOLD",
+		Strength: signal.Strength,
+		Reason:   fmt.Sprintf("%s filtered: %s (%s)", signal.Action, reason, signal.Reason),
+	}
+}
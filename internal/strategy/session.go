@@ -0,0 +1,16 @@
+package strategy
+
+// SessionStrategy is implemented by strategies that only trade in certain UTC sessions, e.g.
+// "MON-FRI 04:00-24:00" to avoid the quiet hours and weekends. Sessions configured for the
+// strategy take precedence over the declared ones.
+type SessionStrategy interface {
+	Sessions() string
+}
+
+// Sessions returns the trading sessions a strategy declares, or "" if it may always trade
+func Sessions(s Strategy) string {
+	if scheduled, ok := s.(SessionStrategy); ok {
+		return scheduled.Sessions()
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/persistence"
+)
+
+// stateFile is the file name of the strategy state snapshot inside the data directory
+const stateFile = "strategy_state.json"
+
+// StatefulStrategy is implemented by strategies with working state (resting orders, open
+// positions, inventory) that must survive a restart, so live orders are not orphaned
+type StatefulStrategy interface {
+	SaveState() (json.RawMessage, error)
+	LoadState(data json.RawMessage) error
+}
+
+// StrategyStateSnapshot is the persisted working state of the stateful strategies
+type StrategyStateSnapshot struct {
+	SavedAt time.Time                  `json:"saved_at"`
+	States  map[string]json.RawMessage `json:"states"` // Strategy type -> strategy state
+}
+
+// StatePath returns the path of the strategy state snapshot inside a data directory
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, stateFile)
+}
+
+// SaveStates writes the working state of every stateful strategy to disk
+func SaveStates(path string, strategies map[StrategyType]Strategy) error {
+	snapshot := StrategyStateSnapshot{
+		SavedAt: time.Now(),
+		States:  make(map[string]json.RawMessage),
+	}
+
+	for _, strategyType := range sortedStrategyTypes(strategies) {
+		stateful, ok := strategies[strategyType].(StatefulStrategy)
+		if !ok {
+			continue
+		}
+		data, err := stateful.SaveState()
+		if err != nil {
+			return fmt.Errorf("failed to save state of strategy %s: %w", strategyType, err)
+		}
+		snapshot.States[string(strategyType)] = data
+	}
+
+	if err := persistence.SaveJSON(path, snapshot); err != nil {
+		return fmt.Errorf("failed to save strategy state: %w", err)
+	}
+
+	return nil
+}
+
+// LoadStates restores the working state of the stateful strategies from the last snapshot, if
+// one exists. States of strategies that are no longer enabled are ignored.
+func LoadStates(path string, strategies map[StrategyType]Strategy) error {
+	var snapshot StrategyStateSnapshot
+	found, err := persistence.LoadJSON(path, &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to load strategy state: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	for _, strategyType := range sortedStrategyTypes(strategies) {
+		data, exists := snapshot.States[string(strategyType)]
+		if !exists {
+			continue
+		}
+		stateful, ok := strategies[strategyType].(StatefulStrategy)
+		if !ok {
+			continue
+		}
+		if err := stateful.LoadState(data); err != nil {
+			return fmt.Errorf("failed to load state of strategy %s: %w", strategyType, err)
+		}
+	}
+
+	return nil
+}
+
+// sortedStrategyTypes returns the strategy types of a strategy map in a stable order
+func sortedStrategyTypes(strategies map[StrategyType]Strategy) []StrategyType {
+	types := make([]StrategyType, 0, len(strategies))
+	for strategyType := range strategies {
+		types = append(types, strategyType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -4,10 +4,13 @@ import (
 	"github.com/forbest/bybitgo/internal/bybit"
 )
 
-// Strategy defines the interface for trading strategies
+// Strategy defines the interface for trading strategies. Strategies do not place orders
+// themselves: the bot sends risk-checked signals to its order executor and calls Execute once
+// the order was placed, so the strategy can track what was traded.
 type Strategy interface {
 	Analyze(marketData *bybit.MarketData) bybit.TradeSignal
 	Execute(signal bybit.TradeSignal) error
 	GetName() string
 	GetParameters() map[string]float64
+	SetParameters(params map[string]float64) error
 }
@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/testutil"
+	"github.com/shopspring/decimal"
+)
+
+func TestTrendFollowingSignals(t *testing.T) {
+	testutil.RunCases(t, func() testutil.Analyzer { return NewTrendFollowingStrategy() }, []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(5).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "range", Data: testutil.NewKlineBuilder(100, 1).Range(100, 2, 20).MarketData("BTCUSDT"), Action: "HOLD"},
+		{Name: "uptrend", Data: testutil.NewKlineBuilder(100, 1).Flat(30).Trend(60, 0.5).MarketData("BTCUSDT"), Action: "BUY", MinStrength: 0.5},
+		{Name: "gap up", Data: testutil.NewKlineBuilder(100, 1).Flat(60).Gap(3).Flat(1).MarketData("BTCUSDT"), Action: "BUY", MinStrength: 0.9, TradePlan: true},
+		{Name: "gap down", Data: testutil.NewKlineBuilder(100, 1).Flat(60).Gap(-3).Flat(1).MarketData("BTCUSDT"), Action: "SELL", MinStrength: 0.9, TradePlan: true},
+	})
+}
+
+func TestMeanReversionSignals(t *testing.T) {
+	testutil.RunCases(t, func() testutil.Analyzer { return NewMeanReversionStrategy() }, []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(5).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "flat", Data: testutil.NewKlineBuilder(100, 1).Flat(100).MarketData("BTCUSDT"), Action: "HOLD"},
+		{Name: "drop below the band", Data: testutil.NewKlineBuilder(100, 1).Flat(60).Gap(-3).Flat(2).MarketData("BTCUSDT"), Action: "BUY"},
+		{Name: "jump above the band", Data: testutil.NewKlineBuilder(100, 1).Flat(60).Gap(3).Flat(2).MarketData("BTCUSDT"), Action: "SELL"},
+	})
+}
+
+func TestMomentumSignals(t *testing.T) {
+	testutil.RunCases(t, func() testutil.Analyzer { return NewMomentumStrategy() }, []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(5).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "flat", Data: testutil.NewKlineBuilder(100, 1).Flat(100).MarketData("BTCUSDT"), Action: "HOLD"},
+		// Overbought without a MACD crossover is no sell signal
+		{Name: "uptrend", Data: testutil.NewKlineBuilder(100, 1).Flat(30).Trend(60, 0.5).MarketData("BTCUSDT"), Action: "HOLD"},
+	})
+}
+
+func TestVolatilityBreakoutSignals(t *testing.T) {
+	testutil.RunCases(t, func() testutil.Analyzer { return NewVolatilityBreakoutStrategy() }, []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(5).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "flat", Data: testutil.NewKlineBuilder(100, 1).Flat(30).MarketData("BTCUSDT"), Action: "HOLD"},
+		{Name: "breakout up on volume", Data: testutil.NewKlineBuilder(100, 1).Flat(30).Gap(3).Spike(0.5, 3).MarketData("BTCUSDT"), Action: "BUY"},
+		{Name: "breakout down on volume", Data: testutil.NewKlineBuilder(100, 1).Flat(30).Gap(-3).Spike(-0.5, 3).MarketData("BTCUSDT"), Action: "SELL"},
+		{Name: "breakout without volume", Data: testutil.NewKlineBuilder(100, 1).Flat(30).Gap(3).Flat(1).MarketData("BTCUSDT"), Action: "HOLD"},
+	})
+}
+
+func TestBreakoutRetestSignals(t *testing.T) {
+	// Three swings of a range form resistance near 101 and support near 99, broken by a gap
+	// that holds for ten bars before the retest
+	setup := func(gap float64) *testutil.KlineBuilder {
+		return testutil.NewKlineBuilder(100, 1).Range(60, 1, 20).Gap(gap).Flat(11)
+	}
+	testutil.RunCases(t, func() testutil.Analyzer { return NewBreakoutRetestStrategy() }, []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(30).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "range", Data: testutil.NewKlineBuilder(100, 1).Range(72, 1, 20).MarketData("BTCUSDT"), Action: "HOLD"},
+		{Name: "waiting for the retest", Data: setup(2).Flat(1).MarketData("BTCUSDT"), Action: "HOLD"},
+		{Name: "retest of resistance", Data: setup(2).Spike(-1, 3).MarketData("BTCUSDT"), Action: "BUY", TradePlan: true},
+		{Name: "retest of support", Data: setup(-2).Spike(1, 3).MarketData("BTCUSDT"), Action: "SELL", TradePlan: true},
+		{Name: "retest without volume", Data: setup(2).Spike(-1, 1).MarketData("BTCUSDT"), Action: "HOLD"},
+	})
+}
+
+func TestDCASignals(t *testing.T) {
+	testutil.RunCases(t, func() testutil.Analyzer { return NewDCAStrategy() }, []testutil.Case{
+		{Name: "scheduled buy", Data: testutil.NewKlineBuilder(100, 1).Flat(60).MarketData("BTCUSDT"), Action: "BUY"},
+	})
+	testutil.RunCases(t, func() testutil.Analyzer {
+		dca := NewDCAStrategy()
+		dca.LastBuy["BTCUSDT"] = time.Now().Add(-time.Hour)
+		return dca
+	}, []testutil.Case{
+		{Name: "before the interval", Data: testutil.NewKlineBuilder(100, 1).Flat(60).MarketData("BTCUSDT"), Action: "HOLD"},
+	})
+
+	dca := NewDCAStrategy()
+	if amount, dip := dca.BuyAmount(testutil.NewKlineBuilder(100, 1).Flat(60).MarketData("BTCUSDT")); dip || amount != 50 {
+		t.Errorf("flat market buys %v (dip %t), want 50 without a dip", amount, dip)
+	}
+	if amount, dip := dca.BuyAmount(testutil.NewKlineBuilder(100, 1).Flat(60).Gap(-8).Flat(1).MarketData("BTCUSDT")); !dip || amount != 100 {
+		t.Errorf("dip buys %v (dip %t), want 100 on a dip", amount, dip)
+	}
+}
+
+func TestEnsembleSignals(t *testing.T) {
+	ensemble := func(trendWeight, reversionWeight float64) func() testutil.Analyzer {
+		return func() testutil.Analyzer {
+			es := NewEnsembleStrategy()
+			es.AddMember(TrendFollowing, NewTrendFollowingStrategy(), trendWeight)
+			es.AddMember(MeanReversion, NewMeanReversionStrategy(), reversionWeight)
+			return es
+		}
+	}
+	gapUp := testutil.NewKlineBuilder(100, 1).Flat(60).Gap(3).Flat(1).MarketData("BTCUSDT")
+
+	testutil.RunCases(t, ensemble(1, 1), []testutil.Case{
+		{Name: "warming up", Data: testutil.NewKlineBuilder(100, 1).Flat(5).MarketData("BTCUSDT"), Action: "HOLD", NotReady: true},
+		{Name: "breakout vote carries", Data: gapUp, Action: "BUY", TradePlan: true},
+	})
+	// Mean reversion holds on the breakout bar, diluting the breakout vote below the consensus
+	testutil.RunCases(t, ensemble(1, 4), []testutil.Case{
+		{Name: "diluted by a heavier member", Data: gapUp, Action: "HOLD"},
+	})
+}
+
+func TestFundingArbitrageFinishesHalfClosedPosition(t *testing.T) {
+	fas := NewFundingArbitrageStrategy()
+	fas.Positions["BTCUSDT"] = &FundingPosition{Symbol: "BTCUSDT", Quantity: 0.01}
+	// Funding still pays, so an intact position is held
+	rate := &bybit.FundingRate{Symbol: "BTCUSDT", Rate: decimal.NewFromFloat(0.0005), MarkPrice: decimal.NewFromInt(50000)}
+
+	if signal := fas.AnalyzeFunding(rate, 50000); signal.Action != FundingHold {
+		t.Fatalf("intact position: action %s, want %s", signal.Action, FundingHold)
+	}
+
+	fas.MarkPerpClosed("BTCUSDT")
+	signal := fas.AnalyzeFunding(rate, 50000)
+	if signal.Action != FundingClose || signal.Quantity != 0.01 {
+		t.Errorf("half-closed position: action %s for %v, want %s for 0.01", signal.Action, signal.Quantity, FundingClose)
+	}
+}
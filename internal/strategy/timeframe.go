@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// TimeframeStrategy is implemented by strategies that also analyze klines of other timeframes,
+// e.g. to require alignment with the higher-timeframe trend. The bot fetches the reported
+// intervals alongside the 5 minute klines into MarketData.Timeframes.
+type TimeframeStrategy interface {
+	Timeframes() []string
+}
+
+// Timeframes returns the Bybit intervals of the additional timeframes a strategy analyzes
+func Timeframes(s Strategy) []string {
+	if multi, ok := s.(TimeframeStrategy); ok {
+		return multi.Timeframes()
+	}
+	return nil
+}
+
+// timeframeTrend compares the last close of a timeframe with its simple moving average over the
+// period. It returns 1 above the average, -1 below it, and false if there are fewer klines than
+// the period.
+func timeframeTrend(klines []bybit.KlineData, period int) (int, float64, float64, bool) {
+	if period <= 0 || len(klines) < period {
+		return 0, 0, 0, false
+	}
+
+	sum := 0.0
+	for _, kline := range klines[len(klines)-period:] {
+		close, _ := kline.Close.Float64()
+		sum += close
+	}
+	average := sum / float64(period)
+	close, _ := klines[len(klines)-1].Close.Float64()
+
+	switch {
+	case close > average:
+		return 1, close, average, true
+	case close < average:
+		return -1, close, average, true
+	default:
+		return 0, close, average, true
+	}
+}
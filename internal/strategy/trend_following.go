@@ -0,0 +1,272 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/risk"
+)
+
+// TrendSignal is a trend-following signal with the indicators behind its trade plan. The
+// embedded signal's StopLoss is the initial ATR stop, or the current trailing stop for an open trend.
+type TrendSignal struct {
+	bybit.TradeSignal
+	ATR         float64
+	ChannelHigh float64 // Highest high of the entry period before the current bar
+	ChannelLow  float64 // Lowest low of the entry period before the current bar
+}
+
+// TrendPosition is a trend being ridden by the strategy
+type TrendPosition struct {
+	EntryPrice   float64
+	InitialStop  float64
+	TrailingStop float64
+	TakeProfit   float64
+	HighestClose float64
+}
+
+// TrendFollowingStrategy enters on a breakout of the N-period Donchian channel with an ATR-based
+// initial stop and rides the trend with an ATR trailing stop and a shorter exit channel. Unlike
+// the volatility breakout strategy it breaks out of the raw channel and manages the exit. With a
+// higher timeframe configured, entries also require the higher-timeframe close to be on the
+// breakout's side of its moving average.
+type TrendFollowingStrategy struct {
+	Parameters map[string]float64
+	Positions  map[string]*TrendPosition
+	// Plans of the last signals, applied to the positions when the signal is executed
+	pending map[string]TrendSignal
+}
+
+// NewTrendFollowingStrategy creates a new TrendFollowingStrategy
+func NewTrendFollowingStrategy() *TrendFollowingStrategy {
+	return &TrendFollowingStrategy{
+		Parameters: map[string]float64{
+			"entry_period":         20, // Donchian channel breakout period
+			"exit_period":          10, // Close below the low of this period exits the trend
+			"atr_period":           14,
+			"atr_stop_multiplier":  2.0, // Initial stop distance in ATRs
+			"atr_trail_multiplier": 3.0, // Trailing stop distance from the highest close in ATRs
+			"reward_risk":          3.0, // Target distance as a multiple of the initial stop distance
+			"htf_minutes":          0,   // Higher timeframe entries must align with, 0 disables the filter
+			"htf_period":           50,  // Moving average period on the higher timeframe
+		},
+		Positions: make(map[string]*TrendPosition),
+		pending:   make(map[string]TrendSignal),
+	}
+}
+
+// GetName returns the strategy name
+func (tfs *TrendFollowingStrategy) GetName() string {
+	return string(TrendFollowing)
+}
+
+// Analyze implements the trend-following analysis logic
+func (tfs *TrendFollowingStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	return tfs.AnalyzeTrend(marketData).TradeSignal
+}
+
+// RequiredBars returns the bars needed before the Donchian channels and ATR are meaningful
+func (tfs *TrendFollowingStrategy) RequiredBars() int {
+	required := int(tfs.Parameters["entry_period"]) + 1
+	if atrPeriod := int(tfs.Parameters["atr_period"]) + 1; atrPeriod > required {
+		required = atrPeriod
+	}
+	if exitPeriod := int(tfs.Parameters["exit_period"]) + 1; exitPeriod > required {
+		required = exitPeriod
+	}
+	return required
+}
+
+// Timeframes returns the higher timeframe of the alignment filter, if it is enabled
+func (tfs *TrendFollowingStrategy) Timeframes() []string {
+	if minutes := int(tfs.Parameters["htf_minutes"]); minutes > 0 {
+		return []string{bybit.IntervalForMinutes(minutes)}
+	}
+	return nil
+}
+
+// AnalyzeTrend returns the trend signal with entry, stop and target prices
+func (tfs *TrendFollowingStrategy) AnalyzeTrend(marketData *bybit.MarketData) TrendSignal {
+	entryPeriod := int(tfs.Parameters["entry_period"])
+	exitPeriod := int(tfs.Parameters["exit_period"])
+	atrPeriod := int(tfs.Parameters["atr_period"])
+
+	if marketData == nil || len(marketData.Kline) < tfs.RequiredBars() {
+		return TrendSignal{TradeSignal: notReadySignal(marketData, tfs.RequiredBars())}
+	}
+
+	klines := marketData.Kline
+	currentClose, _ := klines[len(klines)-1].Close.Float64()
+	atr := risk.CalculateATR(klines, atrPeriod)
+
+	// Channels exclude the current bar so a close beyond them is a breakout
+	channelHigh, channelLow := donchianChannel(klines[len(klines)-1-entryPeriod : len(klines)-1])
+	_, exitLow := donchianChannel(klines[len(klines)-1-exitPeriod : len(klines)-1])
+
+	signal := TrendSignal{
+		TradeSignal: bybit.TradeSignal{Symbol: marketData.Symbol, Action: "HOLD", Strength: 0.5},
+		ATR:         atr,
+		ChannelHigh: channelHigh,
+		ChannelLow:  channelLow,
+	}
+
+	// Manage an open trend: trail the stop and exit on the stop, the exit channel or the target
+	if pos, exists := tfs.Positions[marketData.Symbol]; exists {
+		highestClose := math.Max(pos.HighestClose, currentClose)
+		trailingStop := math.Max(pos.TrailingStop, highestClose-atr*tfs.Parameters["atr_trail_multiplier"])
+		signal.StopLoss = trailingStop
+		signal.TakeProfit = pos.TakeProfit
+
+		switch {
+		case currentClose <= trailingStop:
+			signal.Action = "SELL"
+			signal.Reason = fmt.Sprintf("Trailing stop: close %.4f at or below %.4f", currentClose, trailingStop)
+		case currentClose < exitLow:
+			signal.Action = "SELL"
+			signal.Reason = fmt.Sprintf("Trend exit: close %.4f below the %d-period low %.4f", currentClose, exitPeriod, exitLow)
+		case pos.TakeProfit > 0 && currentClose >= pos.TakeProfit:
+			signal.Action = "SELL"
+			signal.Reason = fmt.Sprintf("Target reached: close %.4f at or above %.4f", currentClose, pos.TakeProfit)
+		default:
+			signal.Reason = fmt.Sprintf("Riding trend from %.4f: close %.4f, trailing stop %.4f", pos.EntryPrice, currentClose, trailingStop)
+		}
+		if signal.Action == "SELL" {
+			signal.Strength = 1.0
+		}
+
+		// The trailing stop only moves up
+		pos.HighestClose = highestClose
+		pos.TrailingStop = trailingStop
+		tfs.pending[marketData.Symbol] = signal
+		return signal
+	}
+
+	if atr <= 0 {
+		signal.Reason = "ATR unavailable"
+		return signal
+	}
+	stopDistance := atr * tfs.Parameters["atr_stop_multiplier"]
+
+	// Direction of the higher-timeframe trend entries must align with
+	htfDirection, htfReason := 0, ""
+	if minutes := int(tfs.Parameters["htf_minutes"]); minutes > 0 && (currentClose > channelHigh || currentClose < channelLow) {
+		interval := bybit.IntervalForMinutes(minutes)
+		period := int(tfs.Parameters["htf_period"])
+		htfKlines := marketData.TimeframeKlines(interval)
+		direction, htfClose, average, ok := timeframeTrend(htfKlines, period)
+		if !ok {
+			signal.TradeSignal = notReadySignal(marketData, tfs.RequiredBars())
+			signal.Reason = fmt.Sprintf("Warming up: %d of %d %dm bars", len(htfKlines), period, minutes)
+			return signal
+		}
+		htfDirection = direction
+		htfReason = fmt.Sprintf("%dm close %.4f vs %d-period average %.4f", minutes, htfClose, period, average)
+	}
+
+	switch {
+	case currentClose > channelHigh && htfReason != "" && htfDirection <= 0:
+		signal.Reason = fmt.Sprintf("Breakout above the %d-period high %.4f against the higher timeframe: %s", entryPeriod, channelHigh, htfReason)
+	case currentClose < channelLow && htfReason != "" && htfDirection >= 0:
+		signal.Reason = fmt.Sprintf("Breakdown below the %d-period low %.4f against the higher timeframe: %s", entryPeriod, channelLow, htfReason)
+	case currentClose > channelHigh:
+		signal.Action = "BUY"
+		signal.EntryPrice = currentClose
+		signal.StopLoss = currentClose - stopDistance
+		signal.TakeProfit = currentClose + stopDistance*tfs.Parameters["reward_risk"]
+		signal.Strength = math.Min((currentClose-channelHigh)/atr, 1)
+		signal.Reason = fmt.Sprintf("Breakout above the %d-period high %.4f: entry %.4f, stop %.4f, target %.4f",
+			entryPeriod, channelHigh, currentClose, signal.StopLoss, signal.TakeProfit)
+	case currentClose < channelLow:
+		signal.Action = "SELL"
+		signal.EntryPrice = currentClose
+		signal.StopLoss = currentClose + stopDistance
+		signal.TakeProfit = currentClose - stopDistance*tfs.Parameters["reward_risk"]
+		signal.Strength = math.Min((channelLow-currentClose)/atr, 1)
+		signal.Reason = fmt.Sprintf("Breakdown below the %d-period low %.4f: entry %.4f, stop %.4f, target %.4f",
+			entryPeriod, channelLow, currentClose, signal.StopLoss, signal.TakeProfit)
+	default:
+		signal.Reason = fmt.Sprintf("No breakout: close %.4f within channel [%.4f - %.4f]", currentClose, channelLow, channelHigh)
+	}
+
+	tfs.pending[marketData.Symbol] = signal
+	return signal
+}
+
+// Execute opens or closes the tracked trend for an executed signal
+func (tfs *TrendFollowingStrategy) Execute(signal bybit.TradeSignal) error {
+	plan, exists := tfs.pending[signal.Symbol]
+	delete(tfs.pending, signal.Symbol)
+
+	switch signal.Action {
+	case "BUY":
+		if exists {
+			tfs.Positions[signal.Symbol] = &TrendPosition{
+				EntryPrice:   plan.EntryPrice,
+				InitialStop:  plan.StopLoss,
+				TrailingStop: plan.StopLoss,
+				TakeProfit:   plan.TakeProfit,
+				HighestClose: plan.EntryPrice,
+			}
+		}
+	case "SELL":
+		delete(tfs.Positions, signal.Symbol)
+	default:
+		return nil // Nothing to execute
+	}
+
+	fmt.Printf("Executing trend following strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
+
+	return nil
+}
+
+// OnPositionClosed stops riding a trend whose position was closed outside the strategy,
+// e.g. by a risk stop or the maximum holding period
+func (tfs *TrendFollowingStrategy) OnPositionClosed(position ClosedPosition) {
+	delete(tfs.Positions, position.Symbol)
+	delete(tfs.pending, position.Symbol)
+}
+
+// SaveState returns the trends being ridden by the strategy
+func (tfs *TrendFollowingStrategy) SaveState() (json.RawMessage, error) {
+	return json.Marshal(tfs.Positions)
+}
+
+// LoadState restores the trends being ridden by the strategy
+func (tfs *TrendFollowingStrategy) LoadState(data json.RawMessage) error {
+	positions := make(map[string]*TrendPosition)
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+	tfs.Positions = positions
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (tfs *TrendFollowingStrategy) GetParameters() map[string]float64 {
+	return tfs.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (tfs *TrendFollowingStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(TrendFollowing, tfs.Parameters, params)
+}
+
+// donchianChannel returns the highest high and lowest low of the klines
+func donchianChannel(klines []bybit.KlineData) (float64, float64) {
+	if len(klines) == 0 {
+		return 0, 0
+	}
+
+	highest, _ := klines[0].High.Float64()
+	lowest, _ := klines[0].Low.Float64()
+	for _, kline := range klines[1:] {
+		high, _ := kline.High.Float64()
+		low, _ := kline.Low.Float64()
+		highest = math.Max(highest, high)
+		lowest = math.Min(lowest, low)
+	}
+
+	return highest, lowest
+}
@@ -0,0 +1,297 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Triangular arbitrage actions
+const (
+	TriangleExecute = "EXECUTE" // Place the three legs in order
+	TriangleHold    = "HOLD"
+)
+
+// TriangleMarket is one spot market of a triangle
+type TriangleMarket struct {
+	Symbol    string
+	BaseCoin  string
+	QuoteCoin string
+	QtyStep   float64 // Quantities are rounded down to this step, 0 for no rounding
+}
+
+// Triangle is a cycle of three spot markets connecting three coins, e.g. BTCUSDT, ETHBTC and
+// ETHUSDT connect USDT, BTC and ETH. The cycle starts and ends in the quote coin of the first market.
+type Triangle struct {
+	Name      string
+	Markets   [3]TriangleMarket
+	StartCoin string
+}
+
+// TriangleLeg is one order of a triangle, placed in ascending Order
+type TriangleLeg struct {
+	Order    int
+	Symbol   string
+	Side     string // BUY, SELL
+	Price    float64
+	Quantity float64 // Base quantity
+	FromCoin string
+	ToCoin   string
+}
+
+// TriangleSignal is the outcome of scanning a triangle in both directions
+type TriangleSignal struct {
+	Triangle      string
+	Action        string // EXECUTE, HOLD
+	Legs          []TriangleLeg
+	StartCoin     string
+	StartAmount   float64
+	EndAmount     float64
+	GrossPercent  float64 // Cycle return before fees
+	ProfitPercent float64 // Cycle return after taker fees
+	LatencyMs     float64 // Time taken to fetch the three order books
+	Reason        string
+}
+
+// TriangularArbitrageStrategy scans triangles of spot markets for cycles that return more than
+// they cost in taker fees. Both directions of a triangle are priced from the top of the order
+// books and an opportunity is only taken when its net profit clears the threshold and the
+// quotes were fetched fast enough to still be tradable.
+type TriangularArbitrageStrategy struct {
+	Parameters map[string]float64
+	Triangles  []*Triangle
+	LastSignal map[string]TriangleSignal // Latest scan result by triangle name
+}
+
+// NewTriangularArbitrageStrategy creates a new TriangularArbitrageStrategy
+func NewTriangularArbitrageStrategy() *TriangularArbitrageStrategy {
+	return &TriangularArbitrageStrategy{
+		Parameters: map[string]float64{
+			"taker_fee_percent":  0.1,  // Taker fee charged on each leg
+			"min_profit_percent": 0.05, // Minimum cycle return after fees
+			"max_latency_ms":     300,  // Quotes fetched slower than this are considered stale
+			"notional":           100,  // Amount of the start coin put through the cycle
+		},
+		LastSignal: make(map[string]TriangleSignal),
+	}
+}
+
+// GetName returns the strategy name
+func (tas *TriangularArbitrageStrategy) GetName() string {
+	return string(TriangularArbitrage)
+}
+
+// AddTriangle registers a triangle after checking that its markets form a cycle of three coins
+func (tas *TriangularArbitrageStrategy) AddTriangle(markets [3]TriangleMarket) (*Triangle, error) {
+	counts := make(map[string]int)
+	symbols := make([]string, 0, len(markets))
+	for _, market := range markets {
+		if market.BaseCoin == "" || market.QuoteCoin == "" || market.BaseCoin == market.QuoteCoin {
+			return nil, fmt.Errorf("invalid market %s", market.Symbol)
+		}
+		counts[market.BaseCoin]++
+		counts[market.QuoteCoin]++
+		symbols = append(symbols, market.Symbol)
+	}
+	name := strings.Join(symbols, "/")
+	if len(counts) != 3 {
+		return nil, fmt.Errorf("markets of triangle %s do not connect three coins", name)
+	}
+	for coin, count := range counts {
+		if count != 2 {
+			return nil, fmt.Errorf("coin %s of triangle %s is not traded in exactly two markets", coin, name)
+		}
+	}
+
+	triangle := &Triangle{
+		Name:      name,
+		Markets:   markets,
+		StartCoin: markets[0].QuoteCoin,
+	}
+	tas.Triangles = append(tas.Triangles, triangle)
+	return triangle, nil
+}
+
+// ScanTriangle prices both directions of a triangle from the order books and returns the more
+// profitable one, sized to the notional and the quantity available at the top of each book
+func (tas *TriangularArbitrageStrategy) ScanTriangle(triangle *Triangle, books map[string]*bybit.OrderBook, latency time.Duration) TriangleSignal {
+	signal := TriangleSignal{
+		Triangle:  triangle.Name,
+		Action:    TriangleHold,
+		StartCoin: triangle.StartCoin,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	defer func() { tas.LastSignal[triangle.Name] = signal }()
+
+	for _, market := range triangle.Markets {
+		book, exists := books[market.Symbol]
+		if !exists || book.BestBid() <= 0 || book.BestAsk() <= 0 {
+			signal.Reason = fmt.Sprintf("No quotes for %s", market.Symbol)
+			return signal
+		}
+	}
+
+	fee := tas.Parameters["taker_fee_percent"] / 100
+	notional := tas.Parameters["notional"]
+
+	// Only two of the six market orders are valid routes, one for each direction of the cycle
+	var best []TriangleLeg
+	var bestOrder [3]int
+	bestEnd := 0.0
+	for _, order := range [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		legs, end, ok := tas.route(triangle, order, books, notional, fee)
+		if !ok {
+			continue
+		}
+		if best == nil || end > bestEnd {
+			best, bestOrder, bestEnd = legs, order, end
+		}
+	}
+	if best == nil {
+		signal.Reason = fmt.Sprintf("Markets of %s do not form a route from %s", triangle.Name, triangle.StartCoin)
+		return signal
+	}
+
+	// Cap the amount by the quantity quoted at the top of each book
+	scale := 1.0
+	for _, leg := range best {
+		book := books[leg.Symbol]
+		available := book.Bids[0].Quantity
+		if leg.Side == "BUY" {
+			available = book.Asks[0].Quantity
+		}
+		if size, _ := available.Float64(); leg.Quantity > 0 && size < leg.Quantity {
+			scale = math.Min(scale, size/leg.Quantity)
+		}
+	}
+	signal.StartAmount = notional * scale
+	signal.Legs, signal.EndAmount, _ = tas.route(triangle, bestOrder, books, signal.StartAmount, fee)
+
+	// Gross return without fees for reporting
+	_, grossEnd, _ := tas.route(triangle, bestOrder, books, 1, 0)
+	signal.GrossPercent = (grossEnd - 1) * 100
+	if signal.StartAmount > 0 {
+		signal.ProfitPercent = (signal.EndAmount/signal.StartAmount - 1) * 100
+	}
+
+	path := make([]string, 0, len(signal.Legs))
+	for _, leg := range signal.Legs {
+		path = append(path, fmt.Sprintf("%s %s", leg.Side, leg.Symbol))
+	}
+	route := strings.Join(path, " -> ")
+
+	switch {
+	case signal.ProfitPercent < tas.Parameters["min_profit_percent"]:
+		signal.Reason = fmt.Sprintf("%s: net %.4f%% (gross %.4f%%) below %.4f%%",
+			route, signal.ProfitPercent, signal.GrossPercent, tas.Parameters["min_profit_percent"])
+	case signal.LatencyMs > tas.Parameters["max_latency_ms"]:
+		signal.Reason = fmt.Sprintf("%s: net %.4f%% but quotes took %.0fms, above %.0fms",
+			route, signal.ProfitPercent, signal.LatencyMs, tas.Parameters["max_latency_ms"])
+	default:
+		for _, leg := range signal.Legs {
+			if leg.Quantity <= 0 {
+				signal.Reason = fmt.Sprintf("%s: %s quantity rounds to zero", route, leg.Symbol)
+				return signal
+			}
+		}
+		signal.Action = TriangleExecute
+		signal.Reason = fmt.Sprintf("%s: net %.4f%% (gross %.4f%%) on %.4f %s, quotes in %.0fms",
+			route, signal.ProfitPercent, signal.GrossPercent, signal.StartAmount, signal.StartCoin, signal.LatencyMs)
+	}
+
+	return signal
+}
+
+// route converts an amount of the start coin through the markets in the given order, buying at
+// the ask or selling at the bid and paying the taker fee on the received coin. It returns the
+// legs, the final amount of the start coin and whether the order forms a valid route.
+func (tas *TriangularArbitrageStrategy) route(triangle *Triangle, order [3]int, books map[string]*bybit.OrderBook, amount, fee float64) ([]TriangleLeg, float64, bool) {
+	coin := triangle.StartCoin
+	legs := make([]TriangleLeg, 0, len(order))
+
+	for i, index := range order {
+		market := triangle.Markets[index]
+		book := books[market.Symbol]
+		leg := TriangleLeg{Order: i + 1, Symbol: market.Symbol, FromCoin: coin}
+
+		switch coin {
+		case market.QuoteCoin:
+			leg.Side = "BUY"
+			leg.Price = book.BestAsk()
+			leg.Quantity = roundDown(amount/leg.Price, market.QtyStep)
+			amount = leg.Quantity * (1 - fee)
+			coin = market.BaseCoin
+		case market.BaseCoin:
+			leg.Side = "SELL"
+			leg.Price = book.BestBid()
+			leg.Quantity = roundDown(amount, market.QtyStep)
+			amount = leg.Quantity * leg.Price * (1 - fee)
+			coin = market.QuoteCoin
+		default:
+			return nil, 0, false
+		}
+
+		leg.ToCoin = coin
+		legs = append(legs, leg)
+	}
+
+	return legs, amount, coin == triangle.StartCoin
+}
+
+// marketIndex returns the position of a symbol among the triangle's markets
+func (tas *TriangularArbitrageStrategy) marketIndex(triangle *Triangle, symbol string) int {
+	for i, market := range triangle.Markets {
+		if market.Symbol == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// Analyze reports the latest scan of the triangles that include the symbol
+func (tas *TriangularArbitrageStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
+	for _, triangle := range tas.Triangles {
+		if tas.marketIndex(triangle, marketData.Symbol) < 0 {
+			continue
+		}
+		if signal, exists := tas.LastSignal[triangle.Name]; exists {
+			return bybit.TradeSignal{
+				Symbol: marketData.Symbol,
+				Action: "HOLD",
+				Reason: signal.Reason,
+			}
+		}
+	}
+
+	return bybit.TradeSignal{
+		Symbol: marketData.Symbol,
+		Action: "HOLD",
+		Reason: "No triangle scanned for symbol",
+	}
+}
+
+// Execute is a no-op, the three legs are executed together by the bot
+func (tas *TriangularArbitrageStrategy) Execute(signal bybit.TradeSignal) error {
+	return nil
+}
+
+// GetParameters returns the strategy parameters
+func (tas *TriangularArbitrageStrategy) GetParameters() map[string]float64 {
+	return tas.Parameters
+}
+
+// SetParameters validates and applies parameter changes
+func (tas *TriangularArbitrageStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(TriangularArbitrage, tas.Parameters, params)
+}
+
+// roundDown rounds a quantity down to a multiple of step
+func roundDown(quantity, step float64) float64 {
+	if step <= 0 {
+		return quantity
+	}
+	return math.Floor(quantity/step+1e-9) * step
+}
@@ -29,12 +29,8 @@ func (vbs *VolatilityBreakoutStrategy) GetName() string {
 
 // Analyze implements the volatility breakout strategy analysis logic
 func (vbs *VolatilityBreakoutStrategy) Analyze(marketData *bybit.MarketData) bybit.TradeSignal {
-	if marketData == nil || len(marketData.Kline) == 0 {
-		return bybit.TradeSignal{
-			Symbol: marketData.Symbol,
-			Action: "HOLD",
-			Reason: "Insufficient market data",
-		}
+	if marketData == nil || len(marketData.Kline) < vbs.RequiredBars() {
+		return notReadySignal(marketData, vbs.RequiredBars())
 	}
 
 	// Calculate volatility channel
@@ -83,13 +79,22 @@ func (vbs *VolatilityBreakoutStrategy) Analyze(marketData *bybit.MarketData) byb
 	}
 }
 
-// Execute places volatility breakout trades
+// RequiredBars returns the bars needed before the volatility channel and average volume are meaningful
+func (vbs *VolatilityBreakoutStrategy) RequiredBars() int {
+	// The channel is built from the bars before the current one
+	required := int(vbs.Parameters["period"]) + 1
+	if required < 2 {
+		required = 2
+	}
+	return required
+}
+
+// Execute reports a signal whose order the bot has placed
 func (vbs *VolatilityBreakoutStrategy) Execute(signal bybit.TradeSignal) error {
 	if signal.Action == "HOLD" {
 		return nil // Nothing to execute
 	}
 
-	// In a real implementation, this would place actual buy/sell orders
 	fmt.Printf("Executing volatility breakout strategy for %s: %s (%s)\n", signal.Symbol, signal.Action, signal.Reason)
 
 	return nil
@@ -100,19 +105,25 @@ func (vbs *VolatilityBreakoutStrategy) GetParameters() map[string]float64 {
 	return vbs.Parameters
 }
 
-// calculateVolatilityChannel calculates the volatility channel (Donchian channels)
+// SetParameters validates and applies parameter changes
+func (vbs *VolatilityBreakoutStrategy) SetParameters(params map[string]float64) error {
+	return setParameters(VolatilityBreakout, vbs.Parameters, params)
+}
+
+// calculateVolatilityChannel calculates the volatility channel (Donchian channels) of the period
+// before the current bar, which a breakout closes outside of
 func (vbs *VolatilityBreakoutStrategy) calculateVolatilityChannel(marketData *bybit.MarketData) (float64, float64) {
-	if len(marketData.Kline) < int(vbs.Parameters["period"]) {
+	period := int(vbs.Parameters["period"])
+	if period < 1 || len(marketData.Kline) < period+1 {
 		return 0, 0 // Not enough data
 	}
+	last := len(marketData.Kline) - 1
 
-	period := int(vbs.Parameters["period"])
-
-	highestHigh, _ := marketData.Kline[len(marketData.Kline)-period].High.Float64()
-	lowestLow, _ := marketData.Kline[len(marketData.Kline)-period].Low.Float64()
+	highestHigh, _ := marketData.Kline[last-period].High.Float64()
+	lowestLow, _ := marketData.Kline[last-period].Low.Float64()
 
 	// Find highest high and lowest low over the period
-	for i := len(marketData.Kline) - period; i < len(marketData.Kline); i++ {
+	for i := last - period; i < last; i++ {
 		high, _ := marketData.Kline[i].High.Float64()
 		low, _ := marketData.Kline[i].Low.Float64()
 
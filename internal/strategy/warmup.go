@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// WarmUpStrategy is implemented by strategies whose indicators need a minimum number of bars.
+// Until that much history is available they return a NotReady signal instead of acting on
+// degenerate indicator values.
+type WarmUpStrategy interface {
+	RequiredBars() int
+}
+
+// RequiredBars returns the bars a strategy needs before its signals are meaningful, or 0 if
+// it does not report a warm-up period
+func RequiredBars(s Strategy) int {
+	if warmUp, ok := s.(WarmUpStrategy); ok {
+		return warmUp.RequiredBars()
+	}
+	return 0
+}
+
+// notReadySignal is the HOLD signal of a strategy that has not seen enough bars yet
+func notReadySignal(marketData *bybit.MarketData, required int) bybit.TradeSignal {
+	symbol, bars := "", 0
+	if marketData != nil {
+		symbol, bars = marketData.Symbol, len(marketData.Kline)
+	}
+	return bybit.TradeSignal{
+		Symbol:       symbol,
+		Action:       "HOLD",
+		Reason:       fmt.Sprintf("Warming up: %d of %d bars", bars, required),
+		NotReady:     true,
+		RequiredBars: required,
+	}
+}
@@ -0,0 +1,89 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+)
+
+// Analyzer is the part of a strategy exercised by the table-driven helpers
+type Analyzer interface {
+	Analyze(marketData *bybit.MarketData) bybit.TradeSignal
+}
+
+// Case is one row of a table-driven strategy test: the market data and the expected signal
+type Case struct {
+	Name        string
+	Data        *bybit.MarketData
+	Action      string  // Expected action: BUY, SELL or HOLD
+	NotReady    bool    // Whether the signal must report that the strategy is warming up
+	MinStrength float64 // Lower bound on the signal strength, 0 skips the check
+	TradePlan   bool    // Whether to check the signal's stop-loss and take-profit with AssertTradePlan
+}
+
+// RunCases analyzes each case with a fresh strategy from newStrategy and checks the signal
+func RunCases(t *testing.T, newStrategy func() Analyzer, cases []Case) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			signal := newStrategy().Analyze(tc.Data)
+			AssertAction(t, signal, tc.Action)
+			if tc.NotReady {
+				AssertNotReady(t, signal)
+			}
+			if tc.MinStrength > 0 {
+				AssertStrength(t, signal, tc.MinStrength, 1)
+			}
+			if tc.TradePlan {
+				AssertTradePlan(t, signal)
+			}
+		})
+	}
+}
+
+// AssertAction fails the test if the signal's action differs from the expected one
+func AssertAction(t testing.TB, signal bybit.TradeSignal, action string) {
+	t.Helper()
+	if signal.Action != action {
+		t.Errorf("%s: expected %s, got %s (%s)", signal.Symbol, action, signal.Action, signal.Reason)
+	}
+}
+
+// AssertNotReady fails the test unless the signal reports that the strategy is warming up
+func AssertNotReady(t testing.TB, signal bybit.TradeSignal) {
+	t.Helper()
+	if !signal.NotReady || signal.Action != "HOLD" {
+		t.Errorf("%s: expected a not-ready HOLD, got %s (not ready %t, %s)", signal.Symbol, signal.Action, signal.NotReady, signal.Reason)
+	}
+}
+
+// AssertStrength fails the test if the signal's strength is outside [min, max]
+func AssertStrength(t testing.TB, signal bybit.TradeSignal, min, max float64) {
+	t.Helper()
+	if signal.Strength < min || signal.Strength > max {
+		t.Errorf("%s: expected strength in [%g, %g], got %g (%s)", signal.Symbol, min, max, signal.Strength, signal.Reason)
+	}
+}
+
+// AssertTradePlan fails the test unless a BUY or SELL signal's stop-loss and take-profit lie on
+// the correct sides of its entry
+func AssertTradePlan(t testing.TB, signal bybit.TradeSignal) {
+	t.Helper()
+	entry := signal.EntryPrice
+	switch signal.Action {
+	case "BUY":
+		if signal.StopLoss > 0 && signal.StopLoss >= entry {
+			t.Errorf("%s: BUY stop-loss %g not below entry %g", signal.Symbol, signal.StopLoss, entry)
+		}
+		if signal.TakeProfit > 0 && signal.TakeProfit <= entry {
+			t.Errorf("%s: BUY take-profit %g not above entry %g", signal.Symbol, signal.TakeProfit, entry)
+		}
+	case "SELL":
+		if signal.StopLoss > 0 && signal.StopLoss <= entry {
+			t.Errorf("%s: SELL stop-loss %g not above entry %g", signal.Symbol, signal.StopLoss, entry)
+		}
+		if signal.TakeProfit > 0 && signal.TakeProfit >= entry {
+			t.Errorf("%s: SELL take-profit %g not below entry %g", signal.Symbol, signal.TakeProfit, entry)
+		}
+	}
+}
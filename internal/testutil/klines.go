@@ -0,0 +1,134 @@
+// Package testutil generates deterministic synthetic market data and provides assertion helpers
+// for table-driven tests of strategy logic without live data.
+package testutil
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultStart is the open time of the first bar of a series
+var DefaultStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// KlineBuilder builds a synthetic kline series segment by segment. The same seed and segments
+// always produce the same series.
+type KlineBuilder struct {
+	Interval time.Duration // Time between bars
+	Noise    float64       // Relative size of the random wicks and volume variation, e.g. 0.002
+	Volume   float64       // Base volume per bar
+
+	rng    *rand.Rand
+	price  float64 // Close of the last bar, moved by a pending gap; the next bar opens here
+	klines []bybit.KlineData
+}
+
+// NewKlineBuilder creates a builder starting at a price, with 5 minute bars and a fixed seed
+func NewKlineBuilder(price float64, seed int64) *KlineBuilder {
+	return &KlineBuilder{
+		Interval: 5 * time.Minute,
+		Noise:    0.002,
+		Volume:   1000,
+		rng:      rand.New(rand.NewSource(seed)),
+		price:    price,
+	}
+}
+
+// Flat appends bars closing at the current price
+func (b *KlineBuilder) Flat(bars int) *KlineBuilder {
+	for i := 0; i < bars; i++ {
+		b.bar(b.price, 1)
+	}
+	return b
+}
+
+// Trend appends bars whose close moves by a fixed percentage per bar, negative for a downtrend
+func (b *KlineBuilder) Trend(bars int, percentPerBar float64) *KlineBuilder {
+	for i := 0; i < bars; i++ {
+		b.bar(b.price*(1+percentPerBar/100), 1)
+	}
+	return b
+}
+
+// Range appends bars oscillating around the current price with an amplitude in percent and a
+// cycle length in bars, ending back at the starting price after whole cycles
+func (b *KlineBuilder) Range(bars int, amplitudePercent float64, cycleBars int) *KlineBuilder {
+	if cycleBars <= 0 {
+		cycleBars = 20
+	}
+	center := b.price
+	for i := 1; i <= bars; i++ {
+		phase := 2 * math.Pi * float64(i) / float64(cycleBars)
+		b.bar(center*(1+amplitudePercent/100*math.Sin(phase)), 1)
+	}
+	return b
+}
+
+// Spike appends one bar whose wick reaches the given percentage away from the current price on
+// elevated volume and closes back at the current price, negative for a downward spike
+func (b *KlineBuilder) Spike(percent, volumeMultiplier float64) *KlineBuilder {
+	open := b.open()
+	extreme := b.price * (1 + percent/100)
+	b.append(open, math.Max(open, extreme), math.Min(open, extreme), b.price, volumeMultiplier)
+	return b
+}
+
+// Gap makes the next bar open the given percentage away from the last close. Later segments
+// move from the gapped price.
+func (b *KlineBuilder) Gap(percent float64) *KlineBuilder {
+	b.price *= 1 + percent/100
+	return b
+}
+
+// Price returns the close of the last bar moved by a pending gap, or the starting price of an
+// empty series
+func (b *KlineBuilder) Price() float64 {
+	return b.price
+}
+
+// Klines returns a copy of the series built so far, oldest first
+func (b *KlineBuilder) Klines() []bybit.KlineData {
+	klines := make([]bybit.KlineData, len(b.klines))
+	copy(klines, b.klines)
+	return klines
+}
+
+// MarketData returns the series built so far as market data of a symbol, timestamped at the
+// close of the last bar
+func (b *KlineBuilder) MarketData(symbol string) *bybit.MarketData {
+	return &bybit.MarketData{
+		Symbol:    symbol,
+		Timestamp: DefaultStart.Add(time.Duration(len(b.klines)) * b.Interval),
+		Kline:     b.Klines(),
+	}
+}
+
+// open returns the open of the next bar: the last close, moved by a pending gap
+func (b *KlineBuilder) open() float64 {
+	return b.price
+}
+
+// bar appends a bar from the next open to a close with random wicks
+func (b *KlineBuilder) bar(close, volumeMultiplier float64) {
+	open := b.open()
+	high := math.Max(open, close) * (1 + b.Noise*b.rng.Float64())
+	low := math.Min(open, close) * (1 - b.Noise*b.rng.Float64())
+	b.append(open, high, low, close, volumeMultiplier)
+}
+
+// append adds a bar and makes its close the current price
+func (b *KlineBuilder) append(open, high, low, close, volumeMultiplier float64) {
+	volume := b.Volume * volumeMultiplier * (1 + b.Noise*100*(b.rng.Float64()-0.5))
+	b.klines = append(b.klines, bybit.KlineData{
+		Open:      decimal.NewFromFloat(open),
+		High:      decimal.NewFromFloat(high),
+		Low:       decimal.NewFromFloat(low),
+		Close:     decimal.NewFromFloat(close),
+		Volume:    decimal.NewFromFloat(math.Max(volume, 0)),
+		Timestamp: DefaultStart.Add(time.Duration(len(b.klines)) * b.Interval),
+	})
+	b.price = close
+}
@@ -0,0 +1,31 @@
+package testutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGapAppliesOnce(t *testing.T) {
+	cases := []struct {
+		name    string
+		builder *KlineBuilder
+		gapBar  int // Index of the bar opening at the gap
+	}{
+		{"empty series", NewKlineBuilder(100, 1).Gap(5).Flat(2), 0},
+		{"after bars", NewKlineBuilder(100, 1).Flat(3).Gap(5).Flat(2), 3},
+	}
+	for _, tc := range cases {
+		klines := tc.builder.Klines()
+		open, _ := klines[tc.gapBar].Open.Float64()
+		if math.Abs(open-105) > 1e-9 {
+			t.Errorf("%s: gap bar opens at %v, want 105", tc.name, open)
+		}
+		next, _ := klines[tc.gapBar+1].Open.Float64()
+		if close, _ := klines[tc.gapBar].Close.Float64(); next != close {
+			t.Errorf("%s: bar after the gap opens at %v, want the gap bar's close %v", tc.name, next, close)
+		}
+		if price := tc.builder.Price(); math.Abs(price-105) > 1e-9 {
+			t.Errorf("%s: price after the gap is %v, want 105", tc.name, price)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// Number of candles of a chart by default and at most
+const (
+	defaultChartCandles = 200
+	maxChartCandles     = 1000
+)
+
+// ChartCandle is an OHLCV candle of a chart
+type ChartCandle struct {
+	Timestamp time.Time `json:"timestamp"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    float64   `json:"volume"`
+}
+
+// chartHandler serves the recent candles of a symbol at an interval with indicator series
+// aligned to them and the bot's trades of the symbol since the first candle as markers.
+// Supports the query parameters symbol, interval (default the market data interval) and limit.
+func (d *Dashboard) chartHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	symbol := strings.ToUpper(query.Get("symbol"))
+	if symbol == "" {
+		http.Error(w, "Missing symbol", http.StatusBadRequest)
+		return
+	}
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = bybit.MarketDataInterval
+	}
+	if _, err := bybit.IntervalDuration(interval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := defaultChartCandles
+	if val := query.Get("limit"); val != "" {
+		var err error
+		if limit, err = strconv.Atoi(val); err != nil || limit <= 0 || limit > maxChartCandles {
+			http.Error(w, fmt.Sprintf("Invalid limit (1 to %d)", maxChartCandles), http.StatusBadRequest)
+			return
+		}
+	}
+
+	klines, from, err := d.chartKlines(r.Context(), symbol, interval, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	candles := make([]ChartCandle, 0, len(klines)-from)
+	for _, kline := range klines[from:] {
+		candle := ChartCandle{Timestamp: kline.Timestamp}
+		candle.Open, _ = kline.Open.Float64()
+		candle.High, _ = kline.High.Float64()
+		candle.Low, _ = kline.Low.Float64()
+		candle.Close, _ = kline.Close.Float64()
+		candle.Volume, _ = kline.Volume.Float64()
+		candles = append(candles, candle)
+	}
+
+	trades := make([]LiveTrade, 0)
+	if len(candles) > 0 {
+		for _, entry := range d.PortfolioManager.GetTradeLogForSymbol(symbol) {
+			if entry.Timestamp.Before(candles[0].Timestamp) || entry.Action == "HOLD" {
+				continue
+			}
+			trades = append(trades, liveTrade(entry))
+		}
+	}
+
+	response := map[string]interface{}{
+		"symbol":     symbol,
+		"interval":   interval,
+		"candles":    candles,
+		"indicators": market.CalculateChartIndicators(klines, from),
+		"trades":     trades,
+		"timestamp":  time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// chartKlines returns the last klines of a symbol at an interval after the warm-up klines of the
+// chart indicators, and the index of the first of them
+func (d *Dashboard) chartKlines(ctx context.Context, symbol, interval string, limit int) ([]bybit.KlineData, int, error) {
+	barLength, err := bybit.IntervalDuration(interval)
+	if err != nil {
+		return nil, 0, err
+	}
+	var provider bybit.DataProvider = d.PortfolioManager.BybitClient
+	if d.HistoricalData != nil {
+		provider = d.HistoricalData
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(limit+market.ChartWarmUpKlines) * barLength)
+	klines, err := provider.GetKlines(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch klines: %w", err)
+	}
+	from := 0
+	if len(klines) > limit {
+		from = len(klines) - limit
+	}
+	return klines, from, nil
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/config"
+)
+
+// configHandler serves the effective configuration with the API credentials redacted on GET,
+// and applies changes of the tunable parameters on PUT. A PUT body sets any of the tunables;
+// unknown or invalid fields reject the whole update.
+func (d *Dashboard) configHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var update config.Tunables
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&update); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := d.applyConfig(update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg := d.PortfolioManager.Config
+	response := map[string]interface{}{
+		"config":    cfg.Redacted(),
+		"tunable":   cfg.Tunables(),
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// applyConfig applies tunables to the shared configuration, which the portfolio and risk
+// managers and the risk rules read on every use, and notifies the bot
+func (d *Dashboard) applyConfig(update config.Tunables) error {
+	d.configMu.Lock()
+	defer d.configMu.Unlock()
+
+	if err := d.PortfolioManager.Config.ApplyTunables(update); err != nil {
+		return err
+	}
+
+	changes, _ := json.Marshal(update)
+	log.Printf("Configuration updated from the dashboard: %s", changes)
+	if d.OnConfigUpdate != nil {
+		d.OnConfigUpdate(update)
+	}
+	return nil
+}
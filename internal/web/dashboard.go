@@ -1,15 +1,26 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/forbest/bybitgo/internal/backtest"
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/config"
 	"github.com/forbest/bybitgo/internal/market"
+	"github.com/forbest/bybitgo/internal/optimizer"
 	"github.com/forbest/bybitgo/internal/portfolio"
 	"github.com/forbest/bybitgo/internal/risk"
+	"github.com/forbest/bybitgo/internal/strategy"
 )
 
 // Dashboard represents the web dashboard for the trading bot
@@ -17,11 +28,31 @@ type Dashboard struct {
 	PortfolioManager *portfolio.PortfolioManager
 	RiskManager      *risk.RiskManager
 	MarketAnalyzer   *market.MarketAnalyzer
-	Server           *http.Server
+	CircuitBreakers  *risk.CircuitBreakerGroup  // Optional, set by the bot
+	Calibrator       *strategy.SignalCalibrator // Optional, set by the bot
+	Shadow           *portfolio.ShadowLedger    // Optional, set by the bot
+	BacktestStore    *backtest.ResultStore      // Optional, set by the bot; without it results are kept in memory
+	HistoricalData   bybit.DataProvider         // Optional, set by the bot; default the portfolio's Bybit client
+	StrategyAI       *strategy.StrategyAI       // Optional, set by the bot
+	Logs             *LogBuffer                 // Optional, set by the bot
+	// StrategyDetails returns the strategy the bot trades a symbol with and its parameters for
+	// the symbol, false before the first selection. Optional, set by the bot.
+	StrategyDetails func(symbol string) (strategy.StrategyType, map[string]float64, bool)
+	// OnConfigUpdate is notified of the tunables changed through /api/config once applied
+	OnConfigUpdate func(update config.Tunables)
+	Server         *http.Server
 	// Add a channel for manual override commands
 	OverrideChannel chan OverrideCommand
-	// Add backtest result storage
+	// Backtest results kept in memory, by run ID; the newest maxBacktestResults are kept
 	BacktestResults map[string]*backtest.BacktestResult
+	resultOrder     []string // Run IDs of BacktestResults, oldest first
+	resultsMu       sync.Mutex
+	// Running backtest requests, by job ID
+	backtestJobs map[string]*BacktestJob
+	jobsMu       sync.Mutex
+	configMu     sync.Mutex // Serializes configuration updates
+	// Clients of /ws
+	live liveHub
 }
 
 // OverrideCommand represents a manual override command
@@ -39,6 +70,7 @@ func NewDashboard(portfolioManager *portfolio.PortfolioManager, riskManager *ris
 		MarketAnalyzer:   marketAnalyzer,
 		OverrideChannel:  make(chan OverrideCommand, 10), // Buffered channel
 		BacktestResults:  make(map[string]*backtest.BacktestResult),
+		backtestJobs:     make(map[string]*BacktestJob),
 	}
 }
 
@@ -50,12 +82,34 @@ func (d *Dashboard) Start(port string) error {
 	// Register API handlers
 	http.HandleFunc("/api/metrics", d.metricsHandler)
 	http.HandleFunc("/api/trades", d.tradesHandler)
+	http.HandleFunc("/api/trades/export", d.tradesExportHandler)
+	http.HandleFunc("/api/trades/analytics", d.tradeAnalyticsHandler)
 	http.HandleFunc("/api/performance", d.performanceHandler)
 	http.HandleFunc("/api/risk", d.riskHandler)
+	http.HandleFunc("/api/risk/stress", d.stressTestHandler)
+	http.HandleFunc("/api/risk/history", d.riskHistoryHandler)
+	http.HandleFunc("/api/risk/report", d.riskReportHandler)
+	http.HandleFunc("/api/circuit-breakers", d.circuitBreakersHandler)
+	http.HandleFunc("/api/calibration", d.calibrationHandler)
+	http.HandleFunc("/api/shadow", d.shadowHandler)
 	http.HandleFunc("/api/market", d.marketHandler)
+	http.HandleFunc("/api/chart", d.chartHandler)
+	http.HandleFunc("/api/symbol/{symbol}", d.symbolHandler)
+	http.HandleFunc("/api/strategies", d.strategiesHandler)
 	http.HandleFunc("/api/override", d.overrideHandler)
 	http.HandleFunc("/api/backtest", d.backtestHandler)
+	http.HandleFunc("/api/backtest/sweep", d.backtestSweepHandler)
+	http.HandleFunc("/api/backtest/walk-forward", d.backtestWalkForwardHandler)
+	http.HandleFunc("/api/backtest/results", d.backtestResultsHandler)
+	http.HandleFunc("/api/backtest/jobs", d.backtestJobsHandler)
+	http.HandleFunc("/api/backtest/compare", d.backtestCompareHandler)
 	http.HandleFunc("/api/portfolio", d.portfolioHandler)
+	http.HandleFunc("/api/equity", d.equityHandler)
+	http.HandleFunc("/api/pnl-report", d.pnlReportHandler)
+	http.HandleFunc("/api/config", d.configHandler)
+	http.HandleFunc("/api/logs", d.logsHandler)
+	http.HandleFunc("/ws", d.wsHandler)
+	go d.pushMetrics()
 
 	// Serve the main dashboard page
 	http.HandleFunc("/", d.dashboardHandler)
@@ -72,6 +126,7 @@ func (d *Dashboard) Start(port string) error {
 
 // Stop stops the web dashboard server
 func (d *Dashboard) Stop() error {
+	d.live.stop()
 	if d.Server != nil {
 		return d.Server.Close()
 	}
@@ -92,30 +147,166 @@ func (d *Dashboard) dashboardHandler(w http.ResponseWriter, r *http.Request) {
 
 // metricsHandler serves performance metrics as JSON
 func (d *Dashboard) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.metrics())
+}
+
+// metrics returns the performance metrics served by /api/metrics and pushed over /ws
+func (d *Dashboard) metrics() map[string]interface{} {
 	metrics := d.PortfolioManager.CalculatePerformanceMetrics()
 
+	// Undefined without losing trades, served as null
+	var profitFactor interface{}
+	if metrics.HasProfitFactor {
+		profitFactor = metrics.ProfitFactor
+	}
+
+	return map[string]interface{}{
+		"total_trades":          metrics.TotalTrades,
+		"win_rate":              metrics.WinRate,
+		"total_pnl":             metrics.TotalPnL,
+		"avg_pnl":               metrics.AveragePnL,
+		"sharpe_ratio":          metrics.SharpeRatio,
+		"sortino_ratio":         metrics.SortinoRatio,
+		"max_drawdown":          metrics.MaxDrawdown,
+		"calmar_ratio":          metrics.CalmarRatio,
+		"profit_factor":         profitFactor,
+		"expectancy":            metrics.Expectancy,
+		"longest_losing_streak": metrics.LongestLosingStreak,
+		"time_in_market":        metrics.TimeInMarket,
+		"benchmark": map[string]interface{}{
+			"symbol":            d.PortfolioManager.Config.Benchmark,
+			"return":            metrics.BenchmarkReturn,
+			"alpha":             metrics.Alpha,
+			"tracking_error":    metrics.TrackingError,
+			"relative_drawdown": metrics.RelativeDrawdown,
+		},
+		"timestamp": time.Now().Unix(),
+	}
+}
+
+// tradesHandler serves trades as JSON, newest first. Supports the query parameters
+// symbol, strategy, action, from, to (RFC3339 or unix seconds), min_pnl, offset and limit.
+func (d *Dashboard) tradesHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTradeFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := d.PortfolioManager.QueryTrades(filter)
+
 	response := map[string]interface{}{
-		"total_trades":  metrics.TotalTrades,
-		"win_rate":      metrics.WinRate,
-		"total_pnl":     metrics.TotalPnL,
-		"avg_pnl":       metrics.AveragePnL,
-		"sharpe_ratio":  metrics.SharpeRatio,
-		"sortino_ratio": metrics.SortinoRatio,
-		"max_drawdown":  metrics.MaxDrawdown,
-		"timestamp":     time.Now().Unix(),
+		"trades":    result.Trades,
+		"count":     len(result.Trades),
+		"total":     result.Total,
+		"offset":    result.Offset,
+		"limit":     result.Limit,
+		"timestamp": time.Now().Unix(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// tradesHandler serves recent trades as JSON
-func (d *Dashboard) tradesHandler(w http.ResponseWriter, r *http.Request) {
-	trades := d.PortfolioManager.GetRecentTrades(50)
+// maxTradesPageSize caps the number of trades returned by a single request
+const maxTradesPageSize = 500
+
+// maxBacktestResults is the number of backtest runs kept in memory, older runs are evicted
+const maxBacktestResults = 50
+
+// parseTradeFilter builds a trade filter from URL query parameters
+func parseTradeFilter(query url.Values) (portfolio.TradeFilter, error) {
+	filter := portfolio.TradeFilter{
+		Symbol:   strings.ToUpper(query.Get("symbol")),
+		Strategy: query.Get("strategy"),
+		Action:   strings.ToUpper(query.Get("action")),
+		Limit:    portfolio.DefaultTradeQueryLimit,
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+
+	if val := query.Get("min_pnl"); val != "" {
+		minPnL, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid min_pnl: %w", err)
+		}
+		filter.MinPnL = &minPnL
+	}
+
+	if val := query.Get("offset"); val != "" {
+		if filter.Offset, err = strconv.Atoi(val); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("invalid offset %q", val)
+		}
+	}
+
+	if val := query.Get("limit"); val != "" {
+		if filter.Limit, err = strconv.Atoi(val); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", val)
+		}
+		if filter.Limit > maxTradesPageSize {
+			filter.Limit = maxTradesPageSize
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam parses an RFC3339 timestamp or unix seconds. Empty values yield the zero time.
+func parseTimeParam(val string) (time.Time, error) {
+	if val == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, val)
+}
+
+// parseDurationParam parses a duration query parameter given as a Go duration (e.g. 15m),
+// whole days (e.g. 1d) or seconds, zero if empty
+func parseDurationParam(val string) (time.Duration, error) {
+	if val == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(val)
+	if seconds, parseErr := strconv.ParseInt(val, 10, 64); parseErr == nil {
+		duration, err = time.Duration(seconds)*time.Second, nil
+	} else if days, parseErr := strconv.Atoi(strings.TrimSuffix(val, "d")); parseErr == nil && strings.HasSuffix(val, "d") {
+		duration, err = time.Duration(days)*24*time.Hour, nil
+	}
+	if err == nil && duration < 0 {
+		return 0, fmt.Errorf("negative duration %s", val)
+	}
+	return duration, err
+}
+
+// tradeAnalyticsHandler serves the distributions of the excursions and holding times of the
+// positions closed by the bot, optionally of one symbol or strategy
+func (d *Dashboard) tradeAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	strategyName := r.URL.Query().Get("strategy")
+
+	var excursions []backtest.TradeExcursion
+	for _, trade := range d.PortfolioManager.GetTradeLog() {
+		if trade.Action != "SELL" && trade.Action != "COVER" || trade.MAE == 0 && trade.MFE == 0 && trade.Held == 0 {
+			continue // Not a close with recorded excursions
+		}
+		if symbol != "" && trade.Symbol != symbol || strategyName != "" && trade.Strategy != strategyName {
+			continue
+		}
+		excursions = append(excursions, backtest.TradeExcursion{MAE: trade.MAE, MFE: trade.MFE, Holding: trade.Held, PnL: trade.PnL})
+	}
 
 	response := map[string]interface{}{
-		"trades":    trades,
-		"count":     len(trades),
+		"trades":    len(excursions),
+		"analytics": backtest.AnalyzeTrades(excursions),
 		"timestamp": time.Now().Unix(),
 	}
 
@@ -123,6 +314,34 @@ func (d *Dashboard) tradesHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// tradesExportHandler serves the full trade log as a downloadable CSV or JSON file
+func (d *Dashboard) tradesExportHandler(w http.ResponseWriter, r *http.Request) {
+	formatParam := r.URL.Query().Get("format")
+	if formatParam == "" {
+		formatParam = string(portfolio.ExportFormatCSV)
+	}
+
+	format, err := portfolio.ParseExportFormat(formatParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType := "text/csv"
+	if format == portfolio.ExportFormatJSON {
+		contentType = "application/json"
+	}
+
+	filename := fmt.Sprintf("trades-%s.%s", time.Now().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := d.PortfolioManager.ExportTrades(w, format); err != nil {
+		http.Error(w, "Failed to export trades: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // performanceHandler serves performance data as JSON
 func (d *Dashboard) performanceHandler(w http.ResponseWriter, r *http.Request) {
 	allocations := make(map[string]float64)
@@ -133,6 +352,7 @@ func (d *Dashboard) performanceHandler(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"allocations": allocations,
 		"performance": d.PortfolioManager.Performance,
+		"strategies":  d.PortfolioManager.GetAllStrategyPerformanceMetrics(),
 		"timestamp":   time.Now().Unix(),
 	}
 
@@ -149,7 +369,151 @@ func (d *Dashboard) riskHandler(w http.ResponseWriter, r *http.Request) {
 		"portfolio_drawdown": metrics.PortfolioDrawdown,
 		"volatility":         metrics.Volatility,
 		"correlation_risk":   metrics.CorrelationRisk,
-		"timestamp":          time.Now().Unix(),
+		"concentration_hhi":  metrics.ConcentrationHHI,
+		"volatility_target":  d.RiskManager.VolatilityTarget,
+		"halt":               d.RiskManager.HaltState,
+		"violations":         d.RiskManager.EvaluateRules(),
+		"loss_streak_pauses": d.RiskManager.LossStreakPauses,
+		"strategy_cooldowns": d.RiskManager.Cooldowns,
+		"liquidations":       d.RiskManager.Liquidations,
+		"capital_buckets":    d.RiskManager.GetCapitalBuckets(),
+		"var": map[string]interface{}{
+			"parametric":   metrics.VaR.Parametric,
+			"historical":   metrics.VaR.Historical,
+			"observations": metrics.VaR.Observations,
+			"limit":        d.RiskManager.Config.MaxVaRPercent / 100 * d.RiskManager.Config.TotalCapital,
+			"confidence":   d.RiskManager.Config.VaRConfidence,
+		},
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// riskReportHandler serves the structured risk report with limit utilization and violations as JSON
+func (d *Dashboard) riskReportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.RiskManager.GetRiskReportStruct())
+}
+
+// riskHistoryHandler serves risk metrics snapshots as JSON, optionally limited to a time range
+func (d *Dashboard) riskHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := parseTimeParam(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid from: %v", err), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid to: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	history := d.RiskManager.GetRiskHistory(from, to)
+
+	response := map[string]interface{}{
+		"history":   history,
+		"count":     len(history),
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CircuitBreakerCommand is a request to reset or reconfigure circuit breakers from the dashboard
+type CircuitBreakerCommand struct {
+	Action   string                      `json:"action"` // reset, configure
+	Name     string                      `json:"name"`   // Empty applies to all breakers
+	Settings risk.CircuitBreakerSettings `json:"settings"`
+}
+
+// circuitBreakersHandler serves the state and counters of the per-endpoint circuit breakers as JSON.
+// POST requests reset or reconfigure breakers.
+func (d *Dashboard) circuitBreakersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if d.CircuitBreakers == nil {
+			http.Error(w, "Circuit breakers not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		var command CircuitBreakerCommand
+		if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		switch command.Action {
+		case "reset":
+			err = d.CircuitBreakers.Reset(command.Name)
+		case "configure":
+			err = d.CircuitBreakers.Configure(command.Name, command.Settings)
+		default:
+			err = fmt.Errorf("unknown action %q", command.Action)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	breakers := make([]risk.CircuitBreakerStats, 0)
+	if d.CircuitBreakers != nil {
+		breakers = d.CircuitBreakers.Stats()
+	}
+
+	response := map[string]interface{}{
+		"circuit_breakers": breakers,
+		"timestamp":        time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// shadowHandler serves the hypothetical performance and recent fills of the shadow strategies as JSON
+func (d *Dashboard) shadowHandler(w http.ResponseWriter, r *http.Request) {
+	if d.Shadow == nil {
+		http.Error(w, "No strategy runs in shadow mode", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := map[string]interface{}{
+		"strategies": d.Shadow.GetSummaries(d.PortfolioManager.LastPrices),
+		"fills":      d.Shadow.GetFills(100),
+		"timestamp":  time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// calibrationHandler serves the signal calibration mapping of every strategy as JSON
+func (d *Dashboard) calibrationHandler(w http.ResponseWriter, r *http.Request) {
+	if d.Calibrator == nil {
+		http.Error(w, "Signal calibration not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := map[string]interface{}{
+		"calibration": d.Calibrator.Mapping(),
+		"timestamp":   time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// stressTestHandler serves hypothetical losses of the current positions under shock scenarios as JSON
+func (d *Dashboard) stressTestHandler(w http.ResponseWriter, r *http.Request) {
+	results := d.RiskManager.RunStressTest(risk.DefaultStressScenarios())
+
+	response := map[string]interface{}{
+		"scenarios": results,
+		"capital":   d.RiskManager.Config.TotalCapital,
+		"timestamp": time.Now().Unix(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -201,6 +565,10 @@ func (d *Dashboard) portfolioHandler(w http.ResponseWriter, r *http.Request) {
 		"performance":   performance,
 		"trade_log":     tradeLog,
 		"total_capital": d.PortfolioManager.Config.TotalCapital,
+		"currency":      d.PortfolioManager.Config.ReportingCurrency,
+		"cash_reserve":  d.PortfolioManager.GetCashReserve(),
+		"trade_budget":  d.PortfolioManager.GetTradeBudget(time.Now()),
+		"quotes":        d.PortfolioManager.QuoteCurrencies,
 		"timestamp":     time.Now().Unix(),
 	}
 
@@ -208,6 +576,83 @@ func (d *Dashboard) portfolioHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// equityHandler serves the mark-to-market equity curve with drawdowns as JSON, optionally between
+// from and to and downsampled to a resolution or to at most max_points samples
+func (d *Dashboard) equityHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	resolution, err := parseDurationParam(query.Get("resolution"))
+	if err != nil {
+		http.Error(w, "Invalid resolution: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	curve := portfolio.SampleEquity(d.PortfolioManager.GetEquityCurve(), from, to, 0)
+	total := len(curve)
+	// Coarsen the resolution until the curve fits in max_points
+	if val := query.Get("max_points"); val != "" {
+		maxPoints, err := strconv.Atoi(val)
+		if err != nil || maxPoints < 2 {
+			http.Error(w, "Invalid max_points", http.StatusBadRequest)
+			return
+		}
+		if total > maxPoints {
+			span := curve[total-1].Timestamp.Sub(curve[0].Timestamp)
+			resolution = time.Duration(math.Max(float64(resolution), math.Ceil(float64(span)/float64(maxPoints-1))))
+		}
+	}
+	if resolution > 0 {
+		curve = portfolio.SampleEquity(d.PortfolioManager.GetEquityCurve(), from, to, resolution)
+	}
+
+	maxDrawdown := 0.0
+	for _, sample := range curve {
+		maxDrawdown = math.Max(maxDrawdown, sample.Drawdown)
+	}
+
+	response := map[string]interface{}{
+		"equity_curve":       curve,
+		"count":              len(curve),
+		"total":              total,
+		"resolution_seconds": resolution.Seconds(),
+		"max_drawdown":       maxDrawdown,
+		"timestamp":          time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// pnlReportHandler serves the realized/unrealized PnL report as JSON or as a tax-lot CSV download
+func (d *Dashboard) pnlReportHandler(w http.ResponseWriter, r *http.Request) {
+	// Open lots are valued at the last known prices
+	report := d.PortfolioManager.GeneratePnLReport(nil)
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	case "csv":
+		filename := fmt.Sprintf("tax-lots-%s.csv", time.Now().Format("20060102-150405"))
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		if err := portfolio.ExportPnLReportCSV(w, report); err != nil {
+			http.Error(w, "Failed to export PnL report: "+err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		http.Error(w, "Unsupported format", http.StatusBadRequest)
+	}
+}
+
 // Add overrideHandler to handle manual override commands
 func (d *Dashboard) overrideHandler(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
@@ -252,6 +697,170 @@ func (d *Dashboard) GetOverrideChannel() <-chan OverrideCommand {
 	return d.OverrideChannel
 }
 
+// backtestRequest holds the parameters shared by the backtest endpoints
+type backtestRequest struct {
+	Strategy       string   `json:"strategy"`
+	InitialCapital float64  `json:"initial_capital"`
+	StartDate      string   `json:"start_date"`
+	EndDate        string   `json:"end_date"`
+	Symbols        []string `json:"symbols"`     // Default: the portfolio's symbols
+	Interval       string   `json:"interval"`    // Bybit kline interval, default 5 minutes
+	DataSource     string   `json:"data_source"` // "bybit" or "csv" for the files in BACKTEST_DATA_DIR, default HISTORICAL_DATA_SOURCE
+	// Optional overrides of the configured backtest costs
+	SlippageModel   string   `json:"slippage_model"`
+	SlippageBps     *float64 `json:"slippage_bps"`
+	MakerFeePercent *float64 `json:"maker_fee_percent"`
+	TakerFeePercent *float64 `json:"taker_fee_percent"`
+	// Price path within bars for limit order fills, refined by klines of a lower Bybit interval
+	IntrabarPath   string `json:"intrabar_path"` // Default BACKTEST_INTRABAR_PATH
+	RefineInterval string `json:"refine_interval"`
+	RiskExits      *bool  `json:"risk_exits"` // Default BACKTEST_RISK_EXITS
+	Sizing         string `json:"sizing"`     // Position sizing mode, default BACKTEST_SIZING
+	Funding        string `json:"funding"`    // Funding source of perpetual positions, default BACKTEST_FUNDING
+	Seed           *int64 `json:"seed"`       // Seeds the strategy, default 0, and the Monte Carlo resampling, default time based
+	JobID          string `json:"job_id"`     // Follows and cancels the request at /api/backtest/jobs, default a new ID
+}
+
+// newBacktester validates a backtest request and returns a backtester of its strategy on
+// klines of the requested data source, with the configured costs and the request's overrides,
+// reporting its progress to the job. On failure it also returns the HTTP status to answer with.
+func (d *Dashboard) newBacktester(ctx context.Context, job *BacktestJob, params backtestRequest) (*backtest.Backtester, time.Time, time.Time, int, error) {
+	var startDate, endDate time.Time
+
+	// Parse dates
+	startDate, err := time.Parse("2006-01-02", params.StartDate)
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, fmt.Errorf("Invalid start date")
+	}
+
+	endDate, err = time.Parse("2006-01-02", params.EndDate)
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, fmt.Errorf("Invalid end date")
+	}
+
+	if !endDate.After(startDate) {
+		return nil, startDate, endDate, http.StatusBadRequest, fmt.Errorf("End date must be after start date")
+	}
+	if params.InitialCapital <= 0 {
+		return nil, startDate, endDate, http.StatusBadRequest, fmt.Errorf("Initial capital must be positive")
+	}
+
+	strat, err := strategy.NewStrategy(strategy.StrategyType(params.Strategy))
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+
+	// Configured costs, with the request's overrides
+	cfg := d.PortfolioManager.Config.Snapshot()
+	// The selector chooses among the same strategies as the live selection
+	if err := backtest.ConfigureSelector(strat, &cfg); err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+	if params.SlippageModel != "" {
+		cfg.BacktestSlippageModel = params.SlippageModel
+	}
+	if params.SlippageBps != nil {
+		cfg.BacktestSlippageBps = *params.SlippageBps
+	}
+	if params.MakerFeePercent != nil {
+		cfg.BacktestMakerFeePercent = *params.MakerFeePercent
+	}
+	if params.TakerFeePercent != nil {
+		cfg.BacktestTakerFeePercent = *params.TakerFeePercent
+	}
+	costs, err := backtest.CostsFromConfig(&cfg)
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+	intrabar := backtest.Intrabar{Path: cfg.BacktestIntrabarPath}
+	if params.IntrabarPath != "" {
+		intrabar.Path = params.IntrabarPath
+	}
+	if err := backtest.ValidIntrabarPath(intrabar.Path); err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+	if params.Sizing != "" {
+		cfg.BacktestSizing = strings.ToLower(params.Sizing)
+	}
+	sizing, err := backtest.SizingFromConfig(&cfg)
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+	if params.Funding != "" {
+		cfg.BacktestFunding = strings.ToLower(params.Funding)
+	}
+	if err := backtest.ValidFundingSource(cfg.BacktestFunding); err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+
+	// Backtest the requested symbols, or the portfolio's, on klines of the data source
+	symbols := params.Symbols
+	if len(symbols) == 0 {
+		symbols = d.PortfolioManager.Symbols
+	}
+	if len(symbols) == 0 {
+		return nil, startDate, endDate, http.StatusBadRequest, fmt.Errorf("No symbols to backtest")
+	}
+	source := params.DataSource
+	if source == "" {
+		source = cfg.HistoricalDataSource
+	}
+	var bybitData bybit.DataProvider = d.PortfolioManager.BybitClient
+	if d.HistoricalData != nil {
+		bybitData = d.HistoricalData
+	}
+	provider, err := backtest.NewDataProvider(source, &cfg, bybitData)
+	if err != nil {
+		return nil, startDate, endDate, http.StatusBadRequest, err
+	}
+	// Local files that cannot be read are the request's fault, failed downloads are not
+	status := http.StatusBadGateway
+	if source == backtest.DataSourceCSV {
+		status = http.StatusBadRequest
+	}
+
+	interval := params.Interval
+	if interval == "" {
+		interval = bybit.MarketDataInterval
+	}
+	data, err := backtest.FetchHistoricalData(ctx, provider, symbols, interval, startDate, endDate)
+	if err != nil {
+		return nil, startDate, endDate, status, fmt.Errorf("Failed to load historical data: %w", err)
+	}
+	if params.RefineInterval != "" {
+		intrabar.Klines, err = backtest.FetchHistoricalData(ctx, provider, symbols, params.RefineInterval, startDate, endDate)
+		if err != nil {
+			return nil, startDate, endDate, status, fmt.Errorf("Failed to load refinement data: %w", err)
+		}
+	}
+
+	backtester := backtest.NewBacktester(strat, data)
+	backtester.Costs = costs
+	backtester.Intrabar = intrabar
+	if params.RiskExits != nil {
+		cfg.BacktestRiskExits = *params.RiskExits
+	}
+	backtester.Exits = backtest.ExitsFromConfig(&cfg)
+	backtester.Sizing = sizing
+	backtester.Workers = cfg.BacktestWorkers
+
+	// Positions held as perpetuals pay the funding of the requested source
+	backtester.Funding, err = backtest.LoadFunding(ctx, cfg.BacktestFunding, &cfg, d.PortfolioManager.BybitClient, symbols, startDate, endDate)
+	if err != nil {
+		status := http.StatusBadGateway
+		if cfg.BacktestFunding == backtest.FundingCSV {
+			status = http.StatusBadRequest
+		}
+		return nil, startDate, endDate, status, err
+	}
+	if params.Seed != nil {
+		backtester.Seed = *params.Seed
+	}
+	backtester.Context = ctx
+	backtester.Progress = d.jobProgress(job)
+	return backtester, startDate, endDate, http.StatusOK, nil
+}
+
 // Add backtestHandler to handle backtest requests
 func (d *Dashboard) backtestHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -261,10 +870,12 @@ func (d *Dashboard) backtestHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Parse the backtest parameters from the request body
 	var params struct {
-		Strategy       string  `json:"strategy"`
-		InitialCapital float64 `json:"initial_capital"`
-		StartDate      string  `json:"start_date"`
-		EndDate        string  `json:"end_date"`
+		backtestRequest
+		// Monte Carlo resampling of the trades, 0 simulations disable it
+		MonteCarloRuns *int     `json:"monte_carlo_runs"` // Default 1000
+		RuinPercent    *float64 `json:"ruin_percent"`     // Default 50
+		// Percent of the period backtested again out of sample, 0 disables the split
+		OOSPercent *float64 `json:"oos_percent"` // Default BACKTEST_OOS_PERCENT
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
@@ -272,59 +883,77 @@ func (d *Dashboard) backtestHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", params.StartDate)
+	job, ctx, err := d.startBacktestJob(r, params.JobID, "backtest")
 	if err != nil {
-		http.Error(w, "Invalid start date", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
+	defer d.finishBacktestJob(job)
 
-	endDate, err := time.Parse("2006-01-02", params.EndDate)
+	backtester, startDate, endDate, status, err := d.newBacktester(ctx, job, params.backtestRequest)
 	if err != nil {
-		http.Error(w, "Invalid end date", http.StatusBadRequest)
-		return
-	}
-
-	// Create a backtest result (simplified)
-	result := &backtest.BacktestResult{
-		StrategyName:   params.Strategy,
-		StartDate:      startDate,
-		EndDate:        endDate,
-		InitialCapital: params.InitialCapital,
-		FinalCapital:   params.InitialCapital * 1.15, // Sample 15% return
-		TotalReturn:    15.0,
-		TotalTrades:    50,
-		WinningTrades:  30,
-		LosingTrades:   20,
-		WinRate:        60.0,
-		MaxDrawdown:    5.0,
-		SharpeRatio:    1.5,
-		SortinoRatio:   2.1,
-		TradeHistory: []backtest.TradeRecord{
-			{
-				Timestamp:  startDate.Add(24 * time.Hour),
-				Symbol:     "BTCUSDT",
-				Action:     "BUY",
-				Quantity:   0.1,
-				EntryPrice: 50000.0,
-				ExitPrice:  51000.0,
-				PnL:        1000.0,
-				Commission: 10.0,
-			},
-		},
-		EquityCurve: []backtest.EquityPoint{
-			{Timestamp: startDate, Equity: params.InitialCapital},
-			{Timestamp: startDate.Add(24 * time.Hour), Equity: params.InitialCapital * 1.05},
-			{Timestamp: startDate.Add(48 * time.Hour), Equity: params.InitialCapital * 1.10},
-			{Timestamp: endDate, Equity: params.InitialCapital * 1.15},
-		},
+		backtestFailed(w, ctx, err, status)
+		return
+	}
+	result := backtester.Run(params.InitialCapital, startDate, endDate)
+	if result.Cancelled {
+		backtestFailed(w, ctx, nil, statusCancelled)
+		return
+	}
+	result.StrategyName = params.Strategy
+	result.Fingerprint = backtester.Fingerprint(params.InitialCapital, startDate, endDate)
+
+	// Compare a backtest of the strategy selection with each of its strategies on its own
+	if result.Selection != nil {
+		d.jobStage(job, "selection")
+		if err := backtester.CompareSelection(result, params.InitialCapital, startDate, endDate); err != nil {
+			backtestFailed(w, ctx, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// In-sample and out-of-sample parts of the period backtested separately
+	oosPercent := d.PortfolioManager.Config.BacktestOOSPercent
+	if params.OOSPercent != nil {
+		oosPercent = *params.OOSPercent
+	}
+	if oosPercent != 0 {
+		d.jobStage(job, "split")
+		result.Split, err = backtester.Split(params.InitialCapital, startDate, endDate, oosPercent, d.PortfolioManager.Config.BacktestOverfitRatio)
+		if err != nil {
+			backtestFailed(w, ctx, err, http.StatusBadRequest)
+			return
+		}
 	}
 
-	// Store the result
-	d.BacktestResults[params.Strategy] = result
+	// Confidence intervals from resampled trade sequences
+	runs, ruinPercent, seed := 1000, 50.0, time.Now().UnixNano()
+	if params.MonteCarloRuns != nil {
+		runs = *params.MonteCarloRuns
+	}
+	if params.RuinPercent != nil {
+		ruinPercent = *params.RuinPercent
+	}
+	if params.Seed != nil {
+		seed = *params.Seed
+	}
+	if runs > 0 {
+		result.MonteCarlo = backtest.MonteCarlo(result, runs, ruinPercent, seed)
+	}
+
+	// Store the result under a new run ID, on disk when results are persisted
+	result.RunID = backtest.NewRunID(params.Strategy)
+	if d.BacktestStore == nil {
+		d.keepBacktestResult(result)
+	} else if err := d.BacktestStore.Save(result); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		d.keepBacktestResult(result)
+	}
 
 	// Convert to JSON response
 	response := map[string]interface{}{
+		"run_id":          result.RunID,
+		"job_id":          job.ID,
 		"strategy_name":   result.StrategyName,
 		"start_date":      result.StartDate.Format("2006-01-02"),
 		"end_date":        result.EndDate.Format("2006-01-02"),
@@ -338,10 +967,315 @@ func (d *Dashboard) backtestHandler(w http.ResponseWriter, r *http.Request) {
 		"max_drawdown":    result.MaxDrawdown,
 		"sharpe_ratio":    result.SharpeRatio,
 		"sortino_ratio":   result.SortinoRatio,
+		"cagr":            result.CAGR,
+		"calmar_ratio":    result.CalmarRatio,
 		"trade_history":   result.TradeHistory,
 		"equity_curve":    result.EquityCurve,
+		"monte_carlo":     result.MonteCarlo,
+		"benchmark":       result.Benchmark,
+		"selection":       result.Selection,
+		"split":           result.Split,
+		"trade_analytics": result.TradeAnalytics,
+		// Drawdown durations in days and time in market in percent
+		"max_drawdown_days": result.MaxDrawdownDays,
+		"avg_drawdown_days": result.AvgDrawdownDays,
+		"exposure_percent":  result.ExposurePercent,
+		// Reproducibility of the run
+		"seed":         result.Seed,
+		"code_version": result.CodeVersion,
+		"fingerprint":  result.Fingerprint,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// backtestSweepHandler backtests a strategy across a matrix of parameter values and returns
+// the comparison table, one row of metrics per parameter set
+func (d *Dashboard) backtestSweepHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params struct {
+		backtestRequest
+		Grid       string                 `json:"grid"`       // Like "rsi_period=10:20:2;rsi_overbought=65,70,75"
+		Parameters backtest.ParameterGrid `json:"parameters"` // Alternative to grid, name -> values
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	grid, err := requestGrid(params.Grid, params.Parameters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job, ctx, err := d.startBacktestJob(r, params.JobID, "sweep")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	defer d.finishBacktestJob(job)
+
+	backtester, startDate, endDate, status, err := d.newBacktester(ctx, job, params.backtestRequest)
+	if err != nil {
+		backtestFailed(w, ctx, err, status)
+		return
+	}
+	sweep, err := backtester.Sweep(grid, params.InitialCapital, startDate, endDate)
+	if err != nil {
+		backtestFailed(w, ctx, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sweep)
+}
+
+// requestGrid returns the parameter grid of a request, given as a grid spec or as a map
+func requestGrid(spec string, parameters backtest.ParameterGrid) (backtest.ParameterGrid, error) {
+	grid := parameters
+	if spec != "" {
+		parsed, err := optimizer.ParseGrid(spec)
+		if err != nil {
+			return nil, err
+		}
+		grid = parsed
+	}
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("Parameter grid is empty")
+	}
+	if combinations := len(grid.Combinations()); combinations > backtest.MaxSweepCombinations {
+		return nil, fmt.Errorf("Grid has %d combinations, more than %d", combinations, backtest.MaxSweepCombinations)
+	}
+	return grid, nil
+}
+
+// backtestWalkForwardHandler runs a walk-forward analysis: the strategy is re-optimized over a
+// parameter grid on rolling training windows and traded on the test windows that follow them
+func (d *Dashboard) backtestWalkForwardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params struct {
+		backtestRequest
+		Grid       string                 `json:"grid"`
+		Parameters backtest.ParameterGrid `json:"parameters"`
+		TrainDays  float64                `json:"train_days"` // Default 30
+		TestDays   float64                `json:"test_days"`  // Default 7
+		StepDays   float64                `json:"step_days"`  // Default test_days
+		Anchored   bool                   `json:"anchored"`
+		Objective  string                 `json:"objective"` // sharpe, calmar or pnl, default sharpe
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	grid, err := requestGrid(params.Grid, params.Parameters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.TrainDays == 0 {
+		params.TrainDays = 30
+	}
+	if params.TestDays == 0 {
+		params.TestDays = 7
+	}
+	if params.Objective == "" {
+		params.Objective = optimizer.ObjectiveSharpe
+	}
+	objective, err := optimizer.ObjectiveFor(params.Objective)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	day := 24 * time.Hour
+	walkForward := backtest.WalkForward{
+		Grid:      grid,
+		Train:     time.Duration(params.TrainDays * float64(day)),
+		Test:      time.Duration(params.TestDays * float64(day)),
+		Step:      time.Duration(params.StepDays * float64(day)),
+		Anchored:  params.Anchored,
+		Objective: objective,
+	}
+
+	job, ctx, err := d.startBacktestJob(r, params.JobID, "walk-forward")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	defer d.finishBacktestJob(job)
+
+	backtester, startDate, endDate, status, err := d.newBacktester(ctx, job, params.backtestRequest)
+	if err != nil {
+		backtestFailed(w, ctx, err, status)
+		return
+	}
+	result, err := backtester.WalkForward(walkForward, params.InitialCapital, startDate, endDate)
+	if err != nil {
+		backtestFailed(w, ctx, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// backtestResultsHandler lists the kept backtest runs, serves or exports one run by ID (format
+// json, csv for the trades or equity_csv for the equity curve) and deletes runs
+func (d *Dashboard) backtestResultsHandler(w http.ResponseWriter, r *http.Request) {
+	runID := r.URL.Query().Get("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		if runID == "" {
+			summaries := []backtest.RunSummary{}
+			if d.BacktestStore != nil {
+				stored, err := d.BacktestStore.List()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				summaries = append(summaries, stored...)
+			}
+			d.resultsMu.Lock()
+			for i := len(d.resultOrder) - 1; i >= 0; i-- {
+				result := d.BacktestResults[d.resultOrder[i]]
+				summaries = append(summaries, result.Summary(time.Time{})) // Kept in memory only, never saved
+			}
+			d.resultsMu.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(summaries)
+			return
+		}
+
+		result, err := d.backtestResult(runID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if result == nil {
+			http.Error(w, "Backtest not found", http.StatusNotFound)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = backtest.ExportJSON
+		}
+		contentType, extension := "text/csv", "csv"
+		switch format {
+		case backtest.ExportJSON:
+			contentType, extension = "application/json", "json"
+		case backtest.ExportTradesCSV, backtest.ExportEquityCSV:
+		default:
+			http.Error(w, fmt.Sprintf("Unsupported export format %q", format), http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("format") != "" {
+			filename := fmt.Sprintf("backtest-%s-%s.%s", runID, format, extension)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		}
+		w.Header().Set("Content-Type", contentType)
+		if err := result.Export(w, format); err != nil {
+			http.Error(w, "Failed to export backtest: "+err.Error(), http.StatusInternalServerError)
+		}
+	case http.MethodDelete:
+		if runID == "" {
+			http.Error(w, "Missing run ID", http.StatusBadRequest)
+			return
+		}
+		d.forgetBacktestResult(runID)
+		if d.BacktestStore != nil {
+			if err := d.BacktestStore.Delete(runID); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// backtestCompareHandler compares kept backtest runs, given as comma-separated IDs with the
+// baseline first
+func (d *Dashboard) backtestCompareHandler(w http.ResponseWriter, r *http.Request) {
+	var results []*backtest.BacktestResult
+	for _, runID := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if runID = strings.TrimSpace(runID); runID == "" {
+			continue
+		}
+		result, err := d.backtestResult(runID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if result == nil {
+			http.Error(w, fmt.Sprintf("Backtest %s not found", runID), http.StatusNotFound)
+			return
+		}
+		results = append(results, result)
+	}
+
+	comparison, err := backtest.Compare(results...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// keepBacktestResult keeps a backtest run in memory, evicting the oldest runs beyond
+// maxBacktestResults
+func (d *Dashboard) keepBacktestResult(result *backtest.BacktestResult) {
+	d.resultsMu.Lock()
+	defer d.resultsMu.Unlock()
+	if _, exists := d.BacktestResults[result.RunID]; !exists {
+		d.resultOrder = append(d.resultOrder, result.RunID)
+	}
+	d.BacktestResults[result.RunID] = result
+	for len(d.resultOrder) > maxBacktestResults {
+		delete(d.BacktestResults, d.resultOrder[0])
+		d.resultOrder = d.resultOrder[1:]
+	}
+}
+
+// forgetBacktestResult removes a backtest run from memory
+func (d *Dashboard) forgetBacktestResult(runID string) {
+	d.resultsMu.Lock()
+	defer d.resultsMu.Unlock()
+	delete(d.BacktestResults, runID)
+	d.resultOrder = slices.DeleteFunc(d.resultOrder, func(id string) bool { return id == runID })
+}
+
+// backtestResult returns a kept backtest run, from memory or from disk, or nil if there is none
+func (d *Dashboard) backtestResult(runID string) (*backtest.BacktestResult, error) {
+	d.resultsMu.Lock()
+	result, found := d.BacktestResults[runID]
+	d.resultsMu.Unlock()
+	if found {
+		return result, nil
+	}
+	if d.BacktestStore == nil {
+		return nil, nil
+	}
+	stored, err := d.BacktestStore.Load(runID)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	return stored.Result, nil
+}
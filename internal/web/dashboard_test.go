@@ -0,0 +1,41 @@
+package web
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+)
+
+func TestBacktestResultsEvictOldest(t *testing.T) {
+	d := &Dashboard{BacktestResults: make(map[string]*backtest.BacktestResult)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxBacktestResults+10; i++ {
+		if i == 10 {
+			wg.Wait() // The first runs are the oldest
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d.keepBacktestResult(&backtest.BacktestResult{RunID: fmt.Sprintf("run-%d", i)})
+			d.backtestResult(fmt.Sprintf("run-%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	if len(d.BacktestResults) != maxBacktestResults || len(d.resultOrder) != maxBacktestResults {
+		t.Fatalf("kept %d results (%d ordered), want %d", len(d.BacktestResults), len(d.resultOrder), maxBacktestResults)
+	}
+	for i := 0; i < 10; i++ {
+		if result, _ := d.backtestResult(fmt.Sprintf("run-%d", i)); result != nil {
+			t.Errorf("run-%d was not evicted", i)
+		}
+	}
+
+	d.forgetBacktestResult("run-20")
+	if result, _ := d.backtestResult("run-20"); result != nil || len(d.resultOrder) != maxBacktestResults-1 {
+		t.Errorf("run-20 still kept after it was deleted")
+	}
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/backtest"
+)
+
+// statusCancelled answers backtests cancelled before they finished, the status nginx answers
+// requests whose client gave up with
+const statusCancelled = 499
+
+// BacktestJob is a backtest request the dashboard is running, with its progress
+type BacktestJob struct {
+	ID       string            `json:"id"`
+	Kind     string            `json:"kind"`  // backtest, sweep or walk-forward
+	Stage    string            `json:"stage"` // Part of the job running, e.g. split for the out-of-sample backtests
+	Started  time.Time         `json:"started"`
+	Progress backtest.Progress `json:"progress"` // Of the stage
+	cancel   context.CancelFunc
+}
+
+// startBacktestJob registers a job for a backtest request under the requested ID, or a new one.
+// Its context is cancelled by the jobs endpoint or when the client goes away.
+func (d *Dashboard) startBacktestJob(r *http.Request, id, kind string) (*BacktestJob, context.Context, error) {
+	if id == "" {
+		id = backtest.NewRunID(kind)
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	job := &BacktestJob{ID: id, Kind: kind, Stage: kind, Started: time.Now(), cancel: cancel}
+
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	if d.backtestJobs == nil {
+		d.backtestJobs = make(map[string]*BacktestJob)
+	}
+	if _, exists := d.backtestJobs[id]; exists {
+		cancel()
+		return nil, nil, fmt.Errorf("Backtest job %s is already running", id)
+	}
+	d.backtestJobs[id] = job
+	return job, ctx, nil
+}
+
+// finishBacktestJob removes a job once its request is answered
+func (d *Dashboard) finishBacktestJob(job *BacktestJob) {
+	job.cancel()
+	d.jobsMu.Lock()
+	delete(d.backtestJobs, job.ID)
+	d.jobsMu.Unlock()
+}
+
+// jobStage moves a job on to the next stage, whose progress starts over
+func (d *Dashboard) jobStage(job *BacktestJob, stage string) {
+	d.jobsMu.Lock()
+	job.Stage, job.Progress = stage, backtest.Progress{}
+	d.jobsMu.Unlock()
+}
+
+// jobProgress returns the progress func keeping a job's progress
+func (d *Dashboard) jobProgress(job *BacktestJob) backtest.ProgressFunc {
+	return func(progress backtest.Progress) {
+		d.jobsMu.Lock()
+		job.Progress = progress
+		d.jobsMu.Unlock()
+	}
+}
+
+// backtestFailed answers a failed backtest request, as cancelled when its job was
+func backtestFailed(w http.ResponseWriter, ctx context.Context, err error, status int) {
+	if ctx.Err() != nil {
+		http.Error(w, "Backtest cancelled", statusCancelled)
+		return
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// backtestJobsHandler lists the running backtest jobs with their progress, oldest first, or
+// one job by ID, and cancels a job by ID
+func (d *Dashboard) backtestJobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobID := r.URL.Query().Get("id")
+
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	job, found := d.backtestJobs[jobID]
+
+	switch r.Method {
+	case http.MethodGet:
+		if jobID != "" {
+			if !found {
+				http.Error(w, "Backtest job not found", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(job)
+			return
+		}
+		jobs := make([]*BacktestJob, 0, len(d.backtestJobs))
+		for _, job := range d.backtestJobs {
+			jobs = append(jobs, job)
+		}
+		sort.Slice(jobs, func(i, j int) bool { return jobs[i].Started.Before(jobs[j].Started) })
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jobs)
+	case http.MethodDelete:
+		if jobID == "" {
+			http.Error(w, "Missing job ID", http.StatusBadRequest)
+			return
+		}
+		if !found {
+			http.Error(w, "Backtest job not found", http.StatusNotFound)
+			return
+		}
+		job.cancel()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
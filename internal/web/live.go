@@ -0,0 +1,285 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/portfolio"
+	"github.com/gorilla/websocket"
+)
+
+// Event types pushed to /ws clients
+const (
+	EventMetrics     = "metrics"      // The last metrics snapshot, every liveMetricsInterval
+	EventTrade       = "trade"        // A LiveTrade as it is logged
+	EventTradeUpdate = "trade_update" // A LiveTrade again once a close's PnL is known
+	EventRiskAlert   = "risk_alert"   // A risk event as it is detected
+	EventStatus      = "status"       // The bot status whenever it changes
+)
+
+const (
+	liveMetricsInterval = 5 * time.Second  // Between two metrics pushes
+	livePingInterval    = 30 * time.Second // Between pings keeping idle connections open
+	liveWriteTimeout    = 10 * time.Second // For a write before the client is dropped
+	liveClientBuffer    = 64               // Events queued for a client before it is dropped as too slow
+)
+
+// liveUpgrader upgrades /ws requests, refusing cross-origin ones
+var liveUpgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 4096}
+
+// LiveEvent is a message pushed to /ws clients
+type LiveEvent struct {
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// LiveTrade is a logged trade, pushed as EventTrade and marked on charts
+type LiveTrade struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Symbol     string    `json:"symbol"`
+	Action     string    `json:"action"`
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	Strategy   string    `json:"strategy"`
+	Confidence float64   `json:"confidence"`
+	Reason     string    `json:"reason"`
+	PnL        float64   `json:"pnl"`
+	OrderID    string    `json:"order_id,omitempty"`
+}
+
+// BotStatus is whether the bot is trading, pushed as EventStatus
+type BotStatus struct {
+	Running    bool   `json:"running"`
+	Halted     bool   `json:"halted"` // By a hard risk limit, until resumed
+	HaltReason string `json:"halt_reason,omitempty"`
+}
+
+// liveHub fans events out to the connected /ws clients, each with its own queue
+type liveHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+	status  *BotStatus             // Last published, sent to new clients
+	metrics map[string]interface{} // Last snapshot, pushed to clients
+	done    chan struct{}
+	stopped bool
+}
+
+// subscribe adds a client whose queue starts with the given messages
+func (h *liveHub) subscribe(initial ...[]byte) chan []byte {
+	send := make(chan []byte, liveClientBuffer)
+	for _, message := range initial {
+		send <- message
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients == nil {
+		h.clients = make(map[chan []byte]bool)
+	}
+	h.clients[send] = true
+	return send
+}
+
+// unsubscribe removes a client and closes its queue, unless it was dropped already
+func (h *liveHub) unsubscribe(send chan []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[send] {
+		delete(h.clients, send)
+		close(send)
+	}
+}
+
+// broadcast queues a message for every client, dropping clients whose queue is full
+func (h *liveHub) broadcast(message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for send := range h.clients {
+		select {
+		case send <- message:
+		default:
+			delete(h.clients, send)
+			close(send)
+		}
+	}
+}
+
+// connected returns the number of clients
+func (h *liveHub) connected() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// stopping returns the channel closed when the hub stops
+func (h *liveHub) stopping() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.done == nil {
+		h.done = make(chan struct{})
+	}
+	return h.done
+}
+
+// stop ends the metrics pushes
+func (h *liveHub) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.done == nil {
+		h.done = make(chan struct{})
+	}
+	if !h.stopped {
+		h.stopped = true
+		close(h.done)
+	}
+}
+
+// Publish pushes an event to the connected /ws clients. It is safe to call from any goroutine
+// and never blocks on slow clients.
+func (d *Dashboard) Publish(eventType string, data interface{}) {
+	message, err := liveMessage(eventType, data)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	d.live.broadcast(message)
+}
+
+// PublishStatus pushes the bot status, which new clients receive on connecting too
+func (d *Dashboard) PublishStatus(status BotStatus) {
+	d.live.mu.Lock()
+	d.live.status = &status
+	d.live.mu.Unlock()
+	d.Publish(EventStatus, status)
+}
+
+// PublishTrade pushes a trade as it is logged
+func (d *Dashboard) PublishTrade(entry portfolio.TradeLogEntry) {
+	d.Publish(EventTrade, liveTrade(entry))
+}
+
+// PublishTradeUpdate pushes a trade again once a close's PnL is applied to it
+func (d *Dashboard) PublishTradeUpdate(entry portfolio.TradeLogEntry) {
+	d.Publish(EventTradeUpdate, liveTrade(entry))
+}
+
+// SnapshotMetrics records the metrics pushed to /ws clients. The portfolio is not locked, so
+// call it from the goroutine that trades rather than reading the portfolio from the pushes.
+func (d *Dashboard) SnapshotMetrics() {
+	metrics := d.metrics()
+	d.live.mu.Lock()
+	d.live.metrics = metrics
+	d.live.mu.Unlock()
+}
+
+// lastMetrics returns the last metrics snapshot, nil before the first
+func (h *liveHub) lastMetrics() map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.metrics
+}
+
+// liveTrade returns the LiveTrade of a trade log entry
+func liveTrade(entry portfolio.TradeLogEntry) LiveTrade {
+	return LiveTrade{
+		Timestamp:  entry.Timestamp,
+		Symbol:     entry.Symbol,
+		Action:     entry.Action,
+		Quantity:   entry.Quantity,
+		Price:      entry.Price,
+		Strategy:   entry.Strategy,
+		Confidence: entry.Confidence,
+		Reason:     entry.Reason,
+		PnL:        entry.PnL,
+		OrderID:    entry.OrderID,
+	}
+}
+
+// liveMessage encodes an event
+func liveMessage(eventType string, data interface{}) ([]byte, error) {
+	message, err := json.Marshal(LiveEvent{Type: eventType, Data: data, Timestamp: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return message, nil
+}
+
+// pushMetrics pushes the last metrics snapshot to the connected clients every
+// liveMetricsInterval until the dashboard stops
+func (d *Dashboard) pushMetrics() {
+	ticker := time.NewTicker(liveMetricsInterval)
+	defer ticker.Stop()
+	done := d.live.stopping()
+	for {
+		select {
+		case <-ticker.C:
+			if metrics := d.live.lastMetrics(); metrics != nil && d.live.connected() > 0 {
+				d.Publish(EventMetrics, metrics)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// wsHandler upgrades to a WebSocket pushing live events: first the last metrics snapshot and
+// bot status, then every event as it is published
+func (d *Dashboard) wsHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader answered the request
+	}
+	defer conn.Close()
+
+	var initial [][]byte
+	d.live.mu.Lock()
+	metrics, status := d.live.metrics, d.live.status
+	d.live.mu.Unlock()
+	if metrics != nil {
+		if message, err := liveMessage(EventMetrics, metrics); err == nil {
+			initial = append(initial, message)
+		}
+	}
+	if status != nil {
+		if message, err := liveMessage(EventStatus, *status); err == nil {
+			initial = append(initial, message)
+		}
+	}
+	send := d.live.subscribe(initial...)
+	defer d.live.unsubscribe(send)
+
+	// Clients send nothing, reading only notices them closing the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case message, ok := <-send:
+			if !ok {
+				return // Dropped as too slow
+			}
+			conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
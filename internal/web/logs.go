@@ -0,0 +1,204 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Levels of captured log entries, in increasing severity
+const (
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+// defaultLogEntries is the number of entries /api/logs returns by default
+const defaultLogEntries = 100
+
+// logLevelRank orders the levels for minimum level filters
+var logLevelRank = map[string]int{LogInfo: 0, LogWarn: 1, LogError: 2}
+
+// Message prefixes of the levels above info, as the bot's log messages start
+var (
+	logErrorPrefixes = []string{"Error", "ERROR", "Failed", "HALT"}
+	logWarnPrefixes  = []string{"Warning", "WARNING", "RISK EVENT"}
+)
+
+// logSymbolPattern matches the trading symbols a log message mentions
+var logSymbolPattern = regexp.MustCompile(`\b[A-Z0-9]{2,}(?:USDT|USDC|BTC|ETH)\b`)
+
+// logTimestampLength is the length of the date and time the standard logger prefixes
+const logTimestampLength = len("2006/01/02 15:04:05 ")
+
+// LogEntry is a captured log message
+type LogEntry struct {
+	ID        int64     `json:"id"` // Increasing, to fetch only newer entries
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Symbols   []string  `json:"symbols,omitempty"` // Mentioned in the message
+	Message   string    `json:"message"`
+}
+
+// LogBuffer keeps the most recent log messages written to it. It is an io.Writer for the
+// standard logger, which writes every message in one call.
+type LogBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry // Ring of the last entries
+	next    int        // Index of the next entry in the ring
+	full    bool       // Whether the ring wrapped around
+	lastID  int64
+}
+
+// NewLogBuffer creates a LogBuffer keeping the last capacity messages
+func NewLogBuffer(capacity int) *LogBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LogBuffer{entries: make([]LogEntry, capacity)}
+}
+
+// Write captures a log message, inferring its level and the symbols it mentions
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	timestamp := time.Now()
+	if len(message) >= logTimestampLength {
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", message[:logTimestampLength-1], time.Local); err == nil {
+			timestamp, message = t, message[logTimestampLength:]
+		}
+	}
+	if message == "" {
+		return len(p), nil
+	}
+
+	entry := LogEntry{Timestamp: timestamp, Level: logLevel(message), Message: message}
+	seen := make(map[string]bool)
+	for _, symbol := range logSymbolPattern.FindAllString(message, -1) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			entry.Symbols = append(entry.Symbols, symbol)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	entry.ID = b.lastID
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	b.full = b.full || b.next == 0
+	return len(p), nil
+}
+
+// Entries returns the last limit entries, oldest first, of at least a level (empty for all),
+// mentioning a symbol (empty for all) and newer than an entry ID
+func (b *LogBuffer) Entries(minLevel, symbol string, afterID int64, limit int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var matched []LogEntry
+	// From the newest entry backwards
+	size := b.next
+	if b.full {
+		size = len(b.entries)
+	}
+	for i := 1; i <= size && len(matched) < limit; i++ {
+		entry := b.entries[(b.next-i+len(b.entries))%len(b.entries)]
+		if entry.ID <= afterID {
+			break
+		}
+		if logLevelRank[entry.Level] < logLevelRank[minLevel] {
+			continue
+		}
+		if symbol != "" && !slices.Contains(entry.Symbols, symbol) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched
+}
+
+// Capacity returns the number of entries the buffer keeps
+func (b *LogBuffer) Capacity() int {
+	return len(b.entries)
+}
+
+// LastID returns the ID of the newest entry, zero if none was written
+func (b *LogBuffer) LastID() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// logLevel infers the level of a log message from how it starts
+func logLevel(message string) string {
+	message = strings.TrimSpace(message)
+	for _, prefix := range logErrorPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return LogError
+		}
+	}
+	for _, prefix := range logWarnPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return LogWarn
+		}
+	}
+	return LogInfo
+}
+
+// logsHandler serves the recent log entries of the bot, oldest first. Supports the query
+// parameters level (minimum level: info, warn or error), symbol, after (only entries with a
+// higher ID, for polling) and limit.
+func (d *Dashboard) logsHandler(w http.ResponseWriter, r *http.Request) {
+	if d.Logs == nil {
+		http.Error(w, "Log capture not available", http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query()
+	level := strings.ToLower(query.Get("level"))
+	if _, known := logLevelRank[level]; level != "" && !known {
+		http.Error(w, "Invalid level (info, warn or error)", http.StatusBadRequest)
+		return
+	}
+	limit := defaultLogEntries
+	if val := query.Get("limit"); val != "" {
+		var err error
+		if limit, err = strconv.Atoi(val); err != nil || limit <= 0 || limit > d.Logs.Capacity() {
+			http.Error(w, fmt.Sprintf("Invalid limit (1 to %d)", d.Logs.Capacity()), http.StatusBadRequest)
+			return
+		}
+	}
+	var afterID int64
+	if val := query.Get("after"); val != "" {
+		var err error
+		if afterID, err = strconv.ParseInt(val, 10, 64); err != nil || afterID < 0 {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries := d.Logs.Entries(level, strings.ToUpper(query.Get("symbol")), afterID, limit)
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	response := map[string]interface{}{
+		"entries":   entries,
+		"count":     len(entries),
+		"capacity":  d.Logs.Capacity(),
+		"last_id":   d.Logs.LastID(),
+		"timestamp": time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// defaultStrategyWindow is the period of the recent strategy performance by default
+const defaultStrategyWindow = 7 * 24 * time.Hour
+
+// strategiesHandler serves, per traded symbol, the market regime the StrategyAI weighs the
+// strategies by, their weights, the selected strategy and its parameters for the symbol, with
+// each strategy's performance over the trades of a recent window (query parameter window, a
+// Go duration, days such as 30d or seconds; default 7 days)
+func (d *Dashboard) strategiesHandler(w http.ResponseWriter, r *http.Request) {
+	if d.StrategyAI == nil {
+		http.Error(w, "Strategy selection not available", http.StatusServiceUnavailable)
+		return
+	}
+	window, err := parseDurationParam(r.URL.Query().Get("window"))
+	if err != nil {
+		http.Error(w, "Invalid window: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if window == 0 {
+		window = defaultStrategyWindow
+	}
+
+	symbols := make(map[string]interface{}, len(d.PortfolioManager.Symbols))
+	for _, symbol := range d.PortfolioManager.Symbols {
+		regime := d.MarketAnalyzer.GetMarketRegime(symbol)
+		details := map[string]interface{}{
+			"regime": map[string]string{
+				"volatility": regime.Volatility,
+				"trend":      regime.Trend,
+				"volume":     regime.Volume,
+			},
+			"weights": d.StrategyAI.GetStrategyWeights(symbol),
+		}
+		if d.StrategyDetails != nil {
+			if selected, params, ok := d.StrategyDetails(symbol); ok {
+				details["selected"] = selected
+				details["parameters"] = params
+			}
+		} else if selected, ok := d.StrategyAI.LastSelection(symbol); ok {
+			details["selected"] = selected
+		}
+		symbols[symbol] = details
+	}
+
+	now := time.Now()
+	response := map[string]interface{}{
+		"symbols":        symbols,
+		"bandit":         d.StrategyAI.Bandit != nil,
+		"performance":    d.PortfolioManager.GetRecentStrategyPerformanceMetrics(now.Add(-window)),
+		"window_seconds": int64(window / time.Second),
+		"timestamp":      now.Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,149 @@
+package web
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forbest/bybitgo/internal/bybit"
+	"github.com/forbest/bybitgo/internal/market"
+)
+
+// symbolRecentTrades is the number of recent trades of a symbol's details
+const symbolRecentTrades = 20
+
+// symbolHandler serves everything the bot knows of one symbol in one payload: its market regime
+// and indicator values, the strategy selection and weights, the open position, recent trades
+// and risk levels
+func (d *Dashboard) symbolHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+
+	tracked := false
+	for _, s := range d.PortfolioManager.Symbols {
+		tracked = tracked || s == symbol
+	}
+	trades := d.PortfolioManager.GetTradeLogForSymbol(symbol)
+	pos, hasPosition := d.RiskManager.Positions[symbol]
+	if !tracked && !hasPosition && len(trades) == 0 {
+		http.Error(w, "Symbol not traded: "+symbol, http.StatusNotFound)
+		return
+	}
+
+	regime := d.MarketAnalyzer.GetMarketRegime(symbol)
+	indicators := map[string]interface{}{}
+	if volatility, exists := d.MarketAnalyzer.VolatilityTracker[symbol]; exists {
+		indicators["volatility"] = map[string]float64{
+			"recent":    volatility.RecentVolatility,
+			"long_term": volatility.LongTermVolatility,
+		}
+	}
+	if trend, exists := d.MarketAnalyzer.TrendIndicator[symbol]; exists {
+		indicators["trend"] = map[string]interface{}{
+			"strength":  trend.TrendStrength,
+			"direction": trend.TrendDirection,
+			"adx":       trend.ADX,
+		}
+	}
+	if volume, exists := d.MarketAnalyzer.VolumeAnalysis[symbol]; exists {
+		indicators["volume"] = map[string]interface{}{
+			"current": volume.CurrentVolume,
+			"average": volume.AverageVolume,
+			"ratio":   volume.VolumeRatio,
+			"trend":   volume.VolumeTrend,
+		}
+	}
+	// The chart indicators at the last kline of the market data interval
+	if klines, _, err := d.chartKlines(r.Context(), symbol, bybit.MarketDataInterval, 1); err != nil {
+		indicators["error"] = err.Error()
+	} else if len(klines) > 0 {
+		series := market.CalculateChartIndicators(klines, len(klines)-1)
+		latest := func(s market.Series) interface{} {
+			if len(s) == 0 || math.IsNaN(s[len(s)-1]) {
+				return nil
+			}
+			return s[len(s)-1]
+		}
+		indicators["macd"] = latest(series.MACD)
+		indicators["macd_signal"] = latest(series.MACDSignal)
+		indicators["macd_histogram"] = latest(series.MACDHistogram)
+		indicators["bollinger_middle"] = latest(series.BollingerMiddle)
+		indicators["bollinger_upper"] = latest(series.BollingerUpper)
+		indicators["bollinger_lower"] = latest(series.BollingerLower)
+	}
+
+	// The strategy of the open position, else the one the AI would select
+	strategyName := ""
+	selection := map[string]interface{}{}
+	if d.StrategyAI != nil {
+		selection["weights"] = d.StrategyAI.GetStrategyWeights(symbol)
+		if selected, ok := d.StrategyAI.LastSelection(symbol); ok {
+			selection["selected"] = selected
+			strategyName = string(selected)
+		}
+	}
+
+	var position map[string]interface{}
+	if hasPosition {
+		strategyName = pos.Strategy
+		position = map[string]interface{}{
+			"size":           pos.CurrentSize,
+			"entry_price":    pos.EntryPrice,
+			"current_price":  pos.CurrentPrice,
+			"unrealized_pnl": pos.UnrealizedPnL,
+			"value":          math.Abs(pos.CurrentSize * pos.CurrentPrice),
+			"opened_at":      pos.OpenedAt,
+			"strategy":       pos.Strategy,
+			"lowest_price":   pos.LowestPrice,
+			"highest_price":  pos.HighestPrice,
+		}
+	}
+
+	riskLevels := map[string]interface{}{
+		"stop_loss_percent":     d.RiskManager.StopLossPercent(symbol),
+		"take_profit_percent":   d.RiskManager.TakeProfitPercent(symbol),
+		"trailing_stop_percent": d.RiskManager.TrailingStopPercent(symbol),
+		"max_drawdown":          d.RiskManager.MaxDrawdown(symbol),
+		"category":              d.RiskManager.SymbolCategory(symbol),
+	}
+	if headroom, limited := d.RiskManager.CategoryHeadroom(symbol); limited {
+		riskLevels["category_headroom"] = headroom
+	}
+	if hasPosition {
+		riskLevels["stop_loss_level"] = pos.StopLossLevel
+		riskLevels["take_profit_level"] = pos.TakeProfitLevel
+		if pos.IsTrailingStopSet {
+			riskLevels["trailing_stop_level"] = pos.TrailingStopLevel
+		}
+	}
+	if paused, reason := d.RiskManager.IsTradingPaused(symbol, strategyName, time.Now()); paused {
+		riskLevels["paused"] = reason
+	}
+
+	recent := make([]LiveTrade, 0, symbolRecentTrades)
+	for i := len(trades) - 1; i >= 0 && len(recent) < symbolRecentTrades; i-- {
+		recent = append(recent, liveTrade(trades[i]))
+	}
+
+	response := map[string]interface{}{
+		"symbol":     symbol,
+		"tracked":    tracked,
+		"allocation": d.PortfolioManager.Allocations[symbol],
+		"last_price": d.PortfolioManager.LastPrices[symbol],
+		"regime": map[string]string{
+			"volatility": regime.Volatility,
+			"trend":      regime.Trend,
+			"volume":     regime.Volume,
+		},
+		"indicators": indicators,
+		"strategy":   selection,
+		"position":   position,
+		"trades":     recent,
+		"risk":       riskLevels,
+		"timestamp":  time.Now().Unix(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -25,6 +25,8 @@ function refreshData() {
     fetchRisk();
     fetchMarket();
     fetchPortfolio();
+    fetchEquity();
+    fetchLogs();
     document.getElementById('last-updated').textContent = new Date().toLocaleString();
 }
 
@@ -32,26 +34,30 @@ function refreshData() {
 function fetchMetrics() {
     fetch('/api/metrics')
         .then(response => response.json())
-        .then(data => {
-            document.getElementById('total-trades').textContent = data.total_trades;
-            document.getElementById('win-rate').textContent = (data.win_rate * 100).toFixed(2) + '%';
-            document.getElementById('total-pnl').textContent = '$' + data.total_pnl.toFixed(2);
-            document.getElementById('avg-pnl').textContent = '$' + data.avg_pnl.toFixed(2);
-            document.getElementById('sharpe-ratio').textContent = data.sharpe_ratio.toFixed(2);
-            
-            // Update PnL color based on value
-            const pnlElement = document.getElementById('total-pnl');
-            if (data.total_pnl > 0) {
-                pnlElement.className = 'metric-value positive';
-            } else if (data.total_pnl < 0) {
-                pnlElement.className = 'metric-value negative';
-            } else {
-                pnlElement.className = 'metric-value';
-            }
-        })
+        .then(renderMetrics)
         .catch(error => console.error('Error fetching metrics:', error));
 }
 
+// Show performance metrics, fetched or pushed over /ws
+function renderMetrics(data) {
+    document.getElementById('total-trades').textContent = data.total_trades;
+    document.getElementById('win-rate').textContent = (data.win_rate * 100).toFixed(2) + '%';
+    document.getElementById('total-pnl').textContent = '$' + data.total_pnl.toFixed(2);
+    document.getElementById('avg-pnl').textContent = '$' + data.avg_pnl.toFixed(2);
+    document.getElementById('sharpe-ratio').textContent = data.sharpe_ratio.toFixed(2);
+    document.getElementById('alpha').textContent = (data.benchmark.alpha * 100).toFixed(2) + '% vs ' + data.benchmark.symbol;
+    
+    // Update PnL color based on value
+    const pnlElement = document.getElementById('total-pnl');
+    if (data.total_pnl > 0) {
+        pnlElement.className = 'metric-value positive';
+    } else if (data.total_pnl < 0) {
+        pnlElement.className = 'metric-value negative';
+    } else {
+        pnlElement.className = 'metric-value';
+    }
+}
+
 // Fetch recent trades
 function fetchTrades() {
     fetch('/api/trades')
@@ -61,22 +67,27 @@ function fetchTrades() {
             tbody.innerHTML = '';
             
             data.trades.slice(0, 10).forEach(trade => {
-                const row = document.createElement('tr');
-                row.innerHTML = '<td>' + new Date(trade.timestamp).toLocaleTimeString() + '</td>' +
-                    '<td>' + trade.symbol + '</td>' +
-                    '<td class="action-' + trade.action.toLowerCase() + '">' + trade.action + '</td>' +
-                    '<td>' + trade.quantity.toFixed(4) + '</td>' +
-                    '<td>$' + trade.price.toFixed(4) + '</td>' +
-                    '<td>' + trade.strategy + '</td>' +
-                    '<td>' + (trade.confidence * 100).toFixed(1) + '%</td>' +
-                    '<td class="' + (trade.pnl > 0 ? 'positive' : trade.pnl < 0 ? 'negative' : '') + '">$' +
-                        trade.pnl.toFixed(2) + '</td>';
-                tbody.appendChild(row);
+                tbody.appendChild(tradeRow(trade));
             });
         })
         .catch(error => console.error('Error fetching trades:', error));
 }
 
+// Build the table row of a trade
+function tradeRow(trade) {
+    const row = document.createElement('tr');
+    row.innerHTML = '<td>' + new Date(trade.timestamp).toLocaleTimeString() + '</td>' +
+        '<td>' + trade.symbol + '</td>' +
+        '<td class="action-' + trade.action.toLowerCase() + '">' + trade.action + '</td>' +
+        '<td>' + trade.quantity.toFixed(4) + '</td>' +
+        '<td>$' + trade.price.toFixed(4) + '</td>' +
+        '<td>' + trade.strategy + '</td>' +
+        '<td>' + (trade.confidence * 100).toFixed(1) + '%</td>' +
+        '<td class="' + (trade.pnl > 0 ? 'positive' : trade.pnl < 0 ? 'negative' : '') + '">$' +
+            trade.pnl.toFixed(2) + '</td>';
+    return row;
+}
+
 // Fetch performance data
 function fetchPerformance() {
     fetch('/api/performance')
@@ -106,6 +117,19 @@ function fetchRisk() {
             document.getElementById('portfolio-drawdown').textContent = (data.portfolio_drawdown * 100).toFixed(2) + '%';
             document.getElementById('volatility').textContent = (data.volatility * 100).toFixed(2) + '%';
             document.getElementById('correlation-risk').textContent = data.correlation_risk.toFixed(2);
+
+            const container = document.getElementById('strategy-capital');
+            container.innerHTML = '';
+            data.capital_buckets.forEach(bucket => {
+                const div = document.createElement('div');
+                div.className = 'metric';
+                const value = bucket.budget > 0
+                    ? '$' + bucket.used.toFixed(2) + ' / $' + bucket.budget.toFixed(2) + ' (' + bucket.utilization_percent.toFixed(1) + '%)'
+                    : '$' + bucket.used.toFixed(2);
+                div.innerHTML = '<span class="metric-label">' + bucket.strategy + ':</span>' +
+                    '<span class="metric-value">' + value + '</span>';
+                container.appendChild(div);
+            });
         })
         .catch(error => console.error('Error fetching risk:', error));
 }
@@ -168,20 +192,88 @@ function sendCommand(command) {
     });
 }
 
+// Force-close all circuit breakers
+function resetCircuitBreakers() {
+    fetch('/api/circuit-breakers', {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({action: 'reset'}),
+    })
+    .then(response => response.json())
+    .then(data => {
+        const log = document.getElementById('override-log');
+        const entry = document.createElement('div');
+        const states = data.circuit_breakers.map(cb => cb.name + ': ' + cb.state).join(', ');
+        entry.textContent = '[' + new Date().toLocaleTimeString() + '] Circuit breakers reset (' + states + ')';
+        log.appendChild(entry);
+        log.scrollTop = log.scrollHeight;
+    })
+    .catch(error => {
+        console.error('Error resetting circuit breakers:', error);
+        alert('Error resetting circuit breakers: ' + error.message);
+    });
+}
+
+// Follow the progress of a running backtest job until it ends
+function followBacktestJob(jobId) {
+    window.backtestJob = jobId;
+    const progressDiv = document.getElementById('backtest-progress');
+    const timer = setInterval(() => {
+        if (window.backtestJob !== jobId) {
+            clearInterval(timer);
+            return;
+        }
+        fetch('/api/backtest/jobs?id=' + encodeURIComponent(jobId))
+        .then(response => response.ok ? response.json() : null)
+        .then(job => {
+            if (!job || window.backtestJob !== jobId) {
+                return;
+            }
+            const progress = job.progress;
+            progressDiv.textContent = job.stage + ': ' + progress.percent.toFixed(1) + '% (' +
+                progress.bars + ' / ' + progress.total_bars + ' bars, ETA ' + Math.round(progress.eta_seconds) + 's)';
+        })
+        .catch(error => console.error('Error fetching backtest progress:', error));
+    }, 1000);
+}
+
+// Stop following a backtest job
+function endBacktestJob(jobId) {
+    if (window.backtestJob === jobId) {
+        window.backtestJob = null;
+        document.getElementById('backtest-progress').textContent = '';
+    }
+}
+
+// Cancel the running backtest job
+function cancelBacktest() {
+    const jobId = window.backtestJob;
+    if (!jobId) {
+        return;
+    }
+    fetch('/api/backtest/jobs?id=' + encodeURIComponent(jobId), { method: 'DELETE' })
+    .catch(error => console.error('Error cancelling backtest:', error));
+}
+
 // Run backtest
 function runBacktest() {
     const strategy = document.getElementById('backtest-strategy').value;
     const initialCapital = document.getElementById('initial-capital').value;
     const startDate = document.getElementById('start-date').value;
     const endDate = document.getElementById('end-date').value;
+    const jobId = 'backtest-' + Date.now();
 
     const data = {
         strategy: strategy,
         initial_capital: parseFloat(initialCapital),
         start_date: startDate,
-        end_date: endDate
+        end_date: endDate,
+        job_id: jobId
     };
 
+    followBacktestJob(jobId);
     fetch('/api/backtest', {
         method: 'POST',
         headers: {
@@ -189,14 +281,20 @@ function runBacktest() {
         },
         body: JSON.stringify(data),
     })
-    .then(response => response.json())
+    .then(response => {
+        if (!response.ok) {
+            return response.text().then(text => { throw new Error(text); });
+        }
+        return response.json();
+    })
     .then(data => {
         displayBacktestResults(data);
     })
     .catch(error => {
         console.error('Error running backtest:', error);
         alert('Error running backtest: ' + error.message);
-    });
+    })
+    .finally(() => endBacktestJob(jobId));
 }
 
 // Display backtest results
@@ -237,8 +335,62 @@ function displayBacktestResults(data) {
         '<div class="metric">' +
         '<span class="metric-label">Sortino Ratio:</span>' +
         '<span class="metric-value">' + data.sortino_ratio.toFixed(2) + '</span>' +
+        '</div>' +
+        '<div class="metric">' +
+        '<span class="metric-label">CAGR:</span>' +
+        '<span class="metric-value ' + (data.cagr >= 0 ? 'positive' : 'negative') + '">' + data.cagr.toFixed(2) + '%</span>' +
+        '</div>' +
+        '<div class="metric">' +
+        '<span class="metric-label">Calmar Ratio:</span>' +
+        '<span class="metric-value">' + data.calmar_ratio.toFixed(2) + '</span>' +
+        '</div>' +
+        '<div class="metric">' +
+        '<span class="metric-label">Drawdown Duration (max / avg):</span>' +
+        '<span class="metric-value">' + data.max_drawdown_days.toFixed(1) + ' / ' + data.avg_drawdown_days.toFixed(1) + ' days</span>' +
+        '</div>' +
+        '<div class="metric">' +
+        '<span class="metric-label">Time in Market:</span>' +
+        '<span class="metric-value">' + data.exposure_percent.toFixed(1) + '%</span>' +
         '</div>';
 
+    // Monte Carlo confidence intervals (5th-95th percentile)
+    const mc = data.monte_carlo;
+    if (mc && mc.trades > 0) {
+        resultsDiv.innerHTML += '<div class="metric">' +
+            '<span class="metric-label">MC Max Drawdown (5-95%):</span>' +
+            '<span class="metric-value negative">' + mc.max_drawdown.p5.toFixed(2) + '% - ' + mc.max_drawdown.p95.toFixed(2) + '%</span>' +
+            '</div>' +
+            '<div class="metric">' +
+            '<span class="metric-label">MC CAGR (5-95%):</span>' +
+            '<span class="metric-value">' + mc.cagr.p5.toFixed(2) + '% - ' + mc.cagr.p95.toFixed(2) + '%</span>' +
+            '</div>' +
+            '<div class="metric">' +
+            '<span class="metric-label">MC Ruin Probability:</span>' +
+            '<span class="metric-value">' + (mc.ruin_probability * 100).toFixed(1) + '%</span>' +
+            '</div>';
+    }
+
+    // Buy-and-hold benchmark of the same symbols
+    const benchmark = data.benchmark;
+    if (benchmark) {
+        resultsDiv.innerHTML += '<div class="metric">' +
+            '<span class="metric-label">Buy & Hold Return:</span>' +
+            '<span class="metric-value ' + (benchmark.total_return >= 0 ? 'positive' : 'negative') + '">' + benchmark.total_return.toFixed(2) + '%</span>' +
+            '</div>' +
+            '<div class="metric">' +
+            '<span class="metric-label">Excess Return:</span>' +
+            '<span class="metric-value ' + (benchmark.excess_return >= 0 ? 'positive' : 'negative') + '">' + benchmark.excess_return.toFixed(2) + '%</span>' +
+            '</div>' +
+            '<div class="metric">' +
+            '<span class="metric-label">Beta:</span>' +
+            '<span class="metric-value">' + benchmark.beta.toFixed(2) + '</span>' +
+            '</div>' +
+            '<div class="metric">' +
+            '<span class="metric-label">Relative Drawdown:</span>' +
+            '<span class="metric-value negative">' + benchmark.relative_drawdown.toFixed(2) + '%</span>' +
+            '</div>';
+    }
+
     // Display trade history
     const tradesBody = document.getElementById('backtest-trades-body');
     tradesBody.innerHTML = '';
@@ -250,6 +402,7 @@ function displayBacktestResults(data) {
             '<td>' + trade.quantity.toFixed(4) + '</td>' +
             '<td>$' + trade.entry_price.toFixed(2) + '</td>' +
             '<td>$' + trade.exit_price.toFixed(2) + '</td>' +
+            '<td>' + (trade.exit_reason || '') + '</td>' +
             '<td class="' + (trade.pnl >= 0 ? 'positive' : 'negative') + '">$' + trade.pnl.toFixed(2) + '</td>';
         tradesBody.appendChild(row);
     });
@@ -258,37 +411,241 @@ function displayBacktestResults(data) {
     updateEquityChart(data.equity_curve);
 }
 
+// Run a parameter sweep with the backtest configuration
+function runSweep() {
+    const data = {
+        strategy: document.getElementById('backtest-strategy').value,
+        initial_capital: parseFloat(document.getElementById('initial-capital').value),
+        start_date: document.getElementById('start-date').value,
+        end_date: document.getElementById('end-date').value,
+        grid: document.getElementById('sweep-grid').value,
+        job_id: 'sweep-' + Date.now()
+    };
+
+    followBacktestJob(data.job_id);
+    fetch('/api/backtest/sweep', {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(data),
+    })
+    .then(response => {
+        if (!response.ok) {
+            return response.text().then(text => { throw new Error(text); });
+        }
+        return response.json();
+    })
+    .then(data => {
+        window.lastSweep = data;
+        displaySweepResults();
+    })
+    .catch(error => {
+        console.error('Error running sweep:', error);
+        alert('Error running sweep: ' + error.message);
+    })
+    .finally(() => endBacktestJob(data.job_id));
+}
+
+// Display the sweep as a heatmap of the chosen metric: a matrix for two parameters, a list otherwise
+function displaySweepResults() {
+    const sweep = window.lastSweep;
+    if (!sweep) {
+        return;
+    }
+    const metric = document.getElementById('sweep-metric').value;
+    const resultsDiv = document.getElementById('sweep-results');
+
+    const valid = sweep.rows.filter(row => !row.error);
+    const values = valid.map(row => row[metric]);
+    const min = Math.min(...values);
+    const max = Math.max(...values);
+    const lowerIsBetter = metric === 'max_drawdown';
+    const cell = row => {
+        if (!row || row.error) {
+            return '<td title="' + (row ? row.error : '') + '">-</td>';
+        }
+        let ratio = max > min ? (row[metric] - min) / (max - min) : 0.5;
+        if (lowerIsBetter) {
+            ratio = 1 - ratio;
+        }
+        return '<td style="background-color: hsl(' + Math.round(ratio * 120) + ', 60%, 45%)">' + row[metric].toFixed(2) + '</td>';
+    };
+
+    if (sweep.parameters.length === 2) {
+        const [rowName, colName] = sweep.parameters;
+        const find = (r, c) => sweep.rows.find(row => row.parameters[rowName] === r && row.parameters[colName] === c);
+        let html = '<table><thead><tr><th>' + rowName + ' \\ ' + colName + '</th>';
+        sweep.values[colName].forEach(c => { html += '<th>' + c + '</th>'; });
+        html += '</tr></thead><tbody>';
+        sweep.values[rowName].forEach(r => {
+            html += '<tr><th>' + r + '</th>';
+            sweep.values[colName].forEach(c => { html += cell(find(r, c)); });
+            html += '</tr>';
+        });
+        resultsDiv.innerHTML = html + '</tbody></table>';
+        return;
+    }
+
+    let html = '<table><thead><tr>';
+    sweep.parameters.forEach(name => { html += '<th>' + name + '</th>'; });
+    html += '<th>' + metric + '</th><th>Trades</th></tr></thead><tbody>';
+    sweep.rows.forEach(row => {
+        html += '<tr>';
+        sweep.parameters.forEach(name => { html += '<td>' + row.parameters[name] + '</td>'; });
+        html += cell(row) + '<td>' + row.total_trades + '</td></tr>';
+    });
+    resultsDiv.innerHTML = html + '</tbody></table>';
+}
+
 // Update equity chart (simplified implementation)
 function updateEquityChart(equityCurve) {
-    const canvas = document.getElementById('equity-chart');
+    drawLineChart('equity-chart', equityCurve.map(point => point.equity), '#2196f3');
+}
+
+// Fetch the live equity curve, downsampled to the chart's width, with its drawdowns
+function fetchEquity() {
+    fetch('/api/equity?max_points=500')
+        .then(response => response.json())
+        .then(data => {
+            drawLineChart('live-equity-chart', data.equity_curve.map(point => point.equity), '#2196f3');
+            // Drawdowns point down from zero
+            drawLineChart('live-drawdown-chart', data.equity_curve.map(point => -point.drawdown), '#f44336');
+            document.getElementById('live-max-drawdown').textContent = data.max_drawdown.toFixed(2) + '%';
+        })
+        .catch(error => console.error('Error fetching equity:', error));
+}
+
+// Fetch the bot's recent log entries, newest first
+function fetchLogs() {
+    const params = new URLSearchParams({limit: 50});
+    const level = document.getElementById('logs-level').value;
+    const symbol = document.getElementById('logs-symbol').value.trim();
+    if (level) {
+        params.set('level', level);
+    }
+    if (symbol) {
+        params.set('symbol', symbol);
+    }
+    fetch('/api/logs?' + params)
+        .then(response => response.json())
+        .then(data => {
+            const tbody = document.getElementById('logs-body');
+            tbody.innerHTML = '';
+            data.entries.reverse().forEach(entry => {
+                const row = tbody.insertRow();
+                row.insertCell().textContent = new Date(entry.timestamp).toLocaleString();
+                const levelCell = row.insertCell();
+                levelCell.textContent = entry.level;
+                if (entry.level === 'error') {
+                    levelCell.className = 'negative';
+                }
+                row.insertCell().textContent = entry.message;
+            });
+        })
+        .catch(error => console.error('Error fetching logs:', error));
+}
+
+// Fetch and draw a symbol's candles with their indicators and the bot's trades
+function fetchChart() {
+    const symbol = document.getElementById('chart-symbol').value;
+    const interval = document.getElementById('chart-interval').value;
+    fetch('/api/chart?symbol=' + encodeURIComponent(symbol) + '&interval=' + encodeURIComponent(interval))
+        .then(response => {
+            if (!response.ok) return response.text().then(text => { throw new Error(text); });
+            return response.json();
+        })
+        .then(data => {
+            const closes = data.candles.map(candle => candle.close);
+            const indicators = data.indicators;
+            drawSeriesChart('symbol-chart', [
+                { values: indicators.bollinger_upper, color: '#bdbdbd' },
+                { values: indicators.bollinger_middle, color: '#9e9e9e' },
+                { values: indicators.bollinger_lower, color: '#bdbdbd' },
+                { values: indicators.vwap, color: '#ff9800' },
+                { values: closes, color: '#2196f3' },
+            ], data.trades.map(trade => ({
+                index: data.candles.findIndex(candle => new Date(candle.timestamp) > new Date(trade.timestamp)) - 1,
+                value: trade.price,
+                color: trade.action === 'BUY' || trade.action === 'COVER' ? '#4caf50' : '#f44336',
+            })));
+            drawSeriesChart('macd-chart', [
+                { values: indicators.macd_histogram, color: '#9e9e9e' },
+                { values: indicators.macd, color: '#2196f3' },
+                { values: indicators.macd_signal, color: '#ff9800' },
+            ], []);
+        })
+        .catch(error => alert('Error fetching chart: ' + error.message));
+}
+
+// Draw aligned series, skipping missing values, with point markers on a shared scale
+function drawSeriesChart(canvasId, series, markers) {
+    const canvas = document.getElementById(canvasId);
+    const ctx = canvas.getContext('2d');
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+
+    const all = series.flatMap(s => s.values).concat(markers.map(m => m.value)).filter(v => v !== null);
+    if (all.length === 0) return;
+    const minValue = Math.min(...all);
+    const range = Math.max(...all) - minValue || 1;
+    const length = Math.max(...series.map(s => s.values.length));
+    const padding = 20;
+    const x = index => padding + (index / Math.max(length - 1, 1)) * (canvas.width - 2 * padding);
+    const y = value => canvas.height - padding - ((value - minValue) / range) * (canvas.height - 2 * padding);
+
+    series.forEach(s => {
+        ctx.beginPath();
+        ctx.strokeStyle = s.color;
+        ctx.lineWidth = 1.5;
+        let drawing = false;
+        s.values.forEach((value, index) => {
+            if (value === null) {
+                drawing = false;
+                return;
+            }
+            drawing ? ctx.lineTo(x(index), y(value)) : ctx.moveTo(x(index), y(value));
+            drawing = true;
+        });
+        ctx.stroke();
+    });
+
+    markers.forEach(marker => {
+        // Trades after the last candle's open belong to the last candle
+        const index = marker.index < 0 ? length - 1 : marker.index;
+        ctx.beginPath();
+        ctx.fillStyle = marker.color;
+        ctx.arc(x(index), y(marker.value), 4, 0, 2 * Math.PI);
+        ctx.fill();
+    });
+}
+
+// Draw values as a line scaled to a canvas
+function drawLineChart(canvasId, values, color) {
+    const canvas = document.getElementById(canvasId);
     const ctx = canvas.getContext('2d');
     
     // Clear canvas
     ctx.clearRect(0, 0, canvas.width, canvas.height);
     
-    if (equityCurve.length === 0) return;
+    if (values.length === 0) return;
     
     // Simple line chart implementation
     ctx.beginPath();
-    ctx.strokeStyle = '#2196f3';
+    ctx.strokeStyle = color;
     ctx.lineWidth = 2;
     
     const width = canvas.width;
     const height = canvas.height;
     const padding = 20;
     
-    // Find min and max equity values
-    let minEquity = equityCurve[0].equity;
-    let maxEquity = equityCurve[0].equity;
-    equityCurve.forEach(point => {
-        if (point.equity < minEquity) minEquity = point.equity;
-        if (point.equity > maxEquity) maxEquity = point.equity;
-    });
+    // Find min and max values
+    const minValue = Math.min(...values);
+    const range = Math.max(...values) - minValue || 1;
     
     // Draw the line
-    equityCurve.forEach((point, index) => {
-        const x = padding + (index / (equityCurve.length - 1)) * (width - 2 * padding);
-        const y = height - padding - ((point.equity - minEquity) / (maxEquity - minEquity)) * (height - 2 * padding);
+    values.forEach((value, index) => {
+        const x = padding + (index / Math.max(values.length - 1, 1)) * (width - 2 * padding);
+        const y = height - padding - ((value - minValue) / range) * (height - 2 * padding);
         
         if (index === 0) {
             ctx.moveTo(x, y);
@@ -300,9 +657,71 @@ function updateEquityChart(equityCurve) {
     ctx.stroke();
 }
 
+// Live updates pushed over /ws, with polling every 30 seconds while disconnected
+let pollTimer = null;
+
+function connectLive() {
+    const protocol = location.protocol === 'https:' ? 'wss://' : 'ws://';
+    const socket = new WebSocket(protocol + location.host + '/ws');
+    socket.onopen = () => {
+        clearInterval(pollTimer);
+        pollTimer = null;
+        refreshData();
+    };
+    socket.onmessage = message => handleLiveEvent(JSON.parse(message.data));
+    socket.onclose = () => {
+        if (pollTimer === null) {
+            pollTimer = setInterval(refreshData, 30000);
+        }
+        document.getElementById('bot-status').textContent = 'Disconnected, reconnecting...';
+        setTimeout(connectLive, 5000);
+    };
+}
+
+// Apply a pushed event
+function handleLiveEvent(event) {
+    switch (event.type) {
+    case 'metrics':
+        renderMetrics(event.data);
+        document.getElementById('last-updated').textContent = new Date(event.timestamp).toLocaleString();
+        break;
+    case 'trade': {
+        const tbody = document.getElementById('trades-body');
+        tbody.insertBefore(tradeRow(event.data), tbody.firstChild);
+        while (tbody.children.length > 10) {
+            tbody.removeChild(tbody.lastChild);
+        }
+        // Trades move the allocation, exposure and capital
+        fetchPerformance();
+        fetchRisk();
+        fetchPortfolio();
+        fetchEquity();
+        break;
+    }
+    case 'risk_alert': {
+        const alert = event.data;
+        const log = document.getElementById('override-log');
+        const entry = document.createElement('div');
+        entry.className = alert.severity === 'critical' ? 'negative' : '';
+        entry.textContent = '[' + new Date(alert.timestamp).toLocaleTimeString() + '] ' + alert.severity.toUpperCase() + ' ' +
+            alert.type + ' ' + alert.subject + ': ' + alert.message;
+        log.appendChild(entry);
+        log.scrollTop = log.scrollHeight;
+        fetchRisk();
+        break;
+    }
+    case 'status': {
+        const status = event.data;
+        const element = document.getElementById('bot-status');
+        element.textContent = status.halted ? 'Halted: ' + status.halt_reason : status.running ? 'Trading' : 'Stopped';
+        element.className = status.running ? 'positive' : 'negative';
+        break;
+    }
+    }
+}
+
 // Initial data load
 document.addEventListener('DOMContentLoaded', function() {
     refreshData();
-    // Refresh data every 30 seconds
-    setInterval(refreshData, 30000);
+    connectLive();
 });
\ No newline at end of file